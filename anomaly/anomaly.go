@@ -0,0 +1,219 @@
+// anomaly.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package anomaly flags unusual use of the task API that may be an attacker or a runaway script: mass
+// deletes of tasks and a token used from several IP addresses at once.
+package anomaly
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bkojha74/task-management/helper"
+)
+
+// Kinds of anomalies
+const (
+	KindMassDelete = "mass_delete" // A user deleted many tasks in a short time
+	KindTokenReuse = "token_reuse" // The token of a session was used from many IP addresses in a short time
+)
+
+// Config sets when the detector flags a pattern and what happens then.
+type Config struct {
+	DeleteLimit    int           // Task deletes by a user within Window that are flagged; 0 disables the check
+	IPLimit        int           // Distinct IPs a session is used from within Window that are flagged; 0 disables the check
+	Window         time.Duration // Period the deletes and IPs are counted over
+	Reauthenticate bool          // Revoke the flagged session, so that the user has to sign in again
+}
+
+// LoadConfig reads the ANOMALY_DELETE_LIMIT (default 50), ANOMALY_IP_LIMIT (default 4), ANOMALY_WINDOW
+// (seconds, default 600) and ANOMALY_REAUTH environment variables.
+//
+// Returns:
+// - Config: The configured settings.
+func LoadConfig() Config {
+	return Config{
+		DeleteLimit:    helper.GetEnvInt("ANOMALY_DELETE_LIMIT", 50),
+		IPLimit:        helper.GetEnvInt("ANOMALY_IP_LIMIT", 4),
+		Window:         time.Duration(helper.GetEnvInt("ANOMALY_WINDOW", 600)) * time.Second,
+		Reauthenticate: helper.GetEnv("ANOMALY_REAUTH") == "true",
+	}
+}
+
+// Enabled reports whether any pattern is checked.
+func (c Config) Enabled() bool {
+	return c.Window > 0 && (c.DeleteLimit > 0 || c.IPLimit > 0)
+}
+
+// Alert is a flagged pattern, published to admins as an events.SecurityAnomaly event.
+type Alert struct {
+	Kind           string    `json:"kind"`
+	UserID         string    `json:"user_id"`
+	SessionID      string    `json:"session_id,omitempty"`
+	Count          int       `json:"count"`         // Deletes or distinct IPs within the window
+	IPs            []string  `json:"ips,omitempty"` // IPs the session was used from, for token reuse
+	Window         string    `json:"window"`        // Period counted over, e.g. "10m0s"
+	DetectedAt     time.Time `json:"detected_at"`
+	Reauthenticate bool      `json:"reauthenticate"` // The session was revoked
+}
+
+// Detector counts task deletes per user and IPs per session over a sliding window. Counts are kept in
+// memory per instance, which catches the bursts it is meant for without a round trip to the database;
+// behind a load balancer each instance sees its share. A pattern is flagged once per window.
+type Detector struct {
+	config Config
+
+	mu        sync.Mutex
+	deletes   map[string][]time.Time          // Times of the recent deletes per user
+	ips       map[string]map[string]time.Time // Last use per IP per session
+	flagged   map[string]time.Time            // Last alert per kind and user or session
+	lastSweep time.Time
+}
+
+// NewDetector creates a detector with the given thresholds.
+func NewDetector(config Config) *Detector {
+	return &Detector{
+		config:  config,
+		deletes: map[string][]time.Time{},
+		ips:     map[string]map[string]time.Time{},
+		flagged: map[string]time.Time{},
+	}
+}
+
+// Config returns the thresholds of the detector.
+func (d *Detector) Config() Config {
+	return d.config
+}
+
+// Delete counts a task delete by a user and returns an alert if the user deleted more than DeleteLimit
+// tasks within the window, or nil.
+//
+// Parameters:
+// - userID: The hex ID of the user.
+// - sessionID: The hex ID of the session of the token used, if any.
+// - at: The time of the delete.
+//
+// Returns:
+// - *Alert: The alert, or nil.
+func (d *Detector) Delete(userID, sessionID string, at time.Time) *Alert {
+	if d.config.DeleteLimit <= 0 || d.config.Window <= 0 {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sweep(at)
+
+	recent := append(d.within(d.deletes[userID], at), at)
+	d.deletes[userID] = recent
+	if len(recent) <= d.config.DeleteLimit || !d.flag(KindMassDelete+":"+userID, at) {
+		return nil
+	}
+	return &Alert{
+		Kind:       KindMassDelete,
+		UserID:     userID,
+		SessionID:  sessionID,
+		Count:      len(recent),
+		Window:     d.config.Window.String(),
+		DetectedAt: at,
+	}
+}
+
+// Request counts a request made with the token of a session from an IP and returns an alert if the session
+// was used from more than IPLimit IPs within the window, or nil.
+//
+// Parameters:
+// - userID: The hex ID of the user.
+// - sessionID: The hex ID of the session of the token.
+// - ip: The client IP of the request.
+// - at: The time of the request.
+//
+// Returns:
+// - *Alert: The alert, or nil.
+func (d *Detector) Request(userID, sessionID, ip string, at time.Time) *Alert {
+	if d.config.IPLimit <= 0 || d.config.Window <= 0 || sessionID == "" {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sweep(at)
+
+	seen := d.ips[sessionID]
+	if seen == nil {
+		seen = map[string]time.Time{}
+		d.ips[sessionID] = seen
+	}
+	seen[ip] = at
+	for address, last := range seen {
+		if at.Sub(last) > d.config.Window {
+			delete(seen, address)
+		}
+	}
+	if len(seen) <= d.config.IPLimit || !d.flag(KindTokenReuse+":"+sessionID, at) {
+		return nil
+	}
+	ips := make([]string, 0, len(seen))
+	for address := range seen {
+		ips = append(ips, address)
+	}
+	sort.Strings(ips)
+	return &Alert{
+		Kind:       KindTokenReuse,
+		UserID:     userID,
+		SessionID:  sessionID,
+		Count:      len(seen),
+		IPs:        ips,
+		Window:     d.config.Window.String(),
+		DetectedAt: at,
+	}
+}
+
+// within returns the times that are within the window before at.
+func (d *Detector) within(times []time.Time, at time.Time) []time.Time {
+	kept := times[:0]
+	for _, t := range times {
+		if at.Sub(t) <= d.config.Window {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+// flag reports whether a pattern was not flagged within the window, and records that it is flagged at at.
+func (d *Detector) flag(key string, at time.Time) bool {
+	if last, ok := d.flagged[key]; ok && at.Sub(last) <= d.config.Window {
+		return false
+	}
+	d.flagged[key] = at
+	return true
+}
+
+// sweep forgets the users and sessions without activity in the last window, once per window, so that
+// the counts of idle users do not pile up.
+func (d *Detector) sweep(at time.Time) {
+	if at.Sub(d.lastSweep) < d.config.Window {
+		return
+	}
+	d.lastSweep = at
+	for userID, times := range d.deletes {
+		if len(d.within(times, at)) == 0 {
+			delete(d.deletes, userID)
+		}
+	}
+	for sessionID, seen := range d.ips {
+		latest := time.Time{}
+		for _, last := range seen {
+			if last.After(latest) {
+				latest = last
+			}
+		}
+		if at.Sub(latest) > d.config.Window {
+			delete(d.ips, sessionID)
+		}
+	}
+	for key, last := range d.flagged {
+		if at.Sub(last) > d.config.Window {
+			delete(d.flagged, key)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// anomaly_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMassDelete tests when task deletes are flagged
+func TestMassDelete(t *testing.T) {
+	detector := NewDetector(Config{DeleteLimit: 3, Window: time.Minute})
+	start := time.Date(2024, 7, 5, 12, 0, 0, 0, time.UTC)
+
+	// Assert that deletes up to the limit pass, the next one is flagged once per window
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, detector.Delete("u1", "s1", start.Add(time.Duration(i)*time.Second)))
+	}
+	alert := detector.Delete("u1", "s1", start.Add(3*time.Second))
+	require.NotNil(t, alert)
+	assert.Equal(t, KindMassDelete, alert.Kind)
+	assert.Equal(t, 4, alert.Count)
+	assert.Equal(t, "s1", alert.SessionID)
+	assert.Nil(t, detector.Delete("u1", "s1", start.Add(4*time.Second)))
+
+	// Assert that other users are counted apart and that old deletes leave the window
+	assert.Nil(t, detector.Delete("u2", "", start.Add(5*time.Second)))
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, detector.Delete("u3", "", start.Add(time.Duration(i)*40*time.Second)))
+	}
+}
+
+// TestTokenReuse tests when a session used from several IPs is flagged
+func TestTokenReuse(t *testing.T) {
+	detector := NewDetector(Config{IPLimit: 2, Window: time.Minute})
+	start := time.Date(2024, 7, 5, 12, 0, 0, 0, time.UTC)
+
+	// Assert that repeated requests from a few IPs pass and a third IP is flagged
+	assert.Nil(t, detector.Request("u1", "s1", "203.0.113.1", start))
+	assert.Nil(t, detector.Request("u1", "s1", "203.0.113.1", start.Add(time.Second)))
+	assert.Nil(t, detector.Request("u1", "s1", "198.51.100.2", start.Add(2*time.Second)))
+	alert := detector.Request("u1", "s1", "192.0.2.3", start.Add(3*time.Second))
+	require.NotNil(t, alert)
+	assert.Equal(t, KindTokenReuse, alert.Kind)
+	assert.Equal(t, []string{"192.0.2.3", "198.51.100.2", "203.0.113.1"}, alert.IPs)
+
+	// Assert that IPs not seen within the window are forgotten, and tokens without a session not checked
+	assert.Nil(t, detector.Request("u1", "s2", "203.0.113.1", start))
+	assert.Nil(t, detector.Request("u1", "s2", "198.51.100.2", start.Add(2*time.Minute)))
+	assert.Nil(t, detector.Request("u1", "s2", "192.0.2.3", start.Add(2*time.Minute+time.Second)))
+	assert.Nil(t, detector.Request("u1", "", "192.0.2.4", start))
+
+	// Assert that a disabled detector flags nothing
+	assert.False(t, Config{}.Enabled())
+	assert.Nil(t, NewDetector(Config{}).Delete("u1", "", start))
+}
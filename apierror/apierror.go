@@ -0,0 +1,185 @@
+// apierror.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package apierror
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/bkojha74/task-management/i18n"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// Machine-readable error codes shared by all modules
+const (
+	CodeInvalidJSON          = "invalid_json"
+	CodeInvalidID            = "invalid_id"
+	CodeInvalidQuery         = "invalid_query"
+	CodeInvalidHeader        = "invalid_header"
+	CodeValidationFailed     = "validation_failed"
+	CodeAlreadyExists        = "already_exists"
+	CodeUnauthorized         = "unauthorized"
+	CodeInvalidCredentials   = "invalid_credentials"
+	CodeInvalidToken         = "invalid_token"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodeVersionConflict      = "version_conflict"
+	CodeCursorExpired        = "cursor_expired"
+	CodePossibleDuplicate    = "possible_duplicate"
+	CodeUnprocessable        = "unprocessable"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeRateLimited          = "rate_limited"
+	CodeQuotaExceeded        = "quota_exceeded"
+	CodeInternal             = "internal_error"
+	CodeBadGateway           = "bad_gateway"
+	CodeServiceUnavailable   = "service_unavailable"
+	CodeMaintenance          = "maintenance"
+	CodeClientClosedRequest  = "client_closed_request"
+)
+
+// StatusClientClosedRequest is the non-standard status of requests whose client disconnected before the
+// response, as logged by nginx. Nobody reads the response; it shows in the request log and metrics.
+const StatusClientClosedRequest = 499
+
+// Error is an API error with an HTTP status, a machine-readable code, a human-readable message
+// and optional details. Handlers return it and the central Handler renders it.
+type Error struct {
+	Status  int         `json:"-"`
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// WithDetails returns a copy of the error carrying additional details for the client.
+func (e *Error) WithDetails(details interface{}) *Error {
+	clone := *e
+	clone.Details = details
+	return &clone
+}
+
+// New creates an API error with the given HTTP status, code and message.
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// BadRequest creates a 400 Bad Request error.
+func BadRequest(code, message string) *Error {
+	return New(fiber.StatusBadRequest, code, message)
+}
+
+// Unauthorized creates a 401 Unauthorized error.
+func Unauthorized(code, message string) *Error {
+	return New(fiber.StatusUnauthorized, code, message)
+}
+
+// Forbidden creates a 403 Forbidden error.
+func Forbidden(code, message string) *Error {
+	return New(fiber.StatusForbidden, code, message)
+}
+
+// NotFound creates a 404 Not Found error.
+func NotFound(code, message string) *Error {
+	return New(fiber.StatusNotFound, code, message)
+}
+
+// Conflict creates a 409 Conflict error.
+func Conflict(code, message string) *Error {
+	return New(fiber.StatusConflict, code, message)
+}
+
+// Internal creates a 500 Internal Server Error.
+func Internal(code, message string) *Error {
+	return New(fiber.StatusInternalServerError, code, message)
+}
+
+// body is the JSON error response: {code, message, details, request_id}.
+type body struct {
+	*Error
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Handler is the central Fiber ErrorHandler. It renders *Error values as {code, message, details, request_id},
+// converts Fiber errors (404 route not found, 413 body too large, ...) to the same format, and hides the
+// message of unexpected errors behind a generic internal error. Messages are translated into the first
+// language of the Accept-Language header that has a translation, English otherwise. In envelope mode the error is listed
+// under "errors" and the request ID is added to the metadata.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+// - err: The error returned by a handler or middleware.
+//
+// Returns:
+// - error: An error object if the response could not be written.
+func Handler(c *fiber.Ctx, err error) error {
+	var apiErr *Error
+	var fiberErr *fiber.Error
+	switch {
+	case errors.As(err, &apiErr):
+	case errors.As(err, &fiberErr):
+		apiErr = New(fiberErr.Code, codeForStatus(fiberErr.Code), fiberErr.Message)
+	default:
+		log.Printf("Unhandled error on %s %s: %v", c.Method(), c.Path(), err)
+		apiErr = Internal(CodeInternal, "internal server error")
+	}
+
+	// Answer in the client's language, without changing the error the handler returned
+	localized := *apiErr
+	var language string
+	localized.Message, language = i18n.Translate(apiErr.Message, i18n.Languages(c.Get(fiber.HeaderAcceptLanguage)))
+	apiErr = &localized
+	c.Set(fiber.HeaderContentLanguage, language)
+	c.Vary(fiber.HeaderAcceptLanguage)
+
+	requestID := c.GetRespHeader(fiber.HeaderXRequestID)
+	if response.Enabled(c) {
+		if requestID != "" {
+			response.AddMeta(c, "request_id", requestID)
+		}
+		return c.Status(apiErr.Status).JSON(response.Envelope{
+			Meta:   response.Meta(c),
+			Errors: []interface{}{apiErr},
+		})
+	}
+
+	return c.Status(apiErr.Status).JSON(body{Error: apiErr, RequestID: requestID})
+}
+
+// codeForStatus derives an error code for errors raised by Fiber itself.
+func codeForStatus(status int) string {
+	switch status {
+	case fiber.StatusBadRequest:
+		return CodeValidationFailed
+	case fiber.StatusUnauthorized:
+		return CodeUnauthorized
+	case fiber.StatusForbidden:
+		return CodeForbidden
+	case fiber.StatusNotFound:
+		return CodeNotFound
+	case fiber.StatusConflict:
+		return CodeConflict
+	case fiber.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case fiber.StatusUnsupportedMediaType:
+		return CodeUnsupportedMediaType
+	case fiber.StatusTooManyRequests:
+		return CodeRateLimited
+	case fiber.StatusServiceUnavailable:
+		return CodeServiceUnavailable
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return strings.ToLower(strings.ReplaceAll(utils.StatusMessage(status), " ", "_"))
+}
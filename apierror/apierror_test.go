@@ -0,0 +1,89 @@
+// apierror_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestApp returns an app using the central error handler with routes returning different kinds of errors
+func newTestApp() *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: Handler})
+	app.Use(requestid.New())
+	app.Get("/api", func(c *fiber.Ctx) error {
+		return Conflict(CodeVersionConflict, "stale").WithDetails(fiber.Map{"current_version": 2})
+	})
+	app.Get("/plain", func(c *fiber.Ctx) error {
+		return errors.New("database exploded")
+	})
+	return app
+}
+
+// decode performs the request and decodes the JSON body
+func decode(t *testing.T, app *fiber.App, method, path string, headers map[string]string) (int, map[string]interface{}) {
+	req := httptest.NewRequest(method, path, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+
+	var body map[string]interface{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp.StatusCode, body
+}
+
+// TestHandlerAPIError tests that API errors keep their status, code and details and get a request ID
+func TestHandlerAPIError(t *testing.T) {
+	status, body := decode(t, newTestApp(), fiber.MethodGet, "/api", nil)
+	assert.Equal(t, fiber.StatusConflict, status)
+	assert.Equal(t, CodeVersionConflict, body["code"])
+	assert.Equal(t, "stale", body["message"])
+	assert.Equal(t, map[string]interface{}{"current_version": float64(2)}, body["details"])
+	assert.NotEmpty(t, body["request_id"])
+}
+
+// TestHandlerUnexpectedError tests that unexpected errors are hidden behind a generic internal error
+func TestHandlerUnexpectedError(t *testing.T) {
+	status, body := decode(t, newTestApp(), fiber.MethodGet, "/plain", nil)
+	assert.Equal(t, fiber.StatusInternalServerError, status)
+	assert.Equal(t, CodeInternal, body["code"])
+	assert.Equal(t, "internal server error", body["message"])
+}
+
+// TestHandlerFiberError tests that errors raised by Fiber itself use the same format
+func TestHandlerFiberError(t *testing.T) {
+	status, body := decode(t, newTestApp(), fiber.MethodGet, "/missing", nil)
+	assert.Equal(t, fiber.StatusNotFound, status)
+	assert.Equal(t, CodeNotFound, body["code"])
+}
+
+// TestHandlerEnvelope tests that errors are listed under "errors" in envelope mode
+func TestHandlerEnvelope(t *testing.T) {
+	status, body := decode(t, newTestApp(), fiber.MethodGet, "/api", map[string]string{"X-Response-Envelope": "true"})
+	assert.Equal(t, fiber.StatusConflict, status)
+
+	errs := body["errors"].([]interface{})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, CodeVersionConflict, errs[0].(map[string]interface{})["code"])
+	assert.NotEmpty(t, body["meta"].(map[string]interface{})["request_id"])
+}
+
+// TestHandlerLocalized tests that messages follow the Accept-Language header and fall back to English
+func TestHandlerLocalized(t *testing.T) {
+	_, body := decode(t, newTestApp(), fiber.MethodGet, "/plain", map[string]string{fiber.HeaderAcceptLanguage: "hi-IN,en;q=0.5"})
+	assert.Equal(t, CodeInternal, body["code"])
+	assert.Equal(t, "आंतरिक सर्वर त्रुटि", body["message"])
+
+	// Assert that messages without a translation stay English
+	_, body = decode(t, newTestApp(), fiber.MethodGet, "/api", map[string]string{fiber.HeaderAcceptLanguage: "hi"})
+	assert.Equal(t, "stale", body["message"])
+}
@@ -0,0 +1,597 @@
+// app.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/bkojha74/task-management/anomaly"
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/audit"
+	"github.com/bkojha74/task-management/billing"
+	"github.com/bkojha74/task-management/database"
+	"github.com/bkojha74/task-management/environment"
+	"github.com/bkojha74/task-management/features"
+	"github.com/bkojha74/task-management/fieldcrypt"
+	"github.com/bkojha74/task-management/githubsync"
+	"github.com/bkojha74/task-management/handlers"
+	"github.com/bkojha74/task-management/helper"
+	"github.com/bkojha74/task-management/logging"
+	"github.com/bkojha74/task-management/middleware"
+	"github.com/bkojha74/task-management/planning"
+	"github.com/bkojha74/task-management/quota"
+	"github.com/bkojha74/task-management/retention"
+	"github.com/bkojha74/task-management/saml"
+	"github.com/bkojha74/task-management/server"
+	"github.com/bkojha74/task-management/signup"
+	"github.com/bkojha74/task-management/telegram"
+	"github.com/bkojha74/task-management/utils"
+	"github.com/bkojha74/task-management/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/pprof"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+// Config holds the settings the HTTP application is built from.
+type Config struct {
+	Environment           string                   // Deployment environment of APP_ENV; "" if unset
+	Database              string                   // MongoDB database of the environment
+	Reporting             database.ReportingConfig // Client of the reporting reads, e.g. from secondaries; disabled reads from the primary client
+	JWTSecret             string                   // Secret used to sign and verify tokens
+	JWTIssuer             string                   // "iss" of the issued tokens and required of accepted ones; empty disables the check
+	JWTAudience           string                   // "aud" of the issued tokens and required of accepted ones; empty disables the check
+	JWTClockSkew          time.Duration            // Leeway for the exp, nbf and iat claims of accepted tokens
+	TokenExpiry           int                      // Token lifetime in minutes
+	RefreshTokenExpiry    time.Duration            // Lifetime of the refresh tokens issued at sign-in; 0 issues none
+	RememberMeExpiry      time.Duration            // Lifetime of the refresh tokens of sign-ins with remember_me; 0 uses RefreshTokenExpiry
+	SlidingSessions       bool                     // Refreshing extends a session by its refresh token lifetime, up to MaxSessionLifetime
+	MaxSessionLifetime    time.Duration            // Longest a sliding session lasts after its sign-in; 0 is no limit
+	BodyLimit             int                      // Maximum request body size in bytes
+	CORS                  middleware.CORSConfig    // Cross-origin request policy
+	Proxy                 middleware.ProxyConfig   // Reverse proxies whose client IP header is believed
+	HSTSMaxAge            int                      // Strict-Transport-Security max-age in seconds; 0 disables it
+	HSTSIncludeSubdomains bool                     // Add includeSubDomains to the HSTS header
+	DrainGrace            time.Duration            // How long a draining instance keeps serving before shutting down
+	DeletionGrace         time.Duration            // How long a deleted account is kept, and can be restored, before it is purged
+	SyncRetention         time.Duration            // How long the tombstones of deleted tasks are kept for offline clients
+	SyncOverlap           time.Duration            // How far back a finished sync reads again, for writes committed after it read
+	MaintenanceRefresh    time.Duration            // How long an instance uses the maintenance mode before reading it again
+	DisconnectCheck       time.Duration            // How often a running request checks whether its client disconnected; 0 disables the check
+	AuthLimit             middleware.RateLimitTier // Rate limit of the sign-up and sign-in endpoints
+	ReadLimit             middleware.RateLimitTier // Rate limit of task reads
+	WriteLimit            middleware.RateLimitTier // Rate limit of task writes
+	RequestLog            bool                     // Log every request, at the info level
+	LogLevel              slog.Level               // Minimum level of the messages logged
+	Features              []string                 // Enabled feature flags
+	DebugEndpoints        bool                     // Serve pprof profiles and runtime info under /admin/debug
+	Audit                 audit.Config             // What the audit log records of mutating requests
+	FieldEncryption       *fieldcrypt.Keyring      // Keys encrypting the task descriptions of private projects; nil stores them in plaintext
+	Signup                signup.Policy            // Sign-up mode, CAPTCHA and email checks of public sign-up
+	SAML                  *saml.ServiceProvider    // SAML single sign-on with one identity provider; nil disables it
+	SCIMToken             string                   // Bearer token of the identity provider calling the SCIM API; empty disables it
+	Telegram              *telegram.Bot            // Telegram bot for chat commands and notifications; nil disables it
+	GitHub                *githubsync.App          // GitHub OAuth app for syncing tasks with issues; nil disables it
+	Stripe                *billing.Stripe          // Stripe account of the workspace plans; nil keeps all workspaces on the free plan
+	Retention             retention.Policy         // How long completed tasks, trashed tasks and audit entries are kept
+	Anomaly               anomaly.Config           // Unusual task API use flagged to admins; disabled without limits
+	TriageWeights         planning.TriageWeights   // Default weights of the factors GET /tasks/triage ranks open tasks by
+
+	Port                    string           // Port the server listens on
+	TLS                     server.TLSConfig // Native TLS termination
+	CacheSize               int              // Task read cache entries; 0 disables the cache
+	CacheTTL                time.Duration    // Task read cache entry lifetime
+	ResponseEnvelope        bool             // Wrap responses in a {data, meta, errors} envelope by default
+	Holidays                string           // Comma separated YYYY-MM-DD dates warned about as due dates
+	MaxOpenTasksPerAssignee int              // Open tasks above which an assignee is overloaded; 0 disables the check
+	UserQuota               quota.Limits     // Most tasks and webhooks of a user; 0 is unlimited
+	WorkspaceQuota          quota.Limits     // Most tasks and webhooks of all members of a workspace together; 0 is unlimited
+}
+
+// LoadConfig reads the application settings from the environment variables documented in the README.
+//
+// Returns:
+// - Config: The configured settings.
+// - error: An error if a required variable is missing or invalid.
+func LoadConfig() (Config, error) {
+	appPort := helper.GetEnv("APP_PORT")
+	jwtSecret := helper.GetEnv("JWT_SECRET")
+	tokenExpiry := helper.GetEnv("TOKEN_EXPIRY_TIME")
+	if appPort == "" || jwtSecret == "" || tokenExpiry == "" {
+		return Config{}, errors.New("APP_PORT, JWT_SECRET and TOKEN_EXPIRY_TIME must be set")
+	}
+
+	tokenExpiryTime, err := strconv.Atoi(tokenExpiry)
+	if err != nil {
+		return Config{}, errors.New("TOKEN_EXPIRY_TIME must be an integer")
+	}
+
+	issuer := helper.GetEnv("JWT_ISSUER")
+	if issuer == "" {
+		issuer = "task-management"
+	}
+	audience := helper.GetEnv("JWT_AUDIENCE")
+	if audience == "" {
+		audience = "task-management-api"
+	}
+
+	env, err := environment.Parse(helper.GetEnv("APP_ENV"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid APP_ENV: %w", err)
+	}
+
+	logLevel, err := logging.ParseLevel(environment.LogLevel())
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid LOG_LEVEL: %w", err)
+	}
+
+	keyring, err := fieldcrypt.LoadKeyring()
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid FIELD_ENCRYPTION_KEYS: %w", err)
+	}
+
+	signupPolicy, err := signup.LoadPolicy()
+	if err != nil {
+		return Config{}, err
+	}
+
+	serviceProvider, err := saml.LoadServiceProvider()
+	if err != nil {
+		return Config{}, err
+	}
+
+	bot, err := telegram.LoadBot()
+	if err != nil {
+		return Config{}, err
+	}
+
+	githubApp, err := githubsync.LoadApp()
+	if err != nil {
+		return Config{}, err
+	}
+
+	stripe, err := billing.LoadStripe()
+	if err != nil {
+		return Config{}, err
+	}
+
+	triageWeights, err := planning.ParseTriageWeights(helper.GetEnv("TRIAGE_WEIGHTS"), planning.DefaultTriageWeights)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid TRIAGE_WEIGHTS: %w", err)
+	}
+
+	return Config{
+		Environment:           env,
+		Database:              environment.Database(),
+		Reporting:             database.LoadReportingConfig(),
+		JWTSecret:             jwtSecret,
+		JWTIssuer:             issuer,
+		JWTAudience:           audience,
+		JWTClockSkew:          time.Duration(helper.GetEnvInt("JWT_CLOCK_SKEW", 30)) * time.Second,
+		TokenExpiry:           tokenExpiryTime,
+		RefreshTokenExpiry:    time.Duration(helper.GetEnvInt("REFRESH_TOKEN_EXPIRY_DAYS", 0)) * 24 * time.Hour,
+		RememberMeExpiry:      time.Duration(helper.GetEnvInt("REMEMBER_ME_EXPIRY_DAYS", 0)) * 24 * time.Hour,
+		SlidingSessions:       helper.GetEnv("SESSION_SLIDING") == "true",
+		MaxSessionLifetime:    time.Duration(helper.GetEnvInt("SESSION_MAX_LIFETIME_DAYS", 90)) * 24 * time.Hour,
+		BodyLimit:             helper.GetEnvInt("BODY_LIMIT", middleware.DefaultBodyLimit),
+		CORS:                  middleware.LoadCORSConfig(),
+		Proxy:                 middleware.LoadProxyConfig(),
+		HSTSMaxAge:            helper.GetEnvInt("HSTS_MAX_AGE", 0),
+		HSTSIncludeSubdomains: helper.GetEnv("HSTS_INCLUDE_SUBDOMAINS") == "true",
+		DrainGrace:            time.Duration(helper.GetEnvInt("DRAIN_GRACE_PERIOD", 30)) * time.Second,
+		DeletionGrace:         time.Duration(helper.GetEnvInt("ACCOUNT_DELETION_GRACE_DAYS", 30)) * 24 * time.Hour,
+		SyncRetention:         time.Duration(helper.GetEnvInt("SYNC_RETENTION_DAYS", 30)) * 24 * time.Hour,
+		SyncOverlap:           time.Duration(helper.GetEnvInt("SYNC_OVERLAP_SECONDS", 60)) * time.Second,
+		MaintenanceRefresh:    time.Duration(helper.GetEnvInt("MAINTENANCE_REFRESH", 5)) * time.Second,
+		DisconnectCheck:       time.Duration(helper.GetEnvInt("DISCONNECT_CHECK_INTERVAL_MS", 100)) * time.Millisecond,
+		AuthLimit:             middleware.LoadRateLimitTier("AUTH", 10, time.Minute),
+		ReadLimit:             middleware.LoadRateLimitTier("READ", 300, time.Minute),
+		WriteLimit:            middleware.LoadRateLimitTier("WRITE", 60, time.Minute),
+		RequestLog:            true,
+		LogLevel:              logLevel,
+		Features:              features.Parse(helper.GetEnv("FEATURE_FLAGS")),
+		DebugEndpoints:        helper.GetEnv("DEBUG_ENDPOINTS") == "true",
+		Audit:                 audit.LoadConfig(),
+		FieldEncryption:       keyring,
+		Signup:                signupPolicy,
+		SAML:                  serviceProvider,
+		SCIMToken:             helper.GetEnv("SCIM_TOKEN"),
+		Telegram:              bot,
+		GitHub:                githubApp,
+		Stripe:                stripe,
+		Retention:             retention.LoadPolicy(),
+		Anomaly:               anomaly.LoadConfig(),
+		TriageWeights:         triageWeights,
+
+		Port:                    appPort,
+		TLS:                     server.LoadTLSConfig(),
+		CacheSize:               helper.GetEnvInt("CACHE_SIZE", 1000),
+		CacheTTL:                time.Duration(helper.GetEnvInt("CACHE_TTL", 30)) * time.Second,
+		ResponseEnvelope:        helper.GetEnv("RESPONSE_ENVELOPE") == "true",
+		Holidays:                helper.GetEnv("HOLIDAYS"),
+		MaxOpenTasksPerAssignee: helper.GetEnvInt("ASSIGNEE_MAX_OPEN_TASKS", validation.MaxOpenTasksPerAssignee),
+		UserQuota:               quota.Limits{Tasks: helper.GetEnvInt("QUOTA_USER_TASKS", 0), Webhooks: helper.GetEnvInt("QUOTA_USER_WEBHOOKS", 0)},
+		WorkspaceQuota:          quota.Limits{Tasks: helper.GetEnvInt("QUOTA_WORKSPACE_TASKS", 0), Webhooks: helper.GetEnvInt("QUOTA_WORKSPACE_WEBHOOKS", 0)},
+	}, nil
+}
+
+// Validate checks the settings for combinations the application cannot serve.
+//
+// Returns:
+// - error: An error describing the first invalid setting, or nil.
+func (cfg Config) Validate() error {
+	if cfg.JWTSecret == "" {
+		return errors.New("a JWT secret is required")
+	}
+	if err := cfg.Reporting.Validate(); err != nil {
+		return fmt.Errorf("invalid reporting read preference: %w", err)
+	}
+	if err := cfg.TLS.Validate(); err != nil {
+		return fmt.Errorf("invalid TLS configuration: %w", err)
+	}
+	if err := cfg.CORS.Validate(); err != nil {
+		return fmt.Errorf("invalid CORS configuration: %w", err)
+	}
+	if cfg.Environment == environment.Production {
+		for _, origin := range cfg.CORS.AllowOrigins {
+			if origin == "*" {
+				return errors.New("invalid CORS configuration: the \"*\" origin is not allowed in production")
+			}
+		}
+	}
+	if cfg.DisconnectCheck < 0 {
+		return errors.New("the disconnect check interval must not be negative")
+	}
+	if err := cfg.Proxy.Validate(); err != nil {
+		return fmt.Errorf("invalid proxy configuration: %w", err)
+	}
+	if err := cfg.Audit.Validate(); err != nil {
+		return fmt.Errorf("invalid audit configuration: %w", err)
+	}
+	if err := cfg.Signup.Validate(); err != nil {
+		return fmt.Errorf("invalid sign-up configuration: %w", err)
+	}
+	if cfg.SAML != nil {
+		if err := cfg.SAML.Validate(); err != nil {
+			return fmt.Errorf("invalid SAML configuration: %w", err)
+		}
+	}
+	if cfg.SCIMToken != "" && len(cfg.SCIMToken) < 32 {
+		return errors.New("SCIM_TOKEN must be at least 32 characters")
+	}
+	if cfg.Telegram != nil {
+		if err := cfg.Telegram.Validate(); err != nil {
+			return fmt.Errorf("invalid Telegram configuration: %w", err)
+		}
+	}
+	if cfg.GitHub != nil {
+		if err := cfg.GitHub.Validate(); err != nil {
+			return fmt.Errorf("invalid GitHub configuration: %w", err)
+		}
+	}
+	if cfg.Stripe != nil {
+		if err := cfg.Stripe.Validate(); err != nil {
+			return fmt.Errorf("invalid Stripe configuration: %w", err)
+		}
+	}
+	if err := cfg.Retention.Validate(); err != nil {
+		return fmt.Errorf("invalid retention policy: %w", err)
+	}
+	for _, tier := range []middleware.RateLimitTier{cfg.AuthLimit, cfg.ReadLimit, cfg.WriteLimit} {
+		if err := tier.Validate(); err != nil {
+			return fmt.Errorf("invalid rate limit: %w", err)
+		}
+	}
+	for _, limits := range []quota.Limits{cfg.UserQuota, cfg.WorkspaceQuota} {
+		if limits.Tasks < 0 || limits.Webhooks < 0 {
+			return errors.New("quotas must not be negative")
+		}
+	}
+	return nil
+}
+
+// NewApp builds the Fiber application with all middleware and routes, without starting to listen.
+// The repositories and other package-level stores must be initialized by the caller; New does that
+// from options. The returned app can be served with Run, or exercised in-process with app.Test.
+//
+// Parameters:
+// - cfg: The application settings.
+//
+// Returns:
+// - *fiber.App: The configured application.
+func NewApp(cfg Config) *fiber.App {
+	return newApp(cfg, settings{})
+}
+
+// newApp builds the application with what the options set: the audit sink (none if nil), the signing
+// secret (Config.JWTSecret if nil), the reloader of the reloadable settings (a private one if nil) and the
+// extra middlewares run after the built-in ones and before any route.
+func newApp(cfg Config, s settings) *fiber.App {
+	reloader := s.reloader
+	if reloader == nil {
+		reloader = NewReloader()
+	}
+	reloader.start(cfg)
+
+	fiberConfig := fiber.Config{
+		ErrorHandler: apierror.Handler, // Render all errors as {code, message, details, request_id}
+		BodyLimit:    cfg.BodyLimit,    // Reject larger request bodies with 413
+		// The WebDAV methods of the CalDAV endpoints
+		RequestMethods: append(fiber.DefaultMethods[:len(fiber.DefaultMethods):len(fiber.DefaultMethods)], "PROPFIND", "REPORT"),
+	}
+	cfg.Proxy.Apply(&fiberConfig) // Client IPs from the header of trusted proxies
+	app := fiber.New(fiberConfig)
+
+	// Middleware setup
+	app.Use(requestid.New()) // Request ID middleware, exposed as X-Request-ID and in error responses
+	if cfg.RequestLog {
+		app.Use(logger.New(logger.Config{ // Request logger middleware, silenced above the info level
+			Next: func(c *fiber.Ctx) bool { return !logging.Enabled(slog.LevelInfo) },
+		}))
+	}
+	app.Use(middleware.CancelOnDisconnect(cfg.DisconnectCheck)) // Cancel the queries of requests whose client went away, logged as 499
+	if s.auditSink != nil {
+		app.Use(middleware.Audit(s.auditSink, cfg.Audit)) // Audit log of mutating requests
+	}
+	app.Use(reloader.cors.Handler)                                      // CORS middleware, reloadable
+	app.Use(middleware.RequireJSON("/saml/acs", handlers.CalDAVPrefix)) // Reject non-JSON request bodies with 415; identity providers post forms, CalDAV clients XML and iCalendar
+	app.Use(middleware.HSTS(cfg.HSTSMaxAge, cfg.HSTSIncludeSubdomains))
+	for _, handler := range s.middlewares {
+		app.Use(handler)
+	}
+
+	// Maintenance mode rejects writes or all requests with 503; the probes, the version, sign-in and the
+	// admin endpoints stay available so that an admin can switch it off again
+	app.Use(middleware.Maintenance(cfg.MaintenanceRefresh, "/healthz", "/readyz", "/version", "/signin", "/token/refresh", "/saml", "/admin"))
+
+	// Health probes; readiness turns not-ready while the instance is draining
+	app.Get("/healthz", handlers.Healthz)
+	app.Get("/readyz", handlers.Readyz)
+	app.Get("/version", handlers.GetVersion) // Build metadata of the deployed binary
+
+	// Rate limit tiers: brute-force sensitive auth endpoints get a much smaller budget than task reads
+	authLimit := reloader.authLimit.Handler
+	readLimit := reloader.readLimit.Handler
+	writeLimit := reloader.writeLimit.Handler
+	signingSecret := s.signingSecret
+	if signingSecret == nil {
+		signingSecret = utils.NewSigningSecret(cfg.JWTSecret)
+	}
+	jwt := utils.JWTMiddleware(signingSecret)
+	guest := utils.GuestMiddleware(signingSecret) // Also lets invited guests read their project
+
+	// User management endpoints
+	app.Post("/signup", authLimit, handlers.SignUp)                                              // User registration endpoint
+	app.Post("/signin", authLimit, handlers.SignIn(signingSecret, cfg.TokenExpiry))              // User login endpoint with JWT token generation
+	app.Post("/token/refresh", authLimit, handlers.RefreshToken(signingSecret, cfg.TokenExpiry)) // Refresh token exchange endpoint
+	app.Post("/signout", handlers.SignOut)                                                       // User logout endpoint
+
+	// SAML single sign-on, initiated here and completed by the identity provider posting to /saml/acs
+	if cfg.SAML != nil {
+		app.Get("/saml/metadata", handlers.SAMLMetadata(cfg.SAML))                                   // Service provider metadata endpoint
+		app.Get("/saml/login", authLimit, handlers.SAMLLogin(cfg.SAML, signingSecret))               // Redirect to the identity provider
+		app.Post("/saml/acs", authLimit, handlers.SAMLACS(cfg.SAML, signingSecret, cfg.TokenExpiry)) // Assertion consumer service
+	}
+
+	// SCIM 2.0 provisioning by the identity provider, authenticated with SCIM_TOKEN
+	if cfg.SCIMToken != "" {
+		scim := app.Group("/scim/v2", middleware.BearerToken(cfg.SCIMToken))
+		scim.Get("/ServiceProviderConfig", handlers.GetSCIMServiceProviderConfig) // Supported SCIM features
+		scim.Get("/Users", handlers.ListSCIMUsers)                                // List or look up users
+		scim.Post("/Users", handlers.CreateSCIMUser)                              // Provision a user
+		scim.Get("/Users/:id", handlers.GetSCIMUser)                              // Get a user
+		scim.Put("/Users/:id", handlers.ReplaceSCIMUser)                          // Replace a user
+		scim.Patch("/Users/:id", handlers.PatchSCIMUser)                          // Update or (de)activate a user
+		scim.Delete("/Users/:id", handlers.DeleteSCIMUser)                        // Deactivate a user
+	}
+
+	// The account endpoints of the logged-in user need a sign-in token, not a scoped API token
+	account := middleware.Unscoped
+
+	// Notification settings of the logged-in user
+	app.Get("/users/me/notifications", jwt, account, handlers.GetNotificationSettings)    // Get chat channels endpoint
+	app.Put("/users/me/notifications", jwt, account, handlers.UpdateNotificationSettings) // Update chat channels endpoint
+	app.Get("/users/me/digest", jwt, account, handlers.GetDigestSettings)                 // Get digest schedule endpoint
+	app.Put("/users/me/digest", jwt, account, handlers.UpdateDigestSettings)              // Update digest schedule endpoint
+	app.Get("/users/me/timezone", jwt, account, handlers.GetTimezone)                     // Get timezone endpoint
+	app.Put("/users/me/timezone", jwt, account, handlers.UpdateTimezone)                  // Update timezone endpoint
+
+	// Telegram bot: users link their chat with a code, then Telegram posts their commands to the webhook
+	if cfg.Telegram != nil {
+		app.Post("/telegram/webhook", handlers.TelegramWebhook(cfg.Telegram))                         // Bot updates endpoint, authenticated with TELEGRAM_WEBHOOK_SECRET
+		app.Post("/users/me/telegram", writeLimit, jwt, account, handlers.LinkTelegram(cfg.Telegram)) // Issue a chat link code endpoint
+		app.Delete("/users/me/telegram", writeLimit, jwt, account, handlers.UnlinkTelegram)           // Unlink chat endpoint
+	}
+
+	// GitHub issue sync: users connect their GitHub account through the OAuth app, whose callback
+	// authenticates with the signed state, and GitHub reports issue changes to the webhook
+	if cfg.GitHub != nil {
+		app.Post("/users/me/github", writeLimit, jwt, account, handlers.ConnectGitHub(cfg.GitHub)) // Start GitHub authorization endpoint
+		app.Get("/users/me/github", jwt, account, handlers.GetGitHubAccount)                       // Get connected GitHub account endpoint
+		app.Delete("/users/me/github", writeLimit, jwt, account, handlers.DisconnectGitHub)        // Disconnect GitHub endpoint
+		app.Get("/github/callback", authLimit, handlers.GitHubCallback(cfg.GitHub))                // OAuth callback endpoint
+		if cfg.GitHub.WebhookSecret != "" {
+			app.Post("/github/webhook", handlers.GitHubWebhook(cfg.GitHub)) // Issue events endpoint, signed with GITHUB_WEBHOOK_SECRET
+		}
+	}
+
+	// Account deletion and data export of the logged-in user
+	app.Delete("/users/me", writeLimit, jwt, account, handlers.DeleteAccount(cfg.DeletionGrace))    // Schedule account deletion endpoint
+	app.Post("/users/me/cancel-deletion", writeLimit, jwt, account, handlers.CancelAccountDeletion) // Cancel account deletion endpoint
+	app.Get("/users/me/export", readLimit, jwt, account, handlers.ExportAccount)                    // Export account data endpoint
+	app.Post("/users/me/import", writeLimit, jwt, account, handlers.ImportTasks)                    // Import Trello or Todoist tasks endpoint
+
+	// Signed-in sessions and API tokens of the logged-in user
+	app.Get("/users/me/sessions", jwt, account, handlers.GetSessions)                              // List sessions and API tokens endpoint
+	app.Delete("/users/me/sessions/:id", writeLimit, jwt, account, handlers.RevokeSession)         // Revoke session or API token endpoint
+	app.Get("/users/me/signins", readLimit, jwt, account, handlers.GetSignIns)                     // Sign-in history endpoint
+	app.Post("/users/me/tokens", writeLimit, jwt, account, handlers.CreateAPIToken(signingSecret)) // Create scoped API token endpoint
+
+	// JWT Middleware for task management endpoints
+	app.Use("/tasks", middleware.Protected(signingSecret))
+	if cfg.Anomaly.Enabled() {
+		app.Use("/tasks", middleware.Anomalies(anomaly.NewDetector(cfg.Anomaly))) // Flag mass deletes and tokens used from many IPs
+	}
+
+	// Task management endpoints
+	app.Post("/tasks", writeLimit, jwt, handlers.CreateTask)                         // Create task endpoint
+	app.Get("/tasks", readLimit, guest, handlers.GetTasks)                           // Get all tasks endpoint
+	app.Get("/tasks/summary", readLimit, jwt, handlers.GetTaskSummaries)             // List task summaries from the read model
+	app.Get("/tasks/triage", readLimit, jwt, handlers.GetTriage(cfg.TriageWeights))  // Rank open tasks by what to do next
+	app.Post("/tasks/batch-get", readLimit, guest, handlers.BatchGetTasks)           // Get several tasks by ID endpoint
+	app.Post("/tasks/reorder", writeLimit, jwt, handlers.ReorderTask)                // Manual order endpoint
+	app.Post("/tasks/bulk/transition", writeLimit, jwt, handlers.TransitionTasks)    // Bulk status change endpoint
+	app.Patch("/tasks", writeLimit, jwt, handlers.PatchTasks)                        // Patch the tasks matching a filter endpoint
+	app.Get("/tasks/:id", readLimit, guest, handlers.GetTask)                        // Get a single task by ID endpoint
+	app.Put("/tasks/:id", writeLimit, jwt, handlers.UpdateTask)                      // Update task by ID endpoint
+	app.Patch("/tasks/:id", writeLimit, jwt, handlers.PatchTask)                     // Merge a partial update endpoint
+	app.Delete("/tasks/:id", writeLimit, jwt, handlers.DeleteTask)                   // Delete task by ID endpoint
+	app.Post("/tasks/:id/snooze", writeLimit, jwt, handlers.SnoozeTask)              // Postpone task endpoint
+	app.Post("/tasks/:id/reassign", writeLimit, jwt, handlers.ReassignTask)          // Reassign task endpoint
+	app.Post("/tasks/:id/pin", writeLimit, jwt, handlers.PinTask)                    // Pin task endpoint
+	app.Delete("/tasks/:id/pin", writeLimit, jwt, handlers.UnpinTask)                // Unpin task endpoint
+	app.Post("/tasks/:id/comments", writeLimit, jwt, handlers.AddComment)            // Comment on task endpoint
+	app.Get("/tasks/:id/comments", readLimit, jwt, handlers.GetComments)             // List task comments endpoint
+	app.Post("/tasks/:id/share", writeLimit, jwt, handlers.ShareTask(signingSecret)) // Create share link endpoint
+	if cfg.GitHub != nil {
+		app.Post("/tasks/:id/github-issue", writeLimit, jwt, handlers.LinkGitHubIssue(cfg.GitHub))      // Create and link GitHub issue endpoint
+		app.Post("/tasks/:id/github-issue/sync", writeLimit, jwt, handlers.SyncGitHubIssue(cfg.GitHub)) // Sync with GitHub issue endpoint
+		app.Delete("/tasks/:id/github-issue", writeLimit, jwt, handlers.UnlinkGitHubIssue)              // Unlink GitHub issue endpoint
+	}
+	app.Get("/shared/:token", readLimit, handlers.GetSharedTask(signingSecret)) // Read-only shared task endpoint, no account needed
+
+	// Trash of deleted tasks, purged by the retention job
+	app.Get("/trash", readLimit, jwt, handlers.GetTrash)                  // List deleted tasks endpoint
+	app.Post("/trash/:id/restore", writeLimit, jwt, handlers.RestoreTask) // Restore deleted task endpoint
+
+	// Project endpoints
+	app.Post("/projects", writeLimit, jwt, handlers.CreateProject)                     // Create project endpoint
+	app.Get("/projects", readLimit, jwt, handlers.GetProjects)                         // List projects endpoint
+	app.Get("/projects/:id", readLimit, guest, handlers.GetProject)                    // Get project endpoint
+	app.Get("/projects/:id/burndown", readLimit, guest, handlers.GetProjectBurndown)   // Daily remaining work endpoint
+	app.Get("/projects/:id/gantt", readLimit, guest, handlers.GetProjectGantt)         // Dependency-aware schedule endpoint
+	app.Get("/projects/:id/critical-path", readLimit, guest, handlers.GetCriticalPath) // Critical path and slack endpoint
+	app.Get("/projects/:id/report.pdf", readLimit, guest, handlers.GetProjectReport)   // Project PDF report endpoint
+
+	// Auto-assignment rule of a project
+	app.Put("/projects/:id/assignment", writeLimit, jwt, handlers.SetAssignmentRule)       // Set rule endpoint
+	app.Delete("/projects/:id/assignment", writeLimit, jwt, handlers.DeleteAssignmentRule) // Remove rule endpoint
+
+	// Focus sessions on tasks, e.g. pomodoros
+	app.Post("/focus", writeLimit, jwt, handlers.StartFocus)                        // Start focus session endpoint
+	app.Get("/focus/current", readLimit, jwt, handlers.GetCurrentFocus)             // Running focus session endpoint
+	app.Post("/focus/:id/stop", writeLimit, jwt, handlers.StopFocus)                // Stop focus session endpoint
+	app.Post("/focus/:id/interruptions", writeLimit, jwt, handlers.LogInterruption) // Log interruption endpoint
+
+	// Report endpoints
+	app.Get("/reports/workload", readLimit, jwt, handlers.GetWorkload)     // Open work per assignee endpoint
+	app.Get("/reports/tasks.pdf", readLimit, jwt, handlers.GetTasksReport) // PDF task report endpoint
+	app.Get("/reports/focus", readLimit, jwt, handlers.GetFocusReport)     // Daily focused time endpoint
+
+	// Reports emailed on a schedule
+	app.Post("/report-schedules", writeLimit, jwt, handlers.CreateReportSchedule)       // Create report schedule endpoint
+	app.Get("/report-schedules", readLimit, jwt, handlers.GetReportSchedules)           // List report schedules endpoint
+	app.Get("/report-schedules/:id", readLimit, jwt, handlers.GetReportSchedule)        // Get report schedule endpoint
+	app.Put("/report-schedules/:id", writeLimit, jwt, handlers.UpdateReportSchedule)    // Update report schedule endpoint
+	app.Delete("/report-schedules/:id", writeLimit, jwt, handlers.DeleteReportSchedule) // Delete report schedule endpoint
+
+	// Consumption of the tasks and webhooks quotas
+	app.Get("/usage", readLimit, jwt, handlers.GetUsage) // Usage endpoint
+
+	// Read-only guests of projects
+	app.Post("/projects/:id/invitations", writeLimit, jwt, handlers.InviteGuest)                      // Invite guest endpoint
+	app.Get("/projects/:id/invitations", readLimit, jwt, handlers.GetInvitations)                     // List invitations endpoint
+	app.Delete("/projects/:id/invitations/:invitationId", writeLimit, jwt, handlers.RevokeInvitation) // Revoke invitation endpoint
+	app.Post("/invitations/:token/accept", authLimit, handlers.AcceptInvitation(signingSecret))       // Accept invitation endpoint, no account needed
+
+	// Polling triggers and actions for no-code automation platforms such as Zapier and IFTTT, which
+	// authenticate with an API token sent as X-API-Key
+	integrations := app.Group("/integrations", middleware.APIKey, jwt)
+	integrations.Get("/me", readLimit, handlers.GetIntegrationUser)                        // Test the API key
+	integrations.Get("/triggers/new-task", readLimit, handlers.NewTaskTrigger)             // Poll for new tasks
+	integrations.Get("/triggers/task-completed", readLimit, handlers.TaskCompletedTrigger) // Poll for completed tasks
+	integrations.Post("/actions/create-task", writeLimit, handlers.CreateTaskAction)       // Create a task
+	integrations.Post("/actions/complete-task", writeLimit, handlers.CompleteTaskAction)   // Mark a task done
+
+	// CalDAV: native task apps sync the tasks a user owns as VTODOs, with Basic authentication whose
+	// password is an API token
+	app.All("/.well-known/caldav", handlers.CalDAVDiscovery)
+	app.Options(handlers.CalDAVPrefix+"/*", handlers.CalDAVOptions)
+	caldav := app.Group(handlers.CalDAVPrefix, middleware.BasicAPIToken("Tasks"), jwt)
+	caldav.Add("PROPFIND", "/*", readLimit, handlers.CalDAVPropfind)                         // Properties of the principal, calendar home, collection and tasks
+	caldav.Add("REPORT", "/calendars/:username/tasks", readLimit, handlers.CalDAVReport)     // calendar-query and calendar-multiget
+	caldav.Get("/calendars/:username/tasks/:name", readLimit, handlers.GetCalDAVTask)        // Get a task as a VTODO
+	caldav.Put("/calendars/:username/tasks/:name", writeLimit, handlers.PutCalDAVTask)       // Create or replace a task from a VTODO
+	caldav.Delete("/calendars/:username/tasks/:name", writeLimit, handlers.DeleteCalDAVTask) // Delete a task
+
+	// Offline sync: task deltas and tombstones since a cursor, and batches of changes made offline
+	app.Get("/sync", readLimit, jwt, handlers.GetSync(cfg.SyncRetention, cfg.SyncOverlap)) // Changes since a cursor endpoint
+	app.Post("/sync", writeLimit, jwt, handlers.PostSync)                                  // Apply offline changes endpoint
+
+	// Kanban board endpoints
+	app.Get("/boards/:projectId", readLimit, guest, handlers.GetBoard)      // Get project board endpoint
+	app.Post("/boards/:projectId/move", writeLimit, jwt, handlers.MoveCard) // Move board card endpoint
+
+	// Search endpoint
+	app.Get("/search", readLimit, jwt, handlers.Search) // Search tasks and projects endpoint
+
+	// Workspace endpoints
+	app.Post("/workspaces", jwt, handlers.CreateWorkspace)                     // Create workspace endpoint
+	app.Get("/workspaces/:id", jwt, handlers.GetWorkspace)                     // Get workspace endpoint
+	app.Put("/workspaces/:id/branding", jwt, handlers.UpdateWorkspaceBranding) // Update workspace branding endpoint
+	app.Get("/workspaces/:id/plan", jwt, handlers.GetWorkspacePlan)            // Workspace plan and entitlements endpoint
+	app.Post("/workspaces/:id/invitations", jwt, handlers.InviteToWorkspace)   // Invite workspace member endpoint
+	app.Post("/workspaces/:id/join", jwt, handlers.JoinWorkspace)              // Join workspace endpoint
+	app.Post("/workspaces/:id/leave", jwt, handlers.LeaveWorkspace)            // Leave workspace endpoint
+	app.Get("/workspaces/:id/members", jwt, handlers.GetWorkspaceMembers)      // List workspace members endpoint
+
+	// Stripe reports checkouts and subscription changes, which move workspaces between plans
+	if cfg.Stripe != nil {
+		app.Post("/billing/stripe/webhook", handlers.StripeWebhook(cfg.Stripe)) // Stripe events endpoint, authenticated with STRIPE_WEBHOOK_SECRET
+	}
+
+	// Cache metrics endpoint
+	app.Get("/metrics/cache", jwt, handlers.CacheStats)
+
+	// Admin endpoints, restricted to users with the admin role
+	admin := app.Group("/admin", jwt, middleware.RequireScope(utils.ScopeAdmin), middleware.AdminOnly)
+	admin.Get("/dead-letters", handlers.ListDeadLetters)                            // List dead letters
+	admin.Get("/dead-letters/:id", handlers.GetDeadLetter)                          // Inspect a dead letter
+	admin.Put("/dead-letters/:id", handlers.UpdateDeadLetter)                       // Edit a dead letter payload
+	admin.Post("/dead-letters/:id/replay", handlers.ReplayDeadLetter)               // Replay a dead letter
+	admin.Post("/dead-letters/:id/discard", handlers.DiscardDeadLetter)             // Discard a dead letter
+	admin.Post("/drain", handlers.Drain(cfg.DrainGrace))                            // Start connection draining
+	admin.Get("/config", handlers.GetConfig(reloader.Effective))                    // Show the settings in effect
+	admin.Get("/maintenance", handlers.GetMaintenance)                              // Get maintenance mode
+	admin.Put("/maintenance", handlers.UpdateMaintenance)                           // Switch maintenance mode
+	admin.Post("/tasks/summary/rebuild", handlers.RebuildTaskListView)              // Rebuild the task list view
+	admin.Get("/backup", handlers.GetBackup)                                        // Download a backup archive
+	admin.Post("/invites", handlers.CreateInviteCode)                               // Create an invite code for sign-up
+	admin.Get("/invites", handlers.GetInviteCodes)                                  // List invite codes
+	admin.Delete("/invites/:id", handlers.RevokeInviteCode)                         // Revoke an invite code
+	admin.Get("/retention", handlers.GetRetention(cfg.Retention, s.auditSink))      // Dry run of the retention policy
+	admin.Post("/retention", handlers.EnforceRetention(cfg.Retention, s.auditSink)) // Enforce the retention policy now
+
+	// Webhooks receiving domain events, managed by admins
+	hooks := app.Group("/webhooks", jwt, middleware.RequireScope(utils.ScopeAdmin), middleware.AdminOnly)
+	hooks.Post("/", handlers.CreateWebhook)                                        // Register a webhook
+	hooks.Get("/", handlers.GetWebhooks)                                           // List webhooks
+	hooks.Delete("/:id", handlers.DeleteWebhook)                                   // Delete a webhook
+	hooks.Get("/:id/deliveries", handlers.GetWebhookDeliveries)                    // List the deliveries with their attempts
+	hooks.Post("/:id/deliveries/:deliveryId/redeliver", handlers.RedeliverWebhook) // Send a delivery again
+
+	// Profiling and runtime diagnostics for admins, e.g. /admin/debug/pprof/goroutine?debug=2 for a
+	// goroutine dump
+	if cfg.DebugEndpoints {
+		admin.Use(pprof.New(pprof.Config{Prefix: "/admin"})) // pprof profiles under /admin/debug/pprof/
+		admin.Get("/debug/runtime", handlers.GetRuntimeInfo) // Build version and runtime statistics
+	}
+
+	return app
+}
@@ -0,0 +1,1446 @@
+// app_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/database"
+	"github.com/bkojha74/task-management/environment"
+	"github.com/bkojha74/task-management/features"
+	"github.com/bkojha74/task-management/githubsync"
+	"github.com/bkojha74/task-management/logging"
+	"github.com/bkojha74/task-management/middleware"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/notifications"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/saml"
+	"github.com/bkojha74/task-management/signup"
+	"github.com/bkojha74/task-management/telegram"
+	"github.com/bkojha74/task-management/utils"
+	"github.com/bkojha74/task-management/webhooks"
+
+	"github.com/beevik/etree"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// testConfig returns settings for an app served in-process, without request logging.
+func testConfig() Config {
+	return Config{
+		JWTSecret:   "test-secret",
+		TokenExpiry: 5,
+		BodyLimit:   middleware.DefaultBodyLimit,
+		AuthLimit:   middleware.RateLimitTier{Name: "auth", Max: 3, Window: time.Hour},
+		ReadLimit:   middleware.RateLimitTier{Name: "read"},
+		WriteLimit:  middleware.RateLimitTier{Name: "write"},
+		DrainGrace:  time.Second,
+	}
+}
+
+// createUser stores a user and returns a token signed for it with the secret of testConfig.
+func createUser(t *testing.T, user models.User) string {
+	require.NoError(t, repository.Users.Create(context.Background(), &user))
+	claims := utils.Tokens.NewClaims(user.ID.Hex(), "", time.Now(), time.Now().Add(time.Minute))
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testConfig().JWTSecret))
+	require.NoError(t, err)
+	return token
+}
+
+// TestNewApp tests the routes of the built app end to end through app.Test
+func TestNewApp(t *testing.T) {
+	repository.UseMemory()
+	app := NewApp(testConfig())
+
+	send := func(method, path string, body interface{}, token string) (int, []byte) {
+		req := httptest.NewRequest(method, path, nil)
+		if body != nil {
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+			req = httptest.NewRequest(method, path, bytes.NewReader(data))
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Authorization", token)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf := new(bytes.Buffer)
+		_, err = buf.ReadFrom(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, buf.Bytes()
+	}
+
+	// Assert that the liveness probe answers without authentication
+	status, _ := send(fiber.MethodGet, "/healthz", nil, "")
+	assert.Equal(t, fiber.StatusOK, status)
+
+	// Assert that the build metadata is public
+	status, body := send(fiber.MethodGet, "/version", nil, "")
+	require.Equal(t, fiber.StatusOK, status)
+	var version map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &version))
+	assert.Equal(t, "dev", version["version"])
+	assert.NotEmpty(t, version["go_version"])
+
+	// Assert that a user can sign up, sign in and use the token on the task routes
+	user := models.User{Username: "alice", Password: "secret"}
+	status, _ = send(fiber.MethodPost, "/signup", user, "")
+	require.Equal(t, fiber.StatusCreated, status)
+
+	status, body = send(fiber.MethodPost, "/signin", user, "")
+	require.Equal(t, fiber.StatusOK, status)
+	var tokenResp struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(body, &tokenResp))
+	token := tokenResp.Token
+
+	status, _ = send(fiber.MethodPost, "/tasks", models.Task{Title: "Write tests", AllottedTo: "alice"}, token)
+	assert.Equal(t, fiber.StatusCreated, status)
+
+	status, body = send(fiber.MethodGet, "/tasks", nil, token)
+	assert.Equal(t, fiber.StatusOK, status)
+	var tasks []models.Task
+	require.NoError(t, json.Unmarshal(body, &tasks))
+	assert.Len(t, tasks, 1)
+
+	// Assert that task routes require a token and admin routes require the admin role
+	status, _ = send(fiber.MethodGet, "/tasks", nil, "")
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	status, _ = send(fiber.MethodGet, "/admin/dead-letters", nil, token)
+	assert.Equal(t, fiber.StatusForbidden, status)
+
+	// Assert that the configured auth rate limit applies (two requests of the budget of three are spent)
+	status, _ = send(fiber.MethodPost, "/signin", user, "")
+	assert.Equal(t, fiber.StatusOK, status)
+	status, _ = send(fiber.MethodPost, "/signin", user, "")
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+}
+
+// TestConfigValidate tests that a secret is required and the CORS (stricter in production), reporting, proxy and rate limit settings are checked
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, testConfig().Validate())
+
+	config := testConfig()
+	config.JWTSecret = ""
+	assert.Error(t, config.Validate())
+
+	config = testConfig()
+	config.CORS = middleware.CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true}
+	assert.Error(t, config.Validate())
+
+	config = testConfig()
+	config.CORS = middleware.CORSConfig{AllowOrigins: []string{"*"}}
+	assert.NoError(t, config.Validate())
+	config.Environment = environment.Production
+	assert.Error(t, config.Validate())
+
+	config = testConfig()
+	config.Reporting = database.ReportingConfig{ReadPreference: "secondaryPreferred", MaxStaleness: 2 * time.Minute}
+	assert.NoError(t, config.Validate())
+	config.Reporting.MaxStaleness = 10 * time.Second
+	assert.Error(t, config.Validate())
+	config.Reporting = database.ReportingConfig{ReadPreference: "secondaries"}
+	assert.Error(t, config.Validate())
+
+	config = testConfig()
+	config.DisconnectCheck = -time.Millisecond
+	assert.Error(t, config.Validate())
+
+	config = testConfig()
+	config.Proxy = middleware.ProxyConfig{Header: "X-Forwarded-For"}
+	assert.Error(t, config.Validate())
+
+	config = testConfig()
+	config.AuthLimit.Exempt = []string{"10.0.0.0/40"}
+	assert.Error(t, config.Validate())
+}
+
+// TestNew tests building the app from options
+func TestNew(t *testing.T) {
+	// Assert that a storage backend is required
+	_, err := New(WithConfig(testConfig()))
+	assert.ErrorIs(t, err, ErrNoStorage)
+
+	// Assert that invalid settings are rejected
+	invalid := testConfig()
+	invalid.JWTSecret = ""
+	_, err = New(WithConfig(invalid), WithMemoryStorage())
+	assert.Error(t, err)
+
+	var sent []notifications.Notification
+	notifier := notifications.NotifierFunc(func(ctx context.Context, notification notifications.Notification) error {
+		sent = append(sent, notification)
+		return nil
+	})
+	tagged := func(c *fiber.Ctx) error {
+		c.Set("X-Embedded", "yes")
+		return c.Next()
+	}
+
+	app, err := New(WithConfig(testConfig()), WithMemoryStorage(), WithMiddleware(tagged), WithNotifier(notifier))
+	require.NoError(t, err)
+	defer func() { notifications.Default = notifications.Nop{} }()
+
+	// Assert that extra middlewares run before the routes
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/healthz", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, "yes", resp.Header.Get("X-Embedded"))
+
+	// Assert that the notifier receives the assignment of a new task
+	user := models.User{Username: "bob", Password: "hash"}
+	require.NoError(t, repository.Users.Create(context.Background(), &user))
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, utils.Tokens.NewClaims(user.ID.Hex(), "", time.Now(), time.Now().Add(time.Minute)))
+	signed, err := token.SignedString([]byte(testConfig().JWTSecret))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(fiber.MethodPost, "/tasks", bytes.NewReader([]byte(`{"title":"Review","allotted_to":"bob"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", signed)
+	resp, err = app.Test(req, -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	require.Len(t, sent, 1)
+	assert.Equal(t, notifications.EventTaskAssigned, sent[0].Event)
+	assert.Equal(t, "bob", sent[0].Recipient)
+}
+
+// TestMaintenanceMode tests that an admin can take the API into read-only and full maintenance and back
+func TestMaintenanceMode(t *testing.T) {
+	repository.UseMemory()
+	app := NewApp(testConfig())
+
+	token := createUser(t, models.User{Username: "root", Password: "hash", Role: models.RoleAdmin})
+
+	send := func(method, path, body string) *http.Response {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Authorization", token)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Assert that read-only mode rejects writes with Retry-After but serves reads
+	resp := send(fiber.MethodPut, "/admin/maintenance", `{"mode":"read-only","retry_after":120}`)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp = send(fiber.MethodPost, "/tasks", `{"title":"Blocked","allotted_to":"root"}`)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "120", resp.Header.Get(fiber.HeaderRetryAfter))
+	assert.Equal(t, fiber.StatusOK, send(fiber.MethodGet, "/tasks", "").StatusCode)
+
+	// Assert that full mode also rejects reads but keeps the probes and admin endpoints
+	require.Equal(t, fiber.StatusOK, send(fiber.MethodPut, "/admin/maintenance", `{"mode":"full"}`).StatusCode)
+	assert.Equal(t, fiber.StatusServiceUnavailable, send(fiber.MethodGet, "/tasks", "").StatusCode)
+	assert.Equal(t, fiber.StatusOK, send(fiber.MethodGet, "/healthz", "").StatusCode)
+	assert.Equal(t, fiber.StatusOK, send(fiber.MethodGet, "/admin/maintenance", "").StatusCode)
+
+	// Assert that unknown modes are rejected and switching off serves writes again
+	assert.Equal(t, fiber.StatusBadRequest, send(fiber.MethodPut, "/admin/maintenance", `{"mode":"partial"}`).StatusCode)
+	require.Equal(t, fiber.StatusOK, send(fiber.MethodPut, "/admin/maintenance", `{"mode":"off"}`).StatusCode)
+	assert.Equal(t, fiber.StatusCreated, send(fiber.MethodPost, "/tasks", `{"title":"Allowed","allotted_to":"root"}`).StatusCode)
+}
+
+// TestInviteOnlySignUp tests that an admin can issue invite codes and only they let people sign up while
+// registration is invitation-only
+func TestInviteOnlySignUp(t *testing.T) {
+	repository.UseMemory()
+	signup.Default = signup.Policy{Mode: signup.ModeInvite}
+	defer func() { signup.Default = signup.Policy{} }()
+	config := testConfig()
+	config.AuthLimit.Max = 20
+	app := NewApp(config)
+
+	admin := createUser(t, models.User{Username: "root", Password: "hash", Role: models.RoleAdmin})
+	member := createUser(t, models.User{Username: "carol", Password: "hash"})
+
+	send := func(method, path, body, token string) *http.Response {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Authorization", token)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return resp
+	}
+	signUp := func(username, code string) int {
+		return send(fiber.MethodPost, "/signup", `{"username":"`+username+`","password":"secret","invite_code":"`+code+`"}`, "").StatusCode
+	}
+
+	// Assert that only admins can issue invite codes
+	assert.Equal(t, fiber.StatusForbidden, send(fiber.MethodPost, "/admin/invites", `{}`, member).StatusCode)
+	assert.Equal(t, fiber.StatusBadRequest, send(fiber.MethodPost, "/admin/invites", `{"max_uses":-1}`, admin).StatusCode)
+	resp := send(fiber.MethodPost, "/admin/invites", `{"max_uses":2,"expires_in":"24h","note":"Design team"}`, admin)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var invite struct {
+		ID   string `json:"id"`
+		Code string `json:"code"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&invite))
+	require.NotEmpty(t, invite.Code)
+
+	// Assert that sign-up needs a valid code, which works as often as allowed, however it is typed
+	assert.Equal(t, fiber.StatusBadRequest, signUp("dave", ""))
+	assert.Equal(t, fiber.StatusBadRequest, signUp("dave", "AAAA-BBBB-CCCC-DDDD"))
+	assert.Equal(t, fiber.StatusCreated, signUp("dave", invite.Code))
+	assert.Equal(t, fiber.StatusBadRequest, signUp("dave", invite.Code)) // Taken usernames don't use the code up
+	assert.Equal(t, fiber.StatusCreated, signUp("erin", strings.ToLower(invite.Code)))
+	assert.Equal(t, fiber.StatusBadRequest, signUp("frank", invite.Code))
+
+	// Assert that the list shows the uses but never the code, and revoked codes stop working
+	resp = send(fiber.MethodGet, "/admin/invites", "", admin)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var codes []map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&codes))
+	require.Len(t, codes, 1)
+	assert.Equal(t, float64(2), codes[0]["uses"])
+	assert.NotContains(t, codes[0], "code")
+	assert.NotContains(t, codes[0], "code_hash")
+
+	resp = send(fiber.MethodPost, "/admin/invites", "", admin)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&invite))
+	assert.Equal(t, fiber.StatusNoContent, send(fiber.MethodDelete, "/admin/invites/"+invite.ID, "", admin).StatusCode)
+	assert.Equal(t, fiber.StatusNotFound, send(fiber.MethodDelete, "/admin/invites/"+invite.ID, "", admin).StatusCode)
+	assert.Equal(t, fiber.StatusBadRequest, signUp("frank", invite.Code))
+}
+
+// TestSAMLRoutes tests that single sign-on is only served when configured, and accepts posted forms
+func TestSAMLRoutes(t *testing.T) {
+	repository.UseMemory()
+	resp, err := NewApp(testConfig()).Test(httptest.NewRequest(fiber.MethodGet, "/saml/metadata", nil))
+	require.NoError(t, err)
+	// Assert that the routes are absent without SAML settings
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	config := testConfig()
+	config.SAML = &saml.ServiceProvider{
+		EntityID:    "https://tasks.example.com/saml/metadata",
+		ACSURL:      "https://tasks.example.com/saml/acs",
+		IdPEntityID: "https://idp.example.com",
+		IdPSSOURL:   "https://idp.example.com/sso",
+	}
+	app := NewApp(config)
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/saml/metadata", nil))
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	// Assert that the metadata names the assertion consumer service
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `Location="https://tasks.example.com/saml/acs"`)
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/saml/login", nil))
+	require.NoError(t, err)
+	// Assert that login redirects to the identity provider with a signed request token as relay state
+	require.Equal(t, fiber.StatusFound, resp.StatusCode)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "idp.example.com", location.Host)
+	assert.NotEmpty(t, location.Query().Get("SAMLRequest"))
+	request, err := utils.ParseSAMLRequestToken(utils.NewSigningSecret(config.JWTSecret), location.Query().Get("RelayState"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, request.RequestID)
+
+	// Assert that the assertion consumer service takes the form, and rejects responses it cannot verify
+	form := url.Values{"SAMLResponse": {"PHJlc3BvbnNlLz4="}, "RelayState": {location.Query().Get("RelayState")}}
+	req := httptest.NewRequest(fiber.MethodPost, "/saml/acs", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+
+	form.Set("RelayState", "forged")
+	req = httptest.NewRequest(fiber.MethodPost, "/saml/acs", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+}
+
+// samlIdP is the key and certificate of a test identity provider, as goxmldsig's key store.
+type samlIdP struct {
+	key         *rsa.PrivateKey
+	certificate *x509.Certificate
+}
+
+// GetKeyPair returns the key and certificate of the identity provider.
+func (idp samlIdP) GetKeyPair() (*rsa.PrivateKey, []byte, error) {
+	return idp.key, idp.certificate.Raw, nil
+}
+
+// newSAMLIdP generates the key and self-signed certificate of a test identity provider.
+func newSAMLIdP(t *testing.T) samlIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return samlIdP{key: key, certificate: certificate}
+}
+
+// response returns a base64-encoded response to the request with a signed assertion about nameID, with the
+// local part of nameID as uid attribute.
+func (idp samlIdP) response(t *testing.T, sp *saml.ServiceProvider, requestID, assertionID, nameID string) string {
+	now := time.Now().UTC()
+	notOnOrAfter := now.Add(5 * time.Minute).Format(time.RFC3339)
+	assertion := etree.NewDocument()
+	require.NoError(t, assertion.ReadFromString(`<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="`+assertionID+`" Version="2.0" IssueInstant="`+now.Format(time.RFC3339)+`">`+
+		`<saml:Issuer>`+sp.IdPEntityID+`</saml:Issuer><saml:Subject><saml:NameID>`+nameID+`</saml:NameID>`+
+		`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer"><saml:SubjectConfirmationData InResponseTo="`+requestID+`"`+
+		` NotOnOrAfter="`+notOnOrAfter+`" Recipient="`+sp.ACSURL+`"/></saml:SubjectConfirmation></saml:Subject>`+
+		`<saml:Conditions NotBefore="`+now.Add(-time.Minute).Format(time.RFC3339)+`" NotOnOrAfter="`+notOnOrAfter+`">`+
+		`<saml:AudienceRestriction><saml:Audience>`+sp.EntityID+`</saml:Audience></saml:AudienceRestriction></saml:Conditions>`+
+		`<saml:AttributeStatement><saml:Attribute Name="uid"><saml:AttributeValue>`+strings.Split(nameID, "@")[0]+`</saml:AttributeValue></saml:Attribute></saml:AttributeStatement></saml:Assertion>`))
+	signing := dsig.NewDefaultSigningContext(idp)
+	signing.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+	signed, err := signing.SignEnveloped(assertion.Root())
+	require.NoError(t, err)
+	assertion.SetRoot(signed)
+	signedXML, err := assertion.WriteToString()
+	require.NoError(t, err)
+
+	response := `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" ID="r` + assertionID + `" Version="2.0" InResponseTo="` + requestID + `">` +
+		`<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>` + signedXML + `</samlp:Response>`
+	return base64.StdEncoding.EncodeToString([]byte(response))
+}
+
+// TestSAMLSignIn tests that a signed response signs the browser that started sign-in in, once
+func TestSAMLSignIn(t *testing.T) {
+	repository.UseMemory()
+	idp := newSAMLIdP(t)
+	config := testConfig()
+	config.AuthLimit.Max = 20
+	config.SAML = &saml.ServiceProvider{
+		EntityID:        "https://tasks.example.com/saml/metadata",
+		ACSURL:          "https://tasks.example.com/saml/acs",
+		IdPEntityID:     "https://idp.example.com",
+		IdPSSOURL:       "https://idp.example.com/sso",
+		IdPCertificates: []*x509.Certificate{idp.certificate},
+		ClockSkew:       time.Minute,
+	}
+	app := NewApp(config)
+
+	login := func() (string, string, *http.Cookie) {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/saml/login", nil))
+		require.NoError(t, err)
+		location, err := url.Parse(resp.Header.Get("Location"))
+		require.NoError(t, err)
+		relayState := location.Query().Get("RelayState")
+		request, err := utils.ParseSAMLRequestToken(utils.NewSigningSecret(config.JWTSecret), relayState)
+		require.NoError(t, err)
+		for _, cookie := range resp.Cookies() {
+			if cookie.Name == "saml_nonce" {
+				return request.RequestID, relayState, cookie
+			}
+		}
+		t.Fatal("no nonce cookie")
+		return "", "", nil
+	}
+	acs := func(samlResponse, relayState string, cookie *http.Cookie) int {
+		form := url.Values{"SAMLResponse": {samlResponse}, "RelayState": {relayState}}
+		req := httptest.NewRequest(fiber.MethodPost, "/saml/acs", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	requestID, relayState, cookie := login()
+	// Assert that the nonce cookie is sent along with the identity provider's cross-site post
+	assert.True(t, cookie.Secure && cookie.HttpOnly)
+	assert.Equal(t, http.SameSiteNoneMode, cookie.SameSite)
+	samlResponse := idp.response(t, config.SAML, requestID, "a1", "alice@example.com")
+
+	// Assert that the response is only accepted from the browser that started sign-in
+	assert.Equal(t, fiber.StatusUnauthorized, acs(samlResponse, relayState, nil))
+	_, _, otherBrowser := login()
+	assert.Equal(t, fiber.StatusUnauthorized, acs(samlResponse, relayState, otherBrowser))
+	assert.Equal(t, fiber.StatusOK, acs(samlResponse, relayState, cookie))
+
+	// Assert that neither the response nor its assertion can be replayed
+	assert.Equal(t, fiber.StatusUnauthorized, acs(samlResponse, relayState, cookie))
+	requestID, relayState, cookie = login()
+	assert.Equal(t, fiber.StatusUnauthorized, acs(idp.response(t, config.SAML, requestID, "a1", "alice@example.com"), relayState, cookie))
+	requestID, relayState, cookie = login()
+	assert.Equal(t, fiber.StatusOK, acs(idp.response(t, config.SAML, requestID, "a2", "alice@example.com"), relayState, cookie))
+}
+
+// TestSAMLAccountLinking tests that SAML users sign in to the account linked to their NameID, and cannot take
+// over a local account of the same username
+func TestSAMLAccountLinking(t *testing.T) {
+	repository.UseMemory()
+	idp := newSAMLIdP(t)
+	config := testConfig()
+	config.AuthLimit.Max = 20
+	config.SAML = &saml.ServiceProvider{
+		EntityID:          "https://tasks.example.com/saml/metadata",
+		ACSURL:            "https://tasks.example.com/saml/acs",
+		IdPEntityID:       "https://idp.example.com",
+		IdPSSOURL:         "https://idp.example.com/sso",
+		IdPCertificates:   []*x509.Certificate{idp.certificate},
+		UsernameAttribute: "uid",
+		ClockSkew:         time.Minute,
+	}
+	app := NewApp(config)
+
+	signIns := 0
+	signIn := func(nameID string) int {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/saml/login", nil))
+		require.NoError(t, err)
+		location, err := url.Parse(resp.Header.Get("Location"))
+		require.NoError(t, err)
+		relayState := location.Query().Get("RelayState")
+		request, err := utils.ParseSAMLRequestToken(utils.NewSigningSecret(config.JWTSecret), relayState)
+		require.NoError(t, err)
+
+		// The username is taken from the NameID's local part, as a uid attribute would be
+		signIns++
+		samlResponse := idp.response(t, config.SAML, request.RequestID, fmt.Sprintf("a%d", signIns), nameID)
+		form := url.Values{"SAMLResponse": {samlResponse}, "RelayState": {relayState}}
+		req := httptest.NewRequest(fiber.MethodPost, "/saml/acs", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, cookie := range resp.Cookies() {
+			req.AddCookie(cookie)
+		}
+		resp, err = app.Test(req, -1)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+	ctx := context.Background()
+	require.NoError(t, repository.Users.Create(ctx, &models.User{Username: "admin", Password: utils.HashPassword("secret"), Role: models.RoleAdmin}))
+	require.NoError(t, repository.Users.Create(ctx, &models.User{Username: "bob", ExternalID: "00u2"}))
+
+	// Assert that an asserted username of a local account with a password does not sign in to it
+	assert.Equal(t, fiber.StatusConflict, signIn("admin@example.com"))
+	admin, err := repository.Users.FindByUsername(ctx, "admin")
+	require.NoError(t, err)
+	assert.Empty(t, admin.Subject)
+
+	// Assert that an account without a password, e.g. provisioned by SCIM, is linked on first sign-in
+	assert.Equal(t, fiber.StatusOK, signIn("bob@example.com"))
+	bob, err := repository.Users.FindByUsername(ctx, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, "saml|https://idp.example.com|bob@example.com", bob.Subject)
+
+	// Assert that a new user gets an account linked to their NameID, which a second NameID cannot take
+	assert.Equal(t, fiber.StatusOK, signIn("carol@example.com"))
+	carol, err := repository.Users.FindBySubject(ctx, "saml|https://idp.example.com|carol@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "carol", carol.Username)
+	assert.Equal(t, fiber.StatusConflict, signIn("carol@other.example.com"))
+
+	// Assert that an explicitly linked account signs in
+	admin.Subject = "saml|https://idp.example.com|admin@example.com"
+	require.NoError(t, repository.Users.Update(ctx, admin))
+	assert.Equal(t, fiber.StatusOK, signIn("admin@example.com"))
+}
+
+// TestSCIMProvisioning tests that an identity provider can create, look up, update and deactivate users
+func TestSCIMProvisioning(t *testing.T) {
+	repository.UseMemory()
+	config := testConfig()
+	config.AuthLimit.Max = 20
+	config.SCIMToken = strings.Repeat("s", 32)
+	app := NewApp(config)
+
+	send := func(method, path, body, authorization string) *http.Response {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/scim+json")
+		}
+		req.Header.Set("Authorization", authorization)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return resp
+	}
+	scim := func(method, path, body string) *http.Response {
+		return send(method, "/scim/v2"+path, body, "Bearer "+config.SCIMToken)
+	}
+	decode := func(resp *http.Response) map[string]interface{} {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return body
+	}
+	signIn := func() *http.Response {
+		req := httptest.NewRequest(fiber.MethodPost, "/signin", strings.NewReader(`{"username":"alice","password":"secret"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Assert that the SCIM API requires the token
+	assert.Equal(t, fiber.StatusUnauthorized, send(fiber.MethodGet, "/scim/v2/Users", "", "Bearer wrong").StatusCode)
+	assert.Equal(t, fiber.StatusOK, scim(fiber.MethodGet, "/ServiceProviderConfig", "").StatusCode)
+
+	alice := `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"alice","externalId":"00u1",` +
+		`"name":{"givenName":"Alice","familyName":"Liddell"},"emails":[{"value":"alice@example.com","primary":true}],` +
+		`"password":"secret","active":true}`
+	resp := scim(fiber.MethodPost, "/Users", alice)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "application/scim+json", resp.Header.Get("Content-Type"))
+	created := decode(resp)
+	id := created["id"].(string)
+	// Assert that the attributes are mapped onto the user and duplicates are conflicts
+	assert.Equal(t, "Alice Liddell", created["displayName"])
+	assert.Equal(t, "00u1", created["externalId"])
+	assert.NotContains(t, created, "password")
+	assert.Equal(t, fiber.StatusConflict, scim(fiber.MethodPost, "/Users", alice).StatusCode)
+
+	// Assert that users are found by userName and unsupported filters are rejected
+	list := decode(scim(fiber.MethodGet, `/Users?filter=`+url.QueryEscape(`userName eq "alice"`), ""))
+	assert.Equal(t, float64(1), list["totalResults"])
+	list = decode(scim(fiber.MethodGet, `/Users?filter=`+url.QueryEscape(`userName eq "bob"`), ""))
+	assert.Equal(t, float64(0), list["totalResults"])
+	assert.Equal(t, fiber.StatusBadRequest, scim(fiber.MethodGet, `/Users?filter=`+url.QueryEscape(`title eq "x"`), "").StatusCode)
+
+	resp = signIn()
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	token := decode(resp)["token"].(string)
+	assert.Equal(t, fiber.StatusOK, send(fiber.MethodGet, "/users/me/timezone", "", token).StatusCode)
+
+	// Assert that deactivation ends the sessions and blocks sign-in, also with "False" as some IdPs send it
+	resp = scim(fiber.MethodPatch, "/Users/"+id, `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"Replace","path":"active","value":"False"}]}`)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, false, decode(resp)["active"])
+	assert.Equal(t, fiber.StatusUnauthorized, send(fiber.MethodGet, "/users/me/timezone", "", token).StatusCode)
+	assert.Equal(t, fiber.StatusForbidden, signIn().StatusCode)
+
+	// Assert that reactivated users can sign in again, and DELETE only deactivates
+	resp = scim(fiber.MethodPatch, "/Users/"+id, `{"Operations":[{"op":"replace","value":{"active":true,"displayName":"Alice L."}}]}`)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Alice L.", decode(resp)["displayName"])
+	assert.Equal(t, fiber.StatusOK, signIn().StatusCode)
+	assert.Equal(t, fiber.StatusNoContent, scim(fiber.MethodDelete, "/Users/"+id, "").StatusCode)
+	assert.Equal(t, false, decode(scim(fiber.MethodGet, "/Users/"+id, ""))["active"])
+	assert.Equal(t, fiber.StatusNotFound, scim(fiber.MethodGet, "/Users/"+primitive.NewObjectID().Hex(), "").StatusCode)
+}
+
+// TestAPITokens tests that API tokens are limited to their scopes and can be revoked
+func TestAPITokens(t *testing.T) {
+	repository.UseMemory()
+	app := NewApp(testConfig())
+	admin := createUser(t, models.User{Username: "root", Password: "hash", Role: models.RoleAdmin})
+	member := createUser(t, models.User{Username: "carol", Password: "hash"})
+
+	send := func(method, path, body, token string) *http.Response {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Authorization", token)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return resp
+	}
+	create := func(token, body string) (int, string, string) {
+		resp := send(fiber.MethodPost, "/users/me/tokens", body, token)
+		var created struct {
+			ID    string `json:"id"`
+			Token string `json:"token"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&created)
+		return resp.StatusCode, created.ID, created.Token
+	}
+	task := `{"title":"Nightly build","allotted_to":"root"}`
+
+	// Assert that scopes are validated and only admins get the admin scope
+	status, _, _ := create(member, `{"name":"ci","scopes":["tasks:delete"]}`)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	status, _, _ = create(member, `{"name":"ci","scopes":["admin"]}`)
+	assert.Equal(t, fiber.StatusForbidden, status)
+
+	// Assert that a read-only token can read but not write, nor manage the account
+	status, id, readOnly := create(member, `{"name":"ci","scopes":["tasks:read"],"expires_in":"24h"}`)
+	require.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, fiber.StatusOK, send(fiber.MethodGet, "/tasks", "", readOnly).StatusCode)
+	assert.Equal(t, fiber.StatusForbidden, send(fiber.MethodPost, "/tasks", task, readOnly).StatusCode)
+	status, _, _ = create(readOnly, `{"name":"more","scopes":["tasks:write"]}`)
+	assert.Equal(t, fiber.StatusForbidden, status)
+	assert.Equal(t, fiber.StatusForbidden, send(fiber.MethodDelete, "/users/me", "", readOnly).StatusCode)
+
+	// Assert that write tokens can write, and admin endpoints need the admin scope even for admins
+	_, _, writer := create(admin, `{"name":"bot","scopes":["tasks:write"]}`)
+	assert.Equal(t, fiber.StatusCreated, send(fiber.MethodPost, "/tasks", task, writer).StatusCode)
+	assert.Equal(t, fiber.StatusForbidden, send(fiber.MethodGet, "/admin/config", "", writer).StatusCode)
+	_, _, adminToken := create(admin, `{"name":"ops","scopes":["admin"]}`)
+	assert.Equal(t, fiber.StatusOK, send(fiber.MethodGet, "/admin/config", "", adminToken).StatusCode)
+
+	// Assert that tokens are revoked like sessions
+	assert.Equal(t, fiber.StatusNoContent, send(fiber.MethodDelete, "/users/me/sessions/"+id, "", member).StatusCode)
+	assert.Equal(t, fiber.StatusUnauthorized, send(fiber.MethodGet, "/tasks", "", readOnly).StatusCode)
+}
+
+// TestWebhooks tests that admins register webhooks, see their deliveries and redeliver them signed
+func TestWebhooks(t *testing.T) {
+	repository.UseMemory()
+	app := NewApp(testConfig())
+	admin := createUser(t, models.User{Username: "root", Password: "hash", Role: models.RoleAdmin})
+	member := createUser(t, models.User{Username: "carol", Password: "hash"})
+
+	var received http.Header
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer receiver.Close()
+
+	send := func(method, path, body, token string) *http.Response {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Authorization", token)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Assert that only admins register webhooks, for known events
+	hook := `{"url":"` + receiver.URL + `","events":["task.created"]}`
+	assert.Equal(t, fiber.StatusForbidden, send(fiber.MethodPost, "/webhooks", hook, member).StatusCode)
+	assert.Equal(t, fiber.StatusBadRequest, send(fiber.MethodPost, "/webhooks", `{"url":"`+receiver.URL+`","events":["task.deleted"]}`, admin).StatusCode)
+	assert.Equal(t, fiber.StatusBadRequest, send(fiber.MethodPost, "/webhooks", `{"url":"ftp://example.com"}`, admin).StatusCode)
+
+	resp := send(fiber.MethodPost, "/webhooks", hook, admin)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var created struct {
+		ID     string `json:"id"`
+		Secret string `json:"secret"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	assert.NotEmpty(t, created.Secret)
+
+	// Assert that the secret is not listed
+	resp = send(fiber.MethodGet, "/webhooks", "", admin)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), created.ID)
+	assert.NotContains(t, string(body), created.Secret)
+
+	// Assert that a recorded delivery is redelivered with the same delivery ID and a valid signature
+	webhookId, _ := primitive.ObjectIDFromHex(created.ID)
+	delivery := models.WebhookDelivery{WebhookID: webhookId, Event: "task.created", Payload: `{"type":"task.created"}`, Status: models.WebhookDeliveryFailed}
+	require.NoError(t, repository.WebhookDeliveries.Create(context.Background(), &delivery))
+	path := "/webhooks/" + created.ID + "/deliveries"
+	resp = send(fiber.MethodPost, path+"/"+delivery.ID.Hex()+"/redeliver", "", admin)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, delivery.ID.Hex(), received.Get(webhooks.HeaderDelivery))
+	assert.NoError(t, webhooks.Verify(created.Secret, received, []byte(delivery.Payload), time.Now(), time.Minute))
+
+	resp = send(fiber.MethodGet, path, "", admin)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var deliveries []models.WebhookDelivery
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&deliveries))
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, models.WebhookDeliverySucceeded, deliveries[0].Status)
+	require.Len(t, deliveries[0].Attempts, 1)
+	assert.Equal(t, http.StatusAccepted, deliveries[0].Attempts[0].StatusCode)
+	assert.True(t, deliveries[0].Attempts[0].Manual)
+
+	// Assert that deleting the webhook deletes its deliveries
+	assert.Equal(t, fiber.StatusNoContent, send(fiber.MethodDelete, "/webhooks/"+created.ID, "", admin).StatusCode)
+	assert.Equal(t, fiber.StatusNotFound, send(fiber.MethodGet, path, "", admin).StatusCode)
+}
+
+// TestTelegramBot tests linking a chat with a code, creating and listing tasks by chat command and the webhook secret
+func TestTelegramBot(t *testing.T) {
+	repository.UseMemory()
+
+	var replies []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message struct {
+			ChatID int64  `json:"chat_id"`
+			Text   string `json:"text"`
+		}
+		json.NewDecoder(r.Body).Decode(&message)
+		assert.Equal(t, "/botbot-token/sendMessage", r.URL.Path)
+		assert.Equal(t, int64(4242), message.ChatID)
+		replies = append(replies, message.Text)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer api.Close()
+
+	cfg := testConfig()
+	cfg.Telegram = &telegram.Bot{Token: "bot-token", Username: "tasks_bot", WebhookSecret: strings.Repeat("s", 32), APIURL: api.URL}
+	app := NewApp(cfg)
+	token := createUser(t, models.User{Username: "dave", Password: "hash"})
+
+	update := func(text, secret string) int {
+		body := fmt.Sprintf(`{"update_id":1,"message":{"message_id":1,"chat":{"id":4242,"type":"private"},"text":%q}}`, text)
+		req := httptest.NewRequest(fiber.MethodPost, "/telegram/webhook", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(telegram.SecretHeader, secret)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+	secret := cfg.Telegram.WebhookSecret
+
+	// Assert that updates without the webhook secret are rejected
+	assert.Equal(t, fiber.StatusUnauthorized, update("/tasks", "wrong"))
+	assert.Empty(t, replies)
+
+	// Assert that an unlinked chat cannot create tasks
+	assert.Equal(t, fiber.StatusOK, update("/new Write report", secret))
+	require.Len(t, replies, 1)
+	assert.Contains(t, replies[0], "not linked")
+
+	req := httptest.NewRequest(fiber.MethodPost, "/users/me/telegram", nil)
+	req.Header.Set("Authorization", token)
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var link struct {
+		Code string `json:"code"`
+		URL  string `json:"url"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&link))
+	assert.Equal(t, "https://t.me/tasks_bot?start="+link.Code, link.URL)
+
+	// Assert that a forged code does not link the chat and the issued one does
+	update("/start "+link.Code[:len(link.Code)-2]+"AA", secret)
+	assert.Contains(t, replies[len(replies)-1], "invalid or expired")
+	update("/start "+link.Code, secret)
+	assert.Contains(t, replies[len(replies)-1], "linked to dave")
+	user, err := repository.Users.FindByUsername(context.Background(), "dave")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4242), user.TelegramChat)
+
+	// Assert that tasks are created with a natural language due date and listed
+	update("/new Write report due tomorrow 5pm", secret)
+	assert.Contains(t, replies[len(replies)-1], `Created "Write report", due`)
+	update("/new@tasks_bot Call the due diligence team", secret)
+	assert.Equal(t, `Created "Call the due diligence team".`, replies[len(replies)-1])
+	update("/tasks", secret)
+	assert.Contains(t, replies[len(replies)-1], "Write report (Pending), due")
+	assert.Contains(t, replies[len(replies)-1], "Call the due diligence team (Pending)")
+
+	// Assert that unlinking stops the commands
+	req = httptest.NewRequest(fiber.MethodDelete, "/users/me/telegram", nil)
+	req.Header.Set("Authorization", token)
+	resp, err = app.Test(req, -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	update("/tasks", secret)
+	assert.Contains(t, replies[len(replies)-1], "not linked")
+}
+
+// TestGitHubIntegration tests connecting a GitHub account, creating an issue from a task and the signed webhook
+func TestGitHubIntegration(t *testing.T) {
+	repository.UseMemory()
+
+	var issueState string
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login/oauth/access_token":
+			assert.Equal(t, "the-code", r.FormValue("code"))
+			w.Write([]byte(`{"access_token":"gho_token"}`))
+		case "/user":
+			w.Write([]byte(`{"login":"erin-gh"}`))
+		case "/repos/acme/api/issues":
+			issueState = "open"
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"number":3,"html_url":"https://github.com/acme/api/issues/3","state":"open"}`))
+		case "/repos/acme/api/issues/3":
+			fmt.Fprintf(w, `{"number":3,"state":%q}`, issueState)
+		case "/repos/acme/api/issues/3/comments":
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer github.Close()
+
+	cfg := testConfig()
+	cfg.GitHub = &githubsync.App{ClientID: "client", ClientSecret: "secret", RedirectURL: "https://tasks.example.com/github/callback", WebhookSecret: "webhook-secret-16", WebURL: github.URL, APIURL: github.URL}
+	app := NewApp(cfg)
+	token := createUser(t, models.User{Username: "erin", Password: "hash"})
+
+	send := func(method, path, body, token string, header ...string) *http.Response {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Authorization", token)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Assert that the authorization URL carries a state the callback accepts, and a forged one is rejected
+	resp := send(fiber.MethodPost, "/users/me/github", "", token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var authorization struct {
+		URL string `json:"url"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&authorization))
+	authorize, err := url.Parse(authorization.URL)
+	require.NoError(t, err)
+	state := authorize.Query().Get("state")
+	assert.Equal(t, fiber.StatusUnauthorized, send(fiber.MethodGet, "/github/callback?code=the-code&state=forged", "", "").StatusCode)
+	resp = send(fiber.MethodGet, "/github/callback?code=the-code&state="+state, "", "")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "erin-gh")
+	assert.NotContains(t, string(body), "gho_token")
+
+	// Assert that an issue is created from a task once
+	resp = send(fiber.MethodPost, "/tasks", `{"title":"Fix login","allotted_to":"erin"}`, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var task models.Task
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&task))
+	path := "/tasks/" + task.ID.Hex() + "/github-issue"
+	assert.Equal(t, fiber.StatusBadRequest, send(fiber.MethodPost, path, `{"repo":"not a repo"}`, token).StatusCode)
+	resp = send(fiber.MethodPost, path, `{"repo":"acme/api"}`, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&task))
+	require.NotNil(t, task.GitHubIssue)
+	assert.Equal(t, 3, task.GitHubIssue.Number)
+	assert.Equal(t, fiber.StatusConflict, send(fiber.MethodPost, path, `{"repo":"acme/api"}`, token).StatusCode)
+
+	// Assert that updates keep the link
+	assert.Equal(t, fiber.StatusOK, send(fiber.MethodPut, "/tasks/"+task.ID.Hex(), `{"title":"Fix login","allotted_to":"erin","status":"Pending"}`, token).StatusCode)
+
+	// Assert that unsigned webhook requests are rejected and a closed issue completes the task
+	event := `{"action":"closed","issue":{"number":3},"repository":{"full_name":"Acme/api"}}`
+	assert.Equal(t, fiber.StatusUnauthorized, send(fiber.MethodPost, "/github/webhook", event, "", "X-GitHub-Event", "issues").StatusCode)
+	issueState = "closed"
+	mac := hmac.New(sha256.New, []byte("webhook-secret-16"))
+	mac.Write([]byte(event))
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	assert.Equal(t, fiber.StatusNoContent, send(fiber.MethodPost, "/github/webhook", event, "", "X-GitHub-Event", "issues", githubsync.SignatureHeader, signature).StatusCode)
+	stored, err := repository.Tasks.FindByID(context.Background(), task.UserID, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusDone, stored.Status)
+
+	// Assert that unlinking removes the link
+	assert.Equal(t, fiber.StatusNoContent, send(fiber.MethodDelete, path, "", token).StatusCode)
+	assert.Equal(t, fiber.StatusNotFound, send(fiber.MethodDelete, path, "", token).StatusCode)
+}
+
+// TestIntegrationEndpoints tests the polling triggers and actions for automation platforms with an API key
+func TestIntegrationEndpoints(t *testing.T) {
+	repository.UseMemory()
+	app := NewApp(testConfig())
+	member := createUser(t, models.User{Username: "dave", Password: "hash"})
+
+	send := func(method, path, body, key string) *http.Response {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("X-API-Key", key)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return resp
+	}
+	items := func(resp *http.Response) []map[string]interface{} {
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var list []map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+		return list
+	}
+
+	// Assert that an API token works as the API key, and requests without one are rejected
+	req := httptest.NewRequest(fiber.MethodPost, "/users/me/tokens", strings.NewReader(`{"name":"zapier","scopes":["tasks:write"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", member)
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	var created struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	key := created.Token
+	resp = send(fiber.MethodGet, "/integrations/me", "", key)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var me map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&me))
+	assert.Equal(t, "dave", me["username"])
+	assert.Equal(t, fiber.StatusUnauthorized, send(fiber.MethodGet, "/integrations/me", "", "").StatusCode)
+
+	// Assert that the create action returns the bare task, assigned to the user by default
+	resp = send(fiber.MethodPost, "/integrations/actions/create-task", `{"title":"Call supplier"}`, key)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var first models.Task
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&first))
+	assert.Equal(t, "dave", first.AllottedTo)
+	assert.Equal(t, fiber.StatusBadRequest, send(fiber.MethodPost, "/integrations/actions/create-task", `{"description":"no title"}`, key).StatusCode)
+
+	// Assert that the new task trigger lists the newest task first and only the tasks after the cursor
+	resp = send(fiber.MethodPost, "/integrations/actions/create-task", `{"title":"Send invoice"}`, key)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	list := items(send(fiber.MethodGet, "/integrations/triggers/new-task", "", key))
+	require.Len(t, list, 2)
+	assert.Equal(t, "Send invoice", list[0]["title"])
+	list = items(send(fiber.MethodGet, "/integrations/triggers/new-task?since="+first.ID.Hex(), "", key))
+	require.Len(t, list, 1)
+	assert.Equal(t, list[0]["id"], list[0]["cursor"])
+	assert.Empty(t, items(send(fiber.MethodGet, "/integrations/triggers/new-task?since="+list[0]["cursor"].(string), "", key)))
+	assert.Equal(t, fiber.StatusBadRequest, send(fiber.MethodGet, "/integrations/triggers/new-task?since=yesterday", "", key).StatusCode)
+
+	// Assert that completing a task triggers once, with an ID of its own
+	assert.Empty(t, items(send(fiber.MethodGet, "/integrations/triggers/task-completed", "", key)))
+	resp = send(fiber.MethodPost, "/integrations/actions/complete-task", `{"id":"`+first.ID.Hex()+`"}`, key)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var completed models.Task
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&completed))
+	assert.Equal(t, models.TaskStatusDone, completed.Status)
+	list = items(send(fiber.MethodGet, "/integrations/triggers/task-completed", "", key))
+	require.Len(t, list, 1)
+	assert.Equal(t, first.ID.Hex(), list[0]["task_id"])
+	assert.Equal(t, first.ID.Hex()+"-"+list[0]["cursor"].(string), list[0]["id"])
+	assert.Empty(t, items(send(fiber.MethodGet, "/integrations/triggers/task-completed?since="+list[0]["cursor"].(string), "", key)))
+	assert.Equal(t, fiber.StatusNotFound, send(fiber.MethodPost, "/integrations/actions/complete-task", `{"id":"`+primitive.NewObjectID().Hex()+`"}`, key).StatusCode)
+}
+
+// TestCalDAV tests that a CalDAV client discovers the tasks collection and syncs tasks both ways
+func TestCalDAV(t *testing.T) {
+	repository.UseMemory()
+	app := NewApp(testConfig())
+	member := createUser(t, models.User{Username: "erin", Password: "hash"})
+	createUser(t, models.User{Username: "frank", Password: "hash"})
+
+	req := httptest.NewRequest(fiber.MethodPost, "/users/me/tokens", strings.NewReader(`{"name":"reminders","scopes":["tasks:write"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", member)
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	var created struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte("erin:"+created.Token))
+
+	send := func(method, path, body, auth string, header ...string) (*http.Response, string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", auth)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(data)
+	}
+	propfind := `<d:propfind xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/">` +
+		`<d:prop><d:current-user-principal/><c:calendar-home-set/><cs:getctag/><d:getetag/></d:prop></d:propfind>`
+
+	// Assert that clients are asked for credentials and discover the principal, home and collection
+	resp, _ = send("PROPFIND", "/caldav/", propfind, "")
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("WWW-Authenticate"), "Basic")
+	resp, _ = send(fiber.MethodGet, "/.well-known/caldav", "", "")
+	assert.Equal(t, fiber.StatusMovedPermanently, resp.StatusCode)
+	resp, _ = send(fiber.MethodOptions, "/caldav/", "", "")
+	assert.Contains(t, resp.Header.Get("DAV"), "calendar-access")
+	resp, body := send("PROPFIND", "/caldav/", propfind, basic, "Depth", "0")
+	require.Equal(t, fiber.StatusMultiStatus, resp.StatusCode)
+	assert.Contains(t, body, "<d:current-user-principal><d:href>/caldav/principals/erin/</d:href>")
+	_, body = send("PROPFIND", "/caldav/principals/erin/", propfind, basic, "Depth", "0")
+	assert.Contains(t, body, "<c:calendar-home-set><d:href>/caldav/calendars/erin/</d:href>")
+	resp, _ = send("PROPFIND", "/caldav/calendars/frank/tasks/", propfind, basic, "Depth", "0")
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	// Assert that a task created by the client keeps its name and UID, and duplicates are refused
+	todo := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:F00D-1\r\nSUMMARY:Water plants\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+	resp, _ = send(fiber.MethodPut, "/caldav/calendars/erin/tasks/F00D-1.ics", todo, basic, "If-None-Match", "*", "Content-Type", "text/calendar")
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	assert.Equal(t, `"1"`, etag)
+	resp, _ = send(fiber.MethodPut, "/caldav/calendars/erin/tasks/F00D-1.ics", todo, basic, "If-None-Match", "*", "Content-Type", "text/calendar")
+	assert.Equal(t, fiber.StatusPreconditionFailed, resp.StatusCode)
+	resp, body = send(fiber.MethodGet, "/caldav/calendars/erin/tasks/F00D-1.ics", "", basic)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Contains(t, body, "UID:F00D-1\r\n")
+	assert.Contains(t, body, "STATUS:NEEDS-ACTION\r\n")
+
+	// Assert that tasks created through the API are listed by their ID and the CTag follows changes
+	resp, body = send(fiber.MethodPost, "/tasks", `{"title":"File taxes","allotted_to":"erin"}`, member, "Content-Type", "application/json")
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var task models.Task
+	require.NoError(t, json.Unmarshal([]byte(body), &task))
+	resp, body = send("PROPFIND", "/caldav/calendars/erin/tasks/", propfind, basic, "Depth", "1")
+	require.Equal(t, fiber.StatusMultiStatus, resp.StatusCode)
+	assert.Contains(t, body, "<d:href>/caldav/calendars/erin/tasks/F00D-1.ics</d:href>")
+	assert.Contains(t, body, "<d:href>/caldav/calendars/erin/tasks/"+task.ID.Hex()+".ics</d:href>")
+	ctag := body[strings.Index(body, "<cs:getctag>"):strings.Index(body, "</cs:getctag>")]
+
+	// Assert that completing the task from the client updates it, with If-Match checked
+	done := strings.Replace(todo, "SUMMARY:Water plants", "SUMMARY:Water plants\r\nSTATUS:COMPLETED", 1)
+	resp, _ = send(fiber.MethodPut, "/caldav/calendars/erin/tasks/F00D-1.ics", done, basic, "If-Match", `"7"`)
+	assert.Equal(t, fiber.StatusPreconditionFailed, resp.StatusCode)
+	resp, _ = send(fiber.MethodPut, "/caldav/calendars/erin/tasks/F00D-1.ics", done, basic, "If-Match", etag)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, `"2"`, resp.Header.Get("ETag"))
+	_, body = send("PROPFIND", "/caldav/calendars/erin/tasks/", propfind, basic, "Depth", "0")
+	assert.NotContains(t, body, ctag)
+
+	// Assert that a multiget returns the data of the asked tasks and marks missing ones
+	multiget := `<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:prop><d:getetag/><c:calendar-data/></d:prop>` +
+		`<d:href>/caldav/calendars/erin/tasks/F00D-1.ics</d:href><d:href>/caldav/calendars/erin/tasks/nope.ics</d:href></c:calendar-multiget>`
+	resp, body = send("REPORT", "/caldav/calendars/erin/tasks/", multiget, basic, "Depth", "1")
+	require.Equal(t, fiber.StatusMultiStatus, resp.StatusCode)
+	assert.Contains(t, body, "STATUS:COMPLETED")
+	assert.Contains(t, body, "<d:href>/caldav/calendars/erin/tasks/nope.ics</d:href><d:status>HTTP/1.1 404 Not Found</d:status>")
+
+	// Assert that deleting from the client deletes the task
+	resp, _ = send(fiber.MethodDelete, "/caldav/calendars/erin/tasks/"+task.ID.Hex()+".ics", "", basic)
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	resp, _ = send(fiber.MethodGet, "/tasks/"+task.ID.Hex(), "", member)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+// TestOfflineSync tests pulling task deltas with tombstones and pushing offline changes with conflicts
+func TestOfflineSync(t *testing.T) {
+	repository.UseMemory()
+	app := NewApp(testConfig())
+	member := createUser(t, models.User{Username: "grace", Password: "hash"})
+
+	send := func(method, path, body string) (int, []byte) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Authorization", member)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, data
+	}
+	type result struct {
+		ID     string       `json:"id"`
+		Status string       `json:"status"`
+		Task   *models.Task `json:"task"`
+		Error  *struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	push := func(changes string) []result {
+		status, data := send(fiber.MethodPost, "/sync", `{"changes":`+changes+`}`)
+		require.Equal(t, fiber.StatusOK, status, string(data))
+		var body struct {
+			Results []result `json:"results"`
+		}
+		require.NoError(t, json.Unmarshal(data, &body))
+		return body.Results
+	}
+	type delta struct {
+		Created []models.Task      `json:"created"`
+		Updated []models.Task      `json:"updated"`
+		Deleted []models.Tombstone `json:"deleted"`
+		Cursor  string             `json:"cursor"`
+		HasMore bool               `json:"has_more"`
+	}
+	pull := func(query string) delta {
+		status, data := send(fiber.MethodGet, "/sync"+query, "")
+		require.Equal(t, fiber.StatusOK, status, string(data))
+		var body delta
+		require.NoError(t, json.Unmarshal(data, &body))
+		return body
+	}
+
+	// Assert that tasks created offline are stored, with the ID the client chose, and retries are no-ops
+	chosen := primitive.NewObjectID().Hex()
+	creates := `[{"op":"create","id":"` + chosen + `","task":{"title":"Pack bags"}},{"op":"create","task":{"title":"Book taxi","priority":"high"}},{"op":"create","task":{}}]`
+	results := push(creates)
+	require.Len(t, results, 3)
+	assert.Equal(t, "applied", results[0].Status)
+	assert.Equal(t, chosen, results[0].ID)
+	assert.Equal(t, "grace", results[0].Task.AllottedTo)
+	assert.Equal(t, "applied", results[1].Status)
+	taxi := results[1].ID
+	assert.Equal(t, "rejected", results[2].Status)
+	assert.Equal(t, "validation_failed", results[2].Error.Code)
+	assert.Equal(t, "applied", push(`[{"op":"create","id":"` + chosen + `","task":{"title":"Pack bags"}}]`)[0].Status)
+
+	// Assert that the first sync returns all tasks as created, and pages with has_more
+	first := pull("")
+	assert.Len(t, first.Created, 2)
+	assert.Empty(t, first.Updated)
+	assert.Empty(t, first.Deleted)
+	assert.False(t, first.HasMore)
+	page := pull("?limit=1")
+	assert.Len(t, page.Created, 1)
+	assert.True(t, page.HasMore)
+	assert.Len(t, pull("?limit=1&since="+page.Cursor).Created, 1)
+
+	// Assert that updates apply on their base version, and conflict with the server's version otherwise
+	time.Sleep(2 * time.Millisecond)
+	status, _ := send(fiber.MethodPut, "/tasks/"+taxi, `{"title":"Book taxi for 6am","allotted_to":"grace"}`)
+	require.Equal(t, fiber.StatusOK, status)
+	results = push(`[{"op":"update","id":"` + chosen + `","base_version":1,"task":{"title":"Pack bags","status":"Done","allotted_to":"grace"}},` +
+		`{"op":"update","id":"` + taxi + `","base_version":1,"task":{"title":"Book cab","allotted_to":"grace"}},` +
+		`{"op":"update","id":"` + taxi + `","task":{"title":"Book cab"}}]`)
+	assert.Equal(t, "applied", results[0].Status)
+	assert.Equal(t, 2, results[0].Task.Version)
+	assert.Equal(t, models.TaskStatusDone, results[0].Task.Status)
+	assert.Equal(t, "conflict", results[1].Status)
+	assert.Equal(t, "Book taxi for 6am", results[1].Task.Title)
+	assert.Equal(t, "version_conflict", results[1].Error.Code)
+	assert.Equal(t, "rejected", results[2].Status)
+
+	// Assert that the next sync returns the changed tasks as updated and the deleted ones as tombstones
+	status, _ = send(fiber.MethodDelete, "/tasks/"+chosen, "")
+	require.Equal(t, fiber.StatusNoContent, status)
+	next := pull("?since=" + first.Cursor)
+	assert.Empty(t, next.Created)
+	require.Len(t, next.Updated, 1)
+	assert.Equal(t, "Book taxi for 6am", next.Updated[0].Title)
+	require.Len(t, next.Deleted, 1)
+	assert.Equal(t, chosen, next.Deleted[0].TaskID.Hex())
+	latest := pull("?since=" + next.Cursor)
+	assert.Empty(t, latest.Created)
+	assert.Empty(t, latest.Updated)
+	assert.Empty(t, latest.Deleted)
+
+	// Assert that offline deletes conflict with newer versions and are idempotent otherwise
+	results = push(`[{"op":"delete","id":"` + taxi + `","base_version":1},{"op":"delete","id":"` + chosen + `"},{"op":"archive","id":"` + taxi + `"}]`)
+	assert.Equal(t, "conflict", results[0].Status)
+	assert.Equal(t, "applied", results[1].Status)
+	assert.Equal(t, "rejected", results[2].Status)
+	results = push(`[{"op":"delete","id":"` + taxi + `","base_version":2}]`)
+	assert.Equal(t, "applied", results[0].Status)
+	assert.Len(t, pull("?since="+latest.Cursor).Deleted, 1)
+
+	// Assert that invalid cursors are rejected, and cursors older than the retention are gone
+	status, _ = send(fiber.MethodGet, "/sync?since=yesterday", "")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	cfg := testConfig()
+	cfg.SyncRetention = time.Millisecond
+	app = NewApp(cfg)
+	status, _ = send(fiber.MethodGet, "/sync?since="+first.Cursor, "")
+	assert.Equal(t, fiber.StatusGone, status)
+}
+
+// uncommittedTasks hides a task from reads, like a write that stamped its time but has not committed yet.
+type uncommittedTasks struct {
+	repository.TaskRepository
+	hidden primitive.ObjectID
+}
+
+func (r *uncommittedTasks) Find(ctx context.Context, query repository.TaskQuery) ([]models.Task, error) {
+	tasks, err := r.TaskRepository.Find(ctx, query)
+	visible := []models.Task{}
+	for _, task := range tasks {
+		if task.ID != r.hidden {
+			visible = append(visible, task)
+		}
+	}
+	return visible, err
+}
+
+// TestOfflineSyncOverlap tests that a write committed after a sync read past its time reaches the client
+// with the next sync, and that only the writes of the overlap are sent again
+func TestOfflineSyncOverlap(t *testing.T) {
+	repository.UseMemory()
+	cfg := testConfig()
+	cfg.SyncOverlap = time.Minute
+	app := NewApp(cfg)
+	member := createUser(t, models.User{Username: "heidi", Password: "hash"})
+	owner, err := repository.Users.FindByUsername(context.Background(), "heidi")
+	require.NoError(t, err)
+
+	type delta struct {
+		Created []models.Task `json:"created"`
+		Updated []models.Task `json:"updated"`
+		Cursor  string        `json:"cursor"`
+		HasMore bool          `json:"has_more"`
+	}
+	pull := func(query string) delta {
+		req := httptest.NewRequest(fiber.MethodGet, "/sync"+query, nil)
+		req.Header.Set("Authorization", member)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var body delta
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return body
+	}
+	create := func(title string) models.Task {
+		task := models.Task{Title: title, UserID: owner.ID, AllottedTo: "heidi", Version: 1}
+		require.NoError(t, repository.Tasks.Create(context.Background(), &task))
+		time.Sleep(2 * time.Millisecond)
+		return task
+	}
+
+	// Assert that the late write is picked up by the next sync, after one that read past its time
+	create("Early")
+	tasks := &uncommittedTasks{TaskRepository: repository.Tasks}
+	repository.Tasks = tasks
+	tasks.hidden = create("Late").ID
+	create("Later")
+	first := pull("")
+	assert.Len(t, first.Created, 2)
+	tasks.hidden = primitive.NilObjectID
+	next := pull("?since=" + first.Cursor)
+	titles := []string{}
+	for _, task := range append(next.Created, next.Updated...) {
+		titles = append(titles, task.Title)
+		assert.Equal(t, 1, task.Version)
+	}
+	assert.ElementsMatch(t, []string{"Early", "Late", "Later"}, titles)
+
+	// Assert that a sync paged with has_more overlaps from where it started, and nothing older is sent again
+	page := pull("?limit=2&since=" + next.Cursor)
+	require.True(t, page.HasMore)
+	last := pull("?limit=2&since=" + page.Cursor)
+	require.False(t, last.HasMore)
+	assert.Len(t, append(page.Created, page.Updated...), 2)
+	assert.Len(t, append(last.Created, last.Updated...), 1)
+	cfg.SyncOverlap = 0
+	app = NewApp(cfg)
+	assert.Empty(t, pull("?since="+last.Cursor).Updated)
+}
+
+// TestDebugEndpoints tests that profiles and runtime info are served to admins only, and only when enabled
+func TestDebugEndpoints(t *testing.T) {
+	repository.UseMemory()
+	admin := createUser(t, models.User{Username: "root", Password: "hash", Role: models.RoleAdmin})
+	member := createUser(t, models.User{Username: "carol", Password: "hash"})
+
+	get := func(app *fiber.App, path, token string) *http.Response {
+		req := httptest.NewRequest(fiber.MethodGet, path, nil)
+		req.Header.Set("Authorization", token)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Assert that the debug endpoints do not exist unless enabled
+	assert.Equal(t, fiber.StatusNotFound, get(NewApp(testConfig()), "/admin/debug/runtime", admin).StatusCode)
+
+	config := testConfig()
+	config.DebugEndpoints = true
+	app := NewApp(config)
+
+	// Assert that admins get a goroutine dump and the runtime info
+	resp := get(app, "/admin/debug/pprof/goroutine?debug=2", admin)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp = get(app, "/admin/debug/runtime", admin)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var info map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+	assert.Equal(t, "dev", info["version"])
+	assert.NotZero(t, info["goroutines"])
+
+	// Assert that other users and anonymous clients are turned away
+	assert.Equal(t, fiber.StatusForbidden, get(app, "/admin/debug/pprof/heap", member).StatusCode)
+	assert.Equal(t, fiber.StatusUnauthorized, get(app, "/admin/debug/pprof/", "").StatusCode)
+}
+
+// TestConfigReload tests that reloaded CORS, rate limit, log level and feature flag settings apply without a restart
+func TestConfigReload(t *testing.T) {
+	repository.UseMemory()
+	admin := createUser(t, models.User{Username: "root", Password: "hash", Role: models.RoleAdmin})
+	reloader := NewReloader()
+	config := testConfig()
+	app := newApp(config, settings{reloader: reloader})
+
+	send := func(method, path string) *http.Response {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Authorization", admin)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return resp
+	}
+	effective := func() map[string]interface{} {
+		resp := send(fiber.MethodGet, "/admin/config")
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return body
+	}
+
+	// Assert that a newly allowed origin is served and a lowered limit applies right away
+	assert.Empty(t, send(fiber.MethodGet, "/healthz").Header.Get(fiber.HeaderAccessControlAllowOrigin))
+	config.CORS.AllowOrigins = []string{"https://app.example.com"}
+	config.AuthLimit.Max = 1
+	config.LogLevel = slog.LevelWarn
+	config.Features = []string{"beta-board"}
+	config.Port = "9999"
+	changed, err := reloader.Reload(config)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"log_level", "features", "cors", "rate_limits.auth"}, changed)
+	assert.Equal(t, "https://app.example.com", send(fiber.MethodGet, "/healthz").Header.Get(fiber.HeaderAccessControlAllowOrigin))
+	assert.NotEqual(t, fiber.StatusTooManyRequests, send(fiber.MethodPost, "/signup").StatusCode)
+	assert.Equal(t, fiber.StatusTooManyRequests, send(fiber.MethodPost, "/signup").StatusCode)
+	assert.True(t, features.Enabled("beta-board"))
+	assert.False(t, logging.Enabled(slog.LevelInfo))
+
+	// Assert that admins see the reloaded settings, but not settings that need a restart
+	body := effective()
+	assert.Equal(t, "warn", body["log_level"])
+	assert.Equal(t, []interface{}{"beta-board"}, body["features"])
+	assert.Equal(t, "", body["static"].(map[string]interface{})["port"])
+	assert.NotContains(t, body, "jwt_secret")
+
+	// Assert that invalid settings are rejected, keeping the current ones, and reported
+	config.CORS.AllowOrigins = []string{"*"}
+	config.CORS.AllowCredentials = true
+	_, err = reloader.Reload(config)
+	require.Error(t, err)
+	assert.Equal(t, "https://app.example.com", send(fiber.MethodGet, "/healthz").Header.Get(fiber.HeaderAccessControlAllowOrigin))
+	assert.Contains(t, effective()["last_reload_error"], "CORS")
+
+	// Assert that the job reads the .env file, but leaves variables set in the environment alone
+	dir := t.TempDir()
+	env := "APP_PORT=8080\nJWT_SECRET=s\nTOKEN_EXPIRY_TIME=5\nLOG_LEVEL=debug\nFEATURE_FLAGS=from-file\n"
+	require.NoError(t, os.WriteFile(dir+"/.env", []byte(env), 0o600))
+	for _, name := range []string{"APP_PORT", "JWT_SECRET", "TOKEN_EXPIRY_TIME", "LOG_LEVEL"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	t.Setenv("APP_PORT", "8080")
+	t.Setenv("JWT_SECRET", "s")
+	t.Setenv("TOKEN_EXPIRY_TIME", "5")
+	t.Setenv("FEATURE_FLAGS", "from-env")
+	job := reloader.Job(dir, time.Minute)
+	require.NoError(t, job.Run(context.Background()))
+	assert.True(t, logging.Enabled(slog.LevelDebug))
+	assert.True(t, features.Enabled("from-env"))
+	assert.False(t, features.Enabled("from-file"))
+
+	// Assert that a variable removed from the file falls back to its default
+	require.NoError(t, os.WriteFile(dir+"/.env", []byte("APP_PORT=8080\n"), 0o600))
+	require.NoError(t, job.Run(context.Background()))
+	assert.Equal(t, slog.LevelInfo, logging.Level())
+}
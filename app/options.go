@@ -0,0 +1,204 @@
+// options.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package app
+
+import (
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/bkojha74/task-management/audit"
+	"github.com/bkojha74/task-management/billing"
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/database"
+	"github.com/bkojha74/task-management/lifecycle"
+	"github.com/bkojha74/task-management/notifications"
+	"github.com/bkojha74/task-management/quota"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+	"github.com/bkojha74/task-management/server"
+	"github.com/bkojha74/task-management/signup"
+	"github.com/bkojha74/task-management/utils"
+	"github.com/bkojha74/task-management/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrNoStorage is returned by New when no storage backend was configured.
+var ErrNoStorage = errors.New("no storage backend configured")
+
+// settings collects the values set by options.
+type settings struct {
+	config        *Config
+	storage       func()
+	mongoURI      string
+	middlewares   []fiber.Handler
+	notifier      notifications.Notifier
+	auditSink     audit.Sink
+	signingSecret *utils.SigningSecret
+	reloader      *Reloader
+}
+
+// Option configures the application built by New.
+type Option func(*settings)
+
+// WithConfig sets the application settings. Without it New reads them with LoadConfig.
+func WithConfig(cfg Config) Option {
+	return func(s *settings) {
+		s.config = &cfg
+	}
+}
+
+// WithMongoStorage stores users and tasks in the MongoDB at uri; reports read through a client of their
+// own when Config.Reporting is enabled. The caller disconnects with database.Disconnect once the app has
+// stopped.
+func WithMongoStorage(uri string) Option {
+	return func(s *settings) {
+		s.mongoURI = uri
+		s.storage = func() {
+			database.Init(uri)
+			repository.InitMongo()
+		}
+	}
+}
+
+// WithMemoryStorage keeps users and tasks in memory, e.g. for tests and demos. All data is lost on exit.
+func WithMemoryStorage() Option {
+	return func(s *settings) {
+		s.storage = repository.UseMemory
+	}
+}
+
+// WithMiddleware adds middlewares that run after the built-in ones and before every route.
+func WithMiddleware(handlers ...fiber.Handler) Option {
+	return func(s *settings) {
+		s.middlewares = append(s.middlewares, handlers...)
+	}
+}
+
+// WithNotifier sets the notifier that delivers task notifications. Without it notifications are discarded.
+func WithNotifier(notifier notifications.Notifier) Option {
+	return func(s *settings) {
+		s.notifier = notifier
+	}
+}
+
+// WithAuditSink records every mutating request to sink, as configured by Config.Audit. Without it no
+// audit log is kept. The caller closes the sink once the app has stopped.
+func WithAuditSink(sink audit.Sink) Option {
+	return func(s *settings) {
+		s.auditSink = sink
+	}
+}
+
+// WithSigningSecret signs and verifies tokens with secret instead of Config.JWTSecret, so that the caller
+// can rotate it while the app runs, e.g. when it changes in a secrets backend.
+func WithSigningSecret(secret *utils.SigningSecret) Option {
+	return func(s *settings) {
+		s.signingSecret = secret
+	}
+}
+
+// WithReloader lets the caller apply changes to the reloadable settings while the app runs, e.g. with
+// the reloader's job.
+func WithReloader(reloader *Reloader) Option {
+	return func(s *settings) {
+		s.reloader = reloader
+	}
+}
+
+// New builds the application from options: it validates the settings, applies the process-wide
+// settings (cache, response envelope, validation, notifier), initializes the storage backend and
+// returns the Fiber app with all middleware and routes.
+//
+// Parameters:
+// - opts: The options configuring the application.
+//
+// Returns:
+// - *fiber.App: The configured application.
+// - error: An error if the settings are invalid, storage is missing or the reporting client cannot connect.
+func New(opts ...Option) (*fiber.App, error) {
+	s := settings{notifier: notifications.Nop{}}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	if s.config == nil {
+		cfg, err := LoadConfig()
+		if err != nil {
+			return nil, err
+		}
+		s.config = &cfg
+	}
+	if err := s.config.Validate(); err != nil {
+		return nil, err
+	}
+	if s.storage == nil {
+		return nil, ErrNoStorage
+	}
+
+	// Process-wide settings shared by all handlers
+	cache.Init(s.config.CacheSize, s.config.CacheTTL)
+	response.DefaultEnvelope = s.config.ResponseEnvelope
+	validation.SetHolidays(s.config.Holidays)
+	validation.MaxOpenTasksPerAssignee = s.config.MaxOpenTasksPerAssignee
+	quota.User, quota.Workspace = s.config.UserQuota, s.config.WorkspaceQuota
+	billing.Default, quota.WorkspaceLimits = s.config.Stripe, billing.WorkspaceLimits
+	utils.Tokens = utils.TokenPolicy{
+		Issuer:     s.config.JWTIssuer,
+		Audience:   s.config.JWTAudience,
+		ClockSkew:  s.config.JWTClockSkew,
+		Refresh:    s.config.RefreshTokenExpiry,
+		RememberMe: s.config.RememberMeExpiry,
+		Sliding:    s.config.SlidingSessions,
+		MaxSession: s.config.MaxSessionLifetime,
+	}
+	notifications.Default = s.notifier
+	signup.Default = s.config.Signup
+
+	s.storage()
+	if s.mongoURI != "" && s.config.Reporting.Enabled() {
+		if err := database.ConnectReporting(s.mongoURI, s.config.Reporting); err != nil {
+			return nil, err
+		}
+		repository.UseReportingMongo()
+	}
+	repository.UseFieldEncryption(s.config.FieldEncryption)
+
+	return newApp(*s.config, s), nil
+}
+
+// Run serves the application until it is shut down. It drains on SIGUSR1 and shuts down gracefully
+// once the drain grace period is over or on SIGINT/SIGTERM.
+//
+// Parameters:
+// - app: The application to serve.
+// - cfg: The settings the application was built with, for the port, TLS and drain grace period.
+//
+// Returns:
+// - error: An error if the server could not be started.
+func Run(app *fiber.App, cfg Config) error {
+	if cfg.Port == "" {
+		return errors.New("no port configured")
+	}
+
+	lifecycle.HandleDrainSignal(cfg.DrainGrace)
+	go func() {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+		select {
+		case <-lifecycle.Drained():
+		case <-stop:
+		}
+		log.Println("Shutting down server...")
+		if err := app.ShutdownWithTimeout(cfg.DrainGrace); err != nil {
+			log.Println("Error shutting down server:", err)
+		}
+	}()
+
+	// Start the Fiber server on the specified port, over TLS when configured
+	return server.Listen(app, cfg.Port, cfg.TLS)
+}
@@ -0,0 +1,410 @@
+// reload.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bkojha74/task-management/features"
+	"github.com/bkojha74/task-management/helper"
+	"github.com/bkojha74/task-management/jobs"
+	"github.com/bkojha74/task-management/logging"
+	"github.com/bkojha74/task-management/middleware"
+)
+
+// Reloader applies changes to the reloadable settings (rate limits, log level, CORS policy and feature
+// flags) while the app runs. The other settings only take effect at the next restart.
+type Reloader struct {
+	mu         sync.RWMutex
+	config     Config
+	reloadedAt time.Time
+	lastError  string
+
+	cors       *middleware.Swappable
+	authLimit  *middleware.Swappable
+	readLimit  *middleware.Swappable
+	writeLimit *middleware.Swappable
+
+	envOwned map[string]bool // Reloadable variables of the .env file that the environment does not override
+}
+
+// reloadable is the part of Config that Reload applies.
+type reloadable struct {
+	LogLevel   slog.Level
+	Features   []string
+	CORS       middleware.CORSConfig
+	AuthLimit  middleware.RateLimitTier
+	ReadLimit  middleware.RateLimitTier
+	WriteLimit middleware.RateLimitTier
+}
+
+// NewReloader creates a reloader, to be passed to New with WithReloader.
+//
+// Returns:
+// - *Reloader: The reloader; it applies settings once the app is built.
+func NewReloader() *Reloader {
+	return &Reloader{}
+}
+
+// start sets up the swappable middlewares and applies the process-wide settings the app is built with.
+func (r *Reloader) start(cfg Config) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.config = cfg
+	r.reloadedAt = time.Now()
+	r.cors = middleware.NewSwappable(middleware.CORS(cfg.CORS))
+	r.authLimit = middleware.NewSwappable(middleware.RateLimit(cfg.AuthLimit))
+	r.readLimit = middleware.NewSwappable(middleware.RateLimit(cfg.ReadLimit))
+	r.writeLimit = middleware.NewSwappable(middleware.RateLimit(cfg.WriteLimit))
+	logging.SetLevel(cfg.LogLevel)
+	features.Set(cfg.Features)
+}
+
+// Reload applies the reloadable settings of cfg and ignores the others. Rate limit tiers that changed
+// start counting afresh.
+//
+// Parameters:
+// - cfg: The settings to apply.
+//
+// Returns:
+// - []string: The names of the settings that changed.
+// - error: An error if a reloadable setting is invalid; nothing is applied then.
+func (r *Reloader) Reload(cfg Config) ([]string, error) {
+	next := cfg.reloadable()
+	if err := next.validate(); err != nil {
+		r.mu.Lock()
+		r.lastError = err.Error()
+		r.mu.Unlock()
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.config.reloadable()
+	changed := []string{}
+	if next.LogLevel != current.LogLevel {
+		logging.SetLevel(next.LogLevel)
+		changed = append(changed, "log_level")
+	}
+	if !reflect.DeepEqual(next.Features, current.Features) {
+		features.Set(next.Features)
+		changed = append(changed, "features")
+	}
+	if !reflect.DeepEqual(next.CORS, current.CORS) {
+		r.cors.Swap(middleware.CORS(next.CORS))
+		changed = append(changed, "cors")
+	}
+	for _, tier := range []struct {
+		current, next middleware.RateLimitTier
+		handler       *middleware.Swappable
+	}{
+		{current.AuthLimit, next.AuthLimit, r.authLimit},
+		{current.ReadLimit, next.ReadLimit, r.readLimit},
+		{current.WriteLimit, next.WriteLimit, r.writeLimit},
+	} {
+		if !reflect.DeepEqual(tier.current, tier.next) {
+			tier.handler.Swap(middleware.RateLimit(tier.next))
+			changed = append(changed, "rate_limits."+strings.ToLower(tier.next.Name))
+		}
+	}
+
+	r.config.LogLevel = next.LogLevel
+	r.config.Features = next.Features
+	r.config.CORS = next.CORS
+	r.config.AuthLimit = next.AuthLimit
+	r.config.ReadLimit = next.ReadLimit
+	r.config.WriteLimit = next.WriteLimit
+	r.reloadedAt = time.Now()
+	r.lastError = ""
+	return changed, nil
+}
+
+// Job creates the job watching the .env file in configDirectory and the environment: it sets the
+// reloadable variables of the file (RATE_LIMIT_*, CORS_*, LOG_LEVEL and FEATURE_FLAGS), reads the
+// settings again and applies them with Reload. As at startup, variables set in the environment take
+// precedence over the file.
+//
+// Parameters:
+// - configDirectory: The directory of the .env file.
+// - interval: The time between checks; 0 disables the job.
+//
+// Returns:
+// - jobs.Job: The job, to be added to a jobs.Scheduler.
+func (r *Reloader) Job(configDirectory string, interval time.Duration) jobs.Job {
+	// The variables whose value differs from the file's at startup come from the environment
+	if file, err := helper.ReadEnv(configDirectory); err == nil {
+		r.envOwned = map[string]bool{}
+		for name, value := range file {
+			if current, set := os.LookupEnv(name); reloadableVariable(name) && (!set || current == value) {
+				r.envOwned[name] = true
+			}
+		}
+	}
+
+	return jobs.Job{
+		Name:     "config-reload",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			if err := r.readEnv(configDirectory); err != nil {
+				return err
+			}
+			cfg, err := LoadConfig()
+			if err != nil {
+				return err
+			}
+			changed, err := r.Reload(cfg)
+			if err != nil {
+				return fmt.Errorf("keeping the current settings: %w", err)
+			}
+			if len(changed) > 0 {
+				log.Printf("Reloaded configuration: %s", strings.Join(changed, ", "))
+			}
+			return nil
+		},
+	}
+}
+
+// readEnv sets the reloadable variables of the .env file that the environment does not override, and
+// unsets those removed from the file.
+func (r *Reloader) readEnv(configDirectory string) error {
+	file, err := helper.ReadEnv(configDirectory)
+	if err != nil {
+		return err
+	}
+	if r.envOwned == nil {
+		r.envOwned = map[string]bool{}
+	}
+
+	for name, value := range file {
+		if !reloadableVariable(name) {
+			continue
+		}
+		if _, set := os.LookupEnv(name); set && !r.envOwned[name] {
+			continue
+		}
+		r.envOwned[name] = true
+		if err := os.Setenv(name, value); err != nil {
+			return err
+		}
+	}
+	for name := range r.envOwned {
+		if _, ok := file[name]; !ok {
+			os.Unsetenv(name)
+			delete(r.envOwned, name)
+		}
+	}
+	return nil
+}
+
+// reloadableVariable reports whether an environment variable configures a reloadable setting.
+func reloadableVariable(name string) bool {
+	return strings.HasPrefix(name, "RATE_LIMIT_") || strings.HasPrefix(name, "CORS_") ||
+		name == "LOG_LEVEL" || name == "FEATURE_FLAGS"
+}
+
+// reloadable returns the reloadable part of the settings.
+func (cfg Config) reloadable() reloadable {
+	return reloadable{
+		LogLevel:   cfg.LogLevel,
+		Features:   cfg.Features,
+		CORS:       cfg.CORS,
+		AuthLimit:  cfg.AuthLimit,
+		ReadLimit:  cfg.ReadLimit,
+		WriteLimit: cfg.WriteLimit,
+	}
+}
+
+// validate checks the reloadable settings like Config.Validate.
+func (settings reloadable) validate() error {
+	if err := settings.CORS.Validate(); err != nil {
+		return fmt.Errorf("invalid CORS configuration: %w", err)
+	}
+	for _, tier := range []middleware.RateLimitTier{settings.AuthLimit, settings.ReadLimit, settings.WriteLimit} {
+		if err := tier.Validate(); err != nil {
+			return fmt.Errorf("invalid rate limit: %w", err)
+		}
+	}
+	return nil
+}
+
+// effectiveConfig is the view of the settings in effect served to admins; secrets are left out.
+type effectiveConfig struct {
+	ReloadedAt time.Time `json:"reloaded_at"`
+	LastError  string    `json:"last_reload_error,omitempty"`
+
+	LogLevel   string                `json:"log_level"`
+	Features   []string              `json:"features"`
+	CORS       effectiveCORS         `json:"cors"`
+	RateLimits []effectiveRateLimit  `json:"rate_limits"`
+	Static     effectiveStaticConfig `json:"static"`
+}
+
+// effectiveCORS is the CORS policy in effect.
+type effectiveCORS struct {
+	AllowOrigins     []string `json:"allow_origins"`
+	AllowMethods     string   `json:"allow_methods"`
+	AllowHeaders     string   `json:"allow_headers"`
+	ExposeHeaders    string   `json:"expose_headers"`
+	AllowCredentials bool     `json:"allow_credentials"`
+	MaxAge           int      `json:"max_age"`
+}
+
+// effectiveRateLimit is a rate limit tier in effect.
+type effectiveRateLimit struct {
+	Name          string   `json:"name"`
+	Max           int      `json:"max"`
+	WindowSeconds int      `json:"window_seconds"`
+	Exempt        []string `json:"exempt"`
+}
+
+// effectiveStaticConfig are the settings that only change with a restart.
+type effectiveStaticConfig struct {
+	Environment               string   `json:"environment"`
+	Database                  string   `json:"database"`
+	ReportingClient           bool     `json:"reporting_client"`
+	ReportingReadPreference   string   `json:"reporting_read_preference"`
+	Port                      string   `json:"port"`
+	TLS                       bool     `json:"tls"`
+	JWTIssuer                 string   `json:"jwt_issuer"`
+	JWTAudience               string   `json:"jwt_audience"`
+	JWTClockSkewSeconds       int      `json:"jwt_clock_skew_seconds"`
+	TokenExpiryMinutes        int      `json:"token_expiry_minutes"`
+	RefreshTokenExpiryDays    int      `json:"refresh_token_expiry_days"`
+	RememberMeExpiryDays      int      `json:"remember_me_expiry_days"`
+	SlidingSessions           bool     `json:"sliding_sessions"`
+	MaxSessionLifetimeDays    int      `json:"max_session_lifetime_days"`
+	BodyLimit                 int      `json:"body_limit"`
+	TrustedProxyHeader        string   `json:"trusted_proxy_header"`
+	TrustedProxies            []string `json:"trusted_proxies"`
+	HSTSMaxAge                int      `json:"hsts_max_age"`
+	DrainGraceSeconds         int      `json:"drain_grace_seconds"`
+	DeletionGraceDays         int      `json:"deletion_grace_days"`
+	SyncRetentionDays         int      `json:"sync_retention_days"`
+	SyncOverlapSeconds        int      `json:"sync_overlap_seconds"`
+	MaintenanceRefreshSeconds int      `json:"maintenance_refresh_seconds"`
+	DisconnectCheckMillis     int      `json:"disconnect_check_ms"`
+	RequestLog                bool     `json:"request_log"`
+	DebugEndpoints            bool     `json:"debug_endpoints"`
+	AuditBodies               string   `json:"audit_bodies"`
+	FieldEncryption           bool     `json:"field_encryption"`
+	SignupMode                string   `json:"signup_mode"`
+	CaptchaProvider           string   `json:"captcha_provider"`
+	SignupRequireEmail        bool     `json:"signup_require_email"`
+	SAML                      bool     `json:"saml"`
+	SCIM                      bool     `json:"scim"`
+	Telegram                  bool     `json:"telegram"`
+	GitHub                    bool     `json:"github"`
+	Stripe                    bool     `json:"stripe"`
+	CacheSize                 int      `json:"cache_size"`
+	CacheTTLSeconds           int      `json:"cache_ttl_seconds"`
+	ResponseEnvelope          bool     `json:"response_envelope"`
+	MaxOpenTasksPerAssignee   int      `json:"max_open_tasks_per_assignee"`
+	QuotaUserTasks            int      `json:"quota_user_tasks"`
+	QuotaUserWebhooks         int      `json:"quota_user_webhooks"`
+	QuotaWorkspaceTasks       int      `json:"quota_workspace_tasks"`
+	QuotaWorkspaceWebhooks    int      `json:"quota_workspace_webhooks"`
+	RetentionArchiveDoneDays  int      `json:"retention_archive_done_days"`
+	RetentionTrashDays        int      `json:"retention_trash_days"`
+	RetentionAuditDays        int      `json:"retention_audit_days"`
+	RetentionDryRun           bool     `json:"retention_dry_run"`
+	AnomalyDeleteLimit        int      `json:"anomaly_delete_limit"`
+	AnomalyIPLimit            int      `json:"anomaly_ip_limit"`
+	AnomalyWindowSeconds      int      `json:"anomaly_window_seconds"`
+	AnomalyReauthenticate     bool     `json:"anomaly_reauthenticate"`
+}
+
+// Effective returns the settings in effect, for the admin config endpoint.
+func (r *Reloader) Effective() interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cfg := r.config
+	view := effectiveConfig{
+		ReloadedAt: r.reloadedAt.UTC(),
+		LastError:  r.lastError,
+		LogLevel:   strings.ToLower(cfg.LogLevel.String()),
+		Features:   append([]string{}, cfg.Features...),
+		CORS: effectiveCORS{
+			AllowOrigins:     append([]string{}, cfg.CORS.AllowOrigins...),
+			AllowMethods:     cfg.CORS.AllowMethods,
+			AllowHeaders:     cfg.CORS.AllowHeaders,
+			ExposeHeaders:    cfg.CORS.ExposeHeaders,
+			AllowCredentials: cfg.CORS.AllowCredentials,
+			MaxAge:           cfg.CORS.MaxAge,
+		},
+		Static: effectiveStaticConfig{
+			Environment:               cfg.Environment,
+			Database:                  cfg.Database,
+			ReportingClient:           cfg.Reporting.Enabled(),
+			ReportingReadPreference:   cfg.Reporting.ReadPreference,
+			Port:                      cfg.Port,
+			TLS:                       cfg.TLS.Enabled(),
+			JWTIssuer:                 cfg.JWTIssuer,
+			JWTAudience:               cfg.JWTAudience,
+			JWTClockSkewSeconds:       int(cfg.JWTClockSkew / time.Second),
+			TokenExpiryMinutes:        cfg.TokenExpiry,
+			RefreshTokenExpiryDays:    int(cfg.RefreshTokenExpiry / (24 * time.Hour)),
+			RememberMeExpiryDays:      int(cfg.RememberMeExpiry / (24 * time.Hour)),
+			SlidingSessions:           cfg.SlidingSessions,
+			MaxSessionLifetimeDays:    int(cfg.MaxSessionLifetime / (24 * time.Hour)),
+			BodyLimit:                 cfg.BodyLimit,
+			TrustedProxyHeader:        cfg.Proxy.Header,
+			TrustedProxies:            append([]string{}, cfg.Proxy.TrustedProxies...),
+			HSTSMaxAge:                cfg.HSTSMaxAge,
+			DrainGraceSeconds:         int(cfg.DrainGrace / time.Second),
+			DeletionGraceDays:         int(cfg.DeletionGrace / (24 * time.Hour)),
+			SyncRetentionDays:         int(cfg.SyncRetention / (24 * time.Hour)),
+			SyncOverlapSeconds:        int(cfg.SyncOverlap / time.Second),
+			MaintenanceRefreshSeconds: int(cfg.MaintenanceRefresh / time.Second),
+			DisconnectCheckMillis:     int(cfg.DisconnectCheck / time.Millisecond),
+			RequestLog:                cfg.RequestLog,
+			DebugEndpoints:            cfg.DebugEndpoints,
+			AuditBodies:               cfg.Audit.Bodies,
+			FieldEncryption:           cfg.FieldEncryption != nil,
+			SignupMode:                cfg.Signup.Mode,
+			CaptchaProvider:           cfg.Signup.CaptchaProvider,
+			SignupRequireEmail:        cfg.Signup.RequireEmail,
+			SAML:                      cfg.SAML != nil,
+			SCIM:                      cfg.SCIMToken != "",
+			Telegram:                  cfg.Telegram != nil,
+			GitHub:                    cfg.GitHub != nil,
+			Stripe:                    cfg.Stripe != nil,
+			CacheSize:                 cfg.CacheSize,
+			CacheTTLSeconds:           int(cfg.CacheTTL / time.Second),
+			ResponseEnvelope:          cfg.ResponseEnvelope,
+			MaxOpenTasksPerAssignee:   cfg.MaxOpenTasksPerAssignee,
+			QuotaUserTasks:            cfg.UserQuota.Tasks,
+			QuotaUserWebhooks:         cfg.UserQuota.Webhooks,
+			QuotaWorkspaceTasks:       cfg.WorkspaceQuota.Tasks,
+			QuotaWorkspaceWebhooks:    cfg.WorkspaceQuota.Webhooks,
+			RetentionArchiveDoneDays:  int(cfg.Retention.ArchiveDoneAfter / (24 * time.Hour)),
+			RetentionTrashDays:        int(cfg.Retention.PurgeTrashAfter / (24 * time.Hour)),
+			RetentionAuditDays:        int(cfg.Retention.AuditLogMaxAge / (24 * time.Hour)),
+			RetentionDryRun:           cfg.Retention.DryRun,
+			AnomalyDeleteLimit:        cfg.Anomaly.DeleteLimit,
+			AnomalyIPLimit:            cfg.Anomaly.IPLimit,
+			AnomalyWindowSeconds:      int(cfg.Anomaly.Window / time.Second),
+			AnomalyReauthenticate:     cfg.Anomaly.Reauthenticate,
+		},
+	}
+	for _, tier := range []middleware.RateLimitTier{cfg.AuthLimit, cfg.ReadLimit, cfg.WriteLimit} {
+		view.RateLimits = append(view.RateLimits, effectiveRateLimit{
+			Name:          strings.ToLower(tier.Name),
+			Max:           tier.Max,
+			WindowSeconds: int(tier.Window / time.Second),
+			Exempt:        append([]string{}, tier.Exempt...),
+		})
+	}
+	return view
+}
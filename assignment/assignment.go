@@ -0,0 +1,130 @@
+// assignment.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package assignment picks the assignee of the tasks created in a project without one, following the
+// project's models.AssignmentRule: each member in turn (round robin) or the member with the fewest open
+// tasks (least loaded).
+package assignment
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxMembers is the most members a rule shares tasks among.
+const MaxMembers = 100
+
+// Errors of Validate
+var (
+	ErrUnknownStrategy = errors.New("strategy must be round_robin or least_loaded")
+	ErrNoMembers       = errors.New("members must list between 1 and 100 usernames")
+	ErrDuplicateMember = errors.New("members must not repeat a username")
+)
+
+// Validate checks the strategy and members of a rule; it does not look the members up.
+//
+// Parameters:
+// - rule: The rule.
+//
+// Returns:
+// - error: ErrUnknownStrategy, ErrNoMembers or ErrDuplicateMember, or nil if the rule is valid.
+func Validate(rule models.AssignmentRule) error {
+	switch rule.Strategy {
+	case models.AssignmentRoundRobin, models.AssignmentLeastLoaded:
+	default:
+		return ErrUnknownStrategy
+	}
+	if len(rule.Members) == 0 || len(rule.Members) > MaxMembers {
+		return ErrNoMembers
+	}
+	seen := map[string]bool{}
+	for _, member := range rule.Members {
+		if member == "" {
+			return ErrNoMembers
+		}
+		if seen[member] {
+			return ErrDuplicateMember
+		}
+		seen[member] = true
+	}
+	return nil
+}
+
+// Turn is the round-robin turn Pick took for a new task. The zero Turn is no turn.
+type Turn struct {
+	projectID primitive.ObjectID
+	number    int
+	taken     bool
+}
+
+// Release gives the turn back when its task was not created after all, e.g. because it failed validation
+// or a dry run only checked it, so that the member is not skipped. The turn is only given back while it is
+// the last one taken; if a concurrent task took the next turn already, the member misses this round.
+//
+// Parameters:
+// - ctx: The context of the request.
+//
+// Returns:
+// - error: An error writing the rule.
+func (t Turn) Release(ctx context.Context) error {
+	if !t.taken {
+		return nil
+	}
+	return repository.Projects.ReturnAssignment(ctx, t.projectID, t.number)
+}
+
+// Pick returns the member of a project's assignment rule a new task is assigned to. Round robin takes the
+// next turn of the rule, also when concurrent tasks are created; the turn must be released unless the task
+// is then created. Least loaded counts the open (not done and not archived) tasks of the project owner
+// allotted to each member, anywhere, and picks the first member with the fewest.
+//
+// Parameters:
+// - ctx: The context of the request.
+// - project: The project the task is created in.
+//
+// Returns:
+// - string: The username of the assignee, empty if the project has no rule.
+// - Turn: The round-robin turn taken, or the zero Turn.
+// - error: An error reading or advancing the rule or counting the tasks.
+func Pick(ctx context.Context, project models.Project) (string, Turn, error) {
+	rule := project.Assignment
+	if rule == nil || len(rule.Members) == 0 {
+		return "", Turn{}, nil
+	}
+
+	switch rule.Strategy {
+	case models.AssignmentRoundRobin:
+		turns, err := repository.Projects.NextAssignment(ctx, project.ID)
+		if err == repository.ErrNotFound {
+			return "", Turn{}, nil // The rule was removed meanwhile
+		}
+		if err != nil {
+			return "", Turn{}, err
+		}
+		return rule.Members[turns%len(rule.Members)], Turn{projectID: project.ID, number: turns, taken: true}, nil
+	case models.AssignmentLeastLoaded:
+		archived := false
+		pick, fewest := "", int64(-1)
+		for _, member := range rule.Members {
+			open, err := repository.Tasks.Count(ctx, repository.TaskQuery{
+				UserID:        project.OwnerID,
+				AllottedTo:    member,
+				ExcludeStatus: models.TaskStatusDone,
+				Archived:      &archived,
+			})
+			if err != nil {
+				return "", Turn{}, err
+			}
+			if fewest < 0 || open < fewest {
+				pick, fewest = member, open
+			}
+		}
+		return pick, Turn{}, nil
+	}
+	return "", Turn{}, nil
+}
@@ -0,0 +1,82 @@
+// assignment_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package assignment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestValidate tests the rules that are accepted
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(models.AssignmentRule{Strategy: models.AssignmentRoundRobin, Members: []string{"alice", "bob"}}))
+	assert.Equal(t, ErrUnknownStrategy, Validate(models.AssignmentRule{Strategy: "random", Members: []string{"alice"}}))
+	assert.Equal(t, ErrNoMembers, Validate(models.AssignmentRule{Strategy: models.AssignmentLeastLoaded}))
+	assert.Equal(t, ErrNoMembers, Validate(models.AssignmentRule{Strategy: models.AssignmentLeastLoaded, Members: []string{""}}))
+	assert.Equal(t, ErrDuplicateMember, Validate(models.AssignmentRule{Strategy: models.AssignmentLeastLoaded, Members: []string{"alice", "alice"}}))
+}
+
+// TestPick tests that round robin takes turns and least loaded picks the member with the fewest open tasks
+func TestPick(t *testing.T) {
+	repository.UseMemory()
+	ctx := context.Background()
+	owner := primitive.NewObjectID()
+	project := models.Project{OwnerID: owner, Name: "Support"}
+	require.NoError(t, repository.Projects.Create(ctx, &project))
+
+	// Without a rule nobody is picked
+	assignee, turn, err := Pick(ctx, project)
+	require.NoError(t, err)
+	assert.Empty(t, assignee)
+	assert.NoError(t, turn.Release(ctx))
+
+	rule := &models.AssignmentRule{Strategy: models.AssignmentRoundRobin, Members: []string{"alice", "bob", "carol"}}
+	require.NoError(t, repository.Projects.SetAssignment(ctx, owner, project.ID, rule))
+	project.Assignment = rule
+	picked := []string{}
+	for i := 0; i < 4; i++ {
+		assignee, _, err := Pick(ctx, project)
+		require.NoError(t, err)
+		picked = append(picked, assignee)
+	}
+	assert.Equal(t, []string{"alice", "bob", "carol", "alice"}, picked)
+
+	// A released turn is taken again, unless a later turn was taken meanwhile
+	_, turn, err = Pick(ctx, project)
+	require.NoError(t, err)
+	require.NoError(t, turn.Release(ctx))
+	assignee, turn, err = Pick(ctx, project)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", assignee)
+	assignee, _, err = Pick(ctx, project)
+	require.NoError(t, err)
+	assert.Equal(t, "carol", assignee)
+	require.NoError(t, turn.Release(ctx))
+	assignee, _, err = Pick(ctx, project)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", assignee)
+
+	// Done and other owners' tasks do not count towards the load
+	for _, task := range []models.Task{
+		{UserID: owner, AllottedTo: "alice", Status: models.TaskStatusPending},
+		{UserID: owner, AllottedTo: "bob", Status: models.TaskStatusInProgress},
+		{UserID: owner, AllottedTo: "carol", Status: models.TaskStatusDone},
+		{UserID: primitive.NewObjectID(), AllottedTo: "carol", Status: models.TaskStatusPending},
+	} {
+		task := task
+		require.NoError(t, repository.Tasks.Create(ctx, &task))
+	}
+	project.Assignment = &models.AssignmentRule{Strategy: models.AssignmentLeastLoaded, Members: []string{"alice", "bob", "carol"}}
+	assignee, turn, err = Pick(ctx, project)
+	require.NoError(t, err)
+	assert.Equal(t, "carol", assignee)
+	assert.Equal(t, Turn{}, turn)
+}
@@ -0,0 +1,210 @@
+// audit.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/database"
+	"github.com/bkojha74/task-management/helper"
+	"github.com/bkojha74/task-management/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Body recording modes of Config.Bodies
+const (
+	BodiesNone     = "none"     // Record no bodies
+	BodiesRedacted = "redacted" // Record JSON bodies with the Redact fields masked
+	BodiesFull     = "full"     // Record bodies as sent
+)
+
+// Redacted replaces the values of redacted fields.
+const Redacted = "[REDACTED]"
+
+// DefaultRedact lists the fields masked in recorded bodies unless AUDIT_REDACT_FIELDS is set.
+var DefaultRedact = []string{"password", "token", "secret", "slack_webhook_url", "teams_webhook_url"}
+
+// Entry is the audit record of a mutating request.
+type Entry struct {
+	ID         primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"` // Set when stored through repository.AuditLog
+	Time       models.Timestamp   `json:"time" bson:"time"`
+	RequestID  string             `json:"request_id,omitempty" bson:"request_id,omitempty"`
+	Method     string             `json:"method" bson:"method"`
+	Path       string             `json:"path" bson:"path"`
+	UserID     string             `json:"user_id,omitempty" bson:"user_id,omitempty"` // Empty for anonymous requests such as sign-up
+	IP         string             `json:"ip" bson:"ip"`
+	Status     int                `json:"status" bson:"status"`
+	DurationMs int64              `json:"duration_ms" bson:"duration_ms"`
+	Body       interface{}        `json:"body,omitempty" bson:"body,omitempty"`       // Request body as configured by Config.Bodies
+	Details    interface{}        `json:"details,omitempty" bson:"details,omitempty"` // Outcome the handler stored under DetailsKey, if any
+}
+
+// DetailsKey is the key of the request local a handler stores the outcome of a request under, e.g. the count
+// of tasks a bulk update changed, to be recorded with the request in Entry.Details.
+const DetailsKey = "auditDetails"
+
+// Sink stores audit entries.
+type Sink interface {
+	Write(ctx context.Context, entry Entry) error
+	Close() error
+}
+
+// Pruner is implemented by sinks that can delete old entries, to cap the age of the audit log.
+type Pruner interface {
+	// Prune deletes the entries recorded before a time, or only counts them when dryRun is set, and returns
+	// how many there were.
+	Prune(ctx context.Context, before time.Time, dryRun bool) (int64, error)
+}
+
+// Config selects what is recorded of a request.
+type Config struct {
+	Bodies string   // BodiesNone, BodiesRedacted or BodiesFull; empty is BodiesRedacted
+	Redact []string // Field names, at any depth, masked in BodiesRedacted mode; matched case-insensitively
+}
+
+// LoadConfig reads the recording settings from AUDIT_BODIES (default redacted) and AUDIT_REDACT_FIELDS
+// (comma-separated, default DefaultRedact).
+//
+// Returns:
+// - Config: The configured settings.
+func LoadConfig() Config {
+	config := Config{Bodies: helper.GetEnv("AUDIT_BODIES"), Redact: DefaultRedact}
+	if config.Bodies == "" {
+		config.Bodies = BodiesRedacted
+	}
+	if fields := helper.GetEnv("AUDIT_REDACT_FIELDS"); fields != "" {
+		config.Redact = []string{}
+		for _, field := range strings.Split(fields, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				config.Redact = append(config.Redact, field)
+			}
+		}
+	}
+	return config
+}
+
+// Validate rejects unknown body recording modes.
+func (config Config) Validate() error {
+	switch config.Bodies {
+	case "", BodiesNone, BodiesRedacted, BodiesFull:
+		return nil
+	}
+	return fmt.Errorf("unknown AUDIT_BODIES %q, use none, redacted or full", config.Bodies)
+}
+
+// RecordBody returns what is recorded of a request body: nothing in BodiesNone mode or for an empty
+// body, the decoded JSON with the Redact fields masked in BodiesRedacted mode, and the body as sent in
+// BodiesFull mode. Bodies that are not JSON are recorded as a string in full mode only, since their
+// secrets cannot be found.
+//
+// Parameters:
+// - body: The raw request body.
+//
+// Returns:
+// - interface{}: The value to store in Entry.Body, or nil.
+func (config Config) RecordBody(body []byte) interface{} {
+	if config.Bodies == BodiesNone || len(body) == 0 {
+		return nil
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		if config.Bodies == BodiesFull {
+			return string(body)
+		}
+		return nil
+	}
+	if config.Bodies != BodiesFull {
+		decoded = redact(decoded, config.Redact)
+	}
+	return decoded
+}
+
+// redact masks the values of the given fields in a decoded JSON value, at any depth.
+func redact(value interface{}, fields []string) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range value {
+			if containsFold(fields, key) {
+				value[key] = Redacted
+			} else {
+				value[key] = redact(nested, fields)
+			}
+		}
+	case []interface{}:
+		for i, nested := range value {
+			value[i] = redact(nested, fields)
+		}
+	}
+	return value
+}
+
+// containsFold reports whether list contains value, ignoring case.
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadSink creates the sink selected by AUDIT_SINK: "mongo" (the audit_log collection) or "file"
+// (AUDIT_FILE, rotated at AUDIT_FILE_MAX_SIZE_MB keeping AUDIT_FILE_MAX_BACKUPS old files). Auditing is
+// off when it is empty.
+//
+// Returns:
+// - Sink: The configured sink, or nil when auditing is off.
+// - error: An error if the sink is unknown or cannot be opened.
+func LoadSink() (Sink, error) {
+	switch kind := helper.GetEnv("AUDIT_SINK"); kind {
+	case "":
+		return nil, nil
+	case "mongo":
+		return Mongo{}, nil
+	case "file":
+		path := helper.GetEnv("AUDIT_FILE")
+		if path == "" {
+			path = "audit.log"
+		}
+		maxBytes := int64(helper.GetEnvInt("AUDIT_FILE_MAX_SIZE_MB", 100)) << 20
+		return OpenFile(path, maxBytes, helper.GetEnvInt("AUDIT_FILE_MAX_BACKUPS", 10))
+	default:
+		return nil, fmt.Errorf("unknown AUDIT_SINK %q, use mongo or file", kind)
+	}
+}
+
+// Mongo stores audit entries in the audit_log collection opened by database.Init. The collection is
+// looked up on every write, so the sink can be created before the database is connected.
+type Mongo struct{}
+
+// Write inserts an entry.
+func (Mongo) Write(ctx context.Context, entry Entry) error {
+	_, err := database.AuditLogCollection.InsertOne(ctx, entry)
+	return err
+}
+
+// Prune deletes the entries recorded before a time.
+func (Mongo) Prune(ctx context.Context, before time.Time, dryRun bool) (int64, error) {
+	filter := bson.M{"time": bson.M{"$lt": primitive.NewDateTimeFromTime(before)}}
+	if dryRun {
+		return database.AuditLogCollection.CountDocuments(ctx, filter)
+	}
+	result, err := database.AuditLogCollection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// Close does nothing; the connection is closed by database.Disconnect.
+func (Mongo) Close() error {
+	return nil
+}
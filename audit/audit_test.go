@@ -0,0 +1,110 @@
+// audit_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecordBody tests what is recorded of request bodies in each mode
+func TestRecordBody(t *testing.T) {
+	body := []byte(`{"username":"alice","Password":"hunter2","notifications":{"slack_webhook_url":"https://hooks.slack.com/x"},"items":[{"token":"abc"}]}`)
+
+	// Assert that redaction masks the fields at any depth, ignoring case
+	redacted := Config{Bodies: BodiesRedacted, Redact: DefaultRedact}.RecordBody(body)
+	assert.Equal(t, map[string]interface{}{
+		"username":      "alice",
+		"Password":      Redacted,
+		"notifications": map[string]interface{}{"slack_webhook_url": Redacted},
+		"items":         []interface{}{map[string]interface{}{"token": Redacted}},
+	}, redacted)
+
+	// Assert that the empty mode redacts too and that full mode records the body as sent
+	assert.Equal(t, redacted, Config{Redact: DefaultRedact}.RecordBody(body))
+	full := Config{Bodies: BodiesFull, Redact: DefaultRedact}.RecordBody(body)
+	assert.Equal(t, "hunter2", full.(map[string]interface{})["Password"])
+
+	// Assert that nothing is recorded in none mode, for empty bodies, and for non-JSON bodies unless full
+	assert.Nil(t, Config{Bodies: BodiesNone}.RecordBody(body))
+	assert.Nil(t, Config{Bodies: BodiesRedacted}.RecordBody(nil))
+	assert.Nil(t, Config{Bodies: BodiesRedacted}.RecordBody([]byte("password=hunter2")))
+	assert.Equal(t, "password=hunter2", Config{Bodies: BodiesFull}.RecordBody([]byte("password=hunter2")))
+
+	// Assert that unknown modes are rejected
+	assert.Error(t, Config{Bodies: "some"}.Validate())
+}
+
+// TestFileRotation tests that the audit file is rotated at its maximum size, keeping the configured backups
+func TestFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	file, err := OpenFile(path, 200, 2)
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, file.Write(context.Background(), Entry{Method: "POST", Path: "/tasks", Status: 201}))
+	}
+	require.NoError(t, file.Close())
+
+	// Assert that each file holds whole entries within the limit and older files beyond the backups are gone
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		data, err := os.ReadFile(name)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(data), 200)
+		assert.True(t, strings.HasSuffix(string(data), "}\n"))
+	}
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+
+	// Assert that reopening appends to the current file
+	file, err = OpenFile(path, 0, 2)
+	require.NoError(t, err)
+	before, _ := os.ReadFile(path)
+	require.NoError(t, file.Write(context.Background(), Entry{Method: "DELETE", Path: "/tasks/1", Status: 204}))
+	require.NoError(t, file.Close())
+	after, _ := os.ReadFile(path)
+	assert.True(t, strings.HasPrefix(string(after), string(before)))
+}
+
+// TestFilePrune tests that only rotated files older than the cutoff are removed, and none on a dry run
+func TestFilePrune(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	file, err := OpenFile(path, 200, 3)
+	require.NoError(t, err)
+	for i := 0; i < 8; i++ {
+		require.NoError(t, file.Write(context.Background(), Entry{Method: "POST", Path: "/tasks", Status: 201}))
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(path+".2", old, old))
+	require.NoError(t, os.Chtimes(path+".3", old, old))
+	data, _ := os.ReadFile(path + ".2")
+	entries := strings.Count(string(data), "\n")
+	data, _ = os.ReadFile(path + ".3")
+	entries += strings.Count(string(data), "\n")
+
+	// Assert that a dry run counts the entries of the old files without removing them
+	cutoff := time.Now().Add(-24 * time.Hour)
+	counted, err := file.Prune(context.Background(), cutoff, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(entries), counted)
+	_, err = os.Stat(path + ".3")
+	assert.NoError(t, err)
+
+	pruned, err := file.Prune(context.Background(), cutoff, false)
+	require.NoError(t, err)
+	assert.Equal(t, counted, pruned)
+	for name, kept := range map[string]bool{path: true, path + ".1": true, path + ".2": false, path + ".3": false} {
+		_, err := os.Stat(name)
+		assert.Equal(t, kept, err == nil, name)
+	}
+	require.NoError(t, file.Close())
+}
@@ -0,0 +1,152 @@
+// file.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// File appends audit entries as JSON lines to a file. When the file would grow beyond its maximum size
+// it is renamed to <path>.1, older files shift to <path>.2 and so on, and the oldest beyond the backup
+// count is removed.
+type File struct {
+	path       string
+	maxBytes   int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenFile opens, or creates, an audit file.
+//
+// Parameters:
+// - path: The path of the current file.
+// - maxBytes: The size at which the file is rotated; 0 never rotates.
+// - maxBackups: The number of rotated files kept.
+//
+// Returns:
+// - *File: The sink.
+// - error: An error if the file cannot be opened.
+func OpenFile(path string, maxBytes int64, maxBackups int) (*File, error) {
+	f := &File{path: path, maxBytes: maxBytes, maxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends an entry, rotating the file first if the entry does not fit.
+func (f *File) Write(ctx context.Context, entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.maxBytes > 0 && f.size > 0 && f.size+int64(len(line)) > f.maxBytes {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := f.file.Write(line)
+	f.size += int64(n)
+	return err
+}
+
+// Close closes the current file.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
+
+// Prune removes the rotated files last written before a time, all of whose entries are older. The current
+// file is never removed.
+func (f *File) Prune(ctx context.Context, before time.Time, dryRun bool) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var pruned int64
+	for i := 1; i <= f.maxBackups; i++ {
+		info, err := os.Stat(f.backup(i))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return pruned, err
+		}
+		if !info.ModTime().Before(before) {
+			continue
+		}
+
+		data, err := os.ReadFile(f.backup(i))
+		if err != nil {
+			return pruned, err
+		}
+		if !dryRun {
+			if err := os.Remove(f.backup(i)); err != nil {
+				return pruned, err
+			}
+		}
+		pruned += int64(bytes.Count(data, []byte{'\n'}))
+	}
+	return pruned, nil
+}
+
+// open opens the current file for appending and reads its size.
+func (f *File) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// rotate shifts the backups, moves the current file to the first backup and starts a new file.
+func (f *File) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	if f.maxBackups <= 0 {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return f.open()
+	}
+
+	_ = os.Remove(f.backup(f.maxBackups))
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(f.backup(i), f.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(f.path, f.backup(1)); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// backup returns the path of the n-th rotated file.
+func (f *File) backup(n int) string {
+	return fmt.Sprintf("%s.%d", f.path, n)
+}
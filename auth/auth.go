@@ -0,0 +1,72 @@
+// auth.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package auth checks the credentials of a sign-in. By default they are checked against the password
+// hashes in the users collection; enterprise deployments can check them against their LDAP or Active
+// Directory instead. Either way the service issues its own JWTs afterwards.
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/utils"
+)
+
+// Names of the providers
+const (
+	ProviderLocal = "local"
+	ProviderLDAP  = "ldap"
+)
+
+// Errors returned by Provider.Authenticate
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrUnavailable        = errors.New("directory could not be reached")
+)
+
+// Identity is who a sign-in was authenticated as.
+type Identity struct {
+	Username string       // Username of the account in the service
+	Email    string       // Email address, if the provider knows it
+	Subject  string       // Stable ID of the user at the directory, e.g. the DN; the account is linked to it
+	User     *models.User // The stored account, if the provider looked it up; nil for directory users
+}
+
+// Provider authenticates sign-ins. The default provider checks the stored password hashes; a directory
+// can be plugged in by replacing Default.
+type Provider interface {
+	// Name returns ProviderLocal or the kind of directory, e.g. ProviderLDAP.
+	Name() string
+	// Authenticate checks a username and password. It returns ErrInvalidCredentials if they are wrong and
+	// an error wrapping ErrUnavailable if the provider could not tell.
+	Authenticate(ctx context.Context, username, password string) (Identity, error)
+}
+
+// Default is the provider the sign-in handler authenticates with.
+var Default Provider = Local{}
+
+// Local is a Provider checking the password hashes of the users collection.
+type Local struct{}
+
+// Name returns ProviderLocal.
+func (Local) Name() string {
+	return ProviderLocal
+}
+
+// Authenticate checks the password against the hash stored for the user.
+func (Local) Authenticate(ctx context.Context, username, password string) (Identity, error) {
+	user, err := repository.Users.FindByUsername(ctx, username)
+	if err == repository.ErrNotFound {
+		return Identity{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return Identity{}, err
+	}
+	if !utils.CheckPasswordHash(password, user.Password) {
+		return Identity{}, ErrInvalidCredentials
+	}
+	return Identity{Username: user.Username, Email: user.Email, User: user}, nil
+}
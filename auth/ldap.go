@@ -0,0 +1,248 @@
+// ldap.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/helper"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// searchSizeLimit is the number of entries a user search returns at most, enough to tell that a user filter
+// is ambiguous.
+const searchSizeLimit = 2
+
+// userFilterParts are the parts of the filter syntax user filters may use. Substring and approximate
+// matches are not among them, as they would let a part of one username sign in as another user.
+var userFilterParts = map[ber.Tag]bool{
+	ldap.FilterAnd:           true,
+	ldap.FilterOr:            true,
+	ldap.FilterNot:           true,
+	ldap.FilterEqualityMatch: true,
+	ldap.FilterPresent:       true,
+}
+
+// LDAP is a Provider on an LDAP directory or Active Directory, spoken to with go-ldap. A sign-in is checked
+// in two steps: the user's entry is searched with UserFilter, bound as BindDN or anonymously, and then the
+// directory is asked to bind as that entry with the given password. Passwords are never stored by the
+// service.
+type LDAP struct {
+	URL               string        // ldap://host:389 or ldaps://host:636
+	StartTLS          bool          // Upgrade ldap:// connections with StartTLS before sending credentials
+	BindDN            string        // Service account searching for users; anonymous when empty
+	BindPassword      string        // Password of BindDN
+	BaseDN            string        // Entry below which users are searched, e.g. ou=people,dc=example,dc=com
+	UserFilter        string        // Filter finding a user, with %s for the username, e.g. (uid=%s)
+	UsernameAttribute string        // Attribute holding the username of the account, e.g. uid or sAMAccountName
+	EmailAttribute    string        // Attribute holding the email address, e.g. mail
+	Timeout           time.Duration // Timeout of a sign-in against the directory; 10s when zero
+	TLSConfig         *tls.Config   // TLS settings of ldaps:// and StartTLS; system roots when nil
+}
+
+// LoadLDAP creates the LDAP provider when AUTH_PROVIDER is "ldap", from LDAP_URL, LDAP_START_TLS,
+// LDAP_BIND_DN, LDAP_BIND_PASSWORD, LDAP_BASE_DN, LDAP_USER_FILTER (default "(uid=%s)"),
+// LDAP_USERNAME_ATTRIBUTE (default "uid"), LDAP_EMAIL_ATTRIBUTE (default "mail"), LDAP_TIMEOUT in seconds
+// and LDAP_CA_FILE. Sign-ins are checked against the stored passwords when AUTH_PROVIDER is empty or
+// "local".
+//
+// Returns:
+// - *LDAP: The configured provider, or nil when none is configured.
+// - error: An error if the provider is unknown or its settings are invalid.
+func LoadLDAP() (*LDAP, error) {
+	switch kind := helper.GetEnv("AUTH_PROVIDER"); kind {
+	case "", ProviderLocal:
+		return nil, nil
+	case ProviderLDAP:
+	default:
+		return nil, fmt.Errorf("unknown AUTH_PROVIDER %q, use local or ldap", kind)
+	}
+
+	directory := &LDAP{
+		URL:               helper.GetEnv("LDAP_URL"),
+		StartTLS:          helper.GetEnv("LDAP_START_TLS") == "true",
+		BindDN:            helper.GetEnv("LDAP_BIND_DN"),
+		BindPassword:      helper.GetEnv("LDAP_BIND_PASSWORD"),
+		BaseDN:            helper.GetEnv("LDAP_BASE_DN"),
+		UserFilter:        helper.GetEnv("LDAP_USER_FILTER"),
+		UsernameAttribute: helper.GetEnv("LDAP_USERNAME_ATTRIBUTE"),
+		EmailAttribute:    helper.GetEnv("LDAP_EMAIL_ATTRIBUTE"),
+		Timeout:           time.Duration(helper.GetEnvInt("LDAP_TIMEOUT", 10)) * time.Second,
+	}
+	if directory.UserFilter == "" {
+		directory.UserFilter = "(uid=%s)"
+	}
+	if directory.UsernameAttribute == "" {
+		directory.UsernameAttribute = "uid"
+	}
+	if directory.EmailAttribute == "" {
+		directory.EmailAttribute = "mail"
+	}
+	if caFile := helper.GetEnv("LDAP_CA_FILE"); caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read LDAP_CA_FILE: %w", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, errors.New("LDAP_CA_FILE contains no PEM certificates")
+		}
+		directory.TLSConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	}
+	if err := directory.Validate(); err != nil {
+		return nil, err
+	}
+	return directory, nil
+}
+
+// Validate rejects a missing URL or base DN, user filters without exactly one %s, invalid or with other
+// parts than userFilterParts, and plain ldap:// URLs without StartTLS, which would send passwords in the clear.
+func (l *LDAP) Validate() error {
+	address, err := url.Parse(l.URL)
+	if err != nil || address.Host == "" || (address.Scheme != "ldap" && address.Scheme != "ldaps") {
+		return fmt.Errorf("LDAP_URL must be an ldap:// or ldaps:// URL, got %q", l.URL)
+	}
+	if address.Scheme == "ldap" && !l.StartTLS {
+		return errors.New("LDAP_URL with ldap:// needs LDAP_START_TLS=true, passwords must not be sent in the clear")
+	}
+	if l.BaseDN == "" {
+		return errors.New("LDAP_BASE_DN must be set")
+	}
+	if strings.Count(l.UserFilter, "%s") != 1 {
+		return fmt.Errorf("LDAP_USER_FILTER must contain %%s exactly once, got %q", l.UserFilter)
+	}
+	filter, err := ldap.CompileFilter(strings.Replace(l.UserFilter, "%s", "user", 1))
+	if err != nil {
+		return fmt.Errorf("invalid LDAP_USER_FILTER: %w", err)
+	}
+	if err := checkFilterParts(filter); err != nil {
+		return fmt.Errorf("invalid LDAP_USER_FILTER: %w", err)
+	}
+	return nil
+}
+
+// checkFilterParts rejects compiled filters using parts other than userFilterParts.
+func checkFilterParts(filter *ber.Packet) error {
+	if !userFilterParts[filter.Tag] {
+		return fmt.Errorf("unsupported filter %s, use only equality, presence, &, | and !", ldap.FilterMap[uint64(filter.Tag)])
+	}
+	if filter.Tag == ldap.FilterEqualityMatch || filter.Tag == ldap.FilterPresent {
+		return nil
+	}
+	for _, operand := range filter.Children {
+		if err := checkFilterParts(operand); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Name returns ProviderLDAP.
+func (l *LDAP) Name() string {
+	return ProviderLDAP
+}
+
+// Authenticate finds the user's entry and binds as it with the password. Empty passwords are rejected
+// up front, since directories treat a bind without a password as an anonymous bind that succeeds. The
+// identity's subject is the DN of the entry, "ldap|<DN>".
+func (l *LDAP) Authenticate(ctx context.Context, username, password string) (Identity, error) {
+	if username == "" || password == "" {
+		return Identity{}, ErrInvalidCredentials
+	}
+	timeout := l.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := l.dial(ctx)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer conn.Close()
+
+	if l.BindDN != "" {
+		if err := conn.Bind(l.BindDN, l.BindPassword); err != nil {
+			return Identity{}, fmt.Errorf("%w: service bind: %v", ErrUnavailable, err)
+		}
+	}
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		l.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, searchSizeLimit, int(timeout/time.Second), false,
+		strings.Replace(l.UserFilter, "%s", ldap.EscapeFilter(username), 1),
+		[]string{l.UsernameAttribute, l.EmailAttribute}, nil,
+	))
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return Identity{}, ErrInvalidCredentials // Ambiguous users get no hint
+	}
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: search: %v", ErrUnavailable, err)
+	}
+	if len(result.Entries) != 1 {
+		return Identity{}, ErrInvalidCredentials // Unknown or ambiguous users get no hint
+	}
+	entry := result.Entries[0]
+
+	err = conn.Bind(entry.DN, password)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return Identity{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: bind: %v", ErrUnavailable, err)
+	}
+
+	identity := Identity{
+		Username: entry.GetEqualFoldAttributeValue(l.UsernameAttribute),
+		Email:    entry.GetEqualFoldAttributeValue(l.EmailAttribute),
+		Subject:  "ldap|" + entry.DN,
+	}
+	if identity.Username == "" {
+		identity.Username = username
+	}
+	return identity, nil
+}
+
+// dial connects to the directory, with TLS for ldaps:// and after StartTLS for ldap:// if configured. The
+// connection and its requests time out with ctx.
+func (l *LDAP) dial(ctx context.Context) (*ldap.Conn, error) {
+	address, err := url.Parse(l.URL)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := l.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	tlsConfig = tlsConfig.Clone()
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = address.Hostname()
+	}
+
+	deadline, _ := ctx.Deadline()
+	conn, err := ldap.DialURL(l.URL, ldap.DialWithDialer(&net.Dialer{Deadline: deadline}), ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(time.Until(deadline))
+
+	if address.Scheme == "ldap" && l.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("starttls: %w", err)
+		}
+	}
+	return conn, nil
+}
@@ -0,0 +1,200 @@
+// ldap_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// directoryEntry is a user of the fake directory.
+type directoryEntry struct {
+	dn, uid, mail, password string
+}
+
+// fakeDirectory serves binds and equality searches on uid over LDAPS, with the certificate of an
+// httptest server, and returns the settings of a provider trusting it.
+func fakeDirectory(t *testing.T, entries []directoryEntry) *LDAP {
+	certificates := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(certificates.Close)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: certificates.TLS.Certificates})
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveDirectory(conn, entries)
+		}
+	}()
+
+	return &LDAP{
+		URL:               "ldaps://" + listener.Addr().String(),
+		BindDN:            "cn=service,dc=example,dc=com",
+		BindPassword:      "service-secret",
+		BaseDN:            "ou=people,dc=example,dc=com",
+		UserFilter:        "(&(objectClass=person)(uid=%s))",
+		UsernameAttribute: "uid",
+		EmailAttribute:    "mail",
+		TLSConfig:         certificates.Client().Transport.(*http.Transport).TLSClientConfig,
+	}
+}
+
+// serveDirectory answers the requests on one connection of the fake directory.
+func serveDirectory(conn net.Conn, entries []directoryEntry) {
+	defer conn.Close()
+	for {
+		message, err := ber.ReadPacket(conn)
+		if err != nil || len(message.Children) < 2 {
+			return
+		}
+		id, request := message.Children[0].Value, message.Children[1]
+		reply := func(response *ber.Packet) {
+			envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Response")
+			envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "Message ID"))
+			envelope.AppendChild(response)
+			_, _ = conn.Write(envelope.Bytes())
+		}
+		result := func(tag ber.Tag, code int) *ber.Packet {
+			response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+			response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "Result code"))
+			response.AppendChild(octetString(""))
+			response.AppendChild(octetString(""))
+			return response
+		}
+
+		switch request.Tag {
+		case ldap.ApplicationBindRequest:
+			dn, password := request.Children[1].Data.String(), request.Children[2].Data.String()
+			code := ldap.LDAPResultInvalidCredentials
+			if dn == "cn=service,dc=example,dc=com" && password == "service-secret" {
+				code = ldap.LDAPResultSuccess
+			}
+			for _, entry := range entries {
+				if dn == entry.dn && password == entry.password {
+					code = ldap.LDAPResultSuccess
+				}
+			}
+			reply(result(ldap.ApplicationBindResponse, int(code)))
+
+		case ldap.ApplicationSearchRequest:
+			uid := equalityValue(request.Children[6], "uid")
+			for _, entry := range entries {
+				if entry.uid == uid {
+					attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+					for _, attribute := range [][2]string{{"uid", entry.uid}, {"mail", entry.mail}} {
+						values := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+						values.AppendChild(octetString(attribute[1]))
+						pair := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+						pair.AppendChild(octetString(attribute[0]))
+						pair.AppendChild(values)
+						attributes.AppendChild(pair)
+					}
+					found := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Entry")
+					found.AppendChild(octetString(entry.dn))
+					found.AppendChild(attributes)
+					reply(found)
+				}
+			}
+			reply(result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+
+		case ldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+// octetString returns an LDAP string.
+func octetString(value string) *ber.Packet {
+	return ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "")
+}
+
+// equalityValue returns the value the filter compares the attribute with, or "".
+func equalityValue(filter *ber.Packet, attribute string) string {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, operand := range filter.Children {
+			if value := equalityValue(operand, attribute); value != "" {
+				return value
+			}
+		}
+	case ldap.FilterEqualityMatch:
+		if filter.Children[0].Data.String() == attribute {
+			return filter.Children[1].Data.String()
+		}
+	}
+	return ""
+}
+
+// TestLDAP tests that users are found with the service account and authenticated by binding as them
+func TestLDAP(t *testing.T) {
+	directory := fakeDirectory(t, []directoryEntry{
+		{dn: "uid=alice,ou=people,dc=example,dc=com", uid: "alice", mail: "alice@example.com", password: "wonderland"},
+		{dn: "uid=*,ou=people,dc=example,dc=com", uid: "*", password: "wildcard"},
+	})
+	require.NoError(t, directory.Validate())
+	ctx := context.Background()
+
+	identity, err := directory.Authenticate(ctx, "alice", "wonderland")
+	require.NoError(t, err)
+	// Assert that the username and email address come from the directory entry
+	assert.Equal(t, Identity{Username: "alice", Email: "alice@example.com", Subject: "ldap|uid=alice,ou=people,dc=example,dc=com"}, identity)
+
+	// Assert that wrong passwords, unknown users and empty passwords are rejected alike
+	_, err = directory.Authenticate(ctx, "alice", "guess")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = directory.Authenticate(ctx, "mallory", "wonderland")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = directory.Authenticate(ctx, "alice", "")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// Assert that usernames are escaped in the filter, so "*" is looked up literally
+	identity, err = directory.Authenticate(ctx, "*", "wildcard")
+	require.NoError(t, err)
+	assert.Equal(t, "*", identity.Username)
+
+	// Assert that a wrong service account and an unreachable directory are reported as unavailable
+	misconfigured := *directory
+	misconfigured.BindPassword = "expired"
+	_, err = misconfigured.Authenticate(ctx, "alice", "wonderland")
+	assert.ErrorIs(t, err, ErrUnavailable)
+	unreachable := *directory
+	unreachable.URL = "ldaps://127.0.0.1:1"
+	_, err = unreachable.Authenticate(ctx, "alice", "wonderland")
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
+
+// TestLDAPValidate tests that insecure URLs and unsupported user filters are rejected
+func TestLDAPValidate(t *testing.T) {
+	valid := LDAP{URL: "ldaps://ldap.example.com", BaseDN: "dc=example,dc=com", UserFilter: "(sAMAccountName=%s)"}
+	assert.NoError(t, valid.Validate())
+
+	for _, invalid := range []LDAP{
+		{URL: "ldap://ldap.example.com", BaseDN: "dc=example,dc=com", UserFilter: "(uid=%s)"},
+		{URL: "https://ldap.example.com", BaseDN: "dc=example,dc=com", UserFilter: "(uid=%s)"},
+		{URL: "ldaps://ldap.example.com", UserFilter: "(uid=%s)"},
+		{URL: "ldaps://ldap.example.com", BaseDN: "dc=example,dc=com", UserFilter: "(uid=alice)"},
+		{URL: "ldaps://ldap.example.com", BaseDN: "dc=example,dc=com", UserFilter: "(cn=%s*)"},
+		{URL: "ldaps://ldap.example.com", BaseDN: "dc=example,dc=com", UserFilter: "(&(uid=%s)"},
+	} {
+		// Assert that the settings are rejected
+		assert.Error(t, invalid.Validate(), invalid)
+	}
+
+	// Assert that StartTLS makes ldap:// acceptable
+	startTLS := LDAP{URL: "ldap://ldap.example.com", StartTLS: true, BaseDN: "dc=example,dc=com", UserFilter: "(uid=%s)"}
+	assert.NoError(t, startTLS.Validate())
+}
@@ -0,0 +1,253 @@
+// backup.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package backup writes the application data to a portable archive and restores it, for deployments
+// without mongodump. An archive is a gzipped tar file holding manifest.json and one <collection>.jsonl
+// file per collection, with a document per line in canonical extended JSON, so that IDs, dates and
+// encrypted fields are restored exactly.
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// FormatVersion is the version of the archive layout written by Create.
+const FormatVersion = 1
+
+// manifestName is the name of the manifest in an archive; it comes first.
+const manifestName = "manifest.json"
+
+// batchSize is the number of documents inserted at once by Restore.
+const batchSize = 1000
+
+// maxLine is the size of the largest document line read, above MongoDB's 16 MB document limit.
+const maxLine = 32 << 20
+
+// Collections are the collections backed up. Derived data, such as the task list view, counters and the
+// search index, is rebuilt instead, and operational data, such as sessions, sign-ins, leases, nonces, the
+// outbox, webhook deliveries, dead letters and the audit log, is left out.
+var Collections = []string{
+	"users", "workspaces", "projects", "tasks", "trash", "comments", "invitations", "invite_codes",
+	"settings", "webhooks", "report_schedules", "subscriptions", "tombstones", "focus_sessions",
+}
+
+// ErrNotEmpty is returned by Restore when a collection to restore holds documents and replace is off.
+var ErrNotEmpty = errors.New("collection is not empty")
+
+// Manifest describes an archive.
+type Manifest struct {
+	Format      int              `json:"format"`
+	CreatedAt   time.Time        `json:"created_at"`
+	Schema      int              `json:"schema"`      // Latest migration applied to the backed up database
+	Collections map[string]int64 `json:"collections"` // Documents per collection
+}
+
+// Store reads and writes the collections of a database.
+type Store interface {
+	// SchemaVersion returns the latest migration applied to the database.
+	SchemaVersion(ctx context.Context) (int, error)
+	// Count returns the number of documents in a collection.
+	Count(ctx context.Context, collection string) (int64, error)
+	// Dump calls fn with every document of a collection, in _id order.
+	Dump(ctx context.Context, collection string, fn func(doc bson.Raw) error) error
+	// Insert inserts documents into a collection.
+	Insert(ctx context.Context, collection string, docs []bson.Raw) error
+	// Clear deletes all documents of a collection.
+	Clear(ctx context.Context, collection string) error
+}
+
+// Create writes the Collections of a store to an archive. Documents written while it runs may or may not
+// be included; stop writes for a consistent backup.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - store: The database to back up.
+// - w: Where the archive is written.
+// - now: The creation time recorded in the manifest.
+//
+// Returns:
+// - Manifest: The manifest of the archive.
+// - error: An error if the database could not be read or the archive written.
+func Create(ctx context.Context, store Store, w io.Writer, now time.Time) (Manifest, error) {
+	manifest := Manifest{Format: FormatVersion, CreatedAt: now.UTC(), Collections: map[string]int64{}}
+	var err error
+	if manifest.Schema, err = store.SchemaVersion(ctx); err != nil {
+		return manifest, err
+	}
+
+	// Each collection is dumped to memory first, as tar entries need their size up front
+	files := map[string][]byte{}
+	for _, collection := range Collections {
+		manifest.Collections[collection] = 0
+		var buf bytes.Buffer
+		err := store.Dump(ctx, collection, func(doc bson.Raw) error {
+			line, err := bson.MarshalExtJSON(doc, true, false)
+			if err != nil {
+				return err
+			}
+			buf.Write(line)
+			buf.WriteByte('\n')
+			manifest.Collections[collection]++
+			return nil
+		})
+		if err != nil {
+			return manifest, fmt.Errorf("dumping %s: %w", collection, err)
+		}
+		files[collection] = buf.Bytes()
+	}
+
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return manifest, err
+	}
+	if err := writeFile(archive, manifestName, data, now); err != nil {
+		return manifest, err
+	}
+	for _, collection := range Collections {
+		if err := writeFile(archive, collection+".jsonl", files[collection], now); err != nil {
+			return manifest, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return manifest, err
+	}
+	return manifest, gz.Close()
+}
+
+// Restore loads an archive into a store. Unless replace is set, every collection in the archive must be
+// empty; with replace, their documents are deleted first. The schema of the archive must not be newer
+// than that of the store, so migrate the store up first.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - store: The database to restore into.
+// - r: The archive.
+// - replace: Whether to delete the documents of the restored collections first.
+//
+// Returns:
+// - Manifest: The manifest of the archive.
+// - error: An error if the archive is invalid, the store not empty or the documents could not be written.
+func Restore(ctx context.Context, store Store, r io.Reader, replace bool) (Manifest, error) {
+	var manifest Manifest
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return manifest, fmt.Errorf("not a backup archive: %w", err)
+	}
+	archive := tar.NewReader(gz)
+
+	header, err := archive.Next()
+	if err != nil || header.Name != manifestName {
+		return manifest, errors.New("not a backup archive: the manifest is missing")
+	}
+	if err := json.NewDecoder(archive).Decode(&manifest); err != nil {
+		return manifest, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.Format != FormatVersion {
+		return manifest, fmt.Errorf("unsupported archive format %d", manifest.Format)
+	}
+	schema, err := store.SchemaVersion(ctx)
+	if err != nil {
+		return manifest, err
+	}
+	if manifest.Schema > schema {
+		return manifest, fmt.Errorf("the archive has schema version %d but the database %d, run the migrations first", manifest.Schema, schema)
+	}
+
+	for collection := range manifest.Collections {
+		if !known(collection) {
+			return manifest, fmt.Errorf("unknown collection %q in the archive", collection)
+		}
+		if replace {
+			continue
+		}
+		count, err := store.Count(ctx, collection)
+		if err != nil {
+			return manifest, err
+		}
+		if count > 0 {
+			return manifest, fmt.Errorf("%s: %w, restore with replace to overwrite it", collection, ErrNotEmpty)
+		}
+	}
+
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return manifest, nil
+		}
+		if err != nil {
+			return manifest, fmt.Errorf("reading the archive: %w", err)
+		}
+		collection := strings.TrimSuffix(header.Name, ".jsonl")
+		if _, ok := manifest.Collections[collection]; !ok {
+			return manifest, fmt.Errorf("unexpected file %q in the archive", header.Name)
+		}
+		if replace {
+			if err := store.Clear(ctx, collection); err != nil {
+				return manifest, err
+			}
+		}
+		if err := load(ctx, store, collection, archive); err != nil {
+			return manifest, fmt.Errorf("restoring %s: %w", collection, err)
+		}
+	}
+}
+
+// load inserts the documents of a collection file in batches.
+func load(ctx context.Context, store Store, collection string, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLine)
+	batch := make([]bson.Raw, 0, batchSize)
+	for scanner.Scan() {
+		var doc bson.Raw
+		if err := bson.UnmarshalExtJSON(scanner.Bytes(), true, &doc); err != nil {
+			return err
+		}
+		if batch = append(batch, doc); len(batch) == batchSize {
+			if err := store.Insert(ctx, collection, batch); err != nil {
+				return err
+			}
+			batch = make([]bson.Raw, 0, batchSize)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		return store.Insert(ctx, collection, batch)
+	}
+	return nil
+}
+
+// writeFile adds a file to an archive.
+func writeFile(archive *tar.Writer, name string, data []byte, now time.Time) error {
+	header := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), ModTime: now}
+	if err := archive.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := archive.Write(data)
+	return err
+}
+
+// known reports whether a collection is one of the Collections.
+func known(collection string) bool {
+	for _, name := range Collections {
+		if name == collection {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+// backup_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryStore keeps collections in memory.
+type memoryStore struct {
+	schema      int
+	collections map[string][]bson.Raw
+}
+
+func (s *memoryStore) SchemaVersion(ctx context.Context) (int, error) { return s.schema, nil }
+
+func (s *memoryStore) Count(ctx context.Context, collection string) (int64, error) {
+	return int64(len(s.collections[collection])), nil
+}
+
+func (s *memoryStore) Dump(ctx context.Context, collection string, fn func(doc bson.Raw) error) error {
+	for _, doc := range s.collections[collection] {
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) Insert(ctx context.Context, collection string, docs []bson.Raw) error {
+	s.collections[collection] = append(s.collections[collection], docs...)
+	return nil
+}
+
+func (s *memoryStore) Clear(ctx context.Context, collection string) error {
+	delete(s.collections, collection)
+	return nil
+}
+
+// TestCreateAndRestore tests that an archive restores the documents exactly, and only into an empty or
+// replaced database of the same or a newer schema
+func TestCreateAndRestore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 7, 5, 12, 0, 0, 0, time.UTC)
+	userID := primitive.NewObjectID()
+	raw := func(doc bson.M) bson.Raw {
+		data, err := bson.Marshal(doc)
+		require.NoError(t, err)
+		return data
+	}
+	source := &memoryStore{schema: 26, collections: map[string][]bson.Raw{
+		"users": {raw(bson.M{"_id": userID, "username": "alice", "password": "$2a$10$hash"})},
+		"tasks": {
+			raw(bson.M{"_id": primitive.NewObjectID(), "userId": userID, "title": "One", "created_at": primitive.NewDateTimeFromTime(now), "estimate": 1.5}),
+			raw(bson.M{"_id": primitive.NewObjectID(), "userId": userID, "title": "Two", "description": "enc:v1:abc", "version": int64(3)}),
+		},
+		"sessions": {raw(bson.M{"_id": primitive.NewObjectID()})}, // Not backed up
+	}}
+
+	var archive bytes.Buffer
+	manifest, err := Create(ctx, source, &archive, now)
+	require.NoError(t, err)
+	assert.Equal(t, 26, manifest.Schema)
+	assert.Equal(t, int64(2), manifest.Collections["tasks"])
+	assert.Zero(t, manifest.Collections["comments"])
+	assert.NotContains(t, manifest.Collections, "sessions")
+
+	// Assert that the documents come back byte for byte, so IDs, dates and number types are kept
+	target := &memoryStore{schema: 27, collections: map[string][]bson.Raw{}}
+	restored, err := Restore(ctx, target, bytes.NewReader(archive.Bytes()), false)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, restored)
+	for _, collection := range []string{"users", "tasks"} {
+		assert.Equal(t, source.collections[collection], target.collections[collection], collection)
+	}
+	assert.Empty(t, target.collections["sessions"])
+
+	// Assert that a second restore needs replace, which overwrites instead of duplicating
+	_, err = Restore(ctx, target, bytes.NewReader(archive.Bytes()), false)
+	assert.ErrorIs(t, err, ErrNotEmpty)
+	_, err = Restore(ctx, target, bytes.NewReader(archive.Bytes()), true)
+	require.NoError(t, err)
+	assert.Len(t, target.collections["tasks"], 2)
+
+	// Assert that older schemas and other files are rejected
+	_, err = Restore(ctx, &memoryStore{schema: 25, collections: map[string][]bson.Raw{}}, bytes.NewReader(archive.Bytes()), false)
+	assert.ErrorContains(t, err, "run the migrations first")
+	_, err = Restore(ctx, target, bytes.NewReader([]byte("not an archive")), true)
+	assert.Error(t, err)
+}
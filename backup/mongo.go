@@ -0,0 +1,69 @@
+// mongo.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package backup
+
+import (
+	"context"
+
+	"github.com/bkojha74/task-management/database"
+	"github.com/bkojha74/task-management/migrations"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Mongo is the Store of the application database opened by database.Init.
+type Mongo struct{}
+
+// db returns the application database.
+func (Mongo) db() *mongo.Database {
+	return database.MongoClient.Database(database.Name)
+}
+
+// SchemaVersion returns the latest applied migration.
+func (m Mongo) SchemaVersion(ctx context.Context) (int, error) {
+	applied, err := migrations.Status(ctx, m.db())
+	if err != nil || len(applied) == 0 {
+		return 0, err
+	}
+	return applied[len(applied)-1].Version, nil
+}
+
+// Count returns the number of documents in a collection.
+func (m Mongo) Count(ctx context.Context, collection string) (int64, error) {
+	return m.db().Collection(collection).CountDocuments(ctx, bson.M{})
+}
+
+// Dump calls fn with every document of a collection.
+func (m Mongo) Dump(ctx context.Context, collection string, fn func(doc bson.Raw) error) error {
+	cursor, err := m.db().Collection(collection).Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		if err := fn(cursor.Current); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// Insert inserts documents into a collection.
+func (m Mongo) Insert(ctx context.Context, collection string, docs []bson.Raw) error {
+	values := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		values[i] = doc
+	}
+	_, err := m.db().Collection(collection).InsertMany(ctx, values)
+	return err
+}
+
+// Clear deletes all documents of a collection.
+func (m Mongo) Clear(ctx context.Context, collection string) error {
+	_, err := m.db().Collection(collection).DeleteMany(ctx, bson.M{})
+	return err
+}
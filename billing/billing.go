@@ -0,0 +1,355 @@
+// billing.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package billing keeps the plan of each workspace in step with its Stripe subscription and derives the
+// workspace's entitlements, its quotas and features, from the plan. The billing state is stored in the
+// subscriptions collection; checkout and the customer portal stay with Stripe.
+package billing
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/features"
+	"github.com/bkojha74/task-management/helper"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/quota"
+	"github.com/bkojha74/task-management/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SignatureHeader is the header Stripe sends the webhook signature in.
+const SignatureHeader = "Stripe-Signature"
+
+// signatureTolerance is how old a signed webhook may be, against replays.
+const signatureTolerance = 5 * time.Minute
+
+var (
+	// ErrInvalidSignature is returned by VerifySignature for missing, forged or expired signatures.
+	ErrInvalidSignature = errors.New("invalid Stripe signature")
+
+	// ErrUnknownWorkspace is returned by HandleEvent for subscriptions not linked to a workspace yet, e.g.
+	// when Stripe sends the subscription before the checkout session; Stripe retries them later.
+	ErrUnknownWorkspace = errors.New("subscription is not linked to a workspace")
+)
+
+// entitledStatuses are the subscription statuses that keep the paid plan; past_due keeps it while Stripe
+// retries the payment.
+var entitledStatuses = map[string]bool{"active": true, "trialing": true, "past_due": true}
+
+// Plan is a billing plan and what it entitles a workspace to.
+type Plan struct {
+	Name     string       `json:"name"`
+	Quota    quota.Limits `json:"quota"`    // Limits of the workspace; 0 is unlimited
+	Features []string     `json:"features"` // Feature flags enabled for the members, on top of FEATURE_FLAGS
+}
+
+// Stripe is the Stripe account the workspaces subscribe through.
+type Stripe struct {
+	WebhookSecret string // Signing secret of the webhook endpoint posting to /billing/stripe/webhook
+	ProPriceID    string // Price of the pro plan; any entitled subscription is pro when empty
+	Pro           Plan   // Entitlements of the pro plan
+}
+
+// Default is the Stripe account of the process, set by the app; nil puts all workspaces on the free plan.
+var Default *Stripe
+
+// LoadStripe reads the STRIPE_WEBHOOK_SECRET, STRIPE_PRO_PRICE_ID, PLAN_PRO_TASKS, PLAN_PRO_WEBHOOKS and
+// PLAN_PRO_FEATURES environment variables.
+//
+// Returns:
+// - *Stripe: The Stripe account, or nil if STRIPE_WEBHOOK_SECRET is not set.
+// - error: An error if the account is configured incompletely.
+func LoadStripe() (*Stripe, error) {
+	secret := helper.GetEnv("STRIPE_WEBHOOK_SECRET")
+	if secret == "" {
+		return nil, nil
+	}
+	proFeatures := helper.GetEnv("PLAN_PRO_FEATURES")
+	if proFeatures == "" {
+		proFeatures = features.DuplicateCheck
+	}
+	stripe := &Stripe{
+		WebhookSecret: secret,
+		ProPriceID:    helper.GetEnv("STRIPE_PRO_PRICE_ID"),
+		Pro: Plan{
+			Name:     models.PlanPro,
+			Quota:    quota.Limits{Tasks: helper.GetEnvInt("PLAN_PRO_TASKS", 0), Webhooks: helper.GetEnvInt("PLAN_PRO_WEBHOOKS", 0)},
+			Features: features.Parse(proFeatures),
+		},
+	}
+	if err := stripe.Validate(); err != nil {
+		return nil, err
+	}
+	return stripe, nil
+}
+
+// Validate checks that the account has a webhook signing secret and sane pro entitlements.
+//
+// Returns:
+// - error: A descriptive error if a setting is missing or invalid.
+func (s *Stripe) Validate() error {
+	if !strings.HasPrefix(s.WebhookSecret, "whsec_") {
+		return errors.New("STRIPE_WEBHOOK_SECRET must be the whsec_ signing secret of the webhook endpoint")
+	}
+	if s.Pro.Quota.Tasks < 0 || s.Pro.Quota.Webhooks < 0 {
+		return errors.New("PLAN_PRO_TASKS and PLAN_PRO_WEBHOOKS must not be negative")
+	}
+	return nil
+}
+
+// VerifySignature checks the Stripe-Signature header of a webhook: a "t" timestamp within
+// signatureTolerance of now and a "v1" HMAC-SHA256 of "t.payload" with the webhook secret.
+//
+// Parameters:
+// - header: The value of the Stripe-Signature header.
+// - payload: The raw request body.
+// - now: The current time.
+//
+// Returns:
+// - error: ErrInvalidSignature if the signature is missing, forged or too old.
+func (s *Stripe) VerifySignature(header string, payload []byte, now time.Time) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > signatureTolerance || age < -signatureTolerance {
+		return ErrInvalidSignature
+	}
+
+	expected := s.sign(timestamp, payload)
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// sign returns the hex v1 signature of a payload sent at a timestamp.
+func (s *Stripe) sign(timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(s.WebhookSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// event is a Stripe webhook event.
+type event struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// checkoutSession is the object of checkout.session.completed; client_reference_id is the workspace ID
+// the checkout was started for.
+type checkoutSession struct {
+	ClientReferenceID string `json:"client_reference_id"`
+	Customer          string `json:"customer"`
+	Subscription      string `json:"subscription"`
+}
+
+// subscriptionObject is the object of the customer.subscription events; metadata.workspace_id links it to
+// a workspace when the checkout session has not.
+type subscriptionObject struct {
+	ID               string            `json:"id"`
+	Customer         string            `json:"customer"`
+	Status           string            `json:"status"`
+	Metadata         map[string]string `json:"metadata"`
+	CurrentPeriodEnd int64             `json:"current_period_end"`
+	Items            struct {
+		Data []struct {
+			Price struct {
+				ID string `json:"id"`
+			} `json:"price"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+// HandleEvent applies a verified Stripe webhook event to the billing state: checkout.session.completed
+// links a Stripe customer to a workspace, and customer.subscription.created, .updated and .deleted move the
+// workspace between the free and pro plans. Events older than the last applied one and other event types
+// are ignored.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - payload: The raw event.
+//
+// Returns:
+// - string: The plan of the workspace after the event, empty if the event was ignored.
+// - error: ErrUnknownWorkspace if a subscription is not linked to a workspace yet, another error if the
+// event is malformed or could not be stored.
+func (s *Stripe) HandleEvent(ctx context.Context, payload []byte) (string, error) {
+	var e event
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return "", fmt.Errorf("malformed event: %w", err)
+	}
+
+	switch e.Type {
+	case "checkout.session.completed":
+		var session checkoutSession
+		if err := json.Unmarshal(e.Data.Object, &session); err != nil {
+			return "", fmt.Errorf("malformed checkout session: %w", err)
+		}
+		workspaceID, err := primitive.ObjectIDFromHex(session.ClientReferenceID)
+		if err != nil {
+			return "", nil // A checkout of something else
+		}
+		subscription, err := s.find(ctx, workspaceID)
+		if err != nil {
+			return "", err
+		}
+		subscription.StripeCustomerID = session.Customer
+		if session.Subscription != "" {
+			subscription.StripeSubscriptionID = session.Subscription
+		}
+		return subscription.Plan, repository.Subscriptions.Save(ctx, subscription)
+
+	case "customer.subscription.created", "customer.subscription.updated", "customer.subscription.deleted":
+		var object subscriptionObject
+		if err := json.Unmarshal(e.Data.Object, &object); err != nil {
+			return "", fmt.Errorf("malformed subscription: %w", err)
+		}
+		subscription, err := s.link(ctx, object)
+		if err != nil {
+			return "", err
+		}
+		if subscription.LastEventAt.Time().After(time.Unix(e.Created, 0)) {
+			return "", nil // Stripe does not deliver events in order
+		}
+		if e.Type == "customer.subscription.deleted" && subscription.StripeSubscriptionID != "" && subscription.StripeSubscriptionID != object.ID {
+			return "", nil // An earlier subscription the workspace replaced
+		}
+
+		subscription.StripeCustomerID = object.Customer
+		subscription.StripeSubscriptionID = object.ID
+		subscription.Status = object.Status
+		if e.Type == "customer.subscription.deleted" {
+			subscription.Status = "canceled"
+		}
+		subscription.PriceID = ""
+		if len(object.Items.Data) > 0 {
+			subscription.PriceID = object.Items.Data[0].Price.ID
+		}
+		subscription.CurrentPeriodEnd = 0
+		if object.CurrentPeriodEnd > 0 {
+			subscription.CurrentPeriodEnd = models.NewTimestamp(time.Unix(object.CurrentPeriodEnd, 0))
+		}
+		subscription.Plan = models.PlanFree
+		if entitledStatuses[subscription.Status] && (s.ProPriceID == "" || s.ProPriceID == subscription.PriceID) {
+			subscription.Plan = models.PlanPro
+		}
+		subscription.LastEventAt = models.NewTimestamp(time.Unix(e.Created, 0))
+		return subscription.Plan, repository.Subscriptions.Save(ctx, subscription)
+	}
+	return "", nil
+}
+
+// link returns the stored subscription of the workspace a Stripe subscription belongs to, by its
+// metadata or by its customer.
+func (s *Stripe) link(ctx context.Context, object subscriptionObject) (*models.Subscription, error) {
+	if workspaceID, err := primitive.ObjectIDFromHex(object.Metadata["workspace_id"]); err == nil {
+		return s.find(ctx, workspaceID)
+	}
+	subscription, err := repository.Subscriptions.FindByCustomer(ctx, object.Customer)
+	if err == repository.ErrNotFound {
+		return nil, ErrUnknownWorkspace
+	}
+	return subscription, err
+}
+
+// find returns the stored subscription of a workspace, or a new one on the free plan.
+func (s *Stripe) find(ctx context.Context, workspaceID primitive.ObjectID) (*models.Subscription, error) {
+	subscription, err := repository.Subscriptions.FindByWorkspace(ctx, workspaceID)
+	if err == repository.ErrNotFound {
+		return &models.Subscription{WorkspaceID: workspaceID, Plan: models.PlanFree}, nil
+	}
+	return subscription, err
+}
+
+// Free returns the free plan: the QUOTA_WORKSPACE_* limits and no features beyond FEATURE_FLAGS.
+func Free() Plan {
+	return Plan{Name: models.PlanFree, Quota: quota.Workspace, Features: []string{}}
+}
+
+// PlanOf returns the plan of a workspace, the free plan unless its subscription is on the pro plan.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - workspaceID: The workspace.
+//
+// Returns:
+// - Plan: The plan.
+// - *models.Subscription: The billing state, nil if the workspace never subscribed or billing is disabled.
+// - error: An error if the subscription could not be read.
+func PlanOf(ctx context.Context, workspaceID primitive.ObjectID) (Plan, *models.Subscription, error) {
+	if Default == nil || workspaceID.IsZero() {
+		return Free(), nil, nil
+	}
+	subscription, err := repository.Subscriptions.FindByWorkspace(ctx, workspaceID)
+	if err == repository.ErrNotFound {
+		return Free(), nil, nil
+	}
+	if err != nil {
+		return Plan{}, nil, err
+	}
+	if subscription.Plan == models.PlanPro {
+		return Default.Pro, subscription, nil
+	}
+	return Free(), subscription, nil
+}
+
+// WorkspaceLimits returns the quota of a workspace's plan, for quota.WorkspaceLimits.
+func WorkspaceLimits(ctx context.Context, workspaceID primitive.ObjectID) (quota.Limits, error) {
+	plan, _, err := PlanOf(ctx, workspaceID)
+	return plan.Quota, err
+}
+
+// FeatureEnabled reports whether a feature flag is enabled for a user: for everyone by FEATURE_FLAGS, or
+// for the members of a workspace by its plan. Errors reading the plan count as disabled.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - user: The user.
+// - name: The feature flag.
+//
+// Returns:
+// - bool: Whether the feature is enabled.
+func FeatureEnabled(ctx context.Context, user models.User, name string) bool {
+	if features.Enabled(name) {
+		return true
+	}
+	plan, _, err := PlanOf(ctx, user.WorkspaceID)
+	if err != nil {
+		return false
+	}
+	for _, feature := range plan.Features {
+		if feature == strings.ToLower(name) {
+			return true
+		}
+	}
+	return false
+}
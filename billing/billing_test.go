@@ -0,0 +1,102 @@
+// billing_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package billing
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/features"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/quota"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var testStripe = &Stripe{
+	WebhookSecret: "whsec_test",
+	ProPriceID:    "price_pro",
+	Pro:           Plan{Name: models.PlanPro, Quota: quota.Limits{Tasks: 1000}, Features: []string{features.DuplicateCheck}},
+}
+
+// TestVerifySignature tests that only fresh events signed with the webhook secret are accepted
+func TestVerifySignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	payload := []byte(`{"id":"evt_1"}`)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := testStripe.sign(timestamp, payload)
+
+	assert.NoError(t, testStripe.VerifySignature("t="+timestamp+",v1=bad,v1="+signature, payload, now))
+	assert.NoError(t, testStripe.VerifySignature("t="+timestamp+",v1="+signature, payload, now.Add(time.Minute)))
+	assert.ErrorIs(t, testStripe.VerifySignature("t="+timestamp+",v1="+signature, []byte(`{"id":"evt_2"}`), now), ErrInvalidSignature)
+	assert.ErrorIs(t, testStripe.VerifySignature("t="+timestamp+",v1="+signature, payload, now.Add(10*time.Minute)), ErrInvalidSignature)
+	assert.ErrorIs(t, testStripe.VerifySignature("v1="+signature, payload, now), ErrInvalidSignature)
+	assert.ErrorIs(t, testStripe.VerifySignature("", payload, now), ErrInvalidSignature)
+}
+
+// subscriptionEvent returns a customer.subscription event of the test customer.
+func subscriptionEvent(kind string, created int64, status, price, metadata string) []byte {
+	return []byte(fmt.Sprintf(`{"id":"evt_%d","type":"customer.subscription.%s","created":%d,"data":{"object":{
+		"id":"sub_1","customer":"cus_1","status":%q,"metadata":{%s},"current_period_end":1800000000,
+		"items":{"data":[{"price":{"id":%q}}]}}}}`, created, kind, created, status, metadata, price))
+}
+
+// TestHandleEvent tests that subscription events move a workspace between plans and its entitlements
+func TestHandleEvent(t *testing.T) {
+	repository.UseMemory()
+	ctx := context.Background()
+	Default = testStripe
+	defer func() { Default = nil }()
+	workspace := primitive.NewObjectID()
+	member := models.User{Username: "member", WorkspaceID: workspace}
+
+	// Subscriptions of unknown customers are retried until the checkout links them
+	_, err := testStripe.HandleEvent(ctx, subscriptionEvent("created", 100, "active", "price_pro", ""))
+	assert.ErrorIs(t, err, ErrUnknownWorkspace)
+
+	checkout := fmt.Sprintf(`{"type":"checkout.session.completed","created":90,"data":{"object":{
+		"client_reference_id":%q,"customer":"cus_1","subscription":"sub_1"}}}`, workspace.Hex())
+	plan, err := testStripe.HandleEvent(ctx, []byte(checkout))
+	require.NoError(t, err)
+	assert.Equal(t, models.PlanFree, plan)
+	assert.False(t, FeatureEnabled(ctx, member, features.DuplicateCheck))
+
+	plan, err = testStripe.HandleEvent(ctx, subscriptionEvent("created", 100, "active", "price_pro", ""))
+	require.NoError(t, err)
+	assert.Equal(t, models.PlanPro, plan)
+	limits, err := WorkspaceLimits(ctx, workspace)
+	require.NoError(t, err)
+	assert.Equal(t, quota.Limits{Tasks: 1000}, limits)
+	assert.True(t, FeatureEnabled(ctx, member, features.DuplicateCheck))
+	assert.False(t, FeatureEnabled(ctx, models.User{Username: "outsider"}, features.DuplicateCheck))
+
+	// Older events are skipped, failed payments keep the plan until the subscription ends
+	plan, err = testStripe.HandleEvent(ctx, subscriptionEvent("updated", 50, "canceled", "price_pro", ""))
+	require.NoError(t, err)
+	assert.Empty(t, plan)
+	plan, err = testStripe.HandleEvent(ctx, subscriptionEvent("updated", 200, "past_due", "price_pro", ""))
+	require.NoError(t, err)
+	assert.Equal(t, models.PlanPro, plan)
+	plan, err = testStripe.HandleEvent(ctx, subscriptionEvent("deleted", 300, "active", "price_pro", ""))
+	require.NoError(t, err)
+	assert.Equal(t, models.PlanFree, plan)
+
+	stored, err := repository.Subscriptions.FindByWorkspace(ctx, workspace)
+	require.NoError(t, err)
+	assert.Equal(t, "canceled", stored.Status)
+	assert.Equal(t, int64(1800000000), stored.CurrentPeriodEnd.Time().Unix())
+	assert.False(t, FeatureEnabled(ctx, member, features.DuplicateCheck))
+
+	// Metadata links a subscription without a checkout; other prices are not pro
+	other := primitive.NewObjectID()
+	plan, err = testStripe.HandleEvent(ctx, subscriptionEvent("created", 400, "active", "price_other", `"workspace_id":"`+other.Hex()+`"`))
+	require.NoError(t, err)
+	assert.Equal(t, models.PlanFree, plan)
+}
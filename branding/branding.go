@@ -0,0 +1,123 @@
+// branding.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package branding
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Default branding used when a user has no workspace or the workspace leaves a field empty
+const (
+	DefaultSenderName  = "Task Manager"
+	DefaultAccentColor = "#2563eb"
+)
+
+var accentColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Validate checks that a branding configuration is safe to render in emails and HTML pages.
+//
+// Parameters:
+// - b: The branding to validate.
+//
+// Returns:
+// - error: A descriptive error if any field is invalid.
+func Validate(b models.Branding) error {
+	if b.AccentColor != "" && !accentColorPattern.MatchString(b.AccentColor) {
+		return errors.New("accent_color must be a hex color such as #1a2b3c")
+	}
+	if b.LogoURL != "" {
+		u, err := url.Parse(b.LogoURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return errors.New("logo_url must be an absolute http(s) URL")
+		}
+	}
+	if len(b.SenderName) > 64 || strings.ContainsAny(b.SenderName, "\r\n<>\"") {
+		return errors.New("sender_name must be at most 64 characters without line breaks, quotes or angle brackets")
+	}
+	return nil
+}
+
+// WithDefaults fills the empty fields of b with the default branding.
+func WithDefaults(b models.Branding) models.Branding {
+	if b.SenderName == "" {
+		b.SenderName = DefaultSenderName
+	}
+	if b.AccentColor == "" {
+		b.AccentColor = DefaultAccentColor
+	}
+	return b
+}
+
+// ForUser returns the branding of the workspace the user belongs to, with defaults applied.
+// Lookup errors fall back to the default branding so that a notification is never blocked by branding.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - userId: The ID of the user.
+//
+// Returns:
+// - models.Branding: The effective branding.
+func ForUser(ctx context.Context, userId primitive.ObjectID) models.Branding {
+	user, err := repository.Users.FindByID(ctx, userId)
+	if err != nil || user.WorkspaceID.IsZero() {
+		return WithDefaults(models.Branding{})
+	}
+
+	workspace, err := repository.Workspaces.FindByID(ctx, user.WorkspaceID)
+	if err != nil {
+		return WithDefaults(models.Branding{})
+	}
+	return WithDefaults(workspace.Branding)
+}
+
+// page is the HTML layout shared by notification emails and public share pages.
+var page = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body style="margin:0;font-family:Arial,sans-serif;color:#1f2937">
+<div style="background:{{.Branding.AccentColor}};padding:16px">
+{{if .Branding.LogoURL}}<img src="{{.Branding.LogoURL}}" alt="{{.Branding.SenderName}}" style="max-height:40px">{{else}}<strong style="color:#ffffff">{{.Branding.SenderName}}</strong>{{end}}
+</div>
+<div style="padding:24px">
+<h2 style="color:{{.Branding.AccentColor}}">{{.Title}}</h2>
+{{.Body}}
+</div>
+<div style="padding:16px;font-size:12px;color:#6b7280">Sent by {{.Branding.SenderName}}</div>
+</body>
+</html>`))
+
+// Render produces a branded HTML document. The body is inserted as trusted HTML, so callers
+// must escape any user-provided content before passing it in.
+//
+// Parameters:
+// - b: The branding to apply.
+// - title: The page or email title.
+// - body: The HTML body content.
+//
+// Returns:
+// - string: The rendered HTML document.
+// - error: An error object if rendering fails.
+func Render(b models.Branding, title string, body template.HTML) (string, error) {
+	var buf bytes.Buffer
+	err := page.Execute(&buf, struct {
+		Branding models.Branding
+		Title    string
+		Body     template.HTML
+	}{WithDefaults(b), title, body})
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
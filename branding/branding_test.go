@@ -0,0 +1,35 @@
+// branding_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package branding
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bkojha74/task-management/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestValidate tests the accepted and rejected branding values
+func TestValidate(t *testing.T) {
+	// Empty and fully populated branding are valid
+	assert.NoError(t, Validate(models.Branding{}))
+	assert.NoError(t, Validate(models.Branding{LogoURL: "https://example.com/logo.png", AccentColor: "#ff8800", SenderName: "Acme"}))
+
+	// Named colors, non-http URLs and header injection are rejected
+	assert.Error(t, Validate(models.Branding{AccentColor: "red"}))
+	assert.Error(t, Validate(models.Branding{LogoURL: "javascript:alert(1)"}))
+	assert.Error(t, Validate(models.Branding{SenderName: "Acme\r\nBcc: x@example.com"}))
+}
+
+// TestRender tests that the branding is applied to the rendered page
+func TestRender(t *testing.T) {
+	html, err := Render(models.Branding{AccentColor: "#ff8800", SenderName: "Acme"}, "Task <shared>", "<p>body</p>")
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(html, "#ff8800"))             // Assert that the accent color is used
+	assert.True(t, strings.Contains(html, "Sent by Acme"))        // Assert that the sender name is used
+	assert.True(t, strings.Contains(html, "Task &lt;shared&gt;")) // Assert that the title is escaped
+	assert.True(t, strings.Contains(html, "<p>body</p>"))         // Assert that the body is kept as HTML
+}
@@ -0,0 +1,64 @@
+// buildinfo.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// Build metadata, set at link time, e.g. with
+// -ldflags "-X github.com/bkojha74/task-management/buildinfo.Version=v1.4.0
+// -X github.com/bkojha74/task-management/buildinfo.Commit=$(git rev-parse HEAD)
+// -X github.com/bkojha74/task-management/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)".
+// Commit and BuildTime fall back to the VCS information Go embeds when building from a git checkout.
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info describes the running binary.
+type Info struct {
+	Version   string `json:"version"`              // Semantic version of the release, "dev" for local builds
+	Commit    string `json:"commit,omitempty"`     // Git commit the binary was built from
+	BuildTime string `json:"build_time,omitempty"` // RFC 3339 time of the build, or of the commit if not set
+	Modified  bool   `json:"modified,omitempty"`   // The working tree had uncommitted changes
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build metadata of the running binary.
+//
+// Returns:
+// - Info: The version, commit, build time and Go version.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		fillFromSettings(&info, build.Settings)
+	}
+	return info
+}
+
+// fillFromSettings completes the commit and build time that were not set at link time from the VCS
+// build settings.
+func fillFromSettings(info *Info, settings []debug.BuildSetting) {
+	for _, setting := range settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.BuildTime == "" {
+				info.BuildTime = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, info.BuildTime); err == nil {
+		info.BuildTime = t.UTC().Format(time.RFC3339)
+	}
+}
@@ -0,0 +1,35 @@
+// buildinfo_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFillFromSettings tests that VCS settings only complete what was not set at link time
+func TestFillFromSettings(t *testing.T) {
+	settings := []debug.BuildSetting{
+		{Key: "vcs.revision", Value: "4f1c2ab"},
+		{Key: "vcs.time", Value: "2024-07-05T17:30:00+05:30"},
+		{Key: "vcs.modified", Value: "true"},
+	}
+
+	// Assert that a binary built without ldflags reports the commit and its time in UTC
+	info := Info{Version: "dev"}
+	fillFromSettings(&info, settings)
+	assert.Equal(t, Info{Version: "dev", Commit: "4f1c2ab", BuildTime: "2024-07-05T12:00:00Z", Modified: true}, info)
+
+	// Assert that values set at link time win
+	info = Info{Version: "v1.4.0", Commit: "9e8d7c6", BuildTime: "2024-07-06T08:00:00Z"}
+	fillFromSettings(&info, settings)
+	assert.Equal(t, "9e8d7c6", info.Commit)
+	assert.Equal(t, "2024-07-06T08:00:00Z", info.BuildTime)
+
+	// Assert that Get reports the running Go version
+	assert.Equal(t, runtime.Version(), Get().GoVersion)
+}
@@ -0,0 +1,227 @@
+// cache.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache is the interface implemented by cache backends used for hot reads.
+// The in-memory LRU below is the default; other backends (e.g. Redis) only need to satisfy it.
+type Cache interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{})
+	Delete(key string)
+	DeletePrefix(prefix string)
+	Stats() Stats
+}
+
+// Stats holds the hit/miss metrics of a cache.
+type Stats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Entries   int    `json:"entries"`
+	Capacity  int    `json:"capacity"`
+	TTL       string `json:"ttl"`
+}
+
+// entry is a single cached value together with its expiry time.
+type entry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+// LRU is a size-bounded, TTL-aware, least-recently-used in-memory cache.
+// It is safe for concurrent use.
+type LRU struct {
+	mu        sync.Mutex
+	capacity  int
+	ttl       time.Duration
+	items     map[string]*list.Element
+	order     *list.List
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// NewLRU creates an in-memory LRU cache holding at most capacity entries, each valid for ttl.
+func NewLRU(capacity int, ttl time.Duration) *LRU {
+	return &LRU{
+		capacity: capacity,
+		ttl:      ttl,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns the cached value for key, if present and not expired.
+func (l *LRU) Get(key string) (interface{}, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.items[key]
+	if !ok {
+		l.misses++
+		return nil, false
+	}
+
+	e := element.Value.(*entry)
+	if time.Now().After(e.expiresAt) {
+		l.removeElement(element)
+		l.misses++
+		return nil, false
+	}
+
+	l.order.MoveToFront(element)
+	l.hits++
+	return e.value, true
+}
+
+// Set stores value under key, evicting the least recently used entry when the cache is full.
+func (l *LRU) Set(key string, value interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.items[key]; ok {
+		e := element.Value.(*entry)
+		e.value = value
+		e.expiresAt = time.Now().Add(l.ttl)
+		l.order.MoveToFront(element)
+		return
+	}
+
+	element := l.order.PushFront(&entry{key: key, value: value, expiresAt: time.Now().Add(l.ttl)})
+	l.items[key] = element
+
+	if l.order.Len() > l.capacity {
+		l.removeElement(l.order.Back())
+		l.evictions++
+	}
+}
+
+// Delete removes key from the cache.
+func (l *LRU) Delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.items[key]; ok {
+		l.removeElement(element)
+	}
+}
+
+// DeletePrefix removes every key starting with prefix from the cache.
+func (l *LRU) DeletePrefix(prefix string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, element := range l.items {
+		if strings.HasPrefix(key, prefix) {
+			l.removeElement(element)
+		}
+	}
+}
+
+// Stats returns a snapshot of the cache metrics.
+func (l *LRU) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return Stats{
+		Hits:      l.hits,
+		Misses:    l.misses,
+		Evictions: l.evictions,
+		Entries:   l.order.Len(),
+		Capacity:  l.capacity,
+		TTL:       l.ttl.String(),
+	}
+}
+
+// removeElement unlinks element from both the map and the recency list.
+// The caller must hold the lock.
+func (l *LRU) removeElement(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.items, element.Value.(*entry).key)
+}
+
+// Store is the cache used by the handlers. It is nil when caching is disabled,
+// in which case the package-level helpers below are no-ops.
+var Store Cache
+
+// Init enables the in-memory LRU cache with the given capacity and TTL.
+// A non-positive capacity or TTL leaves caching disabled.
+func Init(capacity int, ttl time.Duration) {
+	if capacity <= 0 || ttl <= 0 {
+		Store = nil
+		return
+	}
+	Store = NewLRU(capacity, ttl)
+}
+
+// Get looks up key in the configured cache.
+func Get(key string) (interface{}, bool) {
+	if Store == nil {
+		return nil, false
+	}
+	return Store.Get(key)
+}
+
+// Set stores value under key in the configured cache.
+func Set(key string, value interface{}) {
+	if Store != nil {
+		Store.Set(key, value)
+	}
+}
+
+// Delete removes key from the configured cache.
+func Delete(key string) {
+	if Store != nil {
+		Store.Delete(key)
+	}
+}
+
+// DeletePrefix removes all keys starting with prefix from the configured cache.
+func DeletePrefix(prefix string) {
+	if Store != nil {
+		Store.DeletePrefix(prefix)
+	}
+}
+
+// TaskKey returns the cache key for a single task owned by userId.
+func TaskKey(userId, taskId string) string {
+	return "task:" + userId + ":" + taskId
+}
+
+// TaskListKey returns the cache key for a user's task list. The query string is part of the key
+// so that differently filtered or paginated lists are cached separately.
+func TaskListKey(userId, query string) string {
+	return TaskListPrefix(userId) + query
+}
+
+// TaskListPrefix returns the prefix shared by all cached task lists of userId.
+func TaskListPrefix(userId string) string {
+	return "tasks:" + userId + ":"
+}
+
+// CriticalPathKey returns the cache key for the critical path of a project owned by userId.
+func CriticalPathKey(userId, projectId string) string {
+	return CriticalPathPrefix(userId) + projectId
+}
+
+// CriticalPathPrefix returns the prefix shared by the cached critical paths of all projects of userId.
+func CriticalPathPrefix(userId string) string {
+	return "critical-path:" + userId + ":"
+}
+
+// InvalidateTask drops the cached copy of a task and all cached task lists and critical paths of its
+// owner. It must be called after every write to a task.
+func InvalidateTask(userId, taskId string) {
+	Delete(TaskKey(userId, taskId))
+	DeletePrefix(TaskListPrefix(userId))
+	DeletePrefix(CriticalPathPrefix(userId))
+}
@@ -0,0 +1,75 @@
+// cache_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLRUGetSet tests storing and retrieving values and the hit/miss counters
+func TestLRUGetSet(t *testing.T) {
+	lru := NewLRU(2, time.Minute)
+
+	_, ok := lru.Get("missing")
+	assert.False(t, ok) // Assert that an unknown key is a miss
+
+	lru.Set("a", 1)
+	value, ok := lru.Get("a")
+	assert.True(t, ok)        // Assert that the stored key is a hit
+	assert.Equal(t, 1, value) // Assert that the stored value is returned
+
+	stats := lru.Stats()
+	assert.Equal(t, uint64(1), stats.Hits)   // Assert that one hit was recorded
+	assert.Equal(t, uint64(1), stats.Misses) // Assert that one miss was recorded
+}
+
+// TestLRUEviction tests that the least recently used entry is evicted when the cache is full
+func TestLRUEviction(t *testing.T) {
+	lru := NewLRU(2, time.Minute)
+
+	lru.Set("a", 1)
+	lru.Set("b", 2)
+	lru.Get("a") // "b" becomes the least recently used entry
+	lru.Set("c", 3)
+
+	_, ok := lru.Get("b")
+	assert.False(t, ok) // Assert that "b" was evicted
+	_, ok = lru.Get("a")
+	assert.True(t, ok)                                // Assert that "a" is still cached
+	assert.Equal(t, uint64(1), lru.Stats().Evictions) // Assert that one eviction was recorded
+}
+
+// TestLRUExpiry tests that entries are not returned after their TTL
+func TestLRUExpiry(t *testing.T) {
+	lru := NewLRU(2, 10*time.Millisecond)
+
+	lru.Set("a", 1)
+	time.Sleep(20 * time.Millisecond)
+
+	_, ok := lru.Get("a")
+	assert.False(t, ok)                     // Assert that the expired entry is a miss
+	assert.Equal(t, 0, lru.Stats().Entries) // Assert that the expired entry was removed
+}
+
+// TestInvalidateTask tests that a task write drops the task and its owner's lists
+func TestInvalidateTask(t *testing.T) {
+	Init(10, time.Minute)
+	defer Init(0, 0)
+
+	Set(TaskKey("u1", "t1"), "task")
+	Set(TaskListKey("u1", "limit=10"), "list")
+	Set(TaskListKey("u2", ""), "other list")
+
+	InvalidateTask("u1", "t1")
+
+	_, ok := Get(TaskKey("u1", "t1"))
+	assert.False(t, ok) // Assert that the task was invalidated
+	_, ok = Get(TaskListKey("u1", "limit=10"))
+	assert.False(t, ok) // Assert that the owner's list was invalidated
+	_, ok = Get(TaskListKey("u2", ""))
+	assert.True(t, ok) // Assert that other users' lists are untouched
+}
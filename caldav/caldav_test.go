@@ -0,0 +1,101 @@
+// caldav_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package caldav
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestTaskRoundTrip tests that a task survives encoding as a VTODO and parsing it back
+func TestTaskRoundTrip(t *testing.T) {
+	due := time.Date(2024, 7, 5, 17, 0, 0, 0, time.UTC)
+	task := models.Task{
+		ID:          primitive.NewObjectID(),
+		Title:       "Pay rent; call landlord, then relax",
+		Description: strings.Repeat("Long description with ünïcödé text. ", 5) + "\nSecond line",
+		Status:      models.TaskStatusInProgress,
+		Priority:    models.TaskPriorityHigh,
+		EndDate:     models.NewTimestamp(due),
+	}
+	data := Encode(FromTask(task))
+
+	// Assert that long lines are folded within 75 octets and text is escaped
+	for _, line := range strings.Split(strings.TrimSuffix(data, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	assert.Contains(t, data, `SUMMARY:Pay rent\; call landlord\, then relax`)
+
+	todo, err := Parse([]byte(data), time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, task.ID.Hex(), todo.UID)
+	var parsed models.Task
+	Apply(todo, &parsed)
+	assert.Equal(t, task.Title, parsed.Title)
+	assert.Equal(t, task.Description, parsed.Description)
+	assert.Equal(t, models.TaskStatusInProgress, parsed.Status)
+	assert.Equal(t, models.TaskPriorityHigh, parsed.Priority)
+	assert.Equal(t, task.EndDate, parsed.EndDate)
+}
+
+// TestParse tests reading the VTODOs native task apps send
+func TestParse(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	data := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:ABC-123\r\nSUMMARY:Buy mi\r\n lk\r\n" +
+		"DUE;TZID=Europe/Berlin:20240705T090000\r\nSTATUS:CANCELLED\r\nPRIORITY:9\r\n" +
+		"BEGIN:VALARM\r\nDESCRIPTION:Reminder\r\nEND:VALARM\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+
+	// Assert that lines are unfolded, alarms are ignored and local times use their TZID
+	todo, err := Parse([]byte(data), time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, "ABC-123", todo.UID)
+	assert.Equal(t, "Buy milk", todo.Summary)
+	assert.Empty(t, todo.Description)
+	assert.True(t, todo.Due.Equal(time.Date(2024, 7, 5, 9, 0, 0, 0, berlin)))
+	var task models.Task
+	Apply(todo, &task)
+	assert.Equal(t, models.TaskStatusDone, task.Status)
+	assert.Equal(t, models.TaskPriorityLow, task.Priority)
+
+	// Assert that all-day dates are midnight in the given zone, and events are rejected
+	todo, err = Parse([]byte("BEGIN:VCALENDAR\nBEGIN:VTODO\nDUE;VALUE=DATE:20240705\nEND:VTODO\nEND:VCALENDAR\n"), berlin)
+	require.NoError(t, err)
+	assert.True(t, todo.Due.Equal(time.Date(2024, 7, 5, 0, 0, 0, 0, berlin)))
+	_, err = Parse([]byte("BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:Party\nEND:VEVENT\nEND:VCALENDAR\n"), time.UTC)
+	assert.ErrorIs(t, err, ErrNoTodo)
+}
+
+// TestMultistatus tests that properties are reported with 200 or 404 as the resource has them
+func TestMultistatus(t *testing.T) {
+	request, err := ParseRequest([]byte(`<?xml version="1.0"?><d:propfind xmlns:d="DAV:" xmlns:cs="http://calendarserver.org/ns/">
+		<d:prop><d:getetag/><cs:getctag/><x:color xmlns:x="http://apple.com/ns/ical/"/></d:prop></d:propfind>`))
+	require.NoError(t, err)
+	assert.Equal(t, KindPropfind, request.Kind)
+	assert.False(t, request.AllProps)
+	require.Len(t, request.Props, 3)
+
+	body := string(Multistatus([]Resource{
+		{Href: "/caldav/calendars/alice/tasks/", Props: map[xml.Name]string{PropCTag: "abc"}},
+		{Href: "/caldav/calendars/alice/tasks/gone.ics", Missing: true},
+	}, request))
+	assert.Contains(t, body, `<d:propstat><d:prop><cs:getctag>abc</cs:getctag></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>`)
+	assert.Contains(t, body, `<d:getetag/><x:color xmlns:x="http://apple.com/ns/ical/"/></d:prop><d:status>HTTP/1.1 404 Not Found</d:status>`)
+	assert.Contains(t, body, `<d:href>/caldav/calendars/alice/tasks/gone.ics</d:href><d:status>HTTP/1.1 404 Not Found</d:status>`)
+
+	// Assert that the component of a calendar-query filter is read
+	request, err = ParseRequest([]byte(`<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+		<d:prop><d:getetag/></d:prop><c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VEVENT"/></c:comp-filter></c:filter></c:calendar-query>`))
+	require.NoError(t, err)
+	assert.Equal(t, KindCalendarQuery, request.Kind)
+	assert.Equal(t, "VEVENT", request.Component)
+}
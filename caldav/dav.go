@@ -0,0 +1,190 @@
+// dav.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package caldav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// XML namespaces of the WebDAV, CalDAV and Apple calendar server properties
+const (
+	NamespaceDAV            = "DAV:"
+	NamespaceCalDAV         = "urn:ietf:params:xml:ns:caldav"
+	NamespaceCalendarServer = "http://calendarserver.org/ns/"
+)
+
+// prefixes are the namespace prefixes declared on multistatus documents.
+var prefixes = map[string]string{NamespaceDAV: "d", NamespaceCalDAV: "c", NamespaceCalendarServer: "cs"}
+
+// Properties served by the endpoints
+var (
+	PropResourceType          = xml.Name{Space: NamespaceDAV, Local: "resourcetype"}
+	PropDisplayName           = xml.Name{Space: NamespaceDAV, Local: "displayname"}
+	PropCurrentUserPrincipal  = xml.Name{Space: NamespaceDAV, Local: "current-user-principal"}
+	PropPrincipalURL          = xml.Name{Space: NamespaceDAV, Local: "principal-URL"}
+	PropOwner                 = xml.Name{Space: NamespaceDAV, Local: "owner"}
+	PropCurrentUserPrivileges = xml.Name{Space: NamespaceDAV, Local: "current-user-privilege-set"}
+	PropSupportedReports      = xml.Name{Space: NamespaceDAV, Local: "supported-report-set"}
+	PropETag                  = xml.Name{Space: NamespaceDAV, Local: "getetag"}
+	PropContentType           = xml.Name{Space: NamespaceDAV, Local: "getcontenttype"}
+	PropCalendarHomeSet       = xml.Name{Space: NamespaceCalDAV, Local: "calendar-home-set"}
+	PropSupportedComponents   = xml.Name{Space: NamespaceCalDAV, Local: "supported-calendar-component-set"}
+	PropCalendarData          = xml.Name{Space: NamespaceCalDAV, Local: "calendar-data"}
+	PropCTag                  = xml.Name{Space: NamespaceCalendarServer, Local: "getctag"}
+)
+
+// Kinds of requests
+var (
+	KindPropfind         = xml.Name{Space: NamespaceDAV, Local: "propfind"}
+	KindCalendarQuery    = xml.Name{Space: NamespaceCalDAV, Local: "calendar-query"}
+	KindCalendarMultiget = xml.Name{Space: NamespaceCalDAV, Local: "calendar-multiget"}
+)
+
+// Request is a PROPFIND or REPORT request body.
+type Request struct {
+	Kind      xml.Name   // KindPropfind, KindCalendarQuery, KindCalendarMultiget or an unsupported report
+	AllProps  bool       // allprop, or an empty PROPFIND body
+	Props     []xml.Name // The properties asked for
+	Hrefs     []string   // The resources of a calendar-multiget
+	Component string     // The component a calendar-query filters on, e.g. VTODO; empty without a filter
+}
+
+// compFilter is a comp-filter element of a calendar-query.
+type compFilter struct {
+	Name  string       `xml:"name,attr"`
+	Comps []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+// Resource is a response of a multistatus document.
+type Resource struct {
+	Href    string
+	Props   map[xml.Name]string // Inner XML of the properties the resource has
+	Missing bool                // The resource does not exist, e.g. an href of a multiget
+}
+
+// ParseRequest reads the body of a PROPFIND or REPORT request.
+//
+// Parameters:
+// - body: The XML body; an empty body asks for all properties.
+//
+// Returns:
+// - Request: The request.
+// - error: An error if the body is not XML.
+func ParseRequest(body []byte) (Request, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return Request{Kind: KindPropfind, AllProps: true}, nil
+	}
+	var parsed struct {
+		XMLName xml.Name
+		AllProp *struct{} `xml:"DAV: allprop"`
+		Prop    *struct {
+			Names []struct {
+				XMLName xml.Name
+			} `xml:",any"`
+		} `xml:"DAV: prop"`
+		Hrefs  []string `xml:"DAV: href"`
+		Filter *struct {
+			Calendar compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+		} `xml:"urn:ietf:params:xml:ns:caldav filter"`
+	}
+	if err := xml.Unmarshal(body, &parsed); err != nil {
+		return Request{}, err
+	}
+
+	request := Request{Kind: parsed.XMLName, AllProps: parsed.AllProp != nil || parsed.Prop == nil, Hrefs: parsed.Hrefs}
+	if parsed.Filter != nil && len(parsed.Filter.Calendar.Comps) > 0 {
+		request.Component = strings.ToUpper(parsed.Filter.Calendar.Comps[0].Name)
+	}
+	if parsed.Prop != nil {
+		for _, name := range parsed.Prop.Names {
+			request.Props = append(request.Props, name.XMLName)
+		}
+	}
+	return request, nil
+}
+
+// Multistatus renders the 207 Multi-Status body answering a request for resources. The properties asked
+// for that a resource has are reported with 200 OK, the others with 404 Not Found.
+//
+// Parameters:
+// - resources: The resources.
+// - request: The request.
+//
+// Returns:
+// - []byte: The XML document.
+func Multistatus(resources []Resource, request Request) []byte {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:c="` + NamespaceCalDAV + `" xmlns:cs="` + NamespaceCalendarServer + `">`)
+	for _, resource := range resources {
+		b.WriteString("<d:response>" + Href(resource.Href))
+		if resource.Missing {
+			b.WriteString("<d:status>HTTP/1.1 404 Not Found</d:status></d:response>")
+			continue
+		}
+
+		names := request.Props
+		if request.AllProps {
+			names = names[:0:0]
+			for name := range resource.Props {
+				if name != PropCalendarData { // Not part of allprop (RFC 4791, section 9.6)
+					names = append(names, name)
+				}
+			}
+			sort.Slice(names, func(i, j int) bool { return names[i].Space+names[i].Local < names[j].Space+names[j].Local })
+		}
+		var found, missing strings.Builder
+		for _, name := range names {
+			if value, ok := resource.Props[name]; ok {
+				found.WriteString(element(name, value))
+			} else {
+				missing.WriteString(element(name, ""))
+			}
+		}
+		if found.Len() > 0 {
+			b.WriteString("<d:propstat><d:prop>" + found.String() + "</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>")
+		}
+		if missing.Len() > 0 {
+			b.WriteString("<d:propstat><d:prop>" + missing.String() + "</d:prop><d:status>HTTP/1.1 404 Not Found</d:status></d:propstat>")
+		}
+		b.WriteString("</d:response>")
+	}
+	b.WriteString("</d:multistatus>")
+	return []byte(b.String())
+}
+
+// Href renders a d:href element.
+func Href(href string) string {
+	return "<d:href>" + Text(href) + "</d:href>"
+}
+
+// Text escapes character data.
+func Text(text string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+// Empty renders an empty element, e.g. Empty(NamespaceDAV, "collection") for <d:collection/>.
+func Empty(space, local string) string {
+	return element(xml.Name{Space: space, Local: local}, "")
+}
+
+// element renders an element with inner XML, declaring its namespace if it has no prefix.
+func element(name xml.Name, inner string) string {
+	tag, declaration := name.Local, ""
+	if prefix, ok := prefixes[name.Space]; ok {
+		tag = prefix + ":" + name.Local
+	} else if name.Space != "" {
+		tag, declaration = "x:"+name.Local, fmt.Sprintf(` xmlns:x="%s"`, Text(name.Space))
+	}
+	if inner == "" {
+		return "<" + tag + declaration + "/>"
+	}
+	return "<" + tag + declaration + ">" + inner + "</" + tag + ">"
+}
@@ -0,0 +1,316 @@
+// ical.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package caldav serves tasks as the VTODOs of a CalDAV calendar collection, so that native task apps such
+// as Apple Reminders and Thunderbird sync with them directly. It encodes and parses the iCalendar objects
+// (RFC 5545) and the WebDAV multistatus documents (RFC 4918, RFC 4791) the endpoints exchange.
+package caldav
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bkojha74/task-management/models"
+)
+
+// ProductID identifies the server in the iCalendar objects it produces.
+const ProductID = "-//task-management//CalDAV//EN"
+
+// ContentType is the media type of the task resources.
+const ContentType = "text/calendar; charset=utf-8; component=vtodo"
+
+// ErrNoTodo is returned by Parse for data that is not an iCalendar object with a VTODO.
+var ErrNoTodo = errors.New("not an iCalendar object with a VTODO")
+
+// iCalendar date-time formats
+const (
+	utcFormat   = "20060102T150405Z"
+	localFormat = "20060102T150405"
+	dateFormat  = "20060102"
+)
+
+// Todo holds the VTODO properties mapped to task fields.
+type Todo struct {
+	UID          string
+	Summary      string
+	Description  string
+	Status       string // NEEDS-ACTION, IN-PROCESS, COMPLETED or CANCELLED
+	Priority     int    // 1 (highest) to 9 (lowest), 0 when undefined
+	Due          time.Time
+	Completed    time.Time
+	Created      time.Time
+	LastModified time.Time
+}
+
+// Encode renders a todo as an iCalendar object with a single VTODO.
+//
+// Parameters:
+// - todo: The todo.
+//
+// Returns:
+// - string: The iCalendar object, with CRLF line endings and long lines folded.
+func Encode(todo Todo) string {
+	var b strings.Builder
+	line := func(name, value string) {
+		fold(&b, name+":"+value)
+	}
+	stamp := todo.LastModified
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", ProductID)
+	line("BEGIN", "VTODO")
+	line("UID", escape(todo.UID))
+	line("DTSTAMP", stamp.UTC().Format(utcFormat))
+	if !todo.Created.IsZero() {
+		line("CREATED", todo.Created.UTC().Format(utcFormat))
+	}
+	if !todo.LastModified.IsZero() {
+		line("LAST-MODIFIED", todo.LastModified.UTC().Format(utcFormat))
+	}
+	line("SUMMARY", escape(todo.Summary))
+	if todo.Description != "" {
+		line("DESCRIPTION", escape(todo.Description))
+	}
+	line("STATUS", todo.Status)
+	if todo.Priority != 0 {
+		line("PRIORITY", strconv.Itoa(todo.Priority))
+	}
+	if !todo.Due.IsZero() {
+		line("DUE", todo.Due.UTC().Format(utcFormat))
+	}
+	if !todo.Completed.IsZero() {
+		line("COMPLETED", todo.Completed.UTC().Format(utcFormat))
+		line("PERCENT-COMPLETE", "100")
+	}
+	line("END", "VTODO")
+	line("END", "VCALENDAR")
+	return b.String()
+}
+
+// Parse reads the first VTODO of an iCalendar object. Properties of nested components such as VALARM are
+// ignored.
+//
+// Parameters:
+// - data: The iCalendar object.
+// - location: The time zone of floating and all-day times, and of TZIDs that are not IANA names.
+//
+// Returns:
+// - Todo: The todo.
+// - error: ErrNoTodo, or an error describing an invalid date.
+func Parse(data []byte, location *time.Location) (Todo, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	text = strings.NewReplacer("\n ", "", "\n\t", "").Replace(text) // Unfold
+
+	var todo Todo
+	var components []string
+	found := false
+	for _, raw := range strings.Split(text, "\n") {
+		name, params, value, ok := splitProperty(raw)
+		if !ok {
+			continue
+		}
+		switch name {
+		case "BEGIN":
+			components = append(components, strings.ToUpper(value))
+			continue
+		case "END":
+			if len(components) == 2 && components[1] == "VTODO" {
+				found = true
+			}
+			if len(components) > 0 {
+				components = components[:len(components)-1]
+			}
+			continue
+		}
+		if found || len(components) != 2 || components[0] != "VCALENDAR" || components[1] != "VTODO" {
+			continue
+		}
+
+		var err error
+		switch name {
+		case "UID":
+			todo.UID = unescape(value)
+		case "SUMMARY":
+			todo.Summary = unescape(value)
+		case "DESCRIPTION":
+			todo.Description = unescape(value)
+		case "STATUS":
+			todo.Status = strings.ToUpper(value)
+		case "PRIORITY":
+			todo.Priority, _ = strconv.Atoi(value)
+		case "DUE":
+			todo.Due, err = parseTime(value, params, location)
+		case "COMPLETED":
+			todo.Completed, err = parseTime(value, params, location)
+		}
+		if err != nil {
+			return Todo{}, err
+		}
+	}
+	if !found {
+		return Todo{}, ErrNoTodo
+	}
+	return todo, nil
+}
+
+// FromTask maps a task to its VTODO.
+//
+// Parameters:
+// - task: The task.
+//
+// Returns:
+// - Todo: The todo.
+func FromTask(task models.Task) Todo {
+	todo := Todo{UID: UID(task), Summary: task.Title, Description: task.Description, Status: "NEEDS-ACTION"}
+	switch task.Status {
+	case models.TaskStatusInProgress:
+		todo.Status = "IN-PROCESS"
+	case models.TaskStatusDone:
+		todo.Status = "COMPLETED"
+		if task.CompletedAt != 0 {
+			todo.Completed = task.CompletedAt.Time()
+		}
+	}
+	switch task.Priority {
+	case models.TaskPriorityHigh:
+		todo.Priority = 1
+	case models.TaskPriorityMedium:
+		todo.Priority = 5
+	case models.TaskPriorityLow:
+		todo.Priority = 9
+	}
+	if !task.EndDate.IsZero() {
+		todo.Due = task.EndDate.Time()
+	}
+	if !task.CreatedAt.IsZero() {
+		todo.Created = task.CreatedAt.Time()
+	}
+	if !task.UpdatedAt.IsZero() {
+		todo.LastModified = task.UpdatedAt.Time()
+	}
+	return todo
+}
+
+// Apply copies the fields of a VTODO to a task: title, description, status, priority and due date. A
+// cancelled VTODO completes the task, and a VTODO without a DUE clears the due date.
+//
+// Parameters:
+// - todo: The todo a client sent.
+// - task: The task to update.
+func Apply(todo Todo, task *models.Task) {
+	task.Title = todo.Summary
+	task.Description = todo.Description
+	switch {
+	case todo.Status == "COMPLETED" || todo.Status == "CANCELLED" || (todo.Status == "" && !todo.Completed.IsZero()):
+		task.Status = models.TaskStatusDone
+	case todo.Status == "IN-PROCESS":
+		task.Status = models.TaskStatusInProgress
+	default:
+		task.Status = models.TaskStatusPending
+	}
+	switch {
+	case todo.Priority >= 1 && todo.Priority <= 4:
+		task.Priority = models.TaskPriorityHigh
+	case todo.Priority >= 6 && todo.Priority <= 9:
+		task.Priority = models.TaskPriorityLow
+	default:
+		task.Priority = models.TaskPriorityMedium
+	}
+	task.EndDate = 0
+	if !todo.Due.IsZero() {
+		task.EndDate = models.NewTimestamp(todo.Due)
+	}
+}
+
+// UID returns the UID of a task's VTODO: the one its CalDAV client chose, or the task ID.
+func UID(task models.Task) string {
+	if task.CalDAV != nil {
+		return task.CalDAV.UID
+	}
+	return task.ID.Hex()
+}
+
+// Name returns the resource name of a task in the tasks collection, without .ics.
+func Name(task models.Task) string {
+	if task.CalDAV != nil {
+		return task.CalDAV.Name
+	}
+	return task.ID.Hex()
+}
+
+// splitProperty splits a content line into its upper-case name, its parameters and its value.
+func splitProperty(line string) (string, map[string]string, string, bool) {
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := map[string]string{}
+	for _, param := range parts[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, strings.TrimSpace(line[colon+1:]), true
+}
+
+// parseTime parses a DATE or DATE-TIME value. All-day dates are midnight in location, and local times are
+// read in their TZID if it is an IANA name, otherwise in location.
+func parseTime(value string, params map[string]string, location *time.Location) (time.Time, error) {
+	if params["VALUE"] == "DATE" || len(value) == len(dateFormat) {
+		return time.ParseInLocation(dateFormat, value, location)
+	}
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse(utcFormat, value)
+	}
+	if tzid := params["TZID"]; tzid != "" {
+		if zone, err := time.LoadLocation(tzid); err == nil {
+			location = zone
+		}
+	}
+	return time.ParseInLocation(localFormat, value, location)
+}
+
+// escape escapes a TEXT value.
+func escape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", "").Replace(text)
+}
+
+// unescape reverses escape.
+func unescape(text string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(text)
+}
+
+// fold writes a content line, split into lines of at most 75 octets without breaking UTF-8 sequences.
+func fold(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // The leading space counts
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,191 @@
+// changestream.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package changestream
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/bkojha74/task-management/database"
+	"github.com/bkojha74/task-management/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Operations reported by the change stream
+const (
+	OperationInsert  = "insert"
+	OperationUpdate  = "update"
+	OperationReplace = "replace"
+	OperationDelete  = "delete"
+)
+
+// Change is a write to a task, as seen on the change stream.
+type Change struct {
+	Operation string             // insert, update, replace or delete
+	TaskID    primitive.ObjectID // ID of the written task
+	UserID    primitive.ObjectID // Owner of the task; zero when it could not be determined
+	Task      *models.Task       // Current task; nil when it was deleted
+}
+
+// Consumer keeps one kind of derived data up to date. Handle must be idempotent: after a restart
+// changes since the last saved resume token are delivered again, and every instance runs its own listener.
+type Consumer interface {
+	Name() string
+	Handle(ctx context.Context, change Change) error
+}
+
+// Listener watches the tasks collection and passes every change to its consumers, outside the request path.
+type Listener struct {
+	Name       string            // Identifies the listener's resume token
+	Collection *mongo.Collection // Watched collection
+	Tokens     *mongo.Collection // Where resume tokens are stored; nil disables resuming
+	Consumers  []Consumer        // Consumers in the order they are called
+
+	// Owner resolves the owner of a deleted task, whose document is gone by the time it is seen.
+	Owner func(ctx context.Context, taskID primitive.ObjectID) (primitive.ObjectID, error)
+}
+
+// NewTaskListener creates the listener of the tasks collection with the built-in consumers:
+// search index, per-user counters and cache invalidation.
+//
+// Returns:
+// - *Listener: The listener; start it with Run.
+func NewTaskListener() *Listener {
+	search := &SearchIndex{Collection: database.TaskSearchCollection}
+	return &Listener{
+		Name:       "tasks",
+		Collection: database.TasksCollection,
+		Tokens:     database.ChangeStreamTokensCollection,
+		Consumers:  []Consumer{search, &UserCounters{Collection: database.UserTaskStatsCollection}, CacheInvalidation{}},
+		Owner:      search.Owner,
+	}
+}
+
+// Run processes changes until ctx is cancelled, reopening the stream with backoff when it fails.
+// Change streams need MongoDB to run as a replica set.
+//
+// Parameters:
+// - ctx: Cancel it to stop the listener.
+func (l *Listener) Run(ctx context.Context) {
+	backoff := time.Second
+	for {
+		err := l.watch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Change stream %s stopped: %v, reopening in %s", l.Name, err, backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		if backoff *= 2; backoff > time.Minute {
+			backoff = time.Minute
+		}
+	}
+}
+
+// watch opens the stream after the saved resume token and processes changes until it fails.
+func (l *Listener) watch(ctx context.Context) error {
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if token := l.loadToken(ctx); token != nil {
+		opts.SetResumeAfter(token)
+	}
+
+	stream, err := l.Collection.Watch(ctx, mongo.Pipeline{}, opts)
+	if err != nil {
+		return err
+	}
+	defer stream.Close(context.Background())
+	log.Printf("Change stream %s started", l.Name)
+
+	for stream.Next(ctx) {
+		change, err := decodeEvent(stream.Current)
+		if err != nil {
+			log.Printf("Change stream %s: skipping undecodable event: %v", l.Name, err)
+		} else if change.Operation != "" {
+			l.dispatch(ctx, change)
+		}
+		l.saveToken(ctx, stream.ResumeToken())
+	}
+	return stream.Err()
+}
+
+// dispatch resolves the owner of deleted tasks and passes the change to every consumer.
+// Consumer errors are logged; the data they derive is rebuilt on the next change to the same task.
+func (l *Listener) dispatch(ctx context.Context, change Change) {
+	if change.UserID.IsZero() && l.Owner != nil {
+		if owner, err := l.Owner(ctx, change.TaskID); err == nil {
+			change.UserID = owner
+		}
+	}
+
+	for _, consumer := range l.Consumers {
+		if err := consumer.Handle(ctx, change); err != nil {
+			log.Printf("Change stream %s: %s failed for task %s: %v", l.Name, consumer.Name(), change.TaskID.Hex(), err)
+		}
+	}
+}
+
+// loadToken returns the saved resume token, or nil to start with the current changes.
+func (l *Listener) loadToken(ctx context.Context) bson.Raw {
+	if l.Tokens == nil {
+		return nil
+	}
+	var saved struct {
+		Token bson.Raw `bson:"token"`
+	}
+	if err := l.Tokens.FindOne(ctx, bson.M{"_id": l.Name}).Decode(&saved); err != nil {
+		return nil
+	}
+	return saved.Token
+}
+
+// saveToken stores the resume token of the last processed change.
+func (l *Listener) saveToken(ctx context.Context, token bson.Raw) {
+	if l.Tokens == nil || token == nil {
+		return
+	}
+	_, err := l.Tokens.UpdateOne(ctx, bson.M{"_id": l.Name},
+		bson.M{"$set": bson.M{"token": token, "updated_at": primitive.NewDateTimeFromTime(time.Now())}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		log.Printf("Change stream %s: could not save resume token: %v", l.Name, err)
+	}
+}
+
+// decodeEvent converts a change stream event to a Change. Events other than document writes,
+// e.g. drop or invalidate, yield a Change without Operation.
+func decodeEvent(raw bson.Raw) (Change, error) {
+	var event struct {
+		OperationType string `bson:"operationType"`
+		DocumentKey   struct {
+			ID primitive.ObjectID `bson:"_id"`
+		} `bson:"documentKey"`
+		FullDocument *models.Task `bson:"fullDocument"`
+	}
+	if err := bson.Unmarshal(raw, &event); err != nil {
+		return Change{}, err
+	}
+
+	switch event.OperationType {
+	case OperationInsert, OperationUpdate, OperationReplace, OperationDelete:
+	default:
+		return Change{}, nil
+	}
+
+	change := Change{Operation: event.OperationType, TaskID: event.DocumentKey.ID}
+	// An update whose task was deleted before the lookup has no full document and is handled like a delete
+	if event.OperationType != OperationDelete && event.FullDocument != nil {
+		change.Task = event.FullDocument
+		change.UserID = event.FullDocument.UserID
+	}
+	return change, nil
+}
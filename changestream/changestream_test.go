@@ -0,0 +1,86 @@
+// changestream_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package changestream
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestDecodeEvent tests that change events are converted to changes with their task and owner
+func TestDecodeEvent(t *testing.T) {
+	taskID, userID := primitive.NewObjectID(), primitive.NewObjectID()
+	encode := func(event bson.M) bson.Raw {
+		raw, err := bson.Marshal(event)
+		require.NoError(t, err)
+		return raw
+	}
+
+	change, err := decodeEvent(encode(bson.M{
+		"operationType": "update",
+		"documentKey":   bson.M{"_id": taskID},
+		"fullDocument":  bson.M{"_id": taskID, "userId": userID, "title": "Ship"},
+	}))
+	require.NoError(t, err)
+
+	// Assert that updates carry the current task and its owner
+	assert.Equal(t, OperationUpdate, change.Operation)
+	assert.Equal(t, taskID, change.TaskID)
+	assert.Equal(t, userID, change.UserID)
+	require.NotNil(t, change.Task)
+	assert.Equal(t, "Ship", change.Task.Title)
+
+	change, err = decodeEvent(encode(bson.M{"operationType": "delete", "documentKey": bson.M{"_id": taskID}}))
+	require.NoError(t, err)
+
+	// Assert that deletes have no task and leave the owner to be resolved
+	assert.Equal(t, OperationDelete, change.Operation)
+	assert.Nil(t, change.Task)
+	assert.True(t, change.UserID.IsZero())
+
+	change, err = decodeEvent(encode(bson.M{"operationType": "invalidate"}))
+	require.NoError(t, err)
+
+	// Assert that other events are skipped
+	assert.Empty(t, change.Operation)
+}
+
+// TestTerms tests that search terms are distinct, lower-case and sorted words
+func TestTerms(t *testing.T) {
+	terms := Terms(models.Task{Title: "Fix login bug", Description: "Login fails on Safari, a bug since v2", AllottedTo: "alice"})
+
+	// Assert that words are normalized and single characters dropped
+	assert.Equal(t, []string{"alice", "bug", "fails", "fix", "login", "on", "safari", "since", "v2"}, terms)
+}
+
+// TestCountTasks tests that a user's tasks are counted in total and per status
+func TestCountTasks(t *testing.T) {
+	repository.UseMemory()
+	ctx := context.Background()
+	userID := primitive.NewObjectID()
+	for _, status := range []string{models.TaskStatusPending, models.TaskStatusPending, models.TaskStatusDone} {
+		require.NoError(t, repository.Tasks.Create(ctx, &models.Task{UserID: userID, Title: "Task", Status: status}))
+	}
+	require.NoError(t, repository.Tasks.Create(ctx, &models.Task{UserID: primitive.NewObjectID(), Title: "Other", Status: models.TaskStatusDone}))
+
+	stats, err := CountTasks(ctx, userID)
+	require.NoError(t, err)
+
+	// Assert that only the user's tasks are counted
+	assert.Equal(t, userID, stats.UserID)
+	assert.Equal(t, int64(3), stats.Total)
+	assert.Equal(t, map[string]int64{
+		models.TaskStatusPending:    2,
+		models.TaskStatusInProgress: 0,
+		models.TaskStatusDone:       1,
+	}, stats.ByStatus)
+}
@@ -0,0 +1,182 @@
+// consumers.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package changestream
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/fieldcrypt"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CacheInvalidation drops the cached copies of changed tasks, including writes made by other
+// instances or directly in the database.
+type CacheInvalidation struct{}
+
+// Name returns the consumer name used in logs.
+func (CacheInvalidation) Name() string {
+	return "cache invalidation"
+}
+
+// Handle invalidates the task and its owner's cached lists.
+func (CacheInvalidation) Handle(ctx context.Context, change Change) error {
+	if !change.UserID.IsZero() {
+		cache.InvalidateTask(change.UserID.Hex(), change.TaskID.Hex())
+	}
+	return nil
+}
+
+// UserCounters maintains the total and per-status task counts of every user.
+type UserCounters struct {
+	Collection *mongo.Collection // Where the counters are stored
+}
+
+// Name returns the consumer name used in logs.
+func (u *UserCounters) Name() string {
+	return "user counters"
+}
+
+// Handle recounts the tasks of the changed task's owner. Recounting instead of incrementing keeps the
+// counters correct when a change is delivered twice.
+func (u *UserCounters) Handle(ctx context.Context, change Change) error {
+	if change.UserID.IsZero() {
+		return nil
+	}
+	stats, err := CountTasks(ctx, change.UserID)
+	if err != nil {
+		return err
+	}
+	_, err = u.Collection.ReplaceOne(ctx, bson.M{"_id": stats.UserID}, stats, options.Replace().SetUpsert(true))
+	return err
+}
+
+// CountTasks counts the tasks of a user, in total and per status.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - userID: The owner of the tasks.
+//
+// Returns:
+// - models.UserTaskStats: The counters.
+// - error: An error if counting failed.
+func CountTasks(ctx context.Context, userID primitive.ObjectID) (models.UserTaskStats, error) {
+	stats := models.UserTaskStats{
+		UserID:    userID,
+		ByStatus:  map[string]int64{},
+		UpdatedAt: primitive.NewDateTimeFromTime(time.Now()),
+	}
+
+	total, err := repository.Tasks.Count(ctx, repository.TaskQuery{UserID: userID})
+	if err != nil {
+		return stats, err
+	}
+	stats.Total = total
+
+	for _, status := range models.TaskStatuses {
+		count, err := repository.Tasks.Count(ctx, repository.TaskQuery{UserID: userID, Statuses: []string{status}})
+		if err != nil {
+			return stats, err
+		}
+		stats.ByStatus[status] = count
+	}
+	return stats, nil
+}
+
+// SearchIndex maintains the search terms of every task, for prefix and term searches without a
+// full collection scan.
+type SearchIndex struct {
+	Collection *mongo.Collection // Where the search entries are stored
+}
+
+// Name returns the consumer name used in logs.
+func (s *SearchIndex) Name() string {
+	return "search index"
+}
+
+// Handle stores the terms of the changed task, or removes its entry when it was deleted.
+func (s *SearchIndex) Handle(ctx context.Context, change Change) error {
+	if change.Task == nil {
+		_, err := s.Collection.DeleteOne(ctx, bson.M{"_id": change.TaskID})
+		return err
+	}
+
+	entry := models.TaskSearchEntry{ID: change.TaskID, UserID: change.Task.UserID, Terms: Terms(*change.Task)}
+	_, err := s.Collection.ReplaceOne(ctx, bson.M{"_id": entry.ID}, entry, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Owner returns the owner recorded in a task's search entry, which outlives the task until the delete
+// is processed.
+func (s *SearchIndex) Owner(ctx context.Context, taskID primitive.ObjectID) (primitive.ObjectID, error) {
+	var entry models.TaskSearchEntry
+	if err := s.Collection.FindOne(ctx, bson.M{"_id": taskID}).Decode(&entry); err != nil {
+		return primitive.NilObjectID, err
+	}
+	return entry.UserID, nil
+}
+
+// TaskIndexer mirrors tasks into an external search engine, such as search.Elastic.
+type TaskIndexer interface {
+	IndexTask(ctx context.Context, task models.Task) error
+	DeleteTask(ctx context.Context, taskID primitive.ObjectID) error
+}
+
+// SearchEngineIndex keeps the tasks in an external search engine up to date.
+type SearchEngineIndex struct {
+	Indexer TaskIndexer
+}
+
+// Name returns the consumer name used in logs.
+func (s SearchEngineIndex) Name() string {
+	return "search engine index"
+}
+
+// Handle indexes the changed task, or removes it from the index when it was deleted.
+func (s SearchEngineIndex) Handle(ctx context.Context, change Change) error {
+	if change.Task == nil {
+		return s.Indexer.DeleteTask(ctx, change.TaskID)
+	}
+	return s.Indexer.IndexTask(ctx, *change.Task)
+}
+
+// Terms returns the distinct lower-case words of a task's title, description and assignee, sorted.
+// Single characters are left out, and so are encrypted descriptions, which must not leak into the index.
+//
+// Parameters:
+// - task: The task to index.
+//
+// Returns:
+// - []string: The search terms.
+func Terms(task models.Task) []string {
+	description := task.Description
+	if fieldcrypt.IsEncrypted(description) {
+		description = ""
+	}
+	text := strings.ToLower(task.Title + " " + description + " " + task.AllottedTo)
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	seen := map[string]bool{}
+	terms := []string{}
+	for _, word := range words {
+		if len([]rune(word)) > 1 && !seen[word] {
+			seen[word] = true
+			terms = append(terms, word)
+		}
+	}
+	sort.Strings(terms)
+	return terms
+}
@@ -0,0 +1,114 @@
+// backup.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bkojha74/task-management/backup"
+	"github.com/bkojha74/task-management/readmodel"
+
+	"github.com/spf13/cobra"
+)
+
+// backupCommand groups the backup and restore commands.
+func backupCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "backup", Short: "Back up and restore the application data"}
+	cmd.AddCommand(backupCreateCommand(), backupRestoreCommand())
+	return cmd
+}
+
+// backupCreateCommand writes the application data to an archive.
+func backupCreateCommand() *cobra.Command {
+	var out string
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Write the users, tasks, comments and other data to an archive",
+		Example: `  taskctl backup create                            # backup-<date>.tar.gz in the current directory
+  taskctl backup create --out /backups/tasks.tar.gz`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := time.Now()
+			if out == "" {
+				out = "backup-" + now.UTC().Format("2006-01-02T150405Z") + ".tar.gz"
+			}
+			file, err := os.OpenFile(out, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+			if err != nil {
+				return err
+			}
+
+			manifest, err := backup.Create(context.Background(), backup.Mongo{}, file, now)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(out)
+				return err
+			}
+			for _, collection := range backup.Collections {
+				fmt.Printf("%-18s %d\n", collection, manifest.Collections[collection])
+			}
+			fmt.Printf("Wrote %s at schema version %d\n", out, manifest.Schema)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&out, "out", "", "path of the archive, which must not exist yet")
+	return cmd
+}
+
+// backupRestoreCommand loads an archive into the database.
+func backupRestoreCommand() *cobra.Command {
+	var in string
+	var replace bool
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Load an archive written by backup create or GET /admin/backup",
+		Long: `Loads an archive into the database configured by MONGO_URI. Run "taskctl migrate up" first, and stop the
+API servers while restoring. Collections holding documents are only overwritten with --replace. The task
+list view is rebuilt afterwards; run "taskctl search reindex" when a search engine is used. Encrypted task
+descriptions need the FIELD_ENCRYPTION_KEYS of the backed up deployment.`,
+		Example: `  taskctl backup restore --in backup-2024-07-05T120000Z.tar.gz
+  taskctl backup restore --in backup-2024-07-05T120000Z.tar.gz --replace`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if in == "" {
+				return errors.New("--in is required")
+			}
+			file, err := os.Open(in)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			ctx := context.Background()
+			manifest, err := backup.Restore(ctx, backup.Mongo{}, file, replace)
+			if err != nil {
+				return err
+			}
+			for _, collection := range backup.Collections {
+				if count, ok := manifest.Collections[collection]; ok {
+					fmt.Printf("%-18s %d\n", collection, count)
+				}
+			}
+
+			// The list view is derived from the tasks, so views of tasks replaced by the backup go
+			if replace {
+				if err := (backup.Mongo{}).Clear(ctx, "task_list_view"); err != nil {
+					return err
+				}
+			}
+			rebuilt, err := readmodel.Rebuild(ctx)
+			if err != nil {
+				return fmt.Errorf("rebuilding the task list view: %w", err)
+			}
+			fmt.Printf("Restored the backup of %s and rebuilt the list view of %d tasks\n", manifest.CreatedAt.Format(time.RFC3339), rebuilt)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&in, "in", "", "path of the archive")
+	cmd.Flags().BoolVar(&replace, "replace", false, "delete the documents of the restored collections first")
+	return cmd
+}
@@ -0,0 +1,200 @@
+// loadtest.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// loadtestCommand sends concurrent requests to a running API server and reports their latencies.
+func loadtestCommand() *cobra.Command {
+	var baseURL, username, password, token string
+	var paths []string
+	var requests, concurrency int
+	cmd := &cobra.Command{
+		Use:   "loadtest",
+		Short: "Measure the latency of API requests, e.g. of the task list of a heavy user",
+		Long: `Signs in and sends GET requests to a running API server from several concurrent clients, then prints
+the throughput and latency percentiles of each path. Seed a heavy user first, e.g. with
+"taskctl seed --users 1 --tasks 5000". Only run it against your own servers: it is not rate limited
+beyond what the server enforces, so raise RATE_LIMIT_READ_MAX for the test. It does not use MONGO_URI.`,
+		Example: `  taskctl loadtest --username seed-aarav00 --password password
+  taskctl loadtest --url https://staging.example.com --token "$TOKEN" --requests 2000 --concurrency 20 \
+    --path "/tasks" --path "/tasks?limit=50" --path "/tasks?fields=title,status"`,
+
+		// The API server is the target, not the database
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if requests <= 0 || concurrency <= 0 {
+				return errors.New("--requests and --concurrency must be positive")
+			}
+			baseURL = strings.TrimSuffix(baseURL, "/")
+			client := &http.Client{Timeout: 30 * time.Second}
+			if token == "" {
+				var err error
+				if token, err = signIn(client, baseURL, username, password); err != nil {
+					return err
+				}
+			}
+
+			fmt.Printf("%-48s %8s %6s %8s %8s %8s %8s %8s %10s\n", "path", "req/s", "errors", "p50", "p90", "p99", "max", "mean", "bytes")
+			for _, path := range paths {
+				result := runLoad(client, baseURL+path, token, requests, concurrency)
+				fmt.Printf("%-48s %8.1f %6d %8s %8s %8s %8s %8s %10d\n", path, result.throughput(), result.errors,
+					result.percentile(50), result.percentile(90), result.percentile(99), result.percentile(100),
+					result.mean(), result.bytes)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&baseURL, "url", "http://localhost:8080", "base URL of the API server")
+	cmd.Flags().StringVar(&username, "username", "", "user to sign in as, e.g. a seeded user")
+	cmd.Flags().StringVar(&password, "password", "password", "password of the user")
+	cmd.Flags().StringVar(&token, "token", "", "access token to use instead of signing in")
+	cmd.Flags().StringArrayVar(&paths, "path", []string{"/tasks", "/tasks?limit=50"}, "path and query to request; repeat for several")
+	cmd.Flags().IntVar(&requests, "requests", 500, "requests per path")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 10, "concurrent clients")
+	return cmd
+}
+
+// signIn returns the access token of a user from POST /signin.
+func signIn(client *http.Client, baseURL, username, password string) (string, error) {
+	if username == "" {
+		return "", errors.New("--username or --token is required")
+	}
+	body, _ := json.Marshal(map[string]string{"username": username, "password": password})
+	resp, err := client.Post(baseURL+"/signin", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("signing in: %s", resp.Status)
+	}
+
+	// The token is at the top level, or in data with the response envelope
+	var signedIn struct {
+		Token string `json:"token"`
+		Data  struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&signedIn); err != nil {
+		return "", err
+	}
+	if signedIn.Token == "" {
+		signedIn.Token = signedIn.Data.Token
+	}
+	return signedIn.Token, nil
+}
+
+// loadResult collects the outcome of the requests to one path.
+type loadResult struct {
+	latencies []time.Duration // Of the successful requests
+	errors    int             // Failed requests and non-2xx responses
+	bytes     int64           // Body size of the last successful response
+	elapsed   time.Duration
+}
+
+// runLoad sends requests GET requests to url from concurrency clients.
+func runLoad(client *http.Client, url, token string, requests, concurrency int) loadResult {
+	var mu sync.Mutex
+	result := loadResult{latencies: make([]time.Duration, 0, requests)}
+	work := make(chan struct{}, requests)
+	for i := 0; i < requests; i++ {
+		work <- struct{}{}
+	}
+	close(work)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range work {
+				latency, size, err := timeRequest(client, url, token)
+				mu.Lock()
+				if err != nil {
+					result.errors++
+				} else {
+					result.latencies = append(result.latencies, latency)
+					result.bytes = size
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	result.elapsed = time.Since(start)
+	sort.Slice(result.latencies, func(i, j int) bool { return result.latencies[i] < result.latencies[j] })
+	return result
+}
+
+// timeRequest sends one request and returns its latency until the whole body was read.
+func timeRequest(client *http.Client, url, token string) (time.Duration, int64, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("Authorization", token)
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	size, err := io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		return 0, 0, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return 0, 0, fmt.Errorf("%s", resp.Status)
+	}
+	return time.Since(start), size, nil
+}
+
+// throughput returns the successful requests per second.
+func (r loadResult) throughput() float64 {
+	if r.elapsed <= 0 {
+		return 0
+	}
+	return float64(len(r.latencies)) / r.elapsed.Seconds()
+}
+
+// percentile returns the latency below which p percent of the successful requests completed.
+func (r loadResult) percentile(p int) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	i := (len(r.latencies)*p + 99) / 100
+	if i > 0 {
+		i--
+	}
+	return r.latencies[i].Round(100 * time.Microsecond)
+}
+
+// mean returns the average latency of the successful requests.
+func (r loadResult) mean() time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, latency := range r.latencies {
+		total += latency
+	}
+	return (total / time.Duration(len(r.latencies))).Round(100 * time.Microsecond)
+}
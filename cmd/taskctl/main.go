@@ -0,0 +1,66 @@
+// main.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// taskctl is the operations tool of the task manager: user administration, task purges,
+// database migrations, backups and demo data, working through the same repository layer as the API server.
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+
+	"github.com/bkojha74/task-management/database"
+	"github.com/bkojha74/task-management/environment"
+	"github.com/bkojha74/task-management/fieldcrypt"
+	"github.com/bkojha74/task-management/helper"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/spf13/cobra"
+)
+
+// configDir is the directory holding the .env file, like for the API server.
+var configDir string
+
+func main() {
+	root := &cobra.Command{
+		Use:           "taskctl",
+		Short:         "Operations tool for the task manager",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+
+		CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
+
+		// Every command works on the database configured by MONGO_URI and MONGO_DATABASE
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// The .env file is optional, MONGO_URI may also be set in the environment
+			if _, err := os.Stat(configDir + "/.env"); err == nil {
+				helper.LoadEnv(configDir)
+			}
+			mongoURI := helper.GetEnv("MONGO_URI")
+			if mongoURI == "" {
+				return errors.New("MONGO_URI must be set")
+			}
+			keyring, err := fieldcrypt.LoadKeyring()
+			if err != nil {
+				return err
+			}
+			database.Name = environment.Database()
+			database.Init(mongoURI)
+			repository.InitMongo()
+			repository.UseFieldEncryption(keyring)
+			return nil
+		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			database.Disconnect()
+		},
+	}
+	root.PersistentFlags().StringVar(&configDir, "config", "config", "directory containing the .env file")
+
+	root.AddCommand(userCommand(), taskCommand(), migrateCommand(), seedCommand(), searchCommand(), backupCommand(), loadtestCommand())
+
+	if err := root.Execute(); err != nil {
+		log.Println("Error:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,90 @@
+// migrate.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bkojha74/task-management/database"
+	"github.com/bkojha74/task-management/migrations"
+
+	"github.com/spf13/cobra"
+)
+
+// migrateCommand groups the database migration commands.
+func migrateCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "migrate", Short: "Apply or revert database migrations"}
+	cmd.AddCommand(migrateUpCommand(), migrateDownCommand(), migrateStatusCommand())
+	return cmd
+}
+
+// migrateUpCommand applies pending migrations.
+func migrateUpCommand() *cobra.Command {
+	var target int
+	cmd := &cobra.Command{
+		Use:   "up",
+		Short: "Apply pending migrations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ran, err := migrations.Up(context.Background(), database.MongoClient.Database(database.Name), target)
+			for _, migration := range ran {
+				fmt.Printf("Applied %d: %s\n", migration.Version, migration.Description)
+			}
+			if err == nil && len(ran) == 0 {
+				fmt.Println("No pending migrations")
+			}
+			return err
+		},
+	}
+	cmd.Flags().IntVar(&target, "to", 0, "migrate up to this version (default latest)")
+	return cmd
+}
+
+// migrateDownCommand reverts applied migrations.
+func migrateDownCommand() *cobra.Command {
+	var steps int
+	cmd := &cobra.Command{
+		Use:   "down",
+		Short: "Revert the most recent migrations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ran, err := migrations.Down(context.Background(), database.MongoClient.Database(database.Name), steps)
+			for _, migration := range ran {
+				fmt.Printf("Reverted %d: %s\n", migration.Version, migration.Description)
+			}
+			if err == nil && len(ran) == 0 {
+				fmt.Println("No applied migrations")
+			}
+			return err
+		},
+	}
+	cmd.Flags().IntVar(&steps, "steps", 1, "number of migrations to revert")
+	return cmd
+}
+
+// migrateStatusCommand lists all migrations and whether they are applied.
+func migrateStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "List migrations and when they were applied",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			applied, err := migrations.Status(context.Background(), database.MongoClient.Database(database.Name))
+			if err != nil {
+				return err
+			}
+
+			appliedAt := map[int]string{}
+			for _, record := range applied {
+				appliedAt[record.Version] = record.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			for _, migration := range migrations.All {
+				state := "pending"
+				if at, ok := appliedAt[migration.Version]; ok {
+					state = "applied " + at
+				}
+				fmt.Printf("%3d  %-28s  %s\n", migration.Version, state, migration.Description)
+			}
+			return nil
+		},
+	}
+}
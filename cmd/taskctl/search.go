@@ -0,0 +1,68 @@
+// search.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/search"
+
+	"github.com/spf13/cobra"
+)
+
+// searchCommand groups the search engine commands.
+func searchCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "search", Short: "Maintain the search engine index"}
+	cmd.AddCommand(searchReindexCommand())
+	return cmd
+}
+
+// searchReindexCommand copies all tasks into the search engine, e.g. when it is first enabled or after
+// its index was lost.
+func searchReindexCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reindex",
+		Short: "Index all tasks in the search engine",
+		Long: `Creates the index configured by SEARCH_BACKEND and ELASTICSEARCH_INDEX if needed and indexes all tasks.
+The change stream keeps the index up to date afterwards; reindexing again is safe while the API runs.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			elastic, err := search.LoadElastic()
+			if err != nil {
+				return err
+			}
+			if elastic == nil {
+				return errors.New("SEARCH_BACKEND must be set to elasticsearch or opensearch")
+			}
+
+			ctx := context.Background()
+			if err := elastic.EnsureIndex(ctx); err != nil {
+				return err
+			}
+
+			// Index the tasks as stored, so encrypted descriptions stay out of the index
+			tasks := repository.Tasks
+			if encrypted, ok := tasks.(*repository.EncryptedTasks); ok {
+				tasks = encrypted.TaskRepository
+			}
+			all, err := tasks.Find(ctx, repository.TaskQuery{})
+			if err != nil {
+				return err
+			}
+
+			indexed := 0
+			for _, task := range all {
+				if err := elastic.IndexTask(ctx, task); err != nil {
+					fmt.Printf("Indexed %d tasks\n", indexed)
+					return err
+				}
+				indexed++
+			}
+			fmt.Printf("Indexed %d tasks\n", indexed)
+			return nil
+		},
+	}
+}
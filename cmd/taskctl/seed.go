@@ -0,0 +1,48 @@
+// seed.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bkojha74/task-management/helper"
+	"github.com/bkojha74/task-management/seed"
+
+	"github.com/spf13/cobra"
+)
+
+// seedCommand fills the database with demo users and tasks.
+func seedCommand() *cobra.Command {
+	opts := seed.Options{}
+	var allowRemote bool
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Populate the database with demo users and tasks",
+		Long: `Creates seed-* users and tasks with varied statuses, priorities and dates for demos and
+load testing. Seeding is refused when APP_ENV is "production", and for staging and MongoDB hosts
+other than localhost unless --allow-remote is given. Re-running reuses the seeded users and adds more tasks.`,
+		Example: `  taskctl seed
+  taskctl seed --users 20 --tasks 1000 --seed 42`,
+
+		// Check the target before writing anything
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return seed.CheckTarget(helper.GetEnv("APP_ENV"), helper.GetEnv("MONGO_URI"), allowRemote)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := seed.Run(context.Background(), opts)
+			fmt.Printf("Users created: %d, reused: %d, tasks created: %d\n", result.UsersCreated, result.UsersReused, result.TasksCreated)
+			if err == nil && result.UsersCreated > 0 {
+				fmt.Printf("Seeded users sign in with the password %q\n", opts.Password)
+			}
+			return err
+		},
+	}
+	cmd.Flags().IntVar(&opts.Users, "users", 10, "number of seeded users")
+	cmd.Flags().IntVar(&opts.Tasks, "tasks", 300, "number of tasks to create")
+	cmd.Flags().StringVar(&opts.Password, "password", "password", "password of the seeded users")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", 1, "random seed, the same seed generates the same fixtures")
+	cmd.Flags().BoolVar(&allowRemote, "allow-remote", false, "allow seeding a staging environment or a MongoDB host other than localhost")
+	return cmd
+}
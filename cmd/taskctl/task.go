@@ -0,0 +1,107 @@
+// task.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/readmodel"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/spf13/cobra"
+)
+
+// taskCommand groups the task maintenance commands.
+func taskCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "task", Short: "Maintain tasks"}
+	cmd.AddCommand(taskPurgeCommand(), taskReencryptCommand())
+	return cmd
+}
+
+// taskPurgeCommand deletes old tasks, e.g. done tasks due more than a year ago.
+func taskPurgeCommand() *cobra.Command {
+	var before, owner string
+	var statuses []string
+	var yes bool
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Delete tasks due before a date",
+		Example: `  taskctl task purge --before 2024-01-01             # count done tasks due before 2024
+  taskctl task purge --before 2024-01-01 --yes       # delete them
+  taskctl task purge --before 2024-01-01 --status Pending --owner alice --yes`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if before == "" {
+				return errors.New("--before is required")
+			}
+			dueBefore, err := time.Parse("2006-01-02", before)
+			if err != nil {
+				return fmt.Errorf("invalid --before date %q, expected YYYY-MM-DD", before)
+			}
+
+			ctx := context.Background()
+			query := repository.TaskQuery{Statuses: statuses, DueBefore: dueBefore}
+			if owner != "" {
+				user, err := repository.Users.FindByUsername(ctx, owner)
+				if err != nil {
+					return fmt.Errorf("owner %q: %w", owner, err)
+				}
+				query.UserID = user.ID
+			}
+
+			tasks, err := repository.Tasks.Find(ctx, query)
+			if err != nil {
+				return err
+			}
+			if !yes {
+				fmt.Printf("%d tasks would be deleted, run again with --yes to delete them\n", len(tasks))
+				return nil
+			}
+
+			deleted, err := repository.Tasks.DeleteMany(ctx, query)
+			if err != nil {
+				return err
+			}
+			for _, task := range tasks {
+				readmodel.RemoveOrLog(ctx, task.ID)
+				if err := repository.Tombstones.Create(ctx, &models.Tombstone{TaskID: task.ID, UserID: task.UserID}); err != nil {
+					return err
+				}
+			}
+
+			fmt.Printf("Deleted %d tasks\n", deleted)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&before, "before", "", "delete tasks due before this date (YYYY-MM-DD)")
+	cmd.Flags().StringSliceVar(&statuses, "status", []string{models.TaskStatusDone}, "only delete tasks with these statuses")
+	cmd.Flags().StringVar(&owner, "owner", "", "only delete tasks created by this username")
+	cmd.Flags().BoolVar(&yes, "yes", false, "delete instead of only counting the matching tasks")
+	return cmd
+}
+
+// taskReencryptCommand rewrites the encrypted task descriptions after a key rotation or after projects
+// became private.
+func taskReencryptCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reencrypt",
+		Short: "Re-encrypt task descriptions with the primary key",
+		Long: `Re-encrypts the descriptions of tasks in private projects that are stored in plaintext or with a key
+other than the first of FIELD_ENCRYPTION_KEYS, and decrypts those of tasks no longer in a private project.
+Once it reports no more rewrites, keys after the first can be removed.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			encrypted, ok := repository.Tasks.(*repository.EncryptedTasks)
+			if !ok {
+				return errors.New("FIELD_ENCRYPTION_KEYS must be set")
+			}
+
+			rewritten, err := encrypted.Rotate(context.Background())
+			fmt.Printf("Rewrote %d tasks\n", rewritten)
+			return err
+		},
+	}
+}
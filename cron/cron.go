@@ -0,0 +1,218 @@
+// cron.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package cron parses cron expressions, the five fields "minute hour day-of-month month day-of-week" of
+// crontab(5), and computes when they next fire.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxYears bounds the search for the next time, e.g. for "0 0 30 2 *" which never fires.
+const maxYears = 5
+
+// macros are the shorthands for common schedules.
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field is the range and the names of the values of a field.
+type field struct {
+	name     string
+	min, max int
+	names    []string // Names of the values from min, e.g. months
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64 // Bit i is set when value i matches
+
+	// Whether day of month and day of week are restricted; when both are, a day matching either fires
+	domRestricted, dowRestricted bool
+}
+
+// Parse parses a cron expression: five fields separated by spaces, each "*", a value, a range "a-b", any of
+// these with a step "/n", or a comma-separated list of them. Months and days of the week can be given by
+// their three-letter English names; Sunday is 0 or 7. The macros @yearly, @monthly, @weekly, @daily and
+// @hourly are accepted too.
+//
+// Parameters:
+// - expression: The cron expression, e.g. "0 9 * * mon-fri".
+//
+// Returns:
+// - Schedule: The schedule.
+// - error: A descriptive error if the expression is invalid.
+func Parse(expression string) (Schedule, error) {
+	expression = strings.TrimSpace(expression)
+	if macro, ok := macros[strings.ToLower(expression)]; ok {
+		expression = macro
+	}
+	parts := strings.Fields(expression)
+	if len(parts) != len(fields) {
+		return Schedule{}, errors.New("a cron expression has five fields: minute hour day-of-month month day-of-week")
+	}
+
+	sets := make([]uint64, len(fields))
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return Schedule{}, err
+		}
+		sets[i] = set
+	}
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1 // 7 is Sunday too
+	}
+	return Schedule{
+		minute:        sets[0],
+		hour:          sets[1],
+		dom:           sets[2],
+		month:         sets[3],
+		dow:           sets[4],
+		domRestricted: !strings.HasPrefix(parts[2], "*"),
+		dowRestricted: !strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+// parseField parses one field into the set of values it matches.
+func parseField(part string, f field) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(part, ",") {
+		rangePart, step := item, 1
+		if slash := strings.Index(item, "/"); slash >= 0 {
+			n, err := strconv.Atoi(item[slash+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step in %s field %q", f.name, item)
+			}
+			rangePart, step = item[:slash], n
+		}
+
+		low, high := f.min, f.max
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if low, err = f.value(bounds[0]); err != nil {
+				return 0, err
+			}
+			high = low
+			if len(bounds) == 2 {
+				if high, err = f.value(bounds[1]); err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				high = f.max // "a/n" runs from a to the end
+			}
+			if high < low {
+				return 0, fmt.Errorf("invalid range in %s field %q", f.name, item)
+			}
+		}
+		for v := low; v <= high; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// value parses a number or name of a field.
+func (f field) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s must be between %d and %d, got %q", f.name, f.min, f.max, s)
+	}
+	return v, nil
+}
+
+// Next returns the first time after t, to the minute, at which the schedule fires, in the location of t.
+// Times skipped by a daylight saving change do not fire. It returns the zero time if the schedule never
+// fires, e.g. on February 30.
+//
+// Parameters:
+// - t: The time to search from.
+//
+// Returns:
+// - time.Time: The next time, or the zero time.
+func (s Schedule) Next(t time.Time) time.Time {
+	location := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, location)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, location)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, location)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches reports whether the day of t matches the day of month and day of week fields.
+func (s Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// MinInterval returns the shortest time between two runs of the schedule within the year after t, e.g. to
+// refuse schedules firing every minute.
+//
+// Parameters:
+// - t: The time to start from.
+//
+// Returns:
+// - time.Duration: The shortest interval, or 0 if the schedule fires at most once.
+func (s Schedule) MinInterval(t time.Time) time.Duration {
+	var shortest time.Duration
+	previous := s.Next(t)
+	limit := t.AddDate(1, 0, 0)
+	for runs := 0; !previous.IsZero() && previous.Before(limit) && runs < 1000; runs++ {
+		next := s.Next(previous)
+		if next.IsZero() {
+			break
+		}
+		if gap := next.Sub(previous); shortest == 0 || gap < shortest {
+			shortest = gap
+		}
+		previous = next
+	}
+	return shortest
+}
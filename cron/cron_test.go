@@ -0,0 +1,72 @@
+// cron_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParse tests that valid expressions are accepted and invalid ones rejected
+func TestParse(t *testing.T) {
+	for _, expression := range []string{"* * * * *", "0 9 * * mon-fri", "*/15 8-18 1,15 jan-jun 7", "@weekly", "5/10 * * * *"} {
+		_, err := Parse(expression)
+		assert.NoError(t, err, expression)
+	}
+	for _, expression := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "@often"} {
+		_, err := Parse(expression)
+		assert.Error(t, err, expression)
+	}
+}
+
+// TestNext tests the next run of schedules, in the location of the given time
+func TestNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+	from := time.Date(2024, 7, 5, 10, 30, 0, 0, berlin) // A Friday
+
+	cases := []struct {
+		expression string
+		from       time.Time
+		want       time.Time
+	}{
+		{"0 9 * * mon-fri", from, time.Date(2024, 7, 8, 9, 0, 0, 0, berlin)},
+		{"*/20 * * * *", from, time.Date(2024, 7, 5, 10, 40, 0, 0, berlin)},
+		{"30 10 * * *", from, time.Date(2024, 7, 6, 10, 30, 0, 0, berlin)},
+		{"0 0 1 * *", from, time.Date(2024, 8, 1, 0, 0, 0, 0, berlin)},
+		{"0 8 13 * fri", from, time.Date(2024, 7, 12, 8, 0, 0, 0, berlin)}, // Day of month or day of week
+		{"0 12 29 2 *", from, time.Date(2028, 2, 29, 12, 0, 0, 0, berlin)},
+		{"0 * * * *", time.Date(2024, 7, 5, 10, 45, 0, 0, kolkata), time.Date(2024, 7, 5, 11, 0, 0, 0, kolkata)},
+		{"30 2 * * *", time.Date(2024, 3, 30, 12, 0, 0, 0, berlin), time.Date(2024, 4, 1, 2, 30, 0, 0, berlin)}, // 02:30 skipped on March 31
+	}
+	for _, c := range cases {
+		schedule, err := Parse(c.expression)
+		require.NoError(t, err)
+		assert.True(t, c.want.Equal(schedule.Next(c.from)), "%s: got %v", c.expression, schedule.Next(c.from))
+	}
+
+	never, err := Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, never.Next(from).IsZero())
+}
+
+// TestMinInterval tests the shortest time between runs
+func TestMinInterval(t *testing.T) {
+	from := time.Date(2024, 7, 5, 10, 30, 0, 0, time.UTC)
+	for expression, want := range map[string]time.Duration{
+		"* * * * *":       time.Minute,
+		"0,30 * * * *":    30 * time.Minute,
+		"0 9 * * mon-fri": 24 * time.Hour,
+		"@weekly":         7 * 24 * time.Hour,
+	} {
+		schedule, err := Parse(expression)
+		require.NoError(t, err)
+		assert.Equal(t, want, schedule.MinInterval(from), expression)
+	}
+}
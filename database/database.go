@@ -5,30 +5,132 @@ package database
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"time"
 
+	"github.com/bkojha74/task-management/helper"
+
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
+// Name is the name of the application database; set it before Init, e.g. from environment.Database.
+var Name = "taskmanager"
+
 // Global variables to store the MongoDB client and collection references
 var (
-	MongoClient     *mongo.Client
-	UsersCollection *mongo.Collection
-	TasksCollection *mongo.Collection
+	MongoClient            *mongo.Client
+	UsersCollection        *mongo.Collection
+	TasksCollection        *mongo.Collection
+	DeadLettersCollection  *mongo.Collection
+	WorkspacesCollection   *mongo.Collection
+	TaskListViewCollection *mongo.Collection
+	ProjectsCollection     *mongo.Collection
+	SessionsCollection     *mongo.Collection
+	SignInsCollection      *mongo.Collection
+	InvitationsCollection  *mongo.Collection
+	CommentsCollection     *mongo.Collection
+	InviteCodesCollection  *mongo.Collection
+	SettingsCollection     *mongo.Collection
+	AuditLogCollection     *mongo.Collection
+	LeasesCollection       *mongo.Collection
+	NoncesCollection       *mongo.Collection
+	OutboxCollection       *mongo.Collection
+	WebhooksCollection     *mongo.Collection
+
+	WebhookDeliveriesCollection *mongo.Collection
+	TombstonesCollection        *mongo.Collection
+	ReportSchedulesCollection   *mongo.Collection
+	SubscriptionsCollection     *mongo.Collection
+	TrashCollection             *mongo.Collection
+	FocusSessionsCollection     *mongo.Collection
+
+	UserTaskStatsCollection      *mongo.Collection
+	TaskSearchCollection         *mongo.Collection
+	ChangeStreamTokensCollection *mongo.Collection
+
+	// The client and collections of the reporting reads, set by ConnectReporting; nil when reports read
+	// through MongoClient
+	ReportingClient          *mongo.Client
+	ReportingTasksCollection *mongo.Collection
 )
 
-// Init initializes the MongoDB connection and sets up the collections
+// ReportingConfig selects where the reporting and analytics endpoints read from, so that their heavy
+// queries can be served by secondaries or a dedicated analytics node instead of the primary.
+type ReportingConfig struct {
+	URI            string        // Connection string of the reporting client; empty uses the one of the primary client
+	ReadPreference string        // primary, primaryPreferred, secondary, secondaryPreferred or nearest; empty keeps the URI's
+	MaxStaleness   time.Duration // Most replication lag of the secondaries read; 0 is unlimited
+}
+
+// LoadReportingConfig reads the reporting client settings from the MONGO_REPORTING_URI,
+// MONGO_REPORTING_READ_PREFERENCE and MONGO_REPORTING_MAX_STALENESS (seconds) environment variables.
+//
+// Returns:
+// - ReportingConfig: The settings; without any of the variables reports read through the primary client.
+func LoadReportingConfig() ReportingConfig {
+	return ReportingConfig{
+		URI:            helper.GetEnv("MONGO_REPORTING_URI"),
+		ReadPreference: helper.GetEnv("MONGO_REPORTING_READ_PREFERENCE"),
+		MaxStaleness:   time.Duration(helper.GetEnvInt("MONGO_REPORTING_MAX_STALENESS", 0)) * time.Second,
+	}
+}
+
+// Enabled reports whether reports use a client of their own.
+func (cfg ReportingConfig) Enabled() bool {
+	return cfg.URI != "" || cfg.ReadPreference != ""
+}
+
+// Validate rejects unknown read preferences and a max staleness the server would refuse.
+func (cfg ReportingConfig) Validate() error {
+	_, err := cfg.readPreference()
+	return err
+}
+
+// readPreference returns the configured read preference, or nil to keep the one of the URI.
+func (cfg ReportingConfig) readPreference() (*readpref.ReadPref, error) {
+	if cfg.ReadPreference == "" {
+		if cfg.MaxStaleness != 0 {
+			return nil, errors.New("a max staleness requires a read preference")
+		}
+		return nil, nil
+	}
+	mode, err := readpref.ModeFromString(cfg.ReadPreference)
+	if err != nil {
+		return nil, fmt.Errorf("unknown read preference %q, use primary, primaryPreferred, secondary, secondaryPreferred or nearest", cfg.ReadPreference)
+	}
+	if cfg.MaxStaleness == 0 {
+		return readpref.New(mode)
+	}
+	// MongoDB requires at least 90 seconds, and rejects a max staleness with the primary mode
+	if cfg.MaxStaleness < 90*time.Second {
+		return nil, errors.New("the max staleness must be at least 90 seconds")
+	}
+	return readpref.New(mode, readpref.WithMaxStaleness(cfg.MaxStaleness))
+}
+
+// Init initializes the MongoDB connection and sets up the collections, exiting if MongoDB is unreachable
 // mongoURI is the URI string for connecting to the MongoDB instance
 func Init(mongoURI string) {
+	if err := Connect(mongoURI); err != nil {
+		log.Fatal("Error ", err)
+	}
+}
+
+// Connect initializes the MongoDB connection and sets up the collections like Init, but returns an error
+// if MongoDB is unreachable, e.g. for the startup self-check to report it
+// mongoURI is the URI string for connecting to the MongoDB instance
+func Connect(mongoURI string) error {
 	// Set up client options with the provided MongoDB URI
 	clientOptions := options.Client().ApplyURI(mongoURI)
 
 	// Connect to MongoDB
 	client, err := mongo.Connect(context.Background(), clientOptions)
 	if err != nil {
-		log.Fatal("Error connecting to MongoDB: ", err)
+		return fmt.Errorf("connecting to MongoDB: %w", err)
 	}
 
 	// Create a context with a timeout for the ping operation
@@ -38,17 +140,105 @@ func Init(mongoURI string) {
 	// Ping the MongoDB server to ensure the connection is established
 	err = client.Ping(ctx, nil)
 	if err != nil {
-		log.Fatal("Error pinging MongoDB: ", err)
+		client.Disconnect(context.Background())
+		return fmt.Errorf("pinging MongoDB: %w", err)
 	}
 
 	// Assign the connected client to the global MongoClient variable
 	MongoClient = client
 	// Initialize the users collection reference
-	UsersCollection = client.Database("taskmanager").Collection("users")
+	UsersCollection = client.Database(Name).Collection("users")
 	// Initialize the tasks collection reference
-	TasksCollection = client.Database("taskmanager").Collection("tasks")
+	TasksCollection = client.Database(Name).Collection("tasks")
+	// Initialize the dead letters collection reference
+	DeadLettersCollection = client.Database(Name).Collection("dead_letters")
+	// Initialize the workspaces collection reference
+	WorkspacesCollection = client.Database(Name).Collection("workspaces")
+	// Initialize the denormalized task list view collection reference
+	TaskListViewCollection = client.Database(Name).Collection("task_list_view")
+	// Initialize the projects collection reference
+	ProjectsCollection = client.Database(Name).Collection("projects")
+	// Initialize the sign-in sessions collection reference
+	SessionsCollection = client.Database(Name).Collection("sessions")
+	// Initialize the collection of the sign-in history of users
+	SignInsCollection = client.Database(Name).Collection("sign_ins")
+	// Initialize the collection of the guest invitations to projects
+	InvitationsCollection = client.Database(Name).Collection("invitations")
+	// Initialize the collection of the comments on tasks
+	CommentsCollection = client.Database(Name).Collection("comments")
+	// Initialize the collection of the invite codes of invitation-only registration
+	InviteCodesCollection = client.Database(Name).Collection("invite_codes")
+	// Initialize the collection of settings shared by all instances, such as the maintenance mode
+	SettingsCollection = client.Database(Name).Collection("settings")
+	// Initialize the audit log collection reference, written when AUDIT_SINK is mongo
+	AuditLogCollection = client.Database(Name).Collection("audit_log")
+	// Initialize the collection of leases that let one instance at a time run a background job
+	LeasesCollection = client.Database(Name).Collection("leases")
+	// Initialize the collection of single-use values, such as the IDs of consumed SAML assertions
+	NoncesCollection = client.Database(Name).Collection("nonces")
+	// Initialize the outbox collection reference, the events and notifications waiting for the relay
+	OutboxCollection = client.Database(Name).Collection("outbox")
+	// Initialize the collections of the webhooks registered by admins and the deliveries to them
+	WebhooksCollection = client.Database(Name).Collection("webhooks")
+	WebhookDeliveriesCollection = client.Database(Name).Collection("webhook_deliveries")
+	// Initialize the collection of the tombstones of deleted tasks, read by syncing offline clients
+	TombstonesCollection = client.Database(Name).Collection("tombstones")
+	// Initialize the collection of the reports users scheduled to be emailed
+	ReportSchedulesCollection = client.Database(Name).Collection("report_schedules")
+	// Initialize the collection of the billing state of workspaces, kept in step with Stripe
+	SubscriptionsCollection = client.Database(Name).Collection("subscriptions")
+	// Initialize the collection of deleted tasks kept until they are restored or purged
+	TrashCollection = client.Database(Name).Collection("trash")
+	// Initialize the collection of the focus sessions of users on their tasks
+	FocusSessionsCollection = client.Database(Name).Collection("focus_sessions")
+	// Initialize the collections derived from the tasks change stream
+	UserTaskStatsCollection = client.Database(Name).Collection("user_task_stats")
+	TaskSearchCollection = client.Database(Name).Collection("task_search")
+	ChangeStreamTokensCollection = client.Database(Name).Collection("change_stream_tokens")
 
 	log.Println("Connected to MongoDB!")
+	return nil
+}
+
+// ConnectReporting opens the client of the reporting reads with the read preference of cfg, and the
+// collections read through it. It connects to cfg.URI or, if that is empty, to mongoURI.
+//
+// Parameters:
+// - mongoURI: The URI of the primary client, used when cfg has none.
+// - cfg: The reporting client settings.
+//
+// Returns:
+// - error: An error if the settings are invalid or MongoDB is unreachable.
+func ConnectReporting(mongoURI string, cfg ReportingConfig) error {
+	pref, err := cfg.readPreference()
+	if err != nil {
+		return err
+	}
+	uri := cfg.URI
+	if uri == "" {
+		uri = mongoURI
+	}
+	clientOptions := options.Client().ApplyURI(uri)
+	if pref != nil {
+		clientOptions.SetReadPreference(pref)
+	}
+
+	client, err := mongo.Connect(context.Background(), clientOptions)
+	if err != nil {
+		return fmt.Errorf("connecting the reporting client to MongoDB: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	// Ping with the client's read preference, so that a missing secondary is reported now
+	if err := client.Ping(ctx, clientOptions.ReadPreference); err != nil {
+		client.Disconnect(context.Background())
+		return fmt.Errorf("pinging the reporting MongoDB: %w", err)
+	}
+
+	ReportingClient = client
+	ReportingTasksCollection = client.Database(Name).Collection("tasks")
+	log.Println("Connected the reporting client to MongoDB!")
+	return nil
 }
 
 // Disconnect disconnects from the MongoDB server
@@ -62,4 +252,9 @@ func Disconnect() {
 		}
 		log.Println("Disconnected from MongoDB.")
 	}
+	if ReportingClient != nil {
+		if err := ReportingClient.Disconnect(context.Background()); err != nil {
+			log.Println("Error disconnecting the reporting client from MongoDB: ", err)
+		}
+	}
 }
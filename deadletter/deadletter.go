@@ -0,0 +1,197 @@
+// deadletter.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package deadletter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bkojha74/task-management/database"
+	"github.com/bkojha74/task-management/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Kinds of work that can end up in the dead-letter collection
+const (
+	KindJob          = "job"
+	KindNotification = "notification"
+	KindWebhook      = "webhook"
+	KindEvent        = "event"
+)
+
+// Replayer re-executes a dead-lettered payload of a given kind.
+type Replayer func(ctx context.Context, payload map[string]interface{}) error
+
+var (
+	// ErrNotFound is returned when the requested dead letter does not exist.
+	ErrNotFound = errors.New("dead letter not found")
+	// ErrNoReplayer is returned when no replayer is registered for the dead letter's kind.
+	ErrNoReplayer = errors.New("no replayer registered for kind")
+	// ErrNotPending is returned when a dead letter was already replayed or discarded.
+	ErrNotPending = errors.New("dead letter is not pending")
+
+	replayersMu sync.RWMutex
+	replayers   = map[string]Replayer{}
+)
+
+// RegisterReplayer registers the function used to replay dead letters of the given kind.
+// Subsystems producing dead letters call it once during start-up.
+func RegisterReplayer(kind string, replayer Replayer) {
+	replayersMu.Lock()
+	defer replayersMu.Unlock()
+	replayers[kind] = replayer
+}
+
+// Record stores a failed payload in the dead-letter collection.
+//
+// Parameters:
+// - ctx: Context for the database operation.
+// - kind: The kind of work that failed (job, notification, webhook).
+// - payload: The payload needed to replay the work.
+// - cause: The error that made the work fail.
+//
+// Returns:
+// - error: An error object if the dead letter could not be stored.
+func Record(ctx context.Context, kind string, payload map[string]interface{}, cause error) error {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	deadLetter := models.DeadLetter{
+		ID:        primitive.NewObjectID(),
+		Kind:      kind,
+		Payload:   payload,
+		Attempts:  1,
+		Status:    models.DeadLetterPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if cause != nil {
+		deadLetter.Error = cause.Error()
+	}
+
+	_, err := database.DeadLettersCollection.InsertOne(ctx, deadLetter)
+	return err
+}
+
+// Find returns the dead letter with the given ID.
+func Find(ctx context.Context, id primitive.ObjectID) (*models.DeadLetter, error) {
+	var deadLetter models.DeadLetter
+	err := database.DeadLettersCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&deadLetter)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &deadLetter, nil
+}
+
+// List returns dead letters matching the optional kind and status filters, newest first.
+func List(ctx context.Context, kind, status string) ([]models.DeadLetter, error) {
+	filter := bson.M{}
+	if kind != "" {
+		filter["kind"] = kind
+	}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	cursor, err := database.DeadLettersCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetters := []models.DeadLetter{}
+	if err = cursor.All(ctx, &deadLetters); err != nil {
+		return nil, err
+	}
+	return deadLetters, nil
+}
+
+// UpdatePayload replaces the payload of a pending dead letter, e.g. to fix bad data before a replay.
+func UpdatePayload(ctx context.Context, id primitive.ObjectID, payload map[string]interface{}) (*models.DeadLetter, error) {
+	result, err := database.DeadLettersCollection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": models.DeadLetterPending},
+		bson.M{"$set": bson.M{"payload": payload, "updated_at": primitive.NewDateTimeFromTime(time.Now())}})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		if _, err := Find(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrNotPending
+	}
+	return Find(ctx, id)
+}
+
+// Discard marks a pending dead letter as discarded, so that it is never replayed, keeping it for the record.
+func Discard(ctx context.Context, id primitive.ObjectID, reason string) (*models.DeadLetter, error) {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	set := bson.M{"status": models.DeadLetterDiscarded, "discarded_at": now, "updated_at": now}
+	if reason != "" {
+		set["reason"] = reason
+	}
+	result, err := database.DeadLettersCollection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": models.DeadLetterPending}, bson.M{"$set": set})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		if _, err := Find(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrNotPending
+	}
+	return Find(ctx, id)
+}
+
+// Replay re-executes a pending dead letter with the replayer registered for its kind.
+// On success the dead letter is marked as replayed; on failure the attempt count and error are updated.
+func Replay(ctx context.Context, id primitive.ObjectID) (*models.DeadLetter, error) {
+	deadLetter, err := Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if deadLetter.Status != models.DeadLetterPending {
+		return nil, ErrNotPending
+	}
+
+	replayersMu.RLock()
+	replayer, ok := replayers[deadLetter.Kind]
+	replayersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrNoReplayer, deadLetter.Kind)
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	update := bson.M{"updated_at": now}
+	replayErr := replayer(ctx, deadLetter.Payload)
+	if replayErr != nil {
+		update["error"] = replayErr.Error()
+	} else {
+		update["status"] = models.DeadLetterReplayed
+		update["replayed_at"] = now
+	}
+
+	_, err = database.DeadLettersCollection.UpdateOne(ctx, bson.M{"_id": id},
+		bson.M{"$set": update, "$inc": bson.M{"attempts": 1}})
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetter, err = Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if replayErr != nil {
+		return deadLetter, replayErr
+	}
+	return deadLetter, nil
+}
@@ -0,0 +1,98 @@
+// devices.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package devices recognizes the browser, operating system and network a sign-in comes from, so that users
+// can be told about sign-ins from devices and places their account was not used from before.
+package devices
+
+import (
+	"net"
+	"strings"
+)
+
+// Device is the browser and operating system named by a User-Agent header.
+type Device struct {
+	Browser string `json:"browser"`
+	OS      string `json:"os"`
+}
+
+// String describes the device, e.g. "Firefox on Linux".
+func (d Device) String() string {
+	return d.Browser + " on " + d.OS
+}
+
+// browsers are the browsers and clients told apart, checked in order: Edge and Opera also claim to be
+// Chrome, and Chrome also claims to be Safari.
+var browsers = []struct{ token, name string }{
+	{"Edg/", "Edge"},
+	{"Edge/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Opera", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"CriOS/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"curl/", "curl"},
+	{"PostmanRuntime/", "Postman"},
+	{"okhttp/", "Android app"},
+	{"CFNetwork/", "iOS app"},
+}
+
+// systems are the operating systems told apart, checked in order: Android and ChromeOS also claim to be
+// Linux, and iOS devices like Mac OS X.
+var systems = []struct{ token, name string }{
+	{"Windows", "Windows"},
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Android", "Android"},
+	{"CrOS", "ChromeOS"},
+	{"Mac OS X", "macOS"},
+	{"Macintosh", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// Parse returns the device of a User-Agent header. Browsers and systems it does not know are "Unknown
+// browser" and "unknown OS".
+//
+// Parameters:
+// - userAgent: The User-Agent header of the request.
+//
+// Returns:
+// - Device: The browser and operating system.
+func Parse(userAgent string) Device {
+	device := Device{Browser: "Unknown browser", OS: "unknown OS"}
+	for _, browser := range browsers {
+		if strings.Contains(userAgent, browser.token) {
+			device.Browser = browser.name
+			break
+		}
+	}
+	for _, system := range systems {
+		if strings.Contains(userAgent, system.token) {
+			device.OS = system.name
+			break
+		}
+	}
+	return device
+}
+
+// Network returns the network an IP address belongs to, as a location that does not change with every
+// address a provider hands out: the /24 of IPv4 and the /48 of IPv6 addresses. Addresses that do not parse
+// are returned as they are.
+//
+// Parameters:
+// - ip: The client IP of the request.
+//
+// Returns:
+// - string: The network in CIDR notation, e.g. "203.0.113.0/24".
+func Network(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ip
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: parsed.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
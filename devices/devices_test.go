@@ -0,0 +1,36 @@
+// devices_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package devices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParse tests which browser and system User-Agent headers name
+func TestParse(t *testing.T) {
+	cases := map[string]string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36":                  "Chrome on Windows",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.0.0":    "Edge on Windows",
+		"Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0":                                                           "Firefox on Linux",
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Safari/605.1.15":               "Safari on macOS",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile Safari/604.1": "Safari on iOS",
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Mobile Safari/537.36":            "Chrome on Android",
+		"curl/8.5.0": "curl on unknown OS",
+		"":           "Unknown browser on unknown OS",
+	}
+	for userAgent, want := range cases {
+		assert.Equal(t, want, Parse(userAgent).String(), userAgent)
+	}
+}
+
+// TestNetwork tests the networks of addresses
+func TestNetwork(t *testing.T) {
+	assert.Equal(t, "203.0.113.0/24", Network("203.0.113.7"))
+	assert.Equal(t, Network("203.0.113.7"), Network("203.0.113.200"))
+	assert.NotEqual(t, Network("203.0.113.7"), Network("203.0.114.7"))
+	assert.Equal(t, "2001:db8:1::/48", Network("2001:db8:1:2::7"))
+	assert.Equal(t, "unknown", Network("unknown"))
+}
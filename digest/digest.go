@@ -0,0 +1,306 @@
+// digest.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package digest builds and sends the daily or weekly digest emails summarizing, for each user who asked
+// for them, the tasks due soon, the overdue tasks and the recent activity on their tasks.
+package digest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/bkojha74/task-management/branding"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxListed is the most tasks listed in each section of a digest; the others are only counted.
+const maxListed = 20
+
+// usersPerPage is the number of users loaded at once by SendDue.
+const usersPerPage = 200
+
+// Mailer sends HTML emails, e.g. a notifications.SMTP.
+type Mailer interface {
+	SendMail(ctx context.Context, sender, to, subject, html string) error
+}
+
+// Digest is the summary of a user's tasks for one period.
+type Digest struct {
+	Frequency string        // daily or weekly
+	Since     time.Time     // Start of the period of the recent activity
+	Upcoming  []models.Task // Open tasks due within the period ahead, soonest first
+	Overdue   []models.Task // Open tasks past their end date, most overdue first
+	Completed []models.Task // Tasks completed since Since
+	Updated   []models.Task // Other open tasks changed since Since
+}
+
+// Empty reports whether the digest has nothing to tell.
+func (d Digest) Empty() bool {
+	return len(d.Upcoming) == 0 && len(d.Overdue) == 0 && len(d.Completed) == 0 && len(d.Updated) == 0
+}
+
+// Validate checks a digest schedule.
+//
+// Parameters:
+// - settings: The schedule to validate.
+//
+// Returns:
+// - error: A descriptive error if any field is invalid.
+func Validate(settings models.DigestSettings) error {
+	switch settings.Frequency {
+	case models.DigestOff, models.DigestDaily, models.DigestWeekly:
+	default:
+		return errors.New("frequency must be off, daily or weekly")
+	}
+	if settings.Hour < 0 || settings.Hour > 23 {
+		return errors.New("hour must be between 0 and 23")
+	}
+	if settings.Weekday < 0 || settings.Weekday > 6 {
+		return errors.New("weekday must be between 0 (Sunday) and 6 (Saturday)")
+	}
+	return nil
+}
+
+// period returns the length of the period a digest covers.
+func period(frequency string) time.Duration {
+	if frequency == models.DigestWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// LastScheduled returns the latest time at or before now at which a digest was scheduled.
+//
+// Parameters:
+// - settings: The digest schedule.
+// - now: The current time.
+// - location: The timezone of the user.
+//
+// Returns:
+// - time.Time: The scheduled time.
+func LastScheduled(settings models.DigestSettings, now time.Time, location *time.Location) time.Time {
+	local := now.In(location)
+	scheduled := time.Date(local.Year(), local.Month(), local.Day(), settings.Hour, 0, 0, 0, location)
+	if scheduled.After(local) {
+		scheduled = scheduled.AddDate(0, 0, -1)
+	}
+	if settings.Frequency == models.DigestWeekly {
+		for int(scheduled.Weekday()) != settings.Weekday {
+			scheduled = scheduled.AddDate(0, 0, -1)
+		}
+	}
+	return scheduled
+}
+
+// Due reports whether a user should get a digest now: they asked for one, have an email address, and
+// none was sent since it was last scheduled.
+//
+// Parameters:
+// - user: The user.
+// - now: The current time.
+//
+// Returns:
+// - bool: True if a digest is due.
+func Due(user models.User, now time.Time) bool {
+	settings := user.Digest
+	if settings == nil || settings.Frequency == "" || settings.Frequency == models.DigestOff || user.Email == "" {
+		return false
+	}
+	if !user.DeactivatedAt.IsZero() || !user.DeleteAt.IsZero() {
+		return false
+	}
+	scheduled := LastScheduled(*settings, now, location(user))
+	return settings.LastSentAt.IsZero() || settings.LastSentAt.Time().Before(scheduled)
+}
+
+// Build aggregates the digest of a user: the open tasks allotted to them that are due within the coming
+// period or overdue, and the tasks allotted to them that were completed or changed since the last digest,
+// or during the past period for the first one. Changed tasks already listed as due are not repeated.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - user: The recipient.
+// - now: The current time.
+//
+// Returns:
+// - Digest: The digest.
+// - error: An error if the tasks could not be read.
+func Build(ctx context.Context, user models.User, now time.Time) (Digest, error) {
+	digest := Digest{Frequency: models.DigestDaily}
+	if user.Digest != nil && user.Digest.Frequency == models.DigestWeekly {
+		digest.Frequency = models.DigestWeekly
+	}
+	length := period(digest.Frequency)
+	digest.Since = now.Add(-length)
+	if user.Digest != nil && !user.Digest.LastSentAt.IsZero() {
+		digest.Since = user.Digest.LastSentAt.Time()
+	}
+
+	open, err := repository.ReportTasks.Find(ctx, repository.TaskQuery{
+		AllottedTo:    user.Username,
+		ExcludeStatus: models.TaskStatusDone,
+		HasDueDate:    true,
+		DueBefore:     now.Add(length),
+	})
+	if err != nil {
+		return digest, err
+	}
+	for _, task := range open {
+		if task.EndDate.Time().Before(now) {
+			digest.Overdue = append(digest.Overdue, task)
+		} else {
+			digest.Upcoming = append(digest.Upcoming, task)
+		}
+	}
+	sortByEndDate(digest.Overdue)
+	sortByEndDate(digest.Upcoming)
+
+	if digest.Completed, err = repository.ReportTasks.Find(ctx, repository.TaskQuery{
+		AllottedTo:     user.Username,
+		CompletedAfter: digest.Since,
+	}); err != nil {
+		return digest, err
+	}
+	updated, err := repository.ReportTasks.Find(ctx, repository.TaskQuery{
+		AllottedTo:    user.Username,
+		ExcludeStatus: models.TaskStatusDone,
+		UpdatedAfter:  digest.Since,
+	})
+	if err != nil {
+		return digest, err
+	}
+	listed := make(map[primitive.ObjectID]bool, len(open))
+	for _, task := range open {
+		listed[task.ID] = true
+	}
+	for _, task := range updated {
+		if !listed[task.ID] {
+			digest.Updated = append(digest.Updated, task)
+		}
+	}
+	return digest, nil
+}
+
+// sortByEndDate orders tasks by their end date, earliest first.
+func sortByEndDate(tasks []models.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].EndDate < tasks[j].EndDate })
+}
+
+// body is the HTML of a digest inside the branded layout.
+var body = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"date": func(t models.Timestamp) string { return t.Time().UTC().Format("Mon, 02 Jan 15:04 MST") },
+	"head": func(tasks []models.Task) []models.Task {
+		if len(tasks) > maxListed {
+			return tasks[:maxListed]
+		}
+		return tasks
+	},
+	"more": func(tasks []models.Task) int {
+		if len(tasks) > maxListed {
+			return len(tasks) - maxListed
+		}
+		return 0
+	},
+}).Parse(`{{define "list"}}<ul>{{range head .}}<li>{{.Title}}{{if .EndDate}} <span style="color:#6b7280">due {{date .EndDate}}</span>{{end}}</li>{{end}}</ul>{{with more .}}<p style="color:#6b7280">and {{.}} more</p>{{end}}{{end}}
+{{if .Overdue}}<h3>Overdue ({{len .Overdue}})</h3>{{template "list" .Overdue}}{{end}}
+{{if .Upcoming}}<h3>Due {{if eq .Frequency "weekly"}}this week{{else}}soon{{end}} ({{len .Upcoming}})</h3>{{template "list" .Upcoming}}{{end}}
+{{if .Completed}}<h3>Completed ({{len .Completed}})</h3>{{template "list" .Completed}}{{end}}
+{{if .Updated}}<h3>Recently changed ({{len .Updated}})</h3>{{template "list" .Updated}}{{end}}`))
+
+// Render produces the subject and the branded HTML of a digest.
+//
+// Parameters:
+// - digest: The digest.
+// - b: The branding of the recipient's workspace.
+//
+// Returns:
+// - string: The subject line.
+// - string: The HTML document.
+// - error: An error if rendering fails.
+func Render(digest Digest, b models.Branding) (string, string, error) {
+	subject := "Your daily task digest"
+	if digest.Frequency == models.DigestWeekly {
+		subject = "Your weekly task digest"
+	}
+
+	var buf bytes.Buffer
+	if err := body.Execute(&buf, digest); err != nil {
+		return "", "", err
+	}
+	html, err := branding.Render(b, subject, template.HTML(buf.String()))
+	if err != nil {
+		return "", "", err
+	}
+	return subject, html, nil
+}
+
+// SendDue sends the digests that are due and records when they were sent. Digests with nothing to tell
+// are skipped but count as sent, so that users are not mailed about nothing. A digest that cannot be sent
+// is logged and tried again by the next run.
+//
+// Parameters:
+// - ctx: Context for the database operations and deliveries.
+// - mailer: The mail server.
+// - now: The current time.
+//
+// Returns:
+// - int: The number of digests sent.
+// - error: An error if the users or tasks could not be read.
+func SendDue(ctx context.Context, mailer Mailer, now time.Time) (int, error) {
+	sent := 0
+	for offset := 0; ; offset += usersPerPage {
+		users, _, err := repository.Users.List(ctx, offset, usersPerPage)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, user := range users {
+			if !Due(user, now) {
+				continue
+			}
+			digest, err := Build(ctx, user, now)
+			if err != nil {
+				return sent, err
+			}
+
+			if !digest.Empty() {
+				b := branding.ForUser(ctx, user.ID)
+				subject, html, err := Render(digest, b)
+				if err != nil {
+					return sent, err
+				}
+				if err := mailer.SendMail(ctx, b.SenderName, user.Email, subject, html); err != nil {
+					log.Printf("Could not send the digest of %s: %v", user.Username, err)
+					continue
+				}
+				sent++
+			}
+
+			user.Digest.LastSentAt = models.NewTimestamp(now)
+			if err := repository.Users.Update(ctx, &user); err != nil {
+				return sent, err
+			}
+		}
+
+		if len(users) < usersPerPage {
+			return sent, nil
+		}
+	}
+}
+
+// location returns the timezone of a user, UTC when none or an unknown one is set.
+func location(user models.User) *time.Location {
+	location, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
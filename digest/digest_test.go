@@ -0,0 +1,109 @@
+// digest_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package digest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mailbox records the emails sent to it.
+type mailbox struct {
+	to, subjects, bodies []string
+}
+
+func (m *mailbox) SendMail(ctx context.Context, sender, to, subject, html string) error {
+	m.to = append(m.to, to)
+	m.subjects = append(m.subjects, subject)
+	m.bodies = append(m.bodies, html)
+	return nil
+}
+
+// TestValidate tests that frequencies, hours and weekdays out of range are rejected
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(models.DigestSettings{Frequency: models.DigestOff}))
+	assert.NoError(t, Validate(models.DigestSettings{Frequency: models.DigestWeekly, Hour: 23, Weekday: 6}))
+	assert.Error(t, Validate(models.DigestSettings{Frequency: "hourly"}))
+	assert.Error(t, Validate(models.DigestSettings{Frequency: models.DigestDaily, Hour: 24}))
+	assert.Error(t, Validate(models.DigestSettings{Frequency: models.DigestWeekly, Weekday: 7}))
+}
+
+// TestLastScheduled tests the latest scheduled time of daily and weekly digests in the user's timezone
+func TestLastScheduled(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+	now := time.Date(2024, 7, 10, 3, 0, 0, 0, time.UTC) // Wednesday 08:30 in Kolkata
+
+	daily := models.DigestSettings{Frequency: models.DigestDaily, Hour: 8}
+	assert.Equal(t, time.Date(2024, 7, 10, 8, 0, 0, 0, kolkata), LastScheduled(daily, now, kolkata))
+	daily.Hour = 9
+	assert.Equal(t, time.Date(2024, 7, 9, 9, 0, 0, 0, kolkata), LastScheduled(daily, now, kolkata))
+
+	weekly := models.DigestSettings{Frequency: models.DigestWeekly, Hour: 9, Weekday: int(time.Monday)}
+	assert.Equal(t, time.Date(2024, 7, 8, 9, 0, 0, 0, kolkata), LastScheduled(weekly, now, kolkata))
+	weekly.Weekday = int(time.Wednesday)
+	assert.Equal(t, time.Date(2024, 7, 3, 9, 0, 0, 0, kolkata), LastScheduled(weekly, now, kolkata))
+}
+
+// TestSendDue tests that due digests list overdue, upcoming and completed tasks, are sent once per period,
+// and are not sent to users without a schedule or an email
+func TestSendDue(t *testing.T) {
+	repository.UseMemory()
+	ctx := context.Background()
+	now := time.Now()
+
+	daily := &models.DigestSettings{Frequency: models.DigestDaily, Hour: now.UTC().Hour(), LastSentAt: models.NewTimestamp(now.Add(-25 * time.Hour))}
+	reader := models.User{Username: "reader", Email: "reader@example.com", Digest: daily}
+	silent := models.User{Username: "silent", Email: "silent@example.com"}
+	noEmail := models.User{Username: "noemail", Digest: &models.DigestSettings{Frequency: models.DigestDaily}}
+	for _, user := range []*models.User{&reader, &silent, &noEmail} {
+		require.NoError(t, repository.Users.Create(ctx, user))
+	}
+
+	tasks := []models.Task{
+		{Title: "Late report", EndDate: models.NewTimestamp(now.Add(-2 * time.Hour))},
+		{Title: "Call supplier", EndDate: models.NewTimestamp(now.Add(3 * time.Hour))},
+		{Title: "Plan next quarter", EndDate: models.NewTimestamp(now.Add(72 * time.Hour))},
+		{Title: "Ship release", Status: models.TaskStatusDone, CompletedAt: primitive.NewDateTimeFromTime(now.Add(-time.Hour))},
+		{Title: "Someone else's", EndDate: models.NewTimestamp(now.Add(time.Hour))},
+	}
+	for i := range tasks {
+		tasks[i].UserID = reader.ID
+		tasks[i].AllottedTo = "reader"
+		if i == len(tasks)-1 {
+			tasks[i].AllottedTo = "silent"
+		}
+		require.NoError(t, repository.Tasks.Create(ctx, &tasks[i]))
+	}
+
+	mail := &mailbox{}
+	sent, err := SendDue(ctx, mail, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.Equal(t, []string{"reader@example.com"}, mail.to)
+	assert.Equal(t, "Your daily task digest", mail.subjects[0])
+	body := mail.bodies[0]
+	assert.True(t, strings.Index(body, "Overdue (1)") < strings.Index(body, "Late report"))
+	assert.Contains(t, body, "Call supplier")
+	assert.Contains(t, body, "Ship release")
+	assert.True(t, strings.Index(body, "Recently changed (1)") < strings.Index(body, "Plan next quarter"))
+	assert.NotContains(t, body, "Someone else")
+
+	// The digest is not sent again before the next scheduled time
+	sent, err = SendDue(ctx, mail, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+	stored, err := repository.Users.FindByID(ctx, reader.ID)
+	require.NoError(t, err)
+	assert.Equal(t, now.Unix(), stored.Digest.LastSentAt.Time().Unix())
+}
@@ -0,0 +1,111 @@
+// environment.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package environment names the deployment environment selected by APP_ENV, so that the same binary
+// picks safe defaults in each: the database it uses, the log level, the CORS origins and whether the
+// database may be seeded. Settings configured explicitly always take precedence over these defaults.
+package environment
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bkojha74/task-management/helper"
+)
+
+// The environments of APP_ENV. Without APP_ENV the defaults of earlier releases apply.
+const (
+	Development = "development"
+	Test        = "test"
+	Staging     = "staging"
+	Production  = "production"
+)
+
+// DefaultDatabase is the MongoDB database of production deployments and of deployments without APP_ENV.
+const DefaultDatabase = "taskmanager"
+
+// Parse parses an environment name: development, test, staging or production, case-insensitive, with
+// the short forms dev, stage and prod. An empty name is returned as is.
+//
+// Parameters:
+// - name: The environment name, e.g. from APP_ENV.
+//
+// Returns:
+// - string: The environment, or "" if the name is empty.
+// - error: An error if the name is unknown.
+func Parse(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "":
+		return "", nil
+	case "development", "dev":
+		return Development, nil
+	case "test":
+		return Test, nil
+	case "staging", "stage":
+		return Staging, nil
+	case "production", "prod":
+		return Production, nil
+	}
+	return "", fmt.Errorf("unknown environment %q, use development, test, staging or production", name)
+}
+
+// Current returns the environment of APP_ENV. An unknown name is treated as unset; LoadConfig of the
+// app rejects it at startup.
+//
+// Returns:
+// - string: The environment, or "" if APP_ENV is unset or unknown.
+func Current() string {
+	env, err := Parse(helper.GetEnv("APP_ENV"))
+	if err != nil {
+		return ""
+	}
+	return env
+}
+
+// Database returns the MongoDB database of MONGO_DATABASE or, if unset, the default of the environment:
+// taskmanager in production and without APP_ENV, taskmanager_<environment> otherwise, so that a
+// development or staging instance pointed at a shared cluster does not touch the production data.
+//
+// Returns:
+// - string: The database name.
+func Database() string {
+	if name := strings.TrimSpace(helper.GetEnv("MONGO_DATABASE")); name != "" {
+		return name
+	}
+	switch env := Current(); env {
+	case "", Production:
+		return DefaultDatabase
+	default:
+		return DefaultDatabase + "_" + env
+	}
+}
+
+// LogLevel returns the LOG_LEVEL value or, if unset, the default of the environment: debug in
+// development and info otherwise.
+//
+// Returns:
+// - string: The level name, for logging.ParseLevel.
+func LogLevel() string {
+	if level := helper.GetEnv("LOG_LEVEL"); level != "" {
+		return level
+	}
+	if Current() == Development {
+		return "debug"
+	}
+	return "info"
+}
+
+// CORSOrigins returns the CORS_ALLOW_ORIGINS value or, if unset, the default of the environment: the
+// usual local frontend dev servers in development and none otherwise.
+//
+// Returns:
+// - string: The comma-separated origins.
+func CORSOrigins() string {
+	if origins := helper.GetEnv("CORS_ALLOW_ORIGINS"); origins != "" {
+		return origins
+	}
+	if Current() == Development {
+		return "http://localhost:3000,http://localhost:5173,http://127.0.0.1:3000,http://127.0.0.1:5173"
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+// environment_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package environment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParse tests parsing environment names and their short forms
+func TestParse(t *testing.T) {
+	for name, want := range map[string]string{"": "", "dev": Development, " Staging ": Staging, "PROD": Production, "test": Test} {
+		env, err := Parse(name)
+		assert.NoError(t, err)
+		assert.Equal(t, want, env)
+	}
+	_, err := Parse("qa")
+	assert.Error(t, err)
+}
+
+// TestDefaults tests the database, log level and CORS defaults of each environment
+func TestDefaults(t *testing.T) {
+	for _, name := range []string{"APP_ENV", "MONGO_DATABASE", "LOG_LEVEL", "CORS_ALLOW_ORIGINS"} {
+		t.Setenv(name, "")
+	}
+
+	// Assert that without APP_ENV the defaults of earlier releases apply
+	assert.Equal(t, DefaultDatabase, Database())
+	assert.Equal(t, "info", LogLevel())
+	assert.Empty(t, CORSOrigins())
+
+	// Assert that development gets its own database, debug logs and the local dev servers as origins
+	t.Setenv("APP_ENV", "dev")
+	assert.Equal(t, "taskmanager_development", Database())
+	assert.Equal(t, "debug", LogLevel())
+	assert.Contains(t, CORSOrigins(), "http://localhost:3000")
+
+	// Assert that production keeps the original database and allows no origins by default
+	t.Setenv("APP_ENV", "production")
+	assert.Equal(t, DefaultDatabase, Database())
+	assert.Equal(t, "info", LogLevel())
+	assert.Empty(t, CORSOrigins())
+
+	// Assert that explicit settings take precedence
+	t.Setenv("APP_ENV", "staging")
+	t.Setenv("MONGO_DATABASE", "tasks_eu")
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("CORS_ALLOW_ORIGINS", "https://app.example.com")
+	assert.Equal(t, "tasks_eu", Database())
+	assert.Equal(t, "warn", LogLevel())
+	assert.Equal(t, "https://app.example.com", CORSOrigins())
+}
@@ -0,0 +1,103 @@
+// events.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Domain event types
+const (
+	TaskCreated     = "task.created"
+	TaskCompleted   = "task.completed"
+	TaskOverdue     = "task.overdue"
+	TaskReassigned  = "task.reassigned"
+	UserRegistered  = "user.registered"
+	UserDeleted     = "user.deleted"
+	UserDeactivated = "user.deactivated"
+	UserReactivated = "user.reactivated"
+	SecurityAnomaly = "security.anomaly"
+)
+
+// Types lists all domain event types, e.g. for the events a webhook can subscribe to.
+var Types = []string{TaskCreated, TaskCompleted, TaskOverdue, TaskReassigned, UserRegistered, UserDeleted, UserDeactivated, UserReactivated, SecurityAnomaly}
+
+// Event is something that happened in the domain, e.g. a task being created.
+type Event struct {
+	ID         string      `json:"id"`          // Unique event ID, for de-duplication by consumers
+	Type       string      `json:"type"`        // Event type, e.g. task.created
+	Subject    string      `json:"subject"`     // ID of the entity the event is about
+	OccurredAt time.Time   `json:"occurred_at"` // When the event happened
+	Data       interface{} `json:"data"`        // Snapshot of the entity
+}
+
+// Handler processes an event. Handlers run synchronously in the publishing request, so anything slow
+// must be handed off, as Forwarder does.
+type Handler func(ctx context.Context, event Event)
+
+// Bus delivers published events to the handlers subscribed to their type.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+// NewBus creates a bus without subscribers.
+func NewBus() *Bus {
+	return &Bus{handlers: map[string][]Handler{}}
+}
+
+// Subscribe registers a handler for an event type; an empty type subscribes to all events.
+func (b *Bus) Subscribe(eventType string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Publish calls the handlers subscribed to the event's type and to all events.
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	b.mu.RLock()
+	handlers := append(append([]Handler{}, b.handlers[event.Type]...), b.handlers[""]...)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(ctx, event)
+	}
+}
+
+// Default is the bus the handlers publish domain events on.
+var Default = NewBus()
+
+// New creates an event with a new ID that occurred now.
+//
+// Parameters:
+// - eventType: The event type, e.g. TaskCreated.
+// - subject: The ID of the entity the event is about.
+// - data: A snapshot of the entity.
+//
+// Returns:
+// - Event: The event.
+func New(eventType, subject string, data interface{}) Event {
+	return Event{
+		ID:         primitive.NewObjectID().Hex(),
+		Type:       eventType,
+		Subject:    subject,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// Publish publishes a new event on the Default bus.
+//
+// Parameters:
+// - ctx: Context of the operation that caused the event.
+// - eventType: The event type, e.g. TaskCreated.
+// - subject: The ID of the entity the event is about.
+// - data: A snapshot of the entity.
+func Publish(ctx context.Context, eventType, subject string, data interface{}) {
+	Default.Publish(ctx, New(eventType, subject, data))
+}
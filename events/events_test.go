@@ -0,0 +1,113 @@
+// events_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBus tests that handlers receive the events of their type and catch-all handlers receive all
+func TestBus(t *testing.T) {
+	bus := NewBus()
+	var created, all []string
+	bus.Subscribe(TaskCreated, func(ctx context.Context, event Event) { created = append(created, event.Subject) })
+	bus.Subscribe("", func(ctx context.Context, event Event) { all = append(all, event.Type) })
+
+	bus.Publish(context.Background(), Event{Type: TaskCreated, Subject: "t1"})
+	bus.Publish(context.Background(), Event{Type: UserRegistered, Subject: "u1"})
+
+	// Assert that each handler saw the expected events
+	assert.Equal(t, []string{"t1"}, created)
+	assert.Equal(t, []string{TaskCreated, UserRegistered}, all)
+}
+
+// TestPublish tests that Publish fills in the event ID and time
+func TestPublish(t *testing.T) {
+	defer func(bus *Bus) { Default = bus }(Default)
+	Default = NewBus()
+
+	var received Event
+	Default.Subscribe(TaskCompleted, func(ctx context.Context, event Event) { received = event })
+	Publish(context.Background(), TaskCompleted, "t1", map[string]string{"title": "Ship"})
+
+	// Assert that the event is complete
+	assert.NotEmpty(t, received.ID)
+	assert.Equal(t, "t1", received.Subject)
+	assert.WithinDuration(t, time.Now(), received.OccurredAt, time.Minute)
+	assert.Equal(t, map[string]string{"title": "Ship"}, received.Data)
+}
+
+// fakePublisher records published events and can be made to fail.
+type fakePublisher struct {
+	mu       sync.Mutex
+	events   []Event
+	attempts int
+	fail     bool
+	closed   bool
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, event Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.attempts++
+	if p.fail {
+		return errors.New("broker down")
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *fakePublisher) Close() error {
+	p.closed = true
+	return nil
+}
+
+// TestForwarder tests that queued events are published in order and flushed on Close
+func TestForwarder(t *testing.T) {
+	publisher := &fakePublisher{}
+	forwarder := NewForwarder(publisher, 10)
+
+	for _, subject := range []string{"a", "b", "c"} {
+		forwarder.Handle(context.Background(), Event{Type: TaskCreated, Subject: subject})
+	}
+	require.NoError(t, forwarder.Close(context.Background()))
+
+	// Assert that all events were published before the publisher was closed
+	require.Len(t, publisher.events, 3)
+	assert.Equal(t, "c", publisher.events[2].Subject)
+	assert.True(t, publisher.closed)
+
+	// Assert that events after Close are ignored
+	forwarder.Handle(context.Background(), Event{Type: TaskCreated})
+	assert.Len(t, publisher.events, 3)
+}
+
+// TestForwarderFailures tests that publishing errors do not stop the forwarder
+func TestForwarderFailures(t *testing.T) {
+	publisher := &fakePublisher{fail: true}
+	forwarder := NewForwarder(publisher, 10)
+	forwarder.Handle(context.Background(), Event{Type: TaskCreated, Subject: "lost"})
+	require.Eventually(t, func() bool {
+		publisher.mu.Lock()
+		defer publisher.mu.Unlock()
+		return publisher.attempts == 1
+	}, time.Second, time.Millisecond)
+
+	publisher.mu.Lock()
+	publisher.fail = false
+	publisher.mu.Unlock()
+	forwarder.Handle(context.Background(), Event{Type: TaskCreated, Subject: "kept"})
+	require.NoError(t, forwarder.Close(context.Background()))
+
+	// Assert that the event after the failure was still published
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, "kept", publisher.events[0].Subject)
+}
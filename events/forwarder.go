@@ -0,0 +1,95 @@
+// forwarder.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Forwarder hands events to an external publisher on a background goroutine, so a slow or
+// unreachable broker never blocks requests. Events that find the queue full are dropped and logged.
+type Forwarder struct {
+	publisher Publisher
+	timeout   time.Duration
+	queue     chan Event
+	done      chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewForwarder starts forwarding to publisher.
+//
+// Parameters:
+// - publisher: The external publisher.
+// - queueSize: The number of events buffered while the publisher is busy.
+//
+// Returns:
+// - *Forwarder: The running forwarder. Subscribe its Handle method to a bus and Close it on shutdown.
+func NewForwarder(publisher Publisher, queueSize int) *Forwarder {
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	f := &Forwarder{
+		publisher: publisher,
+		timeout:   10 * time.Second,
+		queue:     make(chan Event, queueSize),
+		done:      make(chan struct{}),
+	}
+	go f.run()
+	return f
+}
+
+// Handle queues an event for publishing without waiting for the broker.
+func (f *Forwarder) Handle(ctx context.Context, event Event) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.closed {
+		return
+	}
+
+	select {
+	case f.queue <- event:
+	default:
+		log.Printf("Event queue is full, dropped %s event %s", event.Type, event.ID)
+	}
+}
+
+// Close publishes the queued events, waiting at most until ctx ends, and closes the publisher.
+//
+// Parameters:
+// - ctx: Bounds how long to wait for the queue to drain.
+//
+// Returns:
+// - error: ctx.Err() if the queue did not drain in time, or the error closing the publisher.
+func (f *Forwarder) Close(ctx context.Context) error {
+	f.mu.Lock()
+	if !f.closed {
+		f.closed = true
+		close(f.queue)
+	}
+	f.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.publisher.Close()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run publishes queued events until the queue is closed.
+func (f *Forwarder) run() {
+	defer close(f.done)
+	for event := range f.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+		if err := f.publisher.Publish(ctx, event); err != nil {
+			log.Printf("Could not publish %s event %s: %v", event.Type, event.ID, err)
+		}
+		cancel()
+	}
+}
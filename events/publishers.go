@@ -0,0 +1,127 @@
+// publishers.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bkojha74/task-management/helper"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+)
+
+// Publisher sends events to an external broker.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+	Close() error
+}
+
+// LoadPublisher creates the external publisher selected by EVENTS_PUBLISHER: "kafka" (KAFKA_BROKERS,
+// KAFKA_TOPIC) or "nats" (NATS_URL, NATS_SUBJECT_PREFIX). Events stay internal when it is empty.
+//
+// Returns:
+// - Publisher: The configured publisher, or nil when none is configured.
+// - error: An error if the publisher is unknown or cannot be created.
+func LoadPublisher() (Publisher, error) {
+	switch kind := helper.GetEnv("EVENTS_PUBLISHER"); kind {
+	case "":
+		return nil, nil
+	case "kafka":
+		brokers := []string{}
+		for _, broker := range strings.Split(helper.GetEnv("KAFKA_BROKERS"), ",") {
+			if broker = strings.TrimSpace(broker); broker != "" {
+				brokers = append(brokers, broker)
+			}
+		}
+		if len(brokers) == 0 {
+			return nil, fmt.Errorf("KAFKA_BROKERS must be set for the kafka publisher")
+		}
+		topic := helper.GetEnv("KAFKA_TOPIC")
+		if topic == "" {
+			topic = "task-management.events"
+		}
+		return NewKafka(brokers, topic), nil
+	case "nats":
+		url := helper.GetEnv("NATS_URL")
+		if url == "" {
+			url = nats.DefaultURL
+		}
+		prefix := helper.GetEnv("NATS_SUBJECT_PREFIX")
+		if prefix == "" {
+			prefix = "task-management"
+		}
+		return NewNATS(url, prefix)
+	default:
+		return nil, fmt.Errorf("unknown EVENTS_PUBLISHER %q, use kafka or nats", kind)
+	}
+}
+
+// Kafka publishes events as JSON messages to a Kafka topic, keyed by subject so that the events of
+// one entity stay in order.
+type Kafka struct {
+	writer *kafka.Writer
+}
+
+// NewKafka creates a publisher writing to topic on the given brokers.
+func NewKafka(brokers []string, topic string) *Kafka {
+	return &Kafka{writer: &kafka.Writer{
+		Addr:                   kafka.TCP(brokers...),
+		Topic:                  topic,
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: true,
+	}}
+}
+
+// Publish writes the event to the topic, with its type in the "type" header.
+func (k *Kafka) Publish(ctx context.Context, event Event) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return k.writer.WriteMessages(ctx, kafka.Message{
+		Key:     []byte(event.Subject),
+		Value:   value,
+		Headers: []kafka.Header{{Key: "type", Value: []byte(event.Type)}},
+	})
+}
+
+// Close flushes pending messages and closes the writer.
+func (k *Kafka) Close() error {
+	return k.writer.Close()
+}
+
+// NATS publishes events as JSON messages on the subject "<prefix>.<event type>", e.g.
+// task-management.task.created, so consumers can subscribe to "task-management.task.>".
+type NATS struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+// NewNATS connects to the NATS server at url.
+func NewNATS(url, prefix string) (*NATS, error) {
+	conn, err := nats.Connect(url, nats.Name("task-management"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("connecting to NATS: %w", err)
+	}
+	return &NATS{conn: conn, prefix: prefix}, nil
+}
+
+// Publish sends the event to its subject.
+func (n *NATS) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return n.conn.Publish(n.prefix+"."+event.Type, data)
+}
+
+// Close flushes pending messages and closes the connection.
+func (n *NATS) Close() error {
+	return n.conn.Drain()
+}
@@ -0,0 +1,68 @@
+// features.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package features holds the feature flags enabled for the process, which can be changed while it runs.
+package features
+
+import (
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// DuplicateCheck makes POST /tasks reject near-duplicates of open tasks unless the client forces it.
+const DuplicateCheck = "duplicate-check"
+
+// enabled is the set of enabled flags, replaced as a whole by Set.
+var enabled atomic.Pointer[map[string]bool]
+
+// Parse splits a comma-separated list of flag names, e.g. from FEATURE_FLAGS, into sorted unique names.
+//
+// Parameters:
+// - list: The comma-separated flag names.
+//
+// Returns:
+// - []string: The flag names.
+func Parse(list string) []string {
+	flags := []string{}
+	seen := map[string]bool{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" && !seen[name] {
+			seen[name] = true
+			flags = append(flags, name)
+		}
+	}
+	sort.Strings(flags)
+	return flags
+}
+
+// Set replaces the enabled flags.
+//
+// Parameters:
+// - flags: The names of the enabled flags; all others are disabled.
+func Set(flags []string) {
+	set := make(map[string]bool, len(flags))
+	for _, name := range flags {
+		set[strings.ToLower(name)] = true
+	}
+	enabled.Store(&set)
+}
+
+// Enabled reports whether the flag is enabled.
+func Enabled(name string) bool {
+	set := enabled.Load()
+	return set != nil && (*set)[strings.ToLower(name)]
+}
+
+// List returns the names of the enabled flags, sorted.
+func List() []string {
+	flags := []string{}
+	if set := enabled.Load(); set != nil {
+		for name := range *set {
+			flags = append(flags, name)
+		}
+	}
+	sort.Strings(flags)
+	return flags
+}
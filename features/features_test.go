@@ -0,0 +1,30 @@
+// features_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package features
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFeatures tests parsing and switching feature flags
+func TestFeatures(t *testing.T) {
+	defer Set(nil)
+
+	// Assert that the list is normalized, deduplicated and sorted
+	flags := Parse(" Beta-Board, ai-summary,,beta-board ")
+	assert.Equal(t, []string{"ai-summary", "beta-board"}, flags)
+	assert.Empty(t, Parse(""))
+
+	// Assert that only the set flags are enabled and that a new set replaces them
+	assert.False(t, Enabled("beta-board"))
+	Set(flags)
+	assert.True(t, Enabled("Beta-Board"))
+	assert.False(t, Enabled("dark-mode"))
+	assert.Equal(t, flags, List())
+	Set([]string{"dark-mode"})
+	assert.False(t, Enabled("beta-board"))
+	assert.Equal(t, []string{"dark-mode"}, List())
+}
@@ -0,0 +1,171 @@
+// fieldcrypt.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package fieldcrypt encrypts sensitive string fields before they are stored, with AES-256-GCM under a
+// keyring that supports key rotation.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bkojha74/task-management/helper"
+)
+
+// prefix marks encrypted values: "enc:v1:<key id>:<base64 of nonce and ciphertext>".
+const prefix = "enc:v1:"
+
+// Reasons a value cannot be decrypted or a keyring cannot be built
+var (
+	ErrUnknownKey = errors.New("value is encrypted with an unknown key")
+	ErrMalformed  = errors.New("encrypted value is malformed")
+	ErrNoKeys     = errors.New("a keyring needs at least one key")
+)
+
+// Keyring encrypts with its primary key and decrypts with any of its keys, so that a new key can be made
+// primary while values encrypted with the previous keys stay readable until they are re-encrypted.
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+// Key is a named AES-256 key.
+type Key struct {
+	ID     string // Short name stored with every value, e.g. "2024-07"
+	Secret []byte // 32 random bytes
+}
+
+// NewKeyring creates a keyring whose first key is the primary one.
+//
+// Parameters:
+// - keys: The keys, the primary one first.
+//
+// Returns:
+// - *Keyring: The keyring.
+// - error: An error if there is no key, an ID is empty, repeated or contains ':', or a secret is not 32 bytes.
+func NewKeyring(keys ...Key) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	keyring := &Keyring{primary: keys[0].ID, keys: map[string]cipher.AEAD{}}
+	for _, key := range keys {
+		if key.ID == "" || strings.Contains(key.ID, ":") {
+			return nil, fmt.Errorf("invalid key ID %q", key.ID)
+		}
+		if _, ok := keyring.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key ID %q", key.ID)
+		}
+		if len(key.Secret) != 32 {
+			return nil, fmt.Errorf("key %q must be 32 bytes, got %d", key.ID, len(key.Secret))
+		}
+		block, err := aes.NewCipher(key.Secret)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		keyring.keys[key.ID] = aead
+	}
+	return keyring, nil
+}
+
+// LoadKeyring reads the keys from FIELD_ENCRYPTION_KEYS, a comma-separated list of <id>:<base64 secret>
+// with the primary key first, e.g. as injected from a KMS or secret manager. Generate a secret with
+// `openssl rand -base64 32`.
+//
+// Returns:
+// - *Keyring: The keyring, or nil when FIELD_ENCRYPTION_KEYS is not set.
+// - error: An error if an entry is malformed.
+func LoadKeyring() (*Keyring, error) {
+	value := helper.GetEnv("FIELD_ENCRYPTION_KEYS")
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	keys := []Key{}
+	for _, entry := range strings.Split(value, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return nil, fmt.Errorf("FIELD_ENCRYPTION_KEYS entries must be <id>:<base64 secret>")
+		}
+		secret, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q is not valid base64", id)
+		}
+		keys = append(keys, Key{ID: id, Secret: secret})
+	}
+	return NewKeyring(keys...)
+}
+
+// Encrypt encrypts a value with the primary key. Empty values stay empty.
+//
+// Parameters:
+// - plaintext: The value to encrypt.
+//
+// Returns:
+// - string: The encrypted value, tagged with the key ID.
+// - error: An error if no random nonce could be read.
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	aead := k.keys[k.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(k.primary))
+	return prefix + k.primary + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value encrypted by Encrypt with any key of the keyring. Values that are not
+// encrypted, e.g. stored before encryption was enabled, are returned unchanged.
+//
+// Parameters:
+// - value: The stored value.
+//
+// Returns:
+// - string: The plaintext.
+// - error: ErrUnknownKey, ErrMalformed, or an error if the value was tampered with.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok {
+		return "", ErrMalformed
+	}
+	aead, ok := k.keys[id]
+	if !ok {
+		return "", ErrUnknownKey
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("decrypting with key %q: %w", id, err)
+	}
+	return string(plaintext), nil
+}
+
+// IsPrimary reports whether a value is encrypted with the primary key, i.e. needs no re-encryption.
+func (k *Keyring) IsPrimary(value string) bool {
+	return strings.HasPrefix(value, prefix+k.primary+":")
+}
+
+// IsEncrypted reports whether a value was encrypted by a keyring.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
@@ -0,0 +1,63 @@
+// fieldcrypt_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package fieldcrypt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestKeyring tests encryption, decryption across key rotation and tamper detection
+func TestKeyring(t *testing.T) {
+	oldKey := Key{ID: "2024-01", Secret: bytes.Repeat([]byte{1}, 32)}
+	newKey := Key{ID: "2024-07", Secret: bytes.Repeat([]byte{2}, 32)}
+	before, err := NewKeyring(oldKey)
+	require.NoError(t, err)
+	after, err := NewKeyring(newKey, oldKey)
+	require.NoError(t, err)
+
+	// Assert that values round-trip, are tagged with the key and never repeat
+	encrypted, err := before.Encrypt("salary review notes")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encrypted, "enc:v1:2024-01:"))
+	assert.NotContains(t, encrypted, "salary")
+	again, _ := before.Encrypt("salary review notes")
+	assert.NotEqual(t, encrypted, again)
+	plaintext, err := before.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "salary review notes", plaintext)
+
+	// Assert that a rotated keyring reads old values and writes with the new primary key
+	plaintext, err = after.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "salary review notes", plaintext)
+	assert.False(t, after.IsPrimary(encrypted))
+	rotated, _ := after.Encrypt(plaintext)
+	assert.True(t, after.IsPrimary(rotated))
+	_, err = before.Decrypt(rotated)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// Assert that plaintext and empty values pass through, and tampered values are rejected
+	plaintext, err = after.Decrypt("written before encryption")
+	require.NoError(t, err)
+	assert.Equal(t, "written before encryption", plaintext)
+	empty, _ := after.Encrypt("")
+	assert.Empty(t, empty)
+	_, err = after.Decrypt(rotated[:len(rotated)-4] + "AAA=")
+	assert.Error(t, err)
+	_, err = after.Decrypt("enc:v1:2024-07")
+	assert.ErrorIs(t, err, ErrMalformed)
+
+	// Assert that invalid keys are refused
+	_, err = NewKeyring()
+	assert.ErrorIs(t, err, ErrNoKeys)
+	_, err = NewKeyring(Key{ID: "short", Secret: []byte("0123456789")})
+	assert.Error(t, err)
+	_, err = NewKeyring(newKey, newKey)
+	assert.Error(t, err)
+}
@@ -0,0 +1,199 @@
+// focus.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package focus times focus sessions, e.g. pomodoros: periods of work on one task with the interruptions
+// logged during them. It sums the time focused per day and per task for the time-tracking reports.
+package focus
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Limits of a session
+const (
+	DefaultMinutes   = 25  // Planned length of a session when none is given, one pomodoro
+	MaxMinutes       = 240 // Longest planned session, and the most a session counts however late it is stopped
+	MaxInterruptions = 100 // Most interruptions logged in one session
+	MaxNoteLength    = 500 // Longest note of an interruption, in characters
+)
+
+// MaxDays is the longest range of days a summary is computed for.
+const MaxDays = 366
+
+// Errors returned by Start, Interrupt and Summarize
+var (
+	ErrInvalidLength        = errors.New("planned minutes must be between 1 and 240")
+	ErrInvalidInterruption  = errors.New("interruptions last 0 to 240 minutes with a note of at most 500 characters")
+	ErrTooManyInterruptions = errors.New("a session holds at most 100 interruptions")
+	ErrInvalidRange         = errors.New("invalid date range")
+)
+
+// Start returns a running session on a task.
+//
+// Parameters:
+// - userID: The user focusing.
+// - taskID: The task worked on.
+// - plannedMinutes: The length the user sets out to focus for; 0 is DefaultMinutes.
+// - now: The start of the session.
+//
+// Returns:
+// - models.FocusSession: The session, without an ID.
+// - error: ErrInvalidLength if plannedMinutes is out of range.
+func Start(userID, taskID primitive.ObjectID, plannedMinutes int, now time.Time) (models.FocusSession, error) {
+	if plannedMinutes == 0 {
+		plannedMinutes = DefaultMinutes
+	}
+	if plannedMinutes < 0 || plannedMinutes > MaxMinutes {
+		return models.FocusSession{}, ErrInvalidLength
+	}
+	return models.FocusSession{
+		UserID:         userID,
+		TaskID:         taskID,
+		PlannedMinutes: plannedMinutes,
+		StartedAt:      models.NewTimestamp(now),
+		Interruptions:  []models.Interruption{},
+	}, nil
+}
+
+// Interrupt logs an interruption of a running session.
+//
+// Parameters:
+// - session: The session.
+// - minutes: The time lost to the interruption, not counted as focused.
+// - note: What interrupted, may be empty.
+// - now: The time of the interruption.
+//
+// Returns:
+// - error: ErrInvalidInterruption or ErrTooManyInterruptions.
+func Interrupt(session *models.FocusSession, minutes int, note string, now time.Time) error {
+	if minutes < 0 || minutes > MaxMinutes || len([]rune(note)) > MaxNoteLength {
+		return ErrInvalidInterruption
+	}
+	if len(session.Interruptions) >= MaxInterruptions {
+		return ErrTooManyInterruptions
+	}
+	session.Interruptions = append(session.Interruptions, models.Interruption{At: models.NewTimestamp(now), Minutes: minutes, Note: note})
+	return nil
+}
+
+// Stop ends a running session and sets its focused minutes: the time since it started, at most MaxMinutes
+// so that a forgotten session does not count for hours, less the minutes lost to interruptions.
+//
+// Parameters:
+// - session: The session.
+// - now: The end of the session.
+func Stop(session *models.FocusSession, now time.Time) {
+	session.EndedAt = models.NewTimestamp(now)
+	focused := int(now.Sub(session.StartedAt.Time()).Minutes())
+	if focused > MaxMinutes {
+		focused = MaxMinutes
+	}
+	for _, interruption := range session.Interruptions {
+		focused -= interruption.Minutes
+	}
+	if focused < 0 {
+		focused = 0
+	}
+	session.FocusedMinutes = focused
+}
+
+// Day is the focus of one day.
+type Day struct {
+	Date           string `json:"date"` // YYYY-MM-DD in the timezone of the summary
+	FocusedMinutes int    `json:"focused_minutes"`
+	Sessions       int    `json:"sessions"`
+	Interruptions  int    `json:"interruptions"`
+}
+
+// TaskTime is the focus on one task over the days of a summary.
+type TaskTime struct {
+	TaskID         primitive.ObjectID `json:"task_id"`
+	FocusedMinutes int                `json:"focused_minutes"`
+	Sessions       int                `json:"sessions"`
+}
+
+// Summary is the focused time of a range of days.
+type Summary struct {
+	From           string     `json:"from"`
+	To             string     `json:"to"`
+	FocusedMinutes int        `json:"focused_minutes"`
+	Sessions       int        `json:"sessions"`
+	Interruptions  int        `json:"interruptions"`
+	Days           []Day      `json:"days"`  // Every day of the range, oldest first
+	Tasks          []TaskTime `json:"tasks"` // Most focused first
+}
+
+// Summarize sums the ended sessions per day, counted on the day they started, and per task. Running
+// sessions and sessions started outside the range are left out.
+//
+// Parameters:
+// - sessions: The sessions.
+// - from: The first day, in the timezone of the summary.
+// - to: The last day, in the same timezone.
+//
+// Returns:
+// - Summary: The focused time.
+// - error: ErrInvalidRange if to is before from or the range is longer than MaxDays.
+func Summarize(sessions []models.FocusSession, from, to time.Time) (Summary, error) {
+	location := from.Location()
+	first := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, location)
+	last := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, location)
+	if last.Before(first) || last.Sub(first) >= MaxDays*24*time.Hour {
+		return Summary{}, ErrInvalidRange
+	}
+
+	summary := Summary{From: first.Format(time.DateOnly), To: last.Format(time.DateOnly), Days: []Day{}, Tasks: []TaskTime{}}
+	days := map[string]int{}
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		days[day.Format(time.DateOnly)] = len(summary.Days)
+		summary.Days = append(summary.Days, Day{Date: day.Format(time.DateOnly)})
+	}
+	tasks := map[primitive.ObjectID]int{}
+	for _, session := range sessions {
+		i, ok := days[session.StartedAt.Time().In(location).Format(time.DateOnly)]
+		if !ok || session.EndedAt.IsZero() {
+			continue
+		}
+		day := &summary.Days[i]
+		day.FocusedMinutes += session.FocusedMinutes
+		day.Sessions++
+		day.Interruptions += len(session.Interruptions)
+		summary.FocusedMinutes += session.FocusedMinutes
+		summary.Sessions++
+		summary.Interruptions += len(session.Interruptions)
+
+		j, ok := tasks[session.TaskID]
+		if !ok {
+			j = len(summary.Tasks)
+			tasks[session.TaskID] = j
+			summary.Tasks = append(summary.Tasks, TaskTime{TaskID: session.TaskID})
+		}
+		summary.Tasks[j].FocusedMinutes += session.FocusedMinutes
+		summary.Tasks[j].Sessions++
+	}
+	sort.SliceStable(summary.Tasks, func(i, j int) bool { return summary.Tasks[i].FocusedMinutes > summary.Tasks[j].FocusedMinutes })
+	return summary, nil
+}
+
+// ByTask sums the focused minutes of ended sessions per task.
+//
+// Parameters:
+// - sessions: The sessions.
+//
+// Returns:
+// - map[primitive.ObjectID]int: The focused minutes of each task with sessions.
+func ByTask(sessions []models.FocusSession) map[primitive.ObjectID]int {
+	minutes := map[primitive.ObjectID]int{}
+	for _, session := range sessions {
+		if !session.EndedAt.IsZero() {
+			minutes[session.TaskID] += session.FocusedMinutes
+		}
+	}
+	return minutes
+}
@@ -0,0 +1,102 @@
+// focus_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package focus
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestSession tests starting, interrupting and stopping a session
+func TestSession(t *testing.T) {
+	now := time.Date(2024, 7, 10, 9, 0, 0, 0, time.UTC)
+	userID, taskID := primitive.NewObjectID(), primitive.NewObjectID()
+
+	session, err := Start(userID, taskID, 0, now)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultMinutes, session.PlannedMinutes)
+	assert.True(t, session.EndedAt.IsZero())
+	for _, minutes := range []int{-1, MaxMinutes + 1} {
+		_, err := Start(userID, taskID, minutes, now)
+		assert.Equal(t, ErrInvalidLength, err)
+	}
+
+	// Assert that interruptions are taken off the focused time
+	require.NoError(t, Interrupt(&session, 3, "Phone call", now.Add(10*time.Minute)))
+	require.NoError(t, Interrupt(&session, 0, "", now.Add(12*time.Minute)))
+	assert.Equal(t, ErrInvalidInterruption, Interrupt(&session, -1, "", now))
+	assert.Equal(t, ErrInvalidInterruption, Interrupt(&session, 1, strings.Repeat("x", MaxNoteLength+1), now))
+	Stop(&session, now.Add(25*time.Minute+30*time.Second))
+	assert.Equal(t, 22, session.FocusedMinutes)
+	assert.Len(t, session.Interruptions, 2)
+
+	// Assert that a forgotten session counts at most MaxMinutes
+	forgotten, err := Start(userID, taskID, 25, now)
+	require.NoError(t, err)
+	Stop(&forgotten, now.Add(20*time.Hour))
+	assert.Equal(t, MaxMinutes, forgotten.FocusedMinutes)
+
+	// Assert that interruptions never make the focused time negative
+	short, err := Start(userID, taskID, 25, now)
+	require.NoError(t, err)
+	require.NoError(t, Interrupt(&short, 30, "", now))
+	Stop(&short, now.Add(5*time.Minute))
+	assert.Zero(t, short.FocusedMinutes)
+
+	full := short
+	full.Interruptions = make([]models.Interruption, MaxInterruptions)
+	assert.Equal(t, ErrTooManyInterruptions, Interrupt(&full, 1, "", now))
+}
+
+// TestSummarize tests the daily and per-task sums in the timezone of the range
+func TestSummarize(t *testing.T) {
+	location, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+	writing, review := primitive.NewObjectID(), primitive.NewObjectID()
+	session := func(taskID primitive.ObjectID, started time.Time, minutes, interruptions int) models.FocusSession {
+		return models.FocusSession{
+			TaskID:         taskID,
+			StartedAt:      models.NewTimestamp(started),
+			EndedAt:        models.NewTimestamp(started.Add(time.Duration(minutes) * time.Minute)),
+			FocusedMinutes: minutes,
+			Interruptions:  make([]models.Interruption, interruptions),
+		}
+	}
+	running := models.FocusSession{TaskID: review, StartedAt: models.NewTimestamp(time.Date(2024, 7, 11, 6, 0, 0, 0, time.UTC))}
+	sessions := []models.FocusSession{
+		session(writing, time.Date(2024, 7, 9, 20, 0, 0, 0, time.UTC), 25, 1), // July 10th in Kolkata
+		session(review, time.Date(2024, 7, 10, 4, 0, 0, 0, time.UTC), 60, 0),
+		session(writing, time.Date(2024, 7, 11, 5, 0, 0, 0, time.UTC), 25, 2),
+		session(writing, time.Date(2024, 7, 20, 5, 0, 0, 0, time.UTC), 25, 0), // After the range
+		running,
+	}
+
+	summary, err := Summarize(sessions, time.Date(2024, 7, 10, 0, 0, 0, 0, location), time.Date(2024, 7, 12, 0, 0, 0, 0, location))
+	require.NoError(t, err)
+	assert.Equal(t, "2024-07-10", summary.From)
+	assert.Equal(t, "2024-07-12", summary.To)
+	assert.Equal(t, 110, summary.FocusedMinutes)
+	assert.Equal(t, 3, summary.Sessions)
+	assert.Equal(t, 3, summary.Interruptions)
+	assert.Equal(t, []Day{
+		{Date: "2024-07-10", FocusedMinutes: 85, Sessions: 2, Interruptions: 1},
+		{Date: "2024-07-11", FocusedMinutes: 25, Sessions: 1, Interruptions: 2},
+		{Date: "2024-07-12"},
+	}, summary.Days)
+	assert.Equal(t, []TaskTime{{TaskID: review, FocusedMinutes: 60, Sessions: 1}, {TaskID: writing, FocusedMinutes: 50, Sessions: 2}}, summary.Tasks)
+
+	assert.Equal(t, map[primitive.ObjectID]int{writing: 75, review: 60}, ByTask(sessions))
+
+	_, err = Summarize(nil, time.Date(2024, 7, 10, 0, 0, 0, 0, time.UTC), time.Date(2024, 7, 9, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, ErrInvalidRange, err)
+	_, err = Summarize(nil, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, ErrInvalidRange, err)
+}
@@ -0,0 +1,236 @@
+// app.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package githubsync links tasks to GitHub issues: users connect their GitHub account through an OAuth app,
+// create an issue from a task, and the task's status and comments are mirrored to the issue and back by the
+// sync job and GitHub's webhook.
+package githubsync
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/helper"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Default servers of github.com; GitHub Enterprise Server deployments set their own
+const (
+	DefaultWebURL = "https://github.com"
+	DefaultAPIURL = "https://api.github.com"
+)
+
+// SignatureHeader is the header GitHub sends the webhook signature in.
+const SignatureHeader = "X-Hub-Signature-256"
+
+// ErrInvalidState is returned by ParseState for states that are malformed, forged or expired.
+var ErrInvalidState = errors.New("invalid or expired OAuth state")
+
+// App is the GitHub OAuth app users connect their account through.
+type App struct {
+	ClientID      string       // Client ID of the OAuth app
+	ClientSecret  string       // Client secret of the OAuth app; also signs the OAuth states
+	RedirectURL   string       // Public URL of /github/callback, the callback URL of the OAuth app
+	WebhookSecret string       // Secret of the repository webhooks posting to /github/webhook; empty disables them
+	WebURL        string       // GitHub web server; DefaultWebURL when empty
+	APIURL        string       // GitHub API server; DefaultAPIURL when empty
+	Client        *http.Client // HTTP client to use; http.DefaultClient when nil
+}
+
+// LoadApp reads the GITHUB_CLIENT_ID, GITHUB_CLIENT_SECRET, GITHUB_REDIRECT_URL, GITHUB_WEBHOOK_SECRET,
+// GITHUB_URL and GITHUB_API_URL environment variables.
+//
+// Returns:
+// - *App: The OAuth app, or nil if GITHUB_CLIENT_ID is not set.
+// - error: An error if the app is configured incompletely.
+func LoadApp() (*App, error) {
+	clientID := helper.GetEnv("GITHUB_CLIENT_ID")
+	if clientID == "" {
+		return nil, nil
+	}
+	app := &App{
+		ClientID:      clientID,
+		ClientSecret:  helper.GetEnv("GITHUB_CLIENT_SECRET"),
+		RedirectURL:   helper.GetEnv("GITHUB_REDIRECT_URL"),
+		WebhookSecret: helper.GetEnv("GITHUB_WEBHOOK_SECRET"),
+		WebURL:        helper.GetEnv("GITHUB_URL"),
+		APIURL:        helper.GetEnv("GITHUB_API_URL"),
+	}
+	if err := app.Validate(); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// Validate checks that the app has its credentials and an absolute callback URL.
+//
+// Returns:
+// - error: A descriptive error if a setting is missing or invalid.
+func (a *App) Validate() error {
+	if a.ClientID == "" || a.ClientSecret == "" {
+		return errors.New("GITHUB_CLIENT_ID and GITHUB_CLIENT_SECRET are required")
+	}
+	if redirect, err := url.Parse(a.RedirectURL); err != nil || redirect.Scheme == "" || redirect.Host == "" {
+		return errors.New("GITHUB_REDIRECT_URL must be the absolute URL of /github/callback")
+	}
+	if a.WebhookSecret != "" && len(a.WebhookSecret) < 16 {
+		return errors.New("GITHUB_WEBHOOK_SECRET must be at least 16 characters")
+	}
+	return nil
+}
+
+// AuthorizeURL returns the GitHub page asking the user to authorize the app, which then redirects to
+// RedirectURL with a code and the state.
+//
+// Parameters:
+// - state: The state from State, identifying the user.
+//
+// Returns:
+// - string: The URL to send the user to.
+func (a *App) AuthorizeURL(state string) string {
+	query := url.Values{
+		"client_id":    {a.ClientID},
+		"redirect_uri": {a.RedirectURL},
+		"scope":        {"repo"},
+		"state":        {state},
+	}
+	return a.webURL() + "/login/oauth/authorize?" + query.Encode()
+}
+
+// Exchange trades the code GitHub redirected with for an access token.
+//
+// Parameters:
+// - ctx: Context bounding the request.
+// - code: The code from the callback.
+//
+// Returns:
+// - string: The access token.
+// - error: An error if the request failed or GitHub refused the code.
+func (a *App) Exchange(ctx context.Context, code string) (string, error) {
+	form := url.Values{
+		"client_id":     {a.ClientID},
+		"client_secret": {a.ClientSecret},
+		"code":          {code},
+		"redirect_uri":  {a.RedirectURL},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.webURL()+"/login/oauth/access_token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("github token exchange answered %s", resp.Status)
+	}
+	if result.AccessToken == "" {
+		return "", fmt.Errorf("github token exchange failed: %s %s", result.Error, result.ErrorDescription)
+	}
+	return result.AccessToken, nil
+}
+
+// State issues the OAuth state that carries a user through GitHub's authorization page until expiry. It
+// is signed with the client secret, so it needs no storage.
+//
+// Parameters:
+// - userID: The user connecting their account.
+// - expiry: When the state expires.
+//
+// Returns:
+// - string: The state.
+func (a *App) State(userID primitive.ObjectID, expiry time.Time) string {
+	payload := make([]byte, 20)
+	copy(payload, userID[:])
+	binary.BigEndian.PutUint64(payload[12:], uint64(expiry.Unix()))
+	return base64.RawURLEncoding.EncodeToString(append(payload, a.stateMAC(payload)...))
+}
+
+// ParseState returns the user a state issued by State belongs to.
+//
+// Parameters:
+// - state: The state from the callback.
+// - now: The current time.
+//
+// Returns:
+// - primitive.ObjectID: The user connecting their account.
+// - error: ErrInvalidState if the state is malformed, forged or expired.
+func (a *App) ParseState(state string, now time.Time) (primitive.ObjectID, error) {
+	data, err := base64.RawURLEncoding.DecodeString(state)
+	if err != nil || len(data) != 20+sha256.Size {
+		return primitive.NilObjectID, ErrInvalidState
+	}
+	payload, mac := data[:20], data[20:]
+	if !hmac.Equal(mac, a.stateMAC(payload)) {
+		return primitive.NilObjectID, ErrInvalidState
+	}
+	if now.Unix() > int64(binary.BigEndian.Uint64(payload[12:])) {
+		return primitive.NilObjectID, ErrInvalidState
+	}
+	var userID primitive.ObjectID
+	copy(userID[:], payload[:12])
+	return userID, nil
+}
+
+// VerifyWebhook checks the signature GitHub computed over a webhook request body with WebhookSecret.
+//
+// Parameters:
+// - signature: The value of the X-Hub-Signature-256 header.
+// - body: The request body.
+//
+// Returns:
+// - bool: Whether the request comes from GitHub.
+func (a *App) VerifyWebhook(signature string, body []byte) bool {
+	if a.WebhookSecret == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(a.WebhookSecret))
+	mac.Write(body)
+	return hmac.Equal([]byte(signature), []byte("sha256="+hex.EncodeToString(mac.Sum(nil))))
+}
+
+// stateMAC returns the HMAC-SHA256 of a state payload.
+func (a *App) stateMAC(payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(a.ClientSecret))
+	mac.Write([]byte("github-oauth-state:"))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// webURL returns the GitHub web server without a trailing slash.
+func (a *App) webURL() string {
+	if a.WebURL == "" {
+		return DefaultWebURL
+	}
+	return strings.TrimSuffix(a.WebURL, "/")
+}
+
+// client returns the HTTP client to send requests with.
+func (a *App) client() *http.Client {
+	if a.Client == nil {
+		return http.DefaultClient
+	}
+	return a.Client
+}
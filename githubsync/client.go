@@ -0,0 +1,226 @@
+// client.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package githubsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxCommentPages bounds the comments read of one issue, 100 per page.
+const maxCommentPages = 10
+
+// ErrUnauthorized is returned when GitHub rejects a token, e.g. because the user revoked the app.
+var ErrUnauthorized = errors.New("github rejected the access token")
+
+// APIError is an error response of the GitHub API other than 401.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error describes the response.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("github answered %d: %s", e.StatusCode, e.Message)
+}
+
+// User is a GitHub user.
+type User struct {
+	Login string `json:"login"`
+}
+
+// Issue is a GitHub issue.
+type Issue struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	State   string `json:"state"` // open or closed
+	Title   string `json:"title"`
+}
+
+// IssueComment is a comment on a GitHub issue.
+type IssueComment struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	User      User      `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CurrentUser returns the user a token belongs to.
+//
+// Parameters:
+// - ctx: Context bounding the request.
+// - token: An access token.
+//
+// Returns:
+// - *User: The user.
+// - error: ErrUnauthorized, an *APIError or a transport error.
+func (a *App) CurrentUser(ctx context.Context, token string) (*User, error) {
+	var user User
+	if err := a.call(ctx, token, http.MethodGet, "/user", nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreateIssue opens an issue.
+//
+// Parameters:
+// - ctx: Context bounding the request.
+// - token: The access token of the user creating the issue.
+// - repo: The repository, "owner/name".
+// - title: The title of the issue.
+// - body: The Markdown body of the issue.
+//
+// Returns:
+// - *Issue: The new issue.
+// - error: ErrUnauthorized, an *APIError or a transport error.
+func (a *App) CreateIssue(ctx context.Context, token, repo, title, body string) (*Issue, error) {
+	var issue Issue
+	request := map[string]string{"title": title, "body": body}
+	if err := a.call(ctx, token, http.MethodPost, "/repos/"+repo+"/issues", request, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// GetIssue returns an issue.
+//
+// Parameters:
+// - ctx: Context bounding the request.
+// - token: An access token with access to the repository.
+// - repo: The repository, "owner/name".
+// - number: The number of the issue.
+//
+// Returns:
+// - *Issue: The issue.
+// - error: ErrUnauthorized, an *APIError or a transport error.
+func (a *App) GetIssue(ctx context.Context, token, repo string, number int) (*Issue, error) {
+	var issue Issue
+	if err := a.call(ctx, token, http.MethodGet, fmt.Sprintf("/repos/%s/issues/%d", repo, number), nil, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// SetIssueState closes or reopens an issue.
+//
+// Parameters:
+// - ctx: Context bounding the request.
+// - token: An access token with write access to the repository.
+// - repo: The repository, "owner/name".
+// - number: The number of the issue.
+// - state: open or closed.
+//
+// Returns:
+// - error: ErrUnauthorized, an *APIError or a transport error.
+func (a *App) SetIssueState(ctx context.Context, token, repo string, number int, state string) error {
+	return a.call(ctx, token, http.MethodPatch, fmt.Sprintf("/repos/%s/issues/%d", repo, number), map[string]string{"state": state}, nil)
+}
+
+// ListComments returns the comments on an issue, oldest first, up to 1000.
+//
+// Parameters:
+// - ctx: Context bounding the requests.
+// - token: An access token with access to the repository.
+// - repo: The repository, "owner/name".
+// - number: The number of the issue.
+//
+// Returns:
+// - []IssueComment: The comments.
+// - error: ErrUnauthorized, an *APIError or a transport error.
+func (a *App) ListComments(ctx context.Context, token, repo string, number int) ([]IssueComment, error) {
+	comments := []IssueComment{}
+	for page := 1; page <= maxCommentPages; page++ {
+		var batch []IssueComment
+		path := fmt.Sprintf("/repos/%s/issues/%d/comments?per_page=100&page=%d", repo, number, page)
+		if err := a.call(ctx, token, http.MethodGet, path, nil, &batch); err != nil {
+			return nil, err
+		}
+		comments = append(comments, batch...)
+		if len(batch) < 100 {
+			break
+		}
+	}
+	return comments, nil
+}
+
+// CreateComment comments on an issue.
+//
+// Parameters:
+// - ctx: Context bounding the request.
+// - token: The access token of the user commenting.
+// - repo: The repository, "owner/name".
+// - number: The number of the issue.
+// - body: The Markdown body of the comment.
+//
+// Returns:
+// - *IssueComment: The new comment.
+// - error: ErrUnauthorized, an *APIError or a transport error.
+func (a *App) CreateComment(ctx context.Context, token, repo string, number int, body string) (*IssueComment, error) {
+	var comment IssueComment
+	path := fmt.Sprintf("/repos/%s/issues/%d/comments", repo, number)
+	if err := a.call(ctx, token, http.MethodPost, path, map[string]string{"body": body}, &comment); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// call sends a request to the GitHub API and decodes the JSON response into result, unless it is nil.
+func (a *App) call(ctx context.Context, token, method, path string, request, result interface{}) error {
+	var body io.Reader
+	if request != nil {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	apiURL := a.APIURL
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(apiURL, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.client().Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("github %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return ErrUnauthorized
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var failure struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&failure)
+		return &APIError{StatusCode: resp.StatusCode, Message: failure.Message}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
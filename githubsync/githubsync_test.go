@@ -0,0 +1,149 @@
+// githubsync_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package githubsync
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeGitHub serves the issue endpoints of the GitHub API for one repository.
+type fakeGitHub struct {
+	mu       sync.Mutex
+	issues   map[string]*Issue
+	comments []IssueComment
+}
+
+// ServeHTTP implements the issue endpoints the sync uses.
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer gho_token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var body map[string]string
+	json.NewDecoder(r.Body).Decode(&body)
+	path := strings.ToLower(r.URL.Path) // Repository names are case-insensitive
+	switch {
+	case r.Method == http.MethodPost && path == "/repos/acme/api/issues":
+		issue := &Issue{Number: 7, HTMLURL: "https://github.com/acme/api/issues/7", State: "open", Title: body["title"]}
+		f.issues["7"] = issue
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(issue)
+	case path == "/repos/acme/api/issues/7" && f.issues["7"] != nil:
+		if r.Method == http.MethodPatch {
+			f.issues["7"].State = body["state"]
+		}
+		json.NewEncoder(w).Encode(f.issues["7"])
+	case path == "/repos/acme/api/issues/7/comments" && r.Method == http.MethodPost:
+		comment := IssueComment{ID: int64(100 + len(f.comments)), Body: body["body"], User: User{Login: "alice-gh"}, CreatedAt: time.Now()}
+		f.comments = append(f.comments, comment)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(comment)
+	case path == "/repos/acme/api/issues/7/comments":
+		json.NewEncoder(w).Encode(f.comments)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"Not Found"}`))
+	}
+}
+
+// TestState tests that OAuth states identify the user until they expire and cannot be forged
+func TestState(t *testing.T) {
+	app := &App{ClientID: "id", ClientSecret: "secret"}
+	userID := primitive.NewObjectID()
+	now := time.Unix(1720180800, 0)
+	state := app.State(userID, now.Add(10*time.Minute))
+
+	parsed, err := app.ParseState(state, now)
+	require.NoError(t, err)
+	assert.Equal(t, userID, parsed)
+
+	// Assert that expired states and states of another app are rejected
+	_, err = app.ParseState(state, now.Add(11*time.Minute))
+	assert.ErrorIs(t, err, ErrInvalidState)
+	_, err = (&App{ClientID: "id", ClientSecret: "other"}).ParseState(state, now)
+	assert.ErrorIs(t, err, ErrInvalidState)
+	assert.Contains(t, app.AuthorizeURL(state), "https://github.com/login/oauth/authorize?client_id=id")
+}
+
+// TestReconcile tests that status changes and comments are mirrored between a task and its issue
+func TestReconcile(t *testing.T) {
+	repository.UseMemory()
+	ctx := context.Background()
+
+	github := &fakeGitHub{issues: map[string]*Issue{}}
+	server := httptest.NewServer(github)
+	defer server.Close()
+	app := &App{ClientID: "id", ClientSecret: "secret", APIURL: server.URL}
+
+	user := models.User{Username: "alice", GitHub: &models.GitHubAccount{Login: "alice-gh", Token: "gho_token"}}
+	require.NoError(t, repository.Users.Create(ctx, &user))
+	task := models.Task{ID: primitive.NewObjectID(), UserID: user.ID, Title: "Fix login", Status: models.TaskStatusPending, Version: 1}
+	require.NoError(t, repository.Tasks.Create(ctx, &task))
+	comment := models.Comment{TaskID: task.ID, OwnerID: user.ID, AuthorID: user.ID, Author: "alice", Body: "Started on it"}
+	require.NoError(t, repository.Comments.Create(ctx, &comment))
+
+	// Assert that linking creates the issue and copies the existing comment to it
+	task, err := LinkIssue(ctx, app, task, &user, "Acme/API")
+	require.NoError(t, err)
+	require.NotNil(t, task.GitHubIssue)
+	assert.Equal(t, "acme/api", task.GitHubIssue.Repo)
+	assert.Equal(t, 7, task.GitHubIssue.Number)
+	assert.Equal(t, models.GitHubIssueOpen, task.GitHubIssue.State)
+	require.Len(t, github.comments, 1)
+	assert.Equal(t, "**alice** commented on the task:\n\nStarted on it", github.comments[0].Body)
+
+	// Assert that closing the issue and commenting on it completes the task and copies the comment back once
+	github.issues["7"].State = models.GitHubIssueClosed
+	github.comments = append(github.comments, IssueComment{ID: 500, Body: "Fixed in #8", User: User{Login: "bob-gh"}, CreatedAt: time.Now()})
+	require.NoError(t, SyncIssue(ctx, app, "acme/API", 7))
+	require.NoError(t, SyncIssue(ctx, app, "acme/api", 7))
+	stored, err := repository.Tasks.FindByID(ctx, user.ID, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusDone, stored.Status)
+	assert.Equal(t, models.GitHubIssueClosed, stored.GitHubIssue.State)
+	comments, err := repository.Comments.Find(ctx, task.ID)
+	require.NoError(t, err)
+	require.Len(t, comments, 2)
+	assert.Equal(t, "github:bob-gh", comments[1].Author)
+	assert.Equal(t, int64(500), comments[1].GitHubCommentID)
+	assert.Len(t, github.comments, 2)
+
+	// Assert that reopening the task reopens the issue
+	reopened := *stored
+	reopened.Status = models.TaskStatusPending
+	reopened.Version++
+	require.NoError(t, repository.Tasks.Update(ctx, &reopened, stored.Version))
+	synced, err := ReconcileAll(ctx, app)
+	require.NoError(t, err)
+	assert.Equal(t, 1, synced)
+	assert.Equal(t, models.GitHubIssueOpen, github.issues["7"].State)
+
+	// Assert that a disconnected account is recorded as the sync error
+	user.GitHub = nil
+	require.NoError(t, repository.Users.Update(ctx, &user))
+	stored, err = repository.Tasks.FindByID(ctx, user.ID, task.ID)
+	require.NoError(t, err)
+	_, err = Reconcile(ctx, app, *stored)
+	assert.ErrorIs(t, err, ErrNotConnected)
+	stored, err = repository.Tasks.FindByID(ctx, user.ID, task.ID)
+	require.NoError(t, err)
+	assert.Contains(t, stored.GitHubIssue.SyncError, "no connected GitHub account")
+}
@@ -0,0 +1,301 @@
+// sync.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package githubsync
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/markdown"
+	"github.com/bkojha74/task-management/merge"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/pagination"
+	"github.com/bkojha74/task-management/planning"
+	"github.com/bkojha74/task-management/readmodel"
+	"github.com/bkojha74/task-management/repository"
+)
+
+// syncBatchSize is the number of linked tasks read per query of ReconcileAll.
+const syncBatchSize = 100
+
+// CommentAuthorPrefix starts the author of the comments copied from GitHub, e.g. "github:octocat".
+const CommentAuthorPrefix = "github:"
+
+// ErrNotConnected is recorded as the sync error of issues whose linking user disconnected GitHub.
+var ErrNotConnected = errors.New("the user who linked the issue has no connected GitHub account")
+
+// repoPattern matches "owner/name" repository names.
+var repoPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+
+// ValidRepo reports whether repo is a repository name like "owner/name".
+func ValidRepo(repo string) bool {
+	return repoPattern.MatchString(repo)
+}
+
+// LinkIssue creates an issue for a task in a repository with the token of a user and links the task to
+// it, then syncs the task's status and comments to the new issue.
+//
+// Parameters:
+// - ctx: Context for the requests and the database operations.
+// - app: The OAuth app.
+// - task: The task, which must not be linked yet.
+// - user: The user linking the task, with a connected GitHub account.
+// - repo: The repository, "owner/name".
+//
+// Returns:
+// - models.Task: The linked task as stored.
+// - error: ErrNotConnected, a GitHub error, or repository.ErrNotFound if the task changed meanwhile.
+func LinkIssue(ctx context.Context, app *App, task models.Task, user *models.User, repo string) (models.Task, error) {
+	if user.GitHub == nil {
+		return task, ErrNotConnected
+	}
+	body := task.Description
+	if body == "" {
+		body = "_Created from a task._"
+	}
+	issue, err := app.CreateIssue(ctx, user.GitHub.Token, repo, task.Title, body)
+	if err != nil {
+		return task, err
+	}
+
+	linked := task
+	linked.GitHubIssue = &models.GitHubIssue{
+		Repo:     strings.ToLower(repo),
+		Number:   issue.Number,
+		URL:      issue.HTMLURL,
+		LinkedBy: user.ID,
+		State:    issue.State,
+	}
+	if err := store(ctx, &linked, task); err != nil {
+		return task, err
+	}
+	return Reconcile(ctx, app, linked)
+}
+
+// Reconcile brings a linked task and its issue in line. The side whose state changed since the last sync
+// wins: closing the issue marks the task done, reopening it makes the task pending again, and the task
+// closes or reopens the issue the same way. Comments missing on either side are copied to the other, so
+// each comment exists once on the task and once on the issue. Failures are recorded as the sync error of
+// the link.
+//
+// Parameters:
+// - ctx: Context for the requests and the database operations.
+// - app: The OAuth app.
+// - task: The linked task.
+//
+// Returns:
+// - models.Task: The task as stored after the sync.
+// - error: The failure, also when it was recorded.
+func Reconcile(ctx context.Context, app *App, task models.Task) (models.Task, error) {
+	if task.GitHubIssue == nil {
+		return task, nil
+	}
+	synced := task
+	link := *task.GitHubIssue
+	synced.GitHubIssue = &link
+
+	err := reconcile(ctx, app, &synced)
+	if err != nil {
+		link.SyncError = err.Error()
+	} else {
+		link.SyncError = ""
+	}
+	if synced.Status == task.Status && link == *task.GitHubIssue {
+		return task, err
+	}
+	if storeErr := store(ctx, &synced, task); storeErr != nil {
+		return task, storeErr
+	}
+	return synced, err
+}
+
+// ReconcileAll reconciles every linked task. Tasks failing to sync are logged and skipped.
+//
+// Parameters:
+// - ctx: Context for the requests and the database operations.
+// - app: The OAuth app.
+//
+// Returns:
+// - int: The number of tasks synced without error.
+// - error: An error if the tasks could not be read.
+func ReconcileAll(ctx context.Context, app *App) (int, error) {
+	query := repository.TaskQuery{HasGitHubIssue: true, Sort: pagination.Sort{Field: "_id"}, Limit: syncBatchSize}
+
+	synced := 0
+	for {
+		tasks, err := repository.Tasks.Find(ctx, query)
+		if err != nil {
+			return synced, err
+		}
+		if len(tasks) > syncBatchSize {
+			tasks = tasks[:syncBatchSize]
+		}
+
+		for _, task := range tasks {
+			if ctx.Err() != nil {
+				return synced, ctx.Err()
+			}
+			if _, err := Reconcile(ctx, app, task); err != nil {
+				log.Printf("Could not sync task %s with GitHub issue %s#%d: %v", task.ID.Hex(), task.GitHubIssue.Repo, task.GitHubIssue.Number, err)
+				continue
+			}
+			synced++
+		}
+
+		if len(tasks) < syncBatchSize {
+			return synced, nil
+		}
+		query.After = &pagination.Cursor{Sort: query.Sort.String(), ID: tasks[len(tasks)-1].ID}
+	}
+}
+
+// SyncIssue reconciles the tasks linked to an issue, e.g. when GitHub reports a change of it.
+//
+// Parameters:
+// - ctx: Context for the requests and the database operations.
+// - app: The OAuth app.
+// - repo: The repository, "owner/name".
+// - number: The number of the issue.
+//
+// Returns:
+// - error: The first failure; the other tasks are synced regardless.
+func SyncIssue(ctx context.Context, app *App, repo string, number int) error {
+	tasks, err := repository.Tasks.Find(ctx, repository.TaskQuery{GitHubRepo: strings.ToLower(repo), GitHubIssue: number})
+	if err != nil {
+		return err
+	}
+	var first error
+	for _, task := range tasks {
+		if _, err := Reconcile(ctx, app, task); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// reconcile syncs the state and the comments of a task and its issue, updating the task and its link.
+func reconcile(ctx context.Context, app *App, task *models.Task) error {
+	link := task.GitHubIssue
+	user, err := repository.Users.FindByID(ctx, link.LinkedBy)
+	if err == repository.ErrNotFound || (err == nil && user.GitHub == nil) {
+		return ErrNotConnected
+	}
+	if err != nil {
+		return err
+	}
+	token := user.GitHub.Token
+
+	issue, err := app.GetIssue(ctx, token, link.Repo, link.Number)
+	if err != nil {
+		return err
+	}
+	taskState := models.GitHubIssueOpen
+	if task.Status == models.TaskStatusDone {
+		taskState = models.GitHubIssueClosed
+	}
+	switch {
+	case taskState != link.State:
+		if issue.State != taskState {
+			if err := app.SetIssueState(ctx, token, link.Repo, link.Number, taskState); err != nil {
+				return err
+			}
+		}
+		link.State = taskState
+	case issue.State != link.State:
+		previous := *task
+		task.Status = models.TaskStatusPending
+		if issue.State == models.GitHubIssueClosed {
+			task.Status = models.TaskStatusDone
+		}
+		planning.TrackEffort(task, &previous, time.Now())
+		planning.TrackOverdue(task, &previous, time.Now())
+		link.State = issue.State
+	}
+
+	return syncComments(ctx, app, token, *task)
+}
+
+// syncComments copies the comments of a task missing on its issue to the issue, and those of the issue
+// missing on the task to the task.
+func syncComments(ctx context.Context, app *App, token string, task models.Task) error {
+	link := task.GitHubIssue
+	comments, err := repository.Comments.Find(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	mirrored := map[int64]bool{}
+	for _, comment := range comments {
+		if comment.GitHubCommentID != 0 {
+			mirrored[comment.GitHubCommentID] = true
+		}
+	}
+
+	for _, comment := range comments {
+		if comment.GitHubCommentID != 0 {
+			continue
+		}
+		copied, err := app.CreateComment(ctx, token, link.Repo, link.Number, "**"+comment.Author+"** commented on the task:\n\n"+comment.Body)
+		if err != nil {
+			return err
+		}
+		if err := repository.Comments.SetGitHubComment(ctx, comment.ID, copied.ID); err != nil {
+			return err
+		}
+		mirrored[copied.ID] = true
+	}
+
+	issueComments, err := app.ListComments(ctx, token, link.Repo, link.Number)
+	if err != nil {
+		return err
+	}
+	for _, issueComment := range issueComments {
+		if mirrored[issueComment.ID] {
+			continue
+		}
+		comment := models.Comment{
+			TaskID:          task.ID,
+			OwnerID:         task.UserID,
+			Author:          CommentAuthorPrefix + issueComment.User.Login,
+			Body:            issueComment.Body,
+			BodyHTML:        markdown.Render(issueComment.Body),
+			Mentions:        []string{},
+			CreatedAt:       models.NewTimestamp(issueComment.CreatedAt),
+			GitHubCommentID: issueComment.ID,
+		}
+		if err := repository.Comments.Create(ctx, &comment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// store writes a synced task over its previous version, with the task.completed event when the sync
+// completed it, and refreshes the cache and the list view.
+func store(ctx context.Context, task *models.Task, previous models.Task) error {
+	task.Version = previous.Version + 1
+	merge.Track(task, &previous)
+	err := outbox.Transaction(ctx, func(ctx context.Context) error {
+		if err := repository.Tasks.Update(ctx, task, previous.Version); err != nil {
+			return err
+		}
+		if task.Status == models.TaskStatusDone && previous.Status != models.TaskStatusDone {
+			return outbox.Publish(ctx, events.TaskCompleted, task.ID.Hex(), *task)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	cache.InvalidateTask(task.UserID.Hex(), task.ID.Hex())
+	readmodel.SyncOrLog(ctx, *task)
+	return nil
+}
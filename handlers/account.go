@@ -0,0 +1,117 @@
+// account.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// accountDeletion is the response of DeleteAccount.
+type accountDeletion struct {
+	DeleteAt models.Timestamp `json:"delete_at"`
+}
+
+// accountExport is the archive of a user's data returned by ExportAccount.
+type accountExport struct {
+	ExportedAt models.Timestamp `json:"exported_at"`
+	User       models.User      `json:"user"`
+	Tasks      []models.Task    `json:"tasks"`
+	Projects   []models.Project `json:"projects"`
+}
+
+// DeleteAccount schedules the deletion of the logged-in user's account. The account keeps working during the
+// grace period and the deletion can be cancelled with CancelAccountDeletion; afterwards the account deletion
+// job purges the user with all the tasks and projects they own. Deleting an account that is already scheduled
+// keeps its original date.
+//
+// Parameters:
+// - grace: How long the account is kept before it is purged.
+//
+// Returns:
+// - fiber.Handler: A Fiber handler function that schedules the deletion.
+func DeleteAccount(grace time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, err := currentUser(c)
+		if err != nil {
+			return err
+		}
+
+		if user.DeleteAt.IsZero() {
+			user.DeleteAt = models.NewTimestamp(time.Now().Add(grace))
+			if err := repository.Users.Update(context.Background(), user); err != nil {
+				return apierror.Internal(apierror.CodeInternal, "Could not schedule account deletion")
+			}
+		}
+
+		return response.JSON(c, fiber.StatusAccepted, accountDeletion{DeleteAt: user.DeleteAt})
+	}
+}
+
+// CancelAccountDeletion keeps the logged-in user's account that was scheduled for deletion.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func CancelAccountDeletion(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	if !user.DeleteAt.IsZero() {
+		user.DeleteAt = 0
+		if err := repository.Users.Update(context.Background(), user); err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Could not cancel account deletion")
+		}
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ExportAccount returns all the data of the logged-in user as one JSON archive: the account without its
+// password hash, and every task and project they own. "format=trello" or "format=todoist" exports only the
+// tasks instead, as a file the other task manager imports, see package interchange.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func ExportAccount(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	if format := c.Query("format", "json"); format != "json" {
+		return exportTasks(c, user, format)
+	}
+
+	tasks, err := repository.Tasks.Find(c.UserContext(), repository.TaskQuery{UserID: user.ID})
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+	}
+	projects, err := repository.Projects.Find(c.UserContext(), user.ID)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching projects")
+	}
+
+	user.Password = "" // Never hand out password hashes
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="account-export.json"`)
+	return response.JSON(c, fiber.StatusOK, accountExport{
+		ExportedAt: models.NewTimestamp(time.Now()),
+		User:       *user,
+		Tasks:      tasks,
+		Projects:   projects,
+	})
+}
@@ -0,0 +1,108 @@
+// assignment.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"log"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/assignment"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SetAssignmentRule sets the rule picking the assignee of the tasks created in a project of the logged-in
+// user without one: "round_robin" gives them to the members in turn, "least_loaded" to the member with the
+// fewest open tasks. Every member must be an existing user. Setting a rule again starts its turns over.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func SetAssignmentRule(c *fiber.Ctx) error {
+	project, err := findOwnProject(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var rule models.AssignmentRule
+	if err := c.BodyParser(&rule); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	if err := assignment.Validate(rule); err != nil {
+		return apierror.BadRequest(apierror.CodeValidationFailed, err.Error())
+	}
+	for _, member := range rule.Members {
+		if _, err := repository.Users.FindByUsername(c.UserContext(), member); err != nil {
+			if err == repository.ErrNotFound {
+				return apierror.BadRequest(apierror.CodeValidationFailed, "Member does not exist").
+					WithDetails(fiber.Map{"member": member})
+			}
+			return apierror.Internal(apierror.CodeInternal, "Error checking member")
+		}
+	}
+
+	rule.Next = 0
+	if err := repository.Projects.SetAssignment(c.UserContext(), project.OwnerID, project.ID, &rule); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not set assignment rule")
+	}
+	project.Assignment = &rule
+	return response.JSON(c, fiber.StatusOK, project)
+}
+
+// DeleteAssignmentRule removes the assignment rule of a project of the logged-in user, so that tasks created
+// in it without an assignee are no longer assigned automatically.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func DeleteAssignmentRule(c *fiber.Ctx) error {
+	project, err := findOwnProject(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+	if err := repository.Projects.SetAssignment(c.UserContext(), project.OwnerID, project.ID, nil); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not remove assignment rule")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// autoAssign sets the assignee of a new task without one from the assignment rule of its project, if the
+// project has one. Unknown projects are left for validatePlanning to reject. The round-robin turn it
+// returns must be passed to releaseTurn unless the task is stored.
+func autoAssign(c *fiber.Ctx, task *models.Task) (assignment.Turn, error) {
+	if task.AllottedTo != "" || task.ProjectID == primitive.NilObjectID {
+		return assignment.Turn{}, nil
+	}
+	project, err := findOwnProject(c, task.ProjectID.Hex())
+	if err != nil {
+		if apiErr, ok := err.(*apierror.Error); ok && apiErr.Status == fiber.StatusNotFound {
+			return assignment.Turn{}, nil
+		}
+		return assignment.Turn{}, err
+	}
+
+	assignee, turn, err := assignment.Pick(c.UserContext(), *project)
+	if err != nil {
+		return assignment.Turn{}, apierror.Internal(apierror.CodeInternal, "Could not pick an assignee")
+	}
+	task.AllottedTo = assignee
+	return turn, nil
+}
+
+// releaseTurn gives back the round-robin turn of a task that was not stored. Failures are only logged;
+// they cost the member a turn.
+func releaseTurn(turn assignment.Turn) {
+	if err := turn.Release(context.Background()); err != nil {
+		log.Printf("Could not give back an assignment turn: %v", err)
+	}
+}
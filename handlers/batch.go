@@ -0,0 +1,444 @@
+// batch.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/audit"
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/i18n"
+	"github.com/bkojha74/task-management/merge"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/permissions"
+	"github.com/bkojha74/task-management/planning"
+	"github.com/bkojha74/task-management/readmodel"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxBatchIDs is the most tasks BatchGetTasks fetches at once.
+const maxBatchIDs = 100
+
+// maxBulkPatch is the most tasks PatchTasks changes at once.
+const maxBulkPatch = 1000
+
+// batchGetRequest is the body of BatchGetTasks.
+type batchGetRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// transitionRequest is the body of TransitionTasks.
+type transitionRequest struct {
+	IDs    []string `json:"ids"`
+	Status string   `json:"status"`  // Target status
+	DryRun bool     `json:"dry_run"` // Only report what would happen
+}
+
+// Outcomes of the transition of one task
+const (
+	transitionApplied  = "applied"
+	transitionValid    = "valid" // Would be applied, in a dry run
+	transitionRejected = "rejected"
+)
+
+// transitionResult is the outcome of the transition of one task.
+type transitionResult struct {
+	ID     string          `json:"id"`
+	Status string          `json:"status"`         // applied, valid or rejected
+	From   string          `json:"from,omitempty"` // Status of the task before the transition
+	Task   *models.Task    `json:"task,omitempty"` // The transitioned task
+	Error  *apierror.Error `json:"error,omitempty"`
+}
+
+// bulkPatchRejection is a task PatchTasks matched but did not update.
+type bulkPatchRejection struct {
+	ID    string          `json:"id"`
+	Error *apierror.Error `json:"error"`
+}
+
+// batchGetResult is the outcome for one requested ID.
+type batchGetResult struct {
+	ID    string      `json:"id"`
+	Found bool        `json:"found"`
+	Task  interface{} `json:"task,omitempty"`
+}
+
+// BatchGetTasks fetches several tasks of the logged-in user in one query, e.g. for dashboards that
+// would otherwise call GetTask for each of them. The results are in the order of the requested IDs
+// and tell for each one whether it was found. Like GetTasks, it accepts "fields".
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func BatchGetTasks(c *fiber.Ctx) error {
+	userId := c.Locals("userId").(string)
+	userIdHex, _ := primitive.ObjectIDFromHex(userId)
+
+	var request batchGetRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	if len(request.IDs) == 0 || len(request.IDs) > maxBatchIDs {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "ids must list between 1 and 100 task IDs")
+	}
+
+	fields, err := parseFields(c, taskFields)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(request.IDs))
+	invalid := []string{}
+	for _, id := range request.IDs {
+		taskId, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			invalid = append(invalid, id)
+			continue
+		}
+		ids = append(ids, taskId)
+	}
+	if len(invalid) > 0 {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID").WithDetails(fiber.Map{"invalid": invalid})
+	}
+
+	query := repository.TaskQuery{UserID: userIdHex, IDs: ids}
+	permissions.RestrictQuery(subjectOf(c), &query)
+	if fields != nil {
+		query.Fields = append([]string{"project_id"}, fields...)
+	}
+	tasks, err := repository.Tasks.Find(c.UserContext(), query)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+	}
+
+	found := map[primitive.ObjectID]interface{}{}
+	for _, task := range tasks {
+		selected, err := selectFields(withTaskLinks(c, task), fields)
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Could not select fields")
+		}
+		found[task.ID] = selected
+	}
+
+	results := make([]batchGetResult, len(ids))
+	for i, id := range ids {
+		task, ok := found[id]
+		results[i] = batchGetResult{ID: id.Hex(), Found: ok, Task: task}
+	}
+	response.AddMeta(c, "count", len(tasks))
+	return response.JSON(c, fiber.StatusOK, fiber.Map{"results": results})
+}
+
+// TransitionTasks moves up to 100 tasks of the logged-in user to a target status. Each task is checked
+// against the workflow rules (see planning.CheckTransition) and moved on its own, so that one task breaking
+// a rule or changed concurrently does not hold back the others; the report gives the outcome of each in
+// the order of the IDs, with the reason of each rejection. With "dry_run" the tasks are only checked.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func TransitionTasks(c *fiber.Ctx) error {
+	var request transitionRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	if len(request.IDs) == 0 || len(request.IDs) > maxBatchIDs {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "ids must list between 1 and 100 task IDs")
+	}
+	if err := planning.CheckTransition("", request.Status); err == planning.ErrUnknownStatus {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "status must be Pending, In Progress or Done")
+	}
+
+	languages := i18n.Languages(c.Get(fiber.HeaderAcceptLanguage))
+	results := make([]transitionResult, len(request.IDs))
+	counts := map[string]int{transitionApplied: 0, transitionValid: 0, transitionRejected: 0}
+	for i, id := range request.IDs {
+		results[i] = transitionTask(c, id, request.Status, request.DryRun)
+		if apiErr := results[i].Error; apiErr != nil {
+			localized := *apiErr
+			localized.Message, _ = i18n.Translate(apiErr.Message, languages)
+			results[i].Error = &localized
+		}
+		counts[results[i].Status]++
+	}
+	return response.JSON(c, fiber.StatusOK, fiber.Map{
+		"results":  results,
+		"applied":  counts[transitionApplied],
+		"valid":    counts[transitionValid],
+		"rejected": counts[transitionRejected],
+	})
+}
+
+// transitionTask moves one task to a status, or only checks that it could be moved in a dry run.
+func transitionTask(c *fiber.Ctx, id, status string, dryRun bool) transitionResult {
+	result := transitionResult{ID: id}
+	reject := func(err error) transitionResult {
+		apiErr, ok := err.(*apierror.Error)
+		if !ok {
+			apiErr = apierror.Internal(apierror.CodeInternal, "internal server error")
+		}
+		result.Status, result.Error = transitionRejected, apiErr
+		return result
+	}
+
+	taskId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return reject(apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID"))
+	}
+	previous, err := findTask(c, taskId, true)
+	if err != nil {
+		return reject(err)
+	}
+	result.From = previous.Status
+
+	switch planning.CheckTransition(previous.Status, status) {
+	case nil:
+	case planning.ErrSameStatus:
+		return reject(apierror.Conflict(apierror.CodeConflict, "Task already has this status"))
+	default:
+		return reject(apierror.New(fiber.StatusUnprocessableEntity, apierror.CodeUnprocessable,
+			"The workflow does not allow this status change").
+			WithDetails(fiber.Map{"from": previous.Status, "to": status}))
+	}
+	if dryRun {
+		result.Status = transitionValid
+		return result
+	}
+
+	task := *previous
+	task.Status = status
+	planning.TrackEffort(&task, previous, time.Now())
+	planning.TrackOverdue(&task, previous, time.Now())
+	task.Version = previous.Version + 1
+	merge.Track(&task, previous)
+
+	err = outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := repository.Tasks.Update(ctx, &task, previous.Version); err != nil {
+			return err
+		}
+		if task.Status == models.TaskStatusDone {
+			return outbox.Publish(ctx, events.TaskCompleted, task.ID.Hex(), task)
+		}
+		return nil
+	})
+	if err == repository.ErrNotFound {
+		return reject(apierror.Conflict(apierror.CodeVersionConflict, "Task was modified by someone else, reload it and retry"))
+	}
+	if err != nil {
+		return reject(apierror.Internal(apierror.CodeInternal, "Could not update task"))
+	}
+
+	cache.InvalidateTask(task.UserID.Hex(), task.ID.Hex())
+	readmodel.SyncOrLog(context.Background(), task)
+	result.Status, result.Task = transitionApplied, &task
+	return result
+}
+
+// PatchTasks applies one partial update, in the format of PatchTask, to every task of the logged-in user
+// matching the "filter" query parameter, e.g. to hand all open tasks of a departing user to someone else
+// with "filter=allotted_to:alice,status:open" and {"allotted_to": "bob"}. The filter is a comma-separated
+// list of conditions that must all hold:
+//   - status:<status>, several separated by "|", or status:open for any status but Done;
+//   - allotted_to:<username>;
+//   - project_id:<id>;
+//   - overdue:true or overdue:false;
+//   - due_before:<RFC3339 time>.
+//
+// Archived tasks are left alone, and at most 1000 tasks are patched at once. Each task is merged and written
+// on its own like PatchTask does, so that a task changed concurrently does not hold back the others; the
+// response gives the number of matched and updated tasks and the reason of each rejection. With
+// "dry_run=true" the matching tasks are only counted. The filter, the patched fields and the counts are
+// recorded in the audit log through repository.AuditLog, whatever the audit sink: the entry is stored before
+// the first task is written, and the request fails if it cannot be.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func PatchTasks(c *fiber.Ctx) error {
+	userIdHex, _ := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+	filter := c.Query("filter")
+	if filter == "" {
+		return apierror.BadRequest(apierror.CodeInvalidQuery, "filter is required, e.g. filter=status:open")
+	}
+	query, err := parseTaskFilter(filter)
+	if err != nil {
+		return err
+	}
+	archived := false
+	query.UserID, query.Archived = userIdHex, &archived
+
+	if _, err := localizeRequest(c, "start_time", "end_time"); err != nil {
+		return err
+	}
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(c.Body(), &patch); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	if len(patch) == 0 {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Patch must change at least one field")
+	}
+	// Reject unknown fields and invalid values once rather than for every task
+	if _, _, err := merge.Apply(models.Task{}, patch, 0); err != nil {
+		if fieldErr, ok := err.(*merge.FieldError); ok {
+			return apierror.BadRequest(apierror.CodeValidationFailed, "Field cannot be patched").
+				WithDetails(fiber.Map{"field": fieldErr.Field})
+		}
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+
+	matched, err := repository.Tasks.Count(c.UserContext(), query)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error counting tasks")
+	}
+	if matched > maxBulkPatch {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "The filter matches more than 1000 tasks, narrow it down").
+			WithDetails(fiber.Map{"matched": matched})
+	}
+	dryRun := c.QueryBool("dry_run")
+	if dryRun {
+		return response.JSON(c, fiber.StatusOK, fiber.Map{"matched": matched, "updated": 0, "rejected": []bulkPatchRejection{}})
+	}
+
+	tasks, err := repository.Tasks.Find(c.UserContext(), query)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+	}
+
+	// The entry is recorded before any task changes, so that no bulk update goes unrecorded
+	fields := make([]string, 0, len(patch))
+	for field := range patch {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	start := time.Now()
+	entry := audit.Entry{
+		Time:      models.NewTimestamp(start),
+		RequestID: c.GetRespHeader(fiber.HeaderXRequestID),
+		Method:    c.Method(),
+		Path:      c.Path(),
+		UserID:    userIdHex.Hex(),
+		IP:        c.IP(),
+		Details:   fiber.Map{"filter": filter, "fields": fields, "matched": len(tasks), "updated": 0, "rejected": 0},
+	}
+	if err := repository.AuditLog.Create(c.UserContext(), &entry); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not record the update in the audit log")
+	}
+
+	languages := i18n.Languages(c.Get(fiber.HeaderAcceptLanguage))
+	updated, rejected := 0, []bulkPatchRejection{}
+	for i := range tasks {
+		if err := patchMatchedTask(c, &tasks[i], patch); err != nil {
+			apiErr, ok := err.(*apierror.Error)
+			if !ok {
+				apiErr = apierror.Internal(apierror.CodeInternal, "internal server error")
+			}
+			localized := *apiErr
+			localized.Message, _ = i18n.Translate(apiErr.Message, languages)
+			rejected = append(rejected, bulkPatchRejection{ID: tasks[i].ID.Hex(), Error: &localized})
+			continue
+		}
+		updated++
+	}
+
+	entry.Status, entry.DurationMs = fiber.StatusOK, time.Since(start).Milliseconds()
+	entry.Details = fiber.Map{"filter": filter, "fields": fields, "matched": len(tasks), "updated": updated, "rejected": len(rejected)}
+	if err := repository.AuditLog.Update(context.Background(), &entry); err != nil {
+		log.Printf("Could not record the outcome of bulk update %s: %v", entry.ID.Hex(), err)
+	}
+	return response.JSON(c, fiber.StatusOK, fiber.Map{"matched": len(tasks), "updated": updated, "rejected": rejected})
+}
+
+// patchMatchedTask merges a patch into a task matched by PatchTasks and writes it, merging again when the
+// task is written concurrently.
+func patchMatchedTask(c *fiber.Ctx, previous *models.Task, patch map[string]json.RawMessage) error {
+	for attempt := 1; ; attempt++ {
+		task, _, err := patchTask(c, previous, patch, 0)
+		if err == repository.ErrNotFound && attempt < maxPatchAttempts {
+			if previous, err = findTask(c, previous.ID, true); err != nil {
+				return err
+			}
+			continue
+		}
+		if err == repository.ErrNotFound {
+			return apierror.Conflict(apierror.CodeVersionConflict, "Task was modified by someone else, reload it and retry")
+		}
+		if err != nil {
+			return err
+		}
+
+		cache.InvalidateTask(task.UserID.Hex(), task.ID.Hex())
+		readmodel.SyncOrLog(context.Background(), *task)
+		return nil
+	}
+}
+
+// parseTaskFilter reads the filter of PatchTasks into a task query.
+func parseTaskFilter(filter string) (repository.TaskQuery, error) {
+	var query repository.TaskQuery
+	invalid := func(message string) (repository.TaskQuery, error) {
+		return query, apierror.BadRequest(apierror.CodeInvalidQuery, message).WithDetails(fiber.Map{"filter": filter})
+	}
+	for _, condition := range strings.Split(filter, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(condition), ":")
+		value = strings.TrimSpace(value)
+		if !ok || value == "" {
+			return invalid("Invalid filter condition \"" + condition + "\", use name:value")
+		}
+		switch name {
+		case "status":
+			if value == "open" {
+				query.ExcludeStatus = models.TaskStatusDone
+				continue
+			}
+			for _, status := range strings.Split(value, "|") {
+				if planning.CheckTransition("", status) == planning.ErrUnknownStatus {
+					return invalid("Filter status must be Pending, In Progress, Done or open")
+				}
+				query.Statuses = append(query.Statuses, status)
+			}
+		case "allotted_to":
+			query.AllottedTo = value
+		case "project_id":
+			projectId, err := primitive.ObjectIDFromHex(value)
+			if err != nil {
+				return invalid("Invalid project ID in filter")
+			}
+			query.ProjectID = projectId
+		case "overdue":
+			overdue, err := strconv.ParseBool(value)
+			if err != nil {
+				return invalid("Invalid overdue flag in filter, use true or false")
+			}
+			query.Overdue = &overdue
+		case "due_before":
+			dueBefore, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return invalid("Invalid due_before in filter, use RFC3339 e.g. 2024-07-05T00:00:00Z")
+			}
+			query.DueBefore, query.HasDueDate = dueBefore, true
+		default:
+			return invalid("Unknown filter field \"" + name + "\", use status, allotted_to, project_id, overdue or due_before")
+		}
+	}
+	return query, nil
+}
@@ -0,0 +1,103 @@
+// benchmark_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// benchmarkTasks is the size of the task list of a heavy user.
+const benchmarkTasks = 2000
+
+// fixedTasks serves a prepared task list, so that the benchmarks measure the handler rather than the
+// in-memory storage; the storage is measured against MongoDB with taskctl loadtest.
+type fixedTasks struct {
+	repository.TaskRepository
+	entries []models.TaskListEntry
+}
+
+// FindEntries returns the prepared list, cut to one page plus one task like the repositories do.
+func (r fixedTasks) FindEntries(ctx context.Context, query repository.TaskQuery) ([]models.TaskListEntry, error) {
+	n := len(r.entries)
+	if query.Limit > 0 && query.Limit+1 < n {
+		n = query.Limit + 1
+	}
+	return r.entries[:n:n], nil
+}
+
+// useBenchmarkTasks serves benchmarkTasks tasks of a heavy user until the benchmark ends and returns
+// the user's token.
+func useBenchmarkTasks(b *testing.B) string {
+	user := models.User{ID: primitive.NewObjectID(), Username: "bench"}
+	now := time.Now()
+	entries := make([]models.TaskListEntry, benchmarkTasks)
+	for i := range entries {
+		entries[i] = models.TaskListEntry{
+			Task: models.Task{
+				ID:          primitive.NewObjectID(),
+				UserID:      user.ID,
+				Title:       fmt.Sprintf("Task %04d", i),
+				Description: "Prepare the quarterly report and share it with the team before the review meeting.",
+				AllottedTo:  user.Username,
+				Status:      models.TaskStatusPending,
+				Priority:    models.TaskPriorityMedium,
+				StartDate:   models.NewTimestamp(now),
+				EndDate:     models.NewTimestamp(now.Add(48 * time.Hour)),
+				Version:     1,
+				CreatedAt:   models.NewTimestamp(now),
+				UpdatedAt:   models.NewTimestamp(now),
+			},
+			Assignee:     &models.UserSummary{ID: user.ID, Username: user.Username},
+			CommentCount: i % 3,
+		}
+	}
+
+	tasks := repository.Tasks
+	repository.Tasks = fixedTasks{TaskRepository: tasks, entries: entries}
+	b.Cleanup(func() { repository.Tasks = tasks })
+	return mintToken(b, user)
+}
+
+// benchmarkGet runs GET requests of path and reports the response size.
+func benchmarkGet(b *testing.B, path, token string) {
+	b.ReportAllocs()
+	var size int64
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", token)
+		resp, err := testApp.Test(req, -1)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			b.Fatalf("status %d", resp.StatusCode)
+		}
+		size, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	b.ReportMetric(float64(size), "resp-bytes")
+}
+
+// BenchmarkGetTasks measures GET /tasks of a heavy user: the whole list, one page, selected fields and
+// the envelope format. Run with: go test ./handlers -run '^$' -bench GetTasks -benchmem
+func BenchmarkGetTasks(b *testing.B) {
+	token := useBenchmarkTasks(b)
+
+	b.Run("all", func(b *testing.B) { benchmarkGet(b, "/tasks", token) })
+	b.Run("page", func(b *testing.B) { benchmarkGet(b, "/tasks?limit=50", token) })
+	b.Run("fields", func(b *testing.B) { benchmarkGet(b, "/tasks?fields=title,status,assignee,comment_count", token) })
+	b.Run("envelope", func(b *testing.B) { benchmarkGet(b, "/tasks?limit=50&envelope=true", token) })
+}
@@ -0,0 +1,86 @@
+// billing.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/billing"
+	"github.com/bkojha74/task-management/features"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// workspacePlan is the response of GetWorkspacePlan.
+type workspacePlan struct {
+	billing.Plan
+	Subscription *models.Subscription `json:"subscription,omitempty"`
+}
+
+// StripeWebhook returns the handler of the events Stripe posts about checkouts and subscriptions, which
+// move workspaces between the free and pro plans. Events for subscriptions not linked to a workspace yet
+// are answered with 409 so that Stripe retries them.
+//
+// Parameters:
+// - stripe: The Stripe account whose webhook signing secret the events are verified with.
+//
+// Returns:
+// - fiber.Handler: The webhook handler.
+func StripeWebhook(stripe *billing.Stripe) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := stripe.VerifySignature(c.Get(billing.SignatureHeader), c.Body(), time.Now()); err != nil {
+			return apierror.Unauthorized(apierror.CodeInvalidToken, "invalid webhook signature")
+		}
+
+		plan, err := stripe.HandleEvent(context.Background(), c.Body())
+		if errors.Is(err, billing.ErrUnknownWorkspace) {
+			return apierror.Conflict(apierror.CodeConflict, "Subscription is not linked to a workspace yet")
+		}
+		if err != nil {
+			log.Printf("Could not handle Stripe event: %v", err)
+			return apierror.Internal(apierror.CodeInternal, "Could not handle event")
+		}
+		if plan != "" {
+			log.Printf("Billing: Stripe event moved a workspace to the %s plan", plan)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// GetWorkspacePlan returns the billing plan of a workspace the logged-in user owns or belongs to, with its
+// quotas, features and subscription.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetWorkspacePlan(c *fiber.Ctx) error {
+	workspace, err := findMemberWorkspace(c)
+	if err != nil {
+		return err
+	}
+
+	plan, subscription, err := billing.PlanOf(c.UserContext(), workspace.ID)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching plan")
+	}
+	return response.JSON(c, fiber.StatusOK, workspacePlan{Plan: plan, Subscription: subscription})
+}
+
+// featureEnabled reports whether a feature flag is enabled for the logged-in user, for everyone or by the
+// plan of their workspace.
+func featureEnabled(c *fiber.Ctx, name string) bool {
+	if features.Enabled(name) {
+		return true
+	}
+	user, err := currentUser(c)
+	return err == nil && billing.FeatureEnabled(c.UserContext(), *user, name)
+}
@@ -0,0 +1,191 @@
+// boards.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/merge"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/permissions"
+	"github.com/bkojha74/task-management/planning"
+	"github.com/bkojha74/task-management/readmodel"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// moveRequest is the body of MoveCard.
+type moveRequest struct {
+	TaskID   string `json:"task_id"`
+	Status   string `json:"status"`    // Target column, the card's current status when empty
+	AfterID  string `json:"after_id"`  // Card to place the moved card below
+	BeforeID string `json:"before_id"` // Card to place the moved card above
+}
+
+// GetBoard returns the kanban board of a project: its tasks grouped into status columns in workflow
+// order, with the cards of each column in their board order.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetBoard(c *fiber.Ctx) error {
+	project, err := findOwnProject(c, c.Params("projectId"))
+	if err != nil {
+		return err
+	}
+
+	query := repository.TaskQuery{UserID: project.OwnerID, ProjectID: project.ID}
+	permissions.RestrictQuery(subjectOf(c), &query)
+	tasks, err := repository.Tasks.Find(c.UserContext(), query)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+	}
+
+	return response.JSON(c, fiber.StatusOK, fiber.Map{"project_id": project.ID, "columns": planning.BuildBoard(tasks)})
+}
+
+// MoveCard moves a card within its column or to another status column, between the cards after_id and
+// before_id (either may be omitted; with neither the card goes to the bottom). The position is stored as
+// the task's rank, so only the moved card is rewritten unless the column has to be re-ranked. Moving a card
+// to another column changes the task's status like an update does. Returns the updated board.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func MoveCard(c *fiber.Ctx) error {
+	project, err := findOwnProject(c, c.Params("projectId"))
+	if err != nil {
+		return err
+	}
+
+	var move moveRequest
+	if err := c.BodyParser(&move); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	cardID, err := primitive.ObjectIDFromHex(move.TaskID)
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID")
+	}
+	afterID, err := optionalObjectID(move.AfterID)
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid after_id")
+	}
+	beforeID, err := optionalObjectID(move.BeforeID)
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid before_id")
+	}
+
+	tasks, err := repository.Tasks.Find(c.UserContext(), repository.TaskQuery{UserID: project.OwnerID, ProjectID: project.ID})
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+	}
+	byID := map[primitive.ObjectID]models.Task{}
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+	card, ok := byID[cardID]
+	if !ok {
+		return apierror.NotFound(apierror.CodeNotFound, "Task not found on this board")
+	}
+
+	status := move.Status
+	if status == "" {
+		status = card.Status
+	}
+	known := false
+	for _, s := range models.TaskStatuses {
+		known = known || s == status
+	}
+	if !known {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Unknown status column")
+	}
+
+	column := []models.Task{}
+	for _, task := range tasks {
+		if task.Status == status && task.ID != cardID {
+			column = append(column, task)
+		}
+	}
+	planning.SortCards(column)
+
+	ranks, err := planning.PlaceCard(column, cardID, afterID, beforeID)
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "after_id and before_id must be adjacent cards of the target column")
+	}
+
+	// Re-ranked cards first, so that the moved card is only written when its neighbours are in place
+	for id, rank := range ranks {
+		if id == cardID {
+			continue
+		}
+		task := byID[id]
+		task.Rank = rank
+		if err := writeCard(&task, byID[id]); err != nil {
+			return err
+		}
+		byID[id] = task
+	}
+
+	card.Rank = ranks[cardID]
+	card.Status = status
+	if err := writeCard(&card, byID[cardID]); err != nil {
+		return err
+	}
+	byID[cardID] = card
+
+	updated := make([]models.Task, 0, len(byID))
+	for _, task := range byID {
+		updated = append(updated, task)
+	}
+	return response.JSON(c, fiber.StatusOK, fiber.Map{"project_id": project.ID, "columns": planning.BuildBoard(updated)})
+}
+
+// writeCard stores a card moved from previous with a new version, and keeps caches, the read model and
+// completion events in step like UpdateTask does.
+func writeCard(task *models.Task, previous models.Task) error {
+	planning.TrackEffort(task, &previous, time.Now())
+	planning.TrackOverdue(task, &previous, time.Now())
+	task.Version = previous.Version + 1
+	merge.Track(task, &previous)
+
+	err := outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := repository.Tasks.Update(ctx, task, previous.Version); err != nil {
+			return err
+		}
+		if task.Status == models.TaskStatusDone && previous.Status != models.TaskStatusDone {
+			return outbox.Publish(ctx, events.TaskCompleted, task.ID.Hex(), *task)
+		}
+		return nil
+	})
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return apierror.Conflict(apierror.CodeVersionConflict, "The board was modified by someone else, reload it and retry")
+		}
+		return apierror.Internal(apierror.CodeInternal, "Could not move task")
+	}
+
+	cache.InvalidateTask(task.UserID.Hex(), task.ID.Hex())
+	readmodel.SyncOrLog(context.Background(), *task)
+	return nil
+}
+
+// optionalObjectID parses a hex ObjectID, returning the zero ID for an empty value.
+func optionalObjectID(value string) (primitive.ObjectID, error) {
+	if value == "" {
+		return primitive.NilObjectID, nil
+	}
+	return primitive.ObjectIDFromHex(value)
+}
@@ -0,0 +1,474 @@
+// caldav.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/caldav"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/markdown"
+	"github.com/bkojha74/task-management/merge"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/planning"
+	"github.com/bkojha74/task-management/readmodel"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CalDAVPrefix is the path the CalDAV endpoints are served under. Each user has a principal at
+// /caldav/principals/<username>/ and a calendar home at /caldav/calendars/<username>/ holding one
+// collection, tasks/, with a <name>.ics resource per task the user owns.
+const CalDAVPrefix = "/caldav"
+
+// CalDAVOptions advertises the WebDAV and CalDAV features of the endpoints.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func CalDAVOptions(c *fiber.Ctx) error {
+	c.Set("DAV", "1, 3, calendar-access")
+	c.Set(fiber.HeaderAllow, "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT")
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// CalDAVDiscovery redirects the well-known CalDAV URL to the endpoints, where clients find the principal
+// of the user.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func CalDAVDiscovery(c *fiber.Ctx) error {
+	return c.Redirect(CalDAVPrefix+"/", fiber.StatusMovedPermanently)
+}
+
+// CalDAVPropfind answers PROPFIND requests for the properties of the CalDAV resources of the logged-in
+// user: the root, their principal, their calendar home, the tasks collection and the tasks in it. With
+// "Depth: 1" the members of a collection are included.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func CalDAVPropfind(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	request, err := caldav.ParseRequest(c.Body())
+	if err != nil || request.Kind != caldav.KindPropfind {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Body must be a WebDAV propfind document")
+	}
+	members := c.Get("Depth", "infinity") != "0"
+
+	parts := strings.Split(strings.Trim(c.Params("*"), "/"), "/")
+	if len(parts) > 1 && parts[1] != user.Username {
+		return apierror.Forbidden(apierror.CodeForbidden, "CalDAV clients can only sync their own tasks")
+	}
+	resources := []caldav.Resource{}
+	switch {
+	case len(parts) == 1 && parts[0] == "":
+		resources = append(resources, caldavRoot(user))
+	case len(parts) == 2 && parts[0] == "principals":
+		resources = append(resources, caldavPrincipal(user))
+	case len(parts) == 2 && parts[0] == "calendars":
+		resources = append(resources, caldavHome(user))
+		if members {
+			tasks, err := caldavTasks(c.UserContext(), user)
+			if err != nil {
+				return err
+			}
+			resources = append(resources, caldavCollection(user, tasks))
+		}
+	case len(parts) == 3 && parts[0] == "calendars" && parts[2] == "tasks":
+		tasks, err := caldavTasks(c.UserContext(), user)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, caldavCollection(user, tasks))
+		if members {
+			for _, task := range tasks {
+				resources = append(resources, caldavObject(user, task))
+			}
+		}
+	case len(parts) == 4 && parts[0] == "calendars" && parts[2] == "tasks":
+		task, err := findCalDAVTask(c.UserContext(), user, parts[3])
+		if err != nil {
+			return err
+		}
+		if task == nil {
+			return apierror.NotFound(apierror.CodeNotFound, "Task not found")
+		}
+		resources = append(resources, caldavObject(user, *task))
+	default:
+		return apierror.NotFound(apierror.CodeNotFound, "Resource not found")
+	}
+	return sendMultistatus(c, resources, request)
+}
+
+// CalDAVReport answers the calendar-query and calendar-multiget reports on the tasks collection, which
+// clients use to fetch the ETags and iCalendar data of many tasks at once. A calendar-query returns all
+// tasks when it filters on VTODOs, and none for other components.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func CalDAVReport(c *fiber.Ctx) error {
+	user, err := ownCalDAVCollection(c)
+	if err != nil {
+		return err
+	}
+	request, err := caldav.ParseRequest(c.Body())
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Body must be a CalDAV report document")
+	}
+	if request.Kind != caldav.KindCalendarQuery && request.Kind != caldav.KindCalendarMultiget {
+		return apierror.Forbidden(apierror.CodeForbidden, "Only the calendar-query and calendar-multiget reports are supported")
+	}
+
+	tasks, err := caldavTasks(c.UserContext(), user)
+	if err != nil {
+		return err
+	}
+	resources := []caldav.Resource{}
+	if request.Kind == caldav.KindCalendarQuery {
+		if request.Component == "" || request.Component == "VTODO" {
+			for _, task := range tasks {
+				resources = append(resources, caldavObject(user, task))
+			}
+		}
+		return sendMultistatus(c, resources, request)
+	}
+
+	byName := map[string]models.Task{}
+	for _, task := range tasks {
+		byName[caldav.Name(task)] = task
+	}
+	for _, href := range request.Hrefs {
+		name, _ := url.PathUnescape(strings.TrimSuffix(path.Base(href), ".ics"))
+		if task, ok := byName[name]; ok {
+			resources = append(resources, caldavObject(user, task))
+		} else {
+			resources = append(resources, caldav.Resource{Href: href, Missing: true})
+		}
+	}
+	return sendMultistatus(c, resources, request)
+}
+
+// GetCalDAVTask returns a task of the logged-in user as an iCalendar VTODO.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetCalDAVTask(c *fiber.Ctx) error {
+	user, err := ownCalDAVCollection(c)
+	if err != nil {
+		return err
+	}
+	task, err := findCalDAVTask(c.UserContext(), user, c.Params("name"))
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return apierror.NotFound(apierror.CodeNotFound, "Task not found")
+	}
+
+	c.Set(fiber.HeaderETag, versionTag(task.Version))
+	c.Set(fiber.HeaderContentType, caldav.ContentType)
+	return c.Status(fiber.StatusOK).SendString(caldav.Encode(caldav.FromTask(*task)))
+}
+
+// PutCalDAVTask creates or replaces a task from the VTODO a client sent. New tasks are allotted to the
+// user and keep the resource name and UID the client chose; replacing a task sets its title, description,
+// status, priority and due date. "If-Match" and "If-None-Match: *" are honored with 412 Precondition
+// Failed, and the response carries the ETag of the stored task.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func PutCalDAVTask(c *fiber.Ctx) error {
+	user, err := ownCalDAVCollection(c)
+	if err != nil {
+		return err
+	}
+	todo, err := caldav.Parse(c.Body(), userLocation(user))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Body must be an iCalendar object with a VTODO")
+	}
+	if todo.Summary == "" {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Title is required")
+	}
+	name := strings.TrimSuffix(c.Params("name"), ".ics")
+	previous, err := findCalDAVTask(c.UserContext(), user, name)
+	if err != nil {
+		return err
+	}
+	if err := checkCalDAVPreconditions(c, previous); err != nil {
+		return err
+	}
+
+	if previous == nil {
+		task := models.Task{AllottedTo: user.Username}
+		initTask(&task, user.ID)
+		caldav.Apply(todo, &task)
+		if todo.UID == "" {
+			todo.UID = name
+		}
+		task.CalDAV = &models.CalDAVObject{Name: name, UID: todo.UID}
+		task.DescriptionHTML = markdown.Render(task.Description)
+		if err := normalizeVisibility(&task, nil); err != nil {
+			return err
+		}
+		planning.TrackEffort(&task, nil, time.Now())
+		planning.TrackOverdue(&task, nil, time.Now())
+		if err := storeNewTask(&task); err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderETag, versionTag(task.Version))
+		return c.SendStatus(fiber.StatusCreated)
+	}
+
+	task := *previous
+	caldav.Apply(todo, &task)
+	task.DescriptionHTML = markdown.Render(task.Description)
+	planning.TrackEffort(&task, previous, time.Now())
+	planning.TrackOverdue(&task, previous, time.Now())
+	task.Version = previous.Version + 1
+	merge.Track(&task, previous)
+
+	err = outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := repository.Tasks.Update(ctx, &task, previous.Version); err != nil {
+			return err
+		}
+		if task.Status == models.TaskStatusDone && previous.Status != models.TaskStatusDone {
+			return outbox.Publish(ctx, events.TaskCompleted, task.ID.Hex(), task)
+		}
+		return nil
+	})
+	if err == repository.ErrNotFound {
+		return caldavPreconditionFailed()
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not update task")
+	}
+	cache.InvalidateTask(task.UserID.Hex(), task.ID.Hex())
+	readmodel.SyncOrLog(context.Background(), task)
+
+	c.Set(fiber.HeaderETag, versionTag(task.Version))
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// DeleteCalDAVTask deletes a task of the logged-in user with its comments, like DeleteTask. "If-Match" is
+// honored with 412 Precondition Failed.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func DeleteCalDAVTask(c *fiber.Ctx) error {
+	user, err := ownCalDAVCollection(c)
+	if err != nil {
+		return err
+	}
+	task, err := findCalDAVTask(c.UserContext(), user, c.Params("name"))
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return apierror.NotFound(apierror.CodeNotFound, "Task not found")
+	}
+	if err := checkCalDAVPreconditions(c, task); err != nil {
+		return err
+	}
+
+	err = outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := repository.Tasks.Trash(ctx, user.ID, task.ID); err != nil {
+			return err
+		}
+		return repository.Tombstones.Create(ctx, &models.Tombstone{TaskID: task.ID, UserID: user.ID})
+	})
+	if err == repository.ErrNotFound {
+		return apierror.NotFound(apierror.CodeNotFound, "Task not found")
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not delete task")
+	}
+
+	cache.InvalidateTask(user.ID.Hex(), task.ID.Hex())
+	readmodel.RemoveOrLog(context.Background(), task.ID)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ownCalDAVCollection returns the logged-in user if the request is for their tasks collection.
+func ownCalDAVCollection(c *fiber.Ctx) (*models.User, error) {
+	user, err := currentUser(c)
+	if err != nil {
+		return nil, err
+	}
+	if c.Params("username") != user.Username {
+		return nil, apierror.Forbidden(apierror.CodeForbidden, "CalDAV clients can only sync their own tasks")
+	}
+	return user, nil
+}
+
+// findCalDAVTask returns the task of a user served under a resource name, or nil if there is none.
+func findCalDAVTask(ctx context.Context, user *models.User, name string) (*models.Task, error) {
+	name = strings.TrimSuffix(name, ".ics")
+	tasks, err := repository.Tasks.Find(ctx, repository.TaskQuery{UserID: user.ID, CalDAVName: name})
+	if err != nil {
+		return nil, apierror.Internal(apierror.CodeInternal, "Error fetching task")
+	}
+	if len(tasks) > 0 {
+		return &tasks[0], nil
+	}
+
+	// Tasks not created by a CalDAV client are served under their ID
+	taskId, err := primitive.ObjectIDFromHex(name)
+	if err != nil {
+		return nil, nil
+	}
+	task, err := repository.Tasks.FindByID(ctx, user.ID, taskId)
+	if err == repository.ErrNotFound || (err == nil && task.CalDAV != nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apierror.Internal(apierror.CodeInternal, "Error fetching task")
+	}
+	return task, nil
+}
+
+// caldavTasks returns the tasks the user owns, which make up their tasks collection.
+func caldavTasks(ctx context.Context, user *models.User) ([]models.Task, error) {
+	tasks, err := repository.Tasks.Find(ctx, repository.TaskQuery{UserID: user.ID})
+	if err != nil {
+		return nil, apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+	}
+	return tasks, nil
+}
+
+// checkCalDAVPreconditions checks the If-Match and If-None-Match headers of a write against the task
+// currently stored under the resource name, nil if none is.
+func checkCalDAVPreconditions(c *fiber.Ctx, task *models.Task) error {
+	if c.Get(fiber.HeaderIfNoneMatch) == "*" && task != nil {
+		return caldavPreconditionFailed()
+	}
+	ifMatch := c.Get(fiber.HeaderIfMatch)
+	if ifMatch == "" {
+		return nil
+	}
+	if task == nil {
+		return caldavPreconditionFailed()
+	}
+	if ifMatch != "*" {
+		if version, err := parseVersionTag(ifMatch); err != nil || version != task.Version {
+			return caldavPreconditionFailed()
+		}
+	}
+	return nil
+}
+
+// caldavPreconditionFailed is the error of writes whose If-Match or If-None-Match header does not match.
+func caldavPreconditionFailed() error {
+	return apierror.New(fiber.StatusPreconditionFailed, apierror.CodeVersionConflict, "Task was modified by someone else, reload it and retry")
+}
+
+// sendMultistatus answers with a 207 Multi-Status document.
+func sendMultistatus(c *fiber.Ctx, resources []caldav.Resource, request caldav.Request) error {
+	c.Set(fiber.HeaderContentType, "application/xml; charset=utf-8")
+	return c.Status(fiber.StatusMultiStatus).Send(caldav.Multistatus(resources, request))
+}
+
+// caldavPrincipalPath returns the path of the principal of a user.
+func caldavPrincipalPath(user *models.User) string {
+	return CalDAVPrefix + "/principals/" + url.PathEscape(user.Username) + "/"
+}
+
+// caldavHomePath returns the path of the calendar home of a user.
+func caldavHomePath(user *models.User) string {
+	return CalDAVPrefix + "/calendars/" + url.PathEscape(user.Username) + "/"
+}
+
+// caldavRoot describes the root of the endpoints, where clients look up the principal of the user.
+func caldavRoot(user *models.User) caldav.Resource {
+	return caldav.Resource{Href: CalDAVPrefix + "/", Props: caldavProps(user, caldav.Empty(caldav.NamespaceDAV, "collection"))}
+}
+
+// caldavPrincipal describes the principal of a user, which points to their calendar home.
+func caldavPrincipal(user *models.User) caldav.Resource {
+	props := caldavProps(user, caldav.Empty(caldav.NamespaceDAV, "principal"))
+	props[caldav.PropDisplayName] = caldav.Text(user.Username)
+	props[caldav.PropPrincipalURL] = caldav.Href(caldavPrincipalPath(user))
+	props[caldav.PropCalendarHomeSet] = caldav.Href(caldavHomePath(user))
+	return caldav.Resource{Href: caldavPrincipalPath(user), Props: props}
+}
+
+// caldavHome describes the calendar home of a user.
+func caldavHome(user *models.User) caldav.Resource {
+	return caldav.Resource{Href: caldavHomePath(user), Props: caldavProps(user, caldav.Empty(caldav.NamespaceDAV, "collection"))}
+}
+
+// caldavCollection describes the tasks collection of a user. Its CTag changes whenever a task is added,
+// changed or deleted, so clients know when to fetch the ETags of the tasks again.
+func caldavCollection(user *models.User, tasks []models.Task) caldav.Resource {
+	state := sha256.New()
+	for _, task := range tasks {
+		state.Write([]byte(task.ID.Hex() + ":" + strconv.Itoa(task.Version) + ";"))
+	}
+
+	props := caldavProps(user, caldav.Empty(caldav.NamespaceDAV, "collection")+caldav.Empty(caldav.NamespaceCalDAV, "calendar"))
+	props[caldav.PropDisplayName] = "Tasks"
+	props[caldav.PropSupportedComponents] = `<c:comp name="VTODO"/>`
+	props[caldav.PropCTag] = hex.EncodeToString(state.Sum(nil)[:16])
+	props[caldav.PropSupportedReports] = "<d:supported-report><d:report>" + caldav.Empty(caldav.NamespaceCalDAV, "calendar-query") + "</d:report></d:supported-report>" +
+		"<d:supported-report><d:report>" + caldav.Empty(caldav.NamespaceCalDAV, "calendar-multiget") + "</d:report></d:supported-report>"
+	return caldav.Resource{Href: caldavHomePath(user) + "tasks/", Props: props}
+}
+
+// caldavObject describes a task resource with its iCalendar data.
+func caldavObject(user *models.User, task models.Task) caldav.Resource {
+	props := caldavProps(user, "")
+	props[caldav.PropETag] = caldav.Text(versionTag(task.Version))
+	props[caldav.PropContentType] = caldav.ContentType
+	props[caldav.PropCalendarData] = caldav.Text(caldav.Encode(caldav.FromTask(task)))
+	return caldav.Resource{Href: caldavHomePath(user) + "tasks/" + url.PathEscape(caldav.Name(task)) + ".ics", Props: props}
+}
+
+// caldavProps returns the properties all resources of a user share, with a resource type.
+func caldavProps(user *models.User, resourceType string) map[xml.Name]string {
+	privileges := ""
+	for _, privilege := range []string{"read", "write", "write-content", "bind", "unbind"} {
+		privileges += "<d:privilege>" + caldav.Empty(caldav.NamespaceDAV, privilege) + "</d:privilege>"
+	}
+	return map[xml.Name]string{
+		caldav.PropResourceType:          resourceType,
+		caldav.PropCurrentUserPrincipal:  caldav.Href(caldavPrincipalPath(user)),
+		caldav.PropOwner:                 caldav.Href(caldavPrincipalPath(user)),
+		caldav.PropCurrentUserPrivileges: privileges,
+	}
+}
@@ -0,0 +1,158 @@
+// comments.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/markdown"
+	"github.com/bkojha74/task-management/mentions"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/notifications"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/permissions"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxCommentBody is the longest comment, in characters.
+const maxCommentBody = 10000
+
+// commentRequest is the body of AddComment.
+type commentRequest struct {
+	Body string `json:"body"` // Markdown text, which may @mention users
+}
+
+// AddComment adds a comment to a task anyone who may read the task can comment on. The users it
+// @mentions are validated: mentions of unknown users, of users who may not read the task and of the
+// author are dropped, and the rest are stored with the comment and sent a task.mentioned notification.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func AddComment(c *fiber.Ctx) error {
+	taskIdHex, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID")
+	}
+
+	var request commentRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	request.Body = strings.TrimSpace(request.Body)
+	if request.Body == "" {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "body is required")
+	}
+	if len([]rune(request.Body)) > maxCommentBody {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "body must be at most 10000 characters")
+	}
+
+	task, err := findTask(c, taskIdHex, false)
+	if err != nil {
+		return err
+	}
+	subject := subjectOf(c)
+	author, err := repository.Users.FindByID(c.UserContext(), subject.UserID)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching user")
+	}
+	mentioned, err := mentionedReaders(c.UserContext(), *task, request.Body, author.Username)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error checking mentioned users")
+	}
+
+	comment := models.Comment{
+		TaskID:    task.ID,
+		OwnerID:   task.UserID,
+		AuthorID:  author.ID,
+		Author:    author.Username,
+		Body:      request.Body,
+		BodyHTML:  markdown.Render(request.Body),
+		Mentions:  mentioned,
+		CreatedAt: models.NewTimestamp(time.Now()),
+	}
+	err = outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := repository.Comments.Create(ctx, &comment); err != nil {
+			return err
+		}
+		for _, username := range mentioned {
+			err := outbox.Notify(ctx, notifications.Notification{
+				Event:     notifications.EventTaskMentioned,
+				Recipient: username,
+				TaskID:    task.ID.Hex(),
+				Subject:   "Mentioned on: " + task.Title,
+				Message:   author.Username + " mentioned you on the task \"" + task.Title + "\":\n\n" + comment.Body,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not add comment")
+	}
+
+	cache.InvalidateTask(task.UserID.Hex(), task.ID.Hex()) // The owner's task lists show the comment count
+	return response.JSON(c, fiber.StatusCreated, comment)
+}
+
+// GetComments lists the comments on a task, oldest first.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetComments(c *fiber.Ctx) error {
+	taskIdHex, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID")
+	}
+	if _, err := findTask(c, taskIdHex, false); err != nil {
+		return err
+	}
+
+	comments, err := repository.Comments.Find(c.UserContext(), taskIdHex)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching comments")
+	}
+	return response.JSON(c, fiber.StatusOK, comments)
+}
+
+// mentionedReaders returns the users mentioned in a comment who exist and may read the task, except the
+// author, who need not be told about their own comment.
+func mentionedReaders(ctx context.Context, task models.Task, body, author string) ([]string, error) {
+	readers := []string{}
+	for _, username := range mentions.Parse(body) {
+		if username == author {
+			continue
+		}
+		user, err := repository.Users.FindByUsername(ctx, username)
+		if err == repository.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		readable, err := permissions.CanRead(ctx, permissions.Subject{UserID: user.ID}, task)
+		if err != nil {
+			return nil, err
+		}
+		if readable {
+			readers = append(readers, username)
+		}
+	}
+	return readers, nil
+}
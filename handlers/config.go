@@ -0,0 +1,24 @@
+// config.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetConfig returns the settings in effect, including the reloadable ones as last reloaded, so that
+// operators can verify a configuration change was picked up. Secrets are not included.
+//
+// Parameters:
+// - effective: Returns the settings in effect.
+//
+// Returns:
+// - fiber.Handler: The handler serving the settings.
+func GetConfig(effective func() interface{}) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return response.JSON(c, fiber.StatusOK, effective())
+	}
+}
@@ -0,0 +1,153 @@
+// deadletters.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/deadletter"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ListDeadLetters returns the dead letters, optionally filtered by the "kind" and "status" query parameters.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func ListDeadLetters(c *fiber.Ctx) error {
+	deadLetters, err := deadletter.List(c.UserContext(), c.Query("kind"), c.Query("status"))
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching dead letters")
+	}
+
+	return response.JSON(c, fiber.StatusOK, deadLetters)
+}
+
+// GetDeadLetter returns a single dead letter, including its payload snapshot and last error.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetDeadLetter(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid dead letter ID")
+	}
+
+	deadLetter, err := deadletter.Find(c.UserContext(), id)
+	if err != nil {
+		return deadLetterError(c, err)
+	}
+
+	return response.JSON(c, fiber.StatusOK, deadLetter)
+}
+
+// UpdateDeadLetter replaces the payload of a pending dead letter so it can be fixed before a replay.
+// The request body must be of the form {"payload": {...}}.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func UpdateDeadLetter(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid dead letter ID")
+	}
+
+	var body struct {
+		Payload map[string]interface{} `json:"payload"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.Payload == nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+
+	deadLetter, err := deadletter.UpdatePayload(context.Background(), id, body.Payload)
+	if err != nil {
+		return deadLetterError(c, err)
+	}
+
+	return response.JSON(c, fiber.StatusOK, deadLetter)
+}
+
+// ReplayDeadLetter re-executes a pending dead letter. If the replay fails again the dead letter stays
+// pending with the new error, and a 502 Bad Gateway error is returned with the dead letter in its details.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func ReplayDeadLetter(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid dead letter ID")
+	}
+
+	deadLetter, err := deadletter.Replay(context.Background(), id)
+	if err != nil {
+		if deadLetter != nil {
+			return apierror.New(fiber.StatusBadGateway, apierror.CodeBadGateway, "Replay failed: "+err.Error()).WithDetails(fiber.Map{"dead_letter": deadLetter})
+		}
+		return deadLetterError(c, err)
+	}
+
+	return response.JSON(c, fiber.StatusOK, deadLetter)
+}
+
+// DiscardDeadLetter marks a pending dead letter as discarded so that it is never replayed. The request
+// body may give the reason, {"reason": "..."}.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func DiscardDeadLetter(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid dead letter ID")
+	}
+
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+		}
+	}
+
+	deadLetter, err := deadletter.Discard(context.Background(), id, strings.TrimSpace(body.Reason))
+	if err != nil {
+		return deadLetterError(c, err)
+	}
+
+	return response.JSON(c, fiber.StatusOK, deadLetter)
+}
+
+// deadLetterError maps dead letter package errors to HTTP responses.
+func deadLetterError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, deadletter.ErrNotFound):
+		return apierror.NotFound(apierror.CodeNotFound, "Dead letter not found")
+	case errors.Is(err, deadletter.ErrNotPending):
+		return apierror.Conflict(apierror.CodeConflict, "Dead letter is not pending")
+	case errors.Is(err, deadletter.ErrNoReplayer):
+		return apierror.New(fiber.StatusUnprocessableEntity, apierror.CodeUnprocessable, err.Error())
+	default:
+		return apierror.Internal(apierror.CodeInternal, "Error processing dead letter")
+	}
+}
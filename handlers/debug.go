@@ -0,0 +1,81 @@
+// debug.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/bkojha74/task-management/buildinfo"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// startedAt is when the process started serving, reported as its uptime.
+var startedAt = time.Now()
+
+// runtimeInfo describes the running build and the state of the Go runtime.
+type runtimeInfo struct {
+	buildinfo.Info
+	StartedAt  time.Time  `json:"started_at"`
+	Uptime     string     `json:"uptime"`
+	Goroutines int        `json:"goroutines"`
+	CPUs       int        `json:"cpus"`
+	GOMAXPROCS int        `json:"gomaxprocs"`
+	Memory     memoryInfo `json:"memory"`
+}
+
+// memoryInfo is the part of runtime.MemStats useful to spot leaks and GC pressure.
+type memoryInfo struct {
+	HeapAlloc    uint64 `json:"heap_alloc_bytes"`
+	HeapInuse    uint64 `json:"heap_inuse_bytes"`
+	HeapObjects  uint64 `json:"heap_objects"`
+	Sys          uint64 `json:"sys_bytes"`
+	NumGC        uint32 `json:"num_gc"`
+	PauseTotalNs uint64 `json:"gc_pause_total_ns"`
+}
+
+// GetVersion returns the version, git commit, build time and Go version of the running binary, so that
+// operators can verify what is deployed.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetVersion(c *fiber.Ctx) error {
+	return response.JSON(c, fiber.StatusOK, buildinfo.Get())
+}
+
+// GetRuntimeInfo returns the build metadata of the running binary together with goroutine,
+// CPU and memory statistics, for diagnosing a production instance next to the pprof profiles.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetRuntimeInfo(c *fiber.Ctx) error {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	info := runtimeInfo{
+		Info:       buildinfo.Get(),
+		StartedAt:  startedAt.UTC(),
+		Uptime:     time.Since(startedAt).Round(time.Second).String(),
+		Goroutines: runtime.NumGoroutine(),
+		CPUs:       runtime.NumCPU(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		Memory: memoryInfo{
+			HeapAlloc:    stats.HeapAlloc,
+			HeapInuse:    stats.HeapInuse,
+			HeapObjects:  stats.HeapObjects,
+			Sys:          stats.Sys,
+			NumGC:        stats.NumGC,
+			PauseTotalNs: stats.PauseTotalNs,
+		},
+	}
+	return response.JSON(c, fiber.StatusOK, info)
+}
@@ -0,0 +1,75 @@
+// digest.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/digest"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetDigestSettings returns the digest email schedule of the logged-in user.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetDigestSettings(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	settings := models.DigestSettings{Frequency: models.DigestOff}
+	if user.Digest != nil {
+		settings = *user.Digest
+	}
+	return response.JSON(c, fiber.StatusOK, settings)
+}
+
+// UpdateDigestSettings sets how often the logged-in user gets the digest email of their upcoming, overdue
+// and recently changed tasks: off, daily at hour, or weekly at hour on weekday, in the user's timezone.
+// A digest just turned on is first sent at its next scheduled time.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func UpdateDigestSettings(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	var settings models.DigestSettings
+	if err := c.BodyParser(&settings); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	if err := digest.Validate(settings); err != nil {
+		return apierror.BadRequest(apierror.CodeValidationFailed, err.Error())
+	}
+	if settings.Frequency != models.DigestOff && user.Email == "" {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "An email address is needed to receive digests")
+	}
+
+	settings.LastSentAt = models.NewTimestamp(time.Now())
+	if user.Digest != nil && user.Digest.Frequency != models.DigestOff && !user.Digest.LastSentAt.IsZero() {
+		settings.LastSentAt = user.Digest.LastSentAt
+	}
+	user.Digest = &settings
+	if err := repository.Users.Update(context.Background(), user); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not update digest settings")
+	}
+
+	return response.JSON(c, fiber.StatusOK, settings)
+}
@@ -0,0 +1,56 @@
+// duplicates.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"math"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/permissions"
+	"github.com/bkojha74/task-management/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// duplicateCandidate is an open task listed in the details of a duplicate conflict.
+type duplicateCandidate struct {
+	ID         primitive.ObjectID `json:"id"`
+	Title      string             `json:"title"`
+	Status     string             `json:"status"`
+	AllottedTo string             `json:"allotted_to"`
+	Similarity float64            `json:"similarity"` // Title similarity between 0 and 1
+}
+
+// rejectDuplicates returns a 409 listing the open tasks of the same assignee that the caller may read and
+// whose titles are near-duplicates of the new task's title, or nil if there are none.
+func rejectDuplicates(c *fiber.Ctx, task models.Task) error {
+	duplicates, err := validation.Duplicates(c.UserContext(), task)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error checking for duplicate tasks")
+	}
+
+	candidates := []duplicateCandidate{}
+	for _, duplicate := range duplicates {
+		readable, err := permissions.CanRead(c.UserContext(), subjectOf(c), duplicate.Task)
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Error checking for duplicate tasks")
+		}
+		if readable {
+			candidates = append(candidates, duplicateCandidate{
+				ID:         duplicate.Task.ID,
+				Title:      duplicate.Task.Title,
+				Status:     duplicate.Task.Status,
+				AllottedTo: duplicate.Task.AllottedTo,
+				Similarity: math.Round(duplicate.Similarity*100) / 100,
+			})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return apierror.Conflict(apierror.CodePossibleDuplicate, "Similar open tasks exist, create the task with force=true to add it anyway").
+		WithDetails(fiber.Map{"candidates": candidates})
+}
@@ -0,0 +1,150 @@
+// fields.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// taskFields are the fields of a task that can be selected.
+var taskFields = jsonFields(models.Task{})
+
+// taskEntryFields are the fields of a task of GET /tasks that can be selected, including the joined ones.
+var taskEntryFields = append(jsonFields(models.TaskListEntry{}), taskFields...)
+
+// fieldBuffers are the buffers selectFields encodes documents into, reused across requests.
+var fieldBuffers = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+// summaryFields are the fields of a task summary that can be selected.
+var summaryFields = jsonFields(models.TaskListView{})
+
+// jsonFields returns the JSON names of the fields of a struct.
+func jsonFields(value interface{}) []string {
+	fields := []string{}
+	kind := reflect.TypeOf(value)
+	for i := 0; i < kind.NumField(); i++ {
+		name, _, _ := strings.Cut(kind.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// parseFields reads the "fields" query parameter, e.g. "title,status,end_time", and rejects fields that are
+// not in allowed.
+//
+// Parameters:
+// - c: Fiber context of the request.
+// - allowed: The fields that can be selected.
+//
+// Returns:
+// - []string: The selected fields, or nil when the whole documents are requested.
+// - error: A 400 API error naming the unknown fields.
+func parseFields(c *fiber.Ctx, allowed []string) ([]string, error) {
+	value := strings.TrimSpace(c.Query("fields"))
+	if value == "" {
+		return nil, nil
+	}
+
+	fields, unknown := []string{}, []string{}
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		switch {
+		case field == "":
+		case !containsString(allowed, field):
+			unknown = append(unknown, field)
+		case !containsString(fields, field):
+			fields = append(fields, field)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, apierror.BadRequest(apierror.CodeInvalidQuery, "Unknown field in fields").
+			WithDetails(fiber.Map{"unknown": unknown, "allowed": allowed})
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+// selectFields trims a document, or each document of a list, to the selected fields, the id and the links.
+// Without selected fields the value is returned unchanged.
+//
+// Parameters:
+// - value: The document or list, as it would be sent.
+// - fields: The fields returned by parseFields.
+//
+// Returns:
+// - interface{}: The trimmed value.
+// - error: An error if the value cannot be converted to JSON.
+func selectFields(value interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return value, nil
+	}
+
+	buffer := fieldBuffers.Get().(*bytes.Buffer)
+	buffer.Reset()
+	defer fieldBuffers.Put(buffer)
+	if err := json.NewEncoder(buffer).Encode(value); err != nil {
+		return nil, err
+	}
+
+	// Only the keys are decoded; the kept values stay raw JSON, copied out of the pooled buffer
+	keep := append([]string{"id", "_links"}, fields...)
+	trim := func(object map[string]json.RawMessage) {
+		for key := range object {
+			if !containsString(keep, key) {
+				delete(object, key)
+			}
+		}
+	}
+
+	data := bytes.TrimSpace(buffer.Bytes())
+	if len(data) > 0 && data[0] == '[' {
+		list := []map[string]json.RawMessage{}
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		for _, object := range list {
+			trim(object)
+		}
+		return list, nil
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	trim(object)
+	return object, nil
+}
+
+// containsString reports whether list contains value.
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// sendFields responds with value trimmed to the selected fields.
+func sendFields(c *fiber.Ctx, value interface{}, fields []string) error {
+	selected, err := selectFields(value, fields)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not select fields")
+	}
+	return response.JSON(c, fiber.StatusOK, selected)
+}
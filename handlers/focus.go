@@ -0,0 +1,229 @@
+// focus.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/focus"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultFocusReportDays is the number of days GetFocusReport covers without "from".
+const defaultFocusReportDays = 7
+
+// startFocusRequest is the body of StartFocus.
+type startFocusRequest struct {
+	TaskID         string `json:"task_id"`
+	PlannedMinutes int    `json:"planned_minutes"` // Default 25
+}
+
+// interruptionRequest is the body of LogInterruption.
+type interruptionRequest struct {
+	Minutes int    `json:"minutes"` // Time lost, not counted as focused
+	Note    string `json:"note"`
+}
+
+// StartFocus starts a focus session of the logged-in user on a task they can read, e.g. one allotted to
+// them, for "planned_minutes" (default 25, at most 240). A user focuses on one task at a time: while a
+// session runs, starting another is rejected with its ID in the details. Done tasks cannot be focused on.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func StartFocus(c *fiber.Ctx) error {
+	userIdHex, err := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Invalid user ID")
+	}
+
+	var request startFocusRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	taskIdHex, err := primitive.ObjectIDFromHex(request.TaskID)
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID")
+	}
+	task, err := findTask(c, taskIdHex, false)
+	if err != nil {
+		return err
+	}
+	if task.Status == models.TaskStatusDone {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Done tasks cannot be focused on")
+	}
+
+	session, err := focus.Start(userIdHex, task.ID, request.PlannedMinutes, time.Now())
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeValidationFailed, err.Error())
+	}
+	err = repository.FocusSessions.Create(c.UserContext(), &session)
+	if err == repository.ErrDuplicate {
+		running, findErr := repository.FocusSessions.FindRunning(c.UserContext(), userIdHex)
+		apiErr := apierror.Conflict(apierror.CodeConflict, "A focus session is already running, stop it first")
+		if findErr == nil {
+			apiErr = apiErr.WithDetails(fiber.Map{"session_id": running.ID.Hex()})
+		}
+		return apiErr
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not start focus session")
+	}
+	return response.JSON(c, fiber.StatusCreated, session)
+}
+
+// GetCurrentFocus returns the running focus session of the logged-in user.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetCurrentFocus(c *fiber.Ctx) error {
+	userIdHex, err := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Invalid user ID")
+	}
+
+	session, err := repository.FocusSessions.FindRunning(c.UserContext(), userIdHex)
+	if err == repository.ErrNotFound {
+		return apierror.NotFound(apierror.CodeNotFound, "No focus session is running")
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching focus session")
+	}
+	return response.JSON(c, fiber.StatusOK, session)
+}
+
+// StopFocus ends a running focus session of the logged-in user. The session's focused_minutes are its
+// length, at most 240 minutes, less the minutes of its interruptions; they count towards the daily focus
+// report and the time-tracking columns of the task reports.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func StopFocus(c *fiber.Ctx) error {
+	session, err := findRunningFocus(c)
+	if err != nil {
+		return err
+	}
+	focus.Stop(session, time.Now())
+	return updateRunningFocus(c, session)
+}
+
+// LogInterruption records an interruption of a running focus session of the logged-in user, with the
+// minutes lost to it and an optional note. At most 100 interruptions are logged per session.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func LogInterruption(c *fiber.Ctx) error {
+	var request interruptionRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	session, err := findRunningFocus(c)
+	if err != nil {
+		return err
+	}
+	if err := focus.Interrupt(session, request.Minutes, request.Note, time.Now()); err != nil {
+		return apierror.BadRequest(apierror.CodeValidationFailed, err.Error())
+	}
+	return updateRunningFocus(c, session)
+}
+
+// GetFocusReport returns the time the logged-in user focused per day and per task, from their ended focus
+// sessions, counted on the day a session started in the user's timezone. "from" and "to" are YYYY-MM-DD
+// dates, by default the last 7 days up to today; a range covers at most 366 days.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetFocusReport(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	location := userLocation(user)
+	to := time.Now().In(location)
+	if value := c.Query("to"); value != "" {
+		if to, err = time.ParseInLocation(time.DateOnly, value, location); err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid to date, use YYYY-MM-DD")
+		}
+	}
+	from := to.AddDate(0, 0, 1-defaultFocusReportDays)
+	if value := c.Query("from"); value != "" {
+		if from, err = time.ParseInLocation(time.DateOnly, value, location); err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid from date, use YYYY-MM-DD")
+		}
+	}
+	first := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, location)
+	end := time.Date(to.Year(), to.Month(), to.Day()+1, 0, 0, 0, 0, location)
+
+	sessions, err := repository.FocusSessions.Find(c.UserContext(), repository.FocusSessionQuery{
+		UserID:        user.ID,
+		StartedAfter:  first,
+		StartedBefore: end,
+	})
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching focus sessions")
+	}
+	summary, err := focus.Summarize(sessions, first, to)
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidQuery, "to must not be before from, and a range covers at most 366 days")
+	}
+	return response.JSON(c, fiber.StatusOK, summary)
+}
+
+// findRunningFocus loads the running focus session of the logged-in user with the ID in the path.
+func findRunningFocus(c *fiber.Ctx) (*models.FocusSession, error) {
+	userIdHex, err := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+	if err != nil {
+		return nil, apierror.Internal(apierror.CodeInternal, "Invalid user ID")
+	}
+	sessionId, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return nil, apierror.BadRequest(apierror.CodeInvalidID, "Invalid focus session ID")
+	}
+
+	session, err := repository.FocusSessions.FindByID(c.UserContext(), userIdHex, sessionId)
+	if err == repository.ErrNotFound {
+		return nil, apierror.NotFound(apierror.CodeNotFound, "Focus session not found")
+	}
+	if err != nil {
+		return nil, apierror.Internal(apierror.CodeInternal, "Error fetching focus session")
+	}
+	if !session.EndedAt.IsZero() {
+		return nil, apierror.Conflict(apierror.CodeConflict, "Focus session has already ended")
+	}
+	return session, nil
+}
+
+// updateRunningFocus stores a change of a running focus session and responds with the session. A session
+// that ended meanwhile, e.g. stopped from another device, is a conflict.
+func updateRunningFocus(c *fiber.Ctx, session *models.FocusSession) error {
+	err := repository.FocusSessions.UpdateRunning(c.UserContext(), session)
+	if err == repository.ErrNotFound {
+		return apierror.Conflict(apierror.CodeConflict, "Focus session has already ended")
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not update focus session")
+	}
+	return response.JSON(c, fiber.StatusOK, session)
+}
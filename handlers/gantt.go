@@ -0,0 +1,171 @@
+// gantt.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/permissions"
+	"github.com/bkojha74/task-management/planning"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxDependencies is the most tasks one task can depend on.
+const maxDependencies = 50
+
+// GetProjectGantt returns the tasks of a project scheduled for a Gantt chart: each task with its
+// dependencies, its duration, and the earliest start and finish its dependencies allow. No task starts
+// before the project, at its start date or else its creation.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetProjectGantt(c *fiber.Ctx) error {
+	project, err := findOwnProject(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	query := repository.TaskQuery{UserID: project.OwnerID, ProjectID: project.ID}
+	permissions.RestrictQuery(subjectOf(c), &query)
+	tasks, err := repository.Tasks.Find(c.UserContext(), query)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+	}
+
+	gantt, err := planning.Schedule(tasks, projectStart(*project))
+	if err == planning.ErrDependencyCycle {
+		return apierror.Conflict(apierror.CodeConflict, "Task dependencies form a cycle")
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not schedule tasks")
+	}
+
+	return response.JSON(c, fiber.StatusOK, gantt)
+}
+
+// GetCriticalPath returns the critical path of a project, the chain of dependent tasks that determines when
+// it can finish, with the slack of every task: how long it can slip without delaying the finish. The result
+// is cached until a task of the owner is written, so it is recomputed after every change.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetCriticalPath(c *fiber.Ctx) error {
+	project, err := findOwnProject(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	// Guests do not see private tasks, so their result is not shared through the cache
+	subject := subjectOf(c)
+	cacheKey := cache.CriticalPathKey(project.OwnerID.Hex(), project.ID.Hex())
+	if cached, ok := cache.Get(cacheKey); ok && !subject.Guest() {
+		return response.JSON(c, fiber.StatusOK, cached)
+	}
+
+	query := repository.TaskQuery{UserID: project.OwnerID, ProjectID: project.ID}
+	permissions.RestrictQuery(subject, &query)
+	tasks, err := repository.Tasks.Find(c.UserContext(), query)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+	}
+
+	path, err := planning.ComputeCriticalPath(tasks, projectStart(*project))
+	if err == planning.ErrDependencyCycle {
+		return apierror.Conflict(apierror.CodeConflict, "Task dependencies form a cycle")
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not schedule tasks")
+	}
+
+	if !subject.Guest() {
+		cache.Set(cacheKey, path)
+	}
+	return response.JSON(c, fiber.StatusOK, path)
+}
+
+// projectStart returns the time no task of a project starts before: its start date, else its creation.
+func projectStart(project models.Project) time.Time {
+	if project.StartDate != 0 {
+		return project.StartDate.Time()
+	}
+	return project.CreatedAt.Time()
+}
+
+// validateDependencies checks that the tasks a task depends on are distinct tasks in the same project, and
+// that depending on them does not close a cycle. IDs of tasks that do not exist, e.g. because they were
+// deleted, are accepted and ignored when scheduling.
+func validateDependencies(c *fiber.Ctx, task models.Task) error {
+	if len(task.DependsOn) == 0 {
+		return nil
+	}
+	if len(task.DependsOn) > maxDependencies {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "A task can depend on at most 50 tasks")
+	}
+	seen := map[primitive.ObjectID]bool{}
+	for _, id := range task.DependsOn {
+		if id == task.ID || seen[id] {
+			return apierror.BadRequest(apierror.CodeValidationFailed, "Dependencies must be distinct tasks other than the task itself")
+		}
+		seen[id] = true
+	}
+
+	userIdHex, _ := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+	dependencies, err := repository.Tasks.Find(c.UserContext(), repository.TaskQuery{
+		UserID: userIdHex,
+		IDs:    task.DependsOn,
+		Fields: []string{"project_id", "depends_on"},
+	})
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+	}
+	for _, dependency := range dependencies {
+		if dependency.ProjectID != task.ProjectID {
+			return apierror.BadRequest(apierror.CodeValidationFailed, "Dependencies must be tasks of the same project")
+		}
+	}
+	if task.ID.IsZero() {
+		return nil // Nothing depends on a new task yet
+	}
+
+	// Follow the dependencies of the dependencies; reaching the task means a cycle
+	visited := map[primitive.ObjectID]bool{}
+	for len(dependencies) > 0 {
+		next := []primitive.ObjectID{}
+		for _, dependency := range dependencies {
+			for _, id := range dependency.DependsOn {
+				if id == task.ID {
+					return apierror.BadRequest(apierror.CodeValidationFailed, "Dependencies must not form a cycle")
+				}
+				if !visited[id] {
+					visited[id] = true
+					next = append(next, id)
+				}
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		if dependencies, err = repository.Tasks.Find(c.UserContext(), repository.TaskQuery{
+			UserID: userIdHex,
+			IDs:    next,
+			Fields: []string{"depends_on"},
+		}); err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+// github.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/githubsync"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/readmodel"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Limits of the GitHub integration
+const (
+	githubStateExpiry = 10 * time.Minute
+	githubCallTimeout = 30 * time.Second
+)
+
+// githubAuthorization is the response of ConnectGitHub.
+type githubAuthorization struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// githubIssueRequest is the body of LinkGitHubIssue.
+type githubIssueRequest struct {
+	Repo string `json:"repo"` // "owner/name"
+}
+
+// githubWebhookEvent holds the fields of GitHub's issues and issue_comment events the sync needs.
+type githubWebhookEvent struct {
+	Issue struct {
+		Number int `json:"number"`
+	} `json:"issue"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// ConnectGitHub returns the GitHub page on which the logged-in user authorizes the OAuth app. GitHub then
+// redirects the user to GitHubCallback, which connects the account. The link is valid for 10 minutes.
+//
+// Parameters:
+// - app: The GitHub OAuth app.
+//
+// Returns:
+// - fiber.Handler: The handler starting the authorization.
+func ConnectGitHub(app *githubsync.App) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, err := currentUser(c)
+		if err != nil {
+			return err
+		}
+
+		expiry := time.Now().Add(githubStateExpiry)
+		state := app.State(user.ID, expiry)
+		return response.JSON(c, fiber.StatusOK, githubAuthorization{URL: app.AuthorizeURL(state), ExpiresAt: expiry.UTC().Truncate(time.Second)})
+	}
+}
+
+// GitHubCallback completes the authorization started by ConnectGitHub: it trades the code GitHub redirected
+// with for an access token and stores it with the GitHub login on the user the state names. The user's
+// browser arrives here without a bearer token, so the signed state authenticates the request.
+//
+// Parameters:
+// - app: The GitHub OAuth app.
+//
+// Returns:
+// - fiber.Handler: The handler of the OAuth callback.
+func GitHubCallback(app *githubsync.App) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Query("error") != "" {
+			return apierror.BadRequest(apierror.CodeValidationFailed, "GitHub authorization was denied")
+		}
+		userId, err := app.ParseState(c.Query("state"), time.Now())
+		if err != nil {
+			return apierror.Unauthorized(apierror.CodeInvalidToken, "OAuth state is invalid or expired")
+		}
+		user, err := repository.Users.FindByID(c.UserContext(), userId)
+		if err == repository.ErrNotFound {
+			return apierror.Unauthorized(apierror.CodeInvalidToken, "OAuth state is invalid or expired")
+		}
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Error fetching user")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), githubCallTimeout)
+		defer cancel()
+		token, err := app.Exchange(ctx, c.Query("code"))
+		if err != nil {
+			log.Printf("Could not exchange the GitHub code of user %s: %v", user.Username, err)
+			return apierror.New(fiber.StatusBadGateway, apierror.CodeBadGateway, "GitHub did not issue a token")
+		}
+		account, err := app.CurrentUser(ctx, token)
+		if err != nil {
+			return apierror.New(fiber.StatusBadGateway, apierror.CodeBadGateway, "GitHub did not issue a token")
+		}
+
+		user.GitHub = &models.GitHubAccount{Login: account.Login, Token: token, ConnectedAt: models.NewTimestamp(time.Now())}
+		if err := repository.Users.Update(context.Background(), user); err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Could not connect GitHub")
+		}
+		return response.JSON(c, fiber.StatusOK, user.GitHub)
+	}
+}
+
+// GetGitHubAccount returns the GitHub account the logged-in user connected.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetGitHubAccount(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	if user.GitHub == nil {
+		return apierror.NotFound(apierror.CodeNotFound, "No GitHub account is connected")
+	}
+	return response.JSON(c, fiber.StatusOK, user.GitHub)
+}
+
+// DisconnectGitHub forgets the GitHub token of the logged-in user. The issues they linked stop syncing until
+// they connect again; revoking the token itself is done in the GitHub settings.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func DisconnectGitHub(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	user.GitHub = nil
+	if err := repository.Users.Update(context.Background(), user); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not disconnect GitHub")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// LinkGitHubIssue creates a GitHub issue from a task with the GitHub account of the logged-in user and links
+// the task to it. From then on the sync job mirrors status changes and comments between the two.
+//
+// Parameters:
+// - app: The GitHub OAuth app.
+//
+// Returns:
+// - fiber.Handler: The handler linking tasks to new issues.
+func LinkGitHubIssue(app *githubsync.App) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		taskIdHex, err := primitive.ObjectIDFromHex(c.Params("id"))
+		if err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID")
+		}
+		var request githubIssueRequest
+		if err := c.BodyParser(&request); err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+		}
+		if !githubsync.ValidRepo(request.Repo) {
+			return apierror.BadRequest(apierror.CodeValidationFailed, "repo must be a GitHub repository like owner/name")
+		}
+
+		task, err := findTask(c, taskIdHex, true)
+		if err != nil {
+			return err
+		}
+		if task.GitHubIssue != nil {
+			return apierror.Conflict(apierror.CodeConflict, "Task is already linked to a GitHub issue").WithDetails(fiber.Map{"github_issue": task.GitHubIssue})
+		}
+		user, err := currentUser(c)
+		if err != nil {
+			return err
+		}
+		if user.GitHub == nil {
+			return apierror.BadRequest(apierror.CodeValidationFailed, "Connect a GitHub account first")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), githubCallTimeout)
+		defer cancel()
+		linked, err := githubsync.LinkIssue(ctx, app, *task, user, request.Repo)
+		if linked.GitHubIssue == nil {
+			return githubError(err)
+		}
+		// The issue exists even if its first sync failed; the job retries that
+		return response.JSON(c, fiber.StatusCreated, withTaskLinks(c, linked))
+	}
+}
+
+// SyncGitHubIssue syncs a linked task with its issue right away instead of waiting for the sync job.
+//
+// Parameters:
+// - app: The GitHub OAuth app.
+//
+// Returns:
+// - fiber.Handler: The handler syncing tasks.
+func SyncGitHubIssue(app *githubsync.App) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		task, err := findLinkedTask(c)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), githubCallTimeout)
+		defer cancel()
+		synced, err := githubsync.Reconcile(ctx, app, *task)
+		if err != nil {
+			return githubError(err)
+		}
+		return response.JSON(c, fiber.StatusOK, withTaskLinks(c, synced))
+	}
+}
+
+// UnlinkGitHubIssue stops syncing a task with its GitHub issue. The issue and the comments copied either way
+// are kept.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func UnlinkGitHubIssue(c *fiber.Ctx) error {
+	previous, err := findLinkedTask(c)
+	if err != nil {
+		return err
+	}
+
+	task := *previous
+	task.GitHubIssue = nil
+	task.Version = previous.Version + 1
+	if err := repository.Tasks.Update(context.Background(), &task, previous.Version); err != nil {
+		if err == repository.ErrNotFound {
+			return apierror.Conflict(apierror.CodeVersionConflict, "Task was modified by someone else, reload it and retry")
+		}
+		return apierror.Internal(apierror.CodeInternal, "Could not unlink the GitHub issue")
+	}
+	cache.InvalidateTask(task.UserID.Hex(), task.ID.Hex())
+	readmodel.SyncOrLog(context.Background(), task)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GitHubWebhook receives the issues and issue_comment events of the repository webhooks and syncs the tasks
+// linked to the issue at once. Requests must be signed with GITHUB_WEBHOOK_SECRET; sync failures are logged,
+// and the sync job retries them.
+//
+// Parameters:
+// - app: The GitHub OAuth app.
+//
+// Returns:
+// - fiber.Handler: The handler of the webhook.
+func GitHubWebhook(app *githubsync.App) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !app.VerifyWebhook(c.Get(githubsync.SignatureHeader), c.Body()) {
+			return apierror.Unauthorized(apierror.CodeInvalidToken, "invalid webhook signature")
+		}
+		switch c.Get("X-GitHub-Event") {
+		case "issues", "issue_comment":
+		default:
+			return c.SendStatus(fiber.StatusNoContent) // e.g. ping
+		}
+
+		var event githubWebhookEvent
+		if err := json.Unmarshal(c.Body(), &event); err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), githubCallTimeout)
+		defer cancel()
+		if err := githubsync.SyncIssue(ctx, app, event.Repository.FullName, event.Issue.Number); err != nil {
+			log.Printf("Could not sync GitHub issue %s#%d: %v", event.Repository.FullName, event.Issue.Number, err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// findLinkedTask returns the task of the request if the user may change it and it is linked to an issue.
+func findLinkedTask(c *fiber.Ctx) (*models.Task, error) {
+	taskIdHex, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return nil, apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID")
+	}
+	task, err := findTask(c, taskIdHex, true)
+	if err != nil {
+		return nil, err
+	}
+	if task.GitHubIssue == nil {
+		return nil, apierror.NotFound(apierror.CodeNotFound, "Task is not linked to a GitHub issue")
+	}
+	return task, nil
+}
+
+// githubError turns a failure of the GitHub integration into an API error.
+func githubError(err error) error {
+	var apiErr *githubsync.APIError
+	switch {
+	case err == repository.ErrNotFound:
+		return apierror.Conflict(apierror.CodeVersionConflict, "Task was modified by someone else, reload it and retry")
+	case errors.Is(err, githubsync.ErrNotConnected):
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Connect a GitHub account first")
+	case errors.Is(err, githubsync.ErrUnauthorized):
+		return apierror.BadRequest(apierror.CodeValidationFailed, "GitHub rejected the token, connect the GitHub account again")
+	case errors.As(err, &apiErr):
+		return apierror.New(fiber.StatusBadGateway, apierror.CodeBadGateway, "GitHub rejected the request").WithDetails(fiber.Map{"status": apiErr.StatusCode, "message": apiErr.Message})
+	}
+	return apierror.New(fiber.StatusBadGateway, apierror.CodeBadGateway, "GitHub could not be reached")
+}
@@ -7,81 +7,52 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"log"
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
-	"github.com/bkojha74/task-management/database"
+	"github.com/bkojha74/task-management/auth"
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/features"
+	"github.com/bkojha74/task-management/focus"
+	"github.com/bkojha74/task-management/interchange"
+	"github.com/bkojha74/task-management/jobs"
 	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/notifications"
+	"github.com/bkojha74/task-management/planning"
+	"github.com/bkojha74/task-management/quota"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+	"github.com/bkojha74/task-management/search"
+	"github.com/bkojha74/task-management/signup"
 	"github.com/bkojha74/task-management/utils"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/require"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-var testMongoURI string
-var jwtSecret string
+var jwtSecret *utils.SigningSecret
 var testApp *fiber.App
 
 func TestMain(m *testing.M) {
-	// Load environment variables
-	err := godotenv.Load("../config/.env")
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
-
-	testMongoURI = os.Getenv("TEST_MONGO_URI")
-	jwtSecret = os.Getenv("JWT_SECRET")
-	if testMongoURI == "" || jwtSecret == "" {
-		log.Fatal("TEST_MONGO_URI and JWT_SECRET must be set in the environment")
-	}
-
-	// Set up MongoDB connection
-	clientOptions := options.Client().ApplyURI(testMongoURI)
-	client, err := mongo.Connect(context.Background(), clientOptions)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = client.Ping(context.Background(), nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	database.UsersCollection = client.Database("testdb").Collection("users")
-	database.TasksCollection = client.Database("testdb").Collection("tasks")
+	// Handlers run against in-memory repositories, no MongoDB or listening port is needed
+	repository.UseMemory()
+	jwtSecret = utils.NewSigningSecret("test-secret")
 
 	// Initialize Fiber app
-	testApp = fiber.New()
-	testApp.Post("/signup", SignUp)
-	testApp.Post("/signin", SignIn(jwtSecret, 60))
-	testApp.Post("/tasks", utils.JWTMiddleware(jwtSecret), CreateTask)
-	testApp.Get("/tasks", utils.JWTMiddleware(jwtSecret), GetTasks)
-	testApp.Get("/tasks/:id", utils.JWTMiddleware(jwtSecret), GetTask)
-	testApp.Put("/tasks/:id", utils.JWTMiddleware(jwtSecret), UpdateTask)
-	testApp.Delete("/tasks/:id", utils.JWTMiddleware(jwtSecret), DeleteTask)
-	testApp.Post("/signout", SignOut)
-
-	// Start the server in a goroutine
-	go func() {
-		log.Fatal(testApp.Listen(":4000"))
-	}()
+	testApp = newTestApp(jwtSecret)
 
 	// Run tests
-	code := m.Run()
-
-	// Clean up database after tests
-	/*err = client.Database("testdb").Drop(context.Background())
-	if err != nil {
-		log.Fatal(err)
-	}*/
-
-	os.Exit(code)
+	os.Exit(m.Run())
 }
 
 func TestSignUp(t *testing.T) {
@@ -89,22 +60,50 @@ func TestSignUp(t *testing.T) {
 		Username: "testuser",
 		Password: "testpassword",
 	}
-	body, _ := json.Marshal(user)
-
-	req, err := http.NewRequest(http.MethodPost, "http://localhost:4000/signup", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
 
-	// Create a custom HTTP client with a longer timeout
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	require.NoError(t, err)
+	resp := doRequest(t, http.MethodPost, "/signup", user, "")
 	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
 
 	var createdUser models.User
-	err = json.NewDecoder(resp.Body).Decode(&createdUser)
-	require.NoError(t, err)
+	decodeBody(t, resp, &createdUser)
 	require.Equal(t, user.Username, createdUser.Username)
+
+	// Signing up again with the same username fails
+	resp = doRequest(t, http.MethodPost, "/signup", user, "")
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	// Unknown timezones are rejected
+	resp = doRequest(t, http.MethodPost, "/signup", models.User{Username: "testzone", Password: "testpassword", Timezone: "Mars/Olympus"}, "")
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestSignUpPolicy(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		_, _ = w.Write([]byte(`{"success": ` + strconv.FormatBool(r.PostForm.Get("response") == "solved") + `}`))
+	}))
+	defer provider.Close()
+	signup.Default = signup.Policy{CaptchaProvider: signup.ProviderHCaptcha, CaptchaSecret: "secret", VerifyURL: provider.URL}
+	defer func() { signup.Default = signup.Policy{} }()
+
+	// Sign-ups without a solved CAPTCHA or with a disposable email address are rejected
+	resp := doRequest(t, http.MethodPost, "/signup", fiber.Map{"username": "testcaptcha", "password": "testpassword"}, "")
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/signup", fiber.Map{"username": "testcaptcha", "password": "testpassword", "captcha_token": "guessed"}, "")
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/signup", fiber.Map{"username": "testcaptcha", "password": "testpassword", "captcha_token": "solved", "email": "bot@mailinator.com"}, "")
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp = doRequest(t, http.MethodPost, "/signup", fiber.Map{"username": "testcaptcha", "password": "testpassword", "captcha_token": "solved", "email": "jane@example.com"}, "")
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var created models.User
+	decodeBody(t, resp, &created)
+	require.Equal(t, "jane@example.com", created.Email)
+
+	// A provider that can't be reached is not mistaken for a failed challenge
+	provider.Close()
+	resp = doRequest(t, http.MethodPost, "/signup", fiber.Map{"username": "testcaptcha2", "password": "testpassword", "captcha_token": "solved"}, "")
+	require.Equal(t, fiber.StatusBadGateway, resp.StatusCode)
 }
 
 func TestJWTMiddleware(t *testing.T) {
@@ -113,169 +112,266 @@ func TestJWTMiddleware(t *testing.T) {
 		Username: "testjwt",
 		Password: "testpassword",
 	}
-	body, _ := json.Marshal(user)
 
 	// First, sign up the user
-	req, err := http.NewRequest(http.MethodPost, "http://localhost:4000/signup", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{Timeout: 10 * time.Second}
-	_, _ = client.Do(req)
+	resp := doRequest(t, http.MethodPost, "/signup", user, "")
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
 
 	// Sign in to get the token
-	req, err = http.NewRequest(http.MethodPost, "http://localhost:4000/signin", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := client.Do(req)
-	require.NoError(t, err)
+	resp = doRequest(t, http.MethodPost, "/signin", user, "")
 	require.Equal(t, fiber.StatusOK, resp.StatusCode)
 
-	var tokenResp map[string]string
-	err = json.NewDecoder(resp.Body).Decode(&tokenResp)
-	require.NoError(t, err)
-
-	token := tokenResp["token"]
-
-	// Test protected route with valid token
-	req, err = http.NewRequest(http.MethodGet, "http://localhost:4000/tasks", nil)
-	require.NoError(t, err)
-	req.Header.Set("Authorization", token) // Corrected header value without 'Bearer ' prefix
+	var tokenResp struct {
+		Token string `json:"token"`
+	}
+	decodeBody(t, resp, &tokenResp)
+	token := tokenResp.Token
 
-	resp, err = client.Do(req)
-	require.NoError(t, err)
+	// Test protected route with valid token (without 'Bearer ' prefix)
+	resp = doRequest(t, http.MethodGet, "/tasks", nil, token)
 	require.Equal(t, fiber.StatusOK, resp.StatusCode)
 
 	// Test protected route with invalid token
-	req, err = http.NewRequest(http.MethodGet, "http://localhost:4000/tasks", nil)
-	require.NoError(t, err)
-	req.Header.Set("Authorization", "Bearer invalidtoken")
+	resp = doRequest(t, http.MethodGet, "/tasks", nil, "Bearer invalidtoken")
+	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
 
-	resp, err = client.Do(req)
-	require.NoError(t, err)
+	// Test protected route without token
+	resp = doRequest(t, http.MethodGet, "/tasks", nil, "")
 	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
 }
 
 func TestSignIn(t *testing.T) {
-	// Test case: Successful sign-in
 	user := models.User{
-		Username: "testuser",
+		Username: "testsignin",
 		Password: "testpassword",
 	}
-	body, _ := json.Marshal(user)
-
-	req, err := http.NewRequest(http.MethodPost, "http://localhost:4000/signin", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	require.NoError(t, err)
-	defer resp.Body.Close()
+	resp := doRequest(t, http.MethodPost, "/signup", user, "")
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
 
+	// Test case: Successful sign-in
+	resp = doRequest(t, http.MethodPost, "/signin", user, "")
 	require.Equal(t, http.StatusOK, resp.StatusCode)
 
-	var tokenResp map[string]string
-	err = json.NewDecoder(resp.Body).Decode(&tokenResp)
-	require.NoError(t, err)
-	require.NotEmpty(t, tokenResp["token"])
+	var signedIn signInResponse
+	decodeBody(t, resp, &signedIn)
+	require.NotEmpty(t, signedIn.Token)
+	require.Equal(t, "Bearer", signedIn.TokenType)
+	require.Equal(t, 60, signedIn.ExpiresIn)
+	require.WithinDuration(t, time.Now().Add(time.Minute), signedIn.ExpiresAt.Time(), 2*time.Second)
+	require.Equal(t, "testsignin", signedIn.User.Username)
+	require.Empty(t, signedIn.RefreshToken) // Refresh tokens are disabled by default
 
 	// Test case: Incorrect password
-	invalidUser := models.User{
-		Username: "testuser",
-		Password: "invalidpassword",
-	}
-	invalidBody, _ := json.Marshal(invalidUser)
+	resp = doRequest(t, http.MethodPost, "/signin", models.User{Username: "testsignin", Password: "invalidpassword"}, "")
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 
-	req, err = http.NewRequest(http.MethodPost, "http://localhost:4000/signin", bytes.NewBuffer(invalidBody))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
+	// Test case: User not found
+	resp = doRequest(t, http.MethodPost, "/signin", models.User{Username: "nonexistentuser", Password: "password"}, "")
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 
-	resp, err = client.Do(req)
-	require.NoError(t, err)
-	defer resp.Body.Close()
+	// Test case: Missing username in request body
+	resp = doRequest(t, http.MethodPost, "/signin", models.User{Password: "password"}, "")
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
-	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	// Test case: Missing password in request body
+	resp = doRequest(t, http.MethodPost, "/signin", models.User{Username: "testsignin"}, "")
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
 
-	// Test case: User not found
-	nonexistentUser := models.User{
-		Username: "nonexistentuser",
-		Password: "password",
-	}
-	nonexistentBody, _ := json.Marshal(nonexistentUser)
+func TestRefreshToken(t *testing.T) {
+	defer func(policy utils.TokenPolicy) { utils.Tokens = policy }(utils.Tokens)
+	utils.Tokens.Refresh = 24 * time.Hour
+	credentials := models.User{Username: "testrefresh", Password: "testpassword"}
+	resp := doRequest(t, http.MethodPost, "/signup", credentials, "")
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
 
-	req, err = http.NewRequest(http.MethodPost, "http://localhost:4000/signin", bytes.NewBuffer(nonexistentBody))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
+	resp = doRequest(t, http.MethodPost, "/signin", credentials, "")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var signedIn signInResponse
+	decodeBody(t, resp, &signedIn)
+	require.NotEmpty(t, signedIn.RefreshToken)
+	require.WithinDuration(t, time.Now().Add(24*time.Hour), signedIn.RefreshExpiresAt.Time(), 2*time.Second)
 
-	resp, err = client.Do(req)
-	require.NoError(t, err)
-	defer resp.Body.Close()
+	// The refresh token is exchanged for a new access token and a new refresh token
+	resp = doRequest(t, http.MethodPost, "/token/refresh", fiber.Map{"refresh_token": signedIn.RefreshToken}, "")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var refreshed signInResponse
+	decodeBody(t, resp, &refreshed)
+	require.NotEmpty(t, refreshed.Token)
+	require.NotEqual(t, signedIn.RefreshToken, refreshed.RefreshToken)
+	require.Equal(t, "testrefresh", refreshed.User.Username)
+	resp = doRequest(t, http.MethodGet, "/users/me/sessions", nil, refreshed.Token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
 
-	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	// Malformed tokens are rejected, and using a rotated token again revokes the session
+	resp = doRequest(t, http.MethodPost, "/token/refresh", fiber.Map{"refresh_token": "guessed"}, "")
+	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/token/refresh", fiber.Map{"refresh_token": signedIn.RefreshToken}, "")
+	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/token/refresh", fiber.Map{"refresh_token": refreshed.RefreshToken}, "")
+	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/users/me/sessions", nil, refreshed.Token)
+	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+}
 
-	// Test case: Missing username in request body
-	missingUsername := models.User{
-		Password: "password",
-	}
-	missingUsernameBody, _ := json.Marshal(missingUsername)
+func TestRememberMeSession(t *testing.T) {
+	defer func(policy utils.TokenPolicy) { utils.Tokens = policy }(utils.Tokens)
+	utils.Tokens.Refresh, utils.Tokens.RememberMe = time.Hour, 30*24*time.Hour
+	utils.Tokens.Sliding, utils.Tokens.MaxSession = true, 60*24*time.Hour
+	credentials := fiber.Map{"username": "testremember", "password": "testpassword"}
+	resp := doRequest(t, http.MethodPost, "/signup", credentials, "")
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
 
-	req, err = http.NewRequest(http.MethodPost, "http://localhost:4000/signin", bytes.NewBuffer(missingUsernameBody))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
+	// Without remember_me the refresh token lasts an hour, with it 30 days
+	resp = doRequest(t, http.MethodPost, "/signin", credentials, "")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var signedIn signInResponse
+	decodeBody(t, resp, &signedIn)
+	require.WithinDuration(t, time.Now().Add(time.Hour), signedIn.RefreshExpiresAt.Time(), 2*time.Second)
+
+	credentials["remember_me"] = true
+	resp = doRequest(t, http.MethodPost, "/signin", credentials, "")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &signedIn)
+	require.WithinDuration(t, time.Now().Add(30*24*time.Hour), signedIn.RefreshExpiresAt.Time(), 2*time.Second)
 
-	resp, err = client.Do(req)
+	// Sliding sessions are extended from the refresh, but not past the maximum after the sign-in
+	user, err := repository.Users.FindByUsername(context.Background(), "testremember")
 	require.NoError(t, err)
-	defer resp.Body.Close()
+	issuedAt := time.Now().Add(-59 * 24 * time.Hour)
+	session := models.Session{ID: primitive.NewObjectID(), UserID: user.ID, IssuedAt: models.NewTimestamp(issuedAt), ExpiresAt: models.NewTimestamp(time.Now().Add(time.Hour)), RememberMe: true}
+	refreshToken, err := newRefreshToken(session.ID)
+	require.NoError(t, err)
+	session.RefreshTokenHash = hashRefreshToken(refreshToken)
+	require.NoError(t, repository.Sessions.Create(context.Background(), &session))
+	resp = doRequest(t, http.MethodPost, "/token/refresh", fiber.Map{"refresh_token": refreshToken}, "")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &signedIn)
+	require.WithinDuration(t, issuedAt.Add(60*24*time.Hour), signedIn.RefreshExpiresAt.Time(), 2*time.Second)
+}
 
-	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+func TestSignInHistory(t *testing.T) {
+	var sent []notifications.Notification
+	notifications.Default = notifications.NotifierFunc(func(ctx context.Context, notification notifications.Notification) error {
+		sent = append(sent, notification)
+		return nil
+	})
+	defer func() { notifications.Default = notifications.Nop{} }()
+	credentials := models.User{Username: "testhistory", Password: "testpassword"}
+	resp := doRequest(t, http.MethodPost, "/signup", credentials, "")
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
 
-	// Test case: Missing password in request body
-	missingPassword := models.User{
-		Username: "testuser",
+	signIn := func(userAgent string) string {
+		data, err := json.Marshal(credentials)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/signin", bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", userAgent)
+		resp, err := testApp.Test(req, -1)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var signedIn signInResponse
+		decodeBody(t, resp, &signedIn)
+		return signedIn.Token
 	}
-	missingPasswordBody, _ := json.Marshal(missingPassword)
 
-	req, err = http.NewRequest(http.MethodPost, "http://localhost:4000/signin", bytes.NewBuffer(missingPasswordBody))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
+	// The first sign-in and sign-ins from known devices are not reported, new devices are
+	signIn("Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0")
+	signIn("Mozilla/5.0 (X11; Linux x86_64; rv:129.0) Gecko/20100101 Firefox/129.0")
+	require.Empty(t, sent)
+	token := signIn("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36")
+	require.Len(t, sent, 1)
+	require.Equal(t, notifications.EventNewSignIn, sent[0].Event)
+	require.Equal(t, "testhistory", sent[0].Recipient)
+	require.Equal(t, "New sign-in from a new device", sent[0].Subject)
+	require.Contains(t, sent[0].Message, "Chrome on Windows")
+
+	// The history lists the sign-ins newest first
+	resp = doRequest(t, http.MethodGet, "/users/me/signins", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var history []models.SignIn
+	decodeBody(t, resp, &history)
+	require.Len(t, history, 3)
+	require.Equal(t, "Chrome on Windows", history[0].Device)
+	require.True(t, history[0].NewDevice)
+	require.False(t, history[0].NewNetwork)
+	require.Equal(t, "Firefox on Linux", history[2].Device)
+	require.False(t, history[2].NewDevice)
+
+	resp = doRequest(t, http.MethodGet, "/users/me/signins?limit=1", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &history)
+	require.Len(t, history, 1)
+	resp = doRequest(t, http.MethodGet, "/users/me/signins?limit=0", nil, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
 
-	resp, err = client.Do(req)
-	require.NoError(t, err)
-	defer resp.Body.Close()
+// directoryStub authenticates the users it has a password for, like a directory would.
+type directoryStub map[string]string
 
-	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+func (directoryStub) Name() string {
+	return auth.ProviderLDAP
 }
 
-func TestCreateTask(t *testing.T) {
-	// Sign in to get a valid token
-	user := models.User{
-		Username: "TestCreateTask",
-		Password: "testpassword",
+func (d directoryStub) Authenticate(ctx context.Context, username, password string) (auth.Identity, error) {
+	if username == "offline" {
+		return auth.Identity{}, fmt.Errorf("%w: connection refused", auth.ErrUnavailable)
+	}
+	if stored, ok := d[username]; !ok || stored != password {
+		return auth.Identity{}, auth.ErrInvalidCredentials
 	}
-	body, _ := json.Marshal(user)
+	return auth.Identity{Username: username, Email: username + "@example.com", Subject: "ldap|uid=" + username}, nil
+}
 
-	// First, sign up the user
-	req, err := http.NewRequest(http.MethodPost, "http://localhost:4000/signup", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{Timeout: 10 * time.Second}
-	_, _ = client.Do(req)
+func TestDirectorySignIn(t *testing.T) {
+	auth.Default = directoryStub{"dirdana": "from-ldap", "dirlocal": "from-ldap", "dirscim": "from-ldap"}
+	defer func() { auth.Default = auth.Local{} }()
 
-	// Sign in to get the token
-	req, err = http.NewRequest(http.MethodPost, "http://localhost:4000/signin", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
+	// Test case: Accounts come from the directory, so sign-up is disabled
+	resp := doRequest(t, http.MethodPost, "/signup", models.User{Username: "dirdana", Password: "local"}, "")
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
 
-	resp, err := client.Do(req)
-	require.NoError(t, err)
-	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	// Test case: The first sign-in creates the account, later ones reuse it
+	for i := 0; i < 2; i++ {
+		resp = doRequest(t, http.MethodPost, "/signin", models.User{Username: "dirdana", Password: "from-ldap"}, "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	user, err := repository.Users.FindByUsername(context.Background(), "dirdana")
+	require.NoError(t, err)
+	require.Equal(t, "dirdana@example.com", user.Email)
+	require.Empty(t, user.Password)
+	require.Empty(t, user.Role)
+	require.Equal(t, "ldap|uid=dirdana", user.Subject)
+
+	// Test case: A local account with a password is not taken over by the directory user of its name
+	local := createTestUser(t, "dirlocal")
+	resp = doRequest(t, http.MethodPost, "/signin", models.User{Username: "dirlocal", Password: "from-ldap"}, "")
+	require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	// Test case: Once an administrator linked it, the directory user signs in to it
+	local.Subject = "ldap|uid=dirlocal"
+	require.NoError(t, repository.Users.Update(context.Background(), &local))
+	resp = doRequest(t, http.MethodPost, "/signin", models.User{Username: "dirlocal", Password: "from-ldap"}, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
 
-	var tokenResp map[string]string
-	err = json.NewDecoder(resp.Body).Decode(&tokenResp)
+	// Test case: An account without a password, e.g. provisioned by SCIM, is linked on first sign-in
+	require.NoError(t, repository.Users.Create(context.Background(), &models.User{Username: "dirscim"}))
+	resp = doRequest(t, http.MethodPost, "/signin", models.User{Username: "dirscim", Password: "from-ldap"}, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	user, err = repository.Users.FindByUsername(context.Background(), "dirscim")
 	require.NoError(t, err)
+	require.Equal(t, "ldap|uid=dirscim", user.Subject)
 
-	token := tokenResp["token"]
+	// Test case: Wrong passwords are rejected and an unreachable directory is a bad gateway
+	resp = doRequest(t, http.MethodPost, "/signin", models.User{Username: "dirdana", Password: "guess"}, "")
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/signin", models.User{Username: "offline", Password: "secret"}, "")
+	require.Equal(t, http.StatusBadGateway, resp.StatusCode)
+}
+
+func TestCreateTask(t *testing.T) {
+	user := createTestUser(t, "TestCreateTask")
+	token := mintToken(t, user)
 
 	// Create a new task with valid token
 	task := models.Task{
@@ -283,100 +379,85 @@ func TestCreateTask(t *testing.T) {
 		Description: "This is a test task",
 		AllottedTo:  "TestCreateTask",
 	}
-	body, _ = json.Marshal(task)
 
-	req, err = http.NewRequest(http.MethodPost, "http://localhost:4000/tasks", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", token)
-
-	resp, err = client.Do(req)
-	require.NoError(t, err)
+	resp := doRequest(t, http.MethodPost, "/tasks", task, token)
 	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
 
 	var createdTask models.Task
-	err = json.NewDecoder(resp.Body).Decode(&createdTask)
-	require.NoError(t, err)
+	decodeBody(t, resp, &createdTask)
 	require.Equal(t, task.Title, createdTask.Title)
 	require.Equal(t, task.Description, createdTask.Description)
+	require.Equal(t, models.TaskStatusPending, createdTask.Status)
+	require.Equal(t, models.TaskPriorityMedium, createdTask.Priority)
+	require.Equal(t, 1, createdTask.Version)
+
+	// Allotting to an unknown user fails
+	task.AllottedTo = "nobody"
+	resp = doRequest(t, http.MethodPost, "/tasks", task, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
 }
 
 func TestGetTasks(t *testing.T) {
-	// Sign in to get a valid token
-	user := models.User{
-		Username: "testgettasks",
-		Password: "testpassword",
-	}
-	body, _ := json.Marshal(user)
-
-	// First, sign up the user
-	req, err := http.NewRequest(http.MethodPost, "http://localhost:4000/signup", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{Timeout: 10 * time.Second}
-	_, _ = client.Do(req)
-
-	// Sign in to get the token
-	req, err = http.NewRequest(http.MethodPost, "http://localhost:4000/signin", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := client.Do(req)
-	require.NoError(t, err)
-	require.Equal(t, fiber.StatusOK, resp.StatusCode)
-
-	var tokenResp map[string]string
-	err = json.NewDecoder(resp.Body).Decode(&tokenResp)
-	require.NoError(t, err)
+	user := createTestUser(t, "testgettasks")
+	token := mintToken(t, user)
 
-	token := tokenResp["token"]
+	for _, title := range []string{"First", "Second", "Third"} {
+		resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: title, AllottedTo: "testgettasks"}, token)
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	}
 
 	// Get tasks with valid token
-	req, err = http.NewRequest(http.MethodGet, "http://localhost:4000/tasks", nil)
-	require.NoError(t, err)
-	req.Header.Set("Authorization", token)
-
-	resp, err = client.Do(req)
-	require.NoError(t, err)
+	resp := doRequest(t, http.MethodGet, "/tasks", nil, token)
 	require.Equal(t, fiber.StatusOK, resp.StatusCode)
 
 	var tasks []models.Task
-	err = json.NewDecoder(resp.Body).Decode(&tasks)
-	require.NoError(t, err)
+	decodeBody(t, resp, &tasks)
+	require.Len(t, tasks, 3)
 
-	// Verify that tasks are returned (you may want to add more specific checks here)
-	require.GreaterOrEqual(t, len(tasks), 0)
-}
-
-func TestUpdateTask(t *testing.T) {
-	// Sign in to get a valid token
-	user := models.User{
-		Username: "testupdatetask",
-		Password: "testpassword",
-	}
-	body, _ := json.Marshal(user)
+	// Paginate two at a time
+	resp = doRequest(t, http.MethodGet, "/tasks?limit=2&sort=title", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	cursor := resp.Header.Get("X-Next-Cursor")
+	decodeBody(t, resp, &tasks)
+	require.Len(t, tasks, 2)
+	require.Equal(t, "First", tasks[0].Title)
+	require.NotEmpty(t, cursor)
 
-	// First, sign up the user
-	req, err := http.NewRequest(http.MethodPost, "http://localhost:4000/signup", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{Timeout: 10 * time.Second}
-	_, _ = client.Do(req)
+	resp = doRequest(t, http.MethodGet, "/tasks?limit=2&sort=title&cursor="+cursor, nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Empty(t, resp.Header.Get("X-Next-Cursor"))
+	decodeBody(t, resp, &tasks)
+	require.Len(t, tasks, 1)
+	require.Equal(t, "Third", tasks[0].Title)
 
-	// Sign in to get the token
-	req, err = http.NewRequest(http.MethodPost, "http://localhost:4000/signin", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
+	// Each task comes with its assignee and comment count, also when only those fields are selected
+	resp = doRequest(t, http.MethodPost, "/tasks/"+tasks[0].ID.Hex()+"/comments", map[string]string{"body": "Looks good"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/tasks?sort=title&fields=title,assignee,comment_count", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
 
-	resp, err := client.Do(req)
-	require.NoError(t, err)
+	var entries []models.TaskListEntry
+	decodeBody(t, resp, &entries)
+	require.Len(t, entries, 3)
+	require.NotNil(t, entries[2].Assignee)
+	require.Equal(t, user.ID, entries[2].Assignee.ID)
+	require.Equal(t, "testgettasks", entries[2].Assignee.Username)
+	require.Equal(t, 1, entries[2].CommentCount)
+	require.Equal(t, 0, entries[0].CommentCount)
+
+	// The summary list is served from the read model
+	resp = doRequest(t, http.MethodGet, "/tasks/summary", nil, token)
 	require.Equal(t, fiber.StatusOK, resp.StatusCode)
 
-	var tokenResp map[string]string
-	err = json.NewDecoder(resp.Body).Decode(&tokenResp)
-	require.NoError(t, err)
+	var summaries []models.TaskListView
+	decodeBody(t, resp, &summaries)
+	require.Len(t, summaries, 3)
+	require.Equal(t, "testgettasks", summaries[0].AssigneeName)
+}
 
-	token := tokenResp["token"]
+func TestUpdateTask(t *testing.T) {
+	user := createTestUser(t, "testupdatetask")
+	token := mintToken(t, user)
 
 	// Create a new task to update later
 	task := models.Task{
@@ -384,74 +465,88 @@ func TestUpdateTask(t *testing.T) {
 		Description: "This is a test task",
 		AllottedTo:  "testupdatetask",
 	}
-	body, _ = json.Marshal(task)
-
-	req, err = http.NewRequest(http.MethodPost, "http://localhost:4000/tasks", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", token)
 
-	resp, err = client.Do(req)
-	require.NoError(t, err)
+	resp := doRequest(t, http.MethodPost, "/tasks", task, token)
 	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
 
 	var createdTask models.Task
-	err = json.NewDecoder(resp.Body).Decode(&createdTask)
-	require.NoError(t, err)
+	decodeBody(t, resp, &createdTask)
 
 	// Update the created task
 	updatedTask := models.Task{
 		Title:       "Updated Test Task",
 		Description: "This is an updated test task",
 		AllottedTo:  "testupdatetask",
+		Version:     createdTask.Version,
 	}
-	body, _ = json.Marshal(updatedTask)
-
-	req, err = http.NewRequest(http.MethodPut, "http://localhost:4000/tasks/"+createdTask.ID.Hex(), bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", token)
 
-	resp, err = client.Do(req)
-	require.NoError(t, err)
+	resp = doRequest(t, http.MethodPut, "/tasks/"+createdTask.ID.Hex(), updatedTask, token)
 	require.Equal(t, fiber.StatusOK, resp.StatusCode)
 
 	var updatedTaskResponse models.Task
-	err = json.NewDecoder(resp.Body).Decode(&updatedTaskResponse)
-	require.NoError(t, err)
+	decodeBody(t, resp, &updatedTaskResponse)
 	require.Equal(t, updatedTask.Title, updatedTaskResponse.Title)
 	require.Equal(t, updatedTask.Description, updatedTaskResponse.Description)
-}
+	require.Equal(t, createdTask.Version+1, updatedTaskResponse.Version)
 
-func TestGetTask(t *testing.T) {
-	// Sign in to get a valid token
-	user := models.User{
-		Username: "testgettask",
-		Password: "testpassword",
-	}
-	body, _ := json.Marshal(user)
+	// Updating the stale version conflicts
+	resp = doRequest(t, http.MethodPut, "/tasks/"+createdTask.ID.Hex(), updatedTask, token)
+	require.Equal(t, fiber.StatusConflict, resp.StatusCode)
+}
 
-	// First, sign up the user
-	req, err := http.NewRequest(http.MethodPost, "http://localhost:4000/signup", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{Timeout: 10 * time.Second}
-	_, _ = client.Do(req)
+func TestPatchTask(t *testing.T) {
+	user := createTestUser(t, "testpatchtask")
+	token := mintToken(t, user)
 
-	// Sign in to get the token
-	req, err = http.NewRequest(http.MethodPost, "http://localhost:4000/signin", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
+	var created models.Task
+	resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Book taxi", AllottedTo: "testpatchtask"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &created)
 
-	resp, err := client.Do(req)
-	require.NoError(t, err)
+	// Someone else renames the task
+	resp = doRequest(t, http.MethodPatch, "/tasks/"+created.ID.Hex(), fiber.Map{"title": "Book taxi for 6am", "version": 1}, token)
 	require.Equal(t, fiber.StatusOK, resp.StatusCode)
 
-	var tokenResp map[string]string
-	err = json.NewDecoder(resp.Body).Decode(&tokenResp)
-	require.NoError(t, err)
+	// A stale patch keeps its other changes and reports the title as a conflict
+	resp = doRequest(t, http.MethodPatch, "/tasks/"+created.ID.Hex()+"?envelope=true", fiber.Map{"title": "Book cab", "priority": "high", "version": 1}, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.NotEmpty(t, resp.Header.Get(fiber.HeaderWarning))
+	var merged struct {
+		Data models.Task `json:"data"`
+		Meta struct {
+			Conflicts []struct {
+				Field string `json:"field"`
+			} `json:"conflicts"`
+		} `json:"meta"`
+	}
+	decodeBody(t, resp, &merged)
+	require.Equal(t, "Book taxi for 6am", merged.Data.Title)
+	require.Equal(t, models.TaskPriorityHigh, merged.Data.Priority)
+	require.Equal(t, 3, merged.Data.Version)
+	require.Len(t, merged.Meta.Conflicts, 1)
+	require.Equal(t, "title", merged.Meta.Conflicts[0].Field)
+
+	// A patch whose every field conflicts is rejected
+	resp = doRequest(t, http.MethodPatch, "/tasks/"+created.ID.Hex(), fiber.Map{"title": "Book cab", "version": 1}, token)
+	require.Equal(t, fiber.StatusConflict, resp.StatusCode)
+
+	// Fields that cannot be patched, and unknown assignees, are rejected
+	resp = doRequest(t, http.MethodPatch, "/tasks/"+created.ID.Hex(), fiber.Map{"rank": "0|a"}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodPatch, "/tasks/"+created.ID.Hex(), fiber.Map{"allotted_to": "nobody"}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	// PUT records its changes too, so stale patches of the fields it changed conflict
+	merged.Data.Description = "From the hotel"
+	resp = doRequest(t, http.MethodPut, "/tasks/"+created.ID.Hex(), merged.Data, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp = doRequest(t, http.MethodPatch, "/tasks/"+created.ID.Hex(), fiber.Map{"description": "From home", "version": 3}, token)
+	require.Equal(t, fiber.StatusConflict, resp.StatusCode)
+}
 
-	token := tokenResp["token"]
+func TestGetTask(t *testing.T) {
+	user := createTestUser(t, "testgettask")
+	token := mintToken(t, user)
 
 	// Create a new task with valid token
 	task := models.Task{
@@ -459,67 +554,31 @@ func TestGetTask(t *testing.T) {
 		Description: "This is a test task",
 		AllottedTo:  "testgettask",
 	}
-	body, _ = json.Marshal(task)
-
-	req, err = http.NewRequest(http.MethodPost, "http://localhost:4000/tasks", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", token)
 
-	resp, err = client.Do(req)
-	require.NoError(t, err)
+	resp := doRequest(t, http.MethodPost, "/tasks", task, token)
 	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
 
 	var createdTask models.Task
-	err = json.NewDecoder(resp.Body).Decode(&createdTask)
-	require.NoError(t, err)
+	decodeBody(t, resp, &createdTask)
 
 	// Get the created task by ID
-	req, err = http.NewRequest(http.MethodGet, "http://localhost:4000/tasks/"+createdTask.ID.Hex(), nil)
-	require.NoError(t, err)
-	req.Header.Set("Authorization", token)
-
-	resp, err = client.Do(req)
-	require.NoError(t, err)
+	resp = doRequest(t, http.MethodGet, "/tasks/"+createdTask.ID.Hex(), nil, token)
 	require.Equal(t, fiber.StatusOK, resp.StatusCode)
 
 	var fetchedTask models.Task
-	err = json.NewDecoder(resp.Body).Decode(&fetchedTask)
-	require.NoError(t, err)
+	decodeBody(t, resp, &fetchedTask)
 	require.Equal(t, createdTask.ID, fetchedTask.ID)
 	require.Equal(t, createdTask.Title, fetchedTask.Title)
 	require.Equal(t, createdTask.Description, fetchedTask.Description)
+
+	// Other users can't see the task
+	resp = doRequest(t, http.MethodGet, "/tasks/"+createdTask.ID.Hex(), nil, mintToken(t, createTestUser(t, "testgettask2")))
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
 }
 
 func TestDeleteTask(t *testing.T) {
-	// Sign in to get a valid token
-	user := models.User{
-		Username: "testdeletetask",
-		Password: "testpassword",
-	}
-	body, _ := json.Marshal(user)
-
-	// First, sign up the user
-	req, err := http.NewRequest(http.MethodPost, "http://localhost:4000/signup", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{Timeout: 10 * time.Second}
-	_, _ = client.Do(req)
-
-	// Sign in to get the token
-	req, err = http.NewRequest(http.MethodPost, "http://localhost:4000/signin", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := client.Do(req)
-	require.NoError(t, err)
-	require.Equal(t, fiber.StatusOK, resp.StatusCode)
-
-	var tokenResp map[string]string
-	err = json.NewDecoder(resp.Body).Decode(&tokenResp)
-	require.NoError(t, err)
-
-	token := tokenResp["token"]
+	user := createTestUser(t, "testdeletetask")
+	token := mintToken(t, user)
 
 	// Create a new task with valid token
 	task := models.Task{
@@ -527,77 +586,2050 @@ func TestDeleteTask(t *testing.T) {
 		Description: "This is a test task",
 		AllottedTo:  "testdeletetask",
 	}
-	body, _ = json.Marshal(task)
 
-	req, err = http.NewRequest(http.MethodPost, "http://localhost:4000/tasks", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", token)
-
-	resp, err = client.Do(req)
-	require.NoError(t, err)
+	resp := doRequest(t, http.MethodPost, "/tasks", task, token)
 	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
 
 	var createdTask models.Task
-	err = json.NewDecoder(resp.Body).Decode(&createdTask)
-	require.NoError(t, err)
+	decodeBody(t, resp, &createdTask)
 
 	// Delete the created task by ID
-	req, err = http.NewRequest(http.MethodDelete, "http://localhost:4000/tasks/"+createdTask.ID.Hex(), nil)
-	require.NoError(t, err)
-	req.Header.Set("Authorization", token)
-
-	resp, err = client.Do(req)
-	require.NoError(t, err)
+	resp = doRequest(t, http.MethodDelete, "/tasks/"+createdTask.ID.Hex(), nil, token)
 	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
 
 	// Verify the task was deleted
-	req, err = http.NewRequest(http.MethodGet, "http://localhost:4000/tasks/"+createdTask.ID.Hex(), nil)
-	require.NoError(t, err)
-	req.Header.Set("Authorization", token)
-
-	resp, err = client.Do(req)
-	require.NoError(t, err)
+	resp = doRequest(t, http.MethodGet, "/tasks/"+createdTask.ID.Hex(), nil, token)
 	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
 }
 
 func TestSignOut(t *testing.T) {
-	// Sign in to get a valid token
-	user := models.User{
-		Username: "testsignout",
-		Password: "testpassword",
+	user := createTestUser(t, "testsignout")
+	token := mintToken(t, user)
+
+	// Test signout with valid token
+	resp := doRequest(t, http.MethodPost, "/signout", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestNotificationSettings(t *testing.T) {
+	user := createTestUser(t, "testnotifications")
+	token := mintToken(t, user)
+
+	// No channels are configured initially
+	resp := doRequest(t, http.MethodGet, "/users/me/notifications", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var settings models.NotificationSettings
+	decodeBody(t, resp, &settings)
+	require.Empty(t, settings.SlackWebhookURL)
+
+	// Configure a Slack channel for assignments
+	update := models.NotificationSettings{
+		SlackWebhookURL: "https://hooks.slack.com/services/T0/B0/x",
+		Events:          []string{"task.assigned"},
 	}
-	body, _ := json.Marshal(user)
+	resp = doRequest(t, http.MethodPut, "/users/me/notifications", update, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
 
-	// First, sign up the user
-	req, err := http.NewRequest(http.MethodPost, "http://localhost:4000/signup", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{Timeout: 10 * time.Second}
-	_, _ = client.Do(req)
+	resp = doRequest(t, http.MethodGet, "/users/me/notifications", nil, token)
+	decodeBody(t, resp, &settings)
+	require.Equal(t, update, settings)
 
-	// Sign in to get the token
-	req, err = http.NewRequest(http.MethodPost, "http://localhost:4000/signin", bytes.NewBuffer(body))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
+	// Webhooks of other hosts are rejected
+	update.TeamsWebhookURL = "https://internal.example.com/hook"
+	resp = doRequest(t, http.MethodPut, "/users/me/notifications", update, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
 
-	resp, err := client.Do(req)
-	require.NoError(t, err)
+func TestDigestSettings(t *testing.T) {
+	user := createTestUser(t, "testdigest")
+	token := mintToken(t, user)
+
+	// Digests are off initially
+	resp := doRequest(t, http.MethodGet, "/users/me/digest", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var settings models.DigestSettings
+	decodeBody(t, resp, &settings)
+	require.Equal(t, models.DigestOff, settings.Frequency)
+
+	// A digest needs an email address
+	weekly := models.DigestSettings{Frequency: models.DigestWeekly, Hour: 8, Weekday: 1}
+	resp = doRequest(t, http.MethodPut, "/users/me/digest", weekly, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	user.Email = "digest@example.com"
+	require.NoError(t, repository.Users.Update(context.Background(), &user))
+	resp = doRequest(t, http.MethodPut, "/users/me/digest", weekly, token)
 	require.Equal(t, fiber.StatusOK, resp.StatusCode)
 
-	var tokenResp map[string]string
-	err = json.NewDecoder(resp.Body).Decode(&tokenResp)
-	require.NoError(t, err)
+	// Turning digests on starts the schedule now, so none is due before the next Monday 08:00
+	resp = doRequest(t, http.MethodGet, "/users/me/digest", nil, token)
+	decodeBody(t, resp, &settings)
+	require.Equal(t, models.DigestWeekly, settings.Frequency)
+	require.False(t, settings.LastSentAt.IsZero())
 
-	token := tokenResp["token"]
+	// Hours out of range are rejected
+	weekly.Hour = 24
+	resp = doRequest(t, http.MethodPut, "/users/me/digest", weekly, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
 
-	// Test signout with valid token
-	req, err = http.NewRequest(http.MethodPost, "http://localhost:4000/signout", nil)
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", token)
+func TestDomainEvents(t *testing.T) {
+	var published []events.Event
+	events.Default = events.NewBus()
+	events.Default.Subscribe("", func(ctx context.Context, event events.Event) {
+		published = append(published, event)
+	})
+	defer func() { events.Default = events.NewBus() }()
 
-	_, err = client.Do(req)
-	require.NoError(t, err)
+	// Signing up publishes user.registered without the password hash
+	resp := doRequest(t, http.MethodPost, "/signup", models.User{Username: "testevents", Password: "testpassword"}, "")
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	require.Len(t, published, 1)
+	require.Equal(t, events.UserRegistered, published[0].Type)
+	require.Empty(t, published[0].Data.(models.User).Password)
+
+	user := createTestUser(t, "testeventsowner")
+	token := mintToken(t, user)
+
+	// Creating a task publishes task.created
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Event task", AllottedTo: "testeventsowner"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var task models.Task
+	decodeBody(t, resp, &task)
+	require.Len(t, published, 2)
+	require.Equal(t, events.TaskCreated, published[1].Type)
+	require.Equal(t, task.ID.Hex(), published[1].Subject)
+
+	// Completing it publishes task.completed once
+	task.Status = models.TaskStatusDone
+	for i := 0; i < 2; i++ {
+		task.Version = 0
+		resp = doRequest(t, http.MethodPut, "/tasks/"+task.ID.Hex(), task, token)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	}
+	require.Len(t, published, 3)
+	require.Equal(t, events.TaskCompleted, published[2].Type)
+}
+
+func TestProjectBurndown(t *testing.T) {
+	user := createTestUser(t, "testburndown")
+	token := mintToken(t, user)
+
+	// Create a project for today
+	today := time.Now().UTC().Format(time.DateOnly)
+	resp := doRequest(t, http.MethodPost, "/projects", models.Project{Name: "Sprint 1"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var project models.Project
+	decodeBody(t, resp, &project)
+
+	// Tasks can only be added to existing projects of the user, with non-negative estimates
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Lost", AllottedTo: "testburndown", ProjectID: primitive.NewObjectID()}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Negative", AllottedTo: "testburndown", EstimateMinutes: -5}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	var tasks []models.Task
+	for _, estimate := range []int{60, 30} {
+		resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Work", AllottedTo: "testburndown", ProjectID: project.ID, EstimateMinutes: estimate}, token)
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		var task models.Task
+		decodeBody(t, resp, &task)
+		tasks = append(tasks, task)
+	}
+
+	// Completing a task stamps its completion; client values are ignored
+	tasks[0].Status = models.TaskStatusDone
+	tasks[0].ActualMinutes = 999
+	resp = doRequest(t, http.MethodPut, "/tasks/"+tasks[0].ID.Hex(), tasks[0], token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var done models.Task
+	decodeBody(t, resp, &done)
+	require.NotZero(t, done.CompletedAt)
+	require.Equal(t, 0, done.ActualMinutes)
+
+	resp = doRequest(t, http.MethodGet, "/projects/"+project.ID.Hex()+"/burndown", nil, token)
 	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var burndown planning.Burndown
+	decodeBody(t, resp, &burndown)
+	require.Equal(t, today, burndown.From)
+	require.Equal(t, 90, burndown.TotalEstimateMinutes)
+	require.Len(t, burndown.Days, 1)
+	require.Equal(t, 30, burndown.Days[0].RemainingMinutes)
+	require.Equal(t, 1, burndown.Days[0].RemainingTasks)
+	require.Equal(t, 60, burndown.Days[0].CompletedMinutes)
+
+	// Invalid ranges are rejected
+	resp = doRequest(t, http.MethodGet, "/projects/"+project.ID.Hex()+"/burndown?from=2024-02-01&to=2024-01-01", nil, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	// Projects of other users are not found
+	other := mintToken(t, createTestUser(t, "testburndownother"))
+	resp = doRequest(t, http.MethodGet, "/projects/"+project.ID.Hex()+"/burndown", nil, other)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestAssignmentRule(t *testing.T) {
+	user := createTestUser(t, "testassignowner")
+	createTestUser(t, "testassignone")
+	createTestUser(t, "testassigntwo")
+	token := mintToken(t, user)
+
+	resp := doRequest(t, http.MethodPost, "/projects", models.Project{Name: "Support queue"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var project models.Project
+	decodeBody(t, resp, &project)
+	rulePath := "/projects/" + project.ID.Hex() + "/assignment"
+
+	// Rules need a known strategy and existing members
+	for _, rule := range []fiber.Map{
+		{"strategy": "random", "members": []string{"testassignone"}},
+		{"strategy": models.AssignmentRoundRobin, "members": []string{}},
+		{"strategy": models.AssignmentRoundRobin, "members": []string{"testassignone", "testassignone"}},
+		{"strategy": models.AssignmentRoundRobin, "members": []string{"testassignone", "nobody-here"}},
+	} {
+		resp = doRequest(t, http.MethodPut, rulePath, rule, token)
+		require.Equal(t, fiber.StatusBadRequest, resp.StatusCode, rule)
+	}
+
+	// Round robin hands out the tasks without assignee in turn and leaves explicit assignees alone
+	resp = doRequest(t, http.MethodPut, rulePath, fiber.Map{"strategy": models.AssignmentRoundRobin, "members": []string{"testassignone", "testassigntwo"}}, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &project)
+	require.Equal(t, models.AssignmentRoundRobin, project.Assignment.Strategy)
+
+	create := func(allottedTo string) string {
+		var task models.Task
+		resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Ticket", AllottedTo: allottedTo, ProjectID: project.ID}, token)
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		decodeBody(t, resp, &task)
+		return task.AllottedTo
+	}
+	require.Equal(t, "testassignone", create(""))
+	require.Equal(t, "testassigntwo", create(""))
+	require.Equal(t, "testassignowner", create("testassignowner"))
+	require.Equal(t, "testassignone", create(""))
+
+	// A task that is rejected gives its turn back
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Ticket", Priority: "someday", ProjectID: project.ID}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	require.Equal(t, "testassigntwo", create(""))
+
+	// Least loaded picks the member with fewer open tasks, the first one of a tie
+	resp = doRequest(t, http.MethodPut, rulePath, fiber.Map{"strategy": models.AssignmentLeastLoaded, "members": []string{"testassignone", "testassigntwo"}}, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "testassignone", create(""))
+	require.Equal(t, "testassigntwo", create(""))
+
+	// Without a rule a task needs an assignee again
+	resp = doRequest(t, http.MethodDelete, rulePath, nil, token)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Ticket", ProjectID: project.ID}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp = doRequest(t, http.MethodPut, "/projects/"+primitive.NewObjectID().Hex()+"/assignment", fiber.Map{"strategy": models.AssignmentLeastLoaded, "members": []string{"testassignone"}}, token)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestProjectGantt(t *testing.T) {
+	user := createTestUser(t, "testgantt")
+	token := mintToken(t, user)
+
+	start := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	resp := doRequest(t, http.MethodPost, "/projects", models.Project{Name: "Launch", StartDate: primitive.NewDateTimeFromTime(start)}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var project models.Project
+	decodeBody(t, resp, &project)
+
+	create := func(task models.Task) models.Task {
+		task.AllottedTo, task.ProjectID = "testgantt", project.ID
+		resp := doRequest(t, http.MethodPost, "/tasks", task, token)
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		var created models.Task
+		decodeBody(t, resp, &created)
+		return created
+	}
+	design := create(models.Task{Title: "Design", EstimateMinutes: 240})
+	build := create(models.Task{Title: "Build", EstimateMinutes: 600, DependsOn: []primitive.ObjectID{design.ID}})
+
+	// Dependencies on the task itself, on tasks of other projects and closing a cycle are rejected
+	design.DependsOn = []primitive.ObjectID{design.ID}
+	resp = doRequest(t, http.MethodPut, "/tasks/"+design.ID.Hex(), design, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	design.DependsOn = []primitive.ObjectID{build.ID}
+	resp = doRequest(t, http.MethodPut, "/tasks/"+design.ID.Hex(), design, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Loose", AllottedTo: "testgantt", DependsOn: []primitive.ObjectID{design.ID}}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	// Build starts when Design ends, four hours after the project starts
+	resp = doRequest(t, http.MethodGet, "/projects/"+project.ID.Hex()+"/gantt", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var gantt planning.Gantt
+	decodeBody(t, resp, &gantt)
+	require.Len(t, gantt.Tasks, 2)
+	require.Equal(t, "Build", gantt.Tasks[1].Title)
+	require.Equal(t, []primitive.ObjectID{design.ID}, gantt.Tasks[1].DependsOn)
+	require.Equal(t, models.NewTimestamp(start.Add(4*time.Hour)), gantt.Tasks[1].EarliestStart)
+	require.Equal(t, models.NewTimestamp(start.Add(14*time.Hour)), gantt.Finish)
+
+	// Projects of other users are not found
+	other := mintToken(t, createTestUser(t, "testganttother"))
+	resp = doRequest(t, http.MethodGet, "/projects/"+project.ID.Hex()+"/gantt", nil, other)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestCriticalPath(t *testing.T) {
+	cache.Init(100, time.Minute)
+	defer cache.Init(0, 0)
+	user := createTestUser(t, "testcritical")
+	token := mintToken(t, user)
+
+	resp := doRequest(t, http.MethodPost, "/projects", models.Project{Name: "Critical"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var project models.Project
+	decodeBody(t, resp, &project)
+
+	create := func(task models.Task) models.Task {
+		task.AllottedTo, task.ProjectID = "testcritical", project.ID
+		resp := doRequest(t, http.MethodPost, "/tasks", task, token)
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		var created models.Task
+		decodeBody(t, resp, &created)
+		return created
+	}
+	short := create(models.Task{Title: "Short", EstimateMinutes: 60})
+	long := create(models.Task{Title: "Long", EstimateMinutes: 240})
+	last := create(models.Task{Title: "Last", EstimateMinutes: 30, DependsOn: []primitive.ObjectID{short.ID, long.ID}})
+
+	getPath := func() planning.CriticalPath {
+		resp := doRequest(t, http.MethodGet, "/projects/"+project.ID.Hex()+"/critical-path", nil, token)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var path planning.CriticalPath
+		decodeBody(t, resp, &path)
+		return path
+	}
+	path := getPath()
+	require.Equal(t, []primitive.ObjectID{long.ID, last.ID}, path.Path)
+
+	// Changing an estimate moves the critical path on the next request
+	short.EstimateMinutes = 480
+	resp = doRequest(t, http.MethodPut, "/tasks/"+short.ID.Hex(), short, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	path = getPath()
+	require.Equal(t, []primitive.ObjectID{short.ID, last.ID}, path.Path)
+	require.Equal(t, 480+30, path.DurationMinutes)
+}
+
+func TestWorkload(t *testing.T) {
+	user := createTestUser(t, "testworkload")
+	createTestUser(t, "testworkloadpeer")
+	token := mintToken(t, user)
+
+	// Plan next week: 30 hours for the user and 2 for the peer
+	due := time.Now().AddDate(0, 0, 7)
+	for _, task := range []models.Task{
+		{Title: "Migrate", AllottedTo: "testworkload", EstimateMinutes: 1200, EndDate: models.NewTimestamp(due)},
+		{Title: "Review", AllottedTo: "testworkload", EstimateMinutes: 600, EndDate: models.NewTimestamp(due)},
+		{Title: "Triage", AllottedTo: "testworkloadpeer", EstimateMinutes: 120, EndDate: models.NewTimestamp(due)},
+	} {
+		resp := doRequest(t, http.MethodPost, "/tasks", task, token)
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	}
+
+	resp := doRequest(t, http.MethodGet, "/reports/workload?capacity_hours=24&week="+due.UTC().Format(time.DateOnly), nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var workload planning.Workload
+	decodeBody(t, resp, &workload)
+	require.Equal(t, 24*60, workload.CapacityMinutes)
+	require.Len(t, workload.Assignees, 2)
+	require.Equal(t, "testworkload", workload.Assignees[0].Assignee)
+	require.Equal(t, 1800, workload.Assignees[0].EstimateMinutes)
+	require.True(t, workload.Assignees[0].Overloaded)
+	require.Equal(t, "testworkloadpeer", workload.Assignees[1].Assignee)
+	require.False(t, workload.Assignees[1].Overloaded)
+
+	// Tasks of other users are not included
+	other := mintToken(t, createTestUser(t, "testworkloadother"))
+	resp = doRequest(t, http.MethodGet, "/reports/workload", nil, other)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &workload)
+	require.Empty(t, workload.Assignees)
+
+	// Invalid weeks and capacities are rejected
+	resp = doRequest(t, http.MethodGet, "/reports/workload?week=2024-W60", nil, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/reports/workload?capacity_hours=0", nil, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestTasksReport(t *testing.T) {
+	user := createTestUser(t, "testreport")
+	token := mintToken(t, user)
+
+	resp := doRequest(t, http.MethodPost, "/projects", models.Project{Name: "Launch (Q3)"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var project models.Project
+	decodeBody(t, resp, &project)
+	for _, task := range []models.Task{
+		{Title: "Write announcement", AllottedTo: "testreport", ProjectID: project.ID},
+		{Title: "Book venue", AllottedTo: "testreport"},
+	} {
+		resp = doRequest(t, http.MethodPost, "/tasks", task, token)
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	}
+
+	getReport := func(path string) string {
+		resp := doRequest(t, http.MethodGet, path, nil, token)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/pdf", resp.Header.Get(fiber.HeaderContentType))
+		require.Contains(t, resp.Header.Get(fiber.HeaderContentDisposition), "attachment")
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(body), "%PDF-"))
+		return pdfText(t, body)
+	}
+
+	report := getReport("/reports/tasks.pdf?status=Pending&allotted_to=testreport")
+	require.Contains(t, report, "Status: Pending; Assignee: testreport")
+	require.Contains(t, report, "Write announcement")
+	require.Contains(t, report, "Book venue")
+
+	report = getReport("/projects/" + project.ID.Hex() + "/report.pdf")
+	require.Contains(t, report, "Project report: Launch (Q3)")
+	require.Contains(t, report, "Write announcement")
+	require.NotContains(t, report, "Book venue")
+
+	// Invalid filters are rejected
+	resp = doRequest(t, http.MethodGet, "/reports/tasks.pdf?overdue=maybe", nil, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/reports/tasks.pdf?project_id=nope", nil, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestReportSchedules(t *testing.T) {
+	user := createTestUser(t, "testschedules")
+	token := mintToken(t, user)
+
+	// Without an email address on the account, a schedule needs recipients
+	body := fiber.Map{"name": "Weekly status", "schedule": "0 9 * * mon", "filter": fiber.Map{"statuses": []string{"Pending"}}}
+	resp := doRequest(t, http.MethodPost, "/report-schedules", body, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	body["recipients"] = []string{"lead@example.com"}
+	resp = doRequest(t, http.MethodPost, "/report-schedules", body, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var created models.ReportSchedule
+	decodeBody(t, resp, &created)
+	require.Equal(t, models.ReportFormatPDF, created.Format)
+	require.Equal(t, time.Monday, created.NextRunAt.Time().UTC().Weekday())
+	require.True(t, created.NextRunAt.Time().After(time.Now()))
+
+	// Schedules firing more than hourly and unknown projects are rejected
+	for _, invalid := range []fiber.Map{
+		{"name": "Spam", "schedule": "* * * * *", "recipients": []string{"lead@example.com"}},
+		{"name": "Elsewhere", "schedule": "@daily", "recipients": []string{"lead@example.com"}, "filter": fiber.Map{"project_id": primitive.NewObjectID()}},
+	} {
+		resp = doRequest(t, http.MethodPost, "/report-schedules", invalid, token)
+		require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	}
+
+	body["format"], body["schedule"] = "csv", "@daily"
+	resp = doRequest(t, http.MethodPut, "/report-schedules/"+created.ID.Hex(), body, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var updated models.ReportSchedule
+	decodeBody(t, resp, &updated)
+	require.Equal(t, models.ReportFormatCSV, updated.Format)
+	require.Equal(t, 0, updated.NextRunAt.Time().UTC().Hour())
+
+	resp = doRequest(t, http.MethodGet, "/report-schedules", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var list []models.ReportSchedule
+	decodeBody(t, resp, &list)
+	require.Len(t, list, 1)
+	require.Equal(t, "@daily", list[0].Schedule)
+
+	// Other users cannot see the schedule
+	other := mintToken(t, createTestUser(t, "testschedulesother"))
+	resp = doRequest(t, http.MethodGet, "/report-schedules/"+created.ID.Hex(), nil, other)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	resp = doRequest(t, http.MethodDelete, "/report-schedules/"+created.ID.Hex(), nil, token)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/report-schedules/"+created.ID.Hex(), nil, token)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestBoard(t *testing.T) {
+	user := createTestUser(t, "testboard")
+	token := mintToken(t, user)
+
+	resp := doRequest(t, http.MethodPost, "/projects", models.Project{Name: "Board"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var project models.Project
+	decodeBody(t, resp, &project)
+
+	var ids []string
+	for _, title := range []string{"A", "B", "C"} {
+		resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: title, AllottedTo: "testboard", ProjectID: project.ID}, token)
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		var task models.Task
+		decodeBody(t, resp, &task)
+		ids = append(ids, task.ID.Hex())
+	}
+
+	type board struct {
+		Columns []planning.Column `json:"columns"`
+	}
+	titles := func(column planning.Column) []string {
+		result := []string{}
+		for _, task := range column.Tasks {
+			result = append(result, task.Title)
+		}
+		return result
+	}
+
+	// New cards are listed in creation order
+	resp = doRequest(t, http.MethodGet, "/boards/"+project.ID.Hex(), nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var current board
+	decodeBody(t, resp, &current)
+	require.Len(t, current.Columns, 3)
+	require.Equal(t, []string{"A", "B", "C"}, titles(current.Columns[0]))
+
+	// Move C to the top of Pending, then B to In Progress
+	resp = doRequest(t, http.MethodPost, "/boards/"+project.ID.Hex()+"/move", fiber.Map{"task_id": ids[2], "before_id": ids[0]}, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/boards/"+project.ID.Hex()+"/move", fiber.Map{"task_id": ids[1], "status": models.TaskStatusInProgress}, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	// The order is persisted
+	resp = doRequest(t, http.MethodGet, "/boards/"+project.ID.Hex(), nil, token)
+	decodeBody(t, resp, &current)
+	require.Equal(t, []string{"C", "A"}, titles(current.Columns[0]))
+	require.Equal(t, []string{"B"}, titles(current.Columns[1]))
+	require.NotZero(t, current.Columns[1].Tasks[0].StartedAt)
+
+	// Unknown columns and neighbours from other columns are rejected
+	resp = doRequest(t, http.MethodPost, "/boards/"+project.ID.Hex()+"/move", fiber.Map{"task_id": ids[0], "status": "Someday"}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/boards/"+project.ID.Hex()+"/move", fiber.Map{"task_id": ids[0], "after_id": ids[1]}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestOverdueTasks(t *testing.T) {
+	user := createTestUser(t, "testoverdue")
+	token := mintToken(t, user)
+
+	// One task is already late, one is due later
+	late := models.Task{Title: "Late", AllottedTo: "testoverdue", EndDate: models.NewTimestamp(time.Now().Add(-time.Hour))}
+	resp := doRequest(t, http.MethodPost, "/tasks", late, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &late)
+	upcoming := models.Task{Title: "Upcoming", AllottedTo: "testoverdue", EndDate: models.NewTimestamp(time.Now().Add(time.Hour))}
+	resp = doRequest(t, http.MethodPost, "/tasks", upcoming, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	_, err := jobs.MarkOverdue(context.Background(), time.Now())
+	require.NoError(t, err)
+
+	var tasks []models.Task
+	resp = doRequest(t, http.MethodGet, "/tasks?overdue=true", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &tasks)
+	require.Len(t, tasks, 1)
+	require.Equal(t, "Late", tasks[0].Title)
+	require.True(t, tasks[0].Overdue)
+
+	resp = doRequest(t, http.MethodGet, "/tasks?overdue=false", nil, token)
+	decodeBody(t, resp, &tasks)
+	require.Len(t, tasks, 1)
+	require.Equal(t, "Upcoming", tasks[0].Title)
+
+	// Completing the task clears the flag
+	var current models.Task
+	resp = doRequest(t, http.MethodGet, "/tasks/"+late.ID.Hex(), nil, token)
+	decodeBody(t, resp, &current)
+	current.Status = models.TaskStatusDone
+	resp = doRequest(t, http.MethodPut, "/tasks/"+late.ID.Hex(), current, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &current)
+	require.False(t, current.Overdue)
+
+	resp = doRequest(t, http.MethodGet, "/tasks?overdue=maybe", nil, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestSnoozeTask(t *testing.T) {
+	user := createTestUser(t, "testsnooze")
+	token := mintToken(t, user)
+
+	task := models.Task{Title: "Late", AllottedTo: "testsnooze", EndDate: models.NewTimestamp(time.Now().Add(-time.Hour))}
+	resp := doRequest(t, http.MethodPost, "/tasks", task, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &task)
+	_, err := jobs.MarkOverdue(context.Background(), time.Now())
+	require.NoError(t, err)
+
+	// A late task is pushed from now and is no longer overdue
+	resp = doRequest(t, http.MethodPost, "/tasks/"+task.ID.Hex()+"/snooze", fiber.Map{"duration": "24h", "reason": "Waiting on review"}, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var snoozed models.Task
+	decodeBody(t, resp, &snoozed)
+	require.False(t, snoozed.Overdue)
+	require.WithinDuration(t, time.Now().Add(24*time.Hour), snoozed.EndDate.Time(), time.Minute)
+	require.Equal(t, primitive.DateTime(snoozed.EndDate), snoozed.SnoozedUntil)
+	require.Len(t, snoozed.Snoozes, 1)
+	require.Equal(t, "testsnooze", snoozed.Snoozes[0].By)
+	require.Equal(t, primitive.DateTime(task.EndDate), snoozed.Snoozes[0].From)
+	require.Equal(t, "Waiting on review", snoozed.Snoozes[0].Reason)
+
+	// Snoozing again counts from the new end date and keeps the history
+	resp = doRequest(t, http.MethodPost, "/tasks/"+task.ID.Hex()+"/snooze", fiber.Map{"duration": "1h"}, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var again models.Task
+	decodeBody(t, resp, &again)
+	require.Equal(t, snoozed.EndDate.Time().Add(time.Hour), again.EndDate.Time())
+	require.Len(t, again.Snoozes, 2)
+
+	// Updates keep the history
+	again.Title = "Renamed"
+	resp = doRequest(t, http.MethodPut, "/tasks/"+task.ID.Hex(), again, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var updated models.Task
+	decodeBody(t, resp, &updated)
+	require.Len(t, updated.Snoozes, 2)
+
+	resp = doRequest(t, http.MethodPost, "/tasks/"+task.ID.Hex()+"/snooze", fiber.Map{"duration": "-1h"}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/tasks/"+task.ID.Hex()+"/snooze", fiber.Map{"duration": "tomorrow"}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	// Done tasks cannot be snoozed
+	updated.Status = models.TaskStatusDone
+	resp = doRequest(t, http.MethodPut, "/tasks/"+task.ID.Hex(), updated, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/tasks/"+task.ID.Hex()+"/snooze", fiber.Map{"duration": "1h"}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestTaskDuePhrase(t *testing.T) {
+	user := models.User{Username: "testdue", Password: "not-a-hash", Timezone: "Asia/Kolkata"}
+	require.NoError(t, repository.Users.Create(context.Background(), &user))
+	token := mintToken(t, user)
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+
+	// The phrase is read in the user's timezone
+	resp := doRequest(t, http.MethodPost, "/tasks", fiber.Map{"title": "Report", "allotted_to": "testdue", "due": "tomorrow 9am"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var task models.Task
+	decodeBody(t, resp, &task)
+	tomorrow := time.Now().In(kolkata).AddDate(0, 0, 1)
+	expected := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 9, 0, 0, 0, kolkata)
+	require.True(t, expected.Equal(task.EndDate.Time()), "end_time %s", task.EndDate.Time())
+	require.Empty(t, task.Due)
+
+	// Updates accept phrases too and reject what they cannot parse
+	task.Due = "in 2 days"
+	resp = doRequest(t, http.MethodPut, "/tasks/"+task.ID.Hex(), task, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var updated models.Task
+	decodeBody(t, resp, &updated)
+	require.WithinDuration(t, time.Now().Add(48*time.Hour), updated.EndDate.Time(), time.Minute)
+
+	updated.Due = "whenever"
+	resp = doRequest(t, http.MethodPut, "/tasks/"+task.ID.Hex(), updated, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestTimezone(t *testing.T) {
+	user := createTestUser(t, "testtimezone")
+	token := mintToken(t, user)
+
+	var settings map[string]string
+	resp := doRequest(t, http.MethodGet, "/users/me/timezone", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &settings)
+	require.Equal(t, "UTC", settings["timezone"])
+
+	resp = doRequest(t, http.MethodPut, "/users/me/timezone", fiber.Map{"timezone": "Asia/Kolkata"}, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp = doRequest(t, http.MethodPut, "/users/me/timezone", fiber.Map{"timezone": "Nowhere/Special"}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	// Dates without an offset are read in the user's timezone and returned as RFC3339 in UTC
+	resp = doRequest(t, http.MethodPost, "/tasks", fiber.Map{"title": "Local", "allotted_to": "testtimezone", "end_time": "2030-07-05T17:00"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var raw map[string]interface{}
+	decodeBody(t, resp, &raw)
+	require.Equal(t, "2030-07-05T11:30:00Z", raw["end_time"])
+
+	// Dates with an offset are kept as sent
+	resp = doRequest(t, http.MethodPost, "/tasks", fiber.Map{"title": "Offset", "allotted_to": "testtimezone", "end_time": "2030-07-05T17:00:00Z"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &raw)
+	require.Equal(t, "2030-07-05T17:00:00Z", raw["end_time"])
+
+	resp = doRequest(t, http.MethodPost, "/tasks", fiber.Map{"title": "Bad", "allotted_to": "testtimezone", "end_time": "05/07/2030"}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestTaskTimestamps(t *testing.T) {
+	user := createTestUser(t, "testtimestamps")
+	token := mintToken(t, user)
+
+	var first, second models.Task
+	resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "First", AllottedTo: "testtimestamps"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &first)
+	require.False(t, first.CreatedAt.IsZero())
+	require.Equal(t, first.CreatedAt, first.UpdatedAt)
+	time.Sleep(2 * time.Millisecond)
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Second", AllottedTo: "testtimestamps"}, token)
+	decodeBody(t, resp, &second)
+
+	// Updating the first task moves its updated_at but not its created_at
+	time.Sleep(2 * time.Millisecond)
+	first.Description = "Changed"
+	first.CreatedAt = 0
+	resp = doRequest(t, http.MethodPut, "/tasks/"+first.ID.Hex(), first, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var updated models.Task
+	decodeBody(t, resp, &updated)
+	require.Less(t, int64(updated.CreatedAt), int64(second.CreatedAt))
+	require.Greater(t, int64(updated.UpdatedAt), int64(second.UpdatedAt))
+
+	var tasks []models.Task
+	resp = doRequest(t, http.MethodGet, "/tasks?sort=-updated_at", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &tasks)
+	require.Len(t, tasks, 2)
+	require.Equal(t, "First", tasks[0].Title)
+
+	resp = doRequest(t, http.MethodGet, "/tasks?created_after="+url.QueryEscape(second.CreatedAt.Time().Format(time.RFC3339Nano)), nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &tasks)
+	require.Len(t, tasks, 1)
+	require.Equal(t, "Second", tasks[0].Title)
+
+	resp = doRequest(t, http.MethodGet, "/tasks?updated_before=yesterday", nil, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestHypermediaLinks(t *testing.T) {
+	user := createTestUser(t, "testlinks")
+	token := mintToken(t, user)
+
+	var task models.Task
+	for _, title := range []string{"First", "Second"} {
+		resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: title, AllottedTo: "testlinks"}, token)
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		decodeBody(t, resp, &task)
+	}
+
+	// Bare responses have no links
+	var raw map[string]interface{}
+	resp := doRequest(t, http.MethodGet, "/tasks/"+task.ID.Hex(), nil, token)
+	decodeBody(t, resp, &raw)
+	require.NotContains(t, raw, "_links")
+
+	// The envelope links each task to itself and its actions
+	var single struct {
+		Data struct {
+			Title string         `json:"title"`
+			Links response.Links `json:"_links"`
+		} `json:"data"`
+	}
+	resp = doRequest(t, http.MethodGet, "/tasks/"+task.ID.Hex()+"?envelope=true", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &single)
+	require.Equal(t, "Second", single.Data.Title)
+	require.Equal(t, response.Link{Href: "/tasks/" + task.ID.Hex()}, single.Data.Links["self"])
+	require.Equal(t, response.Link{Href: "/tasks/" + task.ID.Hex(), Method: http.MethodDelete}, single.Data.Links["delete"])
+
+	// Lists link to their next page
+	var list struct {
+		Data  []map[string]interface{} `json:"data"`
+		Links response.Links           `json:"_links"`
+	}
+	resp = doRequest(t, http.MethodGet, "/tasks?envelope=true&limit=1", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	next := resp.Header.Get("X-Next-Cursor")
+	decodeBody(t, resp, &list)
+	require.Len(t, list.Data, 1)
+	require.Contains(t, list.Data[0], "_links")
+	require.Equal(t, "/tasks?envelope=true&limit=1", list.Links["self"].Href)
+	require.Equal(t, "/tasks?cursor="+next+"&envelope=true&limit=1", list.Links["next"].Href)
+
+	resp = doRequest(t, http.MethodGet, list.Links["next"].Href, nil, token)
+	list.Links = nil
+	decodeBody(t, resp, &list)
+	require.Len(t, list.Data, 1)
+	require.NotContains(t, list.Links, "next")
+}
+
+func TestFieldSelection(t *testing.T) {
+	user := createTestUser(t, "testfields")
+	token := mintToken(t, user)
+
+	var task models.Task
+	resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Slim", Description: "Long text", AllottedTo: "testfields"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &task)
+
+	// Lists and single tasks return only the id and the selected fields
+	var tasks []map[string]interface{}
+	resp = doRequest(t, http.MethodGet, "/tasks?fields=title,status", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &tasks)
+	require.Len(t, tasks, 1)
+	require.Equal(t, map[string]interface{}{"id": task.ID.Hex(), "title": "Slim", "status": models.TaskStatusPending}, tasks[0])
+
+	var single map[string]interface{}
+	resp = doRequest(t, http.MethodGet, "/tasks/"+task.ID.Hex()+"?fields=end_time", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &single)
+	require.Len(t, single, 2)
+	require.Contains(t, single, "end_time")
+
+	// The envelope keeps the links of each task
+	var enveloped struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	resp = doRequest(t, http.MethodGet, "/tasks?fields=title&envelope=true", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &enveloped)
+	require.Len(t, enveloped.Data, 1)
+	require.Contains(t, enveloped.Data[0], "_links")
+	require.NotContains(t, enveloped.Data[0], "description")
+
+	resp = doRequest(t, http.MethodGet, "/tasks?fields=title,password", nil, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestConditionalGet(t *testing.T) {
+	user := createTestUser(t, "testconditional")
+	token := mintToken(t, user)
+
+	var task models.Task
+	resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Polled", AllottedTo: "testconditional"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &task)
+
+	get := func(header, value string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, "/tasks/"+task.ID.Hex(), nil)
+		req.Header.Set("Authorization", token)
+		req.Header.Set(header, value)
+		resp, err := testApp.Test(req, -1)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp = doRequest(t, http.MethodGet, "/tasks/"+task.ID.Hex(), nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	lastModified := resp.Header.Get(fiber.HeaderLastModified)
+	require.NotEmpty(t, lastModified)
+
+	// Unchanged tasks are not sent again
+	require.Equal(t, fiber.StatusNotModified, get(fiber.HeaderIfModifiedSince, lastModified).StatusCode)
+	require.Equal(t, fiber.StatusNotModified, get(fiber.HeaderIfNoneMatch, `"1"`).StatusCode)
+	require.Equal(t, fiber.StatusOK, get(fiber.HeaderIfModifiedSince, "not a date").StatusCode)
+
+	// An update makes the task modified again
+	time.Sleep(time.Second)
+	task.Title = "Changed"
+	resp = doRequest(t, http.MethodPut, "/tasks/"+task.ID.Hex(), task, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, fiber.StatusOK, get(fiber.HeaderIfModifiedSince, lastModified).StatusCode)
+	require.Equal(t, fiber.StatusOK, get(fiber.HeaderIfNoneMatch, `"1"`).StatusCode)
+}
+
+func TestBatchGetTasks(t *testing.T) {
+	user := createTestUser(t, "testbatch")
+	token := mintToken(t, user)
+	other := createTestUser(t, "testbatchother")
+
+	var task models.Task
+	resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Mine", AllottedTo: "testbatch"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &task)
+	var foreign models.Task
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Theirs", AllottedTo: "testbatchother"}, mintToken(t, other))
+	decodeBody(t, resp, &foreign)
+
+	// Results follow the requested order; other users' and missing tasks are not found
+	missing := primitive.NewObjectID().Hex()
+	var batch struct {
+		Results []struct {
+			ID    string                 `json:"id"`
+			Found bool                   `json:"found"`
+			Task  map[string]interface{} `json:"task"`
+		} `json:"results"`
+	}
+	body := fiber.Map{"ids": []string{missing, task.ID.Hex(), foreign.ID.Hex()}}
+	resp = doRequest(t, http.MethodPost, "/tasks/batch-get?fields=title", body, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &batch)
+	require.Len(t, batch.Results, 3)
+	require.Equal(t, missing, batch.Results[0].ID)
+	require.False(t, batch.Results[0].Found)
+	require.True(t, batch.Results[1].Found)
+	require.Equal(t, map[string]interface{}{"id": task.ID.Hex(), "title": "Mine"}, batch.Results[1].Task)
+	require.False(t, batch.Results[2].Found)
+	require.Nil(t, batch.Results[2].Task)
+
+	resp = doRequest(t, http.MethodPost, "/tasks/batch-get", fiber.Map{"ids": []string{"nope"}}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/tasks/batch-get", fiber.Map{"ids": []string{}}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestTransitionTasks(t *testing.T) {
+	user := createTestUser(t, "testtransition")
+	token := mintToken(t, user)
+
+	ids := make([]string, 3)
+	for i, title := range []string{"Draft", "Review", "Publish"} {
+		var task models.Task
+		resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: title, AllottedTo: "testtransition"}, token)
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		decodeBody(t, resp, &task)
+		ids[i] = task.ID.Hex()
+	}
+	resp := doRequest(t, http.MethodPost, "/tasks/bulk/transition", fiber.Map{"ids": ids[2:], "status": models.TaskStatusDone}, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	type report struct {
+		Results []struct {
+			ID     string       `json:"id"`
+			Status string       `json:"status"`
+			From   string       `json:"from"`
+			Task   *models.Task `json:"task"`
+			Error  *struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		} `json:"results"`
+		Applied  int `json:"applied"`
+		Valid    int `json:"valid"`
+		Rejected int `json:"rejected"`
+	}
+
+	// A dry run reports without writing
+	var dry report
+	body := fiber.Map{"ids": []string{ids[0], ids[2], "nope"}, "status": models.TaskStatusPending, "dry_run": true}
+	resp = doRequest(t, http.MethodPost, "/tasks/bulk/transition", body, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &dry)
+	require.Equal(t, 0, dry.Valid)
+	require.Equal(t, 3, dry.Rejected)
+	require.Equal(t, "conflict", dry.Results[0].Error.Code)
+	require.Equal(t, "unprocessable", dry.Results[1].Error.Code)
+	require.Equal(t, models.TaskStatusDone, dry.Results[1].From)
+	require.Equal(t, "invalid_id", dry.Results[2].Error.Code)
+
+	// Each task is moved on its own, with the reason of each rejection
+	var moved report
+	body = fiber.Map{"ids": []string{ids[0], ids[1], ids[2], primitive.NewObjectID().Hex()}, "status": models.TaskStatusInProgress}
+	resp = doRequest(t, http.MethodPost, "/tasks/bulk/transition", body, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &moved)
+	require.Equal(t, 3, moved.Applied)
+	require.Equal(t, 1, moved.Rejected)
+	require.Equal(t, "applied", moved.Results[0].Status)
+	require.Equal(t, models.TaskStatusInProgress, moved.Results[0].Task.Status)
+	require.Equal(t, 2, moved.Results[0].Task.Version)
+	require.Equal(t, models.TaskStatusDone, moved.Results[2].From)
+	require.Equal(t, "not_found", moved.Results[3].Error.Code)
+
+	resp = doRequest(t, http.MethodPost, "/tasks/bulk/transition", fiber.Map{"ids": ids, "status": "Archived"}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/tasks/bulk/transition", fiber.Map{"ids": []string{}, "status": models.TaskStatusDone}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestPatchTasksByFilter(t *testing.T) {
+	user := createTestUser(t, "testbulkpatch")
+	createTestUser(t, "testbulkleaving")
+	createTestUser(t, "testbulktaking")
+	token := mintToken(t, user)
+
+	ids := []primitive.ObjectID{}
+	for _, title := range []string{"Open one", "Open two", "Finished", "Own"} {
+		allottedTo := "testbulkleaving"
+		if title == "Own" {
+			allottedTo = "testbulkpatch"
+		}
+		var task models.Task
+		resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: title, AllottedTo: allottedTo}, token)
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		decodeBody(t, resp, &task)
+		ids = append(ids, task.ID)
+	}
+	resp := doRequest(t, http.MethodPatch, "/tasks/"+ids[2].Hex(), fiber.Map{"status": models.TaskStatusDone}, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var outcome struct {
+		Matched  int                  `json:"matched"`
+		Updated  int                  `json:"updated"`
+		Rejected []bulkPatchRejection `json:"rejected"`
+	}
+	filter := "/tasks?filter=" + url.QueryEscape("allotted_to:testbulkleaving,status:open")
+
+	// A dry run only counts the matching tasks
+	resp = doRequest(t, http.MethodPatch, filter+"&dry_run=true", fiber.Map{"allotted_to": "testbulktaking"}, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &outcome)
+	require.Equal(t, 2, outcome.Matched)
+	require.Zero(t, outcome.Updated)
+
+	// The open tasks of the departing user are reassigned, the finished one and the others are not
+	resp = doRequest(t, http.MethodPatch, filter, fiber.Map{"allotted_to": "testbulktaking", "priority": "high"}, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &outcome)
+	require.Equal(t, 2, outcome.Matched)
+	require.Equal(t, 2, outcome.Updated)
+	require.Empty(t, outcome.Rejected)
+
+	// The update is recorded in the audit log without an audit sink, the dry run is not
+	entries, err := repository.AuditLog.FindByUser(context.Background(), user.ID.Hex())
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, http.MethodPatch, entries[0].Method)
+	require.Equal(t, fiber.StatusOK, entries[0].Status)
+	require.Equal(t, fiber.Map{"filter": "allotted_to:testbulkleaving,status:open", "fields": []string{"allotted_to", "priority"},
+		"matched": 2, "updated": 2, "rejected": 0}, entries[0].Details)
+	for i, allottedTo := range []string{"testbulktaking", "testbulktaking", "testbulkleaving", "testbulkpatch"} {
+		task, err := repository.Tasks.FindByID(context.Background(), user.ID, ids[i])
+		require.NoError(t, err)
+		require.Equal(t, allottedTo, task.AllottedTo, task.Title)
+		if i < 2 {
+			require.Equal(t, models.TaskPriorityHigh, task.Priority)
+			require.Equal(t, 2, task.Version)
+		}
+	}
+
+	// Tasks that fail validation are reported and do not hold back the others
+	resp = doRequest(t, http.MethodPatch, "/tasks?filter=status:Pending", fiber.Map{"allotted_to": "nobody-here"}, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &outcome)
+	require.Equal(t, 3, outcome.Matched)
+	require.Zero(t, outcome.Updated)
+	require.Len(t, outcome.Rejected, 3)
+	require.Equal(t, "validation_failed", outcome.Rejected[0].Error.Code)
+
+	// A filter is required and must be understood, and the patch must be valid
+	for _, path := range []string{"/tasks", "/tasks?filter=color:red", "/tasks?filter=status:Blocked", "/tasks?filter=overdue:maybe", "/tasks?filter=status"} {
+		resp = doRequest(t, http.MethodPatch, path, fiber.Map{"priority": "low"}, token)
+		require.Equal(t, fiber.StatusBadRequest, resp.StatusCode, path)
+	}
+	resp = doRequest(t, http.MethodPatch, "/tasks?filter=status:open", fiber.Map{"id": primitive.NewObjectID()}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodPatch, "/tasks?filter=status:open", fiber.Map{}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestAccountDeletion(t *testing.T) {
+	user := createTestUser(t, "testdeletion")
+	token := mintToken(t, user)
+
+	var task models.Task
+	resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Private", AllottedTo: "testdeletion"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &task)
+
+	// The export holds the account without its password and everything it owns
+	var export struct {
+		User  map[string]interface{} `json:"user"`
+		Tasks []models.Task          `json:"tasks"`
+	}
+	resp = doRequest(t, http.MethodGet, "/users/me/export", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Contains(t, resp.Header.Get(fiber.HeaderContentDisposition), "attachment")
+	decodeBody(t, resp, &export)
+	require.Equal(t, "testdeletion", export.User["username"])
+	require.Empty(t, export.User["password"])
+	require.Len(t, export.Tasks, 1)
+	require.Equal(t, task.ID, export.Tasks[0].ID)
+
+	// Deletion is scheduled after the grace period and can be cancelled
+	var scheduled struct {
+		DeleteAt models.Timestamp `json:"delete_at"`
+	}
+	resp = doRequest(t, http.MethodDelete, "/users/me", nil, token)
+	require.Equal(t, fiber.StatusAccepted, resp.StatusCode)
+	decodeBody(t, resp, &scheduled)
+	require.WithinDuration(t, time.Now().Add(24*time.Hour), scheduled.DeleteAt.Time(), time.Minute)
+
+	resp = doRequest(t, http.MethodPost, "/users/me/cancel-deletion", nil, token)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	purged, err := jobs.PurgeDeletedAccounts(context.Background(), time.Now().Add(48*time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, purged)
+
+	// Once the grace period has passed the job purges the account and its tasks
+	resp = doRequest(t, http.MethodDelete, "/users/me", nil, token)
+	require.Equal(t, fiber.StatusAccepted, resp.StatusCode)
+	purged, err = jobs.PurgeDeletedAccounts(context.Background(), time.Now())
+	require.NoError(t, err)
+	require.Zero(t, purged)
+	purged, err = jobs.PurgeDeletedAccounts(context.Background(), time.Now().Add(48*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+
+	_, err = repository.Users.FindByID(context.Background(), user.ID)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = repository.Tasks.FindByID(context.Background(), user.ID, task.ID)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestTaskInterchange(t *testing.T) {
+	user := createTestUser(t, "testinterchange")
+	token := mintToken(t, user)
+	resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Plan offsite", AllottedTo: "testinterchange",
+		Priority: models.TaskPriorityHigh}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	importFile := func(query string, file string) (int, importOutcome) {
+		req := httptest.NewRequest(http.MethodPost, "/users/me/import?"+query, strings.NewReader(file))
+		req.Header.Set("Authorization", token)
+		resp, err := testApp.Test(req, -1)
+		require.NoError(t, err)
+		var outcome importOutcome
+		if resp.StatusCode < 300 {
+			decodeBody(t, resp, &outcome)
+		}
+		return resp.StatusCode, outcome
+	}
+
+	// The Trello export reads back as the same tasks
+	resp = doRequest(t, http.MethodGet, "/users/me/export?format=trello", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/json", resp.Header.Get(fiber.HeaderContentType))
+	require.Contains(t, resp.Header.Get(fiber.HeaderContentDisposition), "tasks-trello.json")
+	board, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	status, outcome := importFile("format=trello&dry_run=true", string(board))
+	require.Equal(t, fiber.StatusOK, status)
+	require.Equal(t, 1, outcome.Valid)
+	status, outcome = importFile("format=trello", string(board))
+	require.Equal(t, fiber.StatusCreated, status)
+	require.Equal(t, 1, outcome.Created)
+	require.Equal(t, "Plan offsite", outcome.Results[0].Task.Title)
+	require.Equal(t, models.TaskPriorityHigh, outcome.Results[0].Task.Priority)
+
+	// Todoist assignees without an account and recurring dates are dropped with a warning
+	template := "TYPE,CONTENT,PRIORITY,RESPONSIBLE,DATE\n" +
+		"section,Doing,,,\n" +
+		"task,Call venue,3,someone-else,every monday\n" +
+		"task,Send invites,,,2030-01-15 09:00\n"
+	status, outcome = importFile("format=todoist", template)
+	require.Equal(t, fiber.StatusCreated, status)
+	require.Equal(t, 2, outcome.Created)
+	require.Equal(t, models.TaskStatusInProgress, outcome.Results[0].Task.Status)
+	require.Equal(t, "testinterchange", outcome.Results[0].Task.AllottedTo)
+	require.True(t, outcome.Results[0].Task.EndDate.IsZero())
+	require.Equal(t, 2030, outcome.Results[1].Task.EndDate.Time().Year())
+	require.Equal(t, []string{
+		"Tasks of assignees without an account are assigned to you",
+		"Dates that cannot be read, such as recurring ones, are left out",
+	}, outcome.Report.Warnings)
+
+	resp = doRequest(t, http.MethodGet, "/users/me/export?format=todoist", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	csvFile, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, 4, strings.Count(string(csvFile), "\ntask,"))
+
+	// Unknown formats and files not of the format are rejected
+	resp = doRequest(t, http.MethodGet, "/users/me/export?format=asana", nil, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	status, _ = importFile("format=asana", "{}")
+	require.Equal(t, fiber.StatusBadRequest, status)
+	status, _ = importFile("format=trello", "TYPE,CONTENT")
+	require.Equal(t, fiber.StatusBadRequest, status)
+}
+
+// importOutcome is the response of ImportTasks.
+type importOutcome struct {
+	Results []importResult     `json:"results"`
+	Created int                `json:"created"`
+	Valid   int                `json:"valid"`
+	Report  interchange.Report `json:"report"`
+}
+
+func TestSessions(t *testing.T) {
+	credentials := models.User{Username: "testsessions", Password: "testpassword"}
+	resp := doRequest(t, http.MethodPost, "/signup", credentials, "")
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	signIn := func() string {
+		var tokenResp struct {
+			Token string `json:"token"`
+		}
+		resp := doRequest(t, http.MethodPost, "/signin", credentials, "")
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		decodeBody(t, resp, &tokenResp)
+		return tokenResp.Token
+	}
+	phone, laptop := signIn(), signIn()
+
+	// Each sign-in is a session, the requesting one is marked current
+	var sessions []struct {
+		ID      string `json:"id"`
+		IP      string `json:"ip"`
+		Current bool   `json:"current"`
+	}
+	resp = doRequest(t, http.MethodGet, "/users/me/sessions", nil, laptop)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &sessions)
+	require.Len(t, sessions, 2)
+	require.True(t, sessions[0].Current)
+	require.False(t, sessions[1].Current)
+	require.NotEmpty(t, sessions[1].IP)
+
+	// Revoking the other session rejects its token but keeps the current one working
+	resp = doRequest(t, http.MethodDelete, "/users/me/sessions/"+sessions[1].ID, nil, laptop)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/users/me/sessions", nil, phone)
+	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/users/me/sessions", nil, laptop)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &sessions)
+	require.Len(t, sessions, 1)
+
+	resp = doRequest(t, http.MethodDelete, "/users/me/sessions/"+primitive.NewObjectID().Hex(), nil, laptop)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestShareTask(t *testing.T) {
+	user := createTestUser(t, "testshare")
+	token := mintToken(t, user)
+	other := mintToken(t, createTestUser(t, "testshareother"))
+
+	task := models.Task{Title: "Quarterly report", Description: "Numbers for Q3", AllottedTo: "testshare"}
+	resp := doRequest(t, http.MethodPost, "/tasks", task, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &task)
+
+	// Only the owner can share a task
+	resp = doRequest(t, http.MethodPost, "/tasks/"+task.ID.Hex()+"/share", nil, other)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/tasks/"+task.ID.Hex()+"/share", fiber.Map{"expires_in": "9000h"}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	var link struct {
+		Token     string    `json:"token"`
+		URL       string    `json:"url"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	resp = doRequest(t, http.MethodPost, "/tasks/"+task.ID.Hex()+"/share", fiber.Map{"expires_in": "48h"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &link)
+	require.Equal(t, "/shared/"+link.Token, link.URL)
+	require.WithinDuration(t, time.Now().Add(48*time.Hour), link.ExpiresAt, time.Minute)
+
+	// Anyone with the link sees the task read-only, without the owner's IDs
+	resp = doRequest(t, http.MethodGet, link.URL, nil, "")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var shared map[string]interface{}
+	decodeBody(t, resp, &shared)
+	require.Equal(t, "Quarterly report", shared["title"])
+	require.Equal(t, "Numbers for Q3", shared["description"])
+	require.NotContains(t, shared, "userId")
+
+	// The page carries the branding of the owner's workspace, as JSON or as a branded page for browsers
+	workspace := models.Workspace{Name: "Acme", OwnerID: user.ID, Branding: models.Branding{AccentColor: "#123abc", SenderName: "Acme <Tasks>"}}
+	require.NoError(t, repository.Workspaces.Create(context.Background(), &workspace))
+	user.WorkspaceID = workspace.ID
+	require.NoError(t, repository.Users.Update(context.Background(), &user))
+	resp = doRequest(t, http.MethodGet, link.URL, nil, "")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &shared)
+	require.Equal(t, map[string]interface{}{"logo_url": "", "accent_color": "#123abc", "sender_name": "Acme <Tasks>"}, shared["branding"])
+
+	req := httptest.NewRequest(http.MethodGet, link.URL, nil)
+	req.Header.Set(fiber.HeaderAccept, "text/html,application/xhtml+xml,*/*;q=0.8")
+	resp, err := testApp.Test(req, -1)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Contains(t, resp.Header.Get(fiber.HeaderContentType), "text/html")
+	require.NotEmpty(t, resp.Header.Get(fiber.HeaderContentSecurityPolicy))
+	page, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(page), "background:#123abc")
+	require.Contains(t, string(page), "Acme &lt;Tasks&gt;")
+	require.Contains(t, string(page), "<title>Quarterly report</title>")
+	require.Contains(t, string(page), "<p>Numbers for Q3</p>")
+
+	// The link is not an access token, and an access token is not a link
+	resp = doRequest(t, http.MethodGet, "/tasks/"+task.ID.Hex(), nil, link.Token)
+	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/shared/"+token, nil, "")
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	// The link stops working once the task is deleted
+	resp = doRequest(t, http.MethodDelete, "/tasks/"+task.ID.Hex(), nil, token)
+	require.Less(t, resp.StatusCode, 300)
+	resp = doRequest(t, http.MethodGet, link.URL, nil, "")
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestGuestInvitations(t *testing.T) {
+	user := createTestUser(t, "testguestowner")
+	token := mintToken(t, user)
+
+	var project, other models.Project
+	resp := doRequest(t, http.MethodPost, "/projects", fiber.Map{"name": "Launch"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &project)
+	resp = doRequest(t, http.MethodPost, "/projects", fiber.Map{"name": "Internal"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &other)
+
+	var shared, hidden models.Task
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Press release", AllottedTo: "testguestowner", ProjectID: project.ID}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &shared)
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Salaries", AllottedTo: "testguestowner", ProjectID: other.ID}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &hidden)
+
+	// The owner invites an external address and gets the token to forward
+	resp = doRequest(t, http.MethodPost, "/projects/"+project.ID.Hex()+"/invitations", fiber.Map{"email": "not an email"}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	var invitation struct {
+		models.Invitation
+		Token     string `json:"token"`
+		AcceptURL string `json:"accept_url"`
+	}
+	resp = doRequest(t, http.MethodPost, "/projects/"+project.ID.Hex()+"/invitations", fiber.Map{"email": "Client@Example.com"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &invitation)
+	require.Equal(t, "client@example.com", invitation.Email)
+	require.Equal(t, models.InvitationRoleViewer, invitation.Role)
+
+	// Accepting issues a guest token, once
+	var accepted struct {
+		Token string `json:"token"`
+	}
+	resp = doRequest(t, http.MethodPost, invitation.AcceptURL, nil, "")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &accepted)
+	resp = doRequest(t, http.MethodPost, invitation.AcceptURL, nil, "")
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	guest := accepted.Token
+
+	// The guest reads the project, its board and its tasks, and nothing else
+	resp = doRequest(t, http.MethodGet, "/projects/"+project.ID.Hex(), nil, guest)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/boards/"+project.ID.Hex(), nil, guest)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/tasks/"+shared.ID.Hex(), nil, guest)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var tasks []models.Task
+	resp = doRequest(t, http.MethodGet, "/tasks", nil, guest)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &tasks)
+	require.Len(t, tasks, 1)
+	require.Equal(t, shared.ID, tasks[0].ID)
+
+	resp = doRequest(t, http.MethodGet, "/projects/"+other.ID.Hex(), nil, guest)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/tasks/"+hidden.ID.Hex(), nil, guest)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	// Guests change nothing and have no account
+	resp = doRequest(t, http.MethodPut, "/tasks/"+shared.ID.Hex(), shared, guest)
+	require.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/projects/"+project.ID.Hex()+"/invitations", fiber.Map{"email": "friend@example.com"}, guest)
+	require.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/users/me/export", nil, guest)
+	require.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	// Revoking the invitation rejects the guest token
+	var invitations []models.Invitation
+	resp = doRequest(t, http.MethodGet, "/projects/"+project.ID.Hex()+"/invitations", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &invitations)
+	require.Len(t, invitations, 1)
+	require.False(t, invitations[0].AcceptedAt.IsZero())
+
+	resp = doRequest(t, http.MethodDelete, "/projects/"+project.ID.Hex()+"/invitations/"+invitation.ID.Hex(), nil, token)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/projects/"+project.ID.Hex(), nil, guest)
+	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestTaskVisibility(t *testing.T) {
+	owner := createTestUser(t, "testvisibilityowner")
+	assignee := createTestUser(t, "testvisibilityassignee")
+	token := mintToken(t, owner)
+
+	var project models.Project
+	resp := doRequest(t, http.MethodPost, "/projects", fiber.Map{"name": "Hiring"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &project)
+
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Offer", AllottedTo: "testvisibilityassignee", ProjectID: project.ID, Visibility: "public"}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	var open, private models.Task
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Job post", AllottedTo: "testvisibilityowner", ProjectID: project.ID}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &open)
+	require.Equal(t, "team", open.Visibility)
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Offer", AllottedTo: "testvisibilityassignee", ProjectID: project.ID, Visibility: "private"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &private)
+
+	// An update without a visibility keeps it
+	private.Visibility = ""
+	private.Description = "Salary band"
+	resp = doRequest(t, http.MethodPut, "/tasks/"+private.ID.Hex(), private, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &private)
+	require.Equal(t, "private", private.Visibility)
+
+	// Guests of the project don't see private tasks
+	var invitation struct {
+		AcceptURL string `json:"accept_url"`
+	}
+	resp = doRequest(t, http.MethodPost, "/projects/"+project.ID.Hex()+"/invitations", fiber.Map{"email": "recruiter@example.com"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &invitation)
+	var accepted struct {
+		Token string `json:"token"`
+	}
+	resp = doRequest(t, http.MethodPost, invitation.AcceptURL, nil, "")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &accepted)
+
+	resp = doRequest(t, http.MethodGet, "/tasks/"+private.ID.Hex(), nil, accepted.Token)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	var tasks []models.Task
+	resp = doRequest(t, http.MethodGet, "/tasks", nil, accepted.Token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &tasks)
+	require.Len(t, tasks, 1)
+	require.Equal(t, open.ID, tasks[0].ID)
+
+	// The assignee reads the private task but can't change it; other users don't see it
+	resp = doRequest(t, http.MethodGet, "/tasks/"+private.ID.Hex(), nil, mintToken(t, assignee))
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp = doRequest(t, http.MethodPut, "/tasks/"+private.ID.Hex(), private, mintToken(t, assignee))
+	require.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/tasks/"+open.ID.Hex(), nil, mintToken(t, assignee))
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestReassignTask(t *testing.T) {
+	owner := createTestUser(t, "testreassignowner")
+	createTestUser(t, "testreassignfrom")
+	createTestUser(t, "testreassignto")
+	token := mintToken(t, owner)
+
+	var sent []notifications.Notification
+	notifications.Default = notifications.NotifierFunc(func(ctx context.Context, notification notifications.Notification) error {
+		sent = append(sent, notification)
+		return nil
+	})
+	defer func() { notifications.Default = notifications.Nop{} }()
+
+	var task models.Task
+	resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Release notes", AllottedTo: "testreassignfrom"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &task)
+	sent = nil
+
+	resp = doRequest(t, http.MethodPost, "/tasks/"+task.ID.Hex()+"/reassign", fiber.Map{"allotted_to": "nobody"}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/tasks/"+task.ID.Hex()+"/reassign", fiber.Map{"allotted_to": "testreassignfrom"}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	// The handoff is recorded and both assignees are told
+	resp = doRequest(t, http.MethodPost, "/tasks/"+task.ID.Hex()+"/reassign", fiber.Map{"allotted_to": "testreassignto", "note": "Draft is in the wiki"}, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var reassigned models.Task
+	decodeBody(t, resp, &reassigned)
+	require.Equal(t, "testreassignto", reassigned.AllottedTo)
+	require.Equal(t, task.Version+1, reassigned.Version)
+	require.Len(t, reassigned.Handoffs, 1)
+	require.Equal(t, "testreassignowner", reassigned.Handoffs[0].By)
+	require.Equal(t, "testreassignfrom", reassigned.Handoffs[0].From)
+	require.Equal(t, "testreassignto", reassigned.Handoffs[0].To)
+	require.Equal(t, "Draft is in the wiki", reassigned.Handoffs[0].Note)
+
+	require.Len(t, sent, 2)
+	require.Equal(t, notifications.EventTaskAssigned, sent[0].Event)
+	require.Equal(t, "testreassignto", sent[0].Recipient)
+	require.Contains(t, sent[0].Message, "Draft is in the wiki")
+	require.Equal(t, notifications.EventTaskReassigned, sent[1].Event)
+	require.Equal(t, "testreassignfrom", sent[1].Recipient)
+
+	// Updates keep the history
+	reassigned.Title = "Release notes v2"
+	resp = doRequest(t, http.MethodPut, "/tasks/"+task.ID.Hex(), reassigned, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var updated models.Task
+	decodeBody(t, resp, &updated)
+	require.Len(t, updated.Handoffs, 1)
+}
+
+func TestSearch(t *testing.T) {
+	user := createTestUser(t, "testsearch")
+	token := mintToken(t, user)
+
+	resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Quarterly invoice run", AllottedTo: "testsearch"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/projects", fiber.Map{"name": "Invoice automation"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var results search.Results
+	resp = doRequest(t, http.MethodGet, "/search?q=invoice", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &results)
+	require.Equal(t, 1, results.Tasks.Total)
+	require.Equal(t, "Quarterly <mark>invoice</mark> run", results.Tasks.Hits[0].Highlights["title"])
+	require.Equal(t, 1, results.Projects.Total)
+
+	results = search.Results{}
+	resp = doRequest(t, http.MethodGet, "/search?q=invoice&type=project", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &results)
+	require.Nil(t, results.Tasks)
+	require.Len(t, results.Projects.Hits, 1)
+
+	resp = doRequest(t, http.MethodGet, "/search?q=%20-", nil, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/search?q=invoice&type=comment", nil, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/search?q=invoice&offset=-1", nil, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestTaskMarkdown(t *testing.T) {
+	user := createTestUser(t, "testmarkdown")
+	token := mintToken(t, user)
+
+	// The description is kept as sent and rendered to sanitized HTML; HTML sent by clients is replaced
+	var task models.Task
+	resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Docs", AllottedTo: "testmarkdown", Description: "**Ship** it <script>alert(1)</script>", DescriptionHTML: "<script>"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &task)
+	require.Equal(t, "**Ship** it <script>alert(1)</script>", task.Description)
+	require.Equal(t, "<p><strong>Ship</strong> it alert(1)</p>", task.DescriptionHTML)
+
+	task.Description = "- [x](javascript:alert)"
+	resp = doRequest(t, http.MethodPut, "/tasks/"+task.ID.Hex(), task, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &task)
+	require.Equal(t, "<ul>\n<li>x</li>\n</ul>", task.DescriptionHTML)
+}
+
+func TestComments(t *testing.T) {
+	owner := createTestUser(t, "testcommentowner")
+	assignee := createTestUser(t, "testcommentassignee")
+	stranger := createTestUser(t, "testcommentstranger")
+	token := mintToken(t, owner)
+
+	var sent []notifications.Notification
+	notifications.Default = notifications.NotifierFunc(func(ctx context.Context, notification notifications.Notification) error {
+		sent = append(sent, notification)
+		return nil
+	})
+	defer func() { notifications.Default = notifications.Nop{} }()
+
+	var task models.Task
+	resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Launch plan", AllottedTo: "testcommentassignee", Visibility: "private"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &task)
+	sent = nil
+
+	resp = doRequest(t, http.MethodPost, "/tasks/"+task.ID.Hex()+"/comments", fiber.Map{"body": "  "}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	// Only mentioned users who exist and may read the task are kept and notified, never the author
+	body := "@testcommentassignee please check, cc @testcommentstranger @nobody @testcommentowner"
+	resp = doRequest(t, http.MethodPost, "/tasks/"+task.ID.Hex()+"/comments", fiber.Map{"body": body}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var comment models.Comment
+	decodeBody(t, resp, &comment)
+	require.Equal(t, "testcommentowner", comment.Author)
+	require.Equal(t, []string{"testcommentassignee"}, comment.Mentions)
+	require.Contains(t, comment.BodyHTML, "<p>@testcommentassignee please check")
+	require.Len(t, sent, 1)
+	require.Equal(t, notifications.EventTaskMentioned, sent[0].Event)
+	require.Equal(t, "testcommentassignee", sent[0].Recipient)
+	require.Equal(t, task.ID.Hex(), sent[0].TaskID)
+
+	// The assignee can read the task and so its comments; other users can't
+	resp = doRequest(t, http.MethodGet, "/tasks/"+task.ID.Hex()+"/comments", nil, mintToken(t, assignee))
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var comments []models.Comment
+	decodeBody(t, resp, &comments)
+	require.Len(t, comments, 1)
+	require.Equal(t, comment.ID, comments[0].ID)
+
+	resp = doRequest(t, http.MethodGet, "/tasks/"+task.ID.Hex()+"/comments", nil, mintToken(t, stranger))
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	// Deleting the task hides its comments, which stay with it in the trash until it is purged
+	resp = doRequest(t, http.MethodDelete, "/tasks/"+task.ID.Hex(), nil, token)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/tasks/"+task.ID.Hex()+"/comments", nil, token)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	comments, err := repository.Comments.Find(context.Background(), task.ID)
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+}
+
+func TestManualOrder(t *testing.T) {
+	user := createTestUser(t, "testmanualorder")
+	token := mintToken(t, user)
+
+	ids := []primitive.ObjectID{}
+	for _, title := range []string{"First", "Second", "Third", "Fourth"} {
+		var task models.Task
+		resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: title, AllottedTo: "testmanualorder"}, token)
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		decodeBody(t, resp, &task)
+		ids = append(ids, task.ID)
+	}
+	titles := func(query string) []string {
+		resp := doRequest(t, http.MethodGet, "/tasks?sort=manual"+query, nil, token)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var tasks []models.Task
+		decodeBody(t, resp, &tasks)
+		result := []string{}
+		for _, task := range tasks {
+			result = append(result, task.Title)
+		}
+		return result
+	}
+	require.Equal(t, []string{"First", "Second", "Third", "Fourth"}, titles(""))
+
+	// Moving a task between two others positions it there
+	resp := doRequest(t, http.MethodPost, "/tasks/reorder", fiber.Map{"task_id": ids[3].Hex(), "after_id": ids[0].Hex(), "before_id": ids[1].Hex()}, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var moved models.Task
+	decodeBody(t, resp, &moved)
+	require.NotEmpty(t, moved.Position)
+	require.Equal(t, []string{"First", "Fourth", "Second", "Third"}, titles(""))
+
+	// Pinned tasks come first and keep their place in the order when unpinned
+	resp = doRequest(t, http.MethodPost, "/tasks/"+ids[2].Hex()+"/pin", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, []string{"Third", "First", "Fourth", "Second"}, titles(""))
+	require.Equal(t, []string{"Third", "First"}, titles("&limit=2"))
+
+	// Neighbours must be adjacent tasks of the same pin group
+	resp = doRequest(t, http.MethodPost, "/tasks/reorder", fiber.Map{"task_id": ids[0].Hex(), "after_id": ids[2].Hex()}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/tasks/reorder", fiber.Map{"task_id": ids[0].Hex(), "after_id": ids[3].Hex(), "before_id": ids[3].Hex()}, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp = doRequest(t, http.MethodDelete, "/tasks/"+ids[2].Hex()+"/pin", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, []string{"First", "Fourth", "Second", "Third"}, titles(""))
+
+	// Updates keep the pin and the position
+	moved.Title = "Fourth, renamed"
+	resp = doRequest(t, http.MethodPut, "/tasks/"+moved.ID.Hex(), moved, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, []string{"First", "Fourth, renamed", "Second", "Third"}, titles(""))
+}
+
+func TestCreateTaskDuplicates(t *testing.T) {
+	user := createTestUser(t, "testduplicates")
+	token := mintToken(t, user)
+	other := createTestUser(t, "testduplicatesother")
+
+	var existing models.Task
+	resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Renew TLS certificate", AllottedTo: "testduplicates"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &existing)
+
+	// Without the feature flag duplicates are created as before
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Renew TLS certificates", AllottedTo: "testduplicates"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	features.Set([]string{features.DuplicateCheck})
+	defer features.Set(nil)
+
+	// Near-duplicates of open tasks of the same assignee are rejected with the candidates
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "renew the TLS certificate", AllottedTo: "testduplicates"}, token)
+	require.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	var conflict struct {
+		Code    string `json:"code"`
+		Details struct {
+			Candidates []struct {
+				ID         primitive.ObjectID `json:"id"`
+				Similarity float64            `json:"similarity"`
+			} `json:"candidates"`
+		} `json:"details"`
+	}
+	decodeBody(t, resp, &conflict)
+	require.Equal(t, "possible_duplicate", conflict.Code)
+	require.Len(t, conflict.Details.Candidates, 2)
+	require.Greater(t, conflict.Details.Candidates[0].Similarity, 0.75)
+
+	// Forcing creates it anyway, and other assignees or titles are no duplicates
+	resp = doRequest(t, http.MethodPost, "/tasks?force=true", models.Task{Title: "renew the TLS certificate", AllottedTo: "testduplicates"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Renew TLS certificate", AllottedTo: "testduplicatesother"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Rotate database passwords", AllottedTo: "testduplicates"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	// Candidates the caller may not read are not revealed
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Rotate database passwords", AllottedTo: "testduplicates"}, mintToken(t, other))
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+}
+
+func TestUsageQuotas(t *testing.T) {
+	workspace := primitive.NewObjectID()
+	user := models.User{Username: "testquota", Password: "not-a-hash", WorkspaceID: workspace}
+	require.NoError(t, repository.Users.Create(context.Background(), &user))
+	member := models.User{Username: "testquotamember", Password: "not-a-hash", WorkspaceID: workspace}
+	require.NoError(t, repository.Users.Create(context.Background(), &member))
+	token, memberToken := mintToken(t, user), mintToken(t, member)
+
+	quota.User, quota.Workspace = quota.Limits{Tasks: 2}, quota.Limits{Tasks: 3}
+	defer func() { quota.User, quota.Workspace = quota.Limits{}, quota.Limits{} }()
+
+	for _, title := range []string{"First", "Second"} {
+		resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: title, AllottedTo: "testquota"}, token)
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	}
+
+	// The user's own quota is used up
+	resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Third", AllottedTo: "testquota"}, token)
+	require.Equal(t, fiber.StatusPaymentRequired, resp.StatusCode)
+	var exceeded struct {
+		Code    string              `json:"code"`
+		Details quota.ExceededError `json:"details"`
+	}
+	decodeBody(t, resp, &exceeded)
+	require.Equal(t, "quota_exceeded", exceeded.Code)
+	require.Equal(t, quota.ExceededError{Resource: "tasks", Scope: "user", Used: 2, Limit: 2}, exceeded.Details)
+
+	// Then the workspace's, which members share
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Member's", AllottedTo: "testquotamember"}, memberToken)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Member's second", AllottedTo: "testquotamember"}, memberToken)
+	require.Equal(t, fiber.StatusPaymentRequired, resp.StatusCode)
+
+	resp = doRequest(t, http.MethodGet, "/usage", nil, memberToken)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var usage quota.Usage
+	decodeBody(t, resp, &usage)
+	require.Equal(t, quota.Consumption{Used: 1, Limit: 2}, usage.User.Tasks)
+	require.NotNil(t, usage.Workspace)
+	require.Equal(t, quota.Consumption{Used: 3, Limit: 3}, usage.Workspace.Tasks)
+	require.Equal(t, quota.Consumption{}, usage.Workspace.Webhooks)
+}
+
+// TestWorkspaceMembership tests that an invited user joins a workspace, then sees the org-visible tasks of
+// its members and shares its quota, and that leaving ends both
+func TestWorkspaceMembership(t *testing.T) {
+	owner := createTestUser(t, "testworkspaceowner")
+	ownerToken := mintToken(t, owner)
+	member := createTestUser(t, "testworkspacemember")
+	memberToken := mintToken(t, member)
+
+	var workspace models.Workspace
+	resp := doRequest(t, http.MethodPost, "/workspaces", models.Workspace{Name: "Acme"}, ownerToken)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &workspace)
+	path := "/workspaces/" + workspace.ID.Hex()
+
+	var task models.Task
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Company offsite", AllottedTo: "testworkspaceowner", Visibility: "org"}, ownerToken)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &task)
+
+	// Without an invitation the workspace stays hidden and cannot be joined
+	resp = doRequest(t, http.MethodGet, path, nil, memberToken)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, path+"/join", nil, memberToken)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/tasks/"+task.ID.Hex(), nil, memberToken)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	// Only the owner invites, and only existing users who are not members yet
+	resp = doRequest(t, http.MethodPost, path+"/invitations", fiber.Map{"username": "nobody"}, ownerToken)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, path+"/invitations", fiber.Map{"username": "testworkspaceowner"}, ownerToken)
+	require.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, path+"/invitations", fiber.Map{"username": "testworkspacemember"}, ownerToken)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+
+	var members struct {
+		Members []models.UserSummary `json:"members"`
+		Invited []models.UserSummary `json:"invited"`
+	}
+	resp = doRequest(t, http.MethodGet, path+"/members", nil, ownerToken)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &members)
+	require.Len(t, members.Members, 1)
+	require.Len(t, members.Invited, 1)
+	require.Equal(t, "testworkspacemember", members.Invited[0].Username)
+
+	// Joining uses up the invitation
+	resp = doRequest(t, http.MethodPost, path+"/join", nil, memberToken)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, path+"/join", nil, memberToken)
+	require.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, path, nil, memberToken)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, path+"/invitations", fiber.Map{"username": "testworkspaceowner"}, memberToken)
+	require.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	members.Members, members.Invited = nil, nil
+	resp = doRequest(t, http.MethodGet, path+"/members", nil, memberToken)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &members)
+	require.Len(t, members.Members, 2)
+	require.Empty(t, members.Invited)
+
+	// The second member reads the org-visible task and counts against the workspace quota
+	resp = doRequest(t, http.MethodGet, "/tasks/"+task.ID.Hex(), nil, memberToken)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	quota.Workspace = quota.Limits{Tasks: 1}
+	defer func() { quota.Workspace = quota.Limits{} }()
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Over the limit", AllottedTo: "testworkspacemember"}, memberToken)
+	require.Equal(t, fiber.StatusPaymentRequired, resp.StatusCode)
+
+	// The owner stays; a member who leaves loses access and has a quota of their own again
+	resp = doRequest(t, http.MethodPost, path+"/leave", nil, ownerToken)
+	require.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, path+"/leave", nil, memberToken)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/tasks/"+task.ID.Hex(), nil, memberToken)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, path, nil, memberToken)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "On my own", AllottedTo: "testworkspacemember"}, memberToken)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+}
+
+// TestTrash tests that deleted tasks go to the trash, from where they can be restored with their comments
+// and without their tombstone, and that archived tasks are listed only on request
+func TestTrash(t *testing.T) {
+	user := createTestUser(t, "testtrash")
+	token := mintToken(t, user)
+
+	var task models.Task
+	resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Throw away", AllottedTo: "testtrash"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &task)
+	resp = doRequest(t, http.MethodPost, "/tasks/"+task.ID.Hex()+"/comments", fiber.Map{"body": "Keep me"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	resp = doRequest(t, http.MethodDelete, "/tasks/"+task.ID.Hex(), nil, token)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/tasks/"+task.ID.Hex(), nil, token)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	tombstones, err := repository.Tombstones.Find(context.Background(), user.ID, nil, 0)
+	require.NoError(t, err)
+	require.Len(t, tombstones, 1)
+
+	var trashed []models.TrashedTask
+	resp = doRequest(t, http.MethodGet, "/trash", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &trashed)
+	require.Len(t, trashed, 1)
+	require.Equal(t, "Throw away", trashed[0].Title)
+	require.NotZero(t, trashed[0].DeletedAt)
+
+	// Restoring brings the task back once, and only for its owner
+	other := mintToken(t, createTestUser(t, "testtrashother"))
+	resp = doRequest(t, http.MethodPost, "/trash/"+task.ID.Hex()+"/restore", nil, other)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/trash/"+task.ID.Hex()+"/restore", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/trash/"+task.ID.Hex()+"/restore", nil, token)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/tasks/"+task.ID.Hex(), nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var comments []models.Comment
+	resp = doRequest(t, http.MethodGet, "/tasks/"+task.ID.Hex()+"/comments", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &comments)
+	require.Len(t, comments, 1)
+	require.Equal(t, "Keep me", comments[0].Body)
+	tombstones, err = repository.Tombstones.Find(context.Background(), user.ID, nil, 0)
+	require.NoError(t, err)
+	require.Empty(t, tombstones)
+
+	// Archived tasks are hidden from the list unless asked for
+	stored, err := repository.Tasks.FindByID(context.Background(), user.ID, task.ID)
+	require.NoError(t, err)
+	stored.ArchivedAt = primitive.NewDateTimeFromTime(time.Now())
+	require.NoError(t, repository.Tasks.Update(context.Background(), stored, stored.Version))
+	for query, count := range map[string]int{"": 0, "?archived=false": 0, "?archived=true": 1} {
+		var tasks []models.Task
+		resp = doRequest(t, http.MethodGet, "/tasks"+query, nil, token)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		decodeBody(t, resp, &tasks)
+		require.Len(t, tasks, count, query)
+	}
+	resp = doRequest(t, http.MethodGet, "/tasks?archived=maybe", nil, token)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestTaskTriage(t *testing.T) {
+	user := createTestUser(t, "testtriage")
+	token := mintToken(t, user)
+
+	resp := doRequest(t, http.MethodPost, "/projects", models.Project{Name: "Triage"}, token)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var project models.Project
+	decodeBody(t, resp, &project)
+
+	create := func(task models.Task) models.Task {
+		task.AllottedTo, task.ProjectID = "testtriage", project.ID
+		resp := doRequest(t, http.MethodPost, "/tasks", task, token)
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		decodeBody(t, resp, &task)
+		return task
+	}
+	soon := models.NewTimestamp(time.Now().Add(24 * time.Hour))
+	later := create(models.Task{Title: "Later", Priority: models.TaskPriorityLow})
+	blocker := create(models.Task{Title: "Blocker", Priority: models.TaskPriorityMedium})
+	waiting := create(models.Task{Title: "Waiting", Priority: models.TaskPriorityHigh, EndDate: soon, DependsOn: []primitive.ObjectID{blocker.ID}})
+	urgent := create(models.Task{Title: "Urgent", Priority: models.TaskPriorityHigh, EndDate: soon})
+
+	var triage struct {
+		Weights planning.TriageWeights `json:"weights"`
+		Tasks   []planning.TriageEntry `json:"tasks"`
+	}
+	ids := func() []primitive.ObjectID {
+		ids := make([]primitive.ObjectID, len(triage.Tasks))
+		for i, entry := range triage.Tasks {
+			ids[i] = entry.Task.ID
+		}
+		return ids
+	}
+
+	// Actionable tasks come first by score, blocked ones last
+	resp = doRequest(t, http.MethodGet, "/tasks/triage", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &triage)
+	require.Equal(t, planning.DefaultTriageWeights, triage.Weights)
+	require.Equal(t, []primitive.ObjectID{urgent.ID, blocker.ID, later.ID, waiting.ID}, ids())
+	require.Equal(t, 1, triage.Tasks[1].Unblocks)
+	require.Equal(t, []primitive.ObjectID{blocker.ID}, triage.Tasks[3].BlockedBy)
+
+	// Weights of the request override the defaults
+	resp = doRequest(t, http.MethodGet, "/tasks/triage?weights=due=0,age=0,unblocks=0&limit=2", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &triage)
+	require.Equal(t, planning.TriageWeights{Priority: 3}, triage.Weights)
+	require.Equal(t, []primitive.ObjectID{urgent.ID, blocker.ID}, ids())
+
+	// Done tasks no longer block or get ranked
+	resp = doRequest(t, http.MethodPatch, "/tasks/"+blocker.ID.Hex(), fiber.Map{"status": models.TaskStatusDone}, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/tasks/triage", nil, token)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &triage)
+	require.Equal(t, []primitive.ObjectID{waiting.ID, urgent.ID, later.ID}, ids())
+
+	for _, query := range []string{"weights=effort=1", "weights=due=-1", "limit=0", "project_id=nope"} {
+		resp = doRequest(t, http.MethodGet, "/tasks/triage?"+query, nil, token)
+		require.Equal(t, fiber.StatusBadRequest, resp.StatusCode, query)
+	}
+}
+
+func TestFocusSessions(t *testing.T) {
+	owner := createTestUser(t, "testfocusowner")
+	dev := createTestUser(t, "testfocusdev")
+	ownerToken, devToken := mintToken(t, owner), mintToken(t, dev)
+
+	var task models.Task
+	resp := doRequest(t, http.MethodPost, "/tasks", models.Task{Title: "Write focus docs", AllottedTo: "testfocusdev"}, ownerToken)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &task)
+
+	// A session stopped earlier today
+	now := time.Now()
+	earlier := models.FocusSession{UserID: dev.ID, TaskID: task.ID, PlannedMinutes: 25, StartedAt: models.NewTimestamp(now.Add(-time.Hour)),
+		EndedAt: models.NewTimestamp(now.Add(-30 * time.Minute)), FocusedMinutes: 30}
+	require.NoError(t, repository.FocusSessions.Create(context.Background(), &earlier))
+
+	// The assignee focuses on the task, one session at a time
+	resp = doRequest(t, http.MethodPost, "/focus", fiber.Map{"task_id": task.ID.Hex()}, devToken)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var session models.FocusSession
+	decodeBody(t, resp, &session)
+	require.Equal(t, focus.DefaultMinutes, session.PlannedMinutes)
+	require.True(t, session.EndedAt.IsZero())
+
+	resp = doRequest(t, http.MethodPost, "/focus", fiber.Map{"task_id": task.ID.Hex()}, devToken)
+	require.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	var apiErr struct {
+		Details map[string]string `json:"details"`
+	}
+	decodeBody(t, resp, &apiErr)
+	require.Equal(t, session.ID.Hex(), apiErr.Details["session_id"])
+
+	resp = doRequest(t, http.MethodGet, "/focus/current", nil, devToken)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp = doRequest(t, http.MethodGet, "/focus/current", nil, ownerToken)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	// Interruptions are logged on the running session only, by its user
+	sessionPath := "/focus/" + session.ID.Hex()
+	resp = doRequest(t, http.MethodPost, sessionPath+"/interruptions", fiber.Map{"minutes": 2, "note": "Phone call"}, devToken)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &session)
+	require.Len(t, session.Interruptions, 1)
+	resp = doRequest(t, http.MethodPost, sessionPath+"/interruptions", fiber.Map{"minutes": -1}, devToken)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, sessionPath+"/interruptions", fiber.Map{"minutes": 1}, ownerToken)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	resp = doRequest(t, http.MethodPost, sessionPath+"/stop", nil, devToken)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	decodeBody(t, resp, &session)
+	require.False(t, session.EndedAt.IsZero())
+	require.Zero(t, session.FocusedMinutes) // Stopped right away, less the interruption
+	resp = doRequest(t, http.MethodPost, sessionPath+"/stop", nil, devToken)
+	require.Equal(t, fiber.StatusConflict, resp.StatusCode)
+
+	// The daily report sums the ended sessions
+	resp = doRequest(t, http.MethodGet, "/reports/focus", nil, devToken)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var summary focus.Summary
+	decodeBody(t, resp, &summary)
+	require.Len(t, summary.Days, 7)
+	require.Equal(t, 30, summary.FocusedMinutes)
+	require.Equal(t, 2, summary.Sessions)
+	require.Equal(t, 1, summary.Interruptions)
+	require.Equal(t, []focus.TaskTime{{TaskID: task.ID, FocusedMinutes: 30, Sessions: 2}}, summary.Tasks)
+
+	for _, query := range []string{"from=yesterday", "to=2024-13-01", "from=2024-07-10&to=2024-07-09", "from=2020-01-01&to=2024-01-01"} {
+		resp = doRequest(t, http.MethodGet, "/reports/focus?"+query, nil, devToken)
+		require.Equal(t, fiber.StatusBadRequest, resp.StatusCode, query)
+	}
+
+	// The task reports of the owner carry the assignee's focused time
+	resp = doRequest(t, http.MethodGet, "/reports/tasks.pdf", nil, ownerToken)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, pdfText(t, body), "0.5 h focused in focus sessions.")
+
+	// Done tasks and tasks the user cannot read cannot be focused on
+	resp = doRequest(t, http.MethodPatch, "/tasks/"+task.ID.Hex(), fiber.Map{"status": models.TaskStatusDone}, ownerToken)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/focus", fiber.Map{"task_id": task.ID.Hex()}, devToken)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/focus", fiber.Map{"task_id": primitive.NewObjectID().Hex()}, devToken)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, "/focus", fiber.Map{"task_id": "nope"}, devToken)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
 }
@@ -0,0 +1,66 @@
+// health.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/bkojha74/task-management/database"
+	"github.com/bkojha74/task-management/lifecycle"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Healthz is the liveness probe. It returns 200 OK as long as the process is serving requests.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func Healthz(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "ok"})
+}
+
+// Readyz is the readiness probe. It returns 503 Service Unavailable while the instance is draining
+// or cannot reach MongoDB, so that load balancers stop routing new traffic to it.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func Readyz(c *fiber.Ctx) error {
+	if lifecycle.Draining() {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"status": "draining"})
+	}
+
+	if database.MongoClient != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := database.MongoClient.Ping(ctx, nil); err != nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"status": "database unavailable"})
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "ready"})
+}
+
+// Drain returns a handler that starts draining the instance: readiness flips to not-ready while
+// existing traffic is still served for the grace period, after which the server shuts down.
+//
+// Parameters:
+// - grace: How long to keep serving traffic before shutting down.
+//
+// Returns:
+// - fiber.Handler: A Fiber handler function that starts draining.
+func Drain(grace time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !lifecycle.StartDrain(grace) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "already draining"})
+		}
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"status": "draining", "grace_period": grace.String()})
+	}
+}
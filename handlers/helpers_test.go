@@ -0,0 +1,162 @@
+// helpers_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/planning"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ledongthuc/pdf"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestApp builds an app with the user, task and project routes, served through app.Test without a listener.
+func newTestApp(secret *utils.SigningSecret) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: apierror.Handler})
+	app.Post("/signup", SignUp)
+	app.Post("/signin", SignIn(secret, 60))
+	app.Post("/token/refresh", RefreshToken(secret, 60))
+	app.Post("/signout", SignOut)
+	app.Get("/users/me/notifications", utils.JWTMiddleware(secret), GetNotificationSettings)
+	app.Put("/users/me/notifications", utils.JWTMiddleware(secret), UpdateNotificationSettings)
+	app.Get("/users/me/digest", utils.JWTMiddleware(secret), GetDigestSettings)
+	app.Put("/users/me/digest", utils.JWTMiddleware(secret), UpdateDigestSettings)
+	app.Get("/users/me/timezone", utils.JWTMiddleware(secret), GetTimezone)
+	app.Put("/users/me/timezone", utils.JWTMiddleware(secret), UpdateTimezone)
+	app.Delete("/users/me", utils.JWTMiddleware(secret), DeleteAccount(24*time.Hour))
+	app.Post("/users/me/cancel-deletion", utils.JWTMiddleware(secret), CancelAccountDeletion)
+	app.Get("/users/me/export", utils.JWTMiddleware(secret), ExportAccount)
+	app.Post("/users/me/import", utils.JWTMiddleware(secret), ImportTasks)
+	app.Get("/users/me/sessions", utils.JWTMiddleware(secret), GetSessions)
+	app.Delete("/users/me/sessions/:id", utils.JWTMiddleware(secret), RevokeSession)
+	app.Get("/users/me/signins", utils.JWTMiddleware(secret), GetSignIns)
+	app.Post("/tasks", utils.JWTMiddleware(secret), CreateTask)
+	app.Get("/tasks", utils.GuestMiddleware(secret), GetTasks)
+	app.Get("/tasks/summary", utils.JWTMiddleware(secret), GetTaskSummaries)
+	app.Get("/tasks/triage", utils.JWTMiddleware(secret), GetTriage(planning.DefaultTriageWeights))
+	app.Post("/tasks/batch-get", utils.GuestMiddleware(secret), BatchGetTasks)
+	app.Post("/tasks/reorder", utils.JWTMiddleware(secret), ReorderTask)
+	app.Post("/tasks/bulk/transition", utils.JWTMiddleware(secret), TransitionTasks)
+	app.Patch("/tasks", utils.JWTMiddleware(secret), PatchTasks)
+	app.Get("/tasks/:id", utils.GuestMiddleware(secret), GetTask)
+	app.Put("/tasks/:id", utils.JWTMiddleware(secret), UpdateTask)
+	app.Patch("/tasks/:id", utils.JWTMiddleware(secret), PatchTask)
+	app.Delete("/tasks/:id", utils.JWTMiddleware(secret), DeleteTask)
+	app.Post("/tasks/:id/snooze", utils.JWTMiddleware(secret), SnoozeTask)
+	app.Post("/tasks/:id/reassign", utils.JWTMiddleware(secret), ReassignTask)
+	app.Post("/tasks/:id/pin", utils.JWTMiddleware(secret), PinTask)
+	app.Delete("/tasks/:id/pin", utils.JWTMiddleware(secret), UnpinTask)
+	app.Post("/tasks/:id/comments", utils.JWTMiddleware(secret), AddComment)
+	app.Get("/tasks/:id/comments", utils.JWTMiddleware(secret), GetComments)
+	app.Get("/search", utils.JWTMiddleware(secret), Search)
+	app.Post("/tasks/:id/share", utils.JWTMiddleware(secret), ShareTask(secret))
+	app.Get("/shared/:token", GetSharedTask(secret))
+	app.Post("/projects", utils.JWTMiddleware(secret), CreateProject)
+	app.Get("/projects/:id", utils.GuestMiddleware(secret), GetProject)
+	app.Get("/projects/:id/burndown", utils.GuestMiddleware(secret), GetProjectBurndown)
+	app.Get("/projects/:id/gantt", utils.GuestMiddleware(secret), GetProjectGantt)
+	app.Get("/projects/:id/critical-path", utils.GuestMiddleware(secret), GetCriticalPath)
+	app.Put("/projects/:id/assignment", utils.JWTMiddleware(secret), SetAssignmentRule)
+	app.Delete("/projects/:id/assignment", utils.JWTMiddleware(secret), DeleteAssignmentRule)
+	app.Get("/reports/workload", utils.JWTMiddleware(secret), GetWorkload)
+	app.Get("/reports/tasks.pdf", utils.JWTMiddleware(secret), GetTasksReport)
+	app.Get("/reports/focus", utils.JWTMiddleware(secret), GetFocusReport)
+	app.Post("/focus", utils.JWTMiddleware(secret), StartFocus)
+	app.Get("/focus/current", utils.JWTMiddleware(secret), GetCurrentFocus)
+	app.Post("/focus/:id/stop", utils.JWTMiddleware(secret), StopFocus)
+	app.Post("/focus/:id/interruptions", utils.JWTMiddleware(secret), LogInterruption)
+	app.Post("/report-schedules", utils.JWTMiddleware(secret), CreateReportSchedule)
+	app.Get("/report-schedules", utils.JWTMiddleware(secret), GetReportSchedules)
+	app.Get("/report-schedules/:id", utils.JWTMiddleware(secret), GetReportSchedule)
+	app.Put("/report-schedules/:id", utils.JWTMiddleware(secret), UpdateReportSchedule)
+	app.Delete("/report-schedules/:id", utils.JWTMiddleware(secret), DeleteReportSchedule)
+	app.Get("/projects/:id/report.pdf", utils.GuestMiddleware(secret), GetProjectReport)
+	app.Get("/usage", utils.JWTMiddleware(secret), GetUsage)
+	app.Get("/trash", utils.JWTMiddleware(secret), GetTrash)
+	app.Post("/trash/:id/restore", utils.JWTMiddleware(secret), RestoreTask)
+	app.Post("/projects/:id/invitations", utils.JWTMiddleware(secret), InviteGuest)
+	app.Get("/projects/:id/invitations", utils.JWTMiddleware(secret), GetInvitations)
+	app.Delete("/projects/:id/invitations/:invitationId", utils.JWTMiddleware(secret), RevokeInvitation)
+	app.Post("/invitations/:token/accept", AcceptInvitation(secret))
+	app.Post("/workspaces", utils.JWTMiddleware(secret), CreateWorkspace)
+	app.Get("/workspaces/:id", utils.JWTMiddleware(secret), GetWorkspace)
+	app.Post("/workspaces/:id/invitations", utils.JWTMiddleware(secret), InviteToWorkspace)
+	app.Post("/workspaces/:id/join", utils.JWTMiddleware(secret), JoinWorkspace)
+	app.Post("/workspaces/:id/leave", utils.JWTMiddleware(secret), LeaveWorkspace)
+	app.Get("/workspaces/:id/members", utils.JWTMiddleware(secret), GetWorkspaceMembers)
+	app.Get("/boards/:projectId", utils.GuestMiddleware(secret), GetBoard)
+	app.Post("/boards/:projectId/move", utils.JWTMiddleware(secret), MoveCard)
+	return app
+}
+
+// createTestUser stores a user directly in the repository, skipping the slow password hashing of sign-up.
+func createTestUser(t testing.TB, username string) models.User {
+	user := models.User{Username: username, Password: "not-a-hash"}
+	require.NoError(t, repository.Users.Create(context.Background(), &user))
+	return user
+}
+
+// mintToken signs a token for the user like SignIn does.
+func mintToken(t testing.TB, user models.User) string {
+	claims := utils.Tokens.NewClaims(user.ID.Hex(), "", time.Now(), time.Now().Add(time.Minute))
+	tokenString, err := jwtSecret.SignToken(claims)
+	require.NoError(t, err)
+	return tokenString
+}
+
+// doRequest sends a request to the test app, with body encoded as JSON and the token in the
+// Authorization header when given.
+func doRequest(t testing.TB, method, path string, body interface{}, token string) *http.Response {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	resp, err := testApp.Test(req, -1)
+	require.NoError(t, err)
+	return resp
+}
+
+// decodeBody decodes a JSON response body into v.
+func decodeBody(t testing.TB, resp *http.Response, v interface{}) {
+	defer resp.Body.Close()
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+}
+
+// pdfText reads back the text of all pages of a PDF response body.
+func pdfText(t testing.TB, body []byte) string {
+	r, err := pdf.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+	var text strings.Builder
+	for page := 1; page <= r.NumPage(); page++ {
+		content, err := r.Page(page).GetPlainText(nil)
+		require.NoError(t, err)
+		text.WriteString(content)
+	}
+	return text.String()
+}
@@ -0,0 +1,280 @@
+// integrations.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/markdown"
+	"github.com/bkojha74/task-management/merge"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/pagination"
+	"github.com/bkojha74/task-management/planning"
+	"github.com/bkojha74/task-management/readmodel"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Page sizes of the polling triggers
+const (
+	triggerDefaultLimit = 50
+	triggerMaxLimit     = 100
+)
+
+// The endpoints for no-code automation platforms such as Zapier and IFTTT answer with bare JSON objects and
+// arrays, whatever the envelope settings, since that is what those platforms parse.
+
+// integrationUser is the response of GetIntegrationUser.
+type integrationUser struct {
+	ID       primitive.ObjectID `json:"id"`
+	Username string             `json:"username"`
+}
+
+// triggeredTask is an item of the new task trigger.
+type triggeredTask struct {
+	models.Task
+	Cursor string `json:"cursor"` // The since value returning the tasks created after this one
+}
+
+// completedTask is an item of the task completed trigger. Its id identifies the completion rather than the
+// task, so that a task completed, reopened and completed again triggers twice.
+type completedTask struct {
+	models.Task
+	ID     string             `json:"id"`      // <task id>-<completed_at in Unix milliseconds>
+	TaskID primitive.ObjectID `json:"task_id"` // The completed task
+	Cursor string             `json:"cursor"`  // The since value returning the completions after this one
+}
+
+// completeTaskRequest is the body of CompleteTaskAction.
+type completeTaskRequest struct {
+	ID string `json:"id"`
+}
+
+// GetIntegrationUser returns the user an API key belongs to. Automation platforms call it to test the key
+// and to label the connected account.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetIntegrationUser(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(integrationUser{ID: user.ID, Username: user.Username})
+}
+
+// NewTaskTrigger is the polling trigger for new tasks. It returns the tasks of the user created after the
+// "since" cursor, newest first; the cursor is the ID of the newest task seen. "assigned=true" polls the
+// tasks allotted to the user instead of those they own, and "limit" caps the result at 100 (50 by default).
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func NewTaskTrigger(c *fiber.Ctx) error {
+	query, err := triggerQuery(c)
+	if err != nil {
+		return err
+	}
+	var since primitive.ObjectID
+	if value := c.Query("since"); value != "" {
+		if since, err = primitive.ObjectIDFromHex(value); err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid since cursor")
+		}
+		// IDs are taken just before the task is stored, so no newer task was created before the ID's time
+		query.CreatedAfter = since.Timestamp()
+	}
+	query.Sort = pagination.Sort{Field: "_id", Descending: true}
+
+	tasks, err := repository.Tasks.Find(c.UserContext(), query)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+	}
+	items := []triggeredTask{}
+	for _, task := range tasks {
+		if len(items) == query.Limit {
+			break
+		}
+		if task.ID.Hex() <= since.Hex() {
+			continue
+		}
+		items = append(items, triggeredTask{Task: task, Cursor: task.ID.Hex()})
+	}
+	return c.Status(fiber.StatusOK).JSON(items)
+}
+
+// TaskCompletedTrigger is the polling trigger for completed tasks. It returns the tasks of the user completed
+// after the "since" cursor, most recent completion first; the cursor is the completed_at of the newest
+// completion seen, in Unix milliseconds. "assigned" and "limit" work like for NewTaskTrigger.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func TaskCompletedTrigger(c *fiber.Ctx) error {
+	query, err := triggerQuery(c)
+	if err != nil {
+		return err
+	}
+	if value := c.Query("since"); value != "" {
+		millis, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || millis < 0 {
+			return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid since cursor")
+		}
+		query.CompletedAfter = time.UnixMilli(millis)
+	}
+	query.Statuses = []string{models.TaskStatusDone}
+	query.Sort = pagination.Sort{Field: "completed_at", Descending: true}
+
+	tasks, err := repository.Tasks.Find(c.UserContext(), query)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+	}
+	items := []completedTask{}
+	for _, task := range tasks {
+		if len(items) == query.Limit {
+			break
+		}
+		cursor := strconv.FormatInt(int64(task.CompletedAt), 10)
+		items = append(items, completedTask{Task: task, ID: task.ID.Hex() + "-" + cursor, TaskID: task.ID, Cursor: cursor})
+	}
+	return c.Status(fiber.StatusOK).JSON(items)
+}
+
+// CreateTaskAction is the action creating a task. It takes the fields of a task like CreateTask, with
+// allotted_to defaulting to the user and "due" accepting phrases such as "next friday 5pm", and returns
+// the new task.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func CreateTaskAction(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	location, err := localizeRequest(c, "start_time", "end_time")
+	if err != nil {
+		return err
+	}
+	var task models.Task
+	if err := c.BodyParser(&task); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	if task.Title == "" {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Title is required")
+	}
+	if task.AllottedTo == "" {
+		task.AllottedTo = user.Username
+	} else if _, err := repository.Users.FindByUsername(c.UserContext(), task.AllottedTo); err != nil {
+		if err == repository.ErrNotFound {
+			return apierror.BadRequest(apierror.CodeValidationFailed, "Allotted user does not exist")
+		}
+		return apierror.Internal(apierror.CodeInternal, "Error checking allotted user")
+	}
+
+	initTask(&task, user.ID)
+	if err := applyDue(&task, location); err != nil {
+		return err
+	}
+	task.DescriptionHTML = markdown.Render(task.Description)
+	if err := normalizePriority(&task); err != nil {
+		return err
+	}
+	if err := normalizeVisibility(&task, nil); err != nil {
+		return err
+	}
+	if err := validatePlanning(c, task); err != nil {
+		return err
+	}
+	planning.TrackEffort(&task, nil, time.Now())
+	planning.TrackOverdue(&task, nil, time.Now())
+
+	if err := storeNewTask(&task); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(task)
+}
+
+// CompleteTaskAction is the action marking a task done. It takes the task's "id" and returns the task;
+// completing a done task changes nothing.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func CompleteTaskAction(c *fiber.Ctx) error {
+	var request completeTaskRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	taskId, err := primitive.ObjectIDFromHex(request.ID)
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID")
+	}
+	previous, err := findTask(c, taskId, true)
+	if err != nil {
+		return err
+	}
+	if previous.Status == models.TaskStatusDone {
+		return c.Status(fiber.StatusOK).JSON(previous)
+	}
+
+	task := *previous
+	task.Status = models.TaskStatusDone
+	planning.TrackEffort(&task, previous, time.Now())
+	planning.TrackOverdue(&task, previous, time.Now())
+	task.Version = previous.Version + 1
+	merge.Track(&task, previous)
+
+	err = outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := repository.Tasks.Update(ctx, &task, previous.Version); err != nil {
+			return err
+		}
+		return outbox.Publish(ctx, events.TaskCompleted, task.ID.Hex(), task)
+	})
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return apierror.Conflict(apierror.CodeVersionConflict, "Task was modified by someone else, reload it and retry")
+		}
+		return apierror.Internal(apierror.CodeInternal, "Could not complete task")
+	}
+	cache.InvalidateTask(task.UserID.Hex(), task.ID.Hex())
+	readmodel.SyncOrLog(context.Background(), task)
+	return c.Status(fiber.StatusOK).JSON(task)
+}
+
+// triggerQuery returns the query of a polling trigger for the tasks of the user, with its limit.
+func triggerQuery(c *fiber.Ctx) (repository.TaskQuery, error) {
+	user, err := currentUser(c)
+	if err != nil {
+		return repository.TaskQuery{}, err
+	}
+	limit := c.QueryInt("limit", triggerDefaultLimit)
+	if limit < 1 || limit > triggerMaxLimit {
+		return repository.TaskQuery{}, apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid limit")
+	}
+
+	query := repository.TaskQuery{UserID: user.ID, Limit: limit}
+	if c.QueryBool("assigned") {
+		query = repository.TaskQuery{AllottedTo: user.Username, Limit: limit}
+	}
+	return query, nil
+}
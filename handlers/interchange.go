@@ -0,0 +1,197 @@
+// interchange.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"strings"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/i18n"
+	"github.com/bkojha74/task-management/interchange"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// droppedFieldsHeader lists the fields an export to another format left out, comma-separated.
+const droppedFieldsHeader = "X-Dropped-Fields"
+
+// Outcomes of the import of one task
+const (
+	importCreated  = "created"
+	importValid    = "valid" // Would be created, in a dry run
+	importRejected = "rejected"
+)
+
+// importResult is the outcome of the import of one task.
+type importResult struct {
+	Index  int             `json:"index"` // Position among the tasks read from the file
+	Title  string          `json:"title"`
+	Status string          `json:"status"`         // created, valid or rejected
+	Task   *models.Task    `json:"task,omitempty"` // The created task, or the task a dry run would create
+	Error  *apierror.Error `json:"error,omitempty"`
+}
+
+// exportTasks writes the tasks of the logged-in user, or of one of their projects with "project_id", in the
+// format of another task manager, see package interchange. The fields that had values but could not be
+// carried over are listed in the X-Dropped-Fields header.
+func exportTasks(c *fiber.Ctx, user *models.User, format string) error {
+	name, query := "Tasks", repository.TaskQuery{UserID: user.ID}
+	if projectId := c.Query("project_id"); projectId != "" {
+		project, err := findOwnProject(c, projectId)
+		if err != nil {
+			return err
+		}
+		name, query.ProjectID = project.Name, project.ID
+	}
+	tasks, err := repository.Tasks.Find(c.UserContext(), query)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+	}
+
+	data, contentType, report, err := interchange.Export(format, name, tasks, userLocation(user))
+	if err == interchange.ErrUnknownFormat {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "format must be json, trello or todoist")
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error writing export")
+	}
+
+	extension := ".json"
+	if format == interchange.FormatTodoist {
+		extension = ".csv"
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="tasks-`+format+extension+`"`)
+	if len(report.Dropped) > 0 {
+		c.Set(droppedFieldsHeader, strings.Join(report.Dropped, ","))
+	}
+	return c.Status(fiber.StatusOK).Send(data)
+}
+
+// ImportTasks creates tasks for the logged-in user from the file of another task manager sent as the body:
+// a Trello board export with "format=trello" or a Todoist template with "format=todoist", see package
+// interchange. "project_id" puts the tasks into one of the user's projects. Each task is validated and
+// created like POST /tasks does, on its own, so that one rejected task does not hold back the others;
+// assignees without an account here and dates that cannot be read are dropped with a warning. The response
+// gives the outcome of each task in the order of the file with the field mapping report of the
+// conversion. With "dry_run=true" the tasks are only checked.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func ImportTasks(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	var projectId primitive.ObjectID
+	if id := c.Query("project_id"); id != "" {
+		project, err := findOwnProject(c, id)
+		if err != nil {
+			return err
+		}
+		projectId = project.ID
+	}
+
+	tasks, report, err := interchange.Import(c.Query("format"), c.Body())
+	if err == interchange.ErrUnknownFormat {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "format must be trello or todoist")
+	}
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeValidationFailed, err.Error())
+	}
+	if len(tasks) > interchange.MaxTasks {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "An import holds at most 1000 tasks")
+	}
+
+	dryRun := c.QueryBool("dry_run")
+	languages := i18n.Languages(c.Get(fiber.HeaderAcceptLanguage))
+	results := make([]importResult, len(tasks))
+	counts := map[string]int{importCreated: 0, importValid: 0, importRejected: 0}
+	for i := range tasks {
+		tasks[i].ProjectID = projectId
+		results[i] = importTask(c, user, &tasks[i], &report, dryRun)
+		results[i].Index = i
+		if apiErr := results[i].Error; apiErr != nil {
+			localized := *apiErr
+			localized.Message, _ = i18n.Translate(apiErr.Message, languages)
+			results[i].Error = &localized
+		}
+		counts[results[i].Status]++
+	}
+
+	status := fiber.StatusOK
+	if counts[importCreated] > 0 {
+		status = fiber.StatusCreated
+	}
+	return response.JSON(c, status, fiber.Map{
+		"results":  results,
+		"created":  counts[importCreated],
+		"valid":    counts[importValid],
+		"rejected": counts[importRejected],
+		"report":   report,
+	})
+}
+
+// importTask creates one imported task, or only checks that it could be created in a dry run. The status
+// and start date read from the file are kept.
+func importTask(c *fiber.Ctx, user *models.User, task *models.Task, report *interchange.Report, dryRun bool) importResult {
+	result := importResult{Title: task.Title}
+	reject := func(err error) importResult {
+		apiErr, ok := err.(*apierror.Error)
+		if !ok {
+			apiErr = apierror.Internal(apierror.CodeInternal, "internal server error")
+		}
+		result.Status, result.Error = importRejected, apiErr
+		return result
+	}
+
+	turn, err := autoAssign(c, task)
+	if err != nil {
+		return reject(err)
+	}
+	defer func() {
+		if result.Status != importCreated {
+			releaseTurn(turn)
+		}
+	}()
+	if task.AllottedTo == "" {
+		task.AllottedTo = user.Username
+	} else if _, err := repository.Users.FindByUsername(c.UserContext(), task.AllottedTo); err == repository.ErrNotFound {
+		report.Warn("Tasks of assignees without an account are assigned to you")
+		task.AllottedTo = user.Username
+	} else if err != nil {
+		return reject(apierror.Internal(apierror.CodeInternal, "Error checking allotted user"))
+	}
+
+	status, start := task.Status, task.StartDate
+	initTask(task, user.ID)
+	task.Status = status
+	if !start.IsZero() {
+		task.StartDate = start
+	}
+	if task.Due != "" && applyDue(task, userLocation(user)) != nil {
+		report.Warn("Dates that cannot be read, such as recurring ones, are left out")
+		task.Due = ""
+	}
+	if err := prepareNewTask(c, user, task); err != nil {
+		return reject(err)
+	}
+	if dryRun {
+		result.Status, result.Task = importValid, task
+		return result
+	}
+
+	if err := storeNewTask(task); err != nil {
+		return reject(err)
+	}
+	result.Status, result.Task = importCreated, task
+	return result
+}
@@ -0,0 +1,205 @@
+// invitations.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+	"github.com/bkojha74/task-management/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Lifetimes of invitations and of the guest tokens issued when they are accepted
+const (
+	invitationExpiry = 7 * 24 * time.Hour
+	guestTokenExpiry = 30 * 24 * time.Hour
+)
+
+// invitationRequest is the body of InviteGuest.
+type invitationRequest struct {
+	Email string `json:"email"`
+}
+
+// createdInvitation is the response of InviteGuest, the only time the invitation token is shown.
+type createdInvitation struct {
+	models.Invitation
+	Token     string `json:"token"`
+	AcceptURL string `json:"accept_url"`
+}
+
+// guestToken is the response of AcceptInvitation.
+type guestToken struct {
+	Token     string             `json:"token"`
+	ProjectID primitive.ObjectID `json:"project_id"`
+	ExpiresAt time.Time          `json:"expires_at"`
+}
+
+// InviteGuest invites an external email address to a project of the logged-in user as a read-only guest.
+// The response carries the invitation token and the URL accepting it, for the owner to forward to the
+// guest; the invitation must be accepted within 7 days.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func InviteGuest(c *fiber.Ctx) error {
+	project, err := findOwnProject(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var request invitationRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	address, err := mail.ParseAddress(request.Email)
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "email must be a valid email address")
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not create invitation")
+	}
+	now := time.Now()
+	invitation := models.Invitation{
+		ID:        primitive.NewObjectID(),
+		ProjectID: project.ID,
+		OwnerID:   project.OwnerID,
+		Email:     strings.ToLower(address.Address),
+		Role:      models.InvitationRoleViewer,
+		TokenHash: hashInvitationToken(token),
+		CreatedAt: models.NewTimestamp(now),
+		ExpiresAt: models.NewTimestamp(now.Add(invitationExpiry)),
+	}
+	if err := repository.Invitations.Create(context.Background(), &invitation); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not create invitation")
+	}
+
+	return response.JSON(c, fiber.StatusCreated, createdInvitation{
+		Invitation: invitation,
+		Token:      token,
+		AcceptURL:  "/invitations/" + token + "/accept",
+	})
+}
+
+// GetInvitations lists the invitations to a project of the logged-in user, oldest first, including
+// accepted and revoked ones.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetInvitations(c *fiber.Ctx) error {
+	project, err := findOwnProject(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	invitations, err := repository.Invitations.Find(c.UserContext(), project.ID)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching invitations")
+	}
+
+	return response.JSON(c, fiber.StatusOK, invitations)
+}
+
+// RevokeInvitation revokes an invitation to a project of the logged-in user. A pending invitation can no
+// longer be accepted, and the guest token of an accepted one is rejected from the next request on.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func RevokeInvitation(c *fiber.Ctx) error {
+	project, err := findOwnProject(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+	invitationId, err := primitive.ObjectIDFromHex(c.Params("invitationId"))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid invitation ID")
+	}
+
+	err = repository.Invitations.Revoke(context.Background(), project.ID, invitationId, time.Now())
+	if err == repository.ErrNotFound {
+		return apierror.NotFound(apierror.CodeNotFound, "Invitation not found")
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not revoke invitation")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// AcceptInvitation accepts an invitation with the token from the invitation URL, without an account, and
+// returns a guest token to send in the Authorization header. The guest token grants read access to the
+// project, its board and its tasks for 30 days, until the invitation is revoked. An invitation can be
+// accepted once.
+//
+// Parameters:
+// - signing: The secret the guest token is signed with.
+//
+// Returns:
+// - fiber.Handler: The handler accepting invitations.
+func AcceptInvitation(signing *utils.SigningSecret) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		invitation, err := repository.Invitations.FindByTokenHash(c.UserContext(), hashInvitationToken(c.Params("token")))
+		if err == repository.ErrNotFound {
+			return apierror.NotFound(apierror.CodeNotFound, "Invitation not found, expired or already accepted")
+		}
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Error fetching invitation")
+		}
+
+		now := time.Now()
+		err = repository.Invitations.Accept(context.Background(), invitation.ID, now)
+		if err == repository.ErrNotFound {
+			return apierror.NotFound(apierror.CodeNotFound, "Invitation not found, expired or already accepted")
+		}
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Could not accept invitation")
+		}
+
+		expiresAt := now.Add(guestTokenExpiry)
+		claims := utils.Tokens.NewGuestClaims(invitation.OwnerID.Hex(), invitation.ProjectID.Hex(), invitation.ID.Hex(), invitation.Email, now, expiresAt)
+		token, err := signing.SignToken(claims)
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Could not accept invitation")
+		}
+
+		return response.JSON(c, fiber.StatusOK, guestToken{Token: token, ProjectID: invitation.ProjectID, ExpiresAt: expiresAt.UTC().Truncate(time.Second)})
+	}
+}
+
+// newInvitationToken returns a random invitation token, URL-safe.
+func newInvitationToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// hashInvitationToken returns the SHA-256 hash of an invitation token in hex, as stored.
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,132 @@
+// invites.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+	"github.com/bkojha74/task-management/signup"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Limits of invite codes
+const (
+	defaultInviteExpiry = 7 * 24 * time.Hour
+	maxInviteExpiry     = 90 * 24 * time.Hour
+	maxInviteUses       = 1000
+)
+
+// inviteCodeRequest is the body of CreateInviteCode.
+type inviteCodeRequest struct {
+	MaxUses   int    `json:"max_uses"`   // Sign-ups the code allows; 1 by default
+	ExpiresIn string `json:"expires_in"` // Go duration, e.g. "48h"; 168h by default
+	Note      string `json:"note"`       // Who the code is for, shown in the list
+}
+
+// createdInviteCode is the response of CreateInviteCode: the stored code and, this one time, the code itself.
+type createdInviteCode struct {
+	models.InviteCode
+	Code string `json:"code"`
+}
+
+// CreateInviteCode creates an invite code for sign-up while registration is invitation-only. The code is
+// returned once and only its hash is stored, so an admin who loses it creates a new one.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func CreateInviteCode(c *fiber.Ctx) error {
+	adminId, err := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid user ID")
+	}
+
+	var request inviteCodeRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+		}
+	}
+	if request.MaxUses == 0 {
+		request.MaxUses = 1
+	}
+	if request.MaxUses < 0 || request.MaxUses > maxInviteUses {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "max_uses must be between 1 and 1000")
+	}
+	expiresIn := defaultInviteExpiry
+	if request.ExpiresIn != "" {
+		expiresIn, err = time.ParseDuration(request.ExpiresIn)
+		if err != nil || expiresIn <= 0 || expiresIn > maxInviteExpiry {
+			return apierror.BadRequest(apierror.CodeValidationFailed, "expires_in must be a positive duration of at most 2160h, e.g. \"48h\"")
+		}
+	}
+
+	code, err := signup.NewInviteCode()
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not create invite code")
+	}
+	now := time.Now()
+	invite := models.InviteCode{
+		CodeHash:  signup.HashInviteCode(code),
+		Note:      request.Note,
+		CreatedBy: adminId,
+		MaxUses:   request.MaxUses,
+		CreatedAt: models.NewTimestamp(now),
+		ExpiresAt: models.NewTimestamp(now.Add(expiresIn)),
+	}
+	if err := repository.InviteCodes.Create(context.Background(), &invite); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not create invite code")
+	}
+
+	return response.JSON(c, fiber.StatusCreated, createdInviteCode{InviteCode: invite, Code: code})
+}
+
+// GetInviteCodes lists the invite codes, oldest first, including used up, expired and revoked ones.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetInviteCodes(c *fiber.Ctx) error {
+	codes, err := repository.InviteCodes.Find(c.UserContext())
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching invite codes")
+	}
+	return response.JSON(c, fiber.StatusOK, codes)
+}
+
+// RevokeInviteCode revokes an invite code, so that no one can sign up with it any more. Accounts already
+// created with it are kept.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func RevokeInviteCode(c *fiber.Ctx) error {
+	inviteId, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid invite code ID")
+	}
+
+	err = repository.InviteCodes.Revoke(context.Background(), inviteId, time.Now())
+	if err == repository.ErrNotFound {
+		return apierror.NotFound(apierror.CodeNotFound, "Invite code not found")
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not revoke invite code")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
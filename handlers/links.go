@@ -0,0 +1,76 @@
+// links.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"net/url"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// linkedTask is a task with its hypermedia links, as sent in envelope responses.
+type linkedTask struct {
+	models.Task
+	Links response.Links `json:"_links"`
+}
+
+// linkedTaskEntry is a task of a list with its hypermedia links, as sent in envelope responses.
+type linkedTaskEntry struct {
+	models.TaskListEntry
+	Links response.Links `json:"_links"`
+}
+
+// taskLinks returns the links to a task and the actions on it.
+func taskLinks(task models.Task) response.Links {
+	self := "/tasks/" + task.ID.Hex()
+	links := response.Links{
+		"self":     {Href: self},
+		"update":   {Href: self, Method: fiber.MethodPut},
+		"patch":    {Href: self, Method: fiber.MethodPatch},
+		"delete":   {Href: self, Method: fiber.MethodDelete},
+		"snooze":   {Href: self + "/snooze", Method: fiber.MethodPost},
+		"share":    {Href: self + "/share", Method: fiber.MethodPost},
+		"reassign": {Href: self + "/reassign", Method: fiber.MethodPost},
+		"comments": {Href: self + "/comments"},
+	}
+	if !task.ProjectID.IsZero() {
+		links["project"] = response.Link{Href: "/projects/" + task.ProjectID.Hex()}
+		links["board"] = response.Link{Href: "/boards/" + task.ProjectID.Hex()}
+	}
+	return links
+}
+
+// withTaskLinks adds the links to a task in envelope responses; bare responses keep the plain task.
+func withTaskLinks(c *fiber.Ctx, task models.Task) interface{} {
+	if !response.Enabled(c) {
+		return task
+	}
+	return linkedTask{Task: task, Links: taskLinks(task)}
+}
+
+// withTaskListLinks adds the links to each task of a list in envelope responses.
+func withTaskListLinks(c *fiber.Ctx, tasks []models.TaskListEntry) interface{} {
+	if !response.Enabled(c) {
+		return tasks
+	}
+	linked := make([]linkedTaskEntry, len(tasks))
+	for i, task := range tasks {
+		linked[i] = linkedTaskEntry{TaskListEntry: task, Links: taskLinks(task.Task)}
+	}
+	return linked
+}
+
+// addPageLinks links a list response to itself and, when there is one, to its next page.
+func addPageLinks(c *fiber.Ctx, nextCursor string) {
+	response.AddLink(c, "self", response.Link{Href: c.OriginalURL()})
+	if nextCursor == "" {
+		return
+	}
+	query, _ := url.ParseQuery(string(c.Request().URI().QueryString()))
+	query.Set("cursor", nextCursor)
+	response.AddLink(c, "next", response.Link{Href: c.Path() + "?" + query.Encode()})
+}
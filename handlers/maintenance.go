@@ -0,0 +1,63 @@
+// maintenance.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetMaintenance returns the maintenance mode of the API.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetMaintenance(c *fiber.Ctx) error {
+	maintenance, err := repository.Maintenance.Get(context.Background())
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching maintenance mode")
+	}
+	return response.JSON(c, fiber.StatusOK, maintenance)
+}
+
+// UpdateMaintenance switches the maintenance mode of the API, e.g. to read-only during a migration and
+// back off afterwards. All instances pick up the new mode within their refresh interval.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func UpdateMaintenance(c *fiber.Ctx) error {
+	var maintenance models.Maintenance
+	if err := c.BodyParser(&maintenance); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+
+	switch maintenance.Mode {
+	case models.MaintenanceOff, models.MaintenanceReadOnly, models.MaintenanceFull:
+	default:
+		return apierror.BadRequest(apierror.CodeValidationFailed, "mode must be off, read-only or full")
+	}
+	if maintenance.RetryAfter < 0 {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "retry_after must not be negative")
+	}
+
+	maintenance.UpdatedAt = models.NewTimestamp(time.Now())
+	maintenance.UpdatedBy = c.Locals("userId").(string)
+	if err := repository.Maintenance.Set(context.Background(), maintenance); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not update maintenance mode")
+	}
+
+	return response.JSON(c, fiber.StatusOK, maintenance)
+}
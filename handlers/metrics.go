@@ -0,0 +1,26 @@
+// metrics.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CacheStats returns the hit/miss metrics of the task cache.
+// When caching is disabled it reports {"enabled": false}.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func CacheStats(c *fiber.Ctx) error {
+	if cache.Store == nil {
+		return response.JSON(c, fiber.StatusOK, fiber.Map{"enabled": false})
+	}
+	return response.JSON(c, fiber.StatusOK, fiber.Map{"enabled": true, "stats": cache.Store.Stats()})
+}
@@ -0,0 +1,85 @@
+// notifications.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/notifications"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetNotificationSettings returns the chat channels the logged-in user receives notifications in and when.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetNotificationSettings(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	settings := models.NotificationSettings{}
+	if user.Notifications != nil {
+		settings = *user.Notifications
+	}
+	return response.JSON(c, fiber.StatusOK, settings)
+}
+
+// UpdateNotificationSettings replaces the Slack webhook, Teams connector, subscribed events and delivery
+// preferences (channels per event, quiet hours and digest-only mode) of the logged-in user. Empty webhooks
+// turn a channel off; an empty event list subscribes to all events.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func UpdateNotificationSettings(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	var settings models.NotificationSettings
+	if err := c.BodyParser(&settings); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	if err := notifications.ValidateSettings(settings); err != nil {
+		return apierror.BadRequest(apierror.CodeValidationFailed, err.Error())
+	}
+
+	user.Notifications = &settings
+	if err := repository.Users.Update(context.Background(), user); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not update notification settings")
+	}
+
+	return response.JSON(c, fiber.StatusOK, settings)
+}
+
+// currentUser loads the logged-in user.
+func currentUser(c *fiber.Ctx) (*models.User, error) {
+	userIdHex, err := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+	if err != nil {
+		return nil, apierror.Internal(apierror.CodeInternal, "Invalid user ID")
+	}
+
+	user, err := repository.Users.FindByID(c.UserContext(), userIdHex)
+	if err == repository.ErrNotFound {
+		return nil, apierror.NotFound(apierror.CodeNotFound, "User not found")
+	}
+	if err != nil {
+		return nil, apierror.Internal(apierror.CodeInternal, "Error fetching user")
+	}
+	return user, nil
+}
@@ -0,0 +1,165 @@
+// order.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/planning"
+	"github.com/bkojha74/task-management/readmodel"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// reorderRequest is the body of ReorderTask.
+type reorderRequest struct {
+	TaskID   string `json:"task_id"`
+	AfterID  string `json:"after_id"`  // Task to place the moved task below
+	BeforeID string `json:"before_id"` // Task to place the moved task above
+}
+
+// PinTask pins a task, which puts it at the top of the manual order of GET /tasks?sort=manual.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func PinTask(c *fiber.Ctx) error {
+	return setPinned(c, true)
+}
+
+// UnpinTask unpins a task, which returns it to its position among the unpinned tasks.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func UnpinTask(c *fiber.Ctx) error {
+	return setPinned(c, false)
+}
+
+// setPinned pins or unpins the task of the request.
+func setPinned(c *fiber.Ctx, pinned bool) error {
+	taskIdHex, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID")
+	}
+	previous, err := findTask(c, taskIdHex, true)
+	if err != nil {
+		return err
+	}
+
+	task := *previous
+	if task.Pinned != pinned {
+		task.Pinned = pinned
+		task.Version = previous.Version + 1
+		if err := repository.Tasks.Update(context.Background(), &task, previous.Version); err != nil {
+			if err == repository.ErrNotFound {
+				return apierror.Conflict(apierror.CodeVersionConflict, "Task was modified by someone else, reload it and retry")
+			}
+			return apierror.Internal(apierror.CodeInternal, "Could not update task")
+		}
+		cache.InvalidateTask(task.UserID.Hex(), task.ID.Hex())
+		readmodel.SyncOrLog(context.Background(), task)
+	}
+
+	c.Set(fiber.HeaderETag, versionTag(task.Version))
+	return response.JSON(c, fiber.StatusOK, withTaskLinks(c, task))
+}
+
+// ReorderTask moves one of the caller's tasks in their manual order, between the tasks after_id and
+// before_id of the same pin group (either may be omitted; with neither the task goes to the bottom of its
+// group). The order is stored as task positions: usually only the moved task is rewritten. When its group
+// has to be re-ranked, every changed position is written against the version that was read, so that
+// concurrent reorders conflict instead of interleaving, and all of them in one outbox transaction, which
+// is a MongoDB transaction when the outbox is enabled. Returns the moved task.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func ReorderTask(c *fiber.Ctx) error {
+	var request reorderRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	taskIdHex, err := primitive.ObjectIDFromHex(request.TaskID)
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID")
+	}
+	afterID, err := optionalObjectID(request.AfterID)
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid after_id")
+	}
+	beforeID, err := optionalObjectID(request.BeforeID)
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid before_id")
+	}
+
+	moved, err := findTask(c, taskIdHex, true)
+	if err != nil {
+		return err
+	}
+	tasks, err := repository.Tasks.Find(c.UserContext(), repository.TaskQuery{UserID: moved.UserID})
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+	}
+	group := []models.Task{}
+	byID := map[primitive.ObjectID]models.Task{}
+	for _, task := range tasks {
+		byID[task.ID] = task
+		if task.Pinned == moved.Pinned && task.ID != moved.ID {
+			group = append(group, task)
+		}
+	}
+	planning.SortManual(group)
+
+	positions, err := planning.PlaceTask(group, moved.ID, afterID, beforeID)
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "after_id and before_id must be adjacent tasks of the same pin group")
+	}
+
+	updated := []models.Task{}
+	err = outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		updated = updated[:0]
+		for id, position := range positions {
+			previous := byID[id]
+			task := previous
+			task.Position = position
+			task.Version = previous.Version + 1
+			if err := repository.Tasks.Update(ctx, &task, previous.Version); err != nil {
+				return err
+			}
+			updated = append(updated, task)
+		}
+		return nil
+	})
+	if err == repository.ErrNotFound {
+		return apierror.Conflict(apierror.CodeVersionConflict, "Tasks were modified by someone else, reload them and retry")
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not reorder tasks")
+	}
+
+	var task models.Task
+	for _, written := range updated {
+		cache.InvalidateTask(written.UserID.Hex(), written.ID.Hex())
+		readmodel.SyncOrLog(context.Background(), written)
+		if written.ID == moved.ID {
+			task = written
+		}
+	}
+	c.Set(fiber.HeaderETag, versionTag(task.Version))
+	return response.JSON(c, fiber.StatusOK, withTaskLinks(c, task))
+}
@@ -0,0 +1,165 @@
+// patch.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/markdown"
+	"github.com/bkojha74/task-management/merge"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/planning"
+	"github.com/bkojha74/task-management/readmodel"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+	"github.com/bkojha74/task-management/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxPatchAttempts is how often PatchTask merges a patch again when the task is written concurrently.
+const maxPatchAttempts = 3
+
+// PatchTask changes some fields of a task and leaves the others alone. The client sends the version it
+// last read, in the "version" field or an If-Match header, and instead of rejecting the whole patch when
+// the task has been changed since, the server merges it field by field: fields nobody else changed are
+// applied, and fields changed by someone else to another value keep the server's value and are reported
+// as conflicts. Conflicts are listed under "conflicts" in the envelope metadata and sent as warnings; if
+// every patched field conflicts, nothing is written and the patch is rejected with 409 Conflict. Requests
+// without a version patch whatever version is current.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func PatchTask(c *fiber.Ctx) error {
+	userId := c.Locals("userId").(string)
+	taskIdHex, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID")
+	}
+
+	if _, err := localizeRequest(c, "start_time", "end_time"); err != nil {
+		return err
+	}
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(c.Body(), &patch); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+
+	base := 0
+	if version, ok := patch["version"]; ok {
+		if err := json.Unmarshal(version, &base); err != nil {
+			return apierror.BadRequest(apierror.CodeValidationFailed, "version must be a number")
+		}
+		delete(patch, "version")
+	}
+	if ifMatch := c.Get(fiber.HeaderIfMatch); ifMatch != "" {
+		if base, err = parseVersionTag(ifMatch); err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidHeader, "Invalid If-Match header")
+		}
+	}
+	if len(patch) == 0 {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Patch must change at least one field")
+	}
+
+	for attempt := 1; ; attempt++ {
+		previous, err := findTask(c, taskIdHex, true)
+		if err != nil {
+			return err
+		}
+
+		task, conflicts, err := patchTask(c, previous, patch, base)
+		if err == repository.ErrNotFound && attempt < maxPatchAttempts {
+			continue // Written by someone else since it was read, merge again
+		}
+		if err == repository.ErrNotFound {
+			return apierror.Conflict(apierror.CodeVersionConflict, "Task was modified by someone else, reload it and retry")
+		}
+		if err != nil {
+			return err
+		}
+
+		addWarnings(c, validation.TaskWarnings(c.UserContext(), *task))
+		if len(conflicts) > 0 {
+			response.AddMeta(c, "conflicts", conflicts)
+			for _, conflict := range conflicts {
+				response.AddWarning(c, apierror.CodeVersionConflict, "Field "+conflict.Field+" was changed by someone else and was not updated")
+			}
+		}
+
+		cache.InvalidateTask(userId, task.ID.Hex())
+		readmodel.SyncOrLog(context.Background(), *task)
+		c.Set(fiber.HeaderETag, versionTag(task.Version))
+		return response.JSON(c, fiber.StatusOK, withTaskLinks(c, *task))
+	}
+}
+
+// patchTask merges a patch made on the base version into the stored version of a task and writes the
+// result. It returns repository.ErrNotFound if the task was written since it was read.
+func patchTask(c *fiber.Ctx, previous *models.Task, patch map[string]json.RawMessage, base int) (*models.Task, []merge.Conflict, error) {
+	if base == 0 {
+		base = previous.Version
+	}
+	task, conflicts, err := merge.Apply(*previous, patch, base)
+	if fieldErr, ok := err.(*merge.FieldError); ok {
+		return nil, nil, apierror.BadRequest(apierror.CodeValidationFailed, "Field cannot be patched").
+			WithDetails(fiber.Map{"field": fieldErr.Field})
+	}
+	if err != nil {
+		return nil, nil, apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	if len(conflicts) == len(patch) {
+		return nil, nil, apierror.Conflict(apierror.CodeVersionConflict, "Task was modified by someone else, reload it and retry").
+			WithDetails(fiber.Map{"current_version": previous.Version, "conflicts": conflicts})
+	}
+
+	if task.AllottedTo != previous.AllottedTo {
+		if _, err := repository.Users.FindByUsername(c.UserContext(), task.AllottedTo); err != nil {
+			if err == repository.ErrNotFound {
+				return nil, nil, apierror.BadRequest(apierror.CodeValidationFailed, "Allotted user does not exist")
+			}
+			return nil, nil, apierror.Internal(apierror.CodeInternal, "Error checking allotted user")
+		}
+	}
+	task.Version = previous.Version + 1
+	task.DescriptionHTML = markdown.Render(task.Description)
+	if err := normalizePriority(&task); err != nil {
+		return nil, nil, err
+	}
+	if err := normalizeVisibility(&task, previous); err != nil {
+		return nil, nil, err
+	}
+	if err := validatePlanning(c, task); err != nil {
+		return nil, nil, err
+	}
+	planning.TrackEffort(&task, previous, time.Now())
+	planning.TrackOverdue(&task, previous, time.Now())
+	merge.Track(&task, previous)
+
+	err = outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := repository.Tasks.Update(ctx, &task, previous.Version); err != nil {
+			return err
+		}
+		if task.Status == models.TaskStatusDone && previous.Status != models.TaskStatusDone {
+			return outbox.Publish(ctx, events.TaskCompleted, task.ID.Hex(), task)
+		}
+		return nil
+	})
+	if err == repository.ErrNotFound {
+		return nil, nil, err
+	}
+	if err != nil {
+		return nil, nil, apierror.Internal(apierror.CodeInternal, "Could not update task")
+	}
+	return &task, conflicts, nil
+}
@@ -0,0 +1,170 @@
+// projects.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/permissions"
+	"github.com/bkojha74/task-management/planning"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CreateProject creates a project owned by the logged-in user. The optional start_date and end_date
+// bound the project's sprint and are the default range of its burndown.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func CreateProject(c *fiber.Ctx) error {
+	userIdHex, err := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Invalid user ID")
+	}
+
+	if _, err := localizeRequest(c, "start_date", "end_date"); err != nil {
+		return err
+	}
+	var project models.Project
+	if err := c.BodyParser(&project); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	if project.Name == "" {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Project name is required")
+	}
+	if project.StartDate != 0 && project.EndDate != 0 && project.EndDate < project.StartDate {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Project end_date must not be before start_date")
+	}
+
+	project.ID = primitive.NewObjectID()
+	project.OwnerID = userIdHex
+	project.CreatedAt = primitive.NewDateTimeFromTime(time.Now())
+
+	if err := repository.Projects.Create(context.Background(), &project); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not create project")
+	}
+
+	return response.JSON(c, fiber.StatusCreated, project)
+}
+
+// GetProjects lists the projects of the logged-in user, oldest first.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetProjects(c *fiber.Ctx) error {
+	userIdHex, err := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Invalid user ID")
+	}
+
+	projects, err := repository.Projects.Find(c.UserContext(), userIdHex)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching projects")
+	}
+
+	return response.JSON(c, fiber.StatusOK, projects)
+}
+
+// GetProject returns a project of the logged-in user.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetProject(c *fiber.Ctx) error {
+	project, err := findOwnProject(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return response.JSON(c, fiber.StatusOK, project)
+}
+
+// GetProjectBurndown returns the daily remaining work of a project's tasks, by their estimate_minutes, for
+// sprint-style tracking. The range is given by the "from" and "to" query parameters (YYYY-MM-DD, UTC) and
+// defaults to the project's start and end dates, then to the project's creation and today.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetProjectBurndown(c *fiber.Ctx) error {
+	project, err := findOwnProject(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	from := project.CreatedAt.Time()
+	if project.StartDate != 0 {
+		from = project.StartDate.Time()
+	}
+	to := time.Now()
+	if project.EndDate != 0 {
+		to = project.EndDate.Time()
+	}
+	if from, err = parseDay(c.Query("from"), from); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid from date, use YYYY-MM-DD")
+	}
+	if to, err = parseDay(c.Query("to"), to); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid to date, use YYYY-MM-DD")
+	}
+
+	query := repository.TaskQuery{UserID: project.OwnerID, ProjectID: project.ID}
+	permissions.RestrictQuery(subjectOf(c), &query)
+	tasks, err := repository.ReportTasks.Find(c.UserContext(), query)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+	}
+
+	burndown, err := planning.ComputeBurndown(tasks, from, to)
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidQuery, "The date range must be at most 366 days and not end before it starts")
+	}
+
+	return response.JSON(c, fiber.StatusOK, burndown)
+}
+
+// findOwnProject loads a project of the logged-in user by its hex ID. Guests find only the project they
+// were invited to.
+func findOwnProject(c *fiber.Ctx, projectId string) (*models.Project, error) {
+	projectIdHex, err := primitive.ObjectIDFromHex(projectId)
+	if err != nil {
+		return nil, apierror.BadRequest(apierror.CodeInvalidID, "Invalid project ID")
+	}
+
+	userIdHex, _ := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+	project, err := repository.Projects.FindByID(c.UserContext(), userIdHex, projectIdHex)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, apierror.NotFound(apierror.CodeNotFound, "Project not found")
+		}
+		return nil, apierror.Internal(apierror.CodeInternal, "Error fetching project")
+	}
+	if !canReadProject(c, *project) {
+		return nil, apierror.NotFound(apierror.CodeNotFound, "Project not found")
+	}
+	return project, nil
+}
+
+// parseDay parses a YYYY-MM-DD query value, returning fallback when it is empty.
+func parseDay(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
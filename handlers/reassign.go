@@ -0,0 +1,142 @@
+// reassign.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/merge"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/notifications"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/readmodel"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxHandoffNote is the longest handoff note, in characters.
+const maxHandoffNote = 2000
+
+// reassignRequest is the body of ReassignTask.
+type reassignRequest struct {
+	AllottedTo string `json:"allotted_to"` // Username of the new assignee
+	Note       string `json:"note"`        // What the new assignee needs to know
+}
+
+// ReassignTask hands a task over to another user. The handoff, with the previous assignee and the note, is
+// appended to the task's history; the new assignee is notified of the task and the previous one that it
+// was taken off them.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func ReassignTask(c *fiber.Ctx) error {
+	userId := c.Locals("userId").(string)
+	taskIdHex, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID")
+	}
+	userIdHex, _ := primitive.ObjectIDFromHex(userId)
+
+	var request reassignRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	request.AllottedTo = strings.TrimSpace(request.AllottedTo)
+	request.Note = strings.TrimSpace(request.Note)
+	if request.AllottedTo == "" {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "allotted_to is required")
+	}
+	if len([]rune(request.Note)) > maxHandoffNote {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "note must be at most 2000 characters")
+	}
+
+	previous, err := findTask(c, taskIdHex, true)
+	if err != nil {
+		return err
+	}
+	if previous.AllottedTo == request.AllottedTo {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Task is already allotted to this user")
+	}
+	if _, err := repository.Users.FindByUsername(c.UserContext(), request.AllottedTo); err != nil {
+		if err == repository.ErrNotFound {
+			return apierror.BadRequest(apierror.CodeValidationFailed, "Allotted user does not exist")
+		}
+		return apierror.Internal(apierror.CodeInternal, "Error checking allotted user")
+	}
+
+	user, err := repository.Users.FindByID(c.UserContext(), userIdHex)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching user")
+	}
+
+	task := *previous
+	task.AllottedTo = request.AllottedTo
+	task.Handoffs = append(append([]models.Handoff{}, previous.Handoffs...), models.Handoff{
+		At:   primitive.NewDateTimeFromTime(time.Now()),
+		By:   user.Username,
+		From: previous.AllottedTo,
+		To:   request.AllottedTo,
+		Note: request.Note,
+	})
+	task.Version = previous.Version + 1
+	merge.Track(&task, previous)
+
+	err = outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := repository.Tasks.Update(ctx, &task, previous.Version); err != nil {
+			return err
+		}
+		if err := outbox.Publish(ctx, events.TaskReassigned, task.ID.Hex(), task); err != nil {
+			return err
+		}
+		return notifyHandoff(ctx, task, previous.AllottedTo, user.Username, request.Note)
+	})
+	if err == repository.ErrNotFound {
+		return apierror.Conflict(apierror.CodeVersionConflict, "Task was modified by someone else, reload it and retry")
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not reassign task")
+	}
+
+	cache.InvalidateTask(userId, task.ID.Hex())
+	readmodel.SyncOrLog(context.Background(), task)
+	c.Set(fiber.HeaderETag, versionTag(task.Version))
+	return response.JSON(c, fiber.StatusOK, withTaskLinks(c, task))
+}
+
+// notifyHandoff tells the new assignee about a reassigned task, with the handoff note, and the previous
+// assignee that it was handed over, unless they reassigned it themselves.
+func notifyHandoff(ctx context.Context, task models.Task, from, by, note string) error {
+	message := by + " handed the task \"" + task.Title + "\" over to you."
+	if note != "" {
+		message += "\n\n" + note
+	}
+	err := outbox.Notify(ctx, notifications.Notification{
+		Event:     notifications.EventTaskAssigned,
+		Recipient: task.AllottedTo,
+		TaskID:    task.ID.Hex(),
+		Subject:   "Task handed over: " + task.Title,
+		Message:   message,
+	})
+	if err != nil || from == "" || from == by {
+		return err
+	}
+	return outbox.Notify(ctx, notifications.Notification{
+		Event:     notifications.EventTaskReassigned,
+		Recipient: from,
+		TaskID:    task.ID.Hex(),
+		Subject:   "Task reassigned: " + task.Title,
+		Message:   by + " handed the task \"" + task.Title + "\" over to " + task.AllottedTo + ".",
+	})
+}
@@ -0,0 +1,206 @@
+// report_schedules.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/reports"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// reportScheduleRequest is the body of CreateReportSchedule and UpdateReportSchedule.
+type reportScheduleRequest struct {
+	Name       string              `json:"name"`
+	Format     string              `json:"format"`   // pdf (default) or csv
+	Schedule   string              `json:"schedule"` // Cron expression in the user's timezone
+	Filter     models.ReportFilter `json:"filter"`
+	Recipients []string            `json:"recipients"`
+}
+
+// CreateReportSchedule schedules a report of the logged-in user's tasks to be emailed as PDF or CSV on a cron
+// schedule in the user's timezone, to the given recipients or the user.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func CreateReportSchedule(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	existing, err := repository.ReportSchedules.Find(c.UserContext(), user.ID)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching report schedules")
+	}
+	if len(existing) >= reports.MaxSchedules {
+		return apierror.Conflict(apierror.CodeConflict, "At most 20 report schedules are allowed")
+	}
+
+	schedule := models.ReportSchedule{UserID: user.ID}
+	if err := applyReportSchedule(c, user, &schedule); err != nil {
+		return err
+	}
+	if err := repository.ReportSchedules.Create(context.Background(), &schedule); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not create report schedule")
+	}
+	return response.JSON(c, fiber.StatusCreated, schedule)
+}
+
+// GetReportSchedules lists the logged-in user's report schedules, oldest first, with when they run next and
+// whether their last run failed.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetReportSchedules(c *fiber.Ctx) error {
+	userIdHex, err := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Invalid user ID")
+	}
+
+	schedules, err := repository.ReportSchedules.Find(c.UserContext(), userIdHex)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching report schedules")
+	}
+	return response.JSON(c, fiber.StatusOK, schedules)
+}
+
+// GetReportSchedule returns a report schedule of the logged-in user.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetReportSchedule(c *fiber.Ctx) error {
+	schedule, err := findOwnReportSchedule(c)
+	if err != nil {
+		return err
+	}
+	return response.JSON(c, fiber.StatusOK, schedule)
+}
+
+// UpdateReportSchedule replaces the name, format, schedule, filter and recipients of a report schedule of the
+// logged-in user, and moves its next run to the first time the new schedule fires.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func UpdateReportSchedule(c *fiber.Ctx) error {
+	schedule, err := findOwnReportSchedule(c)
+	if err != nil {
+		return err
+	}
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	if err := applyReportSchedule(c, user, schedule); err != nil {
+		return err
+	}
+	err = repository.ReportSchedules.Update(context.Background(), schedule)
+	if err == repository.ErrNotFound {
+		return apierror.NotFound(apierror.CodeNotFound, "Report schedule not found")
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not update report schedule")
+	}
+	return response.JSON(c, fiber.StatusOK, schedule)
+}
+
+// DeleteReportSchedule deletes a report schedule of the logged-in user.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func DeleteReportSchedule(c *fiber.Ctx) error {
+	schedule, err := findOwnReportSchedule(c)
+	if err != nil {
+		return err
+	}
+
+	err = repository.ReportSchedules.Delete(context.Background(), schedule.UserID, schedule.ID)
+	if err == repository.ErrNotFound {
+		return apierror.NotFound(apierror.CodeNotFound, "Report schedule not found")
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not delete report schedule")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// findOwnReportSchedule loads the report schedule of the logged-in user named by the "id" route parameter.
+func findOwnReportSchedule(c *fiber.Ctx) (*models.ReportSchedule, error) {
+	scheduleId, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return nil, apierror.BadRequest(apierror.CodeInvalidID, "Invalid report schedule ID")
+	}
+	userIdHex, _ := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+
+	schedule, err := repository.ReportSchedules.FindByID(c.UserContext(), userIdHex, scheduleId)
+	if err == repository.ErrNotFound {
+		return nil, apierror.NotFound(apierror.CodeNotFound, "Report schedule not found")
+	}
+	if err != nil {
+		return nil, apierror.Internal(apierror.CodeInternal, "Error fetching report schedule")
+	}
+	return schedule, nil
+}
+
+// applyReportSchedule validates the request body and copies it to schedule, with its next run.
+func applyReportSchedule(c *fiber.Ctx, user *models.User, schedule *models.ReportSchedule) error {
+	var request reportScheduleRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	if request.Format == "" {
+		request.Format = models.ReportFormatPDF
+	}
+	schedule.Name = strings.TrimSpace(request.Name)
+	schedule.Format = request.Format
+	schedule.Schedule = strings.TrimSpace(request.Schedule)
+	schedule.Filter = request.Filter
+	schedule.Recipients = request.Recipients
+	if schedule.Recipients == nil {
+		schedule.Recipients = []string{}
+	}
+	if err := reports.ValidateSchedule(*schedule); err != nil {
+		return apierror.BadRequest(apierror.CodeValidationFailed, err.Error())
+	}
+	if len(schedule.Recipients) == 0 && user.Email == "" {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Add recipients or an email address to your account")
+	}
+
+	if !schedule.Filter.ProjectID.IsZero() {
+		_, err := repository.Projects.FindByID(c.UserContext(), user.ID, schedule.Filter.ProjectID)
+		if err == repository.ErrNotFound {
+			return apierror.BadRequest(apierror.CodeValidationFailed, "Project not found")
+		}
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Error fetching project")
+		}
+	}
+
+	schedule.NextRunAt = reports.NextRun(*schedule, time.Now(), userLocation(user))
+	return nil
+}
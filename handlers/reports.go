@@ -0,0 +1,166 @@
+// reports.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/permissions"
+	"github.com/bkojha74/task-management/planning"
+	"github.com/bkojha74/task-management/reports"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxCapacityHours is the largest weekly capacity GetWorkload accepts.
+const maxCapacityHours = 168
+
+// GetWorkload returns the open work of each assignee of the logged-in user's tasks for a week, so that
+// managers see who is overloaded before assigning more. The week is given by the "week" query parameter,
+// an ISO week such as 2024-W28 or any day of it, and defaults to the current week. "capacity_hours"
+// (default 40) is the weekly capacity of one assignee, and "project_id" limits the report to a project.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetWorkload(c *fiber.Ctx) error {
+	userIdHex, err := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Invalid user ID")
+	}
+
+	start, err := planning.ParseWeek(c.Query("week"), time.Now())
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid week, use an ISO week such as 2024-W28 or a YYYY-MM-DD date")
+	}
+	capacity := c.QueryInt("capacity_hours", planning.DefaultCapacityMinutes/60)
+	if capacity < 1 || capacity > maxCapacityHours {
+		return apierror.BadRequest(apierror.CodeInvalidQuery, "capacity_hours must be between 1 and 168")
+	}
+
+	query := repository.TaskQuery{
+		UserID:        userIdHex,
+		ExcludeStatus: models.TaskStatusDone,
+		Fields:        []string{"allotted_to", "status", "end_time", "estimate_minutes"},
+	}
+	if value := c.Query("project_id"); value != "" {
+		if query.ProjectID, err = primitive.ObjectIDFromHex(value); err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidID, "Invalid project ID")
+		}
+	}
+	tasks, err := repository.ReportTasks.Find(c.UserContext(), query)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+	}
+
+	return response.JSON(c, fiber.StatusOK, planning.ComputeWorkload(tasks, start, capacity*60))
+}
+
+// GetTasksReport returns a PDF report of the logged-in user's tasks for sharing with stakeholders: counts
+// by status and priority, the overdue tasks and the estimated open work, and a table of the tasks ordered by
+// due date in the user's timezone. The tasks are filtered by the query parameters "status" (comma-separated
+// statuses), "allotted_to", "overdue" (true or false) and "project_id".
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetTasksReport(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	filter, err := reportFilter(c)
+	if err != nil {
+		return err
+	}
+	if value := c.Query("project_id"); value != "" {
+		if filter.ProjectID, err = primitive.ObjectIDFromHex(value); err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidID, "Invalid project ID")
+		}
+	}
+	return sendReport(c, "Task report", reports.Describe(filter), reports.Query(user.ID, filter), userLocation(user))
+}
+
+// GetProjectReport returns the PDF report of GetTasksReport for the tasks of a project, titled with the
+// project name. It accepts the same filters but "project_id". Guests see only the tasks shared with them.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetProjectReport(c *fiber.Ctx) error {
+	project, err := findOwnProject(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	filter, err := reportFilter(c)
+	if err != nil {
+		return err
+	}
+	subtitle := reports.Describe(filter) // The title names the project
+	filter.ProjectID = project.ID
+	query := reports.Query(project.OwnerID, filter)
+	permissions.RestrictQuery(subjectOf(c), &query)
+
+	// Dates are shown in the owner's timezone, also to guests
+	location := time.UTC
+	if owner, err := repository.Users.FindByID(c.UserContext(), project.OwnerID); err == nil {
+		location = userLocation(owner)
+	}
+	return sendReport(c, "Project report: "+project.Name, subtitle, query, location)
+}
+
+// reportFilter reads the "status", "allotted_to" and "overdue" query parameters of a report.
+func reportFilter(c *fiber.Ctx) (models.ReportFilter, error) {
+	filter := models.ReportFilter{AllottedTo: c.Query("allotted_to")}
+	for _, status := range strings.Split(c.Query("status"), ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+	if value := c.Query("overdue"); value != "" {
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid overdue flag, use true or false")
+		}
+		filter.Overdue = &flag
+	}
+	return filter, nil
+}
+
+// sendReport fetches the tasks of query and responds with their PDF report as a download.
+func sendReport(c *fiber.Ctx, title, subtitle string, query repository.TaskQuery, location *time.Location) error {
+	tasks, err := repository.ReportTasks.Find(c.UserContext(), query)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+	}
+
+	report := reports.New(title, subtitle, tasks, time.Now().In(location))
+	if err := reports.AddFocusedTime(c.UserContext(), &report); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching focus sessions")
+	}
+	data, err := reports.PDF(report)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error rendering report")
+	}
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="tasks-%s.pdf"`, report.GeneratedAt.Format("2006-01-02")))
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	return c.Status(fiber.StatusOK).Send(data)
+}
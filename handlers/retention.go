@@ -0,0 +1,71 @@
+// retention.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/audit"
+	"github.com/bkojha74/task-management/response"
+	"github.com/bkojha74/task-management/retention"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// retentionStatus is the response of the retention endpoints.
+type retentionStatus struct {
+	ArchiveDoneDays int              `json:"archive_done_days"`
+	TrashDays       int              `json:"trash_days"`
+	AuditDays       int              `json:"audit_days"`
+	Report          retention.Report `json:"report"`
+}
+
+// GetRetention returns the handler reporting the retention policy and what enforcing it now would archive
+// and delete, without changing anything.
+//
+// Parameters:
+// - policy: The retention policy.
+// - sink: The audit sink, or nil when auditing is off.
+//
+// Returns:
+// - fiber.Handler: The handler.
+func GetRetention(policy retention.Policy, sink audit.Sink) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		policy.DryRun = true
+		return enforceRetention(c, policy, sink)
+	}
+}
+
+// EnforceRetention returns the handler enforcing the retention policy now instead of waiting for the job.
+// With RETENTION_DRY_RUN set it only reports, like the job.
+//
+// Parameters:
+// - policy: The retention policy.
+// - sink: The audit sink, or nil when auditing is off.
+//
+// Returns:
+// - fiber.Handler: The handler.
+func EnforceRetention(policy retention.Policy, sink audit.Sink) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return enforceRetention(c, policy, sink)
+	}
+}
+
+// enforceRetention enforces a policy and responds with the report.
+func enforceRetention(c *fiber.Ctx, policy retention.Policy, sink audit.Sink) error {
+	report, err := retention.Enforce(context.Background(), policy, sink, time.Now())
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not enforce retention")
+	}
+
+	day := 24 * time.Hour
+	return response.JSON(c, fiber.StatusOK, retentionStatus{
+		ArchiveDoneDays: int(policy.ArchiveDoneAfter / day),
+		TrashDays:       int(policy.PurgeTrashAfter / day),
+		AuditDays:       int(policy.AuditLogMaxAge / day),
+		Report:          report,
+	})
+}
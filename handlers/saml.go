@@ -0,0 +1,180 @@
+// saml.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/url"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/auth"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+	"github.com/bkojha74/task-management/saml"
+	"github.com/bkojha74/task-management/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// samlRequestExpiry is how long a user has to sign in at the identity provider.
+const samlRequestExpiry = 10 * time.Minute
+
+// samlNonceCookie is the cookie binding a sign-in to the browser that started it. The identity provider
+// posts the response from its own site, so the cookie must be sent cross-site: SameSite=None, and Secure.
+const samlNonceCookie = "saml_nonce"
+
+// SAMLMetadata serves the metadata of the service provider, to be registered with the identity provider.
+//
+// Parameters:
+// - sp: The service provider.
+//
+// Returns:
+// - fiber.Handler: The handler serving the metadata XML.
+func SAMLMetadata(sp *saml.ServiceProvider) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, "application/samlmetadata+xml")
+		return c.Send(sp.Metadata())
+	}
+}
+
+// SAMLLogin redirects the user to the identity provider with an authentication request. The request ID
+// travels as a signed token in the relay state, so the response can be matched without server-side state.
+// The browser gets a random nonce in a cookie whose hash is in the token, so that only the browser that
+// started sign-in can complete it.
+//
+// Parameters:
+// - sp: The service provider.
+// - signing: The secret the relay state is signed with.
+//
+// Returns:
+// - fiber.Handler: The handler starting single sign-on.
+func SAMLLogin(sp *saml.ServiceProvider, signing *utils.SigningSecret) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestId, err := saml.NewRequestID()
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "could not start single sign-on")
+		}
+		nonce := make([]byte, 32)
+		if _, err := rand.Read(nonce); err != nil {
+			return apierror.Internal(apierror.CodeInternal, "could not start single sign-on")
+		}
+		now := time.Now()
+		relayState, err := signing.SignToken(utils.Tokens.NewSAMLRequestClaims(requestId, hashSAMLNonce(hex.EncodeToString(nonce)), now, now.Add(samlRequestExpiry)))
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "could not start single sign-on")
+		}
+		location, err := sp.AuthnRequestURL(requestId, relayState, now)
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "could not start single sign-on")
+		}
+		setSAMLNonceCookie(c, hex.EncodeToString(nonce), now.Add(samlRequestExpiry))
+		return c.Redirect(location, fiber.StatusFound)
+	}
+}
+
+// SAMLACS is the assertion consumer service: it checks the response the identity provider posts back, signs
+// the user in to the account linked to their issuer and NameID, creating it on their first sign-in, and
+// redirects them to the configured URL with the token in the fragment, or returns the token as JSON if none
+// is configured. The response must be
+// posted from the browser that started sign-in, and each request and assertion is accepted once, so that a
+// captured response cannot be replayed.
+//
+// Parameters:
+// - sp: The service provider.
+// - signing: The secret the relay state and the issued token are signed with.
+// - tokenExpiryTime: The lifetime of the issued token.
+//
+// Returns:
+// - fiber.Handler: The handler completing single sign-on.
+func SAMLACS(sp *saml.ServiceProvider, signing *utils.SigningSecret, tokenExpiryTime int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		request, err := utils.ParseSAMLRequestToken(signing, c.FormValue("RelayState"))
+		if err != nil {
+			return apierror.Unauthorized(apierror.CodeInvalidToken, "single sign-on request is invalid or expired")
+		}
+		nonce := c.Cookies(samlNonceCookie)
+		if nonce == "" || subtle.ConstantTimeCompare([]byte(hashSAMLNonce(nonce)), []byte(request.NonceHash)) != 1 {
+			return apierror.Unauthorized(apierror.CodeInvalidToken, "single sign-on was started in another browser")
+		}
+		setSAMLNonceCookie(c, "", time.Unix(0, 0)) // Used up either way
+		now := time.Now()
+		assertion, err := sp.ParseResponse(c.FormValue("SAMLResponse"), request.RequestID, now)
+		if err != nil {
+			return apierror.Unauthorized(apierror.CodeInvalidCredentials, "SAML response was rejected")
+		}
+
+		// The request is remembered until its relay state expires, the assertion until it does
+		requestExpiry := request.ExpiresAt.Add(utils.Tokens.ClockSkew)
+		assertionExpiry := assertion.ExpiresAt.Add(sp.ClockSkew)
+		if assertion.ExpiresAt.IsZero() || assertionExpiry.Before(requestExpiry) {
+			assertionExpiry = requestExpiry
+		}
+		for _, used := range []struct {
+			value     string
+			expiresAt time.Time
+		}{
+			{"saml-request:" + request.RequestID, requestExpiry},
+			{"saml-assertion:" + sp.IdPEntityID + "|" + assertion.ID, assertionExpiry},
+		} {
+			err := repository.Nonces.Use(c.UserContext(), used.value, used.expiresAt)
+			if err == repository.ErrDuplicate {
+				return apierror.Unauthorized(apierror.CodeInvalidCredentials, "SAML response was already used")
+			}
+			if err != nil {
+				return apierror.Internal(apierror.CodeInternal, "internal server error")
+			}
+		}
+		username, email, err := sp.Identity(assertion)
+		if err != nil {
+			return apierror.Unauthorized(apierror.CodeInvalidCredentials, "SAML response was rejected")
+		}
+		subject, err := sp.Subject(assertion)
+		if err != nil {
+			return apierror.Unauthorized(apierror.CodeInvalidCredentials, "SAML response was rejected")
+		}
+
+		user, err := directoryUser(auth.Identity{Username: username, Email: email, Subject: subject})
+		if err == errAccountNotLinked {
+			return apierror.Conflict(apierror.CodeConflict, "username belongs to an account that is not linked to the identity provider")
+		}
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "internal server error")
+		}
+		if !user.DeactivatedAt.IsZero() {
+			return apierror.Forbidden(apierror.CodeForbidden, "account is deactivated")
+		}
+		issued, err := issueToken(c, signing, user, tokenExpiryTime, false)
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "could not generate token")
+		}
+
+		if sp.RedirectURL != "" {
+			return c.Redirect(sp.RedirectURL+"#token="+url.QueryEscape(issued.Token), fiber.StatusSeeOther)
+		}
+		return response.JSON(c, fiber.StatusOK, issued)
+	}
+}
+
+// hashSAMLNonce returns the hash of a nonce as it is kept in the relay state.
+func hashSAMLNonce(nonce string) string {
+	sum := sha256.Sum256([]byte(nonce))
+	return hex.EncodeToString(sum[:])
+}
+
+// setSAMLNonceCookie sets the nonce cookie of single sign-on until expires; an expiry in the past deletes it.
+func setSAMLNonceCookie(c *fiber.Ctx, nonce string, expires time.Time) {
+	c.Cookie(&fiber.Cookie{
+		Name:     samlNonceCookie,
+		Value:    nonce,
+		Path:     "/saml",
+		Expires:  expires,
+		Secure:   true,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteNoneMode,
+	})
+}
@@ -0,0 +1,488 @@
+// scim.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SCIM 2.0 schemas (RFC 7643, RFC 7644) and paging
+const (
+	scimSchemaUser   = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimSchemaList   = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimSchemaError  = "urn:ietf:params:scim:api:messages:2.0:Error"
+	scimSchemaConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	scimContentType  = "application/scim+json"
+	scimDefaultCount = 100
+	scimMaxCount     = 200
+)
+
+// scimFilter is the one filter supported, the lookup by userName identity providers make before creating a user.
+var scimFilter = regexp.MustCompile(`(?i)^\s*userName\s+eq\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+// scimUser is a user as a SCIM resource. Attribute names are matched without case when reading.
+type scimUser struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	ExternalID  string      `json:"externalId,omitempty"`
+	UserName    string      `json:"userName"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Name        *scimName   `json:"name,omitempty"`
+	Emails      []scimEmail `json:"emails,omitempty"`
+	Active      *bool       `json:"active,omitempty"`
+	Password    string      `json:"password,omitempty"` // Write-only
+	Meta        *scimMeta   `json:"meta,omitempty"`
+}
+
+// scimName is the name of a SCIM user; only its formatted form is kept, as the display name.
+type scimName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// scimEmail is an email address of a SCIM user; only the primary, or first, one is kept.
+type scimEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// scimMeta is the resource metadata of a SCIM user.
+type scimMeta struct {
+	ResourceType string           `json:"resourceType"`
+	Created      models.Timestamp `json:"created"`
+	LastModified models.Timestamp `json:"lastModified"`
+	Location     string           `json:"location"`
+}
+
+// scimListResponse is the response of a SCIM query.
+type scimListResponse struct {
+	Schemas      []string   `json:"schemas"`
+	TotalResults int64      `json:"totalResults"`
+	StartIndex   int        `json:"startIndex"`
+	ItemsPerPage int        `json:"itemsPerPage"`
+	Resources    []scimUser `json:"Resources"`
+}
+
+// scimPatchRequest is the body of a SCIM PATCH.
+type scimPatchRequest struct {
+	Schemas    []string `json:"schemas"`
+	Operations []struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	} `json:"Operations"`
+}
+
+// scimError writes a SCIM error response; SCIM clients do not understand the API's own error format.
+func scimError(c *fiber.Ctx, status int, scimType, detail string) error {
+	body := fiber.Map{"schemas": []string{scimSchemaError}, "status": strconv.Itoa(status), "detail": detail}
+	if scimType != "" {
+		body["scimType"] = scimType
+	}
+	return c.Status(status).JSON(body, scimContentType)
+}
+
+// toSCIMUser returns the SCIM resource of a user.
+func toSCIMUser(c *fiber.Ctx, user *models.User) scimUser {
+	active := user.DeactivatedAt.IsZero()
+	resource := scimUser{
+		Schemas:     []string{scimSchemaUser},
+		ID:          user.ID.Hex(),
+		ExternalID:  user.ExternalID,
+		UserName:    user.Username,
+		DisplayName: user.DisplayName,
+		Active:      &active,
+		Meta: &scimMeta{
+			ResourceType: "User",
+			Created:      user.CreatedAt,
+			LastModified: user.UpdatedAt,
+			Location:     c.BaseURL() + "/scim/v2/Users/" + user.ID.Hex(),
+		},
+	}
+	if user.DisplayName != "" {
+		resource.Name = &scimName{Formatted: user.DisplayName}
+	}
+	if user.Email != "" {
+		resource.Emails = []scimEmail{{Value: user.Email, Type: "work", Primary: true}}
+	}
+	return resource
+}
+
+// applySCIMUser sets the attributes of a SCIM resource on a user, as a create or replace does.
+func applySCIMUser(user *models.User, resource scimUser) {
+	user.Username = strings.TrimSpace(resource.UserName)
+	user.ExternalID = resource.ExternalID
+	user.DisplayName = resource.DisplayName
+	if user.DisplayName == "" && resource.Name != nil {
+		user.DisplayName = resource.Name.Formatted
+		if user.DisplayName == "" {
+			user.DisplayName = strings.TrimSpace(resource.Name.GivenName + " " + resource.Name.FamilyName)
+		}
+	}
+	user.Email = primaryEmail(resource.Emails)
+	if resource.Active != nil {
+		setActive(user, *resource.Active)
+	}
+	if resource.Password != "" {
+		user.Password = utils.HashPassword(resource.Password)
+	}
+}
+
+// primaryEmail returns the primary email address, or the first one.
+func primaryEmail(emails []scimEmail) string {
+	for _, email := range emails {
+		if email.Primary {
+			return strings.TrimSpace(email.Value)
+		}
+	}
+	if len(emails) > 0 {
+		return strings.TrimSpace(emails[0].Value)
+	}
+	return ""
+}
+
+// setActive deactivates or reactivates a user.
+func setActive(user *models.User, active bool) {
+	switch {
+	case active:
+		user.DeactivatedAt = 0
+	case user.DeactivatedAt.IsZero():
+		user.DeactivatedAt = models.NewTimestamp(time.Now())
+	}
+}
+
+// saveSCIMUser stores a changed user. Deactivating a user ends all their sessions, so their tokens stop
+// working at once.
+func saveSCIMUser(user *models.User, wasActive bool) error {
+	active := user.DeactivatedAt.IsZero()
+	return outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := repository.Users.Update(ctx, user); err != nil {
+			return err
+		}
+		switch {
+		case wasActive && !active:
+			if _, err := repository.Sessions.DeleteMany(ctx, user.ID); err != nil {
+				return err
+			}
+			return outbox.Publish(ctx, events.UserDeactivated, user.ID.Hex(), withoutPassword(*user))
+		case !wasActive && active:
+			return outbox.Publish(ctx, events.UserReactivated, user.ID.Hex(), withoutPassword(*user))
+		}
+		return nil
+	})
+}
+
+// withoutPassword returns the user without the password hash, for publishing.
+func withoutPassword(user models.User) models.User {
+	user.Password = ""
+	return user
+}
+
+// findSCIMUser returns the user of the :id parameter, or writes the SCIM error.
+func findSCIMUser(c *fiber.Ctx) (*models.User, error) {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return nil, scimError(c, fiber.StatusNotFound, "", "User not found")
+	}
+	user, err := repository.Users.FindByID(c.UserContext(), id)
+	if err == repository.ErrNotFound {
+		return nil, scimError(c, fiber.StatusNotFound, "", "User not found")
+	}
+	if err != nil {
+		return nil, scimError(c, fiber.StatusInternalServerError, "", "internal server error")
+	}
+	return user, nil
+}
+
+// GetSCIMServiceProviderConfig describes the SCIM features the service supports.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetSCIMServiceProviderConfig(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"schemas":        []string{scimSchemaConfig},
+		"patch":          fiber.Map{"supported": true},
+		"bulk":           fiber.Map{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         fiber.Map{"supported": true, "maxResults": scimMaxCount},
+		"changePassword": fiber.Map{"supported": true},
+		"sort":           fiber.Map{"supported": false},
+		"etag":           fiber.Map{"supported": false},
+		"authenticationSchemes": []fiber.Map{{
+			"type": "oauthbearertoken", "name": "Bearer token", "description": "The token set as SCIM_TOKEN",
+		}},
+	}, scimContentType)
+}
+
+// ListSCIMUsers lists the users page by page, or looks one up with the filter userName eq "name".
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func ListSCIMUsers(c *fiber.Ctx) error {
+	startIndex := c.QueryInt("startIndex", 1)
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	count := c.QueryInt("count", scimDefaultCount)
+	if count < 0 {
+		count = 0
+	}
+	if count > scimMaxCount {
+		count = scimMaxCount
+	}
+
+	list := scimListResponse{Schemas: []string{scimSchemaList}, StartIndex: startIndex, Resources: []scimUser{}}
+	if filter := c.Query("filter"); filter != "" {
+		match := scimFilter.FindStringSubmatch(filter)
+		var username string
+		if match == nil || json.Unmarshal([]byte(match[1]), &username) != nil {
+			return scimError(c, fiber.StatusBadRequest, "invalidFilter", `Only the filter userName eq "name" is supported`)
+		}
+		user, err := repository.Users.FindByUsername(c.UserContext(), username)
+		switch {
+		case err == nil:
+			list.TotalResults = 1
+			if startIndex == 1 && count > 0 {
+				list.Resources = append(list.Resources, toSCIMUser(c, user))
+			}
+		case err != repository.ErrNotFound:
+			return scimError(c, fiber.StatusInternalServerError, "", "internal server error")
+		}
+	} else {
+		users, total, err := repository.Users.List(c.UserContext(), startIndex-1, count)
+		if err != nil {
+			return scimError(c, fiber.StatusInternalServerError, "", "internal server error")
+		}
+		list.TotalResults = total
+		for i := range users {
+			list.Resources = append(list.Resources, toSCIMUser(c, &users[i]))
+		}
+	}
+	list.ItemsPerPage = len(list.Resources)
+	return c.JSON(list, scimContentType)
+}
+
+// GetSCIMUser returns a user.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetSCIMUser(c *fiber.Ctx) error {
+	user, err := findSCIMUser(c)
+	if user == nil {
+		return err
+	}
+	return c.JSON(toSCIMUser(c, user), scimContentType)
+}
+
+// CreateSCIMUser provisions a user. Users are created without a role, and without a password unless the
+// identity provider sends one, for sign-in through the directory or single sign-on.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func CreateSCIMUser(c *fiber.Ctx) error {
+	var resource scimUser
+	if err := json.Unmarshal(c.Body(), &resource); err != nil {
+		return scimError(c, fiber.StatusBadRequest, "invalidSyntax", "cannot parse JSON")
+	}
+	user := models.User{}
+	applySCIMUser(&user, resource)
+	if user.Username == "" {
+		return scimError(c, fiber.StatusBadRequest, "invalidValue", "userName is required")
+	}
+
+	err := outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := repository.Users.Create(ctx, &user); err != nil {
+			return err
+		}
+		return outbox.Publish(ctx, events.UserRegistered, user.ID.Hex(), withoutPassword(user))
+	})
+	if err == repository.ErrDuplicate {
+		return scimError(c, fiber.StatusConflict, "uniqueness", "userName is already taken")
+	}
+	if err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", "could not create user")
+	}
+
+	resourceOut := toSCIMUser(c, &user)
+	c.Location(resourceOut.Meta.Location)
+	return c.Status(fiber.StatusCreated).JSON(resourceOut, scimContentType)
+}
+
+// ReplaceSCIMUser replaces the attributes of a user the identity provider manages.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func ReplaceSCIMUser(c *fiber.Ctx) error {
+	user, err := findSCIMUser(c)
+	if user == nil {
+		return err
+	}
+	var resource scimUser
+	if err := json.Unmarshal(c.Body(), &resource); err != nil {
+		return scimError(c, fiber.StatusBadRequest, "invalidSyntax", "cannot parse JSON")
+	}
+	wasActive := user.DeactivatedAt.IsZero()
+	applySCIMUser(user, resource)
+	if user.Username == "" {
+		return scimError(c, fiber.StatusBadRequest, "invalidValue", "userName is required")
+	}
+	return writeSCIMUser(c, user, wasActive)
+}
+
+// PatchSCIMUser applies add, replace and remove operations to a user, e.g. {"op": "replace", "path":
+// "active", "value": false} to deactivate them. Attributes the service does not keep are ignored.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func PatchSCIMUser(c *fiber.Ctx) error {
+	user, err := findSCIMUser(c)
+	if user == nil {
+		return err
+	}
+	var request scimPatchRequest
+	if err := json.Unmarshal(c.Body(), &request); err != nil {
+		return scimError(c, fiber.StatusBadRequest, "invalidSyntax", "cannot parse JSON")
+	}
+
+	wasActive := user.DeactivatedAt.IsZero()
+	for _, operation := range request.Operations {
+		op := strings.ToLower(operation.Op)
+		if op != "add" && op != "replace" && op != "remove" {
+			return scimError(c, fiber.StatusBadRequest, "invalidSyntax", "op must be add, replace or remove")
+		}
+		values := map[string]json.RawMessage{}
+		if operation.Path == "" {
+			if json.Unmarshal(operation.Value, &values) != nil {
+				return scimError(c, fiber.StatusBadRequest, "invalidValue", "value must be an object without path")
+			}
+		} else {
+			values[operation.Path] = operation.Value
+		}
+		for path, value := range values {
+			if err := patchSCIMAttribute(user, strings.ToLower(path), op == "remove", value); err != nil {
+				return scimError(c, fiber.StatusBadRequest, "invalidValue", err.Error())
+			}
+		}
+	}
+	if user.Username == "" {
+		return scimError(c, fiber.StatusBadRequest, "invalidValue", "userName is required")
+	}
+	return writeSCIMUser(c, user, wasActive)
+}
+
+// patchSCIMAttribute sets or removes one attribute of a user.
+func patchSCIMAttribute(user *models.User, path string, remove bool, value json.RawMessage) error {
+	var text string
+	if !remove && strings.HasPrefix(string(value), `"`) {
+		_ = json.Unmarshal(value, &text)
+	}
+	switch {
+	case path == "active":
+		active := !remove
+		if !remove && json.Unmarshal(value, &active) != nil {
+			// Some identity providers send "True" and "False"
+			parsed, err := strconv.ParseBool(text)
+			if err != nil {
+				return errors.New("invalid value for active")
+			}
+			active = parsed
+		}
+		setActive(user, active)
+	case path == "username":
+		user.Username = strings.TrimSpace(text)
+	case path == "externalid":
+		user.ExternalID = text
+	case path == "displayname", path == "name.formatted":
+		user.DisplayName = text
+	case path == "name":
+		var name scimName
+		if !remove && json.Unmarshal(value, &name) != nil {
+			return errors.New("invalid value for name")
+		}
+		user.DisplayName = name.Formatted
+	case path == "password":
+		if !remove && text != "" {
+			user.Password = utils.HashPassword(text)
+		}
+	case path == "emails":
+		var emails []scimEmail
+		if !remove && json.Unmarshal(value, &emails) != nil {
+			return errors.New("invalid value for emails")
+		}
+		user.Email = primaryEmail(emails)
+	case strings.HasPrefix(path, "emails["), strings.HasPrefix(path, "emails."):
+		user.Email = strings.TrimSpace(text) // e.g. emails[type eq "work"].value; only one address is kept
+	}
+	return nil
+}
+
+// writeSCIMUser stores a replaced or patched user and returns it.
+func writeSCIMUser(c *fiber.Ctx, user *models.User, wasActive bool) error {
+	err := saveSCIMUser(user, wasActive)
+	if err == repository.ErrDuplicate {
+		return scimError(c, fiber.StatusConflict, "uniqueness", "userName is already taken")
+	}
+	if err == repository.ErrNotFound {
+		return scimError(c, fiber.StatusNotFound, "", "User not found")
+	}
+	if err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", "could not update user")
+	}
+	return c.JSON(toSCIMUser(c, user), scimContentType)
+}
+
+// DeleteSCIMUser deactivates a user. Accounts are never deleted through SCIM: the user can no longer
+// sign in, but their tasks stay, and they can be reactivated with active set to true.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func DeleteSCIMUser(c *fiber.Ctx) error {
+	user, err := findSCIMUser(c)
+	if user == nil {
+		return err
+	}
+	wasActive := user.DeactivatedAt.IsZero()
+	setActive(user, false)
+	if err := saveSCIMUser(user, wasActive); err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", "could not update user")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,64 @@
+// search.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"strings"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/pagination"
+	"github.com/bkojha74/task-management/response"
+	"github.com/bkojha74/task-management/search"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Search finds the tasks and projects of the logged-in user containing all words of the "q" query
+// parameter, grouped by type with highlighted fragments. "type" limits the search to a comma-separated
+// list of types, and "limit" and "offset" page through the results of each type.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func Search(c *fiber.Ctx) error {
+	userIdHex, _ := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+
+	text := strings.TrimSpace(c.Query("q"))
+	if len(search.Words(text)) == 0 {
+		return apierror.BadRequest(apierror.CodeInvalidQuery, "q must contain at least one word")
+	}
+	var types []string
+	if c.Query("type") != "" {
+		for _, kind := range strings.Split(c.Query("type"), ",") {
+			kind = strings.TrimSpace(kind)
+			if kind != search.TypeTask && kind != search.TypeProject {
+				return apierror.BadRequest(apierror.CodeInvalidQuery, "type must be task or project")
+			}
+			types = append(types, kind)
+		}
+	}
+	limit := c.QueryInt("limit", search.DefaultLimit)
+	if limit <= 0 || limit > pagination.MaxLimit {
+		return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid limit")
+	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid offset")
+	}
+
+	results, err := search.Default.Search(c.UserContext(), search.Query{
+		Text:   text,
+		UserID: userIdHex,
+		Types:  types,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not search")
+	}
+	return response.JSON(c, fiber.StatusOK, results)
+}
@@ -0,0 +1,74 @@
+// sessions.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sessionView is a session as listed to its user.
+type sessionView struct {
+	models.Session
+	Current bool `json:"current"` // The session of the token making the request
+}
+
+// GetSessions lists the active sessions of the logged-in user, newest first, marking the one of the
+// token making the request as current.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetSessions(c *fiber.Ctx) error {
+	userIdHex, _ := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+
+	sessions, err := repository.Sessions.Find(c.UserContext(), userIdHex, time.Now())
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching sessions")
+	}
+
+	current, _ := c.Locals("sessionId").(string)
+	views := make([]sessionView, len(sessions))
+	for i, session := range sessions {
+		views[i] = sessionView{Session: session, Current: session.ID.Hex() == current}
+	}
+	response.AddMeta(c, "count", len(views))
+	return response.JSON(c, fiber.StatusOK, views)
+}
+
+// RevokeSession revokes a session of the logged-in user, e.g. of a lost device. The token of the session
+// is rejected from then on; revoking the current session signs the user out.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func RevokeSession(c *fiber.Ctx) error {
+	userIdHex, _ := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+	sessionId, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid session ID")
+	}
+
+	err = repository.Sessions.Revoke(context.Background(), userIdHex, sessionId, time.Now())
+	if err == repository.ErrNotFound {
+		return apierror.NotFound(apierror.CodeNotFound, "Session not found")
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not revoke session")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
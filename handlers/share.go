@@ -0,0 +1,175 @@
+// share.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"bytes"
+	"html/template"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/branding"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+	"github.com/bkojha74/task-management/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Lifetime of share links
+const (
+	defaultShareExpiry = 7 * 24 * time.Hour
+	maxShareExpiry     = 30 * 24 * time.Hour
+)
+
+// shareRequest is the body of ShareTask.
+type shareRequest struct {
+	ExpiresIn string `json:"expires_in"` // Go duration, e.g. "48h"; 168h by default
+}
+
+// shareLink is the response of ShareTask.
+type shareLink struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// sharedTask is the read-only view of a task shown through a share link, without the owner's IDs and
+// planning details, with the branding of the owner's workspace.
+type sharedTask struct {
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	HTML        string           `json:"description_html"`
+	AllottedTo  string           `json:"allotted_to"`
+	Status      string           `json:"status"`
+	Priority    string           `json:"priority"`
+	StartDate   models.Timestamp `json:"start_time"`
+	EndDate     models.Timestamp `json:"end_time"`
+	UpdatedAt   models.Timestamp `json:"updated_at"`
+	Branding    models.Branding  `json:"branding"`
+}
+
+// sharedPagePolicy keeps share pages to their own inline styles and the workspace logo.
+const sharedPagePolicy = "default-src 'none'; style-src 'unsafe-inline'; img-src https:"
+
+// sharedPage is the HTML of a shared task inside the branded layout. The description HTML was sanitized
+// when the description was rendered.
+var sharedPage = template.Must(template.New("shared").Funcs(template.FuncMap{
+	"date":    func(t models.Timestamp) string { return t.Time().UTC().Format("Mon, 02 Jan 2006 15:04 MST") },
+	"trusted": func(html string) template.HTML { return template.HTML(html) },
+}).Parse(`<p style="color:#6b7280">{{.Status}}{{with .Priority}} &middot; {{.}} priority{{end}}{{with .AllottedTo}} &middot; allotted to {{.}}{{end}}</p>
+{{if .EndDate}}<p>Due {{date .EndDate}}</p>{{end}}
+{{if .HTML}}{{trusted .HTML}}{{else if .Description}}<p>{{.Description}}</p>{{end}}
+<p style="font-size:12px;color:#6b7280">Last updated {{date .UpdatedAt}}</p>`))
+
+// ShareTask creates a signed link showing a task read-only to anyone who has it, without an account, until
+// it expires. The link always shows the current state of the task and stops working when the task is deleted.
+//
+// Parameters:
+// - signing: The secret the link is signed with.
+//
+// Returns:
+// - fiber.Handler: The handler creating share links.
+func ShareTask(signing *utils.SigningSecret) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userId := c.Locals("userId").(string)
+		taskIdHex, err := primitive.ObjectIDFromHex(c.Params("id"))
+		if err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID")
+		}
+
+		var request shareRequest
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&request); err != nil {
+				return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+			}
+		}
+		expiresIn := defaultShareExpiry
+		if request.ExpiresIn != "" {
+			expiresIn, err = time.ParseDuration(request.ExpiresIn)
+			if err != nil || expiresIn <= 0 || expiresIn > maxShareExpiry {
+				return apierror.BadRequest(apierror.CodeValidationFailed, "expires_in must be a positive duration of at most 720h, e.g. \"48h\"")
+			}
+		}
+
+		if _, err := findTask(c, taskIdHex, true); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		expiresAt := now.Add(expiresIn)
+		token, err := signing.SignToken(utils.Tokens.NewShareClaims(userId, taskIdHex.Hex(), now, expiresAt))
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Could not create share link")
+		}
+
+		return response.JSON(c, fiber.StatusCreated, shareLink{Token: token, URL: "/shared/" + token, ExpiresAt: expiresAt.UTC().Truncate(time.Second)})
+	}
+}
+
+// GetSharedTask shows the task of a share link with the branding of the owner's workspace: as a branded
+// HTML page to browsers, which prefer text/html, and as JSON otherwise. Invalid and expired links are
+// answered with 404, like links to deleted tasks, so that a link reveals nothing once it stops working.
+//
+// Parameters:
+// - signing: The secret the link was signed with.
+//
+// Returns:
+// - fiber.Handler: The handler showing shared tasks.
+func GetSharedTask(signing *utils.SigningSecret) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, err := utils.ParseShareToken(signing, c.Params("token"))
+		if err != nil {
+			return apierror.NotFound(apierror.CodeNotFound, "Shared task not found or link expired")
+		}
+		userIdHex, err := primitive.ObjectIDFromHex(claims.UserID)
+		if err != nil {
+			return apierror.NotFound(apierror.CodeNotFound, "Shared task not found or link expired")
+		}
+		taskIdHex, err := primitive.ObjectIDFromHex(claims.TaskID)
+		if err != nil {
+			return apierror.NotFound(apierror.CodeNotFound, "Shared task not found or link expired")
+		}
+
+		task, err := repository.Tasks.FindByID(c.UserContext(), userIdHex, taskIdHex)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				return apierror.NotFound(apierror.CodeNotFound, "Shared task not found or link expired")
+			}
+			return apierror.Internal(apierror.CodeInternal, "Error fetching task")
+		}
+
+		shared := sharedTask{
+			Title:       task.Title,
+			Description: task.Description,
+			HTML:        task.DescriptionHTML,
+			AllottedTo:  task.AllottedTo,
+			Status:      task.Status,
+			Priority:    task.Priority,
+			StartDate:   task.StartDate,
+			EndDate:     task.EndDate,
+			UpdatedAt:   task.UpdatedAt,
+			Branding:    branding.ForUser(c.UserContext(), task.UserID),
+		}
+
+		c.Set(fiber.HeaderCacheControl, "private, no-store")
+		if c.Accepts(fiber.MIMEApplicationJSON, fiber.MIMETextHTML) != fiber.MIMETextHTML {
+			return response.JSON(c, fiber.StatusOK, shared)
+		}
+
+		var body bytes.Buffer
+		if err := sharedPage.Execute(&body, shared); err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Could not render shared task")
+		}
+		page, err := branding.Render(shared.Branding, shared.Title, template.HTML(body.String()))
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Could not render shared task")
+		}
+		c.Set(fiber.HeaderContentSecurityPolicy, sharedPagePolicy)
+		c.Type("html", "utf-8")
+		return c.SendString(page)
+	}
+}
@@ -0,0 +1,117 @@
+// signins.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/devices"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/notifications"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Number of sign-ins listed by GetSignIns
+const (
+	signInsDefaultLimit = 20
+	signInsMaxLimit     = 100
+)
+
+// recordSignIn records the sign-in starting a session, with the device and network it came from, and tells
+// the user when the account was signed in before but not from that device or network. Failures are logged
+// rather than returned, so that they never fail the sign-in.
+func recordSignIn(user *models.User, session *models.Session) {
+	ctx := context.Background()
+	device := devices.Parse(session.Device)
+	signIn := models.SignIn{
+		UserID:    user.ID,
+		SessionID: session.ID,
+		UserAgent: session.Device,
+		Device:    device.String(),
+		IP:        session.IP,
+		Network:   devices.Network(session.IP),
+		CreatedAt: session.IssuedAt,
+	}
+
+	known, err := repository.SignIns.Exists(ctx, repository.SignInQuery{UserID: user.ID})
+	if err == nil && known {
+		knownDevice, deviceErr := repository.SignIns.Exists(ctx, repository.SignInQuery{UserID: user.ID, Device: signIn.Device})
+		knownNetwork, networkErr := repository.SignIns.Exists(ctx, repository.SignInQuery{UserID: user.ID, Network: signIn.Network})
+		err = deviceErr
+		if err == nil {
+			err = networkErr
+		}
+		signIn.NewDevice, signIn.NewNetwork = !knownDevice, !knownNetwork
+	}
+	if err != nil {
+		log.Printf("Could not check the sign-in history of user %s: %v", user.Username, err)
+		return
+	}
+
+	err = outbox.Transaction(ctx, func(ctx context.Context) error {
+		if err := repository.SignIns.Create(ctx, &signIn); err != nil {
+			return err
+		}
+		if !signIn.NewDevice && !signIn.NewNetwork {
+			return nil
+		}
+		return outbox.Notify(ctx, newSignInNotification(user, signIn))
+	})
+	if err != nil {
+		log.Printf("Could not record the sign-in of user %s: %v", user.Username, err)
+	}
+}
+
+// newSignInNotification tells a user about a sign-in from a new device or network.
+func newSignInNotification(user *models.User, signIn models.SignIn) notifications.Notification {
+	var news []string
+	if signIn.NewDevice {
+		news = append(news, "a new device")
+	}
+	if signIn.NewNetwork {
+		news = append(news, "a new location")
+	}
+	return notifications.Notification{
+		Event:     notifications.EventNewSignIn,
+		Recipient: user.Username,
+		Subject:   "New sign-in from " + strings.Join(news, " and "),
+		Message: fmt.Sprintf("Your account was signed in from %s at %s (network %s) on %s UTC.\n\n"+
+			"If this wasn't you, revoke the session under your sessions and change your password.",
+			signIn.Device, signIn.IP, signIn.Network, signIn.CreatedAt.Time().UTC().Format("2 Jan 2006 15:04")),
+	}
+}
+
+// GetSignIns lists the latest sign-ins of the logged-in user, newest first, with the device and network
+// they came from and whether those were new to the account. The limit query parameter sets how many are
+// listed, 20 by default and at most 100; sign-ins are kept for 90 days.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetSignIns(c *fiber.Ctx) error {
+	userIdHex, _ := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+	limit := c.QueryInt("limit", signInsDefaultLimit)
+	if limit < 1 || limit > signInsMaxLimit {
+		return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid limit")
+	}
+
+	signIns, err := repository.SignIns.Find(c.UserContext(), userIdHex, limit)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching sign-ins")
+	}
+
+	response.AddMeta(c, "count", len(signIns))
+	return response.JSON(c, fiber.StatusOK, signIns)
+}
@@ -0,0 +1,103 @@
+// snooze.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/merge"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/planning"
+	"github.com/bkojha74/task-management/readmodel"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxSnooze is the longest a task can be postponed by at once.
+const maxSnooze = 365 * 24 * time.Hour
+
+// snoozeRequest is the body of SnoozeTask.
+type snoozeRequest struct {
+	Duration string `json:"duration"` // Go duration, e.g. "30m" or "48h"
+	Reason   string `json:"reason"`
+}
+
+// SnoozeTask postpones a task: its end_time is pushed by the requested duration, counted from the current
+// end_time or from now if that has already passed. The snooze is appended to the task's history and overdue
+// reminders are suppressed until the new end_time.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func SnoozeTask(c *fiber.Ctx) error {
+	userId := c.Locals("userId").(string)
+	taskIdHex, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID")
+	}
+	userIdHex, _ := primitive.ObjectIDFromHex(userId)
+
+	var request snoozeRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	duration, err := time.ParseDuration(request.Duration)
+	if err != nil || duration <= 0 || duration > maxSnooze {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "duration must be a positive duration of at most 8760h, e.g. \"24h\"")
+	}
+
+	previous, err := findTask(c, taskIdHex, true)
+	if err != nil {
+		return err
+	}
+	if previous.Status == models.TaskStatusDone {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Done tasks cannot be snoozed")
+	}
+
+	user, err := repository.Users.FindByID(c.UserContext(), userIdHex)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching user")
+	}
+
+	now := time.Now()
+	from := previous.EndDate.Time()
+	if from.Before(now) {
+		from = now
+	}
+	until := primitive.NewDateTimeFromTime(from.Add(duration))
+
+	task := *previous
+	task.Snoozes = append(append([]models.Snooze{}, previous.Snoozes...), models.Snooze{
+		At:     primitive.NewDateTimeFromTime(now),
+		By:     user.Username,
+		From:   primitive.DateTime(previous.EndDate),
+		Until:  until,
+		Reason: request.Reason,
+	})
+	task.EndDate = models.Timestamp(until)
+	task.SnoozedUntil = until
+	planning.TrackOverdue(&task, previous, now)
+	task.Version = previous.Version + 1
+	merge.Track(&task, previous)
+
+	if err := repository.Tasks.Update(context.Background(), &task, previous.Version); err != nil {
+		if err == repository.ErrNotFound {
+			return apierror.Conflict(apierror.CodeVersionConflict, "Task was modified by someone else, reload it and retry")
+		}
+		return apierror.Internal(apierror.CodeInternal, "Could not snooze task")
+	}
+
+	cache.InvalidateTask(userId, task.ID.Hex())
+	readmodel.SyncOrLog(context.Background(), task)
+	c.Set(fiber.HeaderETag, versionTag(task.Version))
+	return response.JSON(c, fiber.StatusOK, withTaskLinks(c, task))
+}
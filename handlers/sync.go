@@ -0,0 +1,483 @@
+// sync.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/i18n"
+	"github.com/bkojha74/task-management/markdown"
+	"github.com/bkojha74/task-management/merge"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/pagination"
+	"github.com/bkojha74/task-management/planning"
+	"github.com/bkojha74/task-management/readmodel"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxSyncChanges is the most client changes PostSync applies at once.
+const maxSyncChanges = 100
+
+// Operations of client changes
+const (
+	syncCreate = "create"
+	syncUpdate = "update"
+	syncDelete = "delete"
+)
+
+// Outcomes of client changes
+const (
+	syncApplied  = "applied"
+	syncConflict = "conflict"
+	syncRejected = "rejected"
+)
+
+// syncTaskSort orders the changed tasks: the order they were last written in.
+var syncTaskSort = pagination.Sort{Field: "updated_at"}
+
+// syncCursor is the position of a client in the changes of its tasks and in their tombstones. It is sent
+// to clients as an opaque token.
+type syncCursor struct {
+	Tasks      *pagination.Cursor `bson:"t,omitempty"` // Last changed task the client received
+	Tombstones *pagination.Cursor `bson:"d,omitempty"` // Last tombstone the client received
+	IssuedAt   primitive.DateTime `bson:"at"`
+	Started    primitive.DateTime `bson:"st,omitempty"` // When the sync began, while its pages are fetched with has_more
+}
+
+// settleSyncCursor returns the position a finished sync leaves a client at in a list ordered by the time
+// of writes, whose last received item is last. Writers stamp that time before they commit, so a write
+// stamped before the sync started may only become visible after it: the position is moved back to
+// overlap before the start, and the items written since are sent again. Clients ignore the tasks whose
+// version they have and the deletions of tasks they don't have.
+func settleSyncCursor(last *pagination.Cursor, sort pagination.Sort, started time.Time, overlap time.Duration) *pagination.Cursor {
+	floor := started.Add(-overlap)
+	if last == nil || overlap <= 0 {
+		return last
+	}
+	if written, ok := last.Value.(primitive.DateTime); ok && written.Time().Before(floor) {
+		return last
+	}
+	return &pagination.Cursor{Sort: sort.String(), Value: primitive.NewDateTimeFromTime(floor)}
+}
+
+// encode serializes the cursor into a URL-safe token.
+func (s syncCursor) encode() string {
+	data, err := bson.Marshal(s)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeSyncCursor parses a token issued by GetSync.
+func decodeSyncCursor(token string) (syncCursor, error) {
+	var cursor syncCursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, pagination.ErrInvalidCursor
+	}
+	if err := bson.Unmarshal(data, &cursor); err != nil || cursor.IssuedAt == 0 {
+		return cursor, pagination.ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// syncChanges is the response of GetSync.
+type syncChanges struct {
+	Created []models.Task      `json:"created"` // Tasks created since the cursor
+	Updated []models.Task      `json:"updated"` // Other tasks written since the cursor
+	Deleted []models.Tombstone `json:"deleted"` // Tasks deleted since the cursor
+	Cursor  string             `json:"cursor"`  // Where the next sync continues
+	HasMore bool               `json:"has_more"`
+}
+
+// syncRequest is the body of PostSync.
+type syncRequest struct {
+	Changes []syncChange `json:"changes"`
+}
+
+// syncChange is a change a client made while offline.
+type syncChange struct {
+	Op          string          `json:"op"`                     // create, update or delete
+	ID          string          `json:"id"`                     // Task ID; clients may choose the ID of a new task
+	BaseVersion int             `json:"base_version,omitempty"` // Version the client changed; required for updates
+	Task        json.RawMessage `json:"task,omitempty"`         // The whole task for create and update
+}
+
+// syncResult is the outcome of one client change.
+type syncResult struct {
+	ID     string          `json:"id,omitempty"`
+	Status string          `json:"status"`          // applied, conflict or rejected
+	Task   *models.Task    `json:"task,omitempty"`  // The stored task, or the server's version on a conflict
+	Error  *apierror.Error `json:"error,omitempty"` // Why the change was rejected or conflicts
+}
+
+// GetSync returns the changes to the tasks of the logged-in user since a cursor, for offline-first clients:
+// the tasks created or written since, and the tombstones of the tasks deleted since. Without "since" all
+// tasks are returned as created. The response's cursor is passed back as "since" on the next sync; while
+// "has_more" is true, the client syncs again right away. "limit" (default 50, max 200) caps the tasks and
+// the tombstones of one response. A cursor older than the retention of tombstones is rejected with 410
+// Gone, as deletions may be missing since; the client then syncs from scratch.
+//
+// Tasks and tombstones are ordered by the time they were written, which is stamped before the write
+// commits. So that a write which commits after a sync read past its time is not lost, the cursor of a
+// finished sync overlaps the writes of the last "overlap" before the sync started, which are sent again.
+//
+// Parameters:
+// - retention: How long tombstones are kept; 0 keeps them, and cursors, forever.
+// - overlap: The longest a write may take to commit after stamping its time, clock skew included; 0 for none.
+//
+// Returns:
+// - fiber.Handler: The handler.
+func GetSync(retention, overlap time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userId := c.Locals("userId").(string)
+		userIdHex, _ := primitive.ObjectIDFromHex(userId)
+
+		limit := c.QueryInt("limit", pagination.DefaultLimit)
+		if limit <= 0 || limit > pagination.MaxLimit {
+			return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid limit")
+		}
+		now := time.Now()
+		var since syncCursor
+		if token := c.Query("since"); token != "" {
+			var err error
+			if since, err = decodeSyncCursor(token); err != nil {
+				return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid sync cursor")
+			}
+			if retention > 0 && since.IssuedAt.Time().Before(now.Add(-retention)) {
+				return apierror.New(fiber.StatusGone, apierror.CodeCursorExpired, "Sync cursor expired, sync again without it")
+			}
+		}
+
+		ctx := context.Background()
+		tasks, err := repository.Tasks.Find(ctx, repository.TaskQuery{UserID: userIdHex, Sort: syncTaskSort, After: since.Tasks, Limit: limit})
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+		}
+		tombstones, err := repository.Tombstones.Find(ctx, userIdHex, since.Tombstones, limit)
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Error fetching deleted tasks")
+		}
+
+		changes := syncChanges{Created: []models.Task{}, Updated: []models.Task{}, Deleted: tombstones}
+		next := syncCursor{Tasks: since.Tasks, Tombstones: since.Tombstones, IssuedAt: primitive.NewDateTimeFromTime(now)}
+		if len(tasks) > limit {
+			tasks, changes.HasMore = tasks[:limit], true
+		}
+		if len(changes.Deleted) > limit {
+			changes.Deleted, changes.HasMore = changes.Deleted[:limit], true
+		}
+
+		// Tasks created after the last write the client received, in the same order, are new to it
+		var seen primitive.DateTime
+		if since.Tasks != nil {
+			seen, _ = since.Tasks.Value.(primitive.DateTime)
+		}
+		for _, task := range tasks {
+			created := primitive.DateTime(task.CreatedAt)
+			if since.Tasks == nil || created > seen || (created == seen && task.ID.Hex() > since.Tasks.ID.Hex()) {
+				changes.Created = append(changes.Created, task)
+			} else {
+				changes.Updated = append(changes.Updated, task)
+			}
+		}
+
+		if len(tasks) > 0 {
+			if next.Tasks, err = pagination.CursorFor(tasks[len(tasks)-1], syncTaskSort); err != nil {
+				return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+			}
+		}
+		if len(changes.Deleted) > 0 {
+			if next.Tombstones, err = pagination.CursorFor(changes.Deleted[len(changes.Deleted)-1], repository.TombstoneSort); err != nil {
+				return apierror.Internal(apierror.CodeInternal, "Error fetching deleted tasks")
+			}
+		}
+
+		// The pages of a sync continue exactly where the previous one ended; the last overlaps the writes
+		// that may have been in flight when the first was read
+		started := now
+		if since.Started != 0 {
+			started = since.Started.Time()
+		}
+		if changes.HasMore {
+			next.Started = primitive.NewDateTimeFromTime(started)
+		} else {
+			next.Tasks = settleSyncCursor(next.Tasks, syncTaskSort, started, overlap)
+			next.Tombstones = settleSyncCursor(next.Tombstones, repository.TombstoneSort, started, overlap)
+		}
+		changes.Cursor = next.encode()
+		return response.JSON(c, fiber.StatusOK, changes)
+	}
+}
+
+// PostSync applies the changes an offline client made to the tasks of the logged-in user, in order, and
+// reports the outcome of each (at most 100). Creates may carry an ID the client generated, which makes
+// retrying them safe. Updates replace the task like PUT /tasks/:id and need the "base_version" they were
+// made on; deletes may give one. A change whose task was written or deleted on the server since its base
+// version is not applied and reported as a conflict with the server's version, for the client to merge and
+// retry. Invalid changes are rejected with the error PUT or DELETE /tasks/:id would have answered.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func PostSync(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	var request syncRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	if len(request.Changes) == 0 || len(request.Changes) > maxSyncChanges {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "changes must list between 1 and 100 changes")
+	}
+
+	languages := i18n.Languages(c.Get(fiber.HeaderAcceptLanguage))
+	results := make([]syncResult, 0, len(request.Changes))
+	for _, change := range request.Changes {
+		result := applySyncChange(c, user, change)
+		if result.Error != nil {
+			localized := *result.Error
+			localized.Message, _ = i18n.Translate(result.Error.Message, languages)
+			result.Error = &localized
+		}
+		results = append(results, result)
+	}
+	return response.JSON(c, fiber.StatusOK, fiber.Map{"results": results})
+}
+
+// applySyncChange applies one client change.
+func applySyncChange(c *fiber.Ctx, user *models.User, change syncChange) syncResult {
+	result := syncResult{ID: change.ID}
+	var taskId primitive.ObjectID
+	if change.ID != "" || change.Op != syncCreate {
+		var err error
+		if taskId, err = primitive.ObjectIDFromHex(change.ID); err != nil {
+			return rejectSyncChange(result, apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID"))
+		}
+	}
+
+	var task models.Task
+	if change.Op == syncCreate || change.Op == syncUpdate {
+		if len(change.Task) == 0 || json.Unmarshal(change.Task, &task) != nil {
+			return rejectSyncChange(result, apierror.BadRequest(apierror.CodeInvalidJSON, "task must be the JSON of a task"))
+		}
+	}
+
+	// The version of the task on the server, nil if the client's task is not or no longer there
+	var previous *models.Task
+	if !taskId.IsZero() {
+		var err error
+		previous, err = repository.Tasks.FindByID(c.UserContext(), user.ID, taskId)
+		if err == repository.ErrNotFound {
+			previous = nil
+		} else if err != nil {
+			return rejectSyncChange(result, apierror.Internal(apierror.CodeInternal, "Error fetching task"))
+		}
+	}
+
+	var err error
+
+	switch change.Op {
+	case syncCreate:
+		if previous != nil {
+			// A retry of a create that was applied
+			result.Status, result.Task = syncApplied, previous
+			return result
+		}
+		err = createSyncTask(c, user, taskId, &task)
+	case syncUpdate:
+		if change.BaseVersion <= 0 {
+			return rejectSyncChange(result, apierror.BadRequest(apierror.CodeValidationFailed, "base_version is required"))
+		}
+		if previous == nil {
+			return conflictSyncChange(result, nil)
+		}
+		if previous.Version != change.BaseVersion {
+			return conflictSyncChange(result, previous)
+		}
+		err = updateSyncTask(c, user, previous, &task)
+	case syncDelete:
+		if previous == nil {
+			// Deleted on the server too, or by a retry of this change
+			result.Status = syncApplied
+			return result
+		}
+		if change.BaseVersion > 0 && previous.Version != change.BaseVersion {
+			return conflictSyncChange(result, previous)
+		}
+		err = deleteSyncTask(previous)
+	default:
+		return rejectSyncChange(result, apierror.BadRequest(apierror.CodeValidationFailed, "op must be create, update or delete"))
+	}
+
+	if err == repository.ErrNotFound {
+		// Written or deleted by someone else since it was read
+		current, _ := repository.Tasks.FindByID(c.UserContext(), user.ID, taskId)
+		return conflictSyncChange(result, current)
+	}
+	if err != nil {
+		return rejectSyncChange(result, err)
+	}
+	result.Status = syncApplied
+	if change.Op != syncDelete {
+		result.ID, result.Task = task.ID.Hex(), &task
+	}
+	return result
+}
+
+// createSyncTask stores a task created offline like POST /tasks does, with the ID the client chose, if any.
+func createSyncTask(c *fiber.Ctx, user *models.User, taskId primitive.ObjectID, task *models.Task) (err error) {
+	if task.Title == "" {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Title is required")
+	}
+	turn, err := autoAssign(c, task)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			releaseTurn(turn)
+		}
+	}()
+	if task.AllottedTo == "" {
+		task.AllottedTo = user.Username
+	} else if _, err := repository.Users.FindByUsername(c.UserContext(), task.AllottedTo); err != nil {
+		if err == repository.ErrNotFound {
+			return apierror.BadRequest(apierror.CodeValidationFailed, "Allotted user does not exist")
+		}
+		return apierror.Internal(apierror.CodeInternal, "Error checking allotted user")
+	}
+
+	initTask(task, user.ID)
+	if !taskId.IsZero() {
+		task.ID = taskId
+	}
+	if err := prepareNewTask(c, user, task); err != nil {
+		return err
+	}
+	return storeNewTask(task)
+}
+
+// prepareNewTask validates an initialized new task of a user and sets the fields the server derives.
+func prepareNewTask(c *fiber.Ctx, user *models.User, task *models.Task) error {
+	if err := applyDue(task, userLocation(user)); err != nil {
+		return err
+	}
+	task.DescriptionHTML = markdown.Render(task.Description)
+	if err := normalizePriority(task); err != nil {
+		return err
+	}
+	if err := normalizeVisibility(task, nil); err != nil {
+		return err
+	}
+	if err := validatePlanning(c, *task); err != nil {
+		return err
+	}
+	planning.TrackEffort(task, nil, time.Now())
+	planning.TrackOverdue(task, nil, time.Now())
+	return nil
+}
+
+// updateSyncTask replaces a task with the version a client changed offline, like PUT /tasks/:id does. It
+// returns repository.ErrNotFound if the task was written since it was read.
+func updateSyncTask(c *fiber.Ctx, user *models.User, previous *models.Task, task *models.Task) error {
+	task.UserID = previous.UserID
+	task.ID = previous.ID
+	task.Version = previous.Version + 1
+	if err := applyDue(task, userLocation(user)); err != nil {
+		return err
+	}
+	task.DescriptionHTML = markdown.Render(task.Description)
+	if err := normalizePriority(task); err != nil {
+		return err
+	}
+	if err := normalizeVisibility(task, previous); err != nil {
+		return err
+	}
+	if err := validatePlanning(c, *task); err != nil {
+		return err
+	}
+	planning.TrackEffort(task, previous, time.Now())
+	planning.TrackOverdue(task, previous, time.Now())
+	keepServerFields(task, previous)
+	merge.Track(task, previous)
+
+	err := outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := repository.Tasks.Update(ctx, task, previous.Version); err != nil {
+			return err
+		}
+		if task.Status == models.TaskStatusDone && previous.Status != models.TaskStatusDone {
+			return outbox.Publish(ctx, events.TaskCompleted, task.ID.Hex(), *task)
+		}
+		return nil
+	})
+	if err == repository.ErrNotFound {
+		return err
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not update task")
+	}
+	cache.InvalidateTask(task.UserID.Hex(), task.ID.Hex())
+	readmodel.SyncOrLog(context.Background(), *task)
+	return nil
+}
+
+// deleteSyncTask moves a task to the trash and leaves its tombstone, like DELETE /tasks/:id does.
+func deleteSyncTask(task *models.Task) error {
+	err := outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := repository.Tasks.Trash(ctx, task.UserID, task.ID); err != nil {
+			return err
+		}
+		return repository.Tombstones.Create(ctx, &models.Tombstone{TaskID: task.ID, UserID: task.UserID})
+	})
+	if err == repository.ErrNotFound {
+		return nil // Deleted concurrently, which is what the client wanted
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not delete task")
+	}
+	cache.InvalidateTask(task.UserID.Hex(), task.ID.Hex())
+	readmodel.RemoveOrLog(context.Background(), task.ID)
+	return nil
+}
+
+// rejectSyncChange reports a change that was not applied because of an error.
+func rejectSyncChange(result syncResult, err error) syncResult {
+	apiErr, ok := err.(*apierror.Error)
+	if !ok {
+		apiErr = apierror.Internal(apierror.CodeInternal, "internal server error")
+	}
+	result.Status, result.Error = syncRejected, apiErr
+	return result
+}
+
+// conflictSyncChange reports a change that was not applied because the task changed on the server; current
+// is the server's version, nil if the task was deleted.
+func conflictSyncChange(result syncResult, current *models.Task) syncResult {
+	result.Status, result.Task = syncConflict, current
+	if current == nil {
+		result.Error = apierror.Conflict(apierror.CodeVersionConflict, "Task was deleted by someone else")
+	} else {
+		result.Error = apierror.Conflict(apierror.CodeVersionConflict, "Task was modified by someone else, reload it and retry").
+			WithDetails(fiber.Map{"current_version": current.Version})
+	}
+	return result
+}
@@ -5,19 +5,59 @@ package handlers
 
 import (
 	"context"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/bkojha74/task-management/database"
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/features"
+	"github.com/bkojha74/task-management/markdown"
+	"github.com/bkojha74/task-management/merge"
 	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/naturaldate"
+	"github.com/bkojha74/task-management/notifications"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/pagination"
+	"github.com/bkojha74/task-management/permissions"
+	"github.com/bkojha74/task-management/planning"
+	"github.com/bkojha74/task-management/quota"
+	"github.com/bkojha74/task-management/readmodel"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+	"github.com/bkojha74/task-management/validation"
 
 	"github.com/gofiber/fiber/v2"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// taskSortFields are the task fields GetTasks can sort by, in addition to _id. "manual" is the user's
+// manual order, stored as manual_rank.
+var taskSortFields = []string{"title", "status", "start_time", "end_time", "created_at", "updated_at", "manual"}
+
+// taskPage is one page of a task list, as cached between requests.
+type taskPage struct {
+	Tasks      []models.TaskListEntry
+	NextCursor string
+}
+
+// setPageMeta exposes the pagination state of a task list, as the X-Next-Cursor header
+// and as envelope metadata and links.
+func setPageMeta(c *fiber.Ctx, page taskPage) {
+	response.AddMeta(c, "count", len(page.Tasks))
+	if page.NextCursor != "" {
+		c.Set("X-Next-Cursor", page.NextCursor)
+		response.AddMeta(c, "next_cursor", page.NextCursor)
+	}
+	addPageLinks(c, page.NextCursor)
+}
+
 // CreateTask handles the creation of a new task. It validates the allotted user,
-// sets the task's initial status, and inserts the task into the database.
+// sets the task's initial status, and inserts the task into the database. With the duplicate-check
+// feature flag, near-duplicates of open tasks are rejected unless "force=true" is given. Tasks created
+// without allotted_to in a project with an assignment rule are assigned by the rule.
 //
 // Parameters:
 // - c: Fiber context, which provides methods to interact with the request and response.
@@ -27,35 +67,125 @@ import (
 func CreateTask(c *fiber.Ctx) error {
 	userId := c.Locals("userId").(string)
 
+	location, err := localizeRequest(c, "start_time", "end_time")
+	if err != nil {
+		return err
+	}
 	var task models.Task
 	if err := c.BodyParser(&task); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
 	}
 
-	// Validate allottedTo field
-	var user models.User
-	err := database.UsersCollection.FindOne(context.Background(), bson.M{"username": task.AllottedTo}).Decode(&user)
+	// Validate allottedTo field, picked by the project's assignment rule when left empty
+	turn, err := autoAssign(c, &task)
+	if err != nil {
+		return err
+	}
+	stored := false
+	defer func() {
+		if !stored {
+			releaseTurn(turn)
+		}
+	}()
+	_, err = repository.Users.FindByUsername(c.UserContext(), task.AllottedTo)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Allotted user does not exist"})
+		if err == repository.ErrNotFound {
+			return apierror.BadRequest(apierror.CodeValidationFailed, "Allotted user does not exist")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error checking allotted user"})
+		return apierror.Internal(apierror.CodeInternal, "Error checking allotted user")
+	}
+
+	ownerId, _ := primitive.ObjectIDFromHex(userId)
+	initTask(&task, ownerId)
+	if err := applyDue(&task, location); err != nil {
+		return err
+	}
+	task.DescriptionHTML = markdown.Render(task.Description)
+	if err := normalizePriority(&task); err != nil {
+		return err
+	}
+	if err := normalizeVisibility(&task, nil); err != nil {
+		return err
+	}
+	if err := validatePlanning(c, task); err != nil {
+		return err
+	}
+	if featureEnabled(c, features.DuplicateCheck) && !c.QueryBool("force") {
+		if err := rejectDuplicates(c, task); err != nil {
+			return err
+		}
+	}
+	planning.TrackEffort(&task, nil, time.Now())
+	planning.TrackOverdue(&task, nil, time.Now())
+
+	addWarnings(c, validation.TaskWarnings(c.UserContext(), task))
+
+	if err := storeNewTask(&task); err != nil {
+		return err
 	}
+	stored = true
 
+	return response.JSON(c, fiber.StatusCreated, withTaskLinks(c, task))
+}
+
+// initTask sets the fields a new task of an owner starts with, whatever the client sent.
+func initTask(task *models.Task, ownerId primitive.ObjectID) {
 	task.ID = primitive.NewObjectID()
-	task.UserID, _ = primitive.ObjectIDFromHex(userId)
-	task.StartDate = primitive.NewDateTimeFromTime(time.Now())
-	task.Status = "Pending"
+	task.UserID = ownerId
+	task.StartDate = models.NewTimestamp(time.Now())
+	task.Status = models.TaskStatusPending
+	task.Version = 1
+	task.SnoozedUntil, task.Snoozes = 0, nil
+	task.Handoffs = nil
+	task.Position = "" // New tasks join the end of the manual order
+	task.GitHubIssue = nil
+}
 
-	_, err = database.TasksCollection.InsertOne(context.Background(), task)
+// storeNewTask stores a validated new task with its task.created event and the notification of its
+// assignee, and refreshes the cache and the list view. It fails with 402 once the owner's task quota is
+// used up.
+func storeNewTask(task *models.Task) error {
+	if err := checkQuota(context.Background(), task.UserID, quota.ResourceTasks); err != nil {
+		return err
+	}
+	err := outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := repository.Tasks.Create(ctx, task); err != nil {
+			return err
+		}
+		if err := outbox.Publish(ctx, events.TaskCreated, task.ID.Hex(), *task); err != nil {
+			return err
+		}
+
+		// Let the assignee know about the new task
+		return outbox.Notify(ctx, notifications.Notification{
+			Event:     notifications.EventTaskAssigned,
+			Recipient: task.AllottedTo,
+			TaskID:    task.ID.Hex(),
+			Subject:   "New task: " + task.Title,
+			Message:   "You have been assigned the task \"" + task.Title + "\".",
+		})
+	})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create task"})
+		return apierror.Internal(apierror.CodeInternal, "Could not create task")
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(task)
+	cache.InvalidateTask(task.UserID.Hex(), task.ID.Hex())
+	readmodel.SyncOrLog(context.Background(), *task)
+	return nil
 }
 
 // GetTasks retrieves all tasks associated with the logged-in user from the database.
+// Results are ordered by the "sort" query parameter (default _id, "-" prefix for descending) with _id as tiebreaker;
+// "sort=manual" is the user's manual order, pinned tasks first.
+// When "limit" or "cursor" is given the list is paginated: the token for the next page is returned in the
+// X-Next-Cursor header and passed back as "cursor", which stays stable while tasks are inserted or deleted.
+// "overdue=true" lists only the tasks flagged by the overdue job, "overdue=false" the others.
+// Tasks archived by the retention job are left out unless "archived=true", which lists only those.
+// "created_after", "created_before", "updated_after" and "updated_before" (RFC3339) restrict the list to tasks
+// created or last written in that range; the "after" bounds are inclusive.
+// "fields" (e.g. "title,status,end_time") loads and returns only those fields of each task, plus its id.
+// Each task comes with the summary of its assignee and its comment count, joined in the same query.
+// Guests see only the tasks of the project they were invited to.
 //
 // Parameters:
 // - c: Fiber context, which provides methods to interact with the request and response.
@@ -68,28 +198,196 @@ func GetTasks(c *fiber.Ctx) error {
 	// Convert userId to ObjectID
 	userObjectId, err := primitive.ObjectIDFromHex(userId)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Invalid user ID"})
+		return apierror.Internal(apierror.CodeInternal, "Invalid user ID")
+	}
+
+	fields, err := parseFields(c, taskEntryFields)
+	if err != nil {
+		return err
+	}
+
+	// Serve from cache when the same list was fetched recently; guests see only their project and are
+	// served uncached, so that their lists never mix with the owner's
+	subject := subjectOf(c)
+	cacheKey := cache.TaskListKey(userId, string(c.Request().URI().QueryString()))
+	if cached, ok := cache.Get(cacheKey); ok && !subject.Guest() {
+		page := cached.(taskPage)
+		setPageMeta(c, page)
+		return sendFields(c, withTaskListLinks(c, page.Tasks), fields)
+	}
+
+	sort, err := pagination.ParseSort(c.Query("sort"), taskSortFields...)
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid sort field")
+	}
+	if sort.Field == "manual" {
+		sort.Field = "manual_rank"
+	}
+
+	limit := 0
+	if c.Query("limit") != "" || c.Query("cursor") != "" {
+		limit = c.QueryInt("limit", pagination.DefaultLimit)
+		if limit <= 0 || limit > pagination.MaxLimit {
+			return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid limit")
+		}
+	}
+
+	var after *pagination.Cursor
+	if token := c.Query("cursor"); token != "" {
+		if after, err = pagination.Decode(token, sort); err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid cursor")
+		}
+	}
+
+	var overdue *bool
+	if value := c.Query("overdue"); value != "" {
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid overdue flag, use true or false")
+		}
+		overdue = &flag
+	}
+
+	archived := false
+	if value := c.Query("archived"); value != "" {
+		if archived, err = strconv.ParseBool(value); err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid archived flag, use true or false")
+		}
+	}
+
+	query := repository.TaskQuery{
+		UserID:   userObjectId,
+		Overdue:  overdue,
+		Archived: &archived,
+		Sort:     sort,
+		After:    after,
+		Limit:    limit,
+	}
+	permissions.RestrictQuery(subject, &query)
+	if fields != nil {
+		// project_id is needed for the links
+		query.Fields = append([]string{"project_id"}, fields...)
+	}
+	for name, bound := range map[string]*time.Time{
+		"created_after":  &query.CreatedAfter,
+		"created_before": &query.CreatedBefore,
+		"updated_after":  &query.UpdatedAfter,
+		"updated_before": &query.UpdatedBefore,
+	} {
+		if value := c.Query(name); value != "" {
+			if *bound, err = time.Parse(time.RFC3339, value); err != nil {
+				return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid "+name+", use RFC3339 e.g. 2024-07-05T00:00:00Z")
+			}
+		}
+	}
+
+	tasks, err := repository.Tasks.FindEntries(c.UserContext(), query)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+	}
+
+	// One extra task was fetched to detect whether another page exists
+	page := taskPage{Tasks: tasks}
+	if limit > 0 && len(tasks) > limit {
+		page.Tasks = tasks[:limit]
+		next, err := pagination.CursorFor(page.Tasks[limit-1].Task, sort)
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Error building cursor")
+		}
+		page.NextCursor = next.Encode()
+	}
+
+	if !subject.Guest() {
+		cache.Set(cacheKey, page)
+	}
+	setPageMeta(c, page)
+
+	return sendFields(c, withTaskListLinks(c, page.Tasks), fields)
+}
+
+// GetTaskSummaries lists the logged-in user's tasks from the denormalized list view: title, status,
+// priority, due date, assignee display name and comment/attachment counts, without any lookups.
+// It accepts the same "sort", "limit", "cursor" and "fields" query parameters as GetTasks; the sort fields
+// are title, status, due and updated_at.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetTaskSummaries(c *fiber.Ctx) error {
+	userId := c.Locals("userId").(string)
+
+	userObjectId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Invalid user ID")
+	}
+
+	sort, err := pagination.ParseSort(c.Query("sort"), readmodel.SortFields...)
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid sort field")
+	}
+
+	fields, err := parseFields(c, summaryFields)
+	if err != nil {
+		return err
+	}
+
+	limit := c.QueryInt("limit", pagination.DefaultLimit)
+	if limit <= 0 || limit > pagination.MaxLimit {
+		return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid limit")
 	}
 
-	var tasks []models.Task
-	filter := bson.M{"userId": userObjectId}
+	var after *pagination.Cursor
+	if token := c.Query("cursor"); token != "" {
+		if after, err = pagination.Decode(token, sort); err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid cursor")
+		}
+	}
 
-	cursor, err := database.TasksCollection.Find(context.Background(), filter)
+	views, err := readmodel.List(c.UserContext(), userObjectId, sort, after, limit)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "No tasks found"})
+		return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+	}
+
+	// One extra view was fetched to detect whether another page exists
+	nextCursor := ""
+	if len(views) > limit {
+		views = views[:limit]
+		next, err := pagination.CursorFor(views[limit-1], sort)
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Error building cursor")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error fetching tasks"})
+		c.Set("X-Next-Cursor", next.Encode())
+		response.AddMeta(c, "next_cursor", next.Encode())
+		nextCursor = next.Encode()
 	}
+	response.AddMeta(c, "count", len(views))
+	addPageLinks(c, nextCursor)
+
+	return sendFields(c, views, fields)
+}
 
-	if err = cursor.All(context.Background(), &tasks); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error decoding tasks"})
+// RebuildTaskListView recreates the list-view documents of all tasks, e.g. after a failed sync.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func RebuildTaskListView(c *fiber.Ctx) error {
+	count, err := readmodel.Rebuild(context.Background())
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not rebuild task list view")
 	}
 
-	return c.Status(fiber.StatusOK).JSON(tasks)
+	return response.JSON(c, fiber.StatusOK, fiber.Map{"synced": count})
 }
 
 // GetTask retrieves a specific task by its ID and the logged-in user ID from the database.
+// Like GetTasks, it accepts "fields" to return only some fields of the task.
+// The response carries the task's updated_at as Last-Modified, and a request whose If-None-Match or
+// If-Modified-Since header shows the client already has the current task gets 304 Not Modified.
 //
 // Parameters:
 // - c: Fiber context, which provides methods to interact with the request and response.
@@ -102,20 +400,43 @@ func GetTask(c *fiber.Ctx) error {
 
 	taskIdHex, err := primitive.ObjectIDFromHex(taskId)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task ID"})
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID")
 	}
 
-	userIdHex, _ := primitive.ObjectIDFromHex(userId)
-	var task models.Task
-	err = database.TasksCollection.FindOne(context.Background(), bson.M{"_id": taskIdHex, "userId": userIdHex}).Decode(&task)
+	fields, err := parseFields(c, taskFields)
+	if err != nil {
+		return err
+	}
+
+	// Only the caller's own tasks are cached, under their key; guests are served uncached
+	guest := subjectOf(c).Guest()
+	cacheKey := cache.TaskKey(userId, taskIdHex.Hex())
+	if cached, ok := cache.Get(cacheKey); ok && !guest {
+		task := cached.(models.Task)
+		if notModified(c, task) {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+		return sendFields(c, withTaskLinks(c, task), fields)
+	}
+
+	task, err := findTask(c, taskIdHex, false)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
+		return err
 	}
 
-	return c.JSON(task)
+	if !guest && task.UserID.Hex() == userId {
+		cache.Set(cacheKey, *task)
+	}
+	if notModified(c, *task) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	return sendFields(c, withTaskLinks(c, *task), fields)
 }
 
 // UpdateTask updates a specific task by its ID and the logged-in user ID in the database.
+// Updates use optimistic concurrency control: the client sends the version it last read, in the
+// "version" field or an If-Match header, and the update is rejected with 409 Conflict if the task has
+// been changed since. Requests without a version update whatever version is current.
 //
 // Parameters:
 // - c: Fiber context, which provides methods to interact with the request and response.
@@ -128,31 +449,214 @@ func UpdateTask(c *fiber.Ctx) error {
 
 	taskIdHex, err := primitive.ObjectIDFromHex(taskId)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task ID"})
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID")
 	}
 
 	userIdHex, _ := primitive.ObjectIDFromHex(userId)
+	location, err := localizeRequest(c, "start_time", "end_time")
+	if err != nil {
+		return err
+	}
 	var task models.Task
 	if err := c.BodyParser(&task); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+
+	expectedVersion := task.Version
+	if ifMatch := c.Get(fiber.HeaderIfMatch); ifMatch != "" {
+		if expectedVersion, err = parseVersionTag(ifMatch); err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidHeader, "Invalid If-Match header")
+		}
+	}
+
+	previous, err := findTask(c, taskIdHex, true)
+	if err != nil {
+		return err
+	}
+
+	// Without a version from the client, update the current version
+	if expectedVersion == 0 {
+		expectedVersion = previous.Version
 	}
 
 	task.UserID = userIdHex
 	task.ID = taskIdHex
+	task.Version = expectedVersion + 1
+	if err := applyDue(&task, location); err != nil {
+		return err
+	}
+	task.DescriptionHTML = markdown.Render(task.Description)
+	if err := normalizePriority(&task); err != nil {
+		return err
+	}
+	if err := normalizeVisibility(&task, previous); err != nil {
+		return err
+	}
+	if err := validatePlanning(c, task); err != nil {
+		return err
+	}
+	planning.TrackEffort(&task, previous, time.Now())
+	planning.TrackOverdue(&task, previous, time.Now())
+	keepServerFields(&task, previous)
+	merge.Track(&task, previous)
+
+	addWarnings(c, validation.TaskWarnings(c.UserContext(), task))
+
+	err = outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := repository.Tasks.Update(ctx, &task, expectedVersion); err != nil {
+			return err
+		}
+		if task.Status == models.TaskStatusDone && previous.Status != models.TaskStatusDone {
+			return outbox.Publish(ctx, events.TaskCompleted, task.ID.Hex(), task)
+		}
+		return nil
+	})
+	if err != nil && err != repository.ErrNotFound {
+		return apierror.Internal(apierror.CodeInternal, "Could not update task")
+	}
 
-	result, err := database.TasksCollection.UpdateOne(context.Background(), bson.M{"_id": taskIdHex, "userId": userIdHex}, bson.M{"$set": task})
+	if err == repository.ErrNotFound {
+		current, err := repository.Tasks.FindByID(c.UserContext(), userIdHex, taskIdHex)
+		if err != nil {
+			return apierror.NotFound(apierror.CodeNotFound, "Task not found")
+		}
+		return apierror.Conflict(apierror.CodeVersionConflict, "Task was modified by someone else, reload it and retry").
+			WithDetails(fiber.Map{"current_version": current.Version})
+	}
+
+	cache.InvalidateTask(userId, taskIdHex.Hex())
+	readmodel.SyncOrLog(context.Background(), task)
+	c.Set(fiber.HeaderETag, versionTag(task.Version))
+	return response.JSON(c, fiber.StatusOK, withTaskLinks(c, task))
+}
+
+// keepServerFields copies the fields a replacement of a task does not change from its previous version.
+func keepServerFields(task *models.Task, previous *models.Task) {
+	task.Rank = previous.Rank // Board positions only change through board moves
+	task.Pinned = previous.Pinned
+	task.Position = previous.Position // Like the manual order, which only changes through reorders
+	task.SnoozedUntil, task.Snoozes = previous.SnoozedUntil, previous.Snoozes
+	task.Handoffs = previous.Handoffs
+	task.GitHubIssue = previous.GitHubIssue // Linked and unlinked through /tasks/:id/github-issue
+	task.CalDAV = previous.CalDAV           // Keeps the resource name a CalDAV client chose
+}
+
+// normalizePriority defaults an empty task priority to medium and rejects unknown priorities.
+func normalizePriority(task *models.Task) error {
+	switch task.Priority {
+	case "":
+		task.Priority = models.TaskPriorityMedium
+	case models.TaskPriorityLow, models.TaskPriorityMedium, models.TaskPriorityHigh:
+	default:
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Priority must be low, medium or high")
+	}
+	return nil
+}
+
+// normalizeVisibility rejects unknown visibilities. A task without one keeps the visibility it had, and a
+// new task is visible to its team.
+func normalizeVisibility(task *models.Task, previous *models.Task) error {
+	if !permissions.Valid(task.Visibility) {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Visibility must be private, team or org")
+	}
+	if task.Visibility == "" {
+		task.Visibility = permissions.VisibilityTeam
+		if previous != nil {
+			task.Visibility = permissions.Of(*previous)
+		}
+	}
+	return nil
+}
+
+// validatePlanning rejects negative estimates, invalid dependencies and projects the logged-in user does
+// not own.
+func validatePlanning(c *fiber.Ctx, task models.Task) error {
+	if task.EstimateMinutes < 0 {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Estimate must not be negative")
+	}
+	if err := validateDependencies(c, task); err != nil {
+		return err
+	}
+	if task.ProjectID.IsZero() {
+		return nil
+	}
+	if _, err := findOwnProject(c, task.ProjectID.Hex()); err != nil {
+		if apiErr, ok := err.(*apierror.Error); ok && apiErr.Status == fiber.StatusNotFound {
+			return apierror.BadRequest(apierror.CodeValidationFailed, "Project does not exist")
+		}
+		return err
+	}
+	return nil
+}
+
+// applyDue sets end_time from a natural-language "due" phrase, read in the user's timezone.
+func applyDue(task *models.Task, location *time.Location) error {
+	if task.Due == "" {
+		return nil
+	}
+
+	due, err := naturaldate.Parse(task.Due, time.Now().In(location))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update task"})
+		return apierror.BadRequest(apierror.CodeValidationFailed,
+			"Cannot understand due \""+task.Due+"\", try e.g. \"tomorrow 5pm\", \"next friday\", \"in 3 days\" or \"2024-07-05 17:00\"").
+			WithDetails(fiber.Map{"field": "due"})
 	}
+	task.EndDate = models.NewTimestamp(due)
+	task.Due = ""
+	return nil
+}
 
-	if result.MatchedCount == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
+// addWarnings attaches non-fatal validation warnings to the response.
+func addWarnings(c *fiber.Ctx, warnings []validation.Warning) {
+	for _, warning := range warnings {
+		response.AddWarning(c, warning.Code, warning.Message)
 	}
+}
 
-	return c.JSON(task)
+// versionTag formats a task version as an ETag value.
+func versionTag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
 }
 
-// DeleteTask deletes a specific task by its ID and the logged-in user ID from the database.
+// notModified sets the ETag and Last-Modified headers of a task and reports whether the request's
+// conditional headers match them. If-None-Match takes precedence over If-Modified-Since, which has
+// a resolution of one second.
+func notModified(c *fiber.Ctx, task models.Task) bool {
+	c.Set(fiber.HeaderETag, versionTag(task.Version))
+	if !task.UpdatedAt.IsZero() {
+		c.Set(fiber.HeaderLastModified, task.UpdatedAt.Time().UTC().Format(http.TimeFormat))
+	}
+
+	if ifNoneMatch := c.Get(fiber.HeaderIfNoneMatch); ifNoneMatch != "" {
+		for _, tag := range strings.Split(ifNoneMatch, ",") {
+			if strings.TrimSpace(tag) == "*" {
+				return true
+			}
+			if version, err := parseVersionTag(tag); err == nil && version == task.Version {
+				return true
+			}
+		}
+		return false
+	}
+
+	if task.UpdatedAt.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(c.Get(fiber.HeaderIfModifiedSince))
+	if err != nil {
+		return false
+	}
+	return !task.UpdatedAt.Time().Truncate(time.Second).After(since)
+}
+
+// parseVersionTag parses an ETag/If-Match value produced by versionTag.
+func parseVersionTag(tag string) (int, error) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	return strconv.Atoi(strings.Trim(tag, `"`))
+}
+
+// DeleteTask deletes a specific task by its ID and the logged-in user ID, moving it to the trash from where
+// it can be restored until the retention job purges it.
 //
 // Parameters:
 // - c: Fiber context, which provides methods to interact with the request and response.
@@ -165,20 +669,31 @@ func DeleteTask(c *fiber.Ctx) error {
 
 	taskIdHex, err := primitive.ObjectIDFromHex(taskId)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task ID"})
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID")
 	}
 
 	userIdHex, _ := primitive.ObjectIDFromHex(userId)
+	if _, err := findTask(c, taskIdHex, true); err != nil {
+		return err
+	}
 
-	filter := bson.M{"_id": taskIdHex, "userId": userIdHex}
-	result, err := database.TasksCollection.DeleteOne(context.Background(), filter)
+	// The task goes to the trash, keeping its comments until it is purged, and a tombstone tells syncing
+	// clients about it
+	err = outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := repository.Tasks.Trash(ctx, userIdHex, taskIdHex); err != nil {
+			return err
+		}
+		return repository.Tombstones.Create(ctx, &models.Tombstone{TaskID: taskIdHex, UserID: userIdHex})
+	})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete task"})
+		if err == repository.ErrNotFound {
+			return apierror.NotFound(apierror.CodeNotFound, "Task not found")
+		}
+		return apierror.Internal(apierror.CodeInternal, "Could not delete task")
 	}
 
-	if result.DeletedCount == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
-	}
+	cache.InvalidateTask(userId, taskIdHex.Hex())
+	readmodel.RemoveOrLog(context.Background(), taskIdHex)
 
 	return c.SendStatus(fiber.StatusNoContent)
 }
@@ -0,0 +1,250 @@
+// telegram.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/naturaldate"
+	"github.com/bkojha74/task-management/pagination"
+	"github.com/bkojha74/task-management/planning"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+	"github.com/bkojha74/task-management/telegram"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Limits of the Telegram bot
+const (
+	telegramLinkExpiry = 10 * time.Minute
+	telegramTaskList   = 10
+	telegramReplyLimit = 10 * time.Second
+)
+
+// telegramHelp lists the bot commands.
+const telegramHelp = "/new <title> [due <when>] creates a task for you, e.g. /new Send invoice due friday 5pm\n" +
+	"/tasks lists your open tasks\n" +
+	"/unlink stops the notifications in this chat"
+
+// telegramLink is the response of LinkTelegram.
+type telegramLink struct {
+	Code      string    `json:"code"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LinkTelegram issues a code that links a Telegram chat to the logged-in user. Opening the returned link
+// sends the code to the bot, which then delivers the user's notifications to that chat and accepts their
+// commands. The code is valid for 10 minutes.
+//
+// Parameters:
+// - bot: The Telegram bot.
+//
+// Returns:
+// - fiber.Handler: The handler issuing link codes.
+func LinkTelegram(bot *telegram.Bot) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, err := currentUser(c)
+		if err != nil {
+			return err
+		}
+
+		expiry := time.Now().Add(telegramLinkExpiry)
+		code := bot.LinkCode(user.ID, expiry)
+		return response.JSON(c, fiber.StatusOK, telegramLink{Code: code, URL: bot.LinkURL(code), ExpiresAt: expiry.UTC().Truncate(time.Second)})
+	}
+}
+
+// UnlinkTelegram unlinks the Telegram chat of the logged-in user.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func UnlinkTelegram(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	user.TelegramChat = 0
+	if err := repository.Users.Update(context.Background(), user); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not unlink Telegram")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// TelegramWebhook receives the updates Telegram posts for the bot and answers commands in private chats:
+// "/start <code>" links the chat, "/new" creates a task and "/tasks" lists the open ones. Updates are
+// always acknowledged, so Telegram does not resend them; failed replies are logged.
+//
+// Parameters:
+// - bot: The Telegram bot.
+//
+// Returns:
+// - fiber.Handler: The handler of the bot's webhook.
+func TelegramWebhook(bot *telegram.Bot) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if subtle.ConstantTimeCompare([]byte(c.Get(telegram.SecretHeader)), []byte(bot.WebhookSecret)) != 1 {
+			return apierror.Unauthorized(apierror.CodeInvalidToken, "invalid secret token")
+		}
+
+		var update telegram.Update
+		if err := c.BodyParser(&update); err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+		}
+		if update.Message == nil {
+			return c.SendStatus(fiber.StatusOK)
+		}
+		command, args, ok := bot.ParseCommand(update.Message.Text)
+		if !ok {
+			return c.SendStatus(fiber.StatusOK)
+		}
+
+		chat := update.Message.Chat
+		reply := "Please message me directly, commands are only accepted in private chats."
+		if chat.Type == "private" {
+			reply = telegramCommand(bot, chat.ID, command, args)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), telegramReplyLimit)
+		defer cancel()
+		if err := bot.SendMessage(ctx, chat.ID, reply); err != nil {
+			log.Printf("Could not answer Telegram update %d: %v", update.UpdateID, err)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	}
+}
+
+// telegramCommand runs a bot command sent in a private chat and returns the reply.
+func telegramCommand(bot *telegram.Bot, chatID int64, command, args string) string {
+	ctx := context.Background()
+	if command == "start" && args != "" {
+		return linkTelegramChat(bot, chatID, args)
+	}
+
+	user, err := repository.Users.FindByTelegramChat(ctx, chatID)
+	if err == repository.ErrNotFound {
+		return "This chat is not linked to an account yet. Request a link with POST /users/me/telegram and open it."
+	}
+	if err != nil {
+		return "Something went wrong, please try again later."
+	}
+	if !user.DeactivatedAt.IsZero() {
+		return "Your account is deactivated."
+	}
+
+	switch command {
+	case "new":
+		return createTelegramTask(user, args)
+	case "tasks":
+		return listTelegramTasks(user)
+	case "unlink":
+		user.TelegramChat = 0
+		if err := repository.Users.Update(ctx, user); err != nil {
+			return "Something went wrong, please try again later."
+		}
+		return "This chat is unlinked from " + user.Username + "."
+	}
+	return telegramHelp
+}
+
+// linkTelegramChat links a chat to the user of a link code, taking it over from any user linked before.
+func linkTelegramChat(bot *telegram.Bot, chatID int64, code string) string {
+	ctx := context.Background()
+	userID, err := bot.ParseLinkCode(code, time.Now())
+	if err != nil {
+		return "This link is invalid or expired. Request a new one with POST /users/me/telegram."
+	}
+	user, err := repository.Users.FindByID(ctx, userID)
+	if err != nil || !user.DeactivatedAt.IsZero() {
+		return "This link is invalid or expired. Request a new one with POST /users/me/telegram."
+	}
+
+	if previous, err := repository.Users.FindByTelegramChat(ctx, chatID); err == nil && previous.ID != user.ID {
+		previous.TelegramChat = 0
+		if err := repository.Users.Update(ctx, previous); err != nil {
+			return "Something went wrong, please try again later."
+		}
+	}
+	user.TelegramChat = chatID
+	if err := repository.Users.Update(ctx, user); err != nil {
+		return "Something went wrong, please try again later."
+	}
+	return "This chat is linked to " + user.Username + ". You will get your task notifications here.\n\n" + telegramHelp
+}
+
+// createTelegramTask creates a task for the user from "/new <title> [due <when>]". When the text after the
+// last " due " is not a date, it is part of the title.
+func createTelegramTask(user *models.User, args string) string {
+	if args == "" {
+		return "Send the title of the task, e.g. /new Send invoice due friday 5pm"
+	}
+
+	task := models.Task{Title: args, AllottedTo: user.Username}
+	location := userLocation(user)
+	if i := strings.LastIndex(strings.ToLower(args), " due "); i > 0 {
+		if due, err := naturaldate.Parse(args[i+len(" due "):], time.Now().In(location)); err == nil {
+			task.Title = strings.TrimSpace(args[:i])
+			task.EndDate = models.NewTimestamp(due)
+		}
+	}
+	initTask(&task, user.ID)
+	task.Priority = models.TaskPriorityMedium
+	if err := normalizeVisibility(&task, nil); err != nil {
+		return "Something went wrong, please try again later."
+	}
+	planning.TrackEffort(&task, nil, time.Now())
+	planning.TrackOverdue(&task, nil, time.Now())
+
+	if err := storeNewTask(&task); err != nil {
+		if apiErr, ok := err.(*apierror.Error); ok && apiErr.Code == apierror.CodeQuotaExceeded {
+			return "Your task quota is used up, delete some tasks first."
+		}
+		return "Could not create the task, please try again later."
+	}
+	if task.EndDate.IsZero() {
+		return "Created \"" + task.Title + "\"."
+	}
+	return "Created \"" + task.Title + "\", due " + task.EndDate.Time().In(location).Format("Mon 2 Jan 15:04") + "."
+}
+
+// listTelegramTasks lists the open tasks allotted to the user, those due first first.
+func listTelegramTasks(user *models.User) string {
+	tasks, err := repository.Tasks.Find(context.Background(), repository.TaskQuery{
+		AllottedTo:    user.Username,
+		ExcludeStatus: models.TaskStatusDone,
+		Sort:          pagination.Sort{Field: "end_time"},
+		Limit:         telegramTaskList,
+	})
+	if err != nil {
+		return "Something went wrong, please try again later."
+	}
+	if len(tasks) == 0 {
+		return "You have no open tasks."
+	}
+
+	location := userLocation(user)
+	var lines []string
+	for i, task := range tasks {
+		if i == telegramTaskList {
+			lines = append(lines, "…")
+			break
+		}
+		line := fmt.Sprintf("• %s (%s)", task.Title, task.Status)
+		if !task.EndDate.IsZero() {
+			line += ", due " + task.EndDate.Time().In(location).Format("Mon 2 Jan 15:04")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,158 @@
+// timezone.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// naiveLayouts are the accepted date formats without a UTC offset, read in the user's timezone.
+var naiveLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	time.DateOnly,
+}
+
+// timezoneSettings is the body and response of the timezone endpoints.
+type timezoneSettings struct {
+	Timezone string `json:"timezone"`
+}
+
+// GetTimezone returns the timezone of the logged-in user, "UTC" when none is set.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetTimezone(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	return response.JSON(c, fiber.StatusOK, timezoneSettings{Timezone: userLocation(user).String()})
+}
+
+// UpdateTimezone sets the IANA timezone, e.g. "Asia/Kolkata", in which the dates the logged-in user sends
+// without a UTC offset are read. An empty timezone resets it to UTC.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func UpdateTimezone(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	var settings timezoneSettings
+	if err := c.BodyParser(&settings); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	if !validTimezone(settings.Timezone) {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Unknown timezone, use an IANA name such as \"Asia/Kolkata\"")
+	}
+
+	user.Timezone = settings.Timezone
+	if err := repository.Users.Update(context.Background(), user); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not update timezone")
+	}
+
+	return response.JSON(c, fiber.StatusOK, timezoneSettings{Timezone: userLocation(user).String()})
+}
+
+// validTimezone reports whether name is empty or a known IANA timezone.
+func validTimezone(name string) bool {
+	_, err := time.LoadLocation(name)
+	return err == nil && name != "Local"
+}
+
+// userLocation returns the timezone of a user, UTC when none or an unknown one is stored.
+func userLocation(user *models.User) *time.Location {
+	location, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// localizeRequest rewrites the given date fields of a JSON request body that were sent without a UTC offset
+// (e.g. "2024-07-05T17:00" or "2024-07-05") to RFC3339 in the logged-in user's timezone, so that the body
+// parser reads them as the user meant them.
+//
+// Parameters:
+// - c: Fiber context of the request.
+// - fields: The JSON names of the date fields.
+//
+// Returns:
+// - *time.Location: The user's timezone.
+// - error: An error object if the user cannot be loaded or a field is not a date.
+func localizeRequest(c *fiber.Ctx, fields ...string) (*time.Location, error) {
+	user, err := currentUser(c)
+	if err != nil {
+		return nil, err
+	}
+	location := userLocation(user)
+
+	if !strings.HasPrefix(string(c.Request().Header.ContentType()), fiber.MIMEApplicationJSON) {
+		return location, nil
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(c.Body(), &body); err != nil {
+		return location, nil // Left to the body parser to report
+	}
+
+	changed := false
+	for _, field := range fields {
+		var value string
+		if json.Unmarshal(body[field], &value) != nil || value == "" {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, value); err == nil {
+			continue
+		}
+
+		parsed, ok := parseNaive(value, location)
+		if !ok {
+			return nil, apierror.BadRequest(apierror.CodeValidationFailed,
+				field+" must be RFC3339, e.g. \"2024-07-05T17:00:00+05:30\", or a local \"2024-07-05T17:00\"").
+				WithDetails(fiber.Map{"field": field})
+		}
+		body[field], _ = json.Marshal(parsed.Format(time.RFC3339))
+		changed = true
+	}
+
+	if changed {
+		rewritten, err := json.Marshal(body)
+		if err != nil {
+			return nil, apierror.Internal(apierror.CodeInternal, "Could not read request body")
+		}
+		c.Request().SetBody(rewritten)
+	}
+	return location, nil
+}
+
+// parseNaive parses a date without a UTC offset in location.
+func parseNaive(value string, location *time.Location) (time.Time, bool) {
+	for _, layout := range naiveLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, location); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
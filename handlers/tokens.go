@@ -0,0 +1,107 @@
+// tokens.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+	"github.com/bkojha74/task-management/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Lifetime of API tokens
+const (
+	defaultAPITokenExpiry = 90 * 24 * time.Hour
+	maxAPITokenExpiry     = 365 * 24 * time.Hour
+)
+
+// apiTokenRequest is the body of CreateAPIToken.
+type apiTokenRequest struct {
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	ExpiresIn string   `json:"expires_in"` // Go duration, e.g. "720h"; 2160h by default
+}
+
+// apiToken is the response of CreateAPIToken. The token is only shown once.
+type apiToken struct {
+	models.Session
+	Token string `json:"token"`
+}
+
+// CreateAPIToken creates a token of the logged-in user limited to scopes, e.g. a read-only token for a CI
+// bot. The token is listed and revoked like a session; it cannot manage the account.
+//
+// Parameters:
+// - signing: The secret the token is signed with.
+//
+// Returns:
+// - fiber.Handler: The handler creating API tokens.
+func CreateAPIToken(signing *utils.SigningSecret) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userIdHex, _ := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+
+		var request apiTokenRequest
+		if err := c.BodyParser(&request); err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+		}
+		request.Name = strings.TrimSpace(request.Name)
+		if request.Name == "" || len(request.Name) > 100 {
+			return apierror.BadRequest(apierror.CodeValidationFailed, "name is required and must be at most 100 characters")
+		}
+		if len(request.Scopes) == 0 {
+			return apierror.BadRequest(apierror.CodeValidationFailed, "scopes must list tasks:read, tasks:write or admin")
+		}
+		for _, scope := range request.Scopes {
+			if !utils.KnownScope(scope) {
+				return apierror.BadRequest(apierror.CodeValidationFailed, "scopes must list tasks:read, tasks:write or admin")
+			}
+			if scope == utils.ScopeAdmin {
+				user, err := repository.Users.FindByID(c.UserContext(), userIdHex)
+				if err != nil || user.Role != models.RoleAdmin {
+					return apierror.Forbidden(apierror.CodeForbidden, "only admins can create tokens with the admin scope")
+				}
+			}
+		}
+		expiresIn := defaultAPITokenExpiry
+		if request.ExpiresIn != "" {
+			var err error
+			expiresIn, err = time.ParseDuration(request.ExpiresIn)
+			if err != nil || expiresIn <= 0 || expiresIn > maxAPITokenExpiry {
+				return apierror.BadRequest(apierror.CodeValidationFailed, "expires_in must be a positive duration of at most 8760h, e.g. \"720h\"")
+			}
+		}
+
+		now := time.Now()
+		expiry := now.Add(expiresIn)
+		session := models.Session{
+			UserID:    userIdHex,
+			Device:    c.Get(fiber.HeaderUserAgent),
+			IP:        c.IP(),
+			IssuedAt:  models.NewTimestamp(now),
+			ExpiresAt: models.NewTimestamp(expiry),
+			Name:      request.Name,
+			Scopes:    request.Scopes,
+		}
+		if err := repository.Sessions.Create(context.Background(), &session); err != nil {
+			return apierror.Internal(apierror.CodeInternal, "could not generate token")
+		}
+
+		claims := utils.Tokens.NewClaims(userIdHex.Hex(), session.ID.Hex(), now, expiry)
+		claims.Scope = strings.Join(request.Scopes, " ")
+		tokenString, err := signing.SignToken(claims)
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "could not generate token")
+		}
+
+		return response.JSON(c, fiber.StatusCreated, apiToken{Session: session, Token: tokenString})
+	}
+}
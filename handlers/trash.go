@@ -0,0 +1,91 @@
+// trash.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/quota"
+	"github.com/bkojha74/task-management/readmodel"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetTrash lists the deleted tasks of the logged-in user that can still be restored, the most recently
+// deleted first.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetTrash(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	trashed, err := repository.Tasks.FindTrashed(c.UserContext(), user.ID)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching trash")
+	}
+	return response.JSON(c, fiber.StatusOK, trashed)
+}
+
+// RestoreTask moves a deleted task of the logged-in user back from the trash, with the comments it kept
+// there, removes its tombstone so that syncing clients no longer see it deleted, and publishes it as
+// created again. It fails with 402 once the task quota is used up.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func RestoreTask(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	taskId, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid task ID")
+	}
+	if err := checkQuota(c.UserContext(), user.ID, quota.ResourceTasks); err != nil {
+		return err
+	}
+
+	var restored *models.Task
+	err = outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		task, err := repository.Tasks.Restore(ctx, user.ID, taskId)
+		if err != nil {
+			return err
+		}
+		restored = task
+		if err := repository.Tombstones.DeleteByTask(ctx, task.ID); err != nil {
+			return err
+		}
+		return outbox.Publish(ctx, events.TaskCreated, task.ID.Hex(), *task)
+	})
+	if err == repository.ErrNotFound {
+		return apierror.NotFound(apierror.CodeNotFound, "Task not found in the trash")
+	}
+	if err == repository.ErrDuplicate {
+		return apierror.Conflict(apierror.CodeConflict, "Task exists already")
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not restore task")
+	}
+
+	cache.InvalidateTask(user.ID.Hex(), restored.ID.Hex())
+	readmodel.SyncOrLog(context.Background(), *restored)
+	return response.JSON(c, fiber.StatusOK, restored)
+}
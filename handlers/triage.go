@@ -0,0 +1,80 @@
+// triage.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/pagination"
+	"github.com/bkojha74/task-management/planning"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultTriageLimit is the number of tasks GetTriage returns without a "limit".
+const defaultTriageLimit = 20
+
+// GetTriage ranks the open tasks of the logged-in user by what to work on next, for a "what should I do
+// next" view: each task is scored on its due date proximity, priority, age and the open tasks waiting on
+// it, see planning.Triage. "weights" overrides the weights of some factors for the request, e.g.
+// "due=5,age=0"; "project_id" and "allotted_to" limit the tasks ranked, and "limit" (default 20, max 200)
+// caps the tasks returned. Archived tasks are left out.
+//
+// Parameters:
+// - defaults: The weights of the factors the request does not override.
+//
+// Returns:
+// - fiber.Handler: The handler.
+func GetTriage(defaults planning.TriageWeights) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userIdHex, err := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Invalid user ID")
+		}
+
+		weights, err := planning.ParseTriageWeights(c.Query("weights"), defaults)
+		if err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidQuery, err.Error())
+		}
+		limit := c.QueryInt("limit", defaultTriageLimit)
+		if limit <= 0 || limit > pagination.MaxLimit {
+			return apierror.BadRequest(apierror.CodeInvalidQuery, "Invalid limit")
+		}
+		var projectId primitive.ObjectID
+		if value := c.Query("project_id"); value != "" {
+			if projectId, err = primitive.ObjectIDFromHex(value); err != nil {
+				return apierror.BadRequest(apierror.CodeInvalidID, "Invalid project ID")
+			}
+		}
+		assignee := c.Query("allotted_to")
+
+		// All open tasks are ranked, so that dependencies outside the project or of other assignees count
+		archived := false
+		tasks, err := repository.Tasks.Find(c.UserContext(), repository.TaskQuery{
+			UserID:        userIdHex,
+			ExcludeStatus: models.TaskStatusDone,
+			Archived:      &archived,
+		})
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "Error fetching tasks")
+		}
+
+		ranked := []planning.TriageEntry{}
+		for _, entry := range planning.Triage(tasks, weights, time.Now()) {
+			if len(ranked) == limit {
+				break
+			}
+			if (!projectId.IsZero() && entry.Task.ProjectID != projectId) || (assignee != "" && entry.Task.AllottedTo != assignee) {
+				continue
+			}
+			ranked = append(ranked, entry)
+		}
+		return response.JSON(c, fiber.StatusOK, fiber.Map{"weights": weights, "tasks": ranked})
+	}
+}
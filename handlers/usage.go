@@ -0,0 +1,51 @@
+// usage.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/quota"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetUsage reports how many tasks and webhooks the logged-in user, and their workspace if they are a member
+// of one, have against their quotas.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetUsage(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	usage, err := quota.ForUser(c.UserContext(), *user)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error computing usage")
+	}
+	return response.JSON(c, fiber.StatusOK, usage)
+}
+
+// checkQuota rejects adding a resource with 402 Payment Required once the quota of the user or their
+// workspace is used up.
+func checkQuota(ctx context.Context, userID primitive.ObjectID, resource string) error {
+	err := quota.Check(ctx, userID, resource)
+	var exceeded *quota.ExceededError
+	if errors.As(err, &exceeded) {
+		return apierror.New(fiber.StatusPaymentRequired, apierror.CodeQuotaExceeded, "Quota exceeded, delete some or upgrade your plan").WithDetails(exceeded)
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error checking quota")
+	}
+	return nil
+}
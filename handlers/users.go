@@ -5,21 +5,43 @@ package handlers
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
 	"time"
 
-	"github.com/bkojha74/task-management/database"
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/auth"
+	"github.com/bkojha74/task-management/events"
 	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+	"github.com/bkojha74/task-management/signup"
 	"github.com/bkojha74/task-management/utils"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v4"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// signupRequest is the body of SignUp: the user, the response token of the CAPTCHA widget and, while
+// registration is invitation-only, the invite code.
+type signupRequest struct {
+	models.User
+	CaptchaToken string `json:"captcha_token"`
+	InviteCode   string `json:"invite_code"`
+}
+
 // SignUp handles user registration. It parses the user information from the request body,
 // checks if the username already exists, hashes the password, and stores the user in the database.
+// Before that the sign-up policy is applied: the email address must not be of a disposable mail
+// provider and, when a CAPTCHA provider is configured, captcha_token must verify with it. While
+// registration is invitation-only, invite_code must be a code issued by an admin that is not used up,
+// expired or revoked; a use of it is only counted when the user is created. When users sign in against a
+// directory, accounts come from there and sign-up is disabled.
 //
 // Parameters:
 // - c: Fiber context, which provides methods to interact with the request and response.
@@ -27,78 +49,395 @@ import (
 // Returns:
 // - error: An error object if an error occurs during the process.
 func SignUp(c *fiber.Ctx) error {
-	var user models.User
-	if err := c.BodyParser(&user); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse JSON"})
+	if auth.Default.Name() != auth.ProviderLocal {
+		return apierror.Forbidden(apierror.CodeForbidden, "sign-up is disabled, accounts come from the directory")
+	}
+
+	var request signupRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "cannot parse JSON")
+	}
+	user := request.User
+	user.Email = strings.TrimSpace(user.Email)
+
+	err := signup.Default.Check(context.Background(), signup.Request{
+		Email:        user.Email,
+		CaptchaToken: request.CaptchaToken,
+		InviteCode:   request.InviteCode,
+		RemoteIP:     c.IP(),
+	})
+	switch {
+	case errors.Is(err, signup.ErrCaptchaUnavailable):
+		return apierror.New(fiber.StatusBadGateway, apierror.CodeBadGateway, "captcha provider could not be reached")
+	case err != nil:
+		return apierror.BadRequest(apierror.CodeValidationFailed, err.Error())
 	}
 
-	var existingUser models.User
-	err := database.UsersCollection.FindOne(context.Background(), bson.M{"username": user.Username}).Decode(&existingUser)
-	if err != nil && err != mongo.ErrNoDocuments {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "internal server error"})
+	_, err = repository.Users.FindByUsername(c.UserContext(), user.Username)
+	if err == nil {
+		return apierror.BadRequest(apierror.CodeAlreadyExists, "username already taken")
 	}
-	if existingUser.Username != "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "username already taken"})
+	if err != repository.ErrNotFound {
+		return apierror.Internal(apierror.CodeInternal, "internal server error")
+	}
+
+	if !validTimezone(user.Timezone) {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "unknown timezone, use an IANA name such as \"Asia/Kolkata\"")
+	}
+
+	var invite *models.InviteCode
+	if signup.Default.InviteOnly() {
+		invite, err = repository.InviteCodes.Redeem(context.Background(), signup.HashInviteCode(request.InviteCode), time.Now())
+		if err == repository.ErrNotFound {
+			return apierror.BadRequest(apierror.CodeValidationFailed, "invite code is invalid, expired or used up")
+		}
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "internal server error")
+		}
 	}
 
 	user.Password = utils.HashPassword(user.Password)
+	user.Role = ""                           // Roles are never granted through public sign-up
+	user.WorkspaceID = primitive.NilObjectID // Workspaces are joined explicitly
 
-	result, err := database.UsersCollection.InsertOne(context.Background(), user)
+	user.ID = primitive.NilObjectID
+	err = outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := repository.Users.Create(ctx, &user); err != nil {
+			return err
+		}
+		registered := user
+		registered.Password = "" // Never publish password hashes
+		return outbox.Publish(ctx, events.UserRegistered, user.ID.Hex(), registered)
+	})
+	if err != nil && invite != nil {
+		_ = repository.InviteCodes.Release(context.Background(), invite.ID) // Give back the use the failed sign-up took
+	}
+	if err == repository.ErrDuplicate {
+		return apierror.BadRequest(apierror.CodeAlreadyExists, "username already taken")
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "could not create user"})
+		return apierror.Internal(apierror.CodeInternal, "could not create user")
 	}
 
-	user.ID = result.InsertedID.(primitive.ObjectID)
-	return c.Status(fiber.StatusCreated).JSON(user)
+	return response.JSON(c, fiber.StatusCreated, user)
+}
+
+// signInRequest is the body of SignIn: the credentials and whether the device should stay signed in for longer.
+type signInRequest struct {
+	models.User
+	RememberMe bool `json:"remember_me"`
 }
 
-// SignIn handles user authentication. It verifies the username and password,
-// generates a JWT token if the credentials are valid, and returns the token in the response.
+// tokenTypeBearer is the token_type of the issued tokens: they are sent in the Authorization header.
+const tokenTypeBearer = "Bearer"
+
+// signInResponse is the response of SignIn and RefreshToken: the access token with its type and expiry, the
+// refresh token when refresh tokens are enabled, and the signed-in user, so that clients need not decode the
+// token or fetch the profile.
+type signInResponse struct {
+	Token            string           `json:"token"`
+	TokenType        string           `json:"token_type"`
+	ExpiresAt        models.Timestamp `json:"expires_at"`
+	ExpiresIn        int              `json:"expires_in"` // Seconds until the token expires
+	RefreshToken     string           `json:"refresh_token,omitempty"`
+	RefreshExpiresAt models.Timestamp `json:"refresh_expires_at,omitempty"`
+	User             signedInUser     `json:"user"`
+}
+
+// signedInUser is the part of the user returned at sign-in, without the password hash and integrations.
+type signedInUser struct {
+	ID          primitive.ObjectID `json:"id"`
+	Username    string             `json:"username"`
+	DisplayName string             `json:"display_name,omitempty"`
+	Email       string             `json:"email,omitempty"`
+	Role        string             `json:"role,omitempty"`
+	WorkspaceID primitive.ObjectID `json:"workspace_id,omitempty"`
+	Timezone    string             `json:"timezone,omitempty"`
+}
+
+// newSignedInUser returns the signed-in part of a user.
+func newSignedInUser(user *models.User) signedInUser {
+	return signedInUser{
+		ID:          user.ID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Email:       user.Email,
+		Role:        user.Role,
+		WorkspaceID: user.WorkspaceID,
+		Timezone:    user.Timezone,
+	}
+}
+
+// SignIn handles user authentication. It verifies the username and password with the auth provider,
+// generates a JWT token if the credentials are valid, and returns the token in the response together
+// with its type and expiry and a summary of the user. Every sign-in starts a session, recorded with the
+// device and IP it came from, whose ID is the token's "sid" claim so that the token can be revoked. When
+// refresh tokens are enabled the response also carries a refresh token of the session, which RefreshToken
+// exchanges for a new access token. Users authenticated by a directory get an account on their first
+// sign-in.
 //
 // Parameters:
-// - jwtSecret: The secret key used to sign the JWT token.
+// - jwtSecret: The secret the JWT token is signed with.
 // - tokenExpiryTime: The token's expiration time in seconds.
 //
 // Returns:
 // - fiber.Handler: A Fiber handler function that performs the sign-in process.
-func SignIn(jwtSecret string, tokenExpiryTime int) fiber.Handler {
+func SignIn(jwtSecret *utils.SigningSecret, tokenExpiryTime int) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var user models.User
-		if err := c.BodyParser(&user); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse JSON"})
+		var request signInRequest
+		if err := c.BodyParser(&request); err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidJSON, "cannot parse JSON")
 		}
+		user := request.User
 
 		if user.Username == "" || user.Password == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "username and password should not be blank!"})
+			return apierror.BadRequest(apierror.CodeValidationFailed, "username and password should not be blank!")
 		}
 
-		var foundUser models.User
-		err := database.UsersCollection.FindOne(context.Background(), bson.M{"username": user.Username}).Decode(&foundUser)
-		if err != nil {
-			if err == mongo.ErrNoDocuments {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid credentials"})
+		identity, err := auth.Default.Authenticate(context.Background(), user.Username, user.Password)
+		switch {
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			return apierror.Unauthorized(apierror.CodeInvalidCredentials, "invalid credentials")
+		case errors.Is(err, auth.ErrUnavailable):
+			return apierror.New(fiber.StatusBadGateway, apierror.CodeBadGateway, "directory could not be reached")
+		case err != nil:
+			return apierror.Internal(apierror.CodeInternal, "internal server error")
+		}
+
+		foundUser := identity.User
+		if foundUser == nil {
+			foundUser, err = directoryUser(identity)
+			if err == errAccountNotLinked {
+				return apierror.Conflict(apierror.CodeConflict, "username belongs to an account that is not linked to the directory")
+			}
+			if err != nil {
+				return apierror.Internal(apierror.CodeInternal, "internal server error")
 			}
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "internal server error"})
+		}
+		if !foundUser.DeactivatedAt.IsZero() {
+			return apierror.Forbidden(apierror.CodeForbidden, "account is deactivated")
 		}
 
-		if !utils.CheckPasswordHash(user.Password, foundUser.Password) {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid credentials"})
+		issued, err := issueToken(c, jwtSecret, foundUser, tokenExpiryTime, request.RememberMe)
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "could not generate token")
 		}
 
-		claims := jwt.MapClaims{
-			"userId": foundUser.ID.Hex(),
-			"exp":    time.Now().Add(time.Second * time.Duration(tokenExpiryTime)).Unix(),
+		return response.JSON(c, fiber.StatusOK, issued)
+	}
+}
+
+// issueToken starts a session of the signing-in user on the requesting device and returns its access token
+// and, when refresh tokens are enabled, its refresh token, longer-lived with rememberMe. The session then
+// lasts as long as the refresh token. The sign-in is recorded in the user's sign-in history.
+func issueToken(c *fiber.Ctx, jwtSecret *utils.SigningSecret, user *models.User, tokenExpiryTime int, rememberMe bool) (*signInResponse, error) {
+	now := time.Now()
+	expiry := now.Add(time.Second * time.Duration(tokenExpiryTime))
+	session := models.Session{
+		ID:        primitive.NewObjectID(),
+		UserID:    user.ID,
+		Device:    c.Get(fiber.HeaderUserAgent),
+		IP:        c.IP(),
+		IssuedAt:  models.NewTimestamp(now),
+		ExpiresAt: models.NewTimestamp(expiry),
+	}
+	var refreshToken string
+	if utils.Tokens.RefreshLifetime(rememberMe) > 0 {
+		var err error
+		if refreshToken, err = newRefreshToken(session.ID); err != nil {
+			return nil, err
 		}
+		session.RefreshTokenHash = hashRefreshToken(refreshToken)
+		session.RememberMe = rememberMe
+		if refreshExpiry := utils.Tokens.SessionExpiry(now, rememberMe, now); refreshExpiry.After(expiry) {
+			session.ExpiresAt = models.NewTimestamp(refreshExpiry)
+		}
+	}
+	if err := repository.Sessions.Create(context.Background(), &session); err != nil {
+		return nil, err
+	}
+	recordSignIn(user, &session)
 
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return signSession(jwtSecret, user, &session, refreshToken, now, expiry)
+}
 
-		tokenString, err := token.SignedString([]byte(jwtSecret))
+// signSession signs an access token of a session expiring at expiry, or at the end of the session if that
+// is earlier, and returns it with the refresh token of the session, if any.
+func signSession(jwtSecret *utils.SigningSecret, user *models.User, session *models.Session, refreshToken string, now, expiry time.Time) (*signInResponse, error) {
+	if sessionEnd := session.ExpiresAt.Time(); sessionEnd.Before(expiry) {
+		expiry = sessionEnd
+	}
+	claims := utils.Tokens.NewClaims(user.ID.Hex(), session.ID.Hex(), now, expiry)
+	tokenString, err := jwtSecret.SignToken(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	issued := &signInResponse{
+		Token:        tokenString,
+		TokenType:    tokenTypeBearer,
+		ExpiresAt:    models.NewTimestamp(expiry),
+		ExpiresIn:    int(expiry.Sub(now).Round(time.Second) / time.Second),
+		RefreshToken: refreshToken,
+		User:         newSignedInUser(user),
+	}
+	if refreshToken != "" {
+		issued.RefreshExpiresAt = session.ExpiresAt
+	}
+	return issued, nil
+}
+
+// newRefreshToken returns a random refresh token of a session, URL-safe. It starts with the session ID so
+// that the session can be found by its token.
+func newRefreshToken(sessionID primitive.ObjectID) (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return sessionID.Hex() + "." + base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// hashRefreshToken returns the SHA-256 hash of a refresh token in hex, as stored.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// refreshRequest is the body of RefreshToken.
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshToken exchanges a refresh token for a new access token of its session. The refresh token is
+// rotated: the response carries a new one and the one sent cannot be used again. In sliding mode every
+// refresh also extends the session, up to its maximum lifetime. Sending a refresh token
+// that was already rotated means it was copied, so the session is revoked, signing out both the thief and
+// the user.
+//
+// Parameters:
+// - jwtSecret: The secret the JWT token is signed with.
+// - tokenExpiryTime: The token's expiration time in seconds.
+//
+// Returns:
+// - fiber.Handler: A Fiber handler function that refreshes tokens.
+func RefreshToken(jwtSecret *utils.SigningSecret, tokenExpiryTime int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var request refreshRequest
+		if err := c.BodyParser(&request); err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidJSON, "cannot parse JSON")
+		}
+		sessionHex, _, found := strings.Cut(request.RefreshToken, ".")
+		sessionId, err := primitive.ObjectIDFromHex(sessionHex)
+		if !found || err != nil {
+			return apierror.Unauthorized(apierror.CodeInvalidToken, "invalid refresh token")
+		}
+
+		now := time.Now()
+		tokenHash := hashRefreshToken(request.RefreshToken)
+		refreshToken, err := newRefreshToken(sessionId)
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "could not generate token")
+		}
+		current, err := repository.Sessions.FindByID(context.Background(), sessionId)
+		if err == repository.ErrNotFound {
+			return apierror.Unauthorized(apierror.CodeInvalidToken, "invalid refresh token")
+		}
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "internal server error")
+		}
+		expiresAt := utils.Tokens.SessionExpiry(current.IssuedAt.Time(), current.RememberMe, now)
+		session, err := repository.Sessions.Refresh(context.Background(), sessionId, tokenHash, hashRefreshToken(refreshToken), expiresAt, now)
+		if err == repository.ErrNotFound {
+			revokeReusedRefreshToken(sessionId, tokenHash, now)
+			return apierror.Unauthorized(apierror.CodeInvalidToken, "invalid refresh token")
+		}
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "could not generate token"})
+			return apierror.Internal(apierror.CodeInternal, "internal server error")
 		}
 
-		return c.JSON(fiber.Map{"token": tokenString})
+		user, err := repository.Users.FindByID(c.UserContext(), session.UserID)
+		if err == repository.ErrNotFound {
+			return apierror.Unauthorized(apierror.CodeInvalidToken, "invalid refresh token")
+		}
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "internal server error")
+		}
+		if !user.DeactivatedAt.IsZero() {
+			return apierror.Forbidden(apierror.CodeForbidden, "account is deactivated")
+		}
+
+		issued, err := signSession(jwtSecret, user, session, refreshToken, now, now.Add(time.Second*time.Duration(tokenExpiryTime)))
+		if err != nil {
+			return apierror.Internal(apierror.CodeInternal, "could not generate token")
+		}
+		return response.JSON(c, fiber.StatusOK, issued)
+	}
+}
+
+// revokeReusedRefreshToken revokes a session if the refresh token with tokenHash is the one it rotated
+// away from, i.e. a copy of the token was used before.
+func revokeReusedRefreshToken(sessionId primitive.ObjectID, tokenHash string, now time.Time) {
+	session, err := repository.Sessions.FindByID(context.Background(), sessionId)
+	if err != nil || session.PreviousRefreshHash == "" || session.PreviousRefreshHash != tokenHash {
+		return
+	}
+	_ = repository.Sessions.Revoke(context.Background(), session.UserID, session.ID, now)
+}
+
+// errAccountNotLinked is returned by directoryUser when the username of a directory user is taken by an
+// account that is not linked to them.
+var errAccountNotLinked = errors.New("account is not linked to the directory user")
+
+// directoryUser returns the account of a user authenticated by a directory, creating it on their first
+// sign-in without a password, which the directory checks. Accounts are found by the directory subject they
+// are linked to, never by username alone, so a directory user named like a local account cannot take it
+// over: an account of the same username is only linked if it has no password, i.e. it was provisioned or
+// created by a directory before, and else errAccountNotLinked is returned until an administrator links
+// it with taskctl user link.
+func directoryUser(identity auth.Identity) (*models.User, error) {
+	ctx := context.Background()
+	if identity.Subject == "" {
+		return nil, errors.New("directory identity has no subject")
+	}
+	user, err := repository.Users.FindBySubject(ctx, identity.Subject)
+	if err != repository.ErrNotFound {
+		return user, err
+	}
+	user, err = repository.Users.FindByUsername(ctx, identity.Username)
+	switch {
+	case err == repository.ErrNotFound:
+	case err != nil:
+		return nil, err
+	case user.Password != "" || user.Subject != "":
+		return nil, errAccountNotLinked
+	default:
+		user.Subject = identity.Subject
+		if err := repository.Users.Update(ctx, user); err == repository.ErrDuplicate {
+			return repository.Users.FindBySubject(ctx, identity.Subject) // Linked by a concurrent sign-in
+		} else if err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	created := models.User{Username: identity.Username, Email: identity.Email, Subject: identity.Subject}
+	err = outbox.Transaction(ctx, func(ctx context.Context) error {
+		if err := repository.Users.Create(ctx, &created); err != nil {
+			return err
+		}
+		return outbox.Publish(ctx, events.UserRegistered, created.ID.Hex(), created)
+	})
+	if err == repository.ErrDuplicate {
+		user, err := repository.Users.FindBySubject(ctx, identity.Subject) // Created by a concurrent sign-in
+		if err == repository.ErrNotFound {
+			return nil, errAccountNotLinked // Or the username was taken meanwhile
+		}
+		return user, err
+	}
+	if err != nil {
+		return nil, err
 	}
+	return &created, nil
 }
 
 // SignOut handles user sign-out. It returns a simple success message.
@@ -109,5 +448,5 @@ func SignIn(jwtSecret string, tokenExpiryTime int) fiber.Handler {
 // Returns:
 // - error: An error object if an error occurs during the process.
 func SignOut(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "signed out"})
+	return response.JSON(c, fiber.StatusOK, fiber.Map{"message": "signed out"})
 }
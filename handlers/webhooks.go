@@ -0,0 +1,201 @@
+// webhooks.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/quota"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+	"github.com/bkojha74/task-management/webhooks"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Limits of webhook deliveries
+const (
+	maxWebhookDeliveries = 100
+	redeliveryTimeout    = 10 * time.Second
+)
+
+// webhookRequest is the body of CreateWebhook.
+type webhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"` // Event types to deliver; all of them when empty
+}
+
+// createdWebhook is the response of CreateWebhook: the stored webhook and, this one time, its secret.
+type createdWebhook struct {
+	models.Webhook
+	Secret string `json:"secret"`
+}
+
+// CreateWebhook registers a webhook receiving domain events. The secret its requests are signed with is
+// returned once.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func CreateWebhook(c *fiber.Ctx) error {
+	adminId, err := primitive.ObjectIDFromHex(c.Locals("userId").(string))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid user ID")
+	}
+
+	var request webhookRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	target, err := url.Parse(request.URL)
+	if err != nil || (target.Scheme != "https" && target.Scheme != "http") || target.Host == "" {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "url must be an absolute http or https URL")
+	}
+	for _, event := range request.Events {
+		if !containsString(events.Types, event) {
+			return apierror.BadRequest(apierror.CodeValidationFailed, "Unknown event").WithDetails(fiber.Map{"event": event, "allowed": events.Types})
+		}
+	}
+
+	if err := checkQuota(c.UserContext(), adminId, quota.ResourceWebhooks); err != nil {
+		return err
+	}
+
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not create webhook")
+	}
+	webhook := models.Webhook{
+		URL:       request.URL,
+		Secret:    secret,
+		Events:    request.Events,
+		CreatedBy: adminId,
+		CreatedAt: models.NewTimestamp(time.Now()),
+	}
+	if err := repository.Webhooks.Create(context.Background(), &webhook); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not create webhook")
+	}
+
+	return response.JSON(c, fiber.StatusCreated, createdWebhook{Webhook: webhook, Secret: secret})
+}
+
+// GetWebhooks lists the webhooks, oldest first.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetWebhooks(c *fiber.Ctx) error {
+	hooks, err := repository.Webhooks.Find(c.UserContext())
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching webhooks")
+	}
+	return response.JSON(c, fiber.StatusOK, hooks)
+}
+
+// DeleteWebhook deletes a webhook with its deliveries. Deliveries in progress are abandoned.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func DeleteWebhook(c *fiber.Ctx) error {
+	webhookId, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid webhook ID")
+	}
+
+	err = repository.Webhooks.Delete(context.Background(), webhookId)
+	if err == repository.ErrNotFound {
+		return apierror.NotFound(apierror.CodeNotFound, "Webhook not found")
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not delete webhook")
+	}
+	if _, err := repository.WebhookDeliveries.DeleteMany(context.Background(), webhookId); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not delete webhook")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetWebhookDeliveries lists the latest 100 deliveries to a webhook, newest first, with the response code
+// of every attempt.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetWebhookDeliveries(c *fiber.Ctx) error {
+	webhook, err := findWebhook(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	deliveries, err := repository.WebhookDeliveries.Find(c.UserContext(), webhook.ID, maxWebhookDeliveries)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching webhook deliveries")
+	}
+	return response.JSON(c, fiber.StatusOK, deliveries)
+}
+
+// RedeliverWebhook sends a delivery once more, with the same body and delivery ID, and returns it with the
+// new attempt. A failed attempt is recorded rather than returned as an error.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func RedeliverWebhook(c *fiber.Ctx) error {
+	webhook, err := findWebhook(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	deliveryId, err := primitive.ObjectIDFromHex(c.Params("deliveryId"))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid delivery ID")
+	}
+	delivery, err := repository.WebhookDeliveries.FindByID(c.UserContext(), webhook.ID, deliveryId)
+	if err == repository.ErrNotFound {
+		return apierror.NotFound(apierror.CodeNotFound, "Delivery not found")
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching webhook deliveries")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redeliveryTimeout)
+	defer cancel()
+	if err := webhooks.Redeliver(ctx, *webhook, delivery); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not record the redelivery")
+	}
+	return response.JSON(c, fiber.StatusOK, delivery)
+}
+
+// findWebhook returns the webhook with the given hex ID, or an API error.
+func findWebhook(ctx context.Context, id string) (*models.Webhook, error) {
+	webhookId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, apierror.BadRequest(apierror.CodeInvalidID, "Invalid webhook ID")
+	}
+	webhook, err := repository.Webhooks.FindByID(ctx, webhookId)
+	if err == repository.ErrNotFound {
+		return nil, apierror.NotFound(apierror.CodeNotFound, "Webhook not found")
+	}
+	if err != nil {
+		return nil, apierror.Internal(apierror.CodeInternal, "Error fetching webhooks")
+	}
+	return webhook, nil
+}
@@ -0,0 +1,312 @@
+// workspaces.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/branding"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CreateWorkspace creates a workspace owned by the logged-in user and makes the user a member of it.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func CreateWorkspace(c *fiber.Ctx) error {
+	userId := c.Locals("userId").(string)
+	userIdHex, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Invalid user ID")
+	}
+
+	var workspace models.Workspace
+	if err := c.BodyParser(&workspace); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	if workspace.Name == "" {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Workspace name is required")
+	}
+	if err := branding.Validate(workspace.Branding); err != nil {
+		return apierror.BadRequest(apierror.CodeValidationFailed, err.Error())
+	}
+
+	workspace.ID = primitive.NilObjectID
+	workspace.OwnerID = userIdHex
+	workspace.Invited = nil
+
+	err = repository.Workspaces.Create(context.Background(), &workspace)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not create workspace")
+	}
+
+	user, err := repository.Users.FindByID(c.UserContext(), userIdHex)
+	if err == nil {
+		user.WorkspaceID = workspace.ID
+		err = repository.Users.Update(context.Background(), user)
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not join workspace")
+	}
+
+	return response.JSON(c, fiber.StatusCreated, workspace)
+}
+
+// GetWorkspace returns a workspace the logged-in user owns or belongs to.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetWorkspace(c *fiber.Ctx) error {
+	workspace, err := findMemberWorkspace(c)
+	if err != nil {
+		return err
+	}
+
+	return response.JSON(c, fiber.StatusOK, workspace)
+}
+
+// UpdateWorkspaceBranding replaces the logo, accent color and sender name of a workspace.
+// Only the workspace owner may change branding.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func UpdateWorkspaceBranding(c *fiber.Ctx) error {
+	workspace, err := findMemberWorkspace(c)
+	if err != nil {
+		return err
+	}
+	if workspace.OwnerID.Hex() != c.Locals("userId").(string) {
+		return apierror.Forbidden(apierror.CodeForbidden, "Only the workspace owner can change branding")
+	}
+
+	var brand models.Branding
+	if err := c.BodyParser(&brand); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	if err := branding.Validate(brand); err != nil {
+		return apierror.BadRequest(apierror.CodeValidationFailed, err.Error())
+	}
+
+	err = repository.Workspaces.UpdateBranding(context.Background(), workspace.ID, brand)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not update branding")
+	}
+
+	workspace.Branding = brand
+	return response.JSON(c, fiber.StatusOK, workspace)
+}
+
+// workspaceInvitation is the body of InviteToWorkspace.
+type workspaceInvitation struct {
+	Username string `json:"username"`
+}
+
+// workspaceMembers is the response of GetWorkspaceMembers. Only the owner sees the invited users.
+type workspaceMembers struct {
+	Members []models.UserSummary `json:"members"`
+	Invited []models.UserSummary `json:"invited,omitempty"`
+}
+
+// InviteToWorkspace invites an existing user, by username, to join a workspace. Only the workspace owner
+// may invite. The invitation stays until the user joins; inviting a user twice is not an error.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func InviteToWorkspace(c *fiber.Ctx) error {
+	workspace, err := findMemberWorkspace(c)
+	if err != nil {
+		return err
+	}
+	if workspace.OwnerID.Hex() != c.Locals("userId").(string) {
+		return apierror.Forbidden(apierror.CodeForbidden, "Only the workspace owner can invite members")
+	}
+
+	var invitation workspaceInvitation
+	if err := c.BodyParser(&invitation); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidJSON, "Cannot parse JSON")
+	}
+	username := strings.TrimSpace(invitation.Username)
+	if username == "" {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "Username is required")
+	}
+
+	invitee, err := repository.Users.FindByUsername(c.UserContext(), username)
+	if err == repository.ErrNotFound {
+		return apierror.NotFound(apierror.CodeNotFound, "User not found")
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching user")
+	}
+	if invitee.WorkspaceID == workspace.ID {
+		return apierror.Conflict(apierror.CodeConflict, "User is a member already")
+	}
+
+	if err := repository.Workspaces.Invite(context.Background(), workspace.ID, invitee.ID); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not invite user")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// JoinWorkspace makes the logged-in user a member of a workspace they were invited to, using up the
+// invitation. Users belong to one workspace at a time and must leave theirs first.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func JoinWorkspace(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	workspaceId, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidID, "Invalid workspace ID")
+	}
+	if user.WorkspaceID == workspaceId {
+		return apierror.Conflict(apierror.CodeConflict, "You are a member already")
+	}
+	if !user.WorkspaceID.IsZero() {
+		return apierror.Conflict(apierror.CodeConflict, "Leave your workspace before joining another")
+	}
+
+	err = outbox.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := repository.Workspaces.Uninvite(ctx, workspaceId, user.ID); err != nil {
+			return err
+		}
+		user.WorkspaceID = workspaceId
+		return repository.Users.Update(ctx, user)
+	})
+	if err == repository.ErrNotFound {
+		// Don't reveal workspaces the user is not invited to
+		return apierror.NotFound(apierror.CodeNotFound, "Workspace not found")
+	}
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not join workspace")
+	}
+
+	workspace, err := repository.Workspaces.FindByID(c.UserContext(), workspaceId)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching workspace")
+	}
+	return response.JSON(c, fiber.StatusOK, workspace)
+}
+
+// LeaveWorkspace removes the logged-in user from a workspace. The owner cannot leave the workspace.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func LeaveWorkspace(c *fiber.Ctx) error {
+	workspace, err := findMemberWorkspace(c)
+	if err != nil {
+		return err
+	}
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	if workspace.OwnerID == user.ID {
+		return apierror.Conflict(apierror.CodeConflict, "The owner cannot leave the workspace")
+	}
+
+	user.WorkspaceID = primitive.NilObjectID
+	if err := repository.Users.Update(context.Background(), user); err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Could not leave workspace")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetWorkspaceMembers lists the members of a workspace the logged-in user owns or belongs to and, for the
+// owner, the users invited who have not joined yet.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func GetWorkspaceMembers(c *fiber.Ctx) error {
+	workspace, err := findMemberWorkspace(c)
+	if err != nil {
+		return err
+	}
+
+	users, err := repository.Users.FindByWorkspace(c.UserContext(), workspace.ID)
+	if err != nil {
+		return apierror.Internal(apierror.CodeInternal, "Error fetching members")
+	}
+	members := workspaceMembers{Members: make([]models.UserSummary, 0, len(users))}
+	for _, user := range users {
+		members.Members = append(members.Members, models.UserSummary{ID: user.ID, Username: user.Username, DisplayName: user.DisplayName})
+	}
+
+	if workspace.OwnerID.Hex() == c.Locals("userId").(string) {
+		for _, id := range workspace.Invited {
+			invitee, err := repository.Users.FindByID(c.UserContext(), id)
+			if err == repository.ErrNotFound {
+				continue // Deleted since the invitation
+			}
+			if err != nil {
+				return apierror.Internal(apierror.CodeInternal, "Error fetching members")
+			}
+			members.Invited = append(members.Invited, models.UserSummary{ID: invitee.ID, Username: invitee.Username, DisplayName: invitee.DisplayName})
+		}
+	}
+	return response.JSON(c, fiber.StatusOK, members)
+}
+
+// findMemberWorkspace loads the workspace from the ":id" route parameter and checks that the
+// logged-in user owns it or is a member.
+func findMemberWorkspace(c *fiber.Ctx) (*models.Workspace, error) {
+	userId := c.Locals("userId").(string)
+	workspaceId, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return nil, apierror.BadRequest(apierror.CodeInvalidID, "Invalid workspace ID")
+	}
+
+	workspace, err := repository.Workspaces.FindByID(c.UserContext(), workspaceId)
+	if err == repository.ErrNotFound {
+		return nil, apierror.NotFound(apierror.CodeNotFound, "Workspace not found")
+	}
+	if err != nil {
+		return nil, apierror.Internal(apierror.CodeInternal, "Error fetching workspace")
+	}
+
+	if workspace.OwnerID.Hex() != userId {
+		userIdHex, _ := primitive.ObjectIDFromHex(userId)
+		user, err := repository.Users.FindByID(c.UserContext(), userIdHex)
+		if err != nil && err != repository.ErrNotFound {
+			return nil, apierror.Internal(apierror.CodeInternal, "Error fetching workspace")
+		}
+		if user == nil || user.WorkspaceID != workspaceId {
+			// Don't reveal workspaces the user is not part of
+			return nil, apierror.NotFound(apierror.CodeNotFound, "Workspace not found")
+		}
+	}
+
+	return workspace, nil
+}
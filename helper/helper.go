@@ -5,6 +5,7 @@ package helper
 
 import (
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
@@ -34,3 +35,32 @@ func LoadEnv(currentConfigDirectory string) {
 func GetEnv(key string) string {
 	return os.Getenv(key)
 }
+
+// GetEnvInt retrieves the value of the environment variable named by the key and converts it to an integer.
+// If the key is not found or the value cannot be converted, the provided default value is returned.
+//
+// Parameters:
+// - key: The name of the environment variable to retrieve.
+// - defaultValue: The value returned when the variable is missing or invalid.
+//
+// Returns:
+// - int: The integer value of the environment variable, or the default value.
+func GetEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// ReadEnv reads the variables of the .env file located in the specified directory without setting them.
+//
+// Parameters:
+// - currentConfigDirectory: The directory where the .env file is located.
+//
+// Returns:
+// - map[string]string: The variables of the file.
+// - error: An error if the file could not be read or parsed.
+func ReadEnv(currentConfigDirectory string) (map[string]string, error) {
+	return godotenv.Read(currentConfigDirectory + "/.env")
+}
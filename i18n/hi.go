@@ -0,0 +1,300 @@
+// hi.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package i18n
+
+// hindi is the Hindi catalog of the API's error messages.
+var hindi = Catalog{
+	// Requests
+	"Cannot parse JSON":                                 "JSON पढ़ा नहीं जा सका",
+	"cannot parse JSON":                                 "JSON पढ़ा नहीं जा सका",
+	"Could not read request body":                       "अनुरोध का मुख्य भाग पढ़ा नहीं जा सका",
+	"Content-Type must be application/json":             "Content-Type application/json होना चाहिए",
+	"Invalid sort field":                                "अमान्य क्रम फ़ील्ड",
+	"Invalid limit":                                     "अमान्य सीमा",
+	"Invalid offset":                                    "अमान्य ऑफ़सेट",
+	"q must contain at least one word":                  "q में कम से कम एक शब्द होना चाहिए",
+	"type must be task or project":                      "type task या project होना चाहिए",
+	"Could not search":                                  "खोज नहीं की जा सकी",
+	"Invalid cursor":                                    "अमान्य कर्सर",
+	"Invalid If-Match header":                           "अमान्य If-Match हेडर",
+	"Invalid overdue flag, use true or false":           "अमान्य overdue मान, true या false का उपयोग करें",
+	"Invalid from date, use YYYY-MM-DD":                 "अमान्य from तिथि, YYYY-MM-DD का उपयोग करें",
+	"Invalid to date, use YYYY-MM-DD":                   "अमान्य to तिथि, YYYY-MM-DD का उपयोग करें",
+	"Unknown field in fields":                           "fields में अज्ञात फ़ील्ड",
+	"Could not select fields":                           "फ़ील्ड चुने नहीं जा सके",
+	"too many requests":                                 "बहुत अधिक अनुरोध, कृपया थोड़ी देर बाद पुनः प्रयास करें",
+	"the service is under maintenance, try again later": "सेवा रखरखाव में है, कृपया बाद में पुनः प्रयास करें",
+	"internal server error":                             "आंतरिक सर्वर त्रुटि",
+
+	// Authentication
+	"unauthorized":                               "अनधिकृत",
+	"invalid credentials":                        "अमान्य उपयोगकर्ता नाम या पासवर्ड",
+	"invalid JWT":                                "अमान्य JWT",
+	"missing or malformed JWT":                   "JWT अनुपस्थित या विकृत है",
+	"session revoked":                            "सत्र रद्द कर दिया गया है",
+	"invitation revoked":                         "आमंत्रण रद्द कर दिया गया है",
+	"admin access required":                      "व्यवस्थापक पहुँच आवश्यक है",
+	"could not generate token":                   "टोकन नहीं बनाया जा सका",
+	"username already taken":                     "यह उपयोगकर्ता नाम पहले से लिया जा चुका है",
+	"email is required":                          "email आवश्यक है",
+	"email is not a valid address":               "email एक मान्य पता नहीं है",
+	"email domain is not allowed":                "इस email डोमेन की अनुमति नहीं है",
+	"captcha token is required":                  "captcha टोकन आवश्यक है",
+	"captcha verification failed":                "captcha सत्यापन विफल रहा",
+	"captcha provider could not be reached":      "captcha प्रदाता से संपर्क नहीं हो सका",
+	"username and password should not be blank!": "उपयोगकर्ता नाम और पासवर्ड खाली नहीं होने चाहिए!",
+	"could not create user":                      "उपयोगकर्ता नहीं बनाया जा सका",
+	"unknown timezone, use an IANA name such as \"Asia/Kolkata\"": "अज्ञात समय क्षेत्र, \"Asia/Kolkata\" जैसे IANA नाम का उपयोग करें",
+
+	// Users
+	"Invalid user ID":                        "अमान्य उपयोगकर्ता ID",
+	"User not found":                         "उपयोगकर्ता नहीं मिला",
+	"Error fetching user":                    "उपयोगकर्ता प्राप्त करने में त्रुटि",
+	"Could not update notification settings": "सूचना सेटिंग्स अपडेट नहीं की जा सकीं",
+	"Unknown timezone, use an IANA name such as \"Asia/Kolkata\"": "अज्ञात समय क्षेत्र, \"Asia/Kolkata\" जैसे IANA नाम का उपयोग करें",
+	"Could not update timezone":                                   "समय क्षेत्र अपडेट नहीं किया जा सका",
+	"frequency must be off, daily or weekly":                      "frequency off, daily या weekly होनी चाहिए",
+	"hour must be between 0 and 23":                               "hour 0 और 23 के बीच होना चाहिए",
+	"weekday must be between 0 (Sunday) and 6 (Saturday)":         "weekday 0 (रविवार) और 6 (शनिवार) के बीच होना चाहिए",
+	"An email address is needed to receive digests":               "डाइजेस्ट पाने के लिए email पता आवश्यक है",
+	"Could not update digest settings":                            "डाइजेस्ट सेटिंग्स अपडेट नहीं की जा सकीं",
+	"Could not schedule account deletion":                         "खाता हटाना निर्धारित नहीं किया जा सका",
+	"Could not cancel account deletion":                           "खाता हटाना रद्द नहीं किया जा सका",
+	"Invalid session ID":                                          "अमान्य सत्र ID",
+	"Session not found":                                           "सत्र नहीं मिला",
+	"Error fetching sessions":                                     "सत्र प्राप्त करने में त्रुटि",
+	"Could not revoke session":                                    "सत्र रद्द नहीं किया जा सका",
+
+	// Tasks
+	"Invalid task ID":                                                      "अमान्य कार्य ID",
+	"Task not found":                                                       "कार्य नहीं मिला",
+	"Error fetching task":                                                  "कार्य प्राप्त करने में त्रुटि",
+	"Error fetching tasks":                                                 "कार्य प्राप्त करने में त्रुटि",
+	"Error building cursor":                                                "कर्सर बनाने में त्रुटि",
+	"Error checking allotted user":                                         "आवंटित उपयोगकर्ता की जाँच में त्रुटि",
+	"Allotted user does not exist":                                         "आवंटित उपयोगकर्ता मौजूद नहीं है",
+	"Priority must be low, medium or high":                                 "प्राथमिकता low, medium या high होनी चाहिए",
+	"Visibility must be private, team or org":                              "दृश्यता private, team या org होनी चाहिए",
+	"Only the owner of a task can change it":                               "केवल कार्य का स्वामी इसे बदल सकता है",
+	"Estimate must not be negative":                                        "अनुमान ऋणात्मक नहीं होना चाहिए",
+	"A task can depend on at most 50 tasks":                                "एक कार्य अधिकतम 50 कार्यों पर निर्भर हो सकता है",
+	"Dependencies must be distinct tasks other than the task itself":       "निर्भरताएँ स्वयं कार्य के अलावा अलग-अलग कार्य होनी चाहिए",
+	"Dependencies must be tasks of the same project":                       "निर्भरताएँ उसी प्रोजेक्ट के कार्य होनी चाहिए",
+	"Dependencies must not form a cycle":                                   "निर्भरताएँ चक्र नहीं बनानी चाहिए",
+	"Could not create task":                                                "कार्य नहीं बनाया जा सका",
+	"Could not update task":                                                "कार्य अपडेट नहीं किया जा सका",
+	"Could not delete task":                                                "कार्य हटाया नहीं जा सका",
+	"Task was modified by someone else, reload it and retry":               "कार्य किसी और ने बदल दिया है, इसे फिर से लोड करें और पुनः प्रयास करें",
+	"version must be a number":                                             "version एक संख्या होनी चाहिए",
+	"Patch must change at least one field":                                 "पैच को कम से कम एक फ़ील्ड बदलना चाहिए",
+	"status must be Pending, In Progress or Done":                          "status Pending, In Progress या Done होना चाहिए",
+	"Task already has this status":                                         "कार्य की स्थिति पहले से यही है",
+	"The workflow does not allow this status change":                       "वर्कफ़्लो इस स्थिति परिवर्तन की अनुमति नहीं देता",
+	"Field cannot be patched":                                              "यह फ़ील्ड पैच नहीं की जा सकती",
+	"Could not rebuild task list view":                                     "कार्य सूची दृश्य फिर से नहीं बनाया जा सका",
+	"ids must list between 1 and 100 task IDs":                             "ids में 1 से 100 कार्य ID होने चाहिए",
+	"Done tasks cannot be snoozed":                                         "पूर्ण कार्यों को स्नूज़ नहीं किया जा सकता",
+	"Could not snooze task":                                                "कार्य स्नूज़ नहीं किया जा सका",
+	"allotted_to is required":                                              "allotted_to आवश्यक है",
+	"note must be at most 2000 characters":                                 "नोट अधिकतम 2000 अक्षरों का होना चाहिए",
+	"Task is already allotted to this user":                                "कार्य पहले से इसी उपयोगकर्ता को आवंटित है",
+	"Could not reassign task":                                              "कार्य पुनः आवंटित नहीं किया जा सका",
+	"body is required":                                                     "body आवश्यक है",
+	"body must be at most 10000 characters":                                "body अधिकतम 10000 अक्षरों का हो सकता है",
+	"Error checking mentioned users":                                       "उल्लेखित उपयोगकर्ताओं की जाँच करने में त्रुटि",
+	"Could not add comment":                                                "टिप्पणी जोड़ी नहीं जा सकी",
+	"Error fetching comments":                                              "टिप्पणियाँ प्राप्त करने में त्रुटि",
+	"duration must be a positive duration of at most 8760h, e.g. \"24h\"":  "duration अधिकतम 8760h की धनात्मक अवधि होनी चाहिए, जैसे \"24h\"",
+	"expires_in must be a positive duration of at most 720h, e.g. \"48h\"": "expires_in अधिकतम 720h की धनात्मक अवधि होनी चाहिए, जैसे \"48h\"",
+	"Could not create share link":                                          "साझा लिंक नहीं बनाया जा सका",
+	"Shared task not found or link expired":                                "साझा कार्य नहीं मिला या लिंक की समय-सीमा समाप्त हो गई",
+
+	// Projects and boards
+	"Invalid project ID":                             "अमान्य प्रोजेक्ट ID",
+	"Project not found":                              "प्रोजेक्ट नहीं मिला",
+	"Project does not exist":                         "प्रोजेक्ट मौजूद नहीं है",
+	"Task dependencies form a cycle":                 "कार्य निर्भरताएँ एक चक्र बनाती हैं",
+	"Could not schedule tasks":                       "कार्यों की समय-सारणी नहीं बनाई जा सकी",
+	"Project name is required":                       "प्रोजेक्ट का नाम आवश्यक है",
+	"Project end_date must not be before start_date": "प्रोजेक्ट की end_date, start_date से पहले नहीं होनी चाहिए",
+	"Error fetching project":                         "प्रोजेक्ट प्राप्त करने में त्रुटि",
+	"Error fetching projects":                        "प्रोजेक्ट प्राप्त करने में त्रुटि",
+	"Could not create project":                       "प्रोजेक्ट नहीं बनाया जा सका",
+	"The date range must be at most 366 days and not end before it starts":       "तिथि सीमा अधिकतम 366 दिन की होनी चाहिए और शुरू होने से पहले समाप्त नहीं होनी चाहिए",
+	"Task not found on this board":                                               "इस बोर्ड पर कार्य नहीं मिला",
+	"Unknown status column":                                                      "अज्ञात स्थिति कॉलम",
+	"Invalid after_id":                                                           "अमान्य after_id",
+	"Invalid before_id":                                                          "अमान्य before_id",
+	"after_id and before_id must be adjacent cards of the target column":         "after_id और before_id लक्ष्य कॉलम के आसन्न कार्ड होने चाहिए",
+	"The board was modified by someone else, reload it and retry":                "बोर्ड किसी और ने बदल दिया है, इसे फिर से लोड करें और पुनः प्रयास करें",
+	"Could not move task":                                                        "कार्य स्थानांतरित नहीं किया जा सका",
+	"Error checking for duplicate tasks":                                         "डुप्लिकेट कार्यों की जाँच करने में त्रुटि",
+	"Similar open tasks exist, create the task with force=true to add it anyway": "समान खुले कार्य मौजूद हैं, फिर भी जोड़ने के लिए force=true के साथ कार्य बनाएँ",
+	"after_id and before_id must be adjacent tasks of the same pin group":        "after_id और before_id एक ही पिन समूह के आसन्न कार्य होने चाहिए",
+	"Tasks were modified by someone else, reload them and retry":                 "कार्यों को किसी और ने बदल दिया है, उन्हें फिर से लोड करें और पुनः प्रयास करें",
+	"Could not reorder tasks":                                                    "कार्यों का क्रम नहीं बदला जा सका",
+
+	// Reports
+	"Invalid week, use an ISO week such as 2024-W28 or a YYYY-MM-DD date": "अमान्य सप्ताह, 2024-W28 जैसे ISO सप्ताह या YYYY-MM-DD तिथि का उपयोग करें",
+	"capacity_hours must be between 1 and 168":                            "capacity_hours 1 और 168 के बीच होना चाहिए",
+	"Error rendering report":                                              "रिपोर्ट बनाने में त्रुटि",
+
+	// Report schedules
+	"At most 20 report schedules are allowed":            "अधिकतम 20 रिपोर्ट शेड्यूल की अनुमति है",
+	"Error fetching report schedules":                    "रिपोर्ट शेड्यूल प्राप्त करने में त्रुटि",
+	"Error fetching report schedule":                     "रिपोर्ट शेड्यूल प्राप्त करने में त्रुटि",
+	"Could not create report schedule":                   "रिपोर्ट शेड्यूल नहीं बनाया जा सका",
+	"Could not update report schedule":                   "रिपोर्ट शेड्यूल अपडेट नहीं किया जा सका",
+	"Could not delete report schedule":                   "रिपोर्ट शेड्यूल हटाया नहीं जा सका",
+	"Invalid report schedule ID":                         "अमान्य रिपोर्ट शेड्यूल ID",
+	"Report schedule not found":                          "रिपोर्ट शेड्यूल नहीं मिला",
+	"Add recipients or an email address to your account": "प्राप्तकर्ता जोड़ें या अपने खाते में ईमेल पता जोड़ें",
+	"name must be 1 to 100 characters":                   "name 1 से 100 अक्षरों का होना चाहिए",
+	"format must be pdf or csv":                          "format pdf या csv होना चाहिए",
+	"schedule never fires or fires only once":            "schedule कभी नहीं चलता या केवल एक बार चलता है",
+	"schedule must not fire more than once an hour":      "schedule एक घंटे में एक से अधिक बार नहीं चलना चाहिए",
+	"at most 10 recipients are allowed":                  "अधिकतम 10 प्राप्तकर्ताओं की अनुमति है",
+
+	// Guests
+	"email must be a valid email address":               "email एक मान्य ईमेल पता होना चाहिए",
+	"Could not create invitation":                       "आमंत्रण नहीं बनाया जा सका",
+	"Error fetching invitations":                        "आमंत्रण प्राप्त करने में त्रुटि",
+	"Error fetching invitation":                         "आमंत्रण प्राप्त करने में त्रुटि",
+	"Invalid invitation ID":                             "अमान्य आमंत्रण ID",
+	"Invitation not found":                              "आमंत्रण नहीं मिला",
+	"Could not revoke invitation":                       "आमंत्रण रद्द नहीं किया जा सका",
+	"Invitation not found, expired or already accepted": "आमंत्रण नहीं मिला, समाप्त हो गया या पहले ही स्वीकार किया जा चुका है",
+	"Could not accept invitation":                       "आमंत्रण स्वीकार नहीं किया जा सका",
+	"Guests have read-only access to their project":     "अतिथियों को केवल अपने प्रोजेक्ट को पढ़ने की अनुमति है",
+
+	// Directory accounts
+	"sign-up is disabled, accounts come from the directory": "साइन-अप बंद है, खाते डायरेक्टरी से आते हैं",
+	"directory could not be reached":                        "डायरेक्टरी से संपर्क नहीं हो सका",
+
+	// Single sign-on
+	"could not start single sign-on":               "सिंगल साइन-ऑन शुरू नहीं हो सका",
+	"single sign-on request is invalid or expired": "सिंगल साइन-ऑन अनुरोध अमान्य है या समाप्त हो गया है",
+	"SAML response was rejected":                   "SAML प्रतिक्रिया अस्वीकार कर दी गई",
+
+	// Provisioning
+	"account is deactivated": "खाता निष्क्रिय है",
+	"invalid bearer token":   "अमान्य bearer टोकन",
+
+	// API tokens
+	"token lacks the required scope":                                         "टोकन के पास आवश्यक स्कोप नहीं है",
+	"API tokens cannot manage the account":                                   "API टोकन खाते का प्रबंधन नहीं कर सकते",
+	"name is required and must be at most 100 characters":                    "नाम आवश्यक है और अधिकतम 100 वर्णों का होना चाहिए",
+	"scopes must list tasks:read, tasks:write or admin":                      "scopes में tasks:read, tasks:write या admin होना चाहिए",
+	"only admins can create tokens with the admin scope":                     "केवल एडमिन admin स्कोप वाले टोकन बना सकते हैं",
+	"expires_in must be a positive duration of at most 8760h, e.g. \"720h\"": "expires_in अधिकतम 8760h की धनात्मक अवधि होनी चाहिए, जैसे \"720h\"",
+
+	// Webhooks
+	"url must be an absolute http or https URL": "url एक पूर्ण http या https URL होना चाहिए",
+	"Unknown event":                     "अज्ञात इवेंट",
+	"Could not create webhook":          "वेबहुक नहीं बनाया जा सका",
+	"Error fetching webhooks":           "वेबहुक प्राप्त करने में त्रुटि",
+	"Invalid webhook ID":                "अमान्य वेबहुक ID",
+	"Webhook not found":                 "वेबहुक नहीं मिला",
+	"Could not delete webhook":          "वेबहुक हटाया नहीं जा सका",
+	"Error fetching webhook deliveries": "वेबहुक डिलीवरी प्राप्त करने में त्रुटि",
+	"Invalid delivery ID":               "अमान्य डिलीवरी ID",
+	"Delivery not found":                "डिलीवरी नहीं मिली",
+	"Could not record the redelivery":   "पुनः डिलीवरी दर्ज नहीं की जा सकी",
+
+	// Telegram
+	"invalid secret token":      "अमान्य सीक्रेट टोकन",
+	"Could not unlink Telegram": "Telegram अनलिंक नहीं किया जा सका",
+
+	// GitHub issue sync
+	"GitHub authorization was denied":                             "GitHub प्राधिकरण अस्वीकार किया गया",
+	"OAuth state is invalid or expired":                           "OAuth state अमान्य है या समाप्त हो गया है",
+	"GitHub did not issue a token":                                "GitHub ने टोकन जारी नहीं किया",
+	"Could not connect GitHub":                                    "GitHub कनेक्ट नहीं किया जा सका",
+	"No GitHub account is connected":                              "कोई GitHub खाता कनेक्ट नहीं है",
+	"Could not disconnect GitHub":                                 "GitHub डिस्कनेक्ट नहीं किया जा सका",
+	"repo must be a GitHub repository like owner/name":            "repo एक GitHub रिपॉजिटरी होनी चाहिए, जैसे owner/name",
+	"Task is already linked to a GitHub issue":                    "कार्य पहले से एक GitHub issue से जुड़ा है",
+	"Connect a GitHub account first":                              "पहले एक GitHub खाता कनेक्ट करें",
+	"GitHub rejected the token, connect the GitHub account again": "GitHub ने टोकन अस्वीकार कर दिया, GitHub खाता फिर से कनेक्ट करें",
+	"GitHub rejected the request":                                 "GitHub ने अनुरोध अस्वीकार कर दिया",
+	"GitHub could not be reached":                                 "GitHub तक पहुँचा नहीं जा सका",
+	"Task is not linked to a GitHub issue":                        "कार्य किसी GitHub issue से जुड़ा नहीं है",
+	"Could not unlink the GitHub issue":                           "GitHub issue अनलिंक नहीं किया जा सका",
+	"invalid webhook signature":                                   "अमान्य वेबहुक हस्ताक्षर",
+
+	// No-code automations
+	"Invalid since cursor":    "अमान्य since कर्सर",
+	"Title is required":       "शीर्षक आवश्यक है",
+	"Could not complete task": "कार्य पूरा नहीं किया जा सका",
+
+	// CalDAV
+	"Body must be a WebDAV propfind document":                             "बॉडी एक WebDAV propfind दस्तावेज़ होनी चाहिए",
+	"CalDAV clients can only sync their own tasks":                        "CalDAV क्लाइंट केवल अपने कार्य सिंक कर सकते हैं",
+	"Resource not found":                                                  "संसाधन नहीं मिला",
+	"Body must be a CalDAV report document":                               "बॉडी एक CalDAV रिपोर्ट दस्तावेज़ होनी चाहिए",
+	"Only the calendar-query and calendar-multiget reports are supported": "केवल calendar-query और calendar-multiget रिपोर्ट समर्थित हैं",
+	"Body must be an iCalendar object with a VTODO":                       "बॉडी VTODO वाला एक iCalendar ऑब्जेक्ट होनी चाहिए",
+
+	// Offline sync
+	"Invalid sync cursor":                         "अमान्य सिंक कर्सर",
+	"Sync cursor expired, sync again without it":  "सिंक कर्सर की समय सीमा समाप्त हो गई, इसके बिना फिर से सिंक करें",
+	"Error fetching deleted tasks":                "हटाए गए कार्य प्राप्त करने में त्रुटि",
+	"changes must list between 1 and 100 changes": "changes में 1 से 100 के बीच बदलाव होने चाहिए",
+	"task must be the JSON of a task":             "task एक कार्य का JSON होना चाहिए",
+	"base_version is required":                    "base_version आवश्यक है",
+	"op must be create, update or delete":         "op create, update या delete होना चाहिए",
+	"Task was deleted by someone else":            "कार्य को किसी और ने हटा दिया है",
+
+	// Invite codes
+	"invite_code is required":                                               "invite_code आवश्यक है",
+	"invite code is invalid, expired or used up":                            "आमंत्रण कोड अमान्य है, समाप्त हो गया है या पूरा उपयोग हो चुका है",
+	"max_uses must be between 1 and 1000":                                   "max_uses 1 और 1000 के बीच होना चाहिए",
+	"expires_in must be a positive duration of at most 2160h, e.g. \"48h\"": "expires_in अधिकतम 2160h की धनात्मक अवधि होनी चाहिए, जैसे \"48h\"",
+	"Could not create invite code":                                          "आमंत्रण कोड नहीं बनाया जा सका",
+	"Error fetching invite codes":                                           "आमंत्रण कोड प्राप्त करने में त्रुटि",
+	"Invalid invite code ID":                                                "अमान्य आमंत्रण कोड ID",
+	"Invite code not found":                                                 "आमंत्रण कोड नहीं मिला",
+	"Could not revoke invite code":                                          "आमंत्रण कोड रद्द नहीं किया जा सका",
+
+	// Workspaces
+	"Invalid workspace ID":                         "अमान्य वर्कस्पेस ID",
+	"Workspace not found":                          "वर्कस्पेस नहीं मिला",
+	"Workspace name is required":                   "वर्कस्पेस का नाम आवश्यक है",
+	"Error fetching workspace":                     "वर्कस्पेस प्राप्त करने में त्रुटि",
+	"Could not create workspace":                   "वर्कस्पेस नहीं बनाया जा सका",
+	"Could not join workspace":                     "वर्कस्पेस में शामिल नहीं हो सके",
+	"Only the workspace owner can change branding": "केवल वर्कस्पेस का स्वामी ब्रांडिंग बदल सकता है",
+	"Could not update branding":                    "ब्रांडिंग अपडेट नहीं की जा सकी",
+
+	// Usage quotas
+	"Quota exceeded, delete some or upgrade your plan": "कोटा समाप्त हो गया, कुछ हटाएं या अपना प्लान अपग्रेड करें",
+	"Error computing usage":                            "उपयोग की गणना करने में त्रुटि",
+	"Error checking quota":                             "कोटा जांचने में त्रुटि",
+
+	// Billing
+	"Subscription is not linked to a workspace yet": "सब्सक्रिप्शन अभी किसी वर्कस्पेस से जुड़ा नहीं है",
+	"Could not handle event":                        "इवेंट संसाधित नहीं किया जा सका",
+	"Error fetching plan":                           "प्लान प्राप्त करने में त्रुटि",
+
+	// Trash and retention
+	"Invalid archived flag, use true or false": "अमान्य archived मान, true या false का उपयोग करें",
+	"Error fetching trash":                     "कचरा पेटी प्राप्त करने में त्रुटि",
+	"Task not found in the trash":              "कार्य कचरा पेटी में नहीं मिला",
+	"Task exists already":                      "कार्य पहले से मौजूद है",
+	"Could not restore task":                   "कार्य पुनर्स्थापित नहीं किया जा सका",
+	"Could not enforce retention":              "प्रतिधारण नीति लागू नहीं की जा सकी",
+
+	// Administration
+	"Invalid dead letter ID":              "अमान्य डेड लेटर ID",
+	"Dead letter not found":               "डेड लेटर नहीं मिला",
+	"Dead letter is not pending":          "डेड लेटर लंबित नहीं है",
+	"Error fetching dead letters":         "डेड लेटर प्राप्त करने में त्रुटि",
+	"Error processing dead letter":        "डेड लेटर संसाधित करने में त्रुटि",
+	"Error fetching maintenance mode":     "रखरखाव मोड प्राप्त करने में त्रुटि",
+	"mode must be off, read-only or full": "mode off, read-only या full होना चाहिए",
+	"retry_after must not be negative":    "retry_after ऋणात्मक नहीं हो सकता",
+	"Could not update maintenance mode":   "रखरखाव मोड अपडेट नहीं किया जा सका",
+	"Backups need MongoDB storage":        "बैकअप के लिए MongoDB स्टोरेज आवश्यक है",
+	"Could not create backup":             "बैकअप नहीं बनाया जा सका",
+}
@@ -0,0 +1,121 @@
+// i18n.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package i18n translates API messages into the languages a client accepts. Messages are written in English
+// in the code and looked up verbatim in the catalog of each other language, so untranslated messages fall
+// back to the next accepted language and finally to English.
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is the language messages are written in.
+const DefaultLanguage = "en"
+
+// Catalog maps English messages to their translation in one language.
+type Catalog map[string]string
+
+var (
+	mu       sync.RWMutex
+	catalogs = map[string]Catalog{"hi": hindi}
+)
+
+// Register adds or extends the catalog of a language.
+//
+// Parameters:
+// - language: The lower-case language tag, e.g. "hi" or "pt-br".
+// - catalog: Translations keyed by English message.
+func Register(language string, catalog Catalog) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	language = strings.ToLower(language)
+	if catalogs[language] == nil {
+		catalogs[language] = Catalog{}
+	}
+	for message, translation := range catalog {
+		catalogs[language][message] = translation
+	}
+}
+
+// Languages parses an Accept-Language header into the fallback chain of language tags, most preferred first.
+// A regional tag is followed by its base language ("hi-IN" by "hi"), languages with q=0 and the "*" wildcard
+// are dropped, and DefaultLanguage always ends the chain.
+//
+// Parameters:
+// - header: The Accept-Language header value, e.g. "hi-IN,hi;q=0.9,en;q=0.8".
+//
+// Returns:
+// - []string: The lower-case language tags to try in order.
+func Languages(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+
+	accepted := []weighted{}
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality > 0 {
+			accepted = append(accepted, weighted{tag: tag, quality: quality})
+		}
+	}
+	sort.SliceStable(accepted, func(i, j int) bool { return accepted[i].quality > accepted[j].quality })
+
+	chain := []string{}
+	seen := map[string]bool{}
+	add := func(tag string) {
+		if !seen[tag] {
+			seen[tag] = true
+			chain = append(chain, tag)
+		}
+	}
+	for _, language := range accepted {
+		add(language.tag)
+		if base, _, regional := strings.Cut(language.tag, "-"); regional {
+			add(base)
+		}
+	}
+	add(DefaultLanguage)
+	return chain
+}
+
+// Translate returns message in the first language of the chain that has a translation for it.
+//
+// Parameters:
+// - message: The English message.
+// - languages: The fallback chain, as returned by Languages.
+//
+// Returns:
+// - string: The translated message, or message itself.
+// - string: The language of the returned message.
+func Translate(message string, languages []string) (string, string) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for _, language := range languages {
+		if language == DefaultLanguage {
+			return message, DefaultLanguage
+		}
+		if translation, ok := catalogs[language][message]; ok {
+			return translation, language
+		}
+	}
+	return message, DefaultLanguage
+}
@@ -0,0 +1,42 @@
+// i18n_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLanguages tests that Accept-Language headers become a fallback chain ordered by quality
+func TestLanguages(t *testing.T) {
+	assert.Equal(t, []string{"en"}, Languages(""))
+	assert.Equal(t, []string{"hi-in", "hi", "en"}, Languages("hi-IN"))
+	assert.Equal(t, []string{"fr", "hi", "en-gb", "en"}, Languages("en-GB;q=0.5, hi;q=0.8, fr, *;q=0.1"))
+
+	// Assert that refused and malformed entries are dropped
+	assert.Equal(t, []string{"en"}, Languages("hi;q=0, fr;q=high"))
+}
+
+// TestTranslate tests that the first language with a translation wins and English is the final fallback
+func TestTranslate(t *testing.T) {
+	Register("fr", Catalog{"Task not found": "Tâche introuvable"})
+
+	message, language := Translate("Task not found", Languages("de, fr;q=0.9, hi;q=0.8"))
+	assert.Equal(t, "Tâche introuvable", message)
+	assert.Equal(t, "fr", language)
+
+	message, language = Translate("Task not found", Languages("hi-IN"))
+	assert.Equal(t, "कार्य नहीं मिला", message)
+	assert.Equal(t, "hi", language)
+
+	// Assert that English stops the chain before less preferred languages
+	message, language = Translate("Task not found", Languages("en, hi;q=0.5"))
+	assert.Equal(t, "Task not found", message)
+	assert.Equal(t, "en", language)
+
+	message, language = Translate("Something new", Languages("hi"))
+	assert.Equal(t, "Something new", message)
+	assert.Equal(t, "en", language)
+}
@@ -0,0 +1,162 @@
+// interchange.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package interchange converts tasks to and from the files of other task managers, so that users can move
+// their work in and out: Trello board exports (JSON) and Todoist templates (CSV). Every conversion comes
+// with a Report of how the fields were mapped and of what could not be carried over.
+package interchange
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+)
+
+// Formats that can be exported and imported
+const (
+	FormatTrello  = "trello"  // Trello board export, JSON
+	FormatTodoist = "todoist" // Todoist template, CSV
+)
+
+// Formats lists the supported formats.
+var Formats = []string{FormatTrello, FormatTodoist}
+
+// MaxTasks is the most tasks a single import may hold.
+const MaxTasks = 1000
+
+// ErrUnknownFormat is returned for formats other than the supported Formats.
+var ErrUnknownFormat = errors.New("unknown format")
+
+// ErrMalformed is returned, wrapped with the reason, for files that are not of the format they claim.
+var ErrMalformed = errors.New("malformed file")
+
+// Mapping is the field of the source a field of the target is taken from.
+type Mapping struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Report describes a conversion.
+type Report struct {
+	Format   string    `json:"format"`
+	Mapped   []Mapping `json:"mapped"`             // Fields carried over
+	Dropped  []string  `json:"dropped,omitempty"`  // Fields of the source that had values but no counterpart
+	Skipped  int       `json:"skipped"`            // Items of the source that were not converted, see Warnings
+	Warnings []string  `json:"warnings,omitempty"` // What was changed or left out on the way, once per kind
+}
+
+// drop records that values of a field were lost.
+func (r *Report) drop(field string) {
+	for _, dropped := range r.Dropped {
+		if dropped == field {
+			return
+		}
+	}
+	r.Dropped = append(r.Dropped, field)
+}
+
+// Warn records a warning, unless it was already given.
+func (r *Report) Warn(format string, args ...interface{}) {
+	warning := fmt.Sprintf(format, args...)
+	for _, given := range r.Warnings {
+		if given == warning {
+			return
+		}
+	}
+	r.Warnings = append(r.Warnings, warning)
+}
+
+// optionalFields tell whether a task has a value for the fields not every format carries over.
+var optionalFields = map[string]func(task models.Task) bool{
+	"start_time":       func(task models.Task) bool { return !task.StartDate.IsZero() },
+	"estimate_minutes": func(task models.Task) bool { return task.EstimateMinutes > 0 },
+	"depends_on":       func(task models.Task) bool { return len(task.DependsOn) > 0 },
+	"snoozed_until":    func(task models.Task) bool { return task.SnoozedUntil != 0 },
+	"pinned":           func(task models.Task) bool { return task.Pinned },
+	"github_issue":     func(task models.Task) bool { return task.GitHubIssue != nil },
+}
+
+// reportDropped adds the fields to the report that have a value in any of the tasks.
+func reportDropped(report *Report, tasks []models.Task, fields ...string) {
+	for _, field := range fields {
+		for _, task := range tasks {
+			if optionalFields[field](task) {
+				report.drop(field)
+				break
+			}
+		}
+	}
+}
+
+// Export writes tasks in a format.
+//
+// Parameters:
+// - format: One of Formats.
+// - name: The name of the exported collection, e.g. the Trello board.
+// - tasks: The tasks.
+// - location: The timezone of dates without one.
+//
+// Returns:
+// - []byte: The file.
+// - string: Its content type.
+// - Report: How the fields were mapped.
+// - error: ErrUnknownFormat, or an error writing the file.
+func Export(format, name string, tasks []models.Task, location *time.Location) ([]byte, string, Report, error) {
+	switch format {
+	case FormatTrello:
+		data, report, err := ExportTrello(name, tasks)
+		return data, "application/json", report, err
+	case FormatTodoist:
+		data, report, err := ExportTodoist(tasks, location)
+		return data, "text/csv; charset=utf-8", report, err
+	}
+	return nil, "", Report{}, ErrUnknownFormat
+}
+
+// Import reads the tasks of a file in a format. The tasks are not validated: they carry a title, which is
+// never empty, and whichever of description, status, priority, assignee, dates and estimate the file had.
+// Dates Todoist keeps as text are returned in Task.Due, for the caller to parse in the user's timezone.
+//
+// Parameters:
+// - format: One of Formats.
+// - data: The file.
+//
+// Returns:
+// - []models.Task: The tasks, in the order of the file.
+// - Report: How the fields were mapped and what was left out.
+// - error: ErrUnknownFormat, or ErrMalformed wrapped with the reason.
+func Import(format string, data []byte) ([]models.Task, Report, error) {
+	switch format {
+	case FormatTrello:
+		return ImportTrello(data)
+	case FormatTodoist:
+		return ImportTodoist(data)
+	}
+	return nil, Report{}, ErrUnknownFormat
+}
+
+// statusOf returns the status named by a Trello list or Todoist section, accepting the names boards
+// commonly use.
+func statusOf(name string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "pending", "to do", "todo", "backlog":
+		return models.TaskStatusPending, true
+	case "in progress", "doing":
+		return models.TaskStatusInProgress, true
+	case "done", "complete", "completed":
+		return models.TaskStatusDone, true
+	}
+	return "", false
+}
+
+// reversed returns the mappings in the other direction.
+func reversed(mappings []Mapping) []Mapping {
+	reverse := make([]Mapping, len(mappings))
+	for i, mapping := range mappings {
+		reverse[i] = Mapping{From: mapping.To, To: mapping.From}
+	}
+	return reverse
+}
@@ -0,0 +1,164 @@
+// interchange_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package interchange
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sampleTasks returns tasks with every field the formats carry over.
+func sampleTasks() []models.Task {
+	due := time.Date(2024, 7, 5, 17, 0, 0, 0, time.UTC)
+	return []models.Task{
+		{ID: primitive.NewObjectID(), Title: "Write report", Description: "For the board", Status: models.TaskStatusPending,
+			Priority: models.TaskPriorityHigh, AllottedTo: "alice", EndDate: models.NewTimestamp(due), EstimateMinutes: 90},
+		{ID: primitive.NewObjectID(), Title: "Review budget", Status: models.TaskStatusInProgress, Priority: models.TaskPriorityLow,
+			AllottedTo: "bob", DependsOn: []primitive.ObjectID{primitive.NewObjectID()}},
+		{ID: primitive.NewObjectID(), Title: "Book venue", Status: models.TaskStatusDone, Priority: models.TaskPriorityMedium, AllottedTo: "alice"},
+	}
+}
+
+// TestTrelloRoundTrip tests that a board export reads back as the tasks it was written from
+func TestTrelloRoundTrip(t *testing.T) {
+	tasks := sampleTasks()
+	data, report, err := ExportTrello("Offsite", tasks)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"estimate_minutes", "depends_on"}, report.Dropped)
+	assert.Contains(t, string(data), `"name": "Offsite"`)
+
+	imported, report, err := ImportTrello(data)
+	require.NoError(t, err)
+	assert.Zero(t, report.Skipped)
+	assert.Empty(t, report.Warnings)
+	require.Len(t, imported, 3)
+	for i, task := range imported {
+		assert.Equal(t, tasks[i].Title, task.Title)
+		assert.Equal(t, tasks[i].Description, task.Description)
+		assert.Equal(t, tasks[i].Status, task.Status)
+		assert.Equal(t, tasks[i].Priority, task.Priority)
+		assert.Equal(t, tasks[i].AllottedTo, task.AllottedTo)
+		assert.Equal(t, tasks[i].EndDate, task.EndDate)
+	}
+}
+
+// TestImportTrello tests how the lists, labels and members of a board made in Trello are mapped
+func TestImportTrello(t *testing.T) {
+	board := `{
+		"name": "Launch",
+		"lists": [{"id": "l1", "name": "Doing"}, {"id": "l2", "name": "Ideas"}, {"id": "l3", "name": "Old", "closed": true}],
+		"labels": [{"id": "a", "name": "Urgent", "color": "red"}, {"id": "b", "name": "high", "color": "orange"}],
+		"members": [{"id": "m1", "username": "carol"}, {"id": "m2", "username": "dave"}],
+		"cards": [
+			{"id": "c1", "name": "Ship it", "idList": "l1", "idLabels": ["a", "b"], "idMembers": ["m1", "m2"], "due": "2024-07-05T17:00:00.000Z"},
+			{"id": "c2", "name": "Blog post", "idList": "l2", "dueComplete": true},
+			{"id": "c3", "name": "Archived", "idList": "l1", "closed": true},
+			{"id": "c4", "name": "Forgotten", "idList": "l3"}
+		],
+		"checklists": [{"id": "x"}]
+	}`
+
+	tasks, report, err := ImportTrello([]byte(board))
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, models.TaskStatusInProgress, tasks[0].Status)
+	assert.Equal(t, models.TaskPriorityHigh, tasks[0].Priority)
+	assert.Equal(t, "carol", tasks[0].AllottedTo)
+	assert.Equal(t, time.Date(2024, 7, 5, 17, 0, 0, 0, time.UTC), tasks[0].EndDate.Time().UTC())
+	assert.Equal(t, models.TaskStatusDone, tasks[1].Status)
+	assert.Empty(t, tasks[1].Priority)
+
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, []string{"labels", "checklists"}, report.Dropped)
+	assert.Contains(t, report.Warnings, "Cards with several members are assigned to the first")
+	assert.Contains(t, report.Warnings, `List "Ideas" matches no status, its cards are Pending`)
+	assert.Contains(t, report.Mapped, Mapping{From: "name", To: "title"})
+
+	_, _, err = ImportTrello([]byte(`{"foo": 1}`))
+	assert.True(t, errors.Is(err, ErrMalformed))
+	_, _, err = ImportTrello([]byte(`title,status`))
+	assert.True(t, errors.Is(err, ErrMalformed))
+}
+
+// TestTodoistRoundTrip tests that a template reads back as the tasks it was written from
+func TestTodoistRoundTrip(t *testing.T) {
+	location, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+	tasks := sampleTasks()
+	data, report, err := ExportTodoist(tasks, location)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"depends_on"}, report.Dropped)
+	assert.Len(t, report.Warnings, 1)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 7)
+	assert.Equal(t, "section,Pending,,,,,,,,,,", lines[1])
+	assert.Equal(t, "task,Write report,For the board,1,1,,alice,2024-07-05 22:30,en,Asia/Kolkata,90,minute", lines[2])
+
+	imported, report, err := ImportTodoist(data)
+	require.NoError(t, err)
+	assert.Empty(t, report.Warnings)
+	require.Len(t, imported, 3)
+	for i, task := range imported {
+		assert.Equal(t, tasks[i].Title, task.Title)
+		assert.Equal(t, tasks[i].Status, task.Status)
+		assert.Equal(t, tasks[i].Priority, task.Priority)
+		assert.Equal(t, tasks[i].AllottedTo, task.AllottedTo)
+		assert.Equal(t, tasks[i].EstimateMinutes, task.EstimateMinutes)
+	}
+	assert.Equal(t, "2024-07-05 22:30", imported[0].Due)
+}
+
+// TestImportTodoist tests sections, notes and sub-tasks of a template made in Todoist
+func TestImportTodoist(t *testing.T) {
+	template := "\uFEFFTYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE\n" +
+		"meta,view_style=list,,,,,,,,\n" +
+		"task,Plan trip,,4,1,,,tomorrow 5pm,en,\n" +
+		"note,Bring passports,,,,,,,,\n" +
+		"task,Book hotel,,2,2,,,,,\n" +
+		",,,,,,,,,\n" +
+		"section,Groceries,,,,,,,,\n" +
+		"task,Buy milk,,1,1,,,,,\n" +
+		"section,Done,,,,,,,,\n" +
+		"task,,,,1,,,,,\n"
+
+	tasks, report, err := ImportTodoist([]byte(template))
+	require.NoError(t, err)
+	require.Len(t, tasks, 3)
+	assert.Equal(t, "Plan trip", tasks[0].Title)
+	assert.Empty(t, tasks[0].Priority)
+	assert.Equal(t, "tomorrow 5pm", tasks[0].Due)
+	assert.Equal(t, models.TaskPriorityMedium, tasks[1].Priority)
+	assert.Equal(t, models.TaskStatusPending, tasks[2].Status)
+	assert.Equal(t, models.TaskPriorityHigh, tasks[2].Priority)
+
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, []string{"notes"}, report.Dropped)
+	assert.Equal(t, []string{
+		"Sub-tasks are imported as tasks of their own",
+		`Section "Groceries" matches no status, its tasks are Pending`,
+		"Tasks without content are skipped",
+	}, report.Warnings)
+
+	_, _, err = ImportTodoist([]byte("title,status\nA,Done\n"))
+	assert.True(t, errors.Is(err, ErrMalformed))
+	_, _, err = ImportTodoist(nil)
+	assert.True(t, errors.Is(err, ErrMalformed))
+}
+
+// TestUnknownFormat tests that only the supported formats are converted
+func TestUnknownFormat(t *testing.T) {
+	_, _, _, err := Export("asana", "Tasks", sampleTasks(), time.UTC)
+	assert.Equal(t, ErrUnknownFormat, err)
+	_, _, err = Import("asana", []byte("{}"))
+	assert.Equal(t, ErrUnknownFormat, err)
+}
@@ -0,0 +1,211 @@
+// todoist.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package interchange
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+)
+
+// todoistColumns are the columns of a Todoist template.
+var todoistColumns = []string{"TYPE", "CONTENT", "DESCRIPTION", "PRIORITY", "INDENT", "AUTHOR", "RESPONSIBLE", "DATE", "DATE_LANG", "TIMEZONE", "DURATION", "DURATION_UNIT"}
+
+// todoistDateLayout is the layout of exported dates, which Todoist and package naturaldate both read.
+const todoistDateLayout = "2006-01-02 15:04"
+
+// todoistMappings are the template columns the task fields are exported to.
+var todoistMappings = []Mapping{
+	{From: "title", To: "CONTENT"},
+	{From: "description", To: "DESCRIPTION"},
+	{From: "status", To: "section"},
+	{From: "priority", To: "PRIORITY"},
+	{From: "allotted_to", To: "RESPONSIBLE"},
+	{From: "end_time", To: "DATE"},
+	{From: "estimate_minutes", To: "DURATION"},
+}
+
+// Todoist priorities, p1 being the most urgent. Tasks without a priority are p4.
+var todoistPriorities = map[string]string{
+	models.TaskPriorityHigh:   "1",
+	models.TaskPriorityMedium: "2",
+	models.TaskPriorityLow:    "3",
+}
+
+// ExportTodoist writes tasks as a Todoist template, which Todoist imports into a project: a section per
+// status holding its tasks, in the order given. Todoist has no completed state for template tasks, done
+// tasks are open in the section "Done". Values are written verbatim, without the formula guard of CSV
+// reports, as Todoist would keep the guard in the task names.
+//
+// Parameters:
+// - tasks: The tasks.
+// - location: The timezone dates are written in.
+//
+// Returns:
+// - []byte: The template as CSV.
+// - Report: How the fields were mapped.
+// - error: An error writing the CSV.
+func ExportTodoist(tasks []models.Task, location *time.Location) ([]byte, Report, error) {
+	report := Report{Format: FormatTodoist, Mapped: todoistMappings}
+	reportDropped(&report, tasks, "start_time", "depends_on", "snoozed_until", "pinned", "github_issue")
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(todoistColumns)
+	sections := map[string][]models.Task{}
+	for _, task := range tasks {
+		status := task.Status
+		if !isStatus(status) {
+			status = models.TaskStatusPending
+		}
+		sections[status] = append(sections[status], task)
+	}
+	for _, status := range models.TaskStatuses {
+		if len(sections[status]) == 0 {
+			continue
+		}
+		if status == models.TaskStatusDone {
+			report.Warn("Todoist templates cannot hold completed tasks, done tasks are open in the section %q", status)
+		}
+		w.Write(todoistRow("section", status))
+		for _, task := range sections[status] {
+			row := todoistRow("task", task.Title)
+			row[2] = task.Description
+			row[3] = todoistPriorities[task.Priority]
+			row[4] = "1"
+			row[6] = task.AllottedTo
+			if !task.EndDate.IsZero() {
+				row[7] = task.EndDate.Time().In(location).Format(todoistDateLayout)
+				row[8] = "en"
+				row[9] = location.String()
+			}
+			if task.EstimateMinutes > 0 {
+				row[10], row[11] = strconv.Itoa(task.EstimateMinutes), "minute"
+			}
+			w.Write(row)
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), report, w.Error()
+}
+
+// todoistRow returns a row of the given type and content with the other columns empty.
+func todoistRow(kind, content string) []string {
+	row := make([]string, len(todoistColumns))
+	row[0], row[1] = kind, content
+	return row
+}
+
+// isStatus reports whether status is one of models.TaskStatuses.
+func isStatus(status string) bool {
+	for _, known := range models.TaskStatuses {
+		if status == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportTodoist reads the tasks of a Todoist template or project export. Sections named like a status
+// (e.g. "To Do", "Doing", "Done") give the status of the tasks below them; sub-tasks become tasks of their
+// own and notes are left out. Dates are returned as written, in Task.Due, as Todoist dates are phrases like
+// "tomorrow 5pm" too.
+//
+// Parameters:
+// - data: The CSV file.
+//
+// Returns:
+// - []models.Task: The tasks, in the order of the file.
+// - Report: How the fields were mapped and what was left out.
+// - error: ErrMalformed wrapped with the reason.
+func ImportTodoist(data []byte) ([]models.Task, Report, error) {
+	report := Report{Format: FormatTodoist, Mapped: reversed(todoistMappings)}
+
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\uFEFF"))))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, report, fmt.Errorf("%w: not a CSV file: %v", ErrMalformed, err)
+	}
+	if len(records) == 0 {
+		return nil, report, fmt.Errorf("%w: not a Todoist template: the file is empty", ErrMalformed)
+	}
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToUpper(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"TYPE", "CONTENT"} {
+		if _, ok := columns[required]; !ok {
+			return nil, report, fmt.Errorf("%w: not a Todoist template: no %s column", ErrMalformed, required)
+		}
+	}
+	value := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	tasks := []models.Task{}
+	status := models.TaskStatusPending
+	for _, record := range records[1:] {
+		switch strings.ToLower(value(record, "TYPE")) {
+		case "section":
+			var ok bool
+			if status, ok = statusOf(value(record, "CONTENT")); !ok {
+				status = models.TaskStatusPending
+				report.Warn("Section %q matches no status, its tasks are %s", value(record, "CONTENT"), status)
+			}
+			continue
+		case "task":
+		case "note":
+			report.drop("notes")
+			continue
+		default:
+			continue // Blank lines and view settings
+		}
+
+		if value(record, "CONTENT") == "" {
+			report.Skipped++
+			report.Warn("Tasks without content are skipped")
+			continue
+		}
+		task := models.Task{
+			Title:       value(record, "CONTENT"),
+			Description: value(record, "DESCRIPTION"),
+			Status:      status,
+			AllottedTo:  value(record, "RESPONSIBLE"),
+			Due:         value(record, "DATE"),
+		}
+		switch value(record, "PRIORITY") {
+		case "1":
+			task.Priority = models.TaskPriorityHigh
+		case "2":
+			task.Priority = models.TaskPriorityMedium
+		case "3":
+			task.Priority = models.TaskPriorityLow
+		}
+		if indent, err := strconv.Atoi(value(record, "INDENT")); err == nil && indent > 1 {
+			report.Warn("Sub-tasks are imported as tasks of their own")
+		}
+		if duration := value(record, "DURATION"); duration != "" {
+			amount, err := strconv.Atoi(duration)
+			switch unit := strings.ToLower(value(record, "DURATION_UNIT")); {
+			case err != nil || amount < 0:
+				report.Warn("Durations that are not a number of minutes or days are left out")
+			case unit == "day":
+				task.EstimateMinutes = amount * 24 * 60
+			default:
+				task.EstimateMinutes = amount
+			}
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, report, nil
+}
@@ -0,0 +1,252 @@
+// trello.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package interchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// trelloPosStep is the distance Trello keeps between the positions of neighbouring lists and cards.
+const trelloPosStep = 16384
+
+// trelloMappings are the card fields the task fields are exported to.
+var trelloMappings = []Mapping{
+	{From: "title", To: "name"},
+	{From: "description", To: "desc"},
+	{From: "status", To: "idList"},
+	{From: "priority", To: "idLabels"},
+	{From: "allotted_to", To: "idMembers"},
+	{From: "start_time", To: "start"},
+	{From: "end_time", To: "due"},
+}
+
+// trelloPriorityLabels are the labels exported for the priorities, highest first.
+var trelloPriorityLabels = []trelloLabel{
+	{Name: "High priority", Color: "red"},
+	{Name: "Medium priority", Color: "yellow"},
+	{Name: "Low priority", Color: "green"},
+}
+
+// trelloBoard is the part of a Trello board export the conversion reads or writes.
+type trelloBoard struct {
+	Name       string            `json:"name"`
+	Desc       string            `json:"desc"`
+	Closed     bool              `json:"closed"`
+	Lists      []trelloList      `json:"lists"`
+	Labels     []trelloLabel     `json:"labels"`
+	Members    []trelloMember    `json:"members"`
+	Cards      []trelloCard      `json:"cards"`
+	Checklists []json.RawMessage `json:"checklists,omitempty"`
+	Actions    []json.RawMessage `json:"actions,omitempty"` // Comments and the board's history
+}
+
+type trelloList struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Closed bool    `json:"closed"`
+	Pos    float64 `json:"pos"`
+}
+
+type trelloLabel struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type trelloMember struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	FullName string `json:"fullName"`
+}
+
+type trelloCard struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Desc        string            `json:"desc"`
+	IDList      string            `json:"idList"`
+	IDLabels    []string          `json:"idLabels"`
+	IDMembers   []string          `json:"idMembers"`
+	Start       *time.Time        `json:"start"`
+	Due         *time.Time        `json:"due"`
+	DueComplete bool              `json:"dueComplete"`
+	Closed      bool              `json:"closed"`
+	Pos         float64           `json:"pos"`
+	Attachments []json.RawMessage `json:"attachments,omitempty"`
+}
+
+// ExportTrello writes tasks as a Trello board export: a list per status, a label per priority and a member
+// per assignee. Trello imports the file with a board import Power-Up or the API.
+//
+// Parameters:
+// - name: The name of the board.
+// - tasks: The tasks, which become cards in this order.
+//
+// Returns:
+// - []byte: The board as JSON.
+// - Report: How the fields were mapped.
+// - error: An error encoding the board.
+func ExportTrello(name string, tasks []models.Task) ([]byte, Report, error) {
+	report := Report{Format: FormatTrello, Mapped: trelloMappings}
+	reportDropped(&report, tasks, "estimate_minutes", "depends_on", "snoozed_until", "pinned", "github_issue")
+
+	board := trelloBoard{Name: name, Lists: []trelloList{}, Labels: []trelloLabel{}, Members: []trelloMember{}, Cards: []trelloCard{}}
+	lists := map[string]string{}
+	for i, status := range models.TaskStatuses {
+		list := trelloList{ID: primitive.NewObjectID().Hex(), Name: status, Pos: float64((i + 1) * trelloPosStep)}
+		board.Lists = append(board.Lists, list)
+		lists[status] = list.ID
+	}
+	labels := map[string]string{}
+	for i, priority := range []string{models.TaskPriorityHigh, models.TaskPriorityMedium, models.TaskPriorityLow} {
+		label := trelloPriorityLabels[i]
+		label.ID = primitive.NewObjectID().Hex()
+		board.Labels = append(board.Labels, label)
+		labels[priority] = label.ID
+	}
+	members := map[string]string{}
+
+	for i, task := range tasks {
+		card := trelloCard{
+			ID:          task.ID.Hex(),
+			Name:        task.Title,
+			Desc:        task.Description,
+			IDList:      lists[task.Status],
+			IDLabels:    []string{},
+			IDMembers:   []string{},
+			DueComplete: task.Status == models.TaskStatusDone,
+			Pos:         float64((i + 1) * trelloPosStep),
+		}
+		if card.IDList == "" {
+			card.IDList = lists[models.TaskStatusPending]
+		}
+		if label, ok := labels[task.Priority]; ok {
+			card.IDLabels = append(card.IDLabels, label)
+		}
+		if task.AllottedTo != "" {
+			member, ok := members[task.AllottedTo]
+			if !ok {
+				member = primitive.NewObjectID().Hex()
+				members[task.AllottedTo] = member
+				board.Members = append(board.Members, trelloMember{ID: member, Username: task.AllottedTo, FullName: task.AllottedTo})
+			}
+			card.IDMembers = append(card.IDMembers, member)
+		}
+		if !task.StartDate.IsZero() {
+			start := task.StartDate.Time().UTC()
+			card.Start = &start
+		}
+		if !task.EndDate.IsZero() {
+			due := task.EndDate.Time().UTC()
+			card.Due = &due
+		}
+		board.Cards = append(board.Cards, card)
+	}
+
+	data, err := json.MarshalIndent(board, "", "  ")
+	return data, report, err
+}
+
+// ImportTrello reads the cards of a Trello board export. The list of a card gives its status when the list
+// is named like one (e.g. "To Do", "Doing", "Done"), and cards marked complete are done; a label named
+// after a priority (e.g. "High" or "High priority") gives the priority and the card's first member the
+// assignee. Archived cards and the cards of archived lists are skipped.
+//
+// Parameters:
+// - data: The board export.
+//
+// Returns:
+// - []models.Task: The tasks, in the order of the file.
+// - Report: How the fields were mapped and what was left out.
+// - error: ErrMalformed wrapped with the reason.
+func ImportTrello(data []byte) ([]models.Task, Report, error) {
+	report := Report{Format: FormatTrello, Mapped: append(reversed(trelloMappings), Mapping{From: "dueComplete", To: "status"})}
+
+	var board trelloBoard
+	if err := json.Unmarshal(data, &board); err != nil {
+		return nil, report, fmt.Errorf("%w: not a Trello board export: %v", ErrMalformed, err)
+	}
+	if board.Lists == nil && board.Cards == nil {
+		return nil, report, fmt.Errorf("%w: not a Trello board export: no lists or cards", ErrMalformed)
+	}
+
+	lists := map[string]trelloList{}
+	for _, list := range board.Lists {
+		lists[list.ID] = list
+	}
+	priorities := map[string]string{}
+	for _, label := range board.Labels {
+		name := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(label.Name)), " priority")
+		switch name {
+		case models.TaskPriorityHigh, models.TaskPriorityMedium, models.TaskPriorityLow:
+			priorities[label.ID] = name
+		}
+	}
+	members := map[string]string{}
+	for _, member := range board.Members {
+		members[member.ID] = member.Username
+	}
+
+	tasks := []models.Task{}
+	for _, card := range board.Cards {
+		list := lists[card.IDList]
+		switch {
+		case card.Closed || list.Closed:
+			report.Skipped++
+			report.Warn("Archived cards are skipped")
+			continue
+		case strings.TrimSpace(card.Name) == "":
+			report.Skipped++
+			report.Warn("Cards without a name are skipped")
+			continue
+		}
+
+		task := models.Task{Title: card.Name, Description: card.Desc, Status: models.TaskStatusPending}
+		if status, ok := statusOf(list.Name); ok {
+			task.Status = status
+		} else if list.Name != "" {
+			report.Warn("List %q matches no status, its cards are %s", list.Name, models.TaskStatusPending)
+		}
+		if card.DueComplete {
+			task.Status = models.TaskStatusDone
+		}
+		for _, label := range card.IDLabels {
+			if priority, ok := priorities[label]; ok && task.Priority == "" {
+				task.Priority = priority
+			} else {
+				report.drop("labels")
+			}
+		}
+		for i, member := range card.IDMembers {
+			if i > 0 {
+				report.Warn("Cards with several members are assigned to the first")
+				break
+			}
+			task.AllottedTo = members[member]
+		}
+		if card.Start != nil {
+			task.StartDate = models.NewTimestamp(*card.Start)
+		}
+		if card.Due != nil {
+			task.EndDate = models.NewTimestamp(*card.Due)
+		}
+		if len(card.Attachments) > 0 {
+			report.drop("attachments")
+		}
+		tasks = append(tasks, task)
+	}
+	if len(board.Checklists) > 0 {
+		report.drop("checklists")
+	}
+	if len(board.Actions) > 0 {
+		report.drop("actions")
+	}
+	return tasks, report, nil
+}
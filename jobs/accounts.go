@@ -0,0 +1,110 @@
+// accounts.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/readmodel"
+	"github.com/bkojha74/task-management/repository"
+)
+
+// AccountDeletionJob creates the job purging the accounts whose deletion grace period has passed.
+//
+// Parameters:
+// - interval: The time between checks; 0 disables the job.
+//
+// Returns:
+// - Job: The job, to be added to a Scheduler.
+func AccountDeletionJob(interval time.Duration) Job {
+	return Job{
+		Name:      "account-deletion",
+		Interval:  interval,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			_, err := PurgeDeletedAccounts(ctx, time.Now())
+			return err
+		},
+	}
+}
+
+// PurgeDeletedAccounts deletes the users whose delete_at has passed together with the tasks, projects,
+// sessions and report schedules they own, and publishes a user.deleted event for each. The user is deleted
+// last, so an account whose purge fails half-way is picked up again by the next run.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - now: The time to compare delete_at with.
+//
+// Returns:
+// - int: The number of accounts purged.
+// - error: An error if an account could not be read or deleted.
+func PurgeDeletedAccounts(ctx context.Context, now time.Time) (int, error) {
+	users, err := repository.Users.FindDeletionDue(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, user := range users {
+		if err := purgeAccount(ctx, user); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// purgeAccount deletes the data of one user and then the user.
+func purgeAccount(ctx context.Context, user models.User) error {
+	owned := repository.TaskQuery{UserID: user.ID}
+	tasks, err := repository.Tasks.Find(ctx, owned)
+	if err != nil {
+		return err
+	}
+	if _, err := repository.Tasks.DeleteMany(ctx, owned); err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		cache.InvalidateTask(user.ID.Hex(), task.ID.Hex())
+		readmodel.RemoveOrLog(ctx, task.ID)
+	}
+
+	if _, err := repository.Tasks.PurgeTrash(ctx, user.ID, time.Now()); err != nil {
+		return err
+	}
+	if _, err := repository.Comments.DeleteMany(ctx, user.ID); err != nil {
+		return err
+	}
+	if _, err := repository.Invitations.DeleteMany(ctx, user.ID); err != nil {
+		return err
+	}
+	if _, err := repository.Projects.DeleteMany(ctx, user.ID); err != nil {
+		return err
+	}
+	if _, err := repository.Sessions.DeleteMany(ctx, user.ID); err != nil {
+		return err
+	}
+	if _, err := repository.SignIns.DeleteMany(ctx, user.ID); err != nil {
+		return err
+	}
+	if _, err := repository.ReportSchedules.DeleteMany(ctx, user.ID); err != nil {
+		return err
+	}
+	if _, err := repository.FocusSessions.DeleteMany(ctx, user.ID); err != nil {
+		return err
+	}
+	user.Password = "" // Never publish password hashes
+	return outbox.Transaction(ctx, func(ctx context.Context) error {
+		if err := repository.Users.Delete(ctx, user.ID); err != nil && err != repository.ErrNotFound {
+			return err
+		}
+		return outbox.Publish(ctx, events.UserDeleted, user.ID.Hex(), user)
+	})
+}
@@ -0,0 +1,32 @@
+// digest.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/bkojha74/task-management/digest"
+)
+
+// DigestJob creates the job emailing the daily and weekly digests that are due. Each user's digest goes out
+// on the first run after its scheduled hour, so the interval bounds how late it can be.
+//
+// Parameters:
+// - mailer: The mail server.
+// - interval: The time between runs; 0 disables the job.
+//
+// Returns:
+// - Job: The job, to be added to a Scheduler.
+func DigestJob(mailer digest.Mailer, interval time.Duration) Job {
+	return Job{
+		Name:      "digest",
+		Interval:  interval,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			_, err := digest.SendDue(ctx, mailer, time.Now())
+			return err
+		},
+	}
+}
@@ -0,0 +1,32 @@
+// github.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/bkojha74/task-management/githubsync"
+)
+
+// GitHubSyncJob creates the job reconciling the tasks linked to GitHub issues with their issues. It catches
+// up on the changes the GitHub webhook missed and pushes the task changes to GitHub.
+//
+// Parameters:
+// - app: The GitHub OAuth app.
+// - interval: The time between syncs; 0 disables the job.
+//
+// Returns:
+// - Job: The job, to be added to a Scheduler.
+func GitHubSyncJob(app *githubsync.App, interval time.Duration) Job {
+	return Job{
+		Name:      "github-sync",
+		Interval:  interval,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			_, err := githubsync.ReconcileAll(ctx, app)
+			return err
+		},
+	}
+}
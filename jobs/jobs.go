@@ -0,0 +1,195 @@
+// jobs.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package jobs runs periodic background work, such as flagging overdue tasks, outside the request path.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bkojha74/task-management/deadletter"
+	"github.com/bkojha74/task-management/repository"
+)
+
+// LeaseGrace is how long the lease of an exclusive job outlasts its interval, so that another instance
+// takes the job over this long after the instance running it stopped.
+const LeaseGrace = 30 * time.Second
+
+// Job is work repeated at a fixed interval.
+type Job struct {
+	Name      string                          // Identifies the job in logs
+	Interval  time.Duration                   // Time between the end of one run and the start of the next
+	Run       func(ctx context.Context) error // The work; errors are logged and the job runs again next time
+	Exclusive bool                            // Run on only one of several instances, the holder of the job's lease
+}
+
+// Scheduler runs jobs in the background until its context is cancelled.
+type Scheduler struct {
+	// DeadLetter records the first failure of a job after it succeeded, or started; it defaults to
+	// deadletter.Record.
+	DeadLetter func(ctx context.Context, kind string, payload map[string]interface{}, cause error) error
+
+	jobs   []Job
+	wg     sync.WaitGroup
+	holder string // Identifies this instance as the holder of leases
+}
+
+// NewScheduler creates a scheduler without jobs.
+//
+// Returns:
+// - *Scheduler: The scheduler; add jobs and call Start.
+func NewScheduler() *Scheduler {
+	return &Scheduler{DeadLetter: deadletter.Record, holder: instanceID()}
+}
+
+// Add registers a job. Jobs with a non-positive interval are disabled and ignored.
+func (s *Scheduler) Add(job Job) {
+	if job.Interval <= 0 {
+		log.Printf("Job %s is disabled", job.Name)
+		return
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+// Start runs every job once right away and then at its interval, each on its own goroutine, until ctx is
+// cancelled. A run in progress gets the cancelled ctx and should return promptly. Failed runs are logged,
+// and the first of a series of failures is dead-lettered, so that a job failing at every run leaves one
+// dead letter until it succeeds again.
+//
+// Parameters:
+// - ctx: Cancel it to stop the jobs.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go func(job Job) {
+			defer s.wg.Done()
+			if job.Exclusive {
+				defer s.release(job)
+			}
+			failing := false
+			for {
+				err := s.run(ctx, job)
+				if err != nil && ctx.Err() == nil {
+					log.Printf("Job %s failed: %v", job.Name, err)
+					if !failing {
+						s.deadLetter(job, err)
+					}
+				}
+				failing = err != nil
+
+				select {
+				case <-time.After(job.Interval):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(job)
+	}
+}
+
+// Replay runs a dead-lettered job once, like the scheduler does, so an exclusive job whose lease another
+// instance holds is left to that instance. It is registered as the replayer of job dead letters.
+//
+// Parameters:
+// - ctx: Context of the run.
+// - payload: The dead letter payload, naming the job.
+//
+// Returns:
+// - error: An error if the job is unknown or failed again.
+func (s *Scheduler) Replay(ctx context.Context, payload map[string]interface{}) error {
+	name := fmt.Sprint(payload["job"])
+	for _, job := range s.jobs {
+		if job.Name == name {
+			return s.run(ctx, job)
+		}
+	}
+	return fmt.Errorf("no job %q is scheduled", name)
+}
+
+// deadLetter records a failed run of a job, logging when even that fails.
+func (s *Scheduler) deadLetter(job Job, cause error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	payload := map[string]interface{}{"job": job.Name, "interval_seconds": int(job.Interval / time.Second)}
+	if err := s.DeadLetter(ctx, deadletter.KindJob, payload, cause); err != nil {
+		log.Printf("Could not dead-letter job %s: %v", job.Name, err)
+	}
+}
+
+// Wait blocks until all jobs have stopped after the context passed to Start was cancelled.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// run runs a job once. An exclusive job only runs if this instance has or takes its lease, which lasts
+// until the next run plus LeaseGrace and is extended while the job runs. Without a lease repository,
+// e.g. in tests, exclusive jobs always run.
+func (s *Scheduler) run(ctx context.Context, job Job) error {
+	if !job.Exclusive || repository.Leases == nil {
+		return job.Run(ctx)
+	}
+
+	name, ttl := leaseName(job), job.Interval+LeaseGrace
+	acquired, err := repository.Leases.Acquire(ctx, name, s.holder, ttl)
+	if err != nil {
+		return fmt.Errorf("acquiring lease: %w", err)
+	}
+	if !acquired {
+		return nil // Another instance runs the job
+	}
+
+	// Keep the lease while a run takes longer than the grace period
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(LeaseGrace / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := repository.Leases.Acquire(ctx, name, s.holder, ttl); err != nil && ctx.Err() == nil {
+					log.Printf("Error extending the lease of job %s: %v", job.Name, err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// release gives up the lease of a stopped job, so that another instance takes it over right away.
+func (s *Scheduler) release(job Job) {
+	if repository.Leases == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := repository.Leases.Release(ctx, leaseName(job), s.holder); err != nil {
+		log.Printf("Error releasing the lease of job %s: %v", job.Name, err)
+	}
+}
+
+// leaseName returns the name of a job's lease.
+func leaseName(job Job) string {
+	return "job:" + job.Name
+}
+
+// instanceID identifies this process among the instances of a deployment: its host name, process ID
+// and a random suffix, which tells apart restarts with a reused process ID.
+func instanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
@@ -0,0 +1,176 @@
+// jobs_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/deadletter"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/notifications"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestScheduler tests that jobs run right away, repeat, and stop with their context
+func TestScheduler(t *testing.T) {
+	var runs atomic.Int32
+	scheduler := NewScheduler()
+	scheduler.Add(Job{Name: "count", Interval: time.Millisecond, Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+	scheduler.Add(Job{Name: "disabled", Run: func(ctx context.Context) error {
+		t.Error("disabled job ran")
+		return nil
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	scheduler.Start(ctx)
+	require.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+	cancel()
+	scheduler.Wait()
+
+	// Assert that no run starts after Wait returned
+	stopped := runs.Load()
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, stopped, runs.Load())
+}
+
+// TestSchedulerDeadLetters tests that a series of failed runs leaves one dead letter, which replays the job
+func TestSchedulerDeadLetters(t *testing.T) {
+	var runs atomic.Int32
+	var mu sync.Mutex
+	var deadLetters []map[string]interface{}
+	scheduler := NewScheduler()
+	scheduler.DeadLetter = func(ctx context.Context, kind string, payload map[string]interface{}, cause error) error {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, deadletter.KindJob, kind)
+		assert.EqualError(t, cause, "unreachable")
+		deadLetters = append(deadLetters, payload)
+		return nil
+	}
+	// The job fails on runs 1-3, succeeds on run 4 and fails from run 5 on
+	scheduler.Add(Job{Name: "flaky", Interval: time.Millisecond, Run: func(ctx context.Context) error {
+		if run := runs.Add(1); run == 4 {
+			return nil
+		}
+		return errors.New("unreachable")
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	scheduler.Start(ctx)
+	require.Eventually(t, func() bool { return runs.Load() >= 8 }, time.Second, time.Millisecond)
+	cancel()
+	scheduler.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, deadLetters, 2)
+	assert.Equal(t, "flaky", deadLetters[0]["job"])
+
+	// Assert that replaying runs the job once more and that unknown jobs are rejected
+	before := runs.Load()
+	assert.EqualError(t, scheduler.Replay(context.Background(), deadLetters[0]), "unreachable")
+	assert.Equal(t, before+1, runs.Load())
+	assert.Error(t, scheduler.Replay(context.Background(), map[string]interface{}{"job": "gone"}))
+}
+
+// TestExclusiveJobs tests that an exclusive job runs on one instance only, and moves when it stops
+func TestExclusiveJobs(t *testing.T) {
+	repository.UseMemory()
+
+	var firstRuns, secondRuns atomic.Int32
+	job := func(runs *atomic.Int32) Job {
+		return Job{Name: "purge", Interval: time.Millisecond, Exclusive: true, Run: func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		}}
+	}
+	first, second := NewScheduler(), NewScheduler()
+	first.Add(job(&firstRuns))
+	second.Add(job(&secondRuns))
+
+	firstCtx, stopFirst := context.WithCancel(context.Background())
+	first.Start(firstCtx)
+	require.Eventually(t, func() bool { return firstRuns.Load() >= 1 }, time.Second, time.Millisecond)
+	secondCtx, stopSecond := context.WithCancel(context.Background())
+	defer stopSecond()
+	second.Start(secondCtx)
+
+	// Assert that only the holder of the lease runs the job
+	require.Eventually(t, func() bool { return firstRuns.Load() >= 5 }, time.Second, time.Millisecond)
+	assert.Zero(t, secondRuns.Load())
+
+	// Assert that the other instance takes over once the holder stopped and released the lease
+	stopFirst()
+	first.Wait()
+	require.Eventually(t, func() bool { return secondRuns.Load() >= 1 }, time.Second, time.Millisecond)
+	stopSecond()
+	second.Wait()
+}
+
+// TestMarkOverdue tests that only late open tasks are flagged, once, with an event and a notification unless
+// they are snoozed
+func TestMarkOverdue(t *testing.T) {
+	repository.UseMemory()
+	defer func(bus *events.Bus, notifier notifications.Notifier) {
+		events.Default, notifications.Default = bus, notifier
+	}(events.Default, notifications.Default)
+
+	var published []string
+	events.Default = events.NewBus()
+	events.Default.Subscribe(events.TaskOverdue, func(ctx context.Context, event events.Event) {
+		published = append(published, event.Subject)
+	})
+	var notified []notifications.Notification
+	notifications.Default = notifications.NotifierFunc(func(ctx context.Context, n notifications.Notification) error {
+		notified = append(notified, n)
+		return nil
+	})
+
+	now := time.Now()
+	past, future := models.NewTimestamp(now.Add(-time.Hour)), models.NewTimestamp(now.Add(time.Hour))
+	late := models.Task{Title: "Late", AllottedTo: "alice", Status: models.TaskStatusInProgress, EndDate: past, Version: 1}
+	ctx := context.Background()
+	for _, task := range []*models.Task{
+		&late,
+		{Title: "Done late", Status: models.TaskStatusDone, EndDate: past},
+		{Title: "Not due", Status: models.TaskStatusPending, EndDate: future},
+		{Title: "No due date", Status: models.TaskStatusPending},
+		{Title: "Snoozed", AllottedTo: "bob", Status: models.TaskStatusPending, EndDate: past, SnoozedUntil: primitive.DateTime(future)},
+	} {
+		require.NoError(t, repository.Tasks.Create(ctx, task))
+	}
+
+	flagged, err := MarkOverdue(ctx, now)
+	require.NoError(t, err)
+
+	// Assert that the late open tasks were flagged and announced, without notifying about the snoozed one
+	assert.Equal(t, 2, flagged)
+	stored, err := repository.Tasks.FindByID(ctx, late.UserID, late.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.Overdue)
+	assert.Equal(t, 2, stored.Version)
+	assert.Len(t, published, 2)
+	require.Len(t, notified, 1)
+	assert.Equal(t, notifications.EventTaskOverdue, notified[0].Event)
+	assert.Equal(t, "alice", notified[0].Recipient)
+
+	// Assert that flagged tasks are not announced again
+	flagged, err = MarkOverdue(ctx, now)
+	require.NoError(t, err)
+	assert.Zero(t, flagged)
+	assert.Len(t, notified, 1)
+}
@@ -0,0 +1,32 @@
+// outbox.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/bkojha74/task-management/outbox"
+)
+
+// OutboxRelayJob creates the job delivering the events and notifications stored in the outbox. It runs on
+// one instance at a time, so that a message is not delivered by several instances at once.
+//
+// Parameters:
+// - relay: The relay delivering the messages.
+// - interval: The time between runs; 0 disables the job.
+//
+// Returns:
+// - Job: The job, to be added to a Scheduler.
+func OutboxRelayJob(relay *outbox.Relay, interval time.Duration) Job {
+	return Job{
+		Name:      "outbox-relay",
+		Interval:  interval,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			_, err := relay.Deliver(ctx, time.Now())
+			return err
+		},
+	}
+}
@@ -0,0 +1,119 @@
+// overdue.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/notifications"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/pagination"
+	"github.com/bkojha74/task-management/readmodel"
+	"github.com/bkojha74/task-management/repository"
+)
+
+// overdueBatchSize is the number of tasks flagged per query.
+const overdueBatchSize = 500
+
+// OverdueJob creates the job flagging overdue tasks.
+//
+// Parameters:
+// - interval: The time between checks; 0 disables the job.
+//
+// Returns:
+// - Job: The job, to be added to a Scheduler.
+func OverdueJob(interval time.Duration) Job {
+	return Job{
+		Name:      "overdue",
+		Interval:  interval,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			_, err := MarkOverdue(ctx, time.Now())
+			return err
+		},
+	}
+}
+
+// MarkOverdue flags the tasks whose end_time has passed and that aren't Done, publishes a task.overdue
+// event and notifies the assignee once per task. Tasks changed concurrently are skipped and picked up by
+// the next run.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - now: The time to compare end times with.
+//
+// Returns:
+// - int: The number of tasks flagged.
+// - error: An error if the tasks could not be read or written.
+func MarkOverdue(ctx context.Context, now time.Time) (int, error) {
+	notFlagged := false
+	query := repository.TaskQuery{
+		ExcludeStatus: models.TaskStatusDone,
+		DueBefore:     now,
+		HasDueDate:    true,
+		Overdue:       &notFlagged,
+		Sort:          pagination.Sort{Field: "_id"},
+		Limit:         overdueBatchSize,
+	}
+
+	flagged := 0
+	for {
+		tasks, err := repository.Tasks.Find(ctx, query)
+		if err != nil {
+			return flagged, err
+		}
+		if len(tasks) > overdueBatchSize {
+			tasks = tasks[:overdueBatchSize]
+		}
+
+		for _, task := range tasks {
+			task.Overdue = true
+			task.Version++
+			err := outbox.Transaction(ctx, func(ctx context.Context) error {
+				if err := repository.Tasks.Update(ctx, &task, task.Version-1); err != nil {
+					return err
+				}
+				return announceOverdue(ctx, task, now)
+			})
+			if err == repository.ErrNotFound {
+				continue
+			}
+			if err != nil {
+				return flagged, err
+			}
+			flagged++
+			cache.InvalidateTask(task.UserID.Hex(), task.ID.Hex())
+			readmodel.SyncOrLog(ctx, task)
+		}
+
+		if len(tasks) < overdueBatchSize {
+			return flagged, nil
+		}
+		query.After = &pagination.Cursor{Sort: query.Sort.String(), ID: tasks[len(tasks)-1].ID}
+	}
+}
+
+// announceOverdue publishes the task.overdue event of a newly flagged task and tells the assignee about it,
+// unless the task is snoozed.
+func announceOverdue(ctx context.Context, task models.Task, now time.Time) error {
+	if err := outbox.Publish(ctx, events.TaskOverdue, task.ID.Hex(), task); err != nil {
+		return err
+	}
+
+	if task.SnoozedUntil.Time().After(now) {
+		return nil
+	}
+
+	return outbox.Notify(ctx, notifications.Notification{
+		Event:     notifications.EventTaskOverdue,
+		Recipient: task.AllottedTo,
+		TaskID:    task.ID.Hex(),
+		Subject:   "Overdue task: " + task.Title,
+		Message:   "The task \"" + task.Title + "\" was due " + task.EndDate.Time().UTC().Format(time.RFC1123) + " and is not done yet.",
+	})
+}
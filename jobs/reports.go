@@ -0,0 +1,32 @@
+// reports.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/bkojha74/task-management/reports"
+)
+
+// ReportScheduleJob creates the job emailing the reports whose schedules are due. A report goes out on the
+// first run after its scheduled time, so the interval bounds how late it can be.
+//
+// Parameters:
+// - mailer: The mail server.
+// - interval: The time between runs; 0 disables the job.
+//
+// Returns:
+// - Job: The job, to be added to a Scheduler.
+func ReportScheduleJob(mailer reports.Mailer, interval time.Duration) Job {
+	return Job{
+		Name:      "report-schedules",
+		Interval:  interval,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			_, err := reports.SendDue(ctx, mailer, time.Now())
+			return err
+		},
+	}
+}
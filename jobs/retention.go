@@ -0,0 +1,43 @@
+// retention.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/bkojha74/task-management/audit"
+	"github.com/bkojha74/task-management/retention"
+)
+
+// RetentionJob creates the job archiving completed tasks, purging the trash and pruning the audit log as
+// the policy says, and logging what it did, or would do in a dry run.
+//
+// Parameters:
+// - policy: The retention policy.
+// - sink: The audit sink, or nil when auditing is off.
+// - interval: The time between runs; 0 disables the job.
+//
+// Returns:
+// - Job: The job, to be added to a Scheduler.
+func RetentionJob(policy retention.Policy, sink audit.Sink, interval time.Duration) Job {
+	return Job{
+		Name:      "retention",
+		Interval:  interval,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			report, err := retention.Enforce(ctx, policy, sink, time.Now())
+			if err != nil {
+				return err
+			}
+			if report.DryRun {
+				log.Printf("Retention dry run: would archive %d tasks, purge %d trashed tasks and %d audit entries", report.Archived, report.PurgedTasks, report.AuditEntries)
+			} else if report.Archived+report.PurgedTasks+report.AuditEntries > 0 {
+				log.Printf("Retention: archived %d tasks, purged %d trashed tasks and %d audit entries", report.Archived, report.PurgedTasks, report.AuditEntries)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,49 @@
+// secrets.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package jobs
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/bkojha74/task-management/secrets"
+	"github.com/bkojha74/task-management/utils"
+)
+
+// SecretsRefreshJob creates the job re-fetching the secrets from the secrets backend, so that a rotated
+// JWT_SECRET is picked up without a restart: new tokens are signed with it and tokens signed with the
+// previous secret stay valid until they expire. A changed MONGO_URI only takes effect after a restart.
+//
+// Parameters:
+// - provider: The secrets backend.
+// - interval: The time between refreshes; 0 disables the job.
+// - signing: The secret tokens are signed with.
+//
+// Returns:
+// - Job: The job, to be added to a Scheduler.
+func SecretsRefreshJob(provider secrets.Provider, interval time.Duration, signing *utils.SigningSecret) Job {
+	return Job{
+		Name:     "secrets-refresh",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			changed, err := secrets.Apply(ctx, provider)
+			if err != nil {
+				return err
+			}
+			for _, name := range changed {
+				switch name {
+				case "JWT_SECRET":
+					if signing.Set(os.Getenv(name)) {
+						log.Println("JWT_SECRET was rotated, signing new tokens with it")
+					}
+				case "MONGO_URI":
+					log.Println("MONGO_URI changed in the secrets backend, restart to connect with it")
+				}
+			}
+			return nil
+		},
+	}
+}
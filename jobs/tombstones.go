@@ -0,0 +1,32 @@
+// tombstones.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/bkojha74/task-management/repository"
+)
+
+// TombstonePruneJob creates the job deleting the tombstones of tasks deleted longer ago than the sync
+// retention. Clients whose sync cursor is older than that get 410 Gone from GET /sync and sync from scratch.
+//
+// Parameters:
+// - interval: The time between runs; 0 disables the job.
+// - retention: How long tombstones are kept.
+//
+// Returns:
+// - Job: The job, to be added to a Scheduler.
+func TombstonePruneJob(interval, retention time.Duration) Job {
+	return Job{
+		Name:      "tombstone-prune",
+		Interval:  interval,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			_, err := repository.Tombstones.DeleteBefore(ctx, time.Now().Add(-retention))
+			return err
+		},
+	}
+}
@@ -0,0 +1,121 @@
+// lexorank.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package lexorank generates string ranks for manually ordered lists. A rank sorts between its
+// neighbours by plain string comparison, so moving an item only rewrites that item's rank.
+package lexorank
+
+import (
+	"errors"
+	"strings"
+)
+
+// digits are the rank characters in ascending order. Ranks never end in the lowest digit, which keeps
+// room for a rank before every other rank.
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// ErrInvalidRange is returned when no rank can be placed between the given ranks.
+var ErrInvalidRange = errors.New("lexorank: lower rank is not below upper rank")
+
+// Between returns a rank sorting after lower and before upper. An empty lower means the start of the
+// list and an empty upper its end.
+//
+// Parameters:
+// - lower: The rank of the previous item, or "".
+// - upper: The rank of the next item, or "".
+//
+// Returns:
+// - string: The new rank.
+// - error: ErrInvalidRange if lower does not sort before upper or a rank contains other characters.
+func Between(lower, upper string) (string, error) {
+	if !valid(lower) || !valid(upper) || (upper != "" && lower >= upper) {
+		return "", ErrInvalidRange
+	}
+	return midpoint(lower, upper), nil
+}
+
+// Spread returns n ascending ranks evenly spaced over the whole range, for (re)ranking a full list.
+//
+// Parameters:
+// - n: The number of ranks.
+//
+// Returns:
+// - []string: The ranks, shortest possible for n of at least two characters.
+func Spread(n int) []string {
+	width, space := 2, len(digits)*len(digits)
+	for space <= n {
+		width, space = width+1, space*len(digits)
+	}
+
+	ranks := make([]string, n)
+	step := space / (n + 1)
+	for i := range ranks {
+		value := step * (i + 1)
+		rank := make([]byte, width)
+		for j := width - 1; j >= 0; j-- {
+			rank[j] = digits[value%len(digits)]
+			value /= len(digits)
+		}
+		ranks[i] = strings.TrimRight(string(rank), digits[:1])
+	}
+	return ranks
+}
+
+// midpoint returns a rank between lower and upper, where upper "" is unbounded. Ranks compare as if
+// lower were padded with the lowest digit.
+func midpoint(lower, upper string) string {
+	if upper != "" {
+		// Keep the common prefix and place the rank within it
+		n := 0
+		for n < len(upper) && digitAt(lower, n) == upper[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(lower) {
+				rest = lower[n:]
+			}
+			return upper[:n] + midpoint(rest, upper[n:])
+		}
+	}
+
+	low, high := 0, len(digits)
+	if lower != "" {
+		low = strings.IndexByte(digits, lower[0])
+	}
+	if upper != "" {
+		high = strings.IndexByte(digits, upper[0])
+	}
+	if high-low > 1 {
+		return string(digits[(low+high+1)/2])
+	}
+
+	// The first digits are adjacent: a longer upper rank leaves room right below it,
+	// otherwise continue after the lower rank's first digit
+	if len(upper) > 1 {
+		return upper[:1]
+	}
+	rest := ""
+	if lower != "" {
+		rest = lower[1:]
+	}
+	return string(digits[low]) + midpoint(rest, "")
+}
+
+// digitAt returns the digit of a rank at position i, padding with the lowest digit.
+func digitAt(rank string, i int) byte {
+	if i < len(rank) {
+		return rank[i]
+	}
+	return digits[0]
+}
+
+// valid reports whether a rank only uses rank digits and does not end in the lowest one.
+func valid(rank string) bool {
+	for i := 0; i < len(rank); i++ {
+		if strings.IndexByte(digits, rank[i]) < 0 {
+			return false
+		}
+	}
+	return !strings.HasSuffix(rank, digits[:1])
+}
@@ -0,0 +1,75 @@
+// lexorank_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package lexorank
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBetween tests that new ranks sort strictly between their neighbours
+func TestBetween(t *testing.T) {
+	cases := []struct{ lower, upper string }{
+		{"", ""}, {"", "1"}, {"z", ""}, {"h", "i"}, {"h9", "i5"}, {"a", "a1"}, {"azz", "b"}, {"", "001"},
+	}
+	for _, c := range cases {
+		rank, err := Between(c.lower, c.upper)
+		require.NoError(t, err)
+
+		// Assert the order and that the rank is valid for further inserts
+		assert.Greater(t, rank, c.lower)
+		if c.upper != "" {
+			assert.Less(t, rank, c.upper)
+		}
+		assert.True(t, valid(rank), rank)
+	}
+
+	// Assert that reversed, equal and malformed neighbours are rejected
+	for _, c := range []struct{ lower, upper string }{{"b", "a"}, {"a", "a"}, {"A", ""}, {"", "a0"}} {
+		_, err := Between(c.lower, c.upper)
+		assert.ErrorIs(t, err, ErrInvalidRange)
+	}
+}
+
+// TestRepeatedInserts tests that random inserts into a list keep it ordered
+func TestRepeatedInserts(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	ranks := Spread(3)
+	for i := 0; i < 1000; i++ {
+		pos := random.Intn(len(ranks) + 1)
+		lower, upper := "", ""
+		if pos > 0 {
+			lower = ranks[pos-1]
+		}
+		if pos < len(ranks) {
+			upper = ranks[pos]
+		}
+		rank, err := Between(lower, upper)
+		require.NoError(t, err)
+		ranks = append(ranks[:pos], append([]string{rank}, ranks[pos:]...)...)
+	}
+
+	// Assert that the list order is the string order
+	assert.True(t, sort.StringsAreSorted(ranks))
+}
+
+// TestSpread tests that spread ranks are ascending, distinct and valid
+func TestSpread(t *testing.T) {
+	for _, n := range []int{1, 5, 1295, 5000} {
+		ranks := Spread(n)
+		require.Len(t, ranks, n)
+
+		// Assert the order and validity of every rank
+		for i, rank := range ranks {
+			assert.True(t, valid(rank), rank)
+			if i > 0 {
+				assert.Less(t, ranks[i-1], rank)
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// lifecycle.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package lifecycle
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	draining  atomic.Bool
+	drainOnce sync.Once
+	drained   = make(chan struct{})
+)
+
+// Draining reports whether the instance is draining. A draining instance keeps serving requests
+// but reports not-ready so that load balancers stop sending it new traffic.
+func Draining() bool {
+	return draining.Load()
+}
+
+// StartDrain flips readiness to not-ready and closes the Drained channel after the grace period,
+// at which point the server should shut down. Calling it again while draining has no effect.
+//
+// Parameters:
+// - grace: How long to keep serving traffic before the instance is considered drained.
+//
+// Returns:
+// - bool: True if draining was started by this call, false if it was already in progress.
+func StartDrain(grace time.Duration) bool {
+	started := false
+	drainOnce.Do(func() {
+		started = true
+		draining.Store(true)
+		log.Printf("Draining: readiness set to not-ready, shutting down in %s", grace)
+
+		time.AfterFunc(grace, func() {
+			close(drained)
+		})
+	})
+	return started
+}
+
+// Drained returns a channel that is closed once the drain grace period has elapsed.
+func Drained() <-chan struct{} {
+	return drained
+}
@@ -0,0 +1,25 @@
+// signals.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+//go:build !windows
+
+package lifecycle
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// HandleDrainSignal starts draining with the given grace period whenever the process receives SIGUSR1.
+func HandleDrainSignal(grace time.Duration) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+
+	go func() {
+		for range signals {
+			StartDrain(grace)
+		}
+	}()
+}
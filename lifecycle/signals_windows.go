@@ -0,0 +1,9 @@
+// signals_windows.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package lifecycle
+
+import "time"
+
+// HandleDrainSignal is a no-op on Windows, which has no SIGUSR1; use the /admin/drain endpoint instead.
+func HandleDrainSignal(grace time.Duration) {}
@@ -0,0 +1,77 @@
+// logging.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package logging holds the log level of the process, which can be changed while it runs, and writes
+// leveled messages through the standard logger.
+package logging
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"strings"
+)
+
+// level is the minimum level written; its zero value is slog.LevelInfo.
+var level slog.LevelVar
+
+// ParseLevel parses a level name: debug, info, warn or error, case-insensitive. An empty name is info.
+//
+// Parameters:
+// - name: The level name, e.g. from LOG_LEVEL.
+//
+// Returns:
+// - slog.Level: The level.
+// - error: An error if the name is unknown.
+func ParseLevel(name string) (slog.Level, error) {
+	if strings.TrimSpace(name) == "" {
+		return slog.LevelInfo, nil
+	}
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q, use debug, info, warn or error", name)
+	}
+	return parsed, nil
+}
+
+// SetLevel sets the minimum level written.
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// Level returns the minimum level written.
+func Level() slog.Level {
+	return level.Level()
+}
+
+// Enabled reports whether messages of level l are written.
+func Enabled(l slog.Level) bool {
+	return l >= level.Level()
+}
+
+// Debugf writes a debug message.
+func Debugf(format string, args ...interface{}) {
+	logf(slog.LevelDebug, format, args...)
+}
+
+// Infof writes an informational message.
+func Infof(format string, args ...interface{}) {
+	logf(slog.LevelInfo, format, args...)
+}
+
+// Warnf writes a warning.
+func Warnf(format string, args ...interface{}) {
+	logf(slog.LevelWarn, format, args...)
+}
+
+// Errorf writes an error message.
+func Errorf(format string, args ...interface{}) {
+	logf(slog.LevelError, format, args...)
+}
+
+// logf writes a message prefixed with its level if the level is enabled.
+func logf(l slog.Level, format string, args ...interface{}) {
+	if Enabled(l) {
+		log.Printf(l.String()+" "+format, args...)
+	}
+}
@@ -0,0 +1,35 @@
+// logging_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package logging
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLevel tests parsing level names and changing the level at runtime
+func TestLevel(t *testing.T) {
+	defer SetLevel(slog.LevelInfo)
+
+	// Assert that names are case-insensitive, empty is info and unknown names are rejected
+	level, err := ParseLevel("WARN")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, level)
+	level, err = ParseLevel("")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelInfo, level)
+	_, err = ParseLevel("verbose")
+	assert.Error(t, err)
+
+	// Assert that only messages at or above the level are enabled
+	SetLevel(slog.LevelWarn)
+	assert.Equal(t, slog.LevelWarn, Level())
+	assert.False(t, Enabled(slog.LevelInfo))
+	assert.True(t, Enabled(slog.LevelError))
+	SetLevel(slog.LevelDebug)
+	assert.True(t, Enabled(slog.LevelDebug))
+}
@@ -4,21 +4,37 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
 	"log"
 	"os"
-	"strconv"
+	"time"
+	_ "time/tzdata" // User timezones must resolve on hosts without a zoneinfo database
 
+	"github.com/bkojha74/task-management/app"
+	"github.com/bkojha74/task-management/audit"
+	"github.com/bkojha74/task-management/auth"
+	"github.com/bkojha74/task-management/changestream"
 	"github.com/bkojha74/task-management/database"
-	"github.com/bkojha74/task-management/handlers"
+	"github.com/bkojha74/task-management/deadletter"
+	"github.com/bkojha74/task-management/events"
 	"github.com/bkojha74/task-management/helper"
-	"github.com/bkojha74/task-management/middleware"
+	"github.com/bkojha74/task-management/jobs"
+	"github.com/bkojha74/task-management/notifications"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/search"
+	"github.com/bkojha74/task-management/secrets"
+	"github.com/bkojha74/task-management/selfcheck"
 	"github.com/bkojha74/task-management/utils"
-
-	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/bkojha74/task-management/webhooks"
 )
 
 func main() {
+	checkOnly := flag.Bool("check", false, "run the startup self-check, print its report and exit non-zero if a check fails")
+	flag.Parse()
+
 	// Read current working directory
 	currentWorkDirectory, err := os.Getwd()
 	if err != nil {
@@ -28,48 +44,216 @@ func main() {
 	// Load environment variables from the configuration file
 	helper.LoadEnv(currentWorkDirectory + "/config")
 
-	// Retrieve environment variables
+	// Fetch JWT_SECRET, MONGO_URI, ... from Vault or AWS Secrets Manager when SECRETS_BACKEND is set
+	secretsProvider, err := secrets.LoadProvider()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if secretsProvider != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		_, err := secrets.Apply(ctx, secretsProvider)
+		cancel()
+		if err != nil {
+			log.Fatal("Error fetching secrets: ", err)
+		}
+	}
+
+	// Ensure the MongoDB connection string is set
 	mongoURI := helper.GetEnv("MONGO_URI")
-	appPort := helper.GetEnv("APP_PORT")
-	jwtSecret := helper.GetEnv("JWT_SECRET")
-	tokenExpiry := helper.GetEnv("TOKEN_EXPIRY_TIME")
+	if mongoURI == "" {
+		log.Fatal("Environment variable MONGO_URI must be set")
+	}
 
-	// Ensure required environment variables are set
-	if mongoURI == "" || jwtSecret == "" || appPort == "" || tokenExpiry == "" {
-		log.Fatal("Environment variables MONGO_URI, APP_PORT, JWT_SECRET and TOKEN_EXPIRY_TIME must be set")
+	// Application settings (port, JWT, TLS, CORS, limits, ...) from the environment
+	config, err := app.LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	database.Name = config.Database // Each environment has its own database unless MONGO_DATABASE says otherwise
+
+	// In --check mode only report whether the deployment is fit to serve, e.g. as a CI/CD gate
+	if *checkOnly {
+		if err := database.Connect(mongoURI); err != nil {
+			log.Println("Error", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		report := selfcheck.Run(ctx, selfcheck.Startup(config.JWTSecret, config.JWTClockSkew))
+		cancel()
+		database.Disconnect()
+		output, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(output))
+		if report.Failed() {
+			os.Exit(1)
+		}
+		return
 	}
 
-	// Convert TOKEN_EXPIRY_TIME to integer
-	tokenExpiryTime, err := strconv.Atoi(tokenExpiry)
+	// Tokens are signed with a secret that the secrets refresh job can rotate
+	signing := utils.NewSigningSecret(config.JWTSecret)
+
+	// Rate limits, log level, CORS and feature flags are reloaded from config/.env and the environment
+	reloader := app.NewReloader()
+
+	options := []app.Option{app.WithConfig(config), app.WithMongoStorage(mongoURI), app.WithSigningSecret(signing), app.WithReloader(reloader)}
+
+	// Deliver notifications to the users' Slack/Teams channels, linked Telegram chats and NOTIFY_WEBHOOK_URL
+	// on a background worker pool
+	channels := notifications.Channels{}
+	webhookURL := helper.GetEnv("NOTIFY_WEBHOOK_URL")
+	if webhookURL != "" {
+		channels.Webhook = notifications.Webhook{URL: webhookURL}
+	}
+	if config.Telegram != nil {
+		channels.Telegram = config.Telegram
+	}
+	dispatcher := notifications.NewDispatcher(channels, notifications.LoadDispatcherConfig())
+	deadletter.RegisterReplayer(deadletter.KindNotification, dispatcher.Replay)
+	options = append(options, app.WithNotifier(notifications.Router{Queue: dispatcher, Webhook: webhookURL != ""}))
+
+	// Forward domain events to Kafka or NATS when EVENTS_PUBLISHER is set
+	publisher, err := events.LoadPublisher()
 	if err != nil {
-		log.Fatal("Error converting TOKEN_EXPIRY_TIME to integer:", err)
+		log.Fatal(err)
 	}
 
-	// Initialize the Fiber app
-	app := fiber.New()
+	// With OUTBOX=true events and notifications are stored with the writes causing them and delivered by
+	// the relay job, which then also forwards the events (needs a replica set)
+	outboxConfig := outbox.LoadConfig()
+	var relay *outbox.Relay
+	var forwarder *events.Forwarder
+	if outboxConfig.Enabled {
+		outbox.Enable(true)
+		relay = outbox.NewRelay(outboxConfig, events.Default, publisher, webhookURL != "", channels)
+		deadletter.RegisterReplayer(deadletter.KindEvent, relay.ReplayEvent)
+	} else if publisher != nil {
+		forwarder = events.NewForwarder(publisher, helper.GetEnvInt("EVENTS_QUEUE_SIZE", 1000))
+		events.Default.Subscribe("", forwarder.Handle)
+	}
+
+	// Post domain events to the webhooks registered by admins, signed and with every attempt recorded
+	deliverer := webhooks.NewDeliverer(webhooks.LoadConfig())
+	events.Default.Subscribe("", deliverer.Handle)
+	deadletter.RegisterReplayer(deadletter.KindWebhook, webhooks.ReplayDelivery)
+
+	// Search tasks in Elasticsearch or OpenSearch when SEARCH_BACKEND is set; the index is fed by the
+	// change stream
+	elastic, err := search.LoadElastic()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if elastic != nil {
+		if helper.GetEnv("CHANGE_STREAMS") != "true" {
+			log.Fatal("SEARCH_BACKEND needs CHANGE_STREAMS=true to keep the search index up to date")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := elastic.EnsureIndex(ctx)
+		cancel()
+		if err != nil {
+			log.Fatal("Error creating the search index: ", err)
+		}
+		search.Default = elastic
+	}
 
-	// Middleware setup
-	app.Use(logger.New()) // Request logger middleware
+	// Check sign-ins against an LDAP directory or Active Directory when AUTH_PROVIDER is ldap
+	directory, err := auth.LoadLDAP()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if directory != nil {
+		auth.Default = directory
+	}
 
-	// Initialize MongoDB connection
-	database.Init(mongoURI)
+	// Record mutating requests to MongoDB or a rotated file when AUDIT_SINK is set
+	auditSink, err := audit.LoadSink()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if auditSink != nil {
+		options = append(options, app.WithAuditSink(auditSink))
+	}
+
+	// Email daily or weekly digests and scheduled reports to the users who ask for them when SMTP_HOST is set
+	mailServer, err := notifications.LoadSMTP()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Build the app on top of MongoDB
+	api, err := app.New(options...)
+	if err != nil {
+		log.Fatal(err)
+	}
 	defer database.Disconnect() // Ensure database connection is closed when main function exits
 
-	// User management endpoints
-	app.Post("/signup", handlers.SignUp)                             // User registration endpoint
-	app.Post("/signin", handlers.SignIn(jwtSecret, tokenExpiryTime)) // User login endpoint with JWT token generation
-	app.Post("/signout", handlers.SignOut)                           // User logout endpoint
+	// Log the startup self-check; failed checks are reported but do not stop the server
+	checkCtx, cancelCheck := context.WithTimeout(context.Background(), 30*time.Second)
+	selfcheck.Run(checkCtx, selfcheck.Startup(config.JWTSecret, config.JWTClockSkew)).Log()
+	cancelCheck()
+
+	// Background work runs until the server stops
+	background, stopBackground := context.WithCancel(context.Background())
+
+	// Keep counters, search terms, caches and the search engine up to date from the change stream (needs a
+	// replica set)
+	if helper.GetEnv("CHANGE_STREAMS") == "true" {
+		listener := changestream.NewTaskListener()
+		if elastic != nil {
+			listener.Consumers = append(listener.Consumers, changestream.SearchEngineIndex{Indexer: elastic})
+		}
+		go listener.Run(background)
+	}
+
+	// Periodic jobs
+	scheduler := jobs.NewScheduler()
+	deadletter.RegisterReplayer(deadletter.KindJob, scheduler.Replay)
+	scheduler.Add(jobs.OverdueJob(time.Duration(helper.GetEnvInt("OVERDUE_CHECK_INTERVAL", 300)) * time.Second))
+	scheduler.Add(jobs.AccountDeletionJob(time.Duration(helper.GetEnvInt("ACCOUNT_PURGE_INTERVAL", 3600)) * time.Second))
+	scheduler.Add(reloader.Job(currentWorkDirectory+"/config", time.Duration(helper.GetEnvInt("CONFIG_RELOAD_INTERVAL", 30))*time.Second))
+	if secretsProvider != nil {
+		scheduler.Add(jobs.SecretsRefreshJob(secretsProvider, time.Duration(helper.GetEnvInt("SECRETS_REFRESH_INTERVAL", 300))*time.Second, signing))
+	}
+	if relay != nil {
+		scheduler.Add(jobs.OutboxRelayJob(relay, outboxConfig.Interval))
+	}
+	scheduler.Add(jobs.RetentionJob(config.Retention, auditSink, time.Duration(helper.GetEnvInt("RETENTION_INTERVAL", 3600))*time.Second))
+	if config.SyncRetention > 0 {
+		scheduler.Add(jobs.TombstonePruneJob(time.Duration(helper.GetEnvInt("TOMBSTONE_PRUNE_INTERVAL", 3600))*time.Second, config.SyncRetention))
+	}
+	if mailServer != nil {
+		scheduler.Add(jobs.DigestJob(mailServer, time.Duration(helper.GetEnvInt("DIGEST_CHECK_INTERVAL", 900))*time.Second))
+		scheduler.Add(jobs.ReportScheduleJob(mailServer, time.Duration(helper.GetEnvInt("REPORT_SCHEDULE_INTERVAL", 60))*time.Second))
+	}
+	if config.GitHub != nil {
+		scheduler.Add(jobs.GitHubSyncJob(config.GitHub, time.Duration(helper.GetEnvInt("GITHUB_SYNC_INTERVAL", 300))*time.Second))
+	}
+	scheduler.Start(background)
 
-	// JWT Middleware for task management endpoints
-	app.Use("/tasks", middleware.Protected(jwtSecret))
+	// Serve until shut down by a signal or a drain
+	err = app.Run(api, config)
+	stopBackground()
+	scheduler.Wait()
 
-	// Task management endpoints
-	app.Post("/tasks", utils.JWTMiddleware(jwtSecret), handlers.CreateTask)       // Create task endpoint
-	app.Get("/tasks", utils.JWTMiddleware(jwtSecret), handlers.GetTasks)          // Get all tasks endpoint
-	app.Get("/tasks/:id", utils.JWTMiddleware(jwtSecret), handlers.GetTask)       // Get a single task by ID endpoint
-	app.Put("/tasks/:id", utils.JWTMiddleware(jwtSecret), handlers.UpdateTask)    // Update task by ID endpoint
-	app.Delete("/tasks/:id", utils.JWTMiddleware(jwtSecret), handlers.DeleteTask) // Delete task by ID endpoint
+	// Deliver the queued notifications, events and webhooks before exiting
+	ctx, cancel := context.WithTimeout(context.Background(), config.DrainGrace)
+	if err := dispatcher.Close(ctx); err != nil {
+		log.Println("Undelivered notifications were dead-lettered:", err)
+	}
+	if forwarder != nil {
+		if err := forwarder.Close(ctx); err != nil {
+			log.Println("Error flushing events:", err)
+		}
+	}
+	if err := deliverer.Close(ctx); err != nil {
+		log.Println("Webhook deliveries were left pending:", err)
+	}
+	cancel()
+	if auditSink != nil {
+		if err := auditSink.Close(); err != nil {
+			log.Println("Error closing audit log:", err)
+		}
+	}
 
-	// Start the Fiber server on the specified port
-	log.Fatal(app.Listen(":" + appPort))
+	if err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,53 @@
+// markdown.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package markdown renders the Markdown of task descriptions and comments to HTML that is safe to embed in
+// a page. The Markdown is rendered with goldmark (CommonMark plus strikethrough), which drops raw HTML, and
+// the result is sanitized with bluemonday's user-generated content policy, so the output keeps only
+// formatting elements and http, https and mailto links and cannot carry scripts.
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// renderer converts Markdown to HTML. Line breaks within a paragraph are kept, as users type them.
+var renderer = goldmark.New(
+	goldmark.WithExtensions(extension.Strikethrough),
+	goldmark.WithRendererOptions(html.WithHardWraps()),
+)
+
+// policy sanitizes the rendered HTML, keeping the language of fenced code blocks for syntax highlighting.
+// Both are safe for concurrent use.
+var policy = func() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[A-Za-z0-9_+-]+$`)).OnElements("code")
+	return policy
+}()
+
+// Render converts Markdown to sanitized HTML. The empty string renders to the empty string.
+//
+// Parameters:
+// - source: The Markdown text, e.g. a task description.
+//
+// Returns:
+// - string: The sanitized HTML.
+func Render(source string) string {
+	if strings.TrimSpace(source) == "" {
+		return ""
+	}
+	var out bytes.Buffer
+	if err := renderer.Convert([]byte(source), &out); err != nil {
+		// Rendering only fails when writing fails, which a buffer does not; fall back to escaped text
+		out.Reset()
+		out.WriteString("<p>" + policy.Sanitize(source) + "</p>")
+	}
+	return strings.TrimSpace(policy.Sanitize(out.String()))
+}
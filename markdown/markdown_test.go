@@ -0,0 +1,104 @@
+// markdown_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRender tests that the supported Markdown renders to the expected HTML
+func TestRender(t *testing.T) {
+	cases := []struct {
+		source   string
+		expected string
+	}{
+		{"", ""},
+		{"Plain text", "<p>Plain text</p>"},
+		{"First line\nsecond line\n\nNext paragraph", "<p>First line<br>\nsecond line</p>\n<p>Next paragraph</p>"},
+		{"## Steps ##", "<h2>Steps</h2>"},
+		{"**bold**, *em*, __strong__, _em_ and ~~gone~~", "<p><strong>bold</strong>, <em>em</em>, <strong>strong</strong>, <em>em</em> and <del>gone</del></p>"},
+		{"2 * 3 * 4 and snake_case_name", "<p>2 * 3 * 4 and snake_case_name</p>"},
+		{"Run `make <all>` now", "<p>Run <code>make &lt;all&gt;</code> now</p>"},
+		{"```go\nif a < b {\n}\n```", "<pre><code class=\"language-go\">if a &lt; b {\n}\n</code></pre>"},
+		{"- one\n- **two**\n  continued\n\n1. first\n2. second", "<ul>\n<li>one</li>\n<li><strong>two</strong><br>\ncontinued</li>\n</ul>\n<ol>\n<li>first</li>\n<li>second</li>\n</ol>"},
+		{"> quoted\n> *text*", "<blockquote>\n<p>quoted<br>\n<em>text</em></p>\n</blockquote>"},
+		{"above\n\n---", "<p>above</p>\n<hr>"},
+		{"See [the docs](https://example.com/a?b=1&c=2)", "<p>See <a href=\"https://example.com/a?b=1&amp;c=2\" rel=\"nofollow\">the docs</a></p>"},
+		{"![logo](https://example.com/logo.png)", "<p><img src=\"https://example.com/logo.png\" alt=\"logo\"></p>"},
+		{"<https://example.com>", "<p><a href=\"https://example.com\" rel=\"nofollow\">https://example.com</a></p>"},
+		{`\*not emphasis\*`, "<p>*not emphasis*</p>"},
+	}
+	for _, c := range cases {
+		// Assert that the source renders as expected
+		assert.Equal(t, c.expected, Render(c.source), c.source)
+	}
+}
+
+// TestRenderSanitizes tests that raw HTML, script URLs and attribute breakouts cannot get into the output
+func TestRenderSanitizes(t *testing.T) {
+	cases := []struct {
+		source   string
+		expected string
+	}{
+		// Raw HTML
+		{"<script>alert(1)</script>", ""},
+		{`<img src=x onerror="alert(1)">`, ""},
+		{"Hi <b onclick=alert(1)>there</b>", "<p>Hi there</p>"},
+		{"<iframe src=\"https://example.com\"></iframe>\n\ntext", "<p>text</p>"},
+		{"<a href=\"javascript:alert(1)\">x</a>", "<p>x</p>"},
+		{"```\"><script>\n\"><script>\n```", "<pre><code>&#34;&gt;&lt;script&gt;\n</code></pre>"},
+
+		// javascript: and data: links
+		{"[click](javascript:alert(1))", "<p>click</p>"},
+		{"[click](JavaScript:alert)", "<p>click</p>"},
+		{"[click]( \tjavascript:alert(1))", "<p>click</p>"},
+		{"<javascript:alert(1)>", "<p>javascript:alert(1)</p>"},
+		{"[x]: javascript:alert(1)\n\n[y][x]", "<p>y</p>"},
+		{"[x](data:text/html;base64,PHNjcmlwdD4=)", "<p>x</p>"},
+		{"![x](data:image/svg+xml;base64,PHN2Zz4=)", "<p><img alt=\"x\"></p>"},
+		{"[x](vbscript:msgbox)", "<p>x</p>"},
+
+		// Entity- and percent-encoded schemes
+		{"[x](&#106;avascript:alert(1))", "<p>x</p>"},
+		{"[x](&#x6A;avascript:alert(1))", "<p>x</p>"},
+		{"[x](java&#x09;script:alert(1))", "<p>x</p>"},
+		{"[x](jav%61script:alert(1))", "<p>x</p>"},
+		{"[x](&#100;ata:text/html,x)", "<p>x</p>"},
+
+		// Attribute breakouts
+		{`[x](https://example.com/"onmouseover="alert)`, "<p><a href=\"https://example.com/%22onmouseover=%22alert\" rel=\"nofollow\">x</a></p>"},
+		{`[x](https://example.com/ "t\" onmouseover=\"alert(1)")`, "<p><a href=\"https://example.com/\" rel=\"nofollow\">x</a></p>"},
+		{`![a" onerror="alert(1)](https://example.com/x.png)`, "<p><img src=\"https://example.com/x.png\"></p>"},
+		{"```go\" onclick=\"alert(1)\nx\n```", "<pre><code>x\n</code></pre>"},
+	}
+	for _, c := range cases {
+		// Assert that the output holds nothing executable
+		html := Render(c.source)
+		assert.Equal(t, c.expected, html, c.source)
+		for _, unsafe := range []string{"<script", "javascript:", "data:", " onerror=", " onclick=", " onmouseover="} {
+			assert.False(t, strings.Contains(strings.ToLower(stripText(html)), unsafe), c.source)
+		}
+	}
+}
+
+// stripText returns the tags of html without the text between them, where e.g. "javascript:" is harmless.
+func stripText(html string) string {
+	var tags strings.Builder
+	inTag := false
+	for _, r := range html {
+		if r == '<' {
+			inTag = true
+		}
+		if inTag {
+			tags.WriteRune(r)
+		}
+		if r == '>' {
+			inTag = false
+		}
+	}
+	return tags.String()
+}
@@ -0,0 +1,64 @@
+// mentions.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package mentions finds the @username mentions in comments.
+package mentions
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mentionPattern matches "@username" when the @ does not follow a word character, so that email
+// addresses such as jane@example.com are not mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_][A-Za-z0-9_.-]*)`)
+
+// Parse returns the usernames mentioned in a text, each once, in the order they first appear. Mentions
+// inside code spans and fenced code blocks are ignored, and a trailing "." or "-", as at the end of a
+// sentence, is not part of the username.
+//
+// Parameters:
+// - text: The text, e.g. the Markdown body of a comment.
+//
+// Returns:
+// - []string: The mentioned usernames, without the @.
+func Parse(text string) []string {
+	usernames := []string{}
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(stripCode(text), -1) {
+		username := strings.TrimRight(match[1], ".-")
+		if username != "" && !seen[username] {
+			seen[username] = true
+			usernames = append(usernames, username)
+		}
+	}
+	return usernames
+}
+
+// stripCode removes fenced code blocks and code spans, in which an @ is code rather than a mention.
+func stripCode(text string) string {
+	var out strings.Builder
+	fenced := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			out.WriteString("\n")
+			continue
+		}
+		if fenced {
+			out.WriteString("\n")
+			continue
+		}
+		parts := strings.Split(line, "`")
+		for i, part := range parts {
+			// Odd parts are inside a span, unless the last backtick is unmatched
+			if i%2 == 0 || i == len(parts)-1 {
+				out.WriteString(part)
+			}
+			out.WriteString(" ")
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
@@ -0,0 +1,29 @@
+// mentions_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package mentions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParse tests that mentions are found once each, outside of code and email addresses
+func TestParse(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected []string
+	}{
+		{"", []string{}},
+		{"@alice please review", []string{"alice"}},
+		{"Thanks @bob.smith, and @alice. Also @bob.smith!", []string{"bob.smith", "alice"}},
+		{"(@carol) and -@dave_1", []string{"carol", "dave_1"}},
+		{"Mail jane@example.com or @@eve", []string{}},
+		{"Run `ssh @host` or\n```\n@frank\n```\nthen ping @grace", []string{"grace"}},
+	}
+	for _, c := range cases {
+		// Assert that the mentioned usernames are returned in order
+		assert.Equal(t, c.expected, Parse(c.text), c.text)
+	}
+}
@@ -0,0 +1,160 @@
+// merge.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package merge
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/bkojha74/task-management/models"
+)
+
+// Fields are the task fields a patch can change, by JSON name. Each write of a task records, per field,
+// the version that last changed it, so that a patch made on an older version only conflicts on the fields
+// written since.
+var Fields = []string{
+	"title", "description", "allotted_to", "done_by", "status", "priority",
+	"start_time", "end_time", "project_id", "estimate_minutes", "visibility", "depends_on",
+}
+
+// Conflict is a patched field that was changed to another value since the version the patch was made on.
+type Conflict struct {
+	Field   string          `json:"field"`
+	Value   json.RawMessage `json:"value"`   // Value of the patch, not applied
+	Current json.RawMessage `json:"current"` // Value on the server, kept
+}
+
+// FieldError is returned for a patch of a field that is not in Fields.
+type FieldError struct {
+	Field string
+}
+
+// Error names the field.
+func (e *FieldError) Error() string {
+	return "field " + e.Field + " cannot be patched"
+}
+
+// Track records the version of a written task in its field versions for every field in Fields whose value
+// differs from the stored task. task.Version must already be the new version.
+//
+// Parameters:
+// - task: The task being written.
+// - previous: The stored task, or nil when it is created.
+func Track(task *models.Task, previous *models.Task) {
+	if previous == nil {
+		return
+	}
+	versions := make(map[string]int, len(previous.FieldVersions))
+	for field, version := range previous.FieldVersions {
+		versions[field] = version
+	}
+
+	written, stored := values(*task), values(*previous)
+	for _, field := range Fields {
+		if !bytes.Equal(written[field], stored[field]) {
+			versions[field] = task.Version
+		}
+	}
+	task.FieldVersions = versions
+}
+
+// Apply merges a patch made on the base version of a task into the current version of the task. A patched
+// field conflicts when it was changed since base and its current value differs from the patch; it keeps
+// the current value. All other patched fields take the value of the patch. Fields changed before their
+// versions were recorded count as unchanged.
+//
+// Parameters:
+// - current: The stored task.
+// - patch: The patched fields by JSON name, with their new JSON values.
+// - base: The version of the task the patch was made on.
+//
+// Returns:
+// - models.Task: The merged task, with the version of current.
+// - []Conflict: The conflicting fields, in the order of Fields.
+// - error: A *FieldError for a field that cannot be patched, or the error of an invalid value.
+func Apply(current models.Task, patch map[string]json.RawMessage, base int) (models.Task, []Conflict, error) {
+	for field := range patch {
+		if !patchable(field) {
+			return current, nil, &FieldError{Field: field}
+		}
+	}
+
+	// Reading the patch into a task validates its values and gives them the format of stored values
+	raw, err := json.Marshal(patch)
+	if err != nil {
+		return current, nil, err
+	}
+	var changes models.Task
+	if err := json.Unmarshal(raw, &changes); err != nil {
+		return current, nil, err
+	}
+
+	merged := current
+	conflicts := []Conflict{}
+	mine, theirs := values(changes), values(current)
+	for _, field := range Fields {
+		if _, ok := patch[field]; !ok {
+			continue
+		}
+		if current.FieldVersions[field] > base && !bytes.Equal(mine[field], theirs[field]) {
+			conflicts = append(conflicts, Conflict{Field: field, Value: mine[field], Current: theirs[field]})
+			continue
+		}
+		copyField(&merged, changes, field)
+	}
+	return merged, conflicts, nil
+}
+
+// patchable reports whether a field is in Fields.
+func patchable(field string) bool {
+	for _, name := range Fields {
+		if name == field {
+			return true
+		}
+	}
+	return false
+}
+
+// values returns the JSON values of the fields of a task by name.
+func values(task models.Task) map[string]json.RawMessage {
+	var fields map[string]json.RawMessage
+	data, err := json.Marshal(task)
+	if err == nil {
+		err = json.Unmarshal(data, &fields)
+	}
+	if err != nil {
+		return nil // Tasks always marshal
+	}
+	return fields
+}
+
+// copyField sets one field of Fields of dst to its value in src.
+func copyField(dst *models.Task, src models.Task, field string) {
+	switch field {
+	case "title":
+		dst.Title = src.Title
+	case "description":
+		dst.Description = src.Description
+	case "allotted_to":
+		dst.AllottedTo = src.AllottedTo
+	case "done_by":
+		dst.DoneBy = src.DoneBy
+	case "status":
+		dst.Status = src.Status
+	case "priority":
+		dst.Priority = src.Priority
+	case "start_time":
+		dst.StartDate = src.StartDate
+	case "end_time":
+		dst.EndDate = src.EndDate
+	case "project_id":
+		dst.ProjectID = src.ProjectID
+	case "estimate_minutes":
+		dst.EstimateMinutes = src.EstimateMinutes
+	case "visibility":
+		dst.Visibility = src.Visibility
+	case "depends_on":
+		dst.DependsOn = src.DependsOn
+	}
+}
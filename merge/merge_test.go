@@ -0,0 +1,87 @@
+// merge_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package merge
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bkojha74/task-management/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTrack tests that writes record their version for the fields they change only
+func TestTrack(t *testing.T) {
+	previous := models.Task{Title: "Draft", Status: models.TaskStatusPending, Version: 1}
+	task := previous
+	task.Title = "Draft v2"
+	task.Rank = "0|i00000:" // Not mergeable
+	task.Version = 2
+	Track(&task, &previous)
+	assert.Equal(t, map[string]int{"title": 2}, task.FieldVersions)
+
+	next := task
+	next.Status = models.TaskStatusDone
+	next.Version = 3
+	Track(&next, &task)
+	assert.Equal(t, map[string]int{"title": 2, "status": 3}, next.FieldVersions)
+	assert.Equal(t, map[string]int{"title": 2}, task.FieldVersions, "the previous versions are copied")
+
+	created := models.Task{Title: "New"}
+	Track(&created, nil)
+	assert.Nil(t, created.FieldVersions)
+}
+
+// TestApply tests that patches keep non-conflicting changes and report fields changed since their base
+func TestApply(t *testing.T) {
+	current := models.Task{
+		Title:         "Book taxi for 6am",
+		Description:   "Airport",
+		Status:        models.TaskStatusPending,
+		Version:       3,
+		FieldVersions: map[string]int{"title": 3, "description": 2},
+	}
+	patch := map[string]json.RawMessage{
+		"title":    json.RawMessage(`"Book cab"`),
+		"status":   json.RawMessage(`"Done"`),
+		"priority": json.RawMessage(`"high"`),
+	}
+
+	// Assert that the title, changed since version 2, conflicts and the other fields are applied
+	merged, conflicts, err := Apply(current, patch, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "Book taxi for 6am", merged.Title)
+	assert.Equal(t, models.TaskStatusDone, merged.Status)
+	assert.Equal(t, models.TaskPriorityHigh, merged.Priority)
+	assert.Equal(t, "Airport", merged.Description)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "title", conflicts[0].Field)
+	assert.JSONEq(t, `"Book cab"`, string(conflicts[0].Value))
+	assert.JSONEq(t, `"Book taxi for 6am"`, string(conflicts[0].Current))
+
+	// Assert that patches on the current version, or agreeing with the server, do not conflict
+	merged, conflicts, err = Apply(current, patch, 3)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+	assert.Equal(t, "Book cab", merged.Title)
+	_, conflicts, err = Apply(current, map[string]json.RawMessage{"title": json.RawMessage(`"Book taxi for 6am"`)}, 1)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	// Assert that null clears timestamps
+	current.EndDate = models.Timestamp(1720000000000)
+	merged, _, err = Apply(current, map[string]json.RawMessage{"end_time": json.RawMessage(`null`)}, 3)
+	require.NoError(t, err)
+	assert.True(t, merged.EndDate.IsZero())
+
+	// Assert that unknown fields and invalid values are rejected
+	_, _, err = Apply(current, map[string]json.RawMessage{"rank": json.RawMessage(`"0|a"`)}, 3)
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "rank", fieldErr.Field)
+	_, _, err = Apply(current, map[string]json.RawMessage{"estimate_minutes": json.RawMessage(`"soon"`)}, 3)
+	assert.Error(t, err)
+}
@@ -0,0 +1,38 @@
+// admin.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AdminOnly restricts a route to users with the admin role.
+// It must be registered after a JWT middleware that sets "userId" in the request context.
+// Non-admin users receive a 403 Forbidden response.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func AdminOnly(c *fiber.Ctx) error {
+	userId, err := primitive.ObjectIDFromHex(fmt.Sprint(c.Locals("userId")))
+	if err != nil {
+		return apierror.Unauthorized(apierror.CodeUnauthorized, "unauthorized")
+	}
+
+	user, err := repository.Users.FindByID(c.UserContext(), userId)
+	if err != nil || user.Role != models.RoleAdmin {
+		return apierror.Forbidden(apierror.CodeForbidden, "admin access required")
+	}
+
+	return c.Next()
+}
@@ -0,0 +1,74 @@
+// anomaly.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/bkojha74/task-management/anomaly"
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Anomalies creates a middleware handler feeding the task requests to an anomaly detector. It must be
+// registered after Protected, whose token it reads. Every flagged pattern is logged and published to admins
+// as an events.SecurityAnomaly event; with Reauthenticate the session of the token is also revoked, so the
+// request and all later ones with the token are rejected until the user signs in again.
+//
+// Parameters:
+// - detector: The detector counting deletes and IPs.
+//
+// Returns:
+// - fiber.Handler: The Fiber middleware handler.
+func Anomalies(detector *anomaly.Detector) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token, ok := c.Locals("user").(*jwt.Token)
+		if !ok {
+			return c.Next() // Guests are read-only
+		}
+		claims := token.Claims.(*utils.AccessClaims)
+
+		if alert := detector.Request(claims.UserID, claims.SessionID, c.IP(), time.Now()); alert != nil {
+			if raiseAnomaly(context.Background(), detector.Config(), *alert) {
+				return apierror.Unauthorized(apierror.CodeInvalidToken, "re-authentication required")
+			}
+		}
+
+		err := c.Next()
+		if c.Method() == fiber.MethodDelete && c.Route().Path == "/tasks/:id" && err == nil && c.Response().StatusCode() < fiber.StatusMultipleChoices {
+			if alert := detector.Delete(claims.UserID, claims.SessionID, time.Now()); alert != nil {
+				raiseAnomaly(context.Background(), detector.Config(), *alert)
+			}
+		}
+		return err
+	}
+}
+
+// raiseAnomaly logs and publishes an alert and, with Reauthenticate, revokes its session. It reports
+// whether the session was revoked.
+func raiseAnomaly(ctx context.Context, config anomaly.Config, alert anomaly.Alert) bool {
+	if config.Reauthenticate && alert.SessionID != "" {
+		userId, userErr := primitive.ObjectIDFromHex(alert.UserID)
+		sessionId, sessionErr := primitive.ObjectIDFromHex(alert.SessionID)
+		if userErr == nil && sessionErr == nil {
+			err := repository.Sessions.Revoke(context.Background(), userId, sessionId, alert.DetectedAt)
+			alert.Reauthenticate = err == nil || err == repository.ErrNotFound // Not found once revoked
+		}
+	}
+
+	log.Printf("Anomaly: %s by user %s (session %s): %d within %s, reauthenticate %t", alert.Kind, alert.UserID, alert.SessionID, alert.Count, alert.Window, alert.Reauthenticate)
+	if err := outbox.Publish(ctx, events.SecurityAnomaly, alert.UserID, alert); err != nil {
+		log.Printf("Could not publish %s anomaly of user %s: %v", alert.Kind, alert.UserID, err)
+	}
+	return alert.Reauthenticate
+}
@@ -0,0 +1,86 @@
+// anomaly_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/anomaly"
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestAnomalies tests that flagged patterns are published and revoke the session with Reauthenticate
+func TestAnomalies(t *testing.T) {
+	repository.UseMemory()
+	defer func(bus *events.Bus) { events.Default = bus }(events.Default)
+	events.Default = events.NewBus()
+	var alerts []anomaly.Alert
+	events.Default.Subscribe(events.SecurityAnomaly, func(ctx context.Context, event events.Event) {
+		alerts = append(alerts, event.Data.(anomaly.Alert))
+	})
+
+	secret := utils.NewSigningSecret("test-secret")
+	detector := anomaly.NewDetector(anomaly.Config{DeleteLimit: 2, IPLimit: 2, Window: time.Minute, Reauthenticate: true})
+	app := fiber.New(fiber.Config{ErrorHandler: apierror.Handler, ProxyHeader: fiber.HeaderXForwardedFor})
+	app.Use("/tasks", Protected(secret), Anomalies(detector))
+	app.Get("/tasks", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	app.Delete("/tasks/:id", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) })
+
+	// signIn starts a session and returns its token
+	userId := primitive.NewObjectID()
+	signIn := func() (string, primitive.ObjectID) {
+		now := time.Now()
+		session := models.Session{UserID: userId, IssuedAt: models.NewTimestamp(now), ExpiresAt: models.NewTimestamp(now.Add(time.Hour))}
+		require.NoError(t, repository.Sessions.Create(context.Background(), &session))
+		token, err := secret.SignToken(utils.Tokens.NewClaims(userId.Hex(), session.ID.Hex(), now, now.Add(time.Hour)))
+		require.NoError(t, err)
+		return token, session.ID
+	}
+	send := func(method, path, token, ip string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set(fiber.HeaderAuthorization, token)
+		req.Header.Set(fiber.HeaderXForwardedFor, ip)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+	revoked := func(id primitive.ObjectID) bool {
+		session, err := repository.Sessions.FindByID(context.Background(), id)
+		require.NoError(t, err)
+		return !session.RevokedAt.IsZero()
+	}
+
+	// Assert that deletes over the limit are flagged and revoke the session
+	token, sessionId := signIn()
+	assert.Equal(t, fiber.StatusNoContent, send(fiber.MethodDelete, "/tasks/1", token, "203.0.113.1"))
+	assert.Equal(t, fiber.StatusNoContent, send(fiber.MethodDelete, "/tasks/2", token, "203.0.113.1"))
+	assert.Empty(t, alerts)
+	assert.Equal(t, fiber.StatusNoContent, send(fiber.MethodDelete, "/tasks/3", token, "203.0.113.1"))
+	require.Len(t, alerts, 1)
+	assert.Equal(t, anomaly.KindMassDelete, alerts[0].Kind)
+	assert.True(t, alerts[0].Reauthenticate)
+	assert.True(t, revoked(sessionId))
+
+	// Assert that a token used from a third IP is flagged and rejected
+	token, sessionId = signIn()
+	assert.Equal(t, fiber.StatusOK, send(fiber.MethodGet, "/tasks", token, "203.0.113.1"))
+	assert.Equal(t, fiber.StatusOK, send(fiber.MethodGet, "/tasks", token, "198.51.100.2"))
+	assert.Equal(t, fiber.StatusUnauthorized, send(fiber.MethodGet, "/tasks", token, "192.0.2.3"))
+	require.Len(t, alerts, 2)
+	assert.Equal(t, anomaly.KindTokenReuse, alerts[1].Kind)
+	assert.Equal(t, sessionId.Hex(), alerts[1].SessionID)
+	assert.True(t, revoked(sessionId))
+}
@@ -0,0 +1,65 @@
+// apikey.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/bkojha74/task-management/apierror"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIKeyHeader is the header no-code automation platforms such as Zapier and IFTTT send an API key in.
+const APIKeyHeader = "X-API-Key"
+
+// APIKey lets clients send an API token in the X-API-Key header instead of the Authorization header, the
+// way API-key authentication of automation platforms is configured. It must be registered before
+// utils.JWTMiddleware, which then validates the token and its scopes as usual. A token in the
+// Authorization header takes precedence.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func APIKey(c *fiber.Ctx) error {
+	if key := c.Get(APIKeyHeader); key != "" && c.Get(fiber.HeaderAuthorization) == "" {
+		c.Request().Header.Set(fiber.HeaderAuthorization, key)
+	}
+	return c.Next()
+}
+
+// BasicAPIToken lets clients that only support HTTP Basic authentication, such as CalDAV task apps, send an
+// API token as the password; the user name is not checked, as the token identifies the user. It must be
+// registered before utils.JWTMiddleware, and asks clients for credentials on 401 responses.
+//
+// Parameters:
+// - realm: The realm of the WWW-Authenticate challenge.
+//
+// Returns:
+// - fiber.Handler: The Fiber middleware handler for Basic authentication.
+func BasicAPIToken(realm string) fiber.Handler {
+	challenge := `Basic realm="` + realm + `", charset="UTF-8"`
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		if scheme, credentials, ok := strings.Cut(header, " "); ok && strings.EqualFold(scheme, "Basic") {
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(credentials))
+			_, password, ok := strings.Cut(string(decoded), ":")
+			if err != nil || !ok {
+				password = ""
+			}
+			c.Request().Header.Set(fiber.HeaderAuthorization, password)
+		}
+
+		err := c.Next()
+		var apiErr *apierror.Error
+		if errors.As(err, &apiErr) && apiErr.Status == fiber.StatusUnauthorized {
+			c.Set(fiber.HeaderWWWAuthenticate, challenge)
+		}
+		return err
+	}
+}
@@ -0,0 +1,60 @@
+// audit.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/bkojha74/task-management/audit"
+	"github.com/bkojha74/task-management/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Audit creates a middleware handler recording every mutating request (POST, PUT, PATCH and DELETE) to
+// an audit sink once it is answered: method, path, user, client IP, status, duration, as configured the
+// request body, and the outcome the handler stored under audit.DetailsKey. Errors are rendered by the
+// app's error handler first, so the recorded status is the one the client received. A failed write is
+// logged and does not fail the request.
+//
+// Parameters:
+// - sink: Where the entries are stored.
+// - config: What is recorded of the request body.
+//
+// Returns:
+// - fiber.Handler: The Fiber middleware handler for audit logging.
+func Audit(sink audit.Sink, config audit.Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if safeMethod(c.Method()) {
+			return c.Next()
+		}
+
+		start := time.Now()
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		userId, _ := c.Locals("userId").(string)
+		entry := audit.Entry{
+			Time:       models.NewTimestamp(start),
+			RequestID:  c.GetRespHeader(fiber.HeaderXRequestID),
+			Method:     c.Method(),
+			Path:       c.Path(),
+			UserID:     userId,
+			IP:         c.IP(),
+			Status:     c.Response().StatusCode(),
+			DurationMs: time.Since(start).Milliseconds(),
+			Body:       config.RecordBody(c.Body()),
+			Details:    c.Locals(audit.DetailsKey),
+		}
+		if err := sink.Write(context.Background(), entry); err != nil {
+			log.Printf("Error writing audit entry for %s %s: %v", entry.Method, entry.Path, err)
+		}
+		return nil
+	}
+}
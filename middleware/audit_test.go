@@ -0,0 +1,83 @@
+// audit_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/audit"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink keeps the written audit entries in memory.
+type recordingSink struct {
+	entries []audit.Entry
+}
+
+func (s *recordingSink) Write(ctx context.Context, entry audit.Entry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *recordingSink) Close() error {
+	return nil
+}
+
+// TestAudit tests that mutating requests are recorded with the status the client received
+func TestAudit(t *testing.T) {
+	sink := &recordingSink{}
+	app := fiber.New(fiber.Config{ErrorHandler: apierror.Handler})
+	app.Use(Audit(sink, audit.Config{Bodies: audit.BodiesRedacted, Redact: audit.DefaultRedact}))
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userId", "66a1")
+		return c.Next()
+	})
+	app.Get("/tasks", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	app.Post("/tasks", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusCreated) })
+	app.Delete("/tasks/:id", func(c *fiber.Ctx) error { return apierror.NotFound(apierror.CodeNotFound, "Task not found") })
+	app.Patch("/tasks", func(c *fiber.Ctx) error {
+		c.Locals(audit.DetailsKey, fiber.Map{"updated": 2})
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	send := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	// Assert that reads are not recorded
+	assert.Equal(t, fiber.StatusOK, send(fiber.MethodGet, "/tasks", ""))
+	assert.Empty(t, sink.entries)
+
+	// Assert that writes are recorded with the user, status and redacted body
+	assert.Equal(t, fiber.StatusCreated, send(fiber.MethodPost, "/tasks", `{"title":"Audit","token":"abc"}`))
+	require.Len(t, sink.entries, 1)
+	entry := sink.entries[0]
+	assert.Equal(t, "POST", entry.Method)
+	assert.Equal(t, "/tasks", entry.Path)
+	assert.Equal(t, "66a1", entry.UserID)
+	assert.Equal(t, fiber.StatusCreated, entry.Status)
+	assert.Equal(t, map[string]interface{}{"title": "Audit", "token": audit.Redacted}, entry.Body)
+
+	// Assert that failed requests are recorded with the status of the rendered error
+	assert.Equal(t, fiber.StatusNotFound, send(fiber.MethodDelete, "/tasks/1", ""))
+	require.Len(t, sink.entries, 2)
+	assert.Equal(t, fiber.StatusNotFound, sink.entries[1].Status)
+	assert.Nil(t, sink.entries[1].Details)
+
+	// Assert that the outcome stored by the handler is recorded
+	assert.Equal(t, fiber.StatusOK, send(fiber.MethodPatch, "/tasks?filter=status:open", `{"priority":"high"}`))
+	require.Len(t, sink.entries, 3)
+	assert.Equal(t, fiber.Map{"updated": 2}, sink.entries[2].Details)
+}
@@ -0,0 +1,35 @@
+// bearer.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/bkojha74/task-management/apierror"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BearerToken restricts routes to clients sending a static token as "Authorization: Bearer <token>", e.g.
+// the identity provider calling the SCIM endpoints. Tokens are compared in constant time.
+//
+// Parameters:
+// - token: The token clients must send.
+//
+// Returns:
+// - fiber.Handler: The Fiber middleware handler for bearer token authentication.
+func BearerToken(token string) fiber.Handler {
+	expected := sha256.Sum256([]byte(token))
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		scheme, sent, ok := strings.Cut(header, " ")
+		given := sha256.Sum256([]byte(strings.TrimSpace(sent)))
+		if !ok || !strings.EqualFold(scheme, "Bearer") || subtle.ConstantTimeCompare(given[:], expected[:]) != 1 {
+			return apierror.Unauthorized(apierror.CodeInvalidToken, "invalid bearer token")
+		}
+		return c.Next()
+	}
+}
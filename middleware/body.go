@@ -0,0 +1,45 @@
+// body.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"mime"
+	"strings"
+
+	"github.com/bkojha74/task-management/apierror"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DefaultBodyLimit is the default maximum request body size in bytes.
+const DefaultBodyLimit = 1024 * 1024
+
+// RequireJSON creates a middleware handler rejecting request bodies that are not JSON with
+// 415 Unsupported Media Type, before they reach BodyParser. Requests without a body pass.
+//
+// Parameters:
+// - exempt: Path prefixes accepting other bodies, e.g. the SAML assertion consumer service, which
+// identity providers post a form to.
+//
+// Returns:
+// - fiber.Handler: The Fiber middleware handler for content-type enforcement.
+func RequireJSON(exempt ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(c.Body()) == 0 || exemptPath(c.Path(), exempt) {
+			return c.Next()
+		}
+
+		mediaType, _, err := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+		if err != nil || !isJSONMediaType(mediaType) {
+			return apierror.New(fiber.StatusUnsupportedMediaType, apierror.CodeUnsupportedMediaType, "Content-Type must be application/json")
+		}
+		return c.Next()
+	}
+}
+
+// isJSONMediaType accepts application/json and structured JSON types such as application/merge-patch+json.
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == fiber.MIMEApplicationJSON ||
+		(strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
@@ -0,0 +1,56 @@
+// body_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bkojha74/task-management/apierror"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRequestBodyLimits tests the body size limit and the JSON content-type enforcement
+func TestRequestBodyLimits(t *testing.T) {
+	app := fiber.New(fiber.Config{BodyLimit: 64, ErrorHandler: apierror.Handler})
+	app.Use(RequireJSON("/form"))
+	app.Post("/", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	app.Post("/form", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	sendTo := func(path, contentType, body string) int {
+		req := httptest.NewRequest(fiber.MethodPost, path, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set(fiber.HeaderContentType, contentType)
+		}
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp.StatusCode
+	}
+	send := func(contentType, body string) int {
+		return sendTo("/", contentType, body)
+	}
+
+	// Assert that JSON bodies and bodiless requests pass
+	assert.Equal(t, fiber.StatusOK, send("application/json", `{"title":"x"}`))
+	assert.Equal(t, fiber.StatusOK, send("application/json; charset=utf-8", `{"title":"x"}`))
+	assert.Equal(t, fiber.StatusOK, send("application/merge-patch+json", `{"title":"x"}`))
+	assert.Equal(t, fiber.StatusOK, send("", ""))
+
+	// Assert that other content types are rejected with 415
+	assert.Equal(t, fiber.StatusUnsupportedMediaType, send("text/plain", `{"title":"x"}`))
+	assert.Equal(t, fiber.StatusUnsupportedMediaType, send("", `{"title":"x"}`))
+	assert.Equal(t, fiber.StatusUnsupportedMediaType, send("application/x-www-form-urlencoded", "title=x"))
+
+	// Assert that exempt paths accept other content types
+	assert.Equal(t, fiber.StatusOK, sendTo("/form", "application/x-www-form-urlencoded", "title=x"))
+
+	// Assert that oversized bodies are rejected before reaching any handler (the server answers 413)
+	req := httptest.NewRequest(fiber.MethodPost, "/", strings.NewReader(`{"title":"`+strings.Repeat("x", 100)+`"}`))
+	req.Header.Set(fiber.HeaderContentType, "application/json")
+	_, err := app.Test(req)
+	assert.Error(t, err)
+}
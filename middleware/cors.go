@@ -0,0 +1,124 @@
+// cors.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/bkojha74/task-management/environment"
+	"github.com/bkojha74/task-management/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+)
+
+// CORSConfig is the cross-origin policy of the API.
+type CORSConfig struct {
+	AllowOrigins     []string // Allowed origins; "https://*.example.com" allows all subdomains, "*" any origin
+	AllowMethods     string   // Comma-separated allowed methods
+	AllowHeaders     string   // Comma-separated allowed request headers
+	ExposeHeaders    string   // Comma-separated response headers readable by the browser
+	AllowCredentials bool     // Whether cookies and Authorization headers may be sent cross-origin
+	MaxAge           int      // Seconds a preflight response may be cached
+}
+
+// LoadCORSConfig reads the CORS policy from the CORS_ALLOW_ORIGINS, CORS_ALLOW_METHODS, CORS_ALLOW_HEADERS,
+// CORS_EXPOSE_HEADERS, CORS_ALLOW_CREDENTIALS and CORS_MAX_AGE environment variables.
+// Without CORS_ALLOW_ORIGINS no origins are allowed and cross-origin requests are not answered, except for
+// the local frontend dev servers when APP_ENV is development.
+//
+// Returns:
+// - CORSConfig: The configured policy.
+func LoadCORSConfig() CORSConfig {
+	config := CORSConfig{
+		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
+		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,If-Match,X-Response-Envelope",
+		ExposeHeaders:    "ETag,Warning,X-Next-Cursor,X-Request-ID,X-RateLimit-Limit,X-RateLimit-Remaining,X-RateLimit-Reset",
+		AllowCredentials: helper.GetEnv("CORS_ALLOW_CREDENTIALS") == "true",
+		MaxAge:           helper.GetEnvInt("CORS_MAX_AGE", 600),
+	}
+	for _, origin := range strings.Split(environment.CORSOrigins(), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			config.AllowOrigins = append(config.AllowOrigins, strings.TrimSuffix(origin, "/"))
+		}
+	}
+	if methods := helper.GetEnv("CORS_ALLOW_METHODS"); methods != "" {
+		config.AllowMethods = methods
+	}
+	if headers := helper.GetEnv("CORS_ALLOW_HEADERS"); headers != "" {
+		config.AllowHeaders = headers
+	}
+	if headers := helper.GetEnv("CORS_EXPOSE_HEADERS"); headers != "" {
+		config.ExposeHeaders = headers
+	}
+	return config
+}
+
+// Validate rejects policies that would let any website make credentialed requests.
+func (config CORSConfig) Validate() error {
+	if !config.AllowCredentials {
+		return nil
+	}
+	for _, origin := range config.AllowOrigins {
+		if origin == "*" {
+			return errors.New("CORS_ALLOW_CREDENTIALS cannot be combined with the \"*\" origin")
+		}
+	}
+	return nil
+}
+
+// Allowed reports whether an Origin header value matches one of the allowed origins.
+// Wildcard patterns match any subdomain, but not the bare domain, with the same scheme and port.
+func (config CORSConfig) Allowed(origin string) bool {
+	for _, pattern := range config.AllowOrigins {
+		if pattern == "*" || strings.EqualFold(pattern, origin) || matchWildcardOrigin(pattern, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// CORS creates a middleware handler answering preflight requests and setting the
+// Access-Control-* headers for allowed origins.
+//
+// Parameters:
+// - config: The CORS policy to apply.
+//
+// Returns:
+// - fiber.Handler: The Fiber middleware handler for CORS.
+func CORS(config CORSConfig) fiber.Handler {
+	return cors.New(cors.Config{
+		AllowOriginsFunc: config.Allowed,
+		AllowMethods:     config.AllowMethods,
+		AllowHeaders:     config.AllowHeaders,
+		ExposeHeaders:    config.ExposeHeaders,
+		AllowCredentials: config.AllowCredentials,
+		MaxAge:           config.MaxAge,
+	})
+}
+
+// matchWildcardOrigin matches origins such as "https://app.example.com" against "https://*.example.com".
+func matchWildcardOrigin(pattern, origin string) bool {
+	if !strings.Contains(pattern, "://*.") {
+		return false
+	}
+
+	patternURL, err := url.Parse(strings.Replace(pattern, "*.", "wildcard.", 1))
+	if err != nil {
+		return false
+	}
+	originURL, err := url.Parse(origin)
+	if err != nil || originURL.Path != "" {
+		return false
+	}
+
+	suffix := strings.TrimPrefix(patternURL.Hostname(), "wildcard")
+	host := strings.ToLower(originURL.Hostname())
+	return strings.EqualFold(patternURL.Scheme, originURL.Scheme) &&
+		patternURL.Port() == originURL.Port() &&
+		len(host) > len(suffix) &&
+		strings.HasSuffix(host, strings.ToLower(suffix))
+}
@@ -0,0 +1,74 @@
+// cors_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCORSAllowed tests exact and wildcard origin matching
+func TestCORSAllowed(t *testing.T) {
+	config := CORSConfig{AllowOrigins: []string{"https://app.example.com", "https://*.example.org", "http://*.local.test:3000"}}
+
+	// Assert that exact origins match case-insensitively
+	assert.True(t, config.Allowed("https://app.example.com"))
+	assert.True(t, config.Allowed("https://APP.example.com"))
+	assert.False(t, config.Allowed("http://app.example.com"))
+	assert.False(t, config.Allowed("https://evil.example.com"))
+
+	// Assert that wildcards match subdomains with the same scheme and port, but not the bare domain
+	assert.True(t, config.Allowed("https://a.example.org"))
+	assert.True(t, config.Allowed("https://a.b.example.org"))
+	assert.False(t, config.Allowed("https://example.org"))
+	assert.False(t, config.Allowed("https://evilexample.org"))
+	assert.False(t, config.Allowed("http://a.example.org"))
+	assert.True(t, config.Allowed("http://dev.local.test:3000"))
+	assert.False(t, config.Allowed("http://dev.local.test:4000"))
+
+	// Assert that "*" allows any origin
+	assert.True(t, CORSConfig{AllowOrigins: []string{"*"}}.Allowed("https://anything.test"))
+}
+
+// TestCORSValidate tests that credentials cannot be combined with the "*" origin
+func TestCORSValidate(t *testing.T) {
+	assert.NoError(t, CORSConfig{AllowOrigins: []string{"*"}}.Validate())
+	assert.NoError(t, CORSConfig{AllowOrigins: []string{"https://*.example.org"}, AllowCredentials: true}.Validate())
+	assert.Error(t, CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true}.Validate())
+}
+
+// TestCORSHeaders tests the headers set on a preflight request
+func TestCORSHeaders(t *testing.T) {
+	app := fiber.New()
+	app.Use(CORS(CORSConfig{
+		AllowOrigins:     []string{"https://*.example.org"},
+		AllowMethods:     "GET,POST",
+		AllowHeaders:     "Authorization",
+		AllowCredentials: true,
+	}))
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	req := httptest.NewRequest(fiber.MethodOptions, "/", nil)
+	req.Header.Set(fiber.HeaderOrigin, "https://app.example.org")
+	req.Header.Set(fiber.HeaderAccessControlRequestMethod, fiber.MethodGet)
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+
+	// Assert that the allowed origin is echoed back with credentials
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "https://app.example.org", resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "true", resp.Header.Get(fiber.HeaderAccessControlAllowCredentials))
+	assert.Equal(t, "GET,POST", resp.Header.Get(fiber.HeaderAccessControlAllowMethods))
+
+	// Assert that other origins get no CORS headers
+	req = httptest.NewRequest(fiber.MethodOptions, "/", nil)
+	req.Header.Set(fiber.HeaderOrigin, "https://evil.test")
+	req.Header.Set(fiber.HeaderAccessControlRequestMethod, fiber.MethodGet)
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+}
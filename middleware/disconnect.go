@@ -0,0 +1,104 @@
+// disconnect.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrClientDisconnected is the cause of request contexts cancelled because the client closed the connection.
+var ErrClientDisconnected = errors.New("client disconnected")
+
+// peerState is what a look at a connection tells about the client.
+type peerState int
+
+const (
+	peerOpen    peerState = iota // Connected, nothing sent since the request
+	peerClosed                   // Closed or reset the connection
+	peerUnknown                  // Sent more data, e.g. a pipelined request, or the connection cannot be inspected
+)
+
+// CancelOnDisconnect creates a middleware handler giving each request a context that is cancelled when the
+// client closes the connection while the request is served, and when the request is done. Handlers pass
+// c.UserContext() to MongoDB queries and downstream calls, which then stop instead of working for a client
+// that is gone. The connection is checked every interval by peeking at it, without consuming a byte; where
+// that is not possible (in-memory test connections, Windows) the context is only cancelled at the end.
+// Server errors of requests cancelled that way become 499 client_closed_request instead of 500.
+//
+// Parameters:
+// - interval: How often a running request checks its connection; 0 disables the check.
+//
+// Returns:
+// - fiber.Handler: The Fiber middleware handler for disconnect cancellation.
+func CancelOnDisconnect(interval time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithCancelCause(c.UserContext())
+		defer cancel(context.Canceled)
+		c.SetUserContext(ctx)
+
+		if conn := rawConn(c.Context().Conn()); interval > 0 && conn != nil {
+			done, stopped := make(chan struct{}), make(chan struct{})
+			go func() {
+				defer close(stopped)
+				watchPeer(conn, interval, done, cancel)
+			}()
+			defer func() {
+				close(done)
+				<-stopped // Done peeking before the server reads the next request
+			}()
+		}
+		err := c.Next()
+
+		// Queries fail once the context is cancelled, which is no server error
+		var apiErr *apierror.Error
+		if err != nil && context.Cause(ctx) == ErrClientDisconnected && (!errors.As(err, &apiErr) || apiErr.Status >= fiber.StatusInternalServerError) {
+			return apierror.New(apierror.StatusClientClosedRequest, apierror.CodeClientClosedRequest, "client closed request")
+		}
+		return err
+	}
+}
+
+// watchPeer cancels with ErrClientDisconnected once the peer of conn has closed it, until done is closed.
+func watchPeer(conn syscall.RawConn, interval time.Duration, done <-chan struct{}, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		switch peek(conn) {
+		case peerClosed:
+			cancel(ErrClientDisconnected)
+			return
+		case peerUnknown:
+			return
+		}
+	}
+}
+
+// rawConn returns the socket of a connection, unwrapping TLS, or nil if it has none.
+func rawConn(conn net.Conn) syscall.RawConn {
+	if tlsConn, ok := conn.(interface{ NetConn() net.Conn }); ok {
+		conn = tlsConn.NetConn()
+	}
+	socket, ok := conn.(syscall.Conn)
+	if !ok {
+		return nil
+	}
+	raw, err := socket.SyscallConn()
+	if err != nil {
+		return nil
+	}
+	return raw
+}
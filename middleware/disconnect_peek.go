@@ -0,0 +1,28 @@
+// disconnect_peek.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+//go:build !windows
+
+package middleware
+
+import "syscall"
+
+// peek looks at the next byte of a connection without consuming it or blocking.
+func peek(conn syscall.RawConn) peerState {
+	var n int
+	var err error
+	buf := make([]byte, 1)
+	controlErr := conn.Read(func(fd uintptr) bool {
+		n, _, err = syscall.Recvfrom(int(fd), buf, syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+		return true // Never wait for data
+	})
+	switch {
+	case controlErr != nil:
+		return peerUnknown
+	case err == syscall.EAGAIN || err == syscall.EWOULDBLOCK || err == syscall.EINTR:
+		return peerOpen
+	case err != nil, n == 0:
+		return peerClosed
+	}
+	return peerUnknown
+}
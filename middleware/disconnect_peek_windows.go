@@ -0,0 +1,11 @@
+// disconnect_peek_windows.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import "syscall"
+
+// peek cannot inspect connections on Windows; request contexts are only cancelled when the request is done.
+func peek(conn syscall.RawConn) peerState {
+	return peerUnknown
+}
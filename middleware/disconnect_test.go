@@ -0,0 +1,88 @@
+// disconnect_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCancelOnDisconnect tests that the request context is cancelled when the client disconnects, and only then
+func TestCancelOnDisconnect(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("connections cannot be inspected on Windows")
+	}
+
+	causes := make(chan error, 1)
+	errored := make(chan int, 1)
+	app := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			status := fiber.StatusInternalServerError
+			if apiErr, ok := err.(*apierror.Error); ok {
+				status = apiErr.Status
+			}
+			errored <- status
+			return apierror.Handler(c, err)
+		},
+	})
+	app.Use(CancelOnDisconnect(10 * time.Millisecond))
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		select {
+		case <-c.UserContext().Done():
+			causes <- context.Cause(c.UserContext())
+			return c.UserContext().Err() // What a cancelled query returns
+		case <-time.After(200 * time.Millisecond):
+			causes <- nil
+			return c.SendString("done")
+		}
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = app.Listener(listener) }()
+	defer func() { _ = app.Shutdown() }()
+
+	// Assert that a client waiting for the response gets it, over a connection kept alive
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	reader := bufio.NewReader(conn)
+	for i := 0; i < 2; i++ {
+		_, err = io.WriteString(conn, "GET /slow HTTP/1.1\r\nHost: localhost\r\n\r\n")
+		require.NoError(t, err)
+		resp, err := http.ReadResponse(reader, nil)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "done", string(body))
+		assert.NoError(t, <-causes)
+	}
+	require.NoError(t, conn.Close())
+
+	// Assert that the request of a client closing the connection is cancelled and answered with 499
+	conn, err = net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	_, err = io.WriteString(conn, "GET /slow HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond) // Let the server start the request
+	require.NoError(t, conn.Close())
+	select {
+	case cause := <-causes:
+		assert.ErrorIs(t, cause, ErrClientDisconnected)
+	case <-time.After(time.Second):
+		t.Fatal("the request was not cancelled")
+	}
+	assert.Equal(t, apierror.StatusClientClosedRequest, <-errored)
+}
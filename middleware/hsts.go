@@ -0,0 +1,33 @@
+// hsts.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HSTS creates a middleware handler sending the Strict-Transport-Security header on HTTPS responses,
+// telling browsers to only use HTTPS for the host from now on.
+//
+// Parameters:
+// - maxAge: Seconds browsers remember the policy; 0 disables the header.
+// - includeSubdomains: Whether the policy also applies to all subdomains.
+//
+// Returns:
+// - fiber.Handler: The Fiber middleware handler for HSTS.
+func HSTS(maxAge int, includeSubdomains bool) fiber.Handler {
+	value := "max-age=" + strconv.Itoa(maxAge)
+	if includeSubdomains {
+		value += "; includeSubDomains"
+	}
+
+	return func(c *fiber.Ctx) error {
+		if maxAge > 0 && c.Protocol() == "https" {
+			c.Set(fiber.HeaderStrictTransportSecurity, value)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,106 @@
+// maintenance.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maintenanceMessage is returned to rejected clients when the admin set no message.
+const maintenanceMessage = "the service is under maintenance, try again later"
+
+// Maintenance creates a middleware handler rejecting requests with 503 Service Unavailable and a
+// Retry-After header while the API is in maintenance: writes in read-only mode, everything in full mode.
+// The mode is read from repository.Maintenance at most once per refresh interval, so switching it takes
+// effect on every instance within that interval; if the store cannot be read, the last known mode is kept.
+//
+// Parameters:
+// - refresh: How long the mode read from the store is used; 0 reads it on every request.
+// - exempt: Path prefixes served in any mode, e.g. the health probes and the admin endpoints that switch
+// maintenance off again.
+//
+// Returns:
+// - fiber.Handler: The Fiber middleware handler for maintenance mode.
+func Maintenance(refresh time.Duration, exempt ...string) fiber.Handler {
+	var (
+		mu      sync.Mutex
+		current = models.Maintenance{Mode: models.MaintenanceOff}
+		fetched time.Time
+	)
+
+	// mode returns the maintenance mode, reading it from the store when the cached one is stale
+	mode := func() models.Maintenance {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !fetched.IsZero() && time.Since(fetched) < refresh {
+			return current
+		}
+		maintenance, err := repository.Maintenance.Get(context.Background())
+		if err != nil {
+			log.Printf("Error reading maintenance mode, keeping %q: %v", current.Mode, err)
+		} else {
+			current = maintenance
+		}
+		fetched = time.Now()
+		return current
+	}
+
+	return func(c *fiber.Ctx) error {
+		if exemptPath(c.Path(), exempt) {
+			return c.Next()
+		}
+
+		maintenance := mode()
+		switch maintenance.Mode {
+		case models.MaintenanceFull:
+		case models.MaintenanceReadOnly:
+			if safeMethod(c.Method()) {
+				return c.Next()
+			}
+		default:
+			return c.Next()
+		}
+
+		if maintenance.RetryAfter > 0 {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(maintenance.RetryAfter))
+		}
+		message := maintenance.Message
+		if message == "" {
+			message = maintenanceMessage
+		}
+		return apierror.New(fiber.StatusServiceUnavailable, apierror.CodeMaintenance, message).
+			WithDetails(fiber.Map{"mode": maintenance.Mode})
+	}
+}
+
+// safeMethod reports whether a request method only reads, including the WebDAV PROPFIND and REPORT.
+func safeMethod(method string) bool {
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions, "PROPFIND", "REPORT":
+		return true
+	}
+	return false
+}
+
+// exemptPath reports whether path is one of the prefixes or below one of them.
+func exemptPath(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
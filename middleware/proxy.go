@@ -0,0 +1,116 @@
+// proxy.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/bkojha74/task-management/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ProxyConfig describes the reverse proxies, such as nginx or a load balancer, in front of the API, so that
+// the rate limiter, sessions and request logs see the client's IP instead of the proxy's.
+type ProxyConfig struct {
+	Header         string   // Header the proxies put the client IP in, e.g. "X-Real-IP"; empty uses the peer address
+	TrustedProxies []string // IPs and CIDRs of the proxies whose Header is believed; requests from others keep their peer address
+}
+
+// LoadProxyConfig reads the proxy settings from the PROXY_HEADER and TRUSTED_PROXIES (comma-separated IPs and
+// CIDRs) environment variables.
+//
+// Returns:
+// - ProxyConfig: The configured proxy settings.
+func LoadProxyConfig() ProxyConfig {
+	return ProxyConfig{
+		Header:         strings.TrimSpace(helper.GetEnv("PROXY_HEADER")),
+		TrustedProxies: splitList(helper.GetEnv("TRUSTED_PROXIES")),
+	}
+}
+
+// Validate rejects a proxy header without trusted proxies, which would let any client choose its IP, and
+// malformed proxy addresses.
+func (config ProxyConfig) Validate() error {
+	if config.Header != "" && len(config.TrustedProxies) == 0 {
+		return errors.New("PROXY_HEADER requires TRUSTED_PROXIES, otherwise any client can choose its IP")
+	}
+	_, err := ParseNetworks(config.TrustedProxies)
+	return err
+}
+
+// Apply sets up a Fiber configuration to read client IPs from the header of trusted proxies. Invalid IPs
+// in the header are skipped.
+//
+// Parameters:
+// - fiberConfig: The configuration the app is created with.
+func (config ProxyConfig) Apply(fiberConfig *fiber.Config) {
+	if config.Header == "" {
+		return
+	}
+	fiberConfig.ProxyHeader = config.Header
+	fiberConfig.EnableTrustedProxyCheck = true
+	fiberConfig.TrustedProxies = config.TrustedProxies
+	fiberConfig.EnableIPValidation = true
+}
+
+// ParseNetworks parses IPs and CIDRs; a bare IP is a network of that address only.
+//
+// Parameters:
+// - entries: The IPs and CIDRs, e.g. "10.0.0.0/8" or "203.0.113.7".
+//
+// Returns:
+// - []*net.IPNet: The networks.
+// - error: An error naming the first malformed entry.
+func ParseNetworks(entries []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q", entry)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// inNetworks reports whether ip is in one of the networks.
+func inNetworks(networks []*net.IPNet, ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList splits a comma-separated list, dropping blank entries.
+func splitList(value string) []string {
+	list := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
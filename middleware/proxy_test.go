@@ -0,0 +1,61 @@
+// proxy_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestProxyConfigValidate tests that a proxy header needs trusted proxies and that addresses are checked
+func TestProxyConfigValidate(t *testing.T) {
+	// Assert that IPs and CIDRs of both families are accepted
+	assert.NoError(t, ProxyConfig{}.Validate())
+	assert.NoError(t, ProxyConfig{Header: "X-Real-IP", TrustedProxies: []string{"10.0.0.0/8", "127.0.0.1", "::1", "fd00::/8"}}.Validate())
+
+	// Assert that a header alone and malformed addresses are rejected
+	assert.Error(t, ProxyConfig{Header: "X-Real-IP"}.Validate())
+	assert.Error(t, ProxyConfig{Header: "X-Real-IP", TrustedProxies: []string{"10.0.0.0/33"}}.Validate())
+	assert.Error(t, RateLimitTier{Name: "auth", Exempt: []string{"nginx"}}.Validate())
+
+	// Assert that a bare IP covers only itself
+	networks, err := ParseNetworks([]string{"203.0.113.7"})
+	require.NoError(t, err)
+	assert.True(t, inNetworks(networks, "203.0.113.7"))
+	assert.False(t, inNetworks(networks, "203.0.113.8"))
+}
+
+// TestRateLimitBehindProxy tests that clients behind a trusted proxy are limited by their own IP and exempt clients not at all
+func TestRateLimitBehindProxy(t *testing.T) {
+	// app.Test connects from 0.0.0.0, which plays the proxy
+	fiberConfig := fiber.Config{ErrorHandler: apierror.Handler}
+	ProxyConfig{Header: "X-Real-IP", TrustedProxies: []string{"0.0.0.0"}}.Apply(&fiberConfig)
+	app := fiber.New(fiberConfig)
+	app.Use(RateLimit(RateLimitTier{Name: "auth", Max: 1, Window: time.Hour, Exempt: []string{"198.51.100.0/24"}}))
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendString(c.IP()) })
+
+	status := func(clientIP string) int {
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		req.Header.Set("X-Real-IP", clientIP)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	// Assert that each client behind the proxy has its own budget
+	assert.Equal(t, fiber.StatusOK, status("203.0.113.1"))
+	assert.Equal(t, fiber.StatusTooManyRequests, status("203.0.113.1"))
+	assert.Equal(t, fiber.StatusOK, status("203.0.113.2"))
+
+	// Assert that exempt clients are never limited
+	assert.Equal(t, fiber.StatusOK, status("198.51.100.9"))
+	assert.Equal(t, fiber.StatusOK, status("198.51.100.9"))
+}
@@ -0,0 +1,88 @@
+// ratelimit.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+// RateLimitTier is the request budget applied to a group of routes.
+type RateLimitTier struct {
+	Name   string        // Name of the route group, e.g. "auth"
+	Max    int           // Maximum number of requests per window; 0 disables the limit
+	Window time.Duration // Length of the rate limit window
+	Exempt []string      // IPs and CIDRs of clients the limit does not apply to, e.g. monitoring or an office network
+}
+
+// LoadRateLimitTier reads the tier of a route group from the RATE_LIMIT_<NAME>_MAX,
+// RATE_LIMIT_<NAME>_WINDOW (seconds) and RATE_LIMIT_<NAME>_EXEMPT (comma-separated IPs and CIDRs)
+// environment variables, falling back to the given defaults.
+//
+// Parameters:
+// - name: The route group name, used in the environment variable names (e.g. "AUTH").
+// - defaultMax: The default number of requests per window.
+// - defaultWindow: The default window length.
+//
+// Returns:
+// - RateLimitTier: The configured tier.
+func LoadRateLimitTier(name string, defaultMax int, defaultWindow time.Duration) RateLimitTier {
+	return RateLimitTier{
+		Name:   name,
+		Max:    helper.GetEnvInt("RATE_LIMIT_"+name+"_MAX", defaultMax),
+		Window: time.Duration(helper.GetEnvInt("RATE_LIMIT_"+name+"_WINDOW", int(defaultWindow/time.Second))) * time.Second,
+		Exempt: splitList(helper.GetEnv("RATE_LIMIT_" + name + "_EXEMPT")),
+	}
+}
+
+// Validate rejects malformed exempt networks.
+func (tier RateLimitTier) Validate() error {
+	if _, err := ParseNetworks(tier.Exempt); err != nil {
+		return fmt.Errorf("RATE_LIMIT_%s_EXEMPT: %w", strings.ToUpper(tier.Name), err)
+	}
+	return nil
+}
+
+// RateLimit creates a middleware handler enforcing the tier per client IP, except for exempt clients.
+// Every response carries the X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers;
+// requests over the limit receive 429 Too Many Requests with a Retry-After header.
+//
+// Parameters:
+// - tier: The rate limit tier to enforce.
+//
+// Returns:
+// - fiber.Handler: The Fiber middleware handler for rate limiting.
+func RateLimit(tier RateLimitTier) fiber.Handler {
+	exempt, _ := ParseNetworks(tier.Exempt) // Checked by Validate
+
+	return limiter.New(limiter.Config{
+		Max:        tier.Max,
+		Expiration: tier.Window,
+
+		// Skip the limiter entirely when the tier is disabled or the client is exempt
+		Next: func(c *fiber.Ctx) bool {
+			return tier.Max <= 0 || inNetworks(exempt, c.IP())
+		},
+
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return tier.Name + ":" + c.IP()
+		},
+
+		// LimitReached keeps the X-RateLimit-* headers on rejected requests, which the limiter only sets on allowed ones.
+		LimitReached: func(c *fiber.Ctx) error {
+			c.Set("X-RateLimit-Limit", strconv.Itoa(tier.Max))
+			c.Set("X-RateLimit-Remaining", "0")
+			c.Set("X-RateLimit-Reset", string(c.Response().Header.Peek(fiber.HeaderRetryAfter)))
+			return apierror.New(fiber.StatusTooManyRequests, apierror.CodeRateLimited, "too many requests")
+		},
+	})
+}
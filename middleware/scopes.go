@@ -0,0 +1,46 @@
+// scopes.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireScope restricts a route to tokens granting a scope, e.g. utils.ScopeAdmin for the admin endpoints.
+// Tokens issued at sign-in grant every scope. It must be registered after utils.JWTMiddleware, which sets
+// the "scopes" of API tokens in the request context.
+//
+// Parameters:
+// - scope: The scope the route needs.
+//
+// Returns:
+// - fiber.Handler: The Fiber middleware handler for the scope check.
+func RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		scopes, _ := c.Locals("scopes").([]string)
+		if !utils.HasScope(scopes, scope) {
+			return apierror.Forbidden(apierror.CodeForbidden, "token lacks the required scope")
+		}
+		return c.Next()
+	}
+}
+
+// Unscoped restricts a route to tokens issued at sign-in, e.g. the account endpoints, so that an API token
+// cannot create further tokens, end sessions or delete its user's account. It must be registered after
+// utils.JWTMiddleware.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+//
+// Returns:
+// - error: An error object if an error occurs during the process.
+func Unscoped(c *fiber.Ctx) error {
+	if scopes, _ := c.Locals("scopes").([]string); len(scopes) > 0 {
+		return apierror.Forbidden(apierror.CodeForbidden, "API tokens cannot manage the account")
+	}
+	return c.Next()
+}
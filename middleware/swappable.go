@@ -0,0 +1,39 @@
+// swappable.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package middleware
+
+import (
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Swappable is a middleware handler that can be replaced while the server runs, e.g. when its settings
+// are reloaded. Requests in progress finish with the handler they started with.
+type Swappable struct {
+	handler atomic.Pointer[fiber.Handler]
+}
+
+// NewSwappable creates a swappable handler.
+//
+// Parameters:
+// - handler: The handler requests are passed to until it is swapped.
+//
+// Returns:
+// - *Swappable: The swappable handler; register its Handler method as the middleware.
+func NewSwappable(handler fiber.Handler) *Swappable {
+	s := &Swappable{}
+	s.Swap(handler)
+	return s
+}
+
+// Swap replaces the handler for the following requests.
+func (s *Swappable) Swap(handler fiber.Handler) {
+	s.handler.Store(&handler)
+}
+
+// Handler passes the request to the current handler.
+func (s *Swappable) Handler(c *fiber.Ctx) error {
+	return (*s.handler.Load())(c)
+}
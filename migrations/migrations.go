@@ -0,0 +1,720 @@
+// migrations.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection is the collection recording the applied migrations.
+const Collection = "schema_migrations"
+
+// Migration is a versioned, reversible change of the database schema or data.
+type Migration struct {
+	Version     int
+	Description string
+	Indexes     []Index // Indexes created by Up, checked by MissingIndexes
+	Up          func(ctx context.Context, db *mongo.Database) error
+	Down        func(ctx context.Context, db *mongo.Database) error
+}
+
+// Index names an index of a collection.
+type Index struct {
+	Collection string
+	Name       string
+}
+
+// Applied is the record of an applied migration.
+type Applied struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// All lists the migrations in version order. New migrations are appended with the next version.
+var All = []Migration{
+	{
+		Version:     1,
+		Description: "unique index on users.username",
+		Indexes:     []Index{{Collection: "users", Name: "username_unique"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndex(ctx, db, "users", "username_unique", bson.D{{Key: "username", Value: 1}}, true)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndex(ctx, db, "users", "username_unique")
+		},
+	},
+	{
+		Version:     2,
+		Description: "task indexes for owner lists and assignee workload",
+		Indexes:     []Index{{Collection: "tasks", Name: "owner_id"}, {Collection: "tasks", Name: "assignee_status"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndex(ctx, db, "tasks", "owner_id", bson.D{{Key: "userId", Value: 1}, {Key: "_id", Value: 1}}, false); err != nil {
+				return err
+			}
+			return createIndex(ctx, db, "tasks", "assignee_status", bson.D{{Key: "allotted_to", Value: 1}, {Key: "status", Value: 1}}, false)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndex(ctx, db, "tasks", "assignee_status"); err != nil {
+				return err
+			}
+			return dropIndex(ctx, db, "tasks", "owner_id")
+		},
+	},
+	{
+		Version:     3,
+		Description: "task list view index by owner",
+		Indexes:     []Index{{Collection: "task_list_view", Name: "owner_id"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndex(ctx, db, "task_list_view", "owner_id", bson.D{{Key: "userId", Value: 1}, {Key: "_id", Value: 1}}, false)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndex(ctx, db, "task_list_view", "owner_id")
+		},
+	},
+	{
+		Version:     4,
+		Description: "backfill task version and priority",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			tasks := db.Collection("tasks")
+			if _, err := tasks.UpdateMany(ctx, bson.M{"version": bson.M{"$in": bson.A{0, nil}}}, bson.M{"$set": bson.M{"version": 1}}); err != nil {
+				return err
+			}
+			_, err := tasks.UpdateMany(ctx, bson.M{"priority": bson.M{"$in": bson.A{"", nil}}}, bson.M{"$set": bson.M{"priority": "medium"}})
+			return err
+		},
+		// Backfilled values are valid for newer code and indistinguishable from real ones, so they are kept
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return nil
+		},
+	},
+	{
+		Version:     5,
+		Description: "task search index by owner and term",
+		Indexes:     []Index{{Collection: "task_search", Name: "owner_terms"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndex(ctx, db, "task_search", "owner_terms", bson.D{{Key: "userId", Value: 1}, {Key: "terms", Value: 1}}, false)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndex(ctx, db, "task_search", "owner_terms")
+		},
+	},
+	{
+		Version:     6,
+		Description: "project indexes for owner lists and project tasks",
+		Indexes:     []Index{{Collection: "projects", Name: "owner_id"}, {Collection: "tasks", Name: "project_id"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndex(ctx, db, "projects", "owner_id", bson.D{{Key: "owner_id", Value: 1}, {Key: "_id", Value: 1}}, false); err != nil {
+				return err
+			}
+			return createIndex(ctx, db, "tasks", "project_id", bson.D{{Key: "project_id", Value: 1}}, false)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndex(ctx, db, "tasks", "project_id"); err != nil {
+				return err
+			}
+			return dropIndex(ctx, db, "projects", "owner_id")
+		},
+	},
+	{
+		Version:     7,
+		Description: "task index for the overdue job",
+		Indexes:     []Index{{Collection: "tasks", Name: "overdue_end_time"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndex(ctx, db, "tasks", "overdue_end_time", bson.D{{Key: "overdue", Value: 1}, {Key: "end_time", Value: 1}}, false)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndex(ctx, db, "tasks", "overdue_end_time")
+		},
+	},
+	{
+		Version:     8,
+		Description: "backfill user and task timestamps, task indexes by creation and last write",
+		Indexes:     []Index{{Collection: "tasks", Name: "owner_created_at"}, {Collection: "tasks", Name: "owner_updated_at"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// Documents written before the timestamps existed were created when their _id was generated
+			fromID := bson.A{bson.M{"$set": bson.M{"created_at": bson.M{"$toDate": "$_id"}, "updated_at": bson.M{"$toDate": "$_id"}}}}
+			for _, collection := range []string{"users", "tasks"} {
+				if _, err := db.Collection(collection).UpdateMany(ctx, bson.M{"created_at": bson.M{"$exists": false}}, fromID); err != nil {
+					return err
+				}
+			}
+			if err := createIndex(ctx, db, "tasks", "owner_created_at", bson.D{{Key: "userId", Value: 1}, {Key: "created_at", Value: 1}}, false); err != nil {
+				return err
+			}
+			return createIndex(ctx, db, "tasks", "owner_updated_at", bson.D{{Key: "userId", Value: 1}, {Key: "updated_at", Value: 1}}, false)
+		},
+		// Backfilled timestamps are kept like those of migration 4
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndex(ctx, db, "tasks", "owner_updated_at"); err != nil {
+				return err
+			}
+			return dropIndex(ctx, db, "tasks", "owner_created_at")
+		},
+	},
+	{
+		Version:     9,
+		Description: "index users by scheduled deletion",
+		Indexes:     []Index{{Collection: "users", Name: "delete_at"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndex(ctx, db, "users", "delete_at", bson.D{{Key: "delete_at", Value: 1}}, false)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndex(ctx, db, "users", "delete_at")
+		},
+	},
+	{
+		Version:     10,
+		Description: "session index by user, expired sessions removed by a TTL index",
+		Indexes:     []Index{{Collection: "sessions", Name: "user_id"}, {Collection: "sessions", Name: "expires_at_ttl"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndex(ctx, db, "sessions", "user_id", bson.D{{Key: "userId", Value: 1}}, false); err != nil {
+				return err
+			}
+			_, err := db.Collection("sessions").Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+			})
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndex(ctx, db, "sessions", "expires_at_ttl"); err != nil {
+				return err
+			}
+			return dropIndex(ctx, db, "sessions", "user_id")
+		},
+	},
+	{
+		Version:     11,
+		Description: "audit log indexes by time and by user",
+		Indexes:     []Index{{Collection: "audit_log", Name: "time"}, {Collection: "audit_log", Name: "user_time"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndex(ctx, db, "audit_log", "time", bson.D{{Key: "time", Value: -1}}, false); err != nil {
+				return err
+			}
+			return createIndex(ctx, db, "audit_log", "user_time", bson.D{{Key: "user_id", Value: 1}, {Key: "time", Value: -1}}, false)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndex(ctx, db, "audit_log", "user_time"); err != nil {
+				return err
+			}
+			return dropIndex(ctx, db, "audit_log", "time")
+		},
+	},
+	{
+		// Creating the index also creates the collection, which MongoDB before 4.4 cannot do in a transaction
+		Version:     12,
+		Description: "outbox index by next delivery attempt",
+		Indexes:     []Index{{Collection: "outbox", Name: "next_attempt_at"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndex(ctx, db, "outbox", "next_attempt_at", bson.D{{Key: "next_attempt_at", Value: 1}, {Key: "_id", Value: 1}}, false)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndex(ctx, db, "outbox", "next_attempt_at")
+		},
+	},
+	{
+		Version:     13,
+		Description: "invitation indexes by token and by project",
+		Indexes:     []Index{{Collection: "invitations", Name: "token_hash"}, {Collection: "invitations", Name: "project_id"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndex(ctx, db, "invitations", "token_hash", bson.D{{Key: "token_hash", Value: 1}}, true); err != nil {
+				return err
+			}
+			return createIndex(ctx, db, "invitations", "project_id", bson.D{{Key: "project_id", Value: 1}}, false)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndex(ctx, db, "invitations", "project_id"); err != nil {
+				return err
+			}
+			return dropIndex(ctx, db, "invitations", "token_hash")
+		},
+	},
+	{
+		Version:     14,
+		Description: "task text index for search",
+		Indexes:     []Index{{Collection: "tasks", Name: "text"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			keys := bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}, {Key: "allotted_to", Value: "text"}}
+			return createIndex(ctx, db, "tasks", "text", keys, false)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndex(ctx, db, "tasks", "text")
+		},
+	},
+	{
+		Version:     15,
+		Description: "comment indexes by task and by task owner",
+		Indexes:     []Index{{Collection: "comments", Name: "task_id"}, {Collection: "comments", Name: "owner_id"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndex(ctx, db, "comments", "task_id", bson.D{{Key: "task_id", Value: 1}, {Key: "_id", Value: 1}}, false); err != nil {
+				return err
+			}
+			return createIndex(ctx, db, "comments", "owner_id", bson.D{{Key: "owner_id", Value: 1}}, false)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndex(ctx, db, "comments", "owner_id"); err != nil {
+				return err
+			}
+			return dropIndex(ctx, db, "comments", "task_id")
+		},
+	},
+	{
+		Version:     16,
+		Description: "backfill task manual_rank, task index by manual order",
+		Indexes:     []Index{{Collection: "tasks", Name: "owner_manual_rank"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// Tasks written before manual ordering were never pinned or reordered, see models.Task.ManualKey
+			if _, err := db.Collection("tasks").UpdateMany(ctx, bson.M{"manual_rank": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"manual_rank": "1~"}}); err != nil {
+				return err
+			}
+			return createIndex(ctx, db, "tasks", "owner_manual_rank", bson.D{{Key: "userId", Value: 1}, {Key: "manual_rank", Value: 1}, {Key: "_id", Value: 1}}, false)
+		},
+		// Backfilled ranks are kept like the values of migration 4
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndex(ctx, db, "tasks", "owner_manual_rank")
+		},
+	},
+	{
+		Version:     17,
+		Description: "unique index on invite_codes.code_hash",
+		Indexes:     []Index{{Collection: "invite_codes", Name: "code_hash_unique"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndex(ctx, db, "invite_codes", "code_hash_unique", bson.D{{Key: "code_hash", Value: 1}}, true)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndex(ctx, db, "invite_codes", "code_hash_unique")
+		},
+	},
+	{
+		Version:     18,
+		Description: "webhook delivery index by webhook, newest first",
+		Indexes:     []Index{{Collection: "webhook_deliveries", Name: "webhook_id"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndex(ctx, db, "webhook_deliveries", "webhook_id", bson.D{{Key: "webhook_id", Value: 1}, {Key: "_id", Value: -1}}, false)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndex(ctx, db, "webhook_deliveries", "webhook_id")
+		},
+	},
+	{
+		Version:     19,
+		Description: "sparse index on users.telegram_chat_id",
+		Indexes:     []Index{{Collection: "users", Name: "telegram_chat_id"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("users").Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "telegram_chat_id", Value: 1}},
+				Options: options.Index().SetName("telegram_chat_id").SetSparse(true),
+			})
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndex(ctx, db, "users", "telegram_chat_id")
+		},
+	},
+	{
+		Version:     20,
+		Description: "sparse index on tasks.github_issue",
+		Indexes:     []Index{{Collection: "tasks", Name: "github_issue"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("tasks").Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "github_issue.repo", Value: 1}, {Key: "github_issue.number", Value: 1}},
+				Options: options.Index().SetName("github_issue").SetSparse(true),
+			})
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndex(ctx, db, "tasks", "github_issue")
+		},
+	},
+	{
+		Version:     21,
+		Description: "partial index on tasks.caldav.name per owner",
+		Indexes:     []Index{{Collection: "tasks", Name: "caldav_name"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// Only tasks created by CalDAV clients have a name; a sparse index would hold every task by userId
+			partial := bson.M{"caldav.name": bson.M{"$exists": true}}
+			_, err := db.Collection("tasks").Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "caldav.name", Value: 1}},
+				Options: options.Index().SetName("caldav_name").SetPartialFilterExpression(partial),
+			})
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndex(ctx, db, "tasks", "caldav_name")
+		},
+	},
+	{
+		Version:     22,
+		Description: "tombstone indexes by owner and deletion, and by deletion for pruning",
+		Indexes:     []Index{{Collection: "tombstones", Name: "owner_deleted_at"}, {Collection: "tombstones", Name: "deleted_at"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndex(ctx, db, "tombstones", "owner_deleted_at", bson.D{{Key: "userId", Value: 1}, {Key: "deleted_at", Value: 1}, {Key: "_id", Value: 1}}, false); err != nil {
+				return err
+			}
+			return createIndex(ctx, db, "tombstones", "deleted_at", bson.D{{Key: "deleted_at", Value: 1}}, false)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndex(ctx, db, "tombstones", "deleted_at"); err != nil {
+				return err
+			}
+			return dropIndex(ctx, db, "tombstones", "owner_deleted_at")
+		},
+	},
+	{
+		Version:     23,
+		Description: "report schedule indexes by owner and by next run",
+		Indexes:     []Index{{Collection: "report_schedules", Name: "owner"}, {Collection: "report_schedules", Name: "next_run_at"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndex(ctx, db, "report_schedules", "owner", bson.D{{Key: "userId", Value: 1}, {Key: "_id", Value: 1}}, false); err != nil {
+				return err
+			}
+			return createIndex(ctx, db, "report_schedules", "next_run_at", bson.D{{Key: "next_run_at", Value: 1}}, false)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndex(ctx, db, "report_schedules", "next_run_at"); err != nil {
+				return err
+			}
+			return dropIndex(ctx, db, "report_schedules", "owner")
+		},
+	},
+	{
+		Version:     24,
+		Description: "user index by workspace for workspace quotas",
+		Indexes:     []Index{{Collection: "users", Name: "workspace"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndex(ctx, db, "users", "workspace", bson.D{{Key: "workspace_id", Value: 1}}, false)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndex(ctx, db, "users", "workspace")
+		},
+	},
+	{
+		Version:     25,
+		Description: "unique subscription per workspace and subscription index by Stripe customer",
+		Indexes:     []Index{{Collection: "subscriptions", Name: "workspace"}, {Collection: "subscriptions", Name: "stripe_customer"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndex(ctx, db, "subscriptions", "workspace", bson.D{{Key: "workspace_id", Value: 1}}, true); err != nil {
+				return err
+			}
+			return createIndex(ctx, db, "subscriptions", "stripe_customer", bson.D{{Key: "stripe_customer_id", Value: 1}}, false)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndex(ctx, db, "subscriptions", "stripe_customer"); err != nil {
+				return err
+			}
+			return dropIndex(ctx, db, "subscriptions", "workspace")
+		},
+	},
+	{
+		Version:     26,
+		Description: "trash indexes by owner and by deletion time",
+		Indexes:     []Index{{Collection: "trash", Name: "owner_deleted_at"}, {Collection: "trash", Name: "deleted_at"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndex(ctx, db, "trash", "owner_deleted_at", bson.D{{Key: "userId", Value: 1}, {Key: "deleted_at", Value: -1}}, false); err != nil {
+				return err
+			}
+			return createIndex(ctx, db, "trash", "deleted_at", bson.D{{Key: "deleted_at", Value: 1}}, false)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndex(ctx, db, "trash", "deleted_at"); err != nil {
+				return err
+			}
+			return dropIndex(ctx, db, "trash", "owner_deleted_at")
+		},
+	},
+	{
+		Version:     27,
+		Description: "sign-in index by user, sign-ins removed after 90 days by a TTL index",
+		Indexes:     []Index{{Collection: "sign_ins", Name: "user_id"}, {Collection: "sign_ins", Name: "created_at_ttl"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndex(ctx, db, "sign_ins", "user_id", bson.D{{Key: "userId", Value: 1}, {Key: "_id", Value: -1}}, false); err != nil {
+				return err
+			}
+			_, err := db.Collection("sign_ins").Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "created_at", Value: 1}},
+				Options: options.Index().SetName("created_at_ttl").SetExpireAfterSeconds(90 * 24 * 60 * 60),
+			})
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndex(ctx, db, "sign_ins", "created_at_ttl"); err != nil {
+				return err
+			}
+			return dropIndex(ctx, db, "sign_ins", "user_id")
+		},
+	},
+	{
+		Version:     28,
+		Description: "focus session indexes by user and start, by task, and one running session per user",
+		Indexes: []Index{
+			{Collection: "focus_sessions", Name: "user_started_at"},
+			{Collection: "focus_sessions", Name: "task_id"},
+			{Collection: "focus_sessions", Name: "running"},
+		},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndex(ctx, db, "focus_sessions", "user_started_at", bson.D{{Key: "userId", Value: 1}, {Key: "started_at", Value: 1}}, false); err != nil {
+				return err
+			}
+			if err := createIndex(ctx, db, "focus_sessions", "task_id", bson.D{{Key: "task_id", Value: 1}}, false); err != nil {
+				return err
+			}
+			// Running sessions have no end yet; the index rejects a second one of the same user
+			_, err := db.Collection("focus_sessions").Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "userId", Value: 1}},
+				Options: options.Index().SetName("running").SetUnique(true).SetPartialFilterExpression(bson.M{"ended_at": primitive.DateTime(0)}),
+			})
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			for _, name := range []string{"running", "task_id", "user_started_at"} {
+				if err := dropIndex(ctx, db, "focus_sessions", name); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     29,
+		Description: "single-use values removed by a TTL index once they expire",
+		Indexes:     []Index{{Collection: "nonces", Name: "expires_at_ttl"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("nonces").Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+			})
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndex(ctx, db, "nonces", "expires_at_ttl")
+		},
+	},
+	{
+		Version:     30,
+		Description: "unique sparse index on users.subject",
+		Indexes:     []Index{{Collection: "users", Name: "subject"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("users").Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "subject", Value: 1}},
+				Options: options.Index().SetName("subject").SetUnique(true).SetSparse(true),
+			})
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndex(ctx, db, "users", "subject")
+		},
+	},
+	{
+		Version:     31,
+		Description: "tombstone index by task, to remove the tombstone of a restored task",
+		Indexes:     []Index{{Collection: "tombstones", Name: "task_id"}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndex(ctx, db, "tombstones", "task_id", bson.D{{Key: "task_id", Value: 1}}, false)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndex(ctx, db, "tombstones", "task_id")
+		},
+	},
+}
+
+// Status returns the applied migrations in version order.
+func Status(ctx context.Context, db *mongo.Database) ([]Applied, error) {
+	cursor, err := db.Collection(Collection).Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	applied := []Applied{}
+	if err = cursor.All(ctx, &applied); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// Up applies the pending migrations up to and including version target (0 applies all of them)
+// and returns the migrations it applied.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - db: The application database.
+// - target: The version to migrate to, 0 for the latest.
+//
+// Returns:
+// - []Migration: The migrations applied, in order.
+// - error: An error object if a migration failed; the migrations before it stay applied.
+func Up(ctx context.Context, db *mongo.Database, target int) ([]Migration, error) {
+	done, err := appliedVersions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	ran := []Migration{}
+	for _, migration := range All {
+		if done[migration.Version] || (target > 0 && migration.Version > target) {
+			continue
+		}
+		if err := migration.Up(ctx, db); err != nil {
+			return ran, fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Description, err)
+		}
+		record := Applied{Version: migration.Version, Description: migration.Description, AppliedAt: time.Now().UTC()}
+		if _, err := db.Collection(Collection).InsertOne(ctx, record); err != nil {
+			return ran, err
+		}
+		ran = append(ran, migration)
+	}
+	return ran, nil
+}
+
+// Down reverts the given number of most recently applied migrations and returns the migrations it reverted.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - db: The application database.
+// - steps: The number of migrations to revert.
+//
+// Returns:
+// - []Migration: The migrations reverted, newest first.
+// - error: An error object if a migration failed; the migrations before it stay reverted.
+func Down(ctx context.Context, db *mongo.Database, steps int) ([]Migration, error) {
+	done, err := appliedVersions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	ran := []Migration{}
+	for i := len(All) - 1; i >= 0 && len(ran) < steps; i-- {
+		migration := All[i]
+		if !done[migration.Version] {
+			continue
+		}
+		if err := migration.Down(ctx, db); err != nil {
+			return ran, fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Description, err)
+		}
+		if _, err := db.Collection(Collection).DeleteOne(ctx, bson.M{"_id": migration.Version}); err != nil {
+			return ran, err
+		}
+		ran = append(ran, migration)
+	}
+	return ran, nil
+}
+
+// Pending returns the migrations not applied yet.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - db: The application database.
+//
+// Returns:
+// - []Migration: The pending migrations, in order.
+// - error: An error object if the applied migrations could not be read.
+func Pending(ctx context.Context, db *mongo.Database) ([]Migration, error) {
+	done, err := appliedVersions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []Migration{}
+	for _, migration := range All {
+		if !done[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// MissingIndexes returns the indexes of applied migrations that do not exist, e.g. because they were
+// dropped by hand.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - db: The application database.
+//
+// Returns:
+// - []Index: The missing indexes, in migration order.
+// - error: An error object if the applied migrations or the indexes could not be read.
+func MissingIndexes(ctx context.Context, db *mongo.Database) ([]Index, error) {
+	done, err := appliedVersions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := map[string]map[string]bool{}
+	missing := []Index{}
+	for _, migration := range All {
+		if !done[migration.Version] {
+			continue
+		}
+		for _, index := range migration.Indexes {
+			if existing[index.Collection] == nil {
+				if existing[index.Collection], err = indexNames(ctx, db, index.Collection); err != nil {
+					return nil, err
+				}
+			}
+			if !existing[index.Collection][index.Name] {
+				missing = append(missing, index)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// indexNames returns the names of the indexes of a collection; a collection that doesn't exist has none.
+func indexNames(ctx context.Context, db *mongo.Database, collection string) (map[string]bool, error) {
+	specs, err := db.Collection(collection).Indexes().ListSpecifications(ctx)
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Code == 26 { // NamespaceNotFound
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, spec := range specs {
+		names[spec.Name] = true
+	}
+	return names, nil
+}
+
+// appliedVersions returns the set of applied migration versions.
+func appliedVersions(ctx context.Context, db *mongo.Database) (map[int]bool, error) {
+	applied, err := Status(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	done := map[int]bool{}
+	for _, record := range applied {
+		done[record.Version] = true
+	}
+	return done, nil
+}
+
+// createIndex creates a named index on a collection.
+func createIndex(ctx context.Context, db *mongo.Database, collection, name string, keys bson.D, unique bool) error {
+	_, err := db.Collection(collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    keys,
+		Options: options.Index().SetName(name).SetUnique(unique),
+	})
+	return err
+}
+
+// dropIndex drops a named index, ignoring indexes and collections that don't exist.
+func dropIndex(ctx context.Context, db *mongo.Database, collection, name string) error {
+	_, err := db.Collection(collection).Indexes().DropOne(ctx, name)
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && (cmdErr.Code == 26 || cmdErr.Code == 27) { // NamespaceNotFound, IndexNotFound
+		return nil
+	}
+	return err
+}
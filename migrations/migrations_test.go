@@ -0,0 +1,25 @@
+// migrations_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMigrationsOrdered tests that migrations have consecutive versions and both directions
+func TestMigrationsOrdered(t *testing.T) {
+	for i, migration := range All {
+		// Assert that versions start at 1 and have no gaps, so Up and Down can rely on the slice order
+		assert.Equal(t, i+1, migration.Version)
+		assert.NotEmpty(t, migration.Description)
+		assert.NotNil(t, migration.Up)
+		assert.NotNil(t, migration.Down)
+		for _, index := range migration.Indexes {
+			assert.NotEmpty(t, index.Collection)
+			assert.NotEmpty(t, index.Name)
+		}
+	}
+}
@@ -5,12 +5,143 @@ package models
 
 import "go.mongodb.org/mongo-driver/bson/primitive"
 
+// RoleAdmin is the role granting access to the /admin endpoints.
+const RoleAdmin = "admin"
+
 type User struct {
-	ID       primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	Username string             `json:"username" bson:"username"`
-	Password string             `json:"password" bson:"password"`
+	ID            primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Username      string             `json:"username" bson:"username"`
+	DisplayName   string             `json:"display_name,omitempty" bson:"display_name,omitempty"`
+	Email         string             `json:"email,omitempty" bson:"email,omitempty"` // Given at sign-up, see package signup
+	Password      string             `json:"password" bson:"password"`
+	Role          string             `json:"role,omitempty" bson:"role,omitempty"`
+	WorkspaceID   primitive.ObjectID `json:"workspace_id,omitempty" bson:"workspace_id,omitempty"`
+	Timezone      string             `json:"timezone,omitempty" bson:"timezone,omitempty"`                 // IANA name, e.g. "Asia/Kolkata"; UTC when empty
+	CreatedAt     Timestamp          `json:"created_at" bson:"created_at"`                                 // Set by the repository
+	UpdatedAt     Timestamp          `json:"updated_at" bson:"updated_at"`                                 // Set by the repository on every write
+	DeleteAt      Timestamp          `json:"delete_at,omitempty" bson:"delete_at,omitempty"`               // Account and data are purged after this time
+	ExternalID    string             `json:"external_id,omitempty" bson:"external_id,omitempty"`           // ID of the user at the identity provider that provisions it (SCIM externalId)
+	Subject       string             `json:"-" bson:"subject,omitempty"`                                   // Directory identity the account is linked to, see auth.Identity; never set from requests
+	DeactivatedAt Timestamp          `json:"deactivated_at,omitempty" bson:"deactivated_at,omitempty"`     // Set while the account is deactivated and cannot sign in
+	TelegramChat  int64              `json:"telegram_chat_id,omitempty" bson:"telegram_chat_id,omitempty"` // Telegram chat linked with the bot, see package telegram
+
+	Notifications *NotificationSettings `json:"notifications,omitempty" bson:"notifications,omitempty"`
+	GitHub        *GitHubAccount        `json:"github,omitempty" bson:"github,omitempty"` // Connected GitHub account, see package githubsync
+	Digest        *DigestSettings       `json:"digest,omitempty" bson:"digest,omitempty"` // Digest email schedule, see package digest
+}
+
+// Digest frequencies
+const (
+	DigestOff    = "off"
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// DigestSettings schedule the digest email summarizing a user's upcoming, overdue and recently changed
+// tasks. Digests are sent to the user's email at Hour in their timezone, on Weekday for weekly digests.
+type DigestSettings struct {
+	Frequency  string    `json:"frequency" bson:"frequency"` // off, daily or weekly
+	Hour       int       `json:"hour" bson:"hour"`           // 0-23, in the user's timezone
+	Weekday    int       `json:"weekday" bson:"weekday"`     // 0 (Sunday) to 6, for weekly digests
+	LastSentAt Timestamp `json:"last_sent_at,omitempty" bson:"last_sent_at,omitempty"`
+}
+
+// GitHubAccount is the GitHub account a user connected through the OAuth app. Its token creates the issues
+// of the tasks the user links and keeps them in sync.
+type GitHubAccount struct {
+	Login       string    `json:"login" bson:"login"`
+	Token       string    `json:"-" bson:"token"` // OAuth access token
+	ConnectedAt Timestamp `json:"connected_at" bson:"connected_at"`
+}
+
+// Session is a signed-in device of a user, or an API token. Its ID is the "sid" claim of the token, so
+// revoking the session invalidates the token.
+type Session struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	UserID    primitive.ObjectID `json:"userId" bson:"userId"`
+	Device    string             `json:"device" bson:"device"` // User-Agent of the sign-in request
+	IP        string             `json:"ip" bson:"ip"`
+	IssuedAt  Timestamp          `json:"issued_at" bson:"issued_at"`
+	ExpiresAt Timestamp          `json:"expires_at" bson:"expires_at"` // Expiry of the token, or of the refresh token if any; the session is removed after it
+	RevokedAt Timestamp          `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	Name      string             `json:"name,omitempty" bson:"name,omitempty"`     // Name of an API token, e.g. "ci-bot"; empty for sign-ins
+	Scopes    []string           `json:"scopes,omitempty" bson:"scopes,omitempty"` // Scopes of an API token; none for sign-ins
+
+	// Refresh tokens are stored as SHA-256 hashes and rotated on every use; the previous hash detects a
+	// rotated token used again
+	RefreshTokenHash    string    `json:"-" bson:"refresh_token_hash,omitempty"`
+	PreviousRefreshHash string    `json:"-" bson:"previous_refresh_hash,omitempty"`
+	RefreshedAt         Timestamp `json:"refreshed_at,omitempty" bson:"refreshed_at,omitempty"` // Last use of the refresh token
+	RememberMe          bool      `json:"remember_me,omitempty" bson:"remember_me,omitempty"`   // Signed in with remember_me, for a longer-lived refresh token
+}
+
+// SignIn is a sign-in of a user, recorded with the device and network it came from so that the user can
+// review them and be told about sign-ins from new ones. Sign-ins are kept for 90 days.
+type SignIn struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	UserID     primitive.ObjectID `json:"-" bson:"userId"`
+	SessionID  primitive.ObjectID `json:"session_id" bson:"session_id"`
+	UserAgent  string             `json:"user_agent" bson:"user_agent"`
+	Device     string             `json:"device" bson:"device"` // Browser and OS of the user agent, e.g. "Firefox on Linux"
+	IP         string             `json:"ip" bson:"ip"`
+	Network    string             `json:"network" bson:"network"`         // Network of the IP, e.g. "203.0.113.0/24"
+	NewDevice  bool               `json:"new_device" bson:"new_device"`   // The account was not signed in from the device before
+	NewNetwork bool               `json:"new_network" bson:"new_network"` // The account was not signed in from the network before
+	CreatedAt  Timestamp          `json:"created_at" bson:"created_at"`
 }
 
+// Maintenance modes of the API
+const (
+	MaintenanceOff      = "off"       // All requests are served
+	MaintenanceReadOnly = "read-only" // Reads are served, writes are rejected
+	MaintenanceFull     = "full"      // Only health probes, sign-in and admin endpoints are served
+)
+
+// Maintenance is the maintenance mode of the API. It is stored once for all instances, so switching it
+// on one instance takes the whole deployment into maintenance.
+type Maintenance struct {
+	Mode       string    `json:"mode" bson:"mode"`
+	Message    string    `json:"message,omitempty" bson:"message,omitempty"` // Shown to rejected clients instead of the default message
+	RetryAfter int       `json:"retry_after" bson:"retry_after"`             // Seconds rejected clients are told to wait
+	UpdatedAt  Timestamp `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+	UpdatedBy  string    `json:"updated_by,omitempty" bson:"updated_by,omitempty"` // Hex ID of the admin who set the mode
+}
+
+// NotificationSettings are the chat channels a user receives task notifications in.
+type NotificationSettings struct {
+	SlackWebhookURL string   `json:"slack_webhook_url,omitempty" bson:"slack_webhook_url,omitempty"`
+	TeamsWebhookURL string   `json:"teams_webhook_url,omitempty" bson:"teams_webhook_url,omitempty"`
+	Events          []string `json:"events,omitempty" bson:"events,omitempty"` // Events to be notified about; empty means all
+
+	Channels   map[string][]string `json:"channels,omitempty" bson:"channels,omitempty"`       // Chat channels per event; unlisted events use all of them
+	QuietHours *QuietHours         `json:"quiet_hours,omitempty" bson:"quiet_hours,omitempty"` // No chat notifications during these hours
+	DigestOnly bool                `json:"digest_only,omitempty" bson:"digest_only,omitempty"` // No chat notifications at all, only the digest email
+}
+
+// QuietHours is a daily period, in the user's timezone, during which no chat notifications are sent. A
+// period whose end is before its start spans midnight, e.g. 22:00 to 07:00.
+type QuietHours struct {
+	Start string `json:"start" bson:"start"` // "HH:MM"
+	End   string `json:"end" bson:"end"`     // "HH:MM", not included
+}
+
+// Task priorities
+const (
+	TaskPriorityLow    = "low"
+	TaskPriorityMedium = "medium"
+	TaskPriorityHigh   = "high"
+)
+
+// Task statuses
+const (
+	TaskStatusPending    = "Pending"
+	TaskStatusInProgress = "In Progress"
+	TaskStatusDone       = "Done"
+)
+
+// TaskStatuses lists the task statuses in workflow order, e.g. the columns of a board.
+var TaskStatuses = []string{TaskStatusPending, TaskStatusInProgress, TaskStatusDone}
+
 type Task struct {
 	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
 	UserID      primitive.ObjectID `json:"userId" bson:"userId"`
@@ -19,6 +150,392 @@ type Task struct {
 	AllottedTo  string             `json:"allotted_to" bson:"allotted_to"`
 	DoneBy      string             `json:"done_by" bson:"done_by"`
 	Status      string             `json:"status" bson:"status"`
-	StartDate   primitive.DateTime `json:"start_time" bson:"start_time"`
-	EndDate     primitive.DateTime `json:"end_time" bson:"end_time"`
+	Priority    string             `json:"priority" bson:"priority"`
+	StartDate   Timestamp          `json:"start_time" bson:"start_time"`
+	EndDate     Timestamp          `json:"end_time" bson:"end_time"`
+	Version     int                `json:"version" bson:"version"`
+	CreatedAt   Timestamp          `json:"created_at" bson:"created_at"` // Set by the repository
+	UpdatedAt   Timestamp          `json:"updated_at" bson:"updated_at"` // Set by the repository on every write
+	Due         string             `json:"due,omitempty" bson:"-"`       // Input only: a date phrase such as "next friday 5pm" setting end_time
+
+	ProjectID       primitive.ObjectID `json:"project_id,omitempty" bson:"project_id"`
+	EstimateMinutes int                `json:"estimate_minutes" bson:"estimate_minutes"`         // Estimated effort, 0 when not estimated
+	StartedAt       primitive.DateTime `json:"started_at,omitempty" bson:"started_at,omitempty"` // Set by the server when work starts
+	CompletedAt     primitive.DateTime `json:"completed_at,omitempty" bson:"completed_at"`
+	ActualMinutes   int                `json:"actual_minutes" bson:"actual_minutes"`                   // Computed on completion from StartedAt
+	Rank            string             `json:"rank,omitempty" bson:"rank,omitempty"`                   // Position in its board column, set by board moves
+	Overdue         bool               `json:"overdue" bson:"overdue"`                                 // Set by the overdue job once end_time has passed
+	SnoozedUntil    primitive.DateTime `json:"snoozed_until,omitempty" bson:"snoozed_until,omitempty"` // Reminders are suppressed until then
+	Snoozes         []Snooze           `json:"snoozes,omitempty" bson:"snoozes,omitempty"`             // Snooze history, oldest first
+	Visibility      string             `json:"visibility" bson:"visibility,omitempty"`                 // private, team or org, see package permissions
+	DescriptionHTML string             `json:"description_html" bson:"description_html,omitempty"`     // Sanitized HTML of the Markdown description, set by the server
+	Handoffs        []Handoff          `json:"handoffs,omitempty" bson:"handoffs,omitempty"`           // Reassignment history, oldest first
+	Pinned          bool               `json:"pinned" bson:"pinned,omitempty"`                         // Pinned tasks come first in the manual order
+	Position        string             `json:"position,omitempty" bson:"position,omitempty"`           // Place in the owner's manual order, set by reorders
+	ManualRank      string             `json:"-" bson:"manual_rank"`                                   // Sort key of the manual order, set by the repository
+	GitHubIssue     *GitHubIssue       `json:"github_issue,omitempty" bson:"github_issue,omitempty"`   // Linked GitHub issue, set by the server
+	CalDAV          *CalDAVObject      `json:"-" bson:"caldav,omitempty"`                              // Resource name and UID chosen by a CalDAV client
+	FieldVersions   map[string]int     `json:"-" bson:"field_versions,omitempty"`                      // Version that last changed each field, see package merge
+
+	DependsOn  []primitive.ObjectID `json:"depends_on,omitempty" bson:"depends_on,omitempty"`   // Tasks of the same project that must finish first
+	ArchivedAt primitive.DateTime   `json:"archived_at,omitempty" bson:"archived_at,omitempty"` // Set by the retention job to hide long completed tasks
+}
+
+// TrashedTask is a deleted task kept in the trash until it is restored or purged by the retention job.
+type TrashedTask struct {
+	Task      `bson:",inline"`
+	DeletedAt Timestamp `json:"deleted_at" bson:"deleted_at"` // Set by the repository
+}
+
+// TaskListEntry is a task of GET /tasks with what clients would otherwise look up for each task: the
+// summary of its assignee and the number of comments on it.
+type TaskListEntry struct {
+	Task         `bson:",inline"`
+	Assignee     *UserSummary `json:"assignee,omitempty" bson:"assignee,omitempty"` // Nil if unassigned or the assignee has no account
+	CommentCount int          `json:"comment_count" bson:"comment_count"`
+}
+
+// UserSummary is the public part of a user shown next to their tasks.
+type UserSummary struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	Username    string             `json:"username" bson:"username"`
+	DisplayName string             `json:"display_name,omitempty" bson:"display_name,omitempty"`
+}
+
+// States of GitHub issues
+const (
+	GitHubIssueOpen   = "open"
+	GitHubIssueClosed = "closed"
+)
+
+// GitHubIssue links a task to a GitHub issue. The task's status and comments are mirrored to the issue and
+// back: closing either side marks the other done, reopening it reopens the other.
+type GitHubIssue struct {
+	Repo      string             `json:"repo" bson:"repo"` // "owner/name", in lower case
+	Number    int                `json:"number" bson:"number"`
+	URL       string             `json:"url" bson:"url"`
+	LinkedBy  primitive.ObjectID `json:"linked_by" bson:"linked_by"`                       // User whose GitHub token syncs the issue
+	State     string             `json:"state" bson:"state"`                               // State of both sides at the last sync
+	SyncError string             `json:"sync_error,omitempty" bson:"sync_error,omitempty"` // Why the last sync failed
+}
+
+// CalDAVObject names a task created by a CalDAV client the way the client did. Tasks without it are served
+// as <task id>.ics with their ID as the UID.
+type CalDAVObject struct {
+	Name string `bson:"name"` // Resource name in the tasks collection, without .ics
+	UID  string `bson:"uid"`  // UID of the VTODO
+}
+
+// ManualKey returns the sort key of a task in the manual order of GET /tasks?sort=manual: pinned tasks
+// first, each group by position, and tasks never reordered after the others.
+func (t Task) ManualKey() string {
+	key := "1"
+	if t.Pinned {
+		key = "0"
+	}
+	if t.Position == "" {
+		return key + "~" // Sorts after every lexorank position
+	}
+	return key + t.Position
+}
+
+// Snooze records one postponement of a task's end date.
+type Snooze struct {
+	At     primitive.DateTime `json:"at" bson:"at"`
+	By     string             `json:"by" bson:"by"`     // Username of the user who snoozed the task
+	From   primitive.DateTime `json:"from" bson:"from"` // End date before the snooze
+	Until  primitive.DateTime `json:"until" bson:"until"`
+	Reason string             `json:"reason,omitempty" bson:"reason,omitempty"`
+}
+
+// Handoff records one reassignment of a task from one user to another.
+type Handoff struct {
+	At   primitive.DateTime `json:"at" bson:"at"`
+	By   string             `json:"by" bson:"by"`     // Username of the user who reassigned the task
+	From string             `json:"from" bson:"from"` // Previous assignee
+	To   string             `json:"to" bson:"to"`     // New assignee
+	Note string             `json:"note,omitempty" bson:"note,omitempty"`
+}
+
+// Tombstone records the deletion of a task, so that offline clients syncing later remove their copy of it.
+// Tombstones are pruned once older than the sync retention.
+type Tombstone struct {
+	ID        primitive.ObjectID `json:"-" bson:"_id"`
+	TaskID    primitive.ObjectID `json:"task_id" bson:"task_id"`
+	UserID    primitive.ObjectID `json:"-" bson:"userId"`              // Owner of the deleted task
+	DeletedAt Timestamp          `json:"deleted_at" bson:"deleted_at"` // Set by the repository
+}
+
+// InviteCode lets people sign up while registration is invitation-only. Admins hand out the code, which
+// is shown once when the code is created; only its hash is stored. A code works until it has been used
+// MaxUses times, expires or is revoked.
+type InviteCode struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	CodeHash  string             `json:"-" bson:"code_hash"` // SHA-256 of the normalized code, in hex
+	Note      string             `json:"note,omitempty" bson:"note,omitempty"`
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"` // Admin who created the code
+	MaxUses   int                `json:"max_uses" bson:"max_uses"`
+	Uses      int                `json:"uses" bson:"uses"`             // Sign-ups with the code so far
+	CreatedAt Timestamp          `json:"created_at" bson:"created_at"` // Set by the handler
+	ExpiresAt Timestamp          `json:"expires_at" bson:"expires_at"`
+	RevokedAt Timestamp          `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+}
+
+// Comment is a Markdown comment on a task. The users it mentions with @username, and who may read the
+// task, are notified and listed in Mentions.
+type Comment struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	TaskID    primitive.ObjectID `json:"task_id" bson:"task_id"`
+	OwnerID   primitive.ObjectID `json:"-" bson:"owner_id"` // Owner of the task, whose account deletion removes the comment
+	AuthorID  primitive.ObjectID `json:"author_id" bson:"author_id"`
+	Author    string             `json:"author" bson:"author"` // Username of the author
+	Body      string             `json:"body" bson:"body"`
+	BodyHTML  string             `json:"body_html" bson:"body_html"` // Sanitized HTML of the Markdown body
+	Mentions  []string           `json:"mentions" bson:"mentions"`   // Usernames of the mentioned users
+	CreatedAt Timestamp          `json:"created_at" bson:"created_at"`
+
+	GitHubCommentID int64 `json:"github_comment_id,omitempty" bson:"github_comment_id,omitempty"` // Copy of the comment on the linked GitHub issue, or its original
+}
+
+// Project groups tasks that are planned and tracked together, e.g. in sprints.
+type Project struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	OwnerID   primitive.ObjectID `json:"owner_id" bson:"owner_id"`
+	Name      string             `json:"name" bson:"name"`
+	Private   bool               `json:"private" bson:"private"`                           // Task descriptions are stored encrypted when field encryption is on
+	StartDate primitive.DateTime `json:"start_date,omitempty" bson:"start_date,omitempty"` // Optional sprint start
+	EndDate   primitive.DateTime `json:"end_date,omitempty" bson:"end_date,omitempty"`     // Optional sprint end
+	CreatedAt primitive.DateTime `json:"created_at" bson:"created_at"`
+
+	Assignment *AssignmentRule `json:"assignment,omitempty" bson:"assignment,omitempty"` // Picks the assignee of new tasks created without one
+}
+
+// Strategies of an AssignmentRule
+const (
+	AssignmentRoundRobin  = "round_robin"  // Each member in turn
+	AssignmentLeastLoaded = "least_loaded" // The member with the fewest open tasks of the project owner
+)
+
+// AssignmentRule picks the assignee of the tasks created in a project without one, see package assignment.
+type AssignmentRule struct {
+	Strategy string   `json:"strategy" bson:"strategy"` // round_robin or least_loaded
+	Members  []string `json:"members" bson:"members"`   // Usernames the tasks are shared among, in turn order
+	Next     int      `json:"-" bson:"next"`            // Round-robin turns taken so far, advanced by the repository
+}
+
+// InvitationRoleViewer is the role of guests: they can read the project, its board and its tasks, and
+// change nothing.
+const InvitationRoleViewer = "viewer"
+
+// Invitation invites an external email address to a project as a read-only guest. The invitation token is
+// handed out once, when the invitation is created; only its hash is stored. Accepting the invitation
+// issues a guest token scoped to the project, which stops working when the invitation is revoked.
+type Invitation struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	ProjectID  primitive.ObjectID `json:"project_id" bson:"project_id"`
+	OwnerID    primitive.ObjectID `json:"owner_id" bson:"owner_id"`
+	Email      string             `json:"email" bson:"email"`
+	Role       string             `json:"role" bson:"role"`
+	TokenHash  string             `json:"-" bson:"token_hash"`          // SHA-256 of the invitation token, in hex
+	CreatedAt  Timestamp          `json:"created_at" bson:"created_at"` // Set by the handler
+	ExpiresAt  Timestamp          `json:"expires_at" bson:"expires_at"` // The invitation must be accepted before then
+	AcceptedAt Timestamp          `json:"accepted_at,omitempty" bson:"accepted_at,omitempty"`
+	RevokedAt  Timestamp          `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+}
+
+// Dead letter statuses
+const (
+	DeadLetterPending   = "pending"
+	DeadLetterReplayed  = "replayed"
+	DeadLetterDiscarded = "discarded"
+)
+
+// DeadLetter is a failed async job, notification or webhook delivery kept for inspection and replay.
+type DeadLetter struct {
+	ID          primitive.ObjectID     `json:"id,omitempty" bson:"_id,omitempty"`
+	Kind        string                 `json:"kind" bson:"kind"`
+	Payload     map[string]interface{} `json:"payload" bson:"payload"`
+	Error       string                 `json:"error" bson:"error"`
+	Attempts    int                    `json:"attempts" bson:"attempts"`
+	Status      string                 `json:"status" bson:"status"`
+	CreatedAt   primitive.DateTime     `json:"created_at" bson:"created_at"`
+	UpdatedAt   primitive.DateTime     `json:"updated_at" bson:"updated_at"`
+	ReplayedAt  primitive.DateTime     `json:"replayed_at,omitempty" bson:"replayed_at,omitempty"`
+	DiscardedAt primitive.DateTime     `json:"discarded_at,omitempty" bson:"discarded_at,omitempty"`
+	Reason      string                 `json:"reason,omitempty" bson:"reason,omitempty"` // Why an admin discarded it
+}
+
+// Kinds of outbox messages
+const (
+	OutboxEvent        = "event"
+	OutboxNotification = "notification"
+)
+
+// OutboxMessage is an event or notification stored in the same transaction as the write that caused it,
+// and delivered by the outbox relay afterwards.
+type OutboxMessage struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id"`
+	Kind          string             `json:"kind" bson:"kind"`
+	Payload       string             `json:"payload" bson:"payload"` // The event or notification as JSON
+	Attempts      int                `json:"attempts" bson:"attempts"`
+	NextAttemptAt primitive.DateTime `json:"next_attempt_at" bson:"next_attempt_at"`
+	LastError     string             `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	CreatedAt     primitive.DateTime `json:"created_at" bson:"created_at"`
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook posts the domain events it subscribes to, signed with its secret, to a URL.
+type Webhook struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	URL       string             `json:"url" bson:"url"`
+	Secret    string             `json:"-" bson:"secret"`                          // Key of the HMAC-SHA256 signatures, shown once on creation
+	Events    []string           `json:"events,omitempty" bson:"events,omitempty"` // Event types delivered; all of them when empty
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`             // Admin who registered the webhook
+	CreatedAt Timestamp          `json:"created_at" bson:"created_at"`
+}
+
+// WebhookAttempt is one attempt to deliver an event to a webhook.
+type WebhookAttempt struct {
+	At         Timestamp `json:"at" bson:"at"`
+	StatusCode int       `json:"status_code,omitempty" bson:"status_code,omitempty"` // Status of the response; none if the request failed
+	Error      string    `json:"error,omitempty" bson:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms" bson:"duration_ms"`
+	Manual     bool      `json:"manual,omitempty" bson:"manual,omitempty"` // Redelivered by an admin
+}
+
+// WebhookDelivery is an event sent to a webhook with all attempts to deliver it. Its ID is sent with every
+// attempt, so receivers can skip deliveries they already processed.
+type WebhookDelivery struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	WebhookID primitive.ObjectID `json:"webhook_id" bson:"webhook_id"`
+	EventID   string             `json:"event_id" bson:"event_id"`
+	Event     string             `json:"event" bson:"event"`     // Event type, e.g. task.created
+	Payload   string             `json:"payload" bson:"payload"` // The request body, the event as JSON
+	Status    string             `json:"status" bson:"status"`
+	Attempts  []WebhookAttempt   `json:"attempts" bson:"attempts"`
+	CreatedAt Timestamp          `json:"created_at" bson:"created_at"`
+}
+
+// Branding is the look of a workspace in notification emails and public share pages.
+type Branding struct {
+	LogoURL     string `json:"logo_url" bson:"logo_url"`
+	AccentColor string `json:"accent_color" bson:"accent_color"`
+	SenderName  string `json:"sender_name" bson:"sender_name"`
+}
+
+// Workspace groups users that share branding and settings.
+type Workspace struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name      string             `json:"name" bson:"name"`
+	OwnerID   primitive.ObjectID `json:"owner_id" bson:"owner_id"`
+	Branding  Branding           `json:"branding" bson:"branding"`
+	CreatedAt primitive.DateTime `json:"created_at" bson:"created_at"`
+
+	Invited []primitive.ObjectID `json:"-" bson:"invited,omitempty"` // Users invited by the owner who have not joined yet
+}
+
+// TaskListView is the denormalized list-view document of a task. It is kept up to date on every task
+// write so that list endpoints can respond from a single lean document without lookups.
+type TaskListView struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id"`
+	UserID          primitive.ObjectID `json:"userId" bson:"userId"`
+	Title           string             `json:"title" bson:"title"`
+	Status          string             `json:"status" bson:"status"`
+	Priority        string             `json:"priority" bson:"priority"`
+	Due             primitive.DateTime `json:"due" bson:"due"`
+	AssigneeName    string             `json:"assignee_name" bson:"assignee_name"`
+	CommentCount    int                `json:"comment_count" bson:"comment_count"`
+	AttachmentCount int                `json:"attachment_count" bson:"attachment_count"`
+	UpdatedAt       primitive.DateTime `json:"updated_at" bson:"updated_at"`
+}
+
+// UserTaskStats are the task counters of a user, derived from the tasks change stream.
+type UserTaskStats struct {
+	UserID    primitive.ObjectID `json:"user_id" bson:"_id"`
+	Total     int64              `json:"total" bson:"total"`
+	ByStatus  map[string]int64   `json:"by_status" bson:"by_status"`
+	UpdatedAt primitive.DateTime `json:"updated_at" bson:"updated_at"`
+}
+
+// TaskSearchEntry holds the search terms of a task, derived from the tasks change stream.
+type TaskSearchEntry struct {
+	ID     primitive.ObjectID `json:"id" bson:"_id"`
+	UserID primitive.ObjectID `json:"userId" bson:"userId"`
+	Terms  []string           `json:"terms" bson:"terms"`
+}
+
+// Report formats
+const (
+	ReportFormatPDF = "pdf"
+	ReportFormatCSV = "csv"
+)
+
+// ReportFilter selects the tasks of a report, like the query parameters of the report endpoints.
+type ReportFilter struct {
+	ProjectID  primitive.ObjectID `json:"project_id,omitempty" bson:"project_id,omitempty"`
+	Statuses   []string           `json:"statuses,omitempty" bson:"statuses,omitempty"`
+	AllottedTo string             `json:"allotted_to,omitempty" bson:"allotted_to,omitempty"`
+	Overdue    *bool              `json:"overdue,omitempty" bson:"overdue,omitempty"`
+}
+
+// ReportSchedule emails a report of a user's tasks on a cron schedule, see package reports.
+type ReportSchedule struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	UserID     primitive.ObjectID `json:"-" bson:"userId"`
+	Name       string             `json:"name" bson:"name"`
+	Format     string             `json:"format" bson:"format"`         // pdf or csv
+	Schedule   string             `json:"schedule" bson:"schedule"`     // Cron expression in the user's timezone, e.g. "0 9 * * mon"
+	Filter     ReportFilter       `json:"filter" bson:"filter"`         // Tasks included
+	Recipients []string           `json:"recipients" bson:"recipients"` // Email addresses; the user's own when empty
+	NextRunAt  Timestamp          `json:"next_run_at" bson:"next_run_at"`
+	LastRunAt  Timestamp          `json:"last_run_at,omitempty" bson:"last_run_at,omitempty"`
+	LastError  string             `json:"last_error,omitempty" bson:"last_error,omitempty"` // Why the last run failed, if it did
+	CreatedAt  Timestamp          `json:"created_at" bson:"created_at"`
+	UpdatedAt  Timestamp          `json:"updated_at" bson:"updated_at"`
+}
+
+// FocusSession is a period of focused work on one task, e.g. a pomodoro, see package focus.
+type FocusSession struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	UserID         primitive.ObjectID `json:"-" bson:"userId"`
+	TaskID         primitive.ObjectID `json:"task_id" bson:"task_id"`
+	PlannedMinutes int                `json:"planned_minutes" bson:"planned_minutes"` // Length the user set out to focus for
+	StartedAt      Timestamp          `json:"started_at" bson:"started_at"`
+	EndedAt        Timestamp          `json:"ended_at" bson:"ended_at"` // Unset while the session runs
+	Interruptions  []Interruption     `json:"interruptions" bson:"interruptions"`
+	FocusedMinutes int                `json:"focused_minutes" bson:"focused_minutes"` // Set when the session ends
+}
+
+// Interruption is a distraction logged during a focus session.
+type Interruption struct {
+	At      Timestamp `json:"at" bson:"at"`
+	Minutes int       `json:"minutes" bson:"minutes"` // Time lost, not counted as focused
+	Note    string    `json:"note,omitempty" bson:"note,omitempty"`
+}
+
+// Billing plans of workspaces
+const (
+	PlanFree = "free"
+	PlanPro  = "pro"
+)
+
+// Subscription is the billing state of a workspace, kept in step with its Stripe subscription by the
+// Stripe webhook, see package billing.
+type Subscription struct {
+	ID                   primitive.ObjectID `json:"-" bson:"_id"`
+	WorkspaceID          primitive.ObjectID `json:"workspace_id" bson:"workspace_id"`
+	Plan                 string             `json:"plan" bson:"plan"`     // free or pro
+	Status               string             `json:"status" bson:"status"` // Stripe subscription status, e.g. active or canceled
+	StripeCustomerID     string             `json:"-" bson:"stripe_customer_id,omitempty"`
+	StripeSubscriptionID string             `json:"-" bson:"stripe_subscription_id,omitempty"`
+	PriceID              string             `json:"-" bson:"price_id,omitempty"`
+	CurrentPeriodEnd     Timestamp          `json:"current_period_end,omitempty" bson:"current_period_end,omitempty"`
+	LastEventAt          Timestamp          `json:"-" bson:"last_event_at"` // Creation time of the last applied Stripe event, to skip older ones
+	UpdatedAt            Timestamp          `json:"updated_at" bson:"updated_at"`
 }
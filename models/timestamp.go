@@ -0,0 +1,112 @@
+// timestamp.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// ErrInvalidTimestamp is returned when a JSON timestamp is neither an RFC3339 string nor epoch milliseconds.
+var ErrInvalidTimestamp = errors.New("timestamp must be an RFC3339 string or epoch milliseconds")
+
+// Timestamp is a BSON datetime with millisecond precision. In JSON it is an RFC3339 string in UTC, or null
+// when unset, and is also read from epoch milliseconds for clients of the old numeric format.
+type Timestamp primitive.DateTime
+
+// NewTimestamp creates a Timestamp from a time.Time, truncated to milliseconds.
+//
+// Parameters:
+// - t: The time.
+//
+// Returns:
+// - Timestamp: The timestamp.
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp(primitive.NewDateTimeFromTime(t))
+}
+
+// Time returns the timestamp as a time.Time in the local timezone.
+func (t Timestamp) Time() time.Time {
+	return primitive.DateTime(t).Time()
+}
+
+// IsZero reports whether the timestamp is unset.
+func (t Timestamp) IsZero() bool {
+	return t == 0
+}
+
+// MarshalJSON writes the timestamp as an RFC3339 string in UTC, or null when it is unset.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	// RFC3339 never needs escaping, so the string is appended without going through encoding/json
+	data := make([]byte, 0, len(time.RFC3339Nano)+2)
+	data = append(data, '"')
+	data = t.Time().UTC().AppendFormat(data, time.RFC3339Nano)
+	return append(data, '"'), nil
+}
+
+// UnmarshalJSON reads an RFC3339 string or a number of epoch milliseconds. null leaves the timestamp
+// unchanged, like it does for time.Time.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return ErrInvalidTimestamp
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return ErrInvalidTimestamp
+		}
+		*t = NewTimestamp(parsed)
+		return nil
+	}
+
+	millis, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	*t = Timestamp(millis)
+	return nil
+}
+
+// MarshalBSONValue stores the timestamp as a BSON datetime, like primitive.DateTime.
+func (t Timestamp) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bsontype.DateTime, bsoncore.AppendDateTime(nil, int64(t)), nil
+}
+
+// UnmarshalBSONValue reads a BSON datetime. Null and missing values leave the timestamp unset.
+func (t *Timestamp) UnmarshalBSONValue(kind bsontype.Type, data []byte) error {
+	if kind == bsontype.Null || kind == bsontype.Undefined {
+		*t = 0
+		return nil
+	}
+	// Datetimes are read directly; other types take the slower path that converts or rejects them
+	if kind == bsontype.DateTime {
+		if value, _, ok := bsoncore.ReadDateTime(data); ok {
+			*t = Timestamp(value)
+			return nil
+		}
+	}
+	var value primitive.DateTime
+	if err := bson.UnmarshalValue(kind, data, &value); err != nil {
+		return err
+	}
+	*t = Timestamp(value)
+	return nil
+}
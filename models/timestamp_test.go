@@ -0,0 +1,84 @@
+// timestamp_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// TestTimestampJSON tests that timestamps are written as RFC3339 and read from RFC3339 or epoch milliseconds
+func TestTimestampJSON(t *testing.T) {
+	at := time.Date(2024, time.July, 5, 17, 0, 0, 250_000_000, time.UTC)
+
+	// Assert that set timestamps are RFC3339 in UTC and unset ones null
+	data, err := json.Marshal(Task{StartDate: NewTimestamp(at)})
+	require.NoError(t, err)
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &raw))
+	assert.Equal(t, "2024-07-05T17:00:00.25Z", raw["start_time"])
+	assert.Nil(t, raw["end_time"])
+
+	// Assert that RFC3339 with any offset, epoch milliseconds and null are accepted
+	var task Task
+	require.NoError(t, json.Unmarshal([]byte(`{"start_time": "2024-07-05T22:30:00.25+05:30", "end_time": 1720198800250}`), &task))
+	assert.True(t, at.Equal(task.StartDate.Time()))
+	assert.True(t, at.Equal(task.EndDate.Time()))
+	require.NoError(t, json.Unmarshal([]byte(`{"end_time": null}`), &task))
+	assert.True(t, at.Equal(task.EndDate.Time()))
+
+	// Assert that other values are rejected
+	for _, value := range []string{`"05/07/2024"`, `""`, `1.5`, `true`} {
+		assert.Error(t, json.Unmarshal([]byte(`{"end_time": `+value+`}`), &task), value)
+	}
+}
+
+// TestTimestampBSON tests that timestamps are stored as BSON datetimes
+func TestTimestampBSON(t *testing.T) {
+	stamp := NewTimestamp(time.Date(2024, time.July, 5, 17, 0, 0, 0, time.UTC))
+
+	data, err := bson.Marshal(Task{EndDate: stamp})
+	require.NoError(t, err)
+
+	// Assert that the field is a datetime that reads back unchanged
+	assert.Equal(t, bsontype.DateTime, bson.Raw(data).Lookup("end_time").Type)
+	var task Task
+	require.NoError(t, bson.Unmarshal(data, &task))
+	assert.Equal(t, stamp, task.EndDate)
+}
+
+// BenchmarkTaskCodec measures decoding a task as read from MongoDB and encoding it as JSON, the per-task
+// cost of GET /tasks. Run with: go test ./models -run '^$' -bench TaskCodec -benchmem
+func BenchmarkTaskCodec(b *testing.B) {
+	now := time.Now()
+	stored, err := bson.Marshal(Task{Title: "Review", StartDate: NewTimestamp(now), EndDate: NewTimestamp(now.Add(time.Hour)),
+		CreatedAt: NewTimestamp(now), UpdatedAt: NewTimestamp(now)})
+	require.NoError(b, err)
+
+	b.Run("bson", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var task Task
+			if err := bson.Unmarshal(stored, &task); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("json", func(b *testing.B) {
+		var task Task
+		require.NoError(b, bson.Unmarshal(stored, &task))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := json.Marshal(task); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
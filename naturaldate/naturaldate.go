@@ -0,0 +1,276 @@
+// naturaldate.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package naturaldate parses the due dates people type, such as "tomorrow", "next friday 5pm",
+// "in 3 days" or "jul 5 9:30am", relative to the current time in the user's timezone.
+package naturaldate
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultHour is the hour of day used when only a date is given: the end of the working day.
+const DefaultHour = 17
+
+// ErrUnparseable is returned for input that is not a date this package understands.
+var ErrUnparseable = errors.New("naturaldate: cannot parse date")
+
+// fillers are words that carry no meaning for the date, as in "by friday at 5pm".
+var fillers = map[string]bool{"at": true, "on": true, "by": true, "due": true, "the": true}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+var units = map[string]time.Duration{
+	"minute": time.Minute, "minutes": time.Minute, "min": time.Minute, "mins": time.Minute,
+	"hour": time.Hour, "hours": time.Hour, "hr": time.Hour, "hrs": time.Hour,
+	"day": 24 * time.Hour, "days": 24 * time.Hour,
+	"week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+// Parse interprets input relative to now, in now's location. It accepts RFC3339 timestamps and
+// "YYYY-MM-DD[ HH:MM]" dates as well as phrases made of an optional date and an optional time of day:
+//   - dates: today, tonight, tomorrow, a weekday ("friday", "this fri" and "next friday" all mean the first
+//     Friday after today), "next week" (its Monday), "jul 5" or "5 july" (the next such day) and YYYY-MM-DD;
+//   - offsets: "in 3 days", "in 2 hours", "in 1 week";
+//   - times: 5pm, 5:30 pm, 17:00, noon, midnight (23:59, the end of the day).
+//
+// A date without a time is due at DefaultHour (20:00 for "tonight"); a time without a date is due today, or
+// tomorrow if that time has passed.
+//
+// Parameters:
+// - input: The text to parse, case-insensitive.
+// - now: The current time, in the user's timezone.
+//
+// Returns:
+// - time.Time: The parsed time, in now's location.
+// - error: ErrUnparseable if the input is not understood.
+func Parse(input string, now time.Time) (time.Time, error) {
+	input = strings.TrimSpace(input)
+	if t, err := time.Parse(time.RFC3339, input); err == nil {
+		return t.In(now.Location()), nil
+	}
+
+	tokens := []string{}
+	for _, token := range strings.Fields(strings.ToLower(strings.ReplaceAll(input, ",", " "))) {
+		if !fillers[token] {
+			tokens = append(tokens, token)
+		}
+	}
+	if len(tokens) == 0 {
+		return time.Time{}, ErrUnparseable
+	}
+
+	p := parser{tokens: tokens, now: now}
+	return p.parse()
+}
+
+// parser consumes the tokens of one input.
+type parser struct {
+	tokens []string
+	now    time.Time
+}
+
+func (p *parser) parse() (time.Time, error) {
+	today := time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+
+	// An offset is relative to now and may only be refined by a time of day for day-sized units
+	if p.peek(0) == "in" {
+		n, err := strconv.Atoi(p.peek(1))
+		unit, ok := units[p.peek(2)]
+		if err != nil || !ok || n <= 0 {
+			return time.Time{}, ErrUnparseable
+		}
+		p.tokens = p.tokens[3:]
+		if len(p.tokens) == 0 {
+			return p.now.Add(time.Duration(n) * unit), nil
+		}
+		clock, ok := p.clock()
+		if !ok || unit < 24*time.Hour || len(p.tokens) > 0 {
+			return time.Time{}, ErrUnparseable
+		}
+		return at(today.AddDate(0, 0, n*int(unit/(24*time.Hour))), clock), nil
+	}
+
+	date, defaultHour, hasDate := p.date(today)
+	clock, hasClock := p.clock()
+	if !hasDate && hasClock {
+		// The time may also come first, as in "5pm tomorrow"
+		date, defaultHour, hasDate = p.date(today)
+	}
+	if len(p.tokens) > 0 || (!hasDate && !hasClock) {
+		return time.Time{}, ErrUnparseable
+	}
+
+	switch {
+	case !hasClock:
+		return at(date, time.Duration(defaultHour)*time.Hour), nil
+	case !hasDate:
+		due := at(today, clock)
+		if !due.After(p.now) {
+			due = at(today.AddDate(0, 0, 1), clock)
+		}
+		return due, nil
+	default:
+		return at(date, clock), nil
+	}
+}
+
+// date consumes a date phrase, returning the day and its default hour.
+func (p *parser) date(today time.Time) (time.Time, int, bool) {
+	token := p.peek(0)
+	switch token {
+	case "today":
+		p.tokens = p.tokens[1:]
+		return today, DefaultHour, true
+	case "tonight":
+		p.tokens = p.tokens[1:]
+		return today, 20, true
+	case "tomorrow", "tmrw":
+		p.tokens = p.tokens[1:]
+		return today.AddDate(0, 0, 1), DefaultHour, true
+	}
+
+	if day, err := time.ParseInLocation(time.DateOnly, token, today.Location()); err == nil {
+		p.tokens = p.tokens[1:]
+		return day, DefaultHour, true
+	}
+
+	if token == "this" || token == "next" {
+		if p.peek(1) == "week" && token == "next" {
+			p.tokens = p.tokens[2:]
+			days := (int(time.Monday) - int(today.Weekday()) + 7) % 7
+			if days == 0 {
+				days = 7
+			}
+			return today.AddDate(0, 0, days), DefaultHour, true
+		}
+		if _, ok := weekdays[p.peek(1)]; !ok {
+			return time.Time{}, 0, false
+		}
+		p.tokens = p.tokens[1:]
+		token = p.peek(0)
+	}
+	if weekday, ok := weekdays[token]; ok {
+		p.tokens = p.tokens[1:]
+		days := (int(weekday) - int(today.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return today.AddDate(0, 0, days), DefaultHour, true
+	}
+
+	// "jul 5" or "5 jul", the next such day
+	month, ok := months[token]
+	dayToken := p.peek(1)
+	if !ok {
+		month, ok = months[p.peek(1)]
+		dayToken = token
+	}
+	if !ok {
+		return time.Time{}, 0, false
+	}
+	dayOfMonth, err := strconv.Atoi(strings.TrimRight(dayToken, "stndrh"))
+	if err != nil || dayOfMonth < 1 || dayOfMonth > 31 {
+		return time.Time{}, 0, false
+	}
+	day := time.Date(today.Year(), month, dayOfMonth, 0, 0, 0, 0, today.Location())
+	if day.Day() != dayOfMonth {
+		return time.Time{}, 0, false // e.g. "feb 30"
+	}
+	if day.Before(today) {
+		day = day.AddDate(1, 0, 0)
+	}
+	p.tokens = p.tokens[2:]
+	return day, DefaultHour, true
+}
+
+// clock consumes a time of day, returning it as the offset from midnight.
+func (p *parser) clock() (time.Duration, bool) {
+	token := p.peek(0)
+	switch token {
+	case "noon", "midday":
+		p.tokens = p.tokens[1:]
+		return 12 * time.Hour, true
+	case "midnight":
+		p.tokens = p.tokens[1:]
+		return 24*time.Hour - time.Minute, true
+	case "":
+		return 0, false
+	}
+
+	// The meridiem may be a separate token, as in "5 pm"
+	consumed := 1
+	if next := p.peek(1); next == "am" || next == "pm" {
+		token += next
+		consumed = 2
+	}
+
+	meridiem := ""
+	if strings.HasSuffix(token, "am") || strings.HasSuffix(token, "pm") {
+		meridiem = token[len(token)-2:]
+		token = token[:len(token)-2]
+	}
+	hourPart, minutePart, hasMinutes := strings.Cut(token, ":")
+	hour, err := strconv.Atoi(hourPart)
+	if err != nil || (meridiem == "" && !hasMinutes) {
+		return 0, false
+	}
+	minute := 0
+	if hasMinutes {
+		if minute, err = strconv.Atoi(minutePart); err != nil || len(minutePart) != 2 || minute > 59 {
+			return 0, false
+		}
+	}
+
+	switch {
+	case meridiem == "" && hour > 23,
+		meridiem != "" && (hour < 1 || hour > 12):
+		return 0, false
+	case meridiem == "am" && hour == 12:
+		hour = 0
+	case meridiem == "pm" && hour != 12:
+		hour += 12
+	}
+	p.tokens = p.tokens[consumed:]
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, true
+}
+
+// peek returns the i-th remaining token, or "" past the end.
+func (p *parser) peek(i int) string {
+	if i < len(p.tokens) {
+		return p.tokens[i]
+	}
+	return ""
+}
+
+// at returns the time of day clock on day.
+func at(day time.Time, clock time.Duration) time.Time {
+	hour, minute := int(clock/time.Hour), int(clock%time.Hour/time.Minute)
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+}
@@ -0,0 +1,68 @@
+// naturaldate_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package naturaldate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParse tests the supported phrases relative to a fixed time in a non-UTC zone
+func TestParse(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+	// Wednesday, 10 July 2024, 14:30
+	now := time.Date(2024, time.July, 10, 14, 30, 0, 0, kolkata)
+	day := func(month time.Month, d, hour, minute int) time.Time {
+		return time.Date(2024, month, d, hour, minute, 0, 0, kolkata)
+	}
+
+	cases := map[string]time.Time{
+		"today":                     day(time.July, 10, 17, 0),
+		"tonight":                   day(time.July, 10, 20, 0),
+		"Tomorrow":                  day(time.July, 11, 17, 0),
+		"tomorrow 9am":              day(time.July, 11, 9, 0),
+		"5pm tomorrow":              day(time.July, 11, 17, 0),
+		"friday":                    day(time.July, 12, 17, 0),
+		"next friday 5pm":           day(time.July, 12, 17, 0),
+		"by this fri at 5:30 pm":    day(time.July, 12, 17, 30),
+		"wednesday":                 day(time.July, 17, 17, 0),
+		"next week":                 day(time.July, 15, 17, 0),
+		"jul 20":                    day(time.July, 20, 17, 0),
+		"20th july noon":            day(time.July, 20, 12, 0),
+		"2024-08-01":                day(time.August, 1, 17, 0),
+		"2024-08-01 09:15":          day(time.August, 1, 9, 15),
+		"in 3 days":                 now.Add(72 * time.Hour),
+		"in 2 hours":                now.Add(2 * time.Hour),
+		"in 1 week at 10am":         day(time.July, 17, 10, 0),
+		"6pm":                       day(time.July, 10, 18, 0),
+		"9am":                       day(time.July, 11, 9, 0),
+		"12am":                      day(time.July, 11, 0, 0),
+		"2024-07-12T10:00:00Z":      time.Date(2024, time.July, 12, 10, 0, 0, 0, time.UTC),
+		"2024-07-12T10:00:00+05:30": day(time.July, 12, 10, 0),
+	}
+	for input, expected := range cases {
+		parsed, err := Parse(input, now)
+
+		// Assert that the phrase is understood and lands on the expected instant in the user's zone
+		if assert.NoError(t, err, input) {
+			assert.True(t, expected.Equal(parsed), "%s: expected %s, got %s", input, expected, parsed)
+			assert.Equal(t, kolkata, parsed.Location(), input)
+		}
+	}
+
+	// Assert that a day already past this year means next year
+	parsed, err := Parse("jan 5", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, time.January, 5, 17, 0, 0, 0, kolkata), parsed)
+
+	// Assert that unknown or incomplete phrases are rejected
+	for _, input := range []string{"", "soon", "next", "in days", "in 2 hours 5pm", "friday banana", "25pm", "13am", "feb 30", "5"} {
+		_, err := Parse(input, now)
+		assert.ErrorIs(t, err, ErrUnparseable, input)
+	}
+}
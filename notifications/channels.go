@@ -0,0 +1,182 @@
+// channels.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+)
+
+// Channels a notification can be delivered through
+const (
+	ChannelWebhook  = "webhook"  // The service-wide NOTIFY_WEBHOOK_URL
+	ChannelSlack    = "slack"    // The recipient's Slack webhook
+	ChannelTeams    = "teams"    // The recipient's Teams connector
+	ChannelTelegram = "telegram" // The recipient's Telegram chat with the bot
+)
+
+// ValidateSettings checks that the chat webhooks point to Slack and Teams over HTTPS, so user input cannot
+// make the server call arbitrary URLs, that only known events and channels are chosen, and that quiet hours
+// are valid times.
+//
+// Parameters:
+// - settings: The notification settings to validate.
+//
+// Returns:
+// - error: A descriptive error if any field is invalid.
+func ValidateSettings(settings models.NotificationSettings) error {
+	if settings.SlackWebhookURL != "" && !webhookHost(settings.SlackWebhookURL, "hooks.slack.com") {
+		return errors.New("slack_webhook_url must be a https://hooks.slack.com/ URL")
+	}
+	if settings.TeamsWebhookURL != "" && !webhookHost(settings.TeamsWebhookURL, "outlook.office.com", ".webhook.office.com", ".logic.azure.com") {
+		return errors.New("teams_webhook_url must be a https:// Teams connector URL (webhook.office.com)")
+	}
+	for _, event := range settings.Events {
+		if !contains(Events, event) {
+			return fmt.Errorf("unknown event %q, events are %s", event, strings.Join(Events, ", "))
+		}
+	}
+	return validatePreferences(settings)
+}
+
+// webhookHost reports whether rawURL is an https URL on one of the hosts; a host starting with
+// "." matches its subdomains.
+func webhookHost(rawURL string, hosts ...string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.User != nil || u.Port() != "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range hosts {
+		if host == allowed || (strings.HasPrefix(allowed, ".") && strings.HasSuffix(host, allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// subscribed reports whether the settings include the event.
+func subscribed(settings *models.NotificationSettings, event string) bool {
+	return len(settings.Events) == 0 || contains(settings.Events, event)
+}
+
+// contains reports whether list contains value.
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Router fans a notification out to every channel it should reach, queuing one notification per
+// channel so that each is retried and dead-lettered on its own.
+type Router struct {
+	Queue   Notifier // Receives one notification per channel, usually a Dispatcher delivering through Channels
+	Webhook bool     // Also deliver every notification to the service-wide webhook
+}
+
+// Notify queues the notification for the service-wide webhook and the recipient's chat channels that Wants
+// allows.
+func (r Router) Notify(ctx context.Context, notification Notification) error {
+	channels := []string{}
+	if r.Webhook {
+		channels = append(channels, ChannelWebhook)
+	}
+
+	user, err := repository.Users.FindByUsername(ctx, notification.Recipient)
+	if err != nil && err != repository.ErrNotFound {
+		return err
+	}
+	if err == nil {
+		now := time.Now()
+		if user.Notifications != nil && user.Notifications.SlackWebhookURL != "" && Wants(user, notification, ChannelSlack, now) {
+			channels = append(channels, ChannelSlack)
+		}
+		if user.Notifications != nil && user.Notifications.TeamsWebhookURL != "" && Wants(user, notification, ChannelTeams, now) {
+			channels = append(channels, ChannelTeams)
+		}
+		if user.TelegramChat != 0 && Wants(user, notification, ChannelTelegram, now) {
+			channels = append(channels, ChannelTelegram)
+		}
+	}
+
+	var errs []error
+	for _, channel := range channels {
+		notification.Channel = channel
+		if err := r.Queue.Notify(ctx, notification); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// TelegramSender sends text messages to Telegram chats, e.g. a telegram.Bot.
+type TelegramSender interface {
+	SendMessage(ctx context.Context, chatID int64, text string) error
+}
+
+// Channels delivers a notification through the channel chosen by Router. Chat webhooks and preferences are
+// looked up at delivery time, so a replayed notification reaches the recipient's current channels, and a
+// notification queued before the recipient's quiet hours began is dropped rather than sent during them.
+type Channels struct {
+	Webhook  Notifier       // Service-wide webhook; notifications for it fail when nil
+	Client   *http.Client   // HTTP client for Slack and Teams; http.DefaultClient when nil
+	Telegram TelegramSender // Telegram bot; notifications for it fail when nil
+}
+
+// Notify delivers the notification through its channel.
+func (c Channels) Notify(ctx context.Context, notification Notification) error {
+	if notification.Channel == ChannelWebhook {
+		if c.Webhook == nil {
+			return errors.New("no webhook configured")
+		}
+		return c.Webhook.Notify(ctx, notification)
+	}
+
+	user, err := repository.Users.FindByUsername(ctx, notification.Recipient)
+	if err != nil {
+		return fmt.Errorf("recipient %s: %w", notification.Recipient, err)
+	}
+	if !Wants(user, notification, notification.Channel, time.Now()) {
+		log.Printf("Dropped %s notification to %s on %s: not wanted now", notification.Event, notification.Recipient, notification.Channel)
+		return nil
+	}
+	settings := user.Notifications
+	if settings == nil {
+		settings = &models.NotificationSettings{}
+	}
+
+	switch notification.Channel {
+	case ChannelSlack:
+		if settings.SlackWebhookURL == "" {
+			return errors.New("recipient has no Slack webhook")
+		}
+		return Slack{URL: settings.SlackWebhookURL, Client: c.Client}.Notify(ctx, notification)
+	case ChannelTeams:
+		if settings.TeamsWebhookURL == "" {
+			return errors.New("recipient has no Teams webhook")
+		}
+		return Teams{URL: settings.TeamsWebhookURL, Client: c.Client}.Notify(ctx, notification)
+	case ChannelTelegram:
+		if c.Telegram == nil {
+			return errors.New("no Telegram bot configured")
+		}
+		if user.TelegramChat == 0 {
+			return errors.New("recipient has no Telegram chat")
+		}
+		return c.Telegram.SendMessage(ctx, user.TelegramChat, notification.Subject+"\n"+notification.Message)
+	}
+	return fmt.Errorf("unknown channel %q", notification.Channel)
+}
@@ -0,0 +1,179 @@
+// channels_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidateSettings tests that only Slack and Teams HTTPS webhooks and known events are accepted
+func TestValidateSettings(t *testing.T) {
+	assert.NoError(t, ValidateSettings(models.NotificationSettings{}))
+	assert.NoError(t, ValidateSettings(models.NotificationSettings{
+		SlackWebhookURL: "https://hooks.slack.com/services/T0/B0/x",
+		TeamsWebhookURL: "https://contoso.webhook.office.com/webhookb2/x",
+		Events:          []string{EventTaskAssigned},
+	}))
+
+	// Assert that other hosts, schemes and events are rejected
+	assert.Error(t, ValidateSettings(models.NotificationSettings{SlackWebhookURL: "http://hooks.slack.com/services/x"}))
+	assert.Error(t, ValidateSettings(models.NotificationSettings{SlackWebhookURL: "https://hooks.slack.com.evil.test/x"}))
+	assert.Error(t, ValidateSettings(models.NotificationSettings{SlackWebhookURL: "https://hooks.slack.com:8443/x"}))
+	assert.Error(t, ValidateSettings(models.NotificationSettings{TeamsWebhookURL: "https://webhook.office.com.evil.test/x"}))
+	assert.Error(t, ValidateSettings(models.NotificationSettings{TeamsWebhookURL: "https://169.254.169.254/latest"}))
+	assert.Error(t, ValidateSettings(models.NotificationSettings{Events: []string{"task.exploded"}}))
+}
+
+// TestValidatePreferences tests that channels per event and quiet hours are checked
+func TestValidatePreferences(t *testing.T) {
+	assert.NoError(t, ValidateSettings(models.NotificationSettings{
+		Channels:   map[string][]string{EventTaskOverdue: {ChannelTelegram}, EventTaskMentioned: {}},
+		QuietHours: &models.QuietHours{Start: "22:00", End: "07:00"},
+		DigestOnly: true,
+	}))
+
+	assert.Error(t, ValidateSettings(models.NotificationSettings{Channels: map[string][]string{"task.exploded": {ChannelSlack}}}))
+	assert.Error(t, ValidateSettings(models.NotificationSettings{Channels: map[string][]string{EventTaskOverdue: {ChannelWebhook}}}))
+	assert.Error(t, ValidateSettings(models.NotificationSettings{QuietHours: &models.QuietHours{Start: "10pm", End: "07:00"}}))
+	assert.Error(t, ValidateSettings(models.NotificationSettings{QuietHours: &models.QuietHours{Start: "22:00", End: "24:00"}}))
+	assert.Error(t, ValidateSettings(models.NotificationSettings{QuietHours: &models.QuietHours{Start: "07:00", End: "07:00"}}))
+}
+
+// TestQuietHours tests quiet hours within a day and across midnight, in the user's timezone
+func TestQuietHours(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+	at := func(hour, minute int) time.Time { return time.Date(2024, 7, 10, hour, minute, 0, 0, kolkata) }
+
+	night := &models.QuietHours{Start: "22:00", End: "07:00"}
+	assert.True(t, InQuietHours(night, at(23, 30), kolkata))
+	assert.True(t, InQuietHours(night, at(6, 59), kolkata))
+	assert.False(t, InQuietHours(night, at(7, 0), kolkata))
+	assert.False(t, InQuietHours(night, at(21, 59), kolkata))
+	assert.False(t, InQuietHours(nil, at(23, 30), kolkata))
+
+	lunch := &models.QuietHours{Start: "12:30", End: "13:30"}
+	assert.True(t, InQuietHours(lunch, at(12, 30), kolkata))
+	assert.False(t, InQuietHours(lunch, at(13, 30), kolkata))
+
+	// 17:00 UTC is 22:30 in Kolkata
+	user := &models.User{Timezone: "Asia/Kolkata", TelegramChat: 1, Notifications: &models.NotificationSettings{QuietHours: night}}
+	notification := Notification{Event: EventTaskAssigned}
+	assert.False(t, Wants(user, notification, ChannelTelegram, time.Date(2024, 7, 10, 17, 0, 0, 0, time.UTC)))
+	assert.True(t, Wants(user, notification, ChannelTelegram, time.Date(2024, 7, 10, 12, 0, 0, 0, time.UTC)))
+}
+
+// TestRouter tests that a notification is queued once per channel the recipient subscribed to
+func TestRouter(t *testing.T) {
+	repository.UseMemory()
+	ctx := context.Background()
+	require.NoError(t, repository.Users.Create(ctx, &models.User{Username: "chatty", Notifications: &models.NotificationSettings{
+		SlackWebhookURL: "https://hooks.slack.com/services/x",
+		TeamsWebhookURL: "https://contoso.webhook.office.com/x",
+	}}))
+	require.NoError(t, repository.Users.Create(ctx, &models.User{Username: "muted", Notifications: &models.NotificationSettings{
+		SlackWebhookURL: "https://hooks.slack.com/services/y",
+		Events:          []string{"task.other"},
+	}}))
+	require.NoError(t, repository.Users.Create(ctx, &models.User{Username: "linked", TelegramChat: 42}))
+	require.NoError(t, repository.Users.Create(ctx, &models.User{Username: "picky", TelegramChat: 7, Notifications: &models.NotificationSettings{
+		SlackWebhookURL: "https://hooks.slack.com/services/z",
+		Channels:        map[string][]string{EventTaskAssigned: {ChannelTelegram}},
+	}}))
+	require.NoError(t, repository.Users.Create(ctx, &models.User{Username: "reader", TelegramChat: 8, Notifications: &models.NotificationSettings{
+		SlackWebhookURL: "https://hooks.slack.com/services/w",
+		DigestOnly:      true,
+	}}))
+
+	var queued []string
+	router := Router{Queue: NotifierFunc(func(ctx context.Context, notification Notification) error {
+		queued = append(queued, notification.Recipient+":"+notification.Channel)
+		return nil
+	}), Webhook: true}
+
+	for _, recipient := range []string{"chatty", "muted", "linked", "picky", "reader", "unknown"} {
+		require.NoError(t, router.Notify(ctx, Notification{Event: EventTaskAssigned, Recipient: recipient}))
+	}
+
+	// Assert that the webhook gets everything and chat channels only subscribed events on chosen channels
+	assert.Equal(t, []string{
+		"chatty:webhook", "chatty:slack", "chatty:teams", "muted:webhook", "linked:webhook", "linked:telegram",
+		"picky:webhook", "picky:telegram", "reader:webhook", "unknown:webhook",
+	}, queued)
+}
+
+// telegramMessages records the messages sent through it as a TelegramSender.
+type telegramMessages struct {
+	sent map[int64]string
+}
+
+func (m *telegramMessages) SendMessage(ctx context.Context, chatID int64, text string) error {
+	if m.sent == nil {
+		m.sent = map[int64]string{}
+	}
+	m.sent[chatID] = text
+	return nil
+}
+
+// TestChannels tests that Slack, Teams and Telegram messages reach the recipient's current channels
+func TestChannels(t *testing.T) {
+	repository.UseMemory()
+	ctx := context.Background()
+
+	bodies := map[string]map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies[r.URL.Path] = body
+	}))
+	defer server.Close()
+
+	require.NoError(t, repository.Users.Create(ctx, &models.User{Username: "alice", Notifications: &models.NotificationSettings{
+		SlackWebhookURL: server.URL + "/slack",
+		TeamsWebhookURL: server.URL + "/teams",
+	}}))
+	require.NoError(t, repository.Users.Create(ctx, &models.User{Username: "bob", TelegramChat: 42}))
+
+	telegram := &telegramMessages{}
+	channels := Channels{Telegram: telegram}
+	notification := Notification{Event: EventTaskAssigned, Recipient: "alice", Subject: "New task: Ship", Message: "You have been assigned the task \"Ship\"."}
+
+	notification.Channel = ChannelSlack
+	require.NoError(t, channels.Notify(ctx, notification))
+	notification.Channel = ChannelTeams
+	require.NoError(t, channels.Notify(ctx, notification))
+
+	// Assert that each service receives its own message format
+	assert.Equal(t, "*New task: Ship*\nYou have been assigned the task \"Ship\".", bodies["/slack"]["text"])
+	assert.Equal(t, "MessageCard", bodies["/teams"]["@type"])
+	assert.Equal(t, "New task: Ship", bodies["/teams"]["title"])
+
+	// Assert that Telegram messages go to the linked chat
+	require.NoError(t, channels.Notify(ctx, Notification{Recipient: "bob", Channel: ChannelTelegram, Subject: "Overdue: Ship", Message: "\"Ship\" was due yesterday."}))
+	assert.Equal(t, map[int64]string{42: "Overdue: Ship\n\"Ship\" was due yesterday."}, telegram.sent)
+	notification.Channel = ChannelTelegram
+	assert.Error(t, channels.Notify(ctx, notification))
+
+	// Assert that missing channels and webhooks are delivery errors
+	notification.Channel = ChannelWebhook
+	assert.Error(t, channels.Notify(ctx, notification))
+	assert.Error(t, channels.Notify(ctx, Notification{Recipient: "bob", Channel: ChannelSlack}))
+	assert.Error(t, channels.Notify(ctx, Notification{Recipient: "nobody", Channel: ChannelTeams}))
+
+	// Assert that a notification queued before the recipient turned on digest-only mode is dropped
+	require.NoError(t, repository.Users.Create(ctx, &models.User{Username: "carol", TelegramChat: 43, Notifications: &models.NotificationSettings{DigestOnly: true}}))
+	require.NoError(t, channels.Notify(ctx, Notification{Recipient: "carol", Channel: ChannelTelegram, Subject: "Overdue: Ship"}))
+	assert.NotContains(t, telegram.sent, int64(43))
+}
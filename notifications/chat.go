@@ -0,0 +1,39 @@
+// chat.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package notifications
+
+import (
+	"context"
+	"net/http"
+)
+
+// Slack delivers notifications to a Slack incoming webhook.
+type Slack struct {
+	URL    string       // Incoming webhook URL, https://hooks.slack.com/services/...
+	Client *http.Client // HTTP client to use; http.DefaultClient when nil
+}
+
+// Notify posts the notification as a Slack message with the subject in bold.
+func (s Slack) Notify(ctx context.Context, notification Notification) error {
+	return postJSON(ctx, s.Client, s.URL, map[string]string{
+		"text": "*" + notification.Subject + "*\n" + notification.Message,
+	})
+}
+
+// Teams delivers notifications to a Microsoft Teams incoming webhook connector.
+type Teams struct {
+	URL    string       // Connector URL, https://<tenant>.webhook.office.com/...
+	Client *http.Client // HTTP client to use; http.DefaultClient when nil
+}
+
+// Notify posts the notification as a Teams message card.
+func (t Teams) Notify(ctx context.Context, notification Notification) error {
+	return postJSON(ctx, t.Client, t.URL, map[string]string{
+		"@type":    "MessageCard",
+		"@context": "https://schema.org/extensions",
+		"summary":  notification.Subject,
+		"title":    notification.Subject,
+		"text":     notification.Message,
+	})
+}
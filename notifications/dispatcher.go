@@ -0,0 +1,246 @@
+// dispatcher.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package notifications
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bkojha74/task-management/deadletter"
+	"github.com/bkojha74/task-management/helper"
+)
+
+var (
+	// ErrQueueFull is returned when a notification cannot be queued because all workers are behind.
+	ErrQueueFull = errors.New("notification queue is full")
+	// ErrClosed is returned when a notification is sent to a dispatcher that was closed.
+	ErrClosed = errors.New("notification dispatcher is closed")
+)
+
+// maxBackoff caps the delay between two delivery attempts.
+const maxBackoff = time.Minute
+
+// DispatcherConfig sizes the worker pool and the retry policy of a Dispatcher.
+type DispatcherConfig struct {
+	Workers     int           // Number of concurrent deliveries
+	QueueSize   int           // Notifications waiting for a worker before new ones are dead-lettered
+	MaxAttempts int           // Delivery attempts before a notification is dead-lettered
+	Backoff     time.Duration // Delay before the first retry, doubled for every further retry
+	Timeout     time.Duration // Time limit of a single delivery attempt
+}
+
+// LoadDispatcherConfig reads the NOTIFY_WORKERS, NOTIFY_QUEUE_SIZE, NOTIFY_MAX_ATTEMPTS,
+// NOTIFY_BACKOFF_MS and NOTIFY_TIMEOUT (seconds) environment variables.
+//
+// Returns:
+// - DispatcherConfig: The configured settings.
+func LoadDispatcherConfig() DispatcherConfig {
+	return DispatcherConfig{
+		Workers:     helper.GetEnvInt("NOTIFY_WORKERS", 4),
+		QueueSize:   helper.GetEnvInt("NOTIFY_QUEUE_SIZE", 1000),
+		MaxAttempts: helper.GetEnvInt("NOTIFY_MAX_ATTEMPTS", 5),
+		Backoff:     time.Duration(helper.GetEnvInt("NOTIFY_BACKOFF_MS", 500)) * time.Millisecond,
+		Timeout:     time.Duration(helper.GetEnvInt("NOTIFY_TIMEOUT", 10)) * time.Second,
+	}
+}
+
+// Dispatcher is a Notifier that queues notifications and delivers them through a sender on a bounded
+// pool of workers, so slow or failing channels never block request handlers. Failed deliveries are
+// retried with exponential backoff; notifications that still fail, or that find the queue full, are
+// recorded in the dead-letter log for inspection and replay.
+type Dispatcher struct {
+	// DeadLetter records undeliverable notifications; it defaults to deadletter.Record.
+	DeadLetter func(ctx context.Context, kind string, payload map[string]interface{}, cause error) error
+
+	sender Notifier
+	config DispatcherConfig
+	queue  chan Notification
+	abort  chan struct{}
+	wg     sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewDispatcher starts a dispatcher delivering through sender.
+//
+// Parameters:
+// - sender: The notifier doing the actual delivery, e.g. a Webhook.
+// - config: The worker pool and retry settings; non-positive values fall back to one worker,
+// an unbuffered queue and a single attempt.
+//
+// Returns:
+// - *Dispatcher: The running dispatcher. Close it on shutdown.
+func NewDispatcher(sender Notifier, config DispatcherConfig) *Dispatcher {
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.QueueSize < 0 {
+		config.QueueSize = 0
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 1
+	}
+
+	d := &Dispatcher{
+		DeadLetter: deadletter.Record,
+		sender:     sender,
+		config:     config,
+		queue:      make(chan Notification, config.QueueSize),
+		abort:      make(chan struct{}),
+	}
+	d.wg.Add(config.Workers)
+	for i := 0; i < config.Workers; i++ {
+		go d.work()
+	}
+	return d
+}
+
+// Notify queues a notification without waiting for its delivery. When the queue is full the
+// notification is dead-lettered and ErrQueueFull is returned.
+func (d *Dispatcher) Notify(ctx context.Context, notification Notification) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrClosed
+	}
+
+	select {
+	case d.queue <- notification:
+		return nil
+	default:
+		d.deadLetter(notification, ErrQueueFull)
+		return ErrQueueFull
+	}
+}
+
+// Close stops accepting notifications and waits for the queued ones to be delivered. When ctx ends
+// first, pending retries are abandoned and the remaining notifications are dead-lettered.
+//
+// Parameters:
+// - ctx: Bounds how long to wait for the queue to drain.
+//
+// Returns:
+// - error: ctx.Err() if the queue did not drain in time.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	close(d.queue)
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		close(d.abort)
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Replay delivers a dead-lettered notification once, synchronously. It is registered as the
+// deadletter.Replayer of notification dead letters.
+//
+// Parameters:
+// - ctx: Context for the delivery.
+// - payload: The dead letter payload, as produced by the dispatcher.
+//
+// Returns:
+// - error: The delivery error, if any.
+func (d *Dispatcher) Replay(ctx context.Context, payload map[string]interface{}) error {
+	return d.attempt(ctx, fromPayload(payload))
+}
+
+// work delivers queued notifications until the queue is closed.
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for notification := range d.queue {
+		d.deliver(notification)
+	}
+}
+
+// deliver sends a notification, retrying with exponential backoff, and dead-letters it when all attempts fail.
+func (d *Dispatcher) deliver(notification Notification) {
+	backoff := d.config.Backoff
+	var err error
+	for attempt := 1; attempt <= d.config.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(backoff):
+			case <-d.abort:
+				d.deadLetter(notification, err)
+				return
+			}
+			if backoff *= 2; backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+
+		if err = d.attempt(context.Background(), notification); err == nil {
+			return
+		}
+		log.Printf("Delivery attempt %d of %s notification to %s failed: %v", attempt, notification.Event, notification.Recipient, err)
+	}
+	d.deadLetter(notification, err)
+}
+
+// attempt makes a single delivery attempt within the configured timeout.
+func (d *Dispatcher) attempt(ctx context.Context, notification Notification) error {
+	if d.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.config.Timeout)
+		defer cancel()
+	}
+	return d.sender.Notify(ctx, notification)
+}
+
+// deadLetter records an undeliverable notification, logging when even that fails.
+func (d *Dispatcher) deadLetter(notification Notification, cause error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.DeadLetter(ctx, deadletter.KindNotification, ToPayload(notification), cause); err != nil {
+		log.Printf("Could not dead-letter %s notification to %s: %v", notification.Event, notification.Recipient, err)
+	}
+}
+
+// ToPayload converts a notification to a dead letter payload, which Dispatcher.Replay delivers.
+func ToPayload(notification Notification) map[string]interface{} {
+	return map[string]interface{}{
+		"event":     notification.Event,
+		"recipient": notification.Recipient,
+		"task_id":   notification.TaskID,
+		"subject":   notification.Subject,
+		"message":   notification.Message,
+		"channel":   notification.Channel,
+	}
+}
+
+// fromPayload converts a dead letter payload back to a notification; missing fields stay empty.
+func fromPayload(payload map[string]interface{}) Notification {
+	field := func(key string) string {
+		value, _ := payload[key].(string)
+		return value
+	}
+	return Notification{
+		Event:     field("event"),
+		Recipient: field("recipient"),
+		TaskID:    field("task_id"),
+		Subject:   field("subject"),
+		Message:   field("message"),
+		Channel:   field("channel"),
+	}
+}
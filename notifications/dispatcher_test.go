@@ -0,0 +1,159 @@
+// dispatcher_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package notifications
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/deadletter"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deadLetters collects the notifications a dispatcher dead-letters.
+type deadLetters struct {
+	mu       sync.Mutex
+	payloads []map[string]interface{}
+	causes   []error
+}
+
+func (d *deadLetters) record(ctx context.Context, kind string, payload map[string]interface{}, cause error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if kind != deadletter.KindNotification {
+		return errors.New("unexpected kind " + kind)
+	}
+	d.payloads = append(d.payloads, payload)
+	d.causes = append(d.causes, cause)
+	return nil
+}
+
+func (d *deadLetters) count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.payloads)
+}
+
+// TestDispatcherRetries tests that failed deliveries are retried and finally dead-lettered
+func TestDispatcherRetries(t *testing.T) {
+	var mu sync.Mutex
+	attempts := map[string]int{}
+	sender := NotifierFunc(func(ctx context.Context, notification Notification) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[notification.Recipient]++
+		// alice succeeds on the third attempt, bob never does
+		if notification.Recipient == "alice" && attempts["alice"] == 3 {
+			return nil
+		}
+		return errors.New("channel down")
+	})
+
+	sink := &deadLetters{}
+	dispatcher := NewDispatcher(sender, DispatcherConfig{Workers: 2, QueueSize: 10, MaxAttempts: 3, Backoff: time.Millisecond})
+	dispatcher.DeadLetter = sink.record
+
+	require.NoError(t, dispatcher.Notify(context.Background(), Notification{Event: EventTaskAssigned, Recipient: "alice"}))
+	require.NoError(t, dispatcher.Notify(context.Background(), Notification{Event: EventTaskAssigned, Recipient: "bob", TaskID: "t1"}))
+	require.NoError(t, dispatcher.Close(context.Background()))
+
+	// Assert that both were tried MaxAttempts times and only the failing one was dead-lettered
+	assert.Equal(t, 3, attempts["alice"])
+	assert.Equal(t, 3, attempts["bob"])
+	require.Equal(t, 1, sink.count())
+	assert.Equal(t, "bob", sink.payloads[0]["recipient"])
+	assert.EqualError(t, sink.causes[0], "channel down")
+
+	// Assert that a closed dispatcher refuses notifications
+	assert.ErrorIs(t, dispatcher.Notify(context.Background(), Notification{}), ErrClosed)
+}
+
+// TestDispatcherQueueFull tests that Notify never blocks and dead-letters what the queue cannot hold
+func TestDispatcherQueueFull(t *testing.T) {
+	release := make(chan struct{})
+	sender := NotifierFunc(func(ctx context.Context, notification Notification) error {
+		<-release
+		return nil
+	})
+
+	sink := &deadLetters{}
+	dispatcher := NewDispatcher(sender, DispatcherConfig{Workers: 1, QueueSize: 1, MaxAttempts: 1})
+	dispatcher.DeadLetter = sink.record
+
+	// One notification is being delivered, one waits in the queue, the third does not fit
+	require.NoError(t, dispatcher.Notify(context.Background(), Notification{Recipient: "a"}))
+	require.Eventually(t, func() bool { return len(dispatcher.queue) == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, dispatcher.Notify(context.Background(), Notification{Recipient: "b"}))
+	assert.ErrorIs(t, dispatcher.Notify(context.Background(), Notification{Recipient: "c"}), ErrQueueFull)
+
+	// Assert that the rejected notification was dead-lettered with the queue error
+	require.Equal(t, 1, sink.count())
+	assert.Equal(t, "c", sink.payloads[0]["recipient"])
+	assert.ErrorIs(t, sink.causes[0], ErrQueueFull)
+
+	close(release)
+	require.NoError(t, dispatcher.Close(context.Background()))
+}
+
+// TestDispatcherCloseTimeout tests that Close abandons retries when its context ends
+func TestDispatcherCloseTimeout(t *testing.T) {
+	sender := NotifierFunc(func(ctx context.Context, notification Notification) error {
+		return errors.New("channel down")
+	})
+
+	sink := &deadLetters{}
+	dispatcher := NewDispatcher(sender, DispatcherConfig{Workers: 1, QueueSize: 1, MaxAttempts: 5, Backoff: time.Hour})
+	dispatcher.DeadLetter = sink.record
+	require.NoError(t, dispatcher.Notify(context.Background(), Notification{Recipient: "alice"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// Assert that the pending retry is dead-lettered instead of waiting an hour
+	assert.ErrorIs(t, dispatcher.Close(ctx), context.DeadlineExceeded)
+	assert.Equal(t, 1, sink.count())
+}
+
+// TestDispatcherReplay tests that a dead letter payload is delivered again unchanged
+func TestDispatcherReplay(t *testing.T) {
+	var received Notification
+	sender := NotifierFunc(func(ctx context.Context, notification Notification) error {
+		received = notification
+		return nil
+	})
+	dispatcher := NewDispatcher(sender, DispatcherConfig{})
+	defer dispatcher.Close(context.Background())
+
+	notification := Notification{Event: EventTaskAssigned, Recipient: "alice", TaskID: "t1", Subject: "s", Message: "m", Channel: ChannelSlack}
+	require.NoError(t, dispatcher.Replay(context.Background(), ToPayload(notification)))
+
+	// Assert that the payload round-trips
+	assert.Equal(t, notification, received)
+}
+
+// TestWebhook tests that notifications are posted as JSON and non-2xx answers are failures
+func TestWebhook(t *testing.T) {
+	status := http.StatusNoContent
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	webhook := Webhook{URL: server.URL}
+	assert.NoError(t, webhook.Notify(context.Background(), Notification{Recipient: "alice"}))
+	assert.Equal(t, "application/json", contentType)
+
+	// Assert that server errors are reported
+	status = http.StatusBadGateway
+	assert.Error(t, webhook.Notify(context.Background(), Notification{Recipient: "alice"}))
+}
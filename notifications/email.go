@@ -0,0 +1,185 @@
+// email.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package notifications
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/helper"
+)
+
+// SMTP sends HTML emails through a mail server, e.g. digests. The connection is upgraded with STARTTLS
+// when the server offers it, and credentials are only sent over TLS.
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string // Empty for servers that relay without authentication
+	Password string
+	From     string // Sender address, e.g. "tasks@example.com"
+}
+
+// LoadSMTP reads the SMTP_HOST, SMTP_PORT (default 587), SMTP_USERNAME, SMTP_PASSWORD and SMTP_FROM
+// environment variables.
+//
+// Returns:
+// - *SMTP: The mail server, or nil if SMTP_HOST is not set.
+// - error: An error if the sender address is missing or invalid.
+func LoadSMTP() (*SMTP, error) {
+	host := helper.GetEnv("SMTP_HOST")
+	if host == "" {
+		return nil, nil
+	}
+	server := &SMTP{
+		Host:     host,
+		Port:     helper.GetEnvInt("SMTP_PORT", 587),
+		Username: helper.GetEnv("SMTP_USERNAME"),
+		Password: helper.GetEnv("SMTP_PASSWORD"),
+		From:     helper.GetEnv("SMTP_FROM"),
+	}
+	if _, err := mail.ParseAddress(server.From); err != nil {
+		return nil, errors.New("SMTP_FROM must be an email address")
+	}
+	return server, nil
+}
+
+// Attachment is a file attached to an email.
+type Attachment struct {
+	Filename    string
+	ContentType string // e.g. application/pdf
+	Data        []byte
+}
+
+// SendMail sends an HTML email.
+//
+// Parameters:
+// - ctx: Context bounding the delivery; without a deadline it is limited to 30 seconds.
+// - sender: Display name of the sender, e.g. the workspace's branding.
+// - to: Address of the recipient.
+// - subject: The subject line.
+// - html: The HTML body.
+//
+// Returns:
+// - error: An error if the server could not be reached or refused the email.
+func (s *SMTP) SendMail(ctx context.Context, sender, to, subject, html string) error {
+	return s.SendMailWithAttachments(ctx, sender, to, subject, html, nil)
+}
+
+// SendMailWithAttachments sends an HTML email with files attached, like SendMail.
+//
+// Parameters:
+// - ctx: Context bounding the delivery; without a deadline it is limited to 30 seconds.
+// - sender: Display name of the sender.
+// - to: Address of the recipient.
+// - subject: The subject line.
+// - html: The HTML body.
+// - attachments: The files to attach; none sends a plain HTML email.
+//
+// Returns:
+// - error: An error if the server could not be reached or refused the email.
+func (s *SMTP) SendMailWithAttachments(ctx context.Context, sender, to, subject, html string, attachments []Attachment) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+	}
+
+	address := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(nil); err != nil {
+			return err
+		}
+	}
+	if s.Username != "" {
+		// PlainAuth refuses to send credentials without TLS, except to localhost
+		if err := client.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(s.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	body, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := body.Write(s.message(sender, to, subject, html, attachments)); err != nil {
+		return err
+	}
+	if err := body.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// message formats an HTML email with its headers, as a multipart/mixed message when files are attached.
+func (s *SMTP) message(sender, to, subject, html string, attachments []Attachment) []byte {
+	from := (&mail.Address{Name: sender, Address: s.From}).String()
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	var boundary string
+	if len(attachments) > 0 {
+		random := make([]byte, 12)
+		rand.Read(random)
+		boundary = "mixed-" + hex.EncodeToString(random)
+		fmt.Fprintf(&b, "Content-Type: multipart/mixed; boundary=\"%s\"\r\n\r\n", boundary)
+		fmt.Fprintf(&b, "--%s\r\n", boundary)
+	}
+	b.WriteString("Content-Type: text/html; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(html, "\r\n", "\n"), "\n", "\r\n"))
+	if len(attachments) == 0 {
+		return []byte(b.String())
+	}
+
+	for _, attachment := range attachments {
+		filename := mime.QEncoding.Encode("utf-8", attachment.Filename)
+		fmt.Fprintf(&b, "\r\n--%s\r\n", boundary)
+		fmt.Fprintf(&b, "Content-Type: %s; name=\"%s\"\r\n", attachment.ContentType, filename)
+		fmt.Fprintf(&b, "Content-Disposition: attachment; filename=\"%s\"\r\n", filename)
+		b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+		encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+		for len(encoded) > 76 {
+			b.WriteString(encoded[:76] + "\r\n")
+			encoded = encoded[76:]
+		}
+		b.WriteString(encoded + "\r\n")
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return []byte(b.String())
+}
@@ -0,0 +1,54 @@
+// email_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package notifications
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMessage tests that emails with attachments are multipart messages carrying the HTML and the files
+func TestMessage(t *testing.T) {
+	server := &SMTP{From: "tasks@example.com"}
+
+	plain, err := mail.ReadMessage(bytes.NewReader(server.message("Acme", "bob@example.com", "Hello", "<p>Hi</p>", nil)))
+	require.NoError(t, err)
+	assert.Equal(t, "text/html; charset=utf-8", plain.Header.Get("Content-Type"))
+
+	data := bytes.Repeat([]byte{0, 1, 2, 255}, 100)
+	raw := server.message("Acme", "bob@example.com", "Report", "<p>Attached</p>", []Attachment{
+		{Filename: "report.pdf", ContentType: "application/pdf", Data: data},
+	})
+	message, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+
+	parts := multipart.NewReader(message.Body, params["boundary"])
+	html, err := parts.NextPart()
+	require.NoError(t, err)
+	body, _ := io.ReadAll(html)
+	assert.Equal(t, "<p>Attached</p>", string(body))
+
+	file, err := parts.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "report.pdf", file.FileName())
+	assert.Equal(t, "base64", file.Header.Get("Content-Transfer-Encoding"))
+	encoded, _ := io.ReadAll(file)
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+	require.NoError(t, err)
+	assert.Equal(t, data, decoded)
+	_, err = parts.NextPart()
+	assert.Equal(t, io.EOF, err)
+}
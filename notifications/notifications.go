@@ -0,0 +1,67 @@
+// notifications.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package notifications
+
+import (
+	"context"
+	"log"
+)
+
+// Events that trigger notifications
+const (
+	EventTaskAssigned   = "task.assigned"
+	EventTaskOverdue    = "task.overdue"
+	EventTaskReassigned = "task.reassigned"
+	EventTaskMentioned  = "task.mentioned"
+	EventNewSignIn      = "account.signin" // Sign-in from a device or network new to the account
+)
+
+// Events lists all notification events, which users can subscribe to.
+var Events = []string{EventTaskAssigned, EventTaskOverdue, EventTaskReassigned, EventTaskMentioned, EventNewSignIn}
+
+// Notification is a message about a task, or the account, addressed to a user.
+type Notification struct {
+	Event     string `json:"event"`             // Event that triggered the notification, e.g. task.assigned
+	Recipient string `json:"recipient"`         // Username of the user to notify
+	TaskID    string `json:"task_id"`           // ID of the task the notification is about; empty for account events
+	Subject   string `json:"subject"`           // Short summary line
+	Message   string `json:"message"`           // Full message text
+	Channel   string `json:"channel,omitempty"` // Channel to deliver through, set by Router
+}
+
+// Notifier delivers notifications, e.g. by email or webhook.
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// NotifierFunc adapts an ordinary function to the Notifier interface.
+type NotifierFunc func(ctx context.Context, notification Notification) error
+
+// Notify calls f(ctx, notification).
+func (f NotifierFunc) Notify(ctx context.Context, notification Notification) error {
+	return f(ctx, notification)
+}
+
+// Nop is a Notifier that discards all notifications.
+type Nop struct{}
+
+// Notify discards the notification.
+func (Nop) Notify(ctx context.Context, notification Notification) error {
+	return nil
+}
+
+// Default is the notifier used by Send. It discards notifications until one is configured.
+var Default Notifier = Nop{}
+
+// Send delivers a notification through the Default notifier. Delivery failures are logged rather
+// than returned, so a failing channel never fails the request that triggered the notification.
+//
+// Parameters:
+// - ctx: Context for the delivery.
+// - notification: The notification to deliver.
+func Send(ctx context.Context, notification Notification) {
+	if err := Default.Notify(ctx, notification); err != nil {
+		log.Printf("Could not deliver %s notification to %s: %v", notification.Event, notification.Recipient, err)
+	}
+}
@@ -0,0 +1,31 @@
+// notifications_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package notifications
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSend tests that Send delivers through the Default notifier and swallows delivery errors
+func TestSend(t *testing.T) {
+	defer func() { Default = Nop{} }()
+
+	var received []Notification
+	Default = NotifierFunc(func(ctx context.Context, notification Notification) error {
+		received = append(received, notification)
+		return errors.New("channel down")
+	})
+
+	// Assert that the notification reaches the notifier even though delivery fails
+	Send(context.Background(), Notification{Event: EventTaskAssigned, Recipient: "alice"})
+	assert.Len(t, received, 1)
+	assert.Equal(t, "alice", received[0].Recipient)
+
+	// Assert that the Nop notifier discards notifications
+	assert.NoError(t, Nop{}.Notify(context.Background(), Notification{}))
+}
@@ -0,0 +1,118 @@
+// preferences.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+)
+
+// userChannels are the chat channels a user chooses between per event.
+var userChannels = []string{ChannelSlack, ChannelTeams, ChannelTelegram}
+
+// validatePreferences checks the channels per event and the quiet hours of notification settings.
+func validatePreferences(settings models.NotificationSettings) error {
+	for event, channels := range settings.Channels {
+		if !contains(Events, event) {
+			return fmt.Errorf("unknown event %q, events are %s", event, strings.Join(Events, ", "))
+		}
+		for _, channel := range channels {
+			if !contains(userChannels, channel) {
+				return fmt.Errorf("unknown channel %q, channels are %s", channel, strings.Join(userChannels, ", "))
+			}
+		}
+	}
+	if quiet := settings.QuietHours; quiet != nil {
+		start, err := minuteOfDay(quiet.Start)
+		if err != nil {
+			return errors.New("quiet_hours.start must be a time such as 22:00")
+		}
+		end, err := minuteOfDay(quiet.End)
+		if err != nil {
+			return errors.New("quiet_hours.end must be a time such as 07:00")
+		}
+		if start == end {
+			return errors.New("quiet_hours must not start and end at the same time")
+		}
+	}
+	return nil
+}
+
+// Wants reports whether a user wants a notification delivered through one of their chat channels now: they
+// subscribed to its event, did not restrict the event to other channels, are not in digest-only mode, and
+// it is not within their quiet hours. The service-wide webhook is not subject to user preferences.
+//
+// Parameters:
+// - user: The recipient.
+// - notification: The notification.
+// - channel: The chat channel, e.g. ChannelSlack.
+// - now: The current time.
+//
+// Returns:
+// - bool: True if the notification may be sent through the channel.
+func Wants(user *models.User, notification Notification, channel string, now time.Time) bool {
+	settings := user.Notifications
+	if settings == nil {
+		return true
+	}
+	if settings.DigestOnly || !subscribed(settings, notification.Event) {
+		return false
+	}
+	if channels, ok := settings.Channels[notification.Event]; ok && !contains(channels, channel) {
+		return false
+	}
+	return !InQuietHours(settings.QuietHours, now, userLocation(user))
+}
+
+// InQuietHours reports whether a time is within quiet hours.
+//
+// Parameters:
+// - quiet: The quiet hours, or nil for none.
+// - now: The time to check.
+// - location: The timezone of the quiet hours.
+//
+// Returns:
+// - bool: True if now is at or after the start and before the end of the quiet hours.
+func InQuietHours(quiet *models.QuietHours, now time.Time, location *time.Location) bool {
+	if quiet == nil {
+		return false
+	}
+	start, err := minuteOfDay(quiet.Start)
+	if err != nil {
+		return false
+	}
+	end, err := minuteOfDay(quiet.End)
+	if err != nil {
+		return false
+	}
+
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end // Spans midnight
+}
+
+// minuteOfDay parses an "HH:MM" time into minutes since midnight.
+func minuteOfDay(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// userLocation returns the timezone of a user, UTC when none or an unknown one is set.
+func userLocation(user *models.User) *time.Location {
+	location, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
@@ -0,0 +1,52 @@
+// webhook.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Webhook delivers notifications as JSON POST requests to a URL. Any response other than 2xx is a
+// failed delivery, which a Dispatcher retries.
+type Webhook struct {
+	URL    string       // Endpoint receiving the notifications
+	Client *http.Client // HTTP client to use; http.DefaultClient when nil
+}
+
+// Notify posts the notification to the webhook URL.
+func (w Webhook) Notify(ctx context.Context, notification Notification) error {
+	return postJSON(ctx, w.Client, w.URL, notification)
+}
+
+// postJSON posts v as JSON to url and treats any response other than 2xx as an error.
+func postJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s answered %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// outbox.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package outbox makes the delivery of events and notifications survive crashes: they are stored in the
+// outbox collection in the same transaction as the write that caused them, and a relay delivers them
+// afterwards, retrying until they are delivered or dead-lettered.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/helper"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/notifications"
+	"github.com/bkojha74/task-management/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// enabled switches Publish and Notify from direct delivery to the outbox.
+var enabled atomic.Bool
+
+// Config is the outbox setting and the retry policy of the relay.
+type Config struct {
+	Enabled     bool          // Store events and notifications in the outbox; needs MongoDB transactions
+	Interval    time.Duration // Time between two relay runs
+	BatchSize   int           // Messages delivered per query
+	MaxAttempts int           // Delivery attempts before a message is dead-lettered
+	Backoff     time.Duration // Delay before the first retry, doubled for every further retry
+	Timeout     time.Duration // Time limit of a single delivery attempt
+}
+
+// LoadConfig reads the OUTBOX, OUTBOX_RELAY_INTERVAL_MS, OUTBOX_MAX_ATTEMPTS, OUTBOX_BACKOFF_MS and
+// NOTIFY_TIMEOUT (seconds) environment variables.
+//
+// Returns:
+// - Config: The configured settings.
+func LoadConfig() Config {
+	return Config{
+		Enabled:     helper.GetEnv("OUTBOX") == "true",
+		Interval:    time.Duration(helper.GetEnvInt("OUTBOX_RELAY_INTERVAL_MS", 1000)) * time.Millisecond,
+		BatchSize:   100,
+		MaxAttempts: helper.GetEnvInt("OUTBOX_MAX_ATTEMPTS", 10),
+		Backoff:     time.Duration(helper.GetEnvInt("OUTBOX_BACKOFF_MS", 1000)) * time.Millisecond,
+		Timeout:     time.Duration(helper.GetEnvInt("NOTIFY_TIMEOUT", 10)) * time.Second,
+	}
+}
+
+// Enable switches the outbox on or off for the whole process.
+func Enable(on bool) {
+	enabled.Store(on)
+}
+
+// Enabled reports whether events and notifications go through the outbox.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Transaction runs fn in a database transaction when the outbox is enabled, so that the events and
+// notifications it adds are stored if and only if its writes are. Otherwise fn runs directly.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - fn: The writes, using the context it is passed.
+//
+// Returns:
+// - error: The error of fn or of the transaction.
+func Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !Enabled() {
+		return fn(ctx)
+	}
+	return repository.Transactions.InTransaction(ctx, fn)
+}
+
+// Publish adds a new event to the outbox when it is enabled, or publishes it on the default bus right away.
+//
+// Parameters:
+// - ctx: Context of the write that caused the event, inside Transaction.
+// - eventType: The event type, e.g. events.TaskCreated.
+// - subject: The ID of the entity the event is about.
+// - data: A snapshot of the entity.
+//
+// Returns:
+// - error: An error if the event could not be stored.
+func Publish(ctx context.Context, eventType, subject string, data interface{}) error {
+	event := events.New(eventType, subject, data)
+	if !Enabled() {
+		events.Default.Publish(ctx, event)
+		return nil
+	}
+	return add(ctx, models.OutboxEvent, event)
+}
+
+// Notify adds a notification to the outbox when it is enabled, or sends it right away.
+//
+// Parameters:
+// - ctx: Context of the write that caused the notification, inside Transaction.
+// - notification: The notification.
+//
+// Returns:
+// - error: An error if the notification could not be stored.
+func Notify(ctx context.Context, notification notifications.Notification) error {
+	if !Enabled() {
+		notifications.Send(ctx, notification)
+		return nil
+	}
+	return add(ctx, models.OutboxNotification, notification)
+}
+
+// add stores a message due right away.
+func add(ctx context.Context, kind string, payload interface{}) error {
+	message, err := newMessage(kind, payload)
+	if err != nil {
+		return err
+	}
+	return repository.Outbox.Add(ctx, message)
+}
+
+// newMessage creates a message due right away, with the payload as JSON.
+func newMessage(kind string, payload interface{}) (models.OutboxMessage, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return models.OutboxMessage{}, err
+	}
+	now := primitive.NewDateTimeFromTime(time.Now())
+	return models.OutboxMessage{
+		ID:            primitive.NewObjectID(),
+		Kind:          kind,
+		Payload:       string(encoded),
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}, nil
+}
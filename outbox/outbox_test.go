@@ -0,0 +1,141 @@
+// outbox_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/notifications"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a notifier remembering the notifications it delivered, failing while err is set.
+type recorder struct {
+	err  error
+	sent []notifications.Notification
+}
+
+func (r *recorder) Notify(ctx context.Context, notification notifications.Notification) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.sent = append(r.sent, notification)
+	return nil
+}
+
+// TestOutbox tests that events and notifications are stored when the outbox is enabled and delivered
+// by the relay, routed to each channel
+func TestOutbox(t *testing.T) {
+	repository.UseMemory()
+	Enable(true)
+	defer Enable(false)
+
+	recipient := models.User{Username: "bob", Password: "hash", Notifications: &models.NotificationSettings{SlackWebhookURL: "https://hooks.slack.test/x"}}
+	require.NoError(t, repository.Users.Create(context.Background(), &recipient))
+
+	bus := events.NewBus()
+	var published []events.Event
+	bus.Subscribe("", func(ctx context.Context, event events.Event) { published = append(published, event) })
+	sender := &recorder{}
+	relay := NewRelay(Config{BatchSize: 100, MaxAttempts: 3, Backoff: time.Second}, bus, nil, true, sender)
+
+	err := Transaction(context.Background(), func(ctx context.Context) error {
+		if err := Publish(ctx, events.TaskCreated, "task-1", map[string]string{"title": "Write docs"}); err != nil {
+			return err
+		}
+		return Notify(ctx, notifications.Notification{Event: notifications.EventTaskAssigned, Recipient: "bob", TaskID: "task-1"})
+	})
+	require.NoError(t, err)
+
+	// Assert that nothing is delivered before the relay runs
+	assert.Empty(t, published)
+	assert.Empty(t, sender.sent)
+	due, err := repository.Outbox.Due(context.Background(), time.Now(), 0)
+	require.NoError(t, err)
+	assert.Len(t, due, 2)
+
+	// Assert that the relay publishes the event and splits the notification per channel
+	_, err = relay.Deliver(context.Background(), time.Now())
+	require.NoError(t, err)
+	require.Len(t, published, 1)
+	assert.Equal(t, events.TaskCreated, published[0].Type)
+	assert.Equal(t, "task-1", published[0].Subject)
+	assert.JSONEq(t, `{"title":"Write docs"}`, string(published[0].Data.(json.RawMessage)))
+
+	_, err = relay.Deliver(context.Background(), time.Now())
+	require.NoError(t, err)
+	channels := []string{}
+	for _, notification := range sender.sent {
+		channels = append(channels, notification.Channel)
+	}
+	assert.ElementsMatch(t, []string{notifications.ChannelWebhook, notifications.ChannelSlack}, channels)
+
+	// Assert that delivered messages leave the outbox
+	due, err = repository.Outbox.Due(context.Background(), time.Now(), 0)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+}
+
+// TestRelayRetries tests that failed deliveries are retried with backoff and dead-lettered after the
+// last attempt
+func TestRelayRetries(t *testing.T) {
+	repository.UseMemory()
+	Enable(true)
+	defer Enable(false)
+
+	sender := &recorder{err: errors.New("connection refused")}
+	relay := NewRelay(Config{BatchSize: 100, MaxAttempts: 2, Backoff: time.Second}, events.NewBus(), nil, false, sender)
+	var deadLettered []string
+	relay.DeadLetter = func(ctx context.Context, kind string, payload map[string]interface{}, cause error) error {
+		deadLettered = append(deadLettered, kind+": "+cause.Error())
+		return nil
+	}
+	require.NoError(t, Notify(context.Background(), notifications.Notification{Event: notifications.EventTaskOverdue, Recipient: "bob", Channel: notifications.ChannelWebhook}))
+
+	// Assert that a failed message waits for its backoff
+	now := time.Now()
+	_, err := relay.Deliver(context.Background(), now)
+	require.NoError(t, err)
+	due, err := repository.Outbox.Due(context.Background(), now.Add(500*time.Millisecond), 0)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+	due, err = repository.Outbox.Due(context.Background(), now.Add(time.Second), 0)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, 1, due[0].Attempts)
+	assert.Equal(t, "connection refused", due[0].LastError)
+
+	// Assert that the last failed attempt dead-letters the message
+	_, err = relay.Deliver(context.Background(), now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"notification: connection refused"}, deadLettered)
+	due, err = repository.Outbox.Due(context.Background(), now.Add(time.Hour), 0)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+}
+
+// TestDisabled tests that events and notifications are delivered right away when the outbox is disabled
+func TestDisabled(t *testing.T) {
+	repository.UseMemory()
+	sender := &recorder{}
+	notifications.Default = sender
+	defer func() { notifications.Default = notifications.Nop{} }()
+
+	require.NoError(t, Notify(context.Background(), notifications.Notification{Event: notifications.EventTaskAssigned, Recipient: "bob"}))
+
+	// Assert that the notification was sent and not stored
+	assert.Len(t, sender.sent, 1)
+	due, err := repository.Outbox.Due(context.Background(), time.Now(), 0)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+}
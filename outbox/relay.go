@@ -0,0 +1,216 @@
+// relay.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bkojha74/task-management/deadletter"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/notifications"
+	"github.com/bkojha74/task-management/repository"
+)
+
+// maxBackoff caps the delay between two delivery attempts.
+const maxBackoff = 10 * time.Minute
+
+// Relay delivers the messages of the outbox. Events are published to the external publisher, if any,
+// and then on the in-process bus. A notification is first split into one message per channel it should
+// reach, so that each channel is retried and dead-lettered on its own, and then delivered
+// synchronously. A message is deleted only once delivered, so delivery is at least once: a crash between
+// delivering and deleting a message delivers it again.
+type Relay struct {
+	Config
+	Bus        *events.Bus            // In-process subscribers of the events
+	Publisher  events.Publisher       // External broker of the events; nil keeps them in-process
+	Webhook    bool                   // Also deliver every notification to the service-wide webhook
+	Sender     notifications.Notifier // Delivers a notification through its channel, e.g. notifications.Channels
+	DeadLetter func(ctx context.Context, kind string, payload map[string]interface{}, cause error) error
+}
+
+// NewRelay creates a relay dead-lettering to the dead-letter collection.
+//
+// Parameters:
+// - config: The retry policy.
+// - bus: The in-process subscribers of the events.
+// - publisher: The external broker of the events, or nil.
+// - webhook: Whether notifications are also delivered to the service-wide webhook.
+// - sender: Delivers a notification through its channel.
+//
+// Returns:
+// - *Relay: The relay; run it with jobs.OutboxRelayJob.
+func NewRelay(config Config, bus *events.Bus, publisher events.Publisher, webhook bool, sender notifications.Notifier) *Relay {
+	return &Relay{
+		Config:     config,
+		Bus:        bus,
+		Publisher:  publisher,
+		Webhook:    webhook,
+		Sender:     sender,
+		DeadLetter: deadletter.Record,
+	}
+}
+
+// Deliver delivers the messages due at now, one batch at a time until none is left.
+//
+// Parameters:
+// - ctx: Context for the database operations and deliveries.
+// - now: The time to compare the next attempts with.
+//
+// Returns:
+// - int: The number of messages delivered.
+// - error: An error if the outbox could not be read or written.
+func (r *Relay) Deliver(ctx context.Context, now time.Time) (int, error) {
+	delivered := 0
+	for {
+		messages, err := repository.Outbox.Due(ctx, now, r.BatchSize)
+		if err != nil {
+			return delivered, err
+		}
+
+		for _, message := range messages {
+			if err := r.deliver(ctx, message); err != nil {
+				if err := r.retry(ctx, message, err, now); err != nil {
+					return delivered, err
+				}
+				continue
+			}
+			if err := repository.Outbox.Delete(ctx, message.ID); err != nil {
+				return delivered, err
+			}
+			delivered++
+		}
+
+		if r.BatchSize <= 0 || len(messages) < r.BatchSize {
+			return delivered, nil
+		}
+	}
+}
+
+// ReplayEvent publishes a dead-lettered event once. It is registered as the deadletter.Replayer of event
+// dead letters.
+//
+// Parameters:
+// - ctx: Context for the delivery.
+// - payload: The dead letter payload, the event.
+//
+// Returns:
+// - error: The publishing error, if any.
+func (r *Relay) ReplayEvent(ctx context.Context, payload map[string]interface{}) error {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return r.publish(ctx, string(encoded))
+}
+
+// deliver makes one delivery attempt of a message.
+func (r *Relay) deliver(ctx context.Context, message models.OutboxMessage) error {
+	switch message.Kind {
+	case models.OutboxEvent:
+		return r.publish(ctx, message.Payload)
+	case models.OutboxNotification:
+		var notification notifications.Notification
+		if err := json.Unmarshal([]byte(message.Payload), &notification); err != nil {
+			return err
+		}
+		if notification.Channel == "" {
+			return r.route(ctx, message, notification)
+		}
+		return r.attempt(ctx, func(ctx context.Context) error { return r.Sender.Notify(ctx, notification) })
+	default:
+		return fmt.Errorf("unknown outbox message kind %q", message.Kind)
+	}
+}
+
+// publish publishes an event to the external publisher and then on the bus.
+func (r *Relay) publish(ctx context.Context, payload string) error {
+	var event events.Event
+	var data json.RawMessage
+	event.Data = &data
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		return err
+	}
+	event.Data = data
+
+	if r.Publisher != nil {
+		if err := r.attempt(ctx, func(ctx context.Context) error { return r.Publisher.Publish(ctx, event) }); err != nil {
+			return err
+		}
+	}
+	if r.Bus != nil {
+		r.Bus.Publish(ctx, event)
+	}
+	return nil
+}
+
+// route replaces a notification by one message per channel it should reach. A notification nobody
+// should receive is simply delivered.
+func (r *Relay) route(ctx context.Context, message models.OutboxMessage, notification notifications.Notification) error {
+	var routed []models.OutboxMessage
+	collect := notifications.NotifierFunc(func(ctx context.Context, notification notifications.Notification) error {
+		channelMessage, err := newMessage(models.OutboxNotification, notification)
+		routed = append(routed, channelMessage)
+		return err
+	})
+	if err := (notifications.Router{Queue: collect, Webhook: r.Webhook}).Notify(ctx, notification); err != nil {
+		return err
+	}
+	if len(routed) == 0 {
+		return nil
+	}
+
+	// The routed messages replace the original one, which the caller deletes once this returns
+	return repository.Transactions.InTransaction(ctx, func(ctx context.Context) error {
+		if err := repository.Outbox.Add(ctx, routed...); err != nil {
+			return err
+		}
+		return repository.Outbox.Delete(ctx, message.ID)
+	})
+}
+
+// attempt makes a single delivery attempt within the configured timeout.
+func (r *Relay) attempt(ctx context.Context, deliver func(ctx context.Context) error) error {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	return deliver(ctx)
+}
+
+// retry schedules the next attempt of a failed message with exponential backoff, or dead-letters it
+// once all attempts failed.
+func (r *Relay) retry(ctx context.Context, message models.OutboxMessage, cause error, now time.Time) error {
+	attempts := message.Attempts + 1
+	log.Printf("Delivery attempt %d of outbox %s %s failed: %v", attempts, message.Kind, message.ID.Hex(), cause)
+
+	if attempts >= r.MaxAttempts {
+		var payload map[string]interface{}
+		if err := json.Unmarshal([]byte(message.Payload), &payload); err != nil {
+			payload = map[string]interface{}{"payload": message.Payload}
+		}
+		kind := deadletter.KindEvent
+		if message.Kind == models.OutboxNotification {
+			kind = deadletter.KindNotification
+		}
+		if err := r.DeadLetter(ctx, kind, payload, cause); err != nil {
+			return fmt.Errorf("dead-lettering outbox %s %s: %w", message.Kind, message.ID.Hex(), err)
+		}
+		return repository.Outbox.Delete(ctx, message.ID)
+	}
+
+	backoff := r.Backoff
+	for i := 1; i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return repository.Outbox.Retry(ctx, message.ID, attempts, now.Add(backoff), cause.Error())
+}
@@ -0,0 +1,153 @@
+// pagination.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Limits applied to the "limit" query parameter
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// ErrInvalidCursor is returned when a cursor token cannot be decoded or doesn't match the requested sort.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrInvalidSort is returned when the requested sort field is not allowed.
+var ErrInvalidSort = errors.New("invalid sort field")
+
+// Sort describes the ordering of a paginated list. Ties are always broken by _id in the same direction,
+// so the ordering is total and stable while documents are inserted or deleted between pages.
+type Sort struct {
+	Field      string
+	Descending bool
+}
+
+// String returns the sort in its query parameter form, e.g. "-end_time".
+func (s Sort) String() string {
+	if s.Descending {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// ParseSort parses a sort query parameter such as "end_time" or "-end_time" against the allowed fields.
+// An empty value sorts by _id ascending.
+func ParseSort(value string, allowed ...string) (Sort, error) {
+	if value == "" {
+		return Sort{Field: "_id"}, nil
+	}
+
+	sort := Sort{Field: strings.TrimPrefix(value, "-"), Descending: strings.HasPrefix(value, "-")}
+	if sort.Field == "_id" {
+		return sort, nil
+	}
+	for _, field := range allowed {
+		if field == sort.Field {
+			return sort, nil
+		}
+	}
+	return Sort{}, ErrInvalidSort
+}
+
+// Cursor marks the last item of a page: the value of its sort key and its _id.
+type Cursor struct {
+	Sort  string             `bson:"s"`
+	Value interface{}        `bson:"v"` // Kept when empty, e.g. "" or 0, which is a sort value like any other
+	ID    primitive.ObjectID `bson:"id"`
+}
+
+// Encode serializes the cursor into an opaque URL-safe token.
+func (c Cursor) Encode() string {
+	data, err := bson.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode parses a cursor token and checks that it was issued for the given sort.
+func Decode(token string, sort Sort) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := bson.Unmarshal(data, &cursor); err != nil || cursor.Sort != sort.String() || cursor.ID.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// CursorFor builds the cursor pointing after doc, reading the sort key and _id from its BSON form.
+func CursorFor(doc interface{}, sort Sort) (*Cursor, error) {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var id primitive.ObjectID
+	if err := bson.Raw(raw).Lookup("_id").Unmarshal(&id); err != nil {
+		return nil, err
+	}
+
+	cursor := &Cursor{Sort: sort.String(), ID: id}
+	if sort.Field != "_id" {
+		if err := bson.Raw(raw).Lookup(sort.Field).Unmarshal(&cursor.Value); err != nil {
+			return nil, err
+		}
+	}
+	return cursor, nil
+}
+
+// Filter returns the filter selecting the documents after the cursor in the given sort order.
+// With a nil cursor it returns an empty filter (the first page).
+func Filter(sort Sort, cursor *Cursor) bson.M {
+	if cursor == nil {
+		return bson.M{}
+	}
+
+	op := "$gt"
+	if sort.Descending {
+		op = "$lt"
+	}
+
+	if sort.Field == "_id" {
+		return bson.M{"_id": bson.M{op: cursor.ID}}
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{sort.Field: bson.M{op: cursor.Value}},
+		bson.M{sort.Field: cursor.Value, "_id": bson.M{op: cursor.ID}},
+	}}
+}
+
+// FindOptions returns the find options sorting by the sort key with the _id tiebreaker.
+// A positive limit fetches one extra document so callers can tell whether another page exists.
+func FindOptions(sort Sort, limit int) *options.FindOptions {
+	direction := 1
+	if sort.Descending {
+		direction = -1
+	}
+
+	order := bson.D{}
+	if sort.Field != "_id" {
+		order = append(order, bson.E{Key: sort.Field, Value: direction})
+	}
+	order = append(order, bson.E{Key: "_id", Value: direction})
+
+	opts := options.Find().SetSort(order)
+	if limit > 0 {
+		opts.SetLimit(int64(limit + 1))
+	}
+	return opts
+}
@@ -0,0 +1,74 @@
+// pagination_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestParseSort tests parsing of ascending, descending and disallowed sort fields
+func TestParseSort(t *testing.T) {
+	sort, err := ParseSort("", "title")
+	assert.NoError(t, err)
+	assert.Equal(t, Sort{Field: "_id"}, sort) // Assert that the default sort is _id ascending
+
+	sort, err = ParseSort("-title", "title")
+	assert.NoError(t, err)
+	assert.Equal(t, Sort{Field: "title", Descending: true}, sort) // Assert that "-" means descending
+
+	_, err = ParseSort("password", "title")
+	assert.ErrorIs(t, err, ErrInvalidSort) // Assert that unknown fields are rejected
+}
+
+// TestCursorRoundTrip tests that a cursor survives encoding and keeps the sort key type
+func TestCursorRoundTrip(t *testing.T) {
+	sort := Sort{Field: "end_time", Descending: true}
+	doc := bson.M{"_id": primitive.NewObjectID(), "end_time": primitive.NewDateTimeFromTime(time.Now())}
+
+	cursor, err := CursorFor(doc, sort)
+	assert.NoError(t, err)
+
+	decoded, err := Decode(cursor.Encode(), sort)
+	assert.NoError(t, err)
+	assert.Equal(t, doc["_id"], decoded.ID)         // Assert that the _id is preserved
+	assert.Equal(t, doc["end_time"], decoded.Value) // Assert that the sort value keeps its BSON type
+
+	_, err = Decode(cursor.Encode(), Sort{Field: "end_time"})
+	assert.ErrorIs(t, err, ErrInvalidCursor) // Assert that a cursor can't be reused with another sort
+}
+
+// TestCursorEmptyValue tests that empty sort values survive encoding, so the next page starts after them
+func TestCursorEmptyValue(t *testing.T) {
+	for _, value := range []interface{}{"", int32(0), int64(0), false} {
+		sort := Sort{Field: "title"}
+		cursor := Cursor{Sort: sort.String(), Value: value, ID: primitive.NewObjectID()}
+
+		decoded, err := Decode(cursor.Encode(), sort)
+		assert.NoError(t, err)
+		assert.Equal(t, value, decoded.Value) // Assert that the empty value is not dropped
+		assert.Equal(t, bson.M{"$or": bson.A{
+			bson.M{"title": bson.M{"$gt": value}},
+			bson.M{"title": value, "_id": bson.M{"$gt": cursor.ID}},
+		}}, Filter(sort, decoded)) // Assert that the filter compares with it rather than null
+	}
+}
+
+// TestFilter tests the keyset filter built from a cursor
+func TestFilter(t *testing.T) {
+	id := primitive.NewObjectID()
+
+	assert.Equal(t, bson.M{}, Filter(Sort{Field: "_id"}, nil)) // Assert that the first page is unfiltered
+	assert.Equal(t, bson.M{"_id": bson.M{"$gt": id}}, Filter(Sort{Field: "_id"}, &Cursor{ID: id}))
+
+	filter := Filter(Sort{Field: "title", Descending: true}, &Cursor{Value: "b", ID: id})
+	assert.Equal(t, bson.M{"$or": bson.A{
+		bson.M{"title": bson.M{"$lt": "b"}},
+		bson.M{"title": "b", "_id": bson.M{"$lt": id}},
+	}}, filter) // Assert that ties on the sort key are broken by _id
+}
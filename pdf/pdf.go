@@ -0,0 +1,322 @@
+// pdf.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package pdf writes simple PDF documents with github.com/signintech/gopdf: headings, wrapped paragraphs and
+// tables on A4 pages. Text is drawn in embedded TrueType subsets, the Go fonts for Latin, Greek and Cyrillic
+// and Noto Sans Devanagari for Hindi, chosen character by character; characters neither font has are
+// replaced by '?'. Glyphs are not shaped, so a Devanagari conjunct shows as its letters joined by a virama.
+package pdf
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/signintech/gopdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+)
+
+// Page geometry in points, A4 portrait, measured from the top left corner
+const (
+	pageWidth    = 595.0
+	pageHeight   = 842.0
+	margin       = 50.0
+	contentWidth = pageWidth - 2*margin
+	footerY      = pageHeight - 30.0
+)
+
+// Text sizes in points
+const (
+	titleSize   = 18.0
+	headingSize = 13.0
+	textSize    = 10.0
+	tableSize   = 9.0
+	lineFactor  = 1.4 // Line height as a multiple of the text size
+)
+
+// Font families, also the PDF font names. Noto Sans Devanagari has no bold face here, so bold Hindi is drawn
+// regular.
+const (
+	latinFont      = "Go"
+	devanagariFont = "NotoSansDevanagari"
+)
+
+// devanagariTTF is Noto Sans Devanagari, under the SIL Open Font License in fonts/OFL.txt.
+//
+//go:embed fonts/NotoSansDevanagari-Regular.ttf
+var devanagariTTF []byte
+
+// Parsed fonts, to look up which of them has a character
+var (
+	latinGlyphs      = mustParse(goregular.TTF)
+	devanagariGlyphs = mustParse(devanagariTTF)
+)
+
+// Column is a column of a table.
+type Column struct {
+	Title string
+	Width float64 // Share of the content width; the widths of a table's columns should add up to 1
+}
+
+// Document is a PDF document being written, page by page from the top.
+type Document struct {
+	pdf   *gopdf.GoPdf
+	pages int
+	y     float64 // Baseline of the next line on the current page
+	err   error   // First error of the library, returned by Bytes
+}
+
+// New creates a document with a title, shown as the first line and in the document properties.
+//
+// Parameters:
+// - title: The title.
+//
+// Returns:
+// - *Document: The document, with one page.
+func New(title string) *Document {
+	d := &Document{pdf: &gopdf.GoPdf{}}
+	d.pdf.Start(gopdf.Config{PageSize: gopdf.Rect{W: pageWidth, H: pageHeight}})
+	d.pdf.SetInfo(gopdf.PdfInfo{Title: title, Producer: "task-management"})
+	d.check(d.pdf.AddTTFFontDataWithOption(latinFont, goregular.TTF, gopdf.TtfOption{Style: gopdf.Regular}))
+	d.check(d.pdf.AddTTFFontDataWithOption(latinFont, gobold.TTF, gopdf.TtfOption{Style: gopdf.Bold}))
+	d.check(d.pdf.AddTTFFontData(devanagariFont, devanagariTTF))
+
+	d.newPage()
+	d.write(title, titleSize, true, margin)
+	d.y += titleSize * 0.6
+	return d
+}
+
+// Heading adds a bold heading, starting a new page when little space is left.
+//
+// Parameters:
+// - text: The heading.
+func (d *Document) Heading(text string) {
+	if d.y > pageHeight-margin-4*headingSize*lineFactor {
+		d.newPage()
+	} else {
+		d.y += headingSize * 0.6
+	}
+	d.write(text, headingSize, true, margin)
+}
+
+// Text adds a paragraph, wrapped to the page width.
+//
+// Parameters:
+// - text: The paragraph.
+func (d *Document) Text(text string) {
+	for _, line := range d.wrap(text, textSize, contentWidth) {
+		d.write(line, textSize, false, margin)
+	}
+}
+
+// Table adds a table with a shaded header row, repeated on every page the table spans. Cells too wide for
+// their column are shortened with "...".
+//
+// Parameters:
+// - columns: The columns.
+// - rows: The cells of each row, one per column.
+func (d *Document) Table(columns []Column, rows [][]string) {
+	rowHeight := tableSize * lineFactor * 1.2
+	header := func() {
+		if d.y+rowHeight > pageHeight-margin {
+			d.newPage()
+		}
+		d.pdf.SetFillColor(230, 230, 230)
+		d.pdf.RectFromUpperLeftWithStyle(margin, d.y-tableSize, contentWidth, rowHeight, "F")
+		d.pdf.SetFillColor(0, 0, 0)
+		d.cells(columns, headerCells(columns), true)
+	}
+
+	header()
+	for _, row := range rows {
+		if d.y+rowHeight > pageHeight-margin {
+			d.newPage()
+			header()
+		}
+		line := d.y + tableSize*0.3
+		d.cells(columns, row, false)
+		d.pdf.SetStrokeColor(204, 204, 204)
+		d.pdf.SetLineWidth(0.5)
+		d.pdf.Line(margin, line, margin+contentWidth, line)
+		d.pdf.SetStrokeColor(0, 0, 0)
+	}
+	d.y += tableSize * 0.6
+}
+
+// cells writes one table row.
+func (d *Document) cells(columns []Column, cells []string, bold bool) {
+	x := margin
+	for i, column := range columns {
+		width := column.Width * contentWidth
+		if i < len(cells) {
+			d.show(d.truncate(cells[i], tableSize, bold, width-6), tableSize, bold, x+3, d.y)
+		}
+		x += width
+	}
+	d.y += tableSize * lineFactor * 1.2
+}
+
+// headerCells returns the titles of columns.
+func headerCells(columns []Column) []string {
+	titles := make([]string, len(columns))
+	for i, column := range columns {
+		titles[i] = column.Title
+	}
+	return titles
+}
+
+// Bytes finishes the document, numbering its pages, and returns it. Call it once.
+//
+// Returns:
+// - []byte: The PDF file.
+// - error: An error if the library could not write the text or the file.
+func (d *Document) Bytes() ([]byte, error) {
+	size := textSize * 0.8
+	for page := 1; page <= d.pages; page++ {
+		d.check(d.pdf.SetPage(page))
+		footer := fmt.Sprintf("Page %d of %d", page, d.pages)
+		d.show(footer, size, false, pageWidth-margin-d.width(footer, size, false), footerY)
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return d.pdf.GetBytesPdfReturnErr()
+}
+
+// newPage starts a page.
+func (d *Document) newPage() {
+	d.pdf.AddPage()
+	d.pages++
+	d.y = margin
+}
+
+// write adds a line of text at x and moves down, starting a new page when the page is full.
+func (d *Document) write(line string, size float64, bold bool, x float64) {
+	if d.y > pageHeight-margin {
+		d.newPage()
+	}
+	d.show(line, size, bold, x, d.y)
+	d.y += size * lineFactor
+}
+
+// show draws text with its baseline at x, y, switching fonts between its runs.
+func (d *Document) show(line string, size float64, bold bool, x, y float64) {
+	for _, run := range runs(line) {
+		d.setFont(run.font, size, bold)
+		d.pdf.SetXY(x, y)
+		d.check(d.pdf.Text(run.text))
+		width, err := d.pdf.MeasureTextWidth(run.text)
+		d.check(err)
+		x += width
+	}
+}
+
+// setFont selects a font family, in bold when it has a bold face.
+func (d *Document) setFont(font string, size float64, bold bool) {
+	style := ""
+	if bold && font == latinFont {
+		style = "B"
+	}
+	d.check(d.pdf.SetFont(font, style, size))
+}
+
+// check keeps the first error of the library.
+func (d *Document) check(err error) {
+	if err != nil && d.err == nil {
+		d.err = err
+	}
+}
+
+// run is a part of a line drawn in one font.
+type run struct {
+	font string
+	text string
+}
+
+// runs splits a line into runs of the font that has their characters, the Latin font where both have
+// them. Control characters become spaces and characters neither font has become '?'.
+func runs(line string) []run {
+	var buf sfnt.Buffer
+	result := []run{}
+	for _, r := range line {
+		font := latinFont
+		switch {
+		case r < ' ':
+			r = ' '
+		case hasGlyph(latinGlyphs, &buf, r):
+		case hasGlyph(devanagariGlyphs, &buf, r):
+			font = devanagariFont
+		default:
+			r = '?'
+		}
+		if n := len(result); n > 0 && result[n-1].font == font {
+			result[n-1].text += string(r)
+		} else {
+			result = append(result, run{font: font, text: string(r)})
+		}
+	}
+	return result
+}
+
+// hasGlyph reports whether a font has a glyph for r.
+func hasGlyph(font *sfnt.Font, buf *sfnt.Buffer, r rune) bool {
+	index, err := font.GlyphIndex(buf, r)
+	return err == nil && index != 0
+}
+
+// mustParse parses an embedded font.
+func mustParse(ttf []byte) *sfnt.Font {
+	font, err := sfnt.Parse(ttf)
+	if err != nil {
+		panic(fmt.Sprintf("pdf: parsing embedded font: %v", err))
+	}
+	return font
+}
+
+// wrap splits text into lines no wider than maxWidth, breaking between words.
+func (d *Document) wrap(s string, size, maxWidth float64) []string {
+	lines := []string{}
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if line != "" && d.width(candidate, size, false) > maxWidth {
+				lines = append(lines, line)
+				candidate = word
+			}
+			line = candidate
+		}
+		lines = append(lines, d.truncate(line, size, false, maxWidth))
+	}
+	return lines
+}
+
+// truncate shortens text with "..." to fit maxWidth.
+func (d *Document) truncate(s string, size float64, bold bool, maxWidth float64) string {
+	if d.width(s, size, bold) <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && d.width(string(runes)+"...", size, bold) > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "..."
+}
+
+// width returns the width of text in points, as drawn by show.
+func (d *Document) width(s string, size float64, bold bool) float64 {
+	total := 0.0
+	for _, run := range runs(s) {
+		d.setFont(run.font, size, bold)
+		width, err := d.pdf.MeasureTextWidth(run.text)
+		d.check(err)
+		total += width
+	}
+	return total
+}
@@ -0,0 +1,104 @@
+// pdf_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bkojha74/task-management/i18n"
+	reader "github.com/ledongthuc/pdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pageTexts reads back the text of each page of a PDF file.
+func pageTexts(t *testing.T, out []byte) []string {
+	t.Helper()
+	r, err := reader.NewReader(bytes.NewReader(out), int64(len(out)))
+	require.NoError(t, err)
+	texts := make([]string, r.NumPage())
+	for i := range texts {
+		text, err := r.Page(i + 1).GetPlainText(nil)
+		require.NoError(t, err)
+		texts[i] = text
+	}
+	return texts
+}
+
+// TestBytes tests that a document is a readable PDF with its title, text and numbered pages
+func TestBytes(t *testing.T) {
+	doc := New("Report (Q3)")
+	doc.Heading("Tasks")
+	doc.Text("A paragraph")
+	doc.Table([]Column{{Title: "Title", Width: 0.5}, {Title: "Status", Width: 0.5}}, [][]string{{"One", "Done"}})
+	out, err := doc.Bytes()
+	require.NoError(t, err)
+
+	require.True(t, bytes.HasPrefix(out, []byte("%PDF-")))
+	texts := pageTexts(t, out)
+	require.Len(t, texts, 1)
+	for _, text := range []string{"Report (Q3)", "Tasks", "A paragraph", "Title", "Status", "One", "Done", "Page 1 of 1"} {
+		assert.Contains(t, texts[0], text)
+	}
+}
+
+// TestUnicode tests that Hindi and Latin text is embedded in TrueType fonts and reads back as written
+func TestUnicode(t *testing.T) {
+	hindi, language := i18n.Translate("invalid credentials", []string{"hi"})
+	require.Equal(t, "hi", language)
+
+	doc := New("रिपोर्ट: Q3")
+	doc.Text(hindi + " (café)")
+	doc.Table([]Column{{Title: "शीर्षक", Width: 0.5}, {Title: "Status", Width: 0.5}}, [][]string{{"कार्य एक", "Pending"}})
+	out, err := doc.Bytes()
+	require.NoError(t, err)
+
+	assert.Contains(t, string(out), "/FontFile2")
+	assert.Contains(t, string(out), "NotoSansDevanagari")
+	texts := pageTexts(t, out)
+	require.Len(t, texts, 1)
+	for _, text := range []string{"रिपोर्ट: Q3", hindi + " (café)", "शीर्षक", "कार्य एक"} {
+		assert.Contains(t, texts[0], text)
+	}
+
+	// Assert that characters neither font has are replaced
+	assert.Equal(t, []run{{font: latinFont, text: "a ? b"}}, runs("a ✓\tb"))
+	assert.Equal(t, []run{{font: devanagariFont, text: "कार्य"}, {font: latinFont, text: " 1"}}, runs("कार्य 1"))
+}
+
+// TestTablePages tests that a long table continues on further pages
+func TestTablePages(t *testing.T) {
+	rows := make([][]string, 200)
+	for i := range rows {
+		rows[i] = []string{fmt.Sprintf("Task %d", i)}
+	}
+	doc := New("Long")
+	doc.Table([]Column{{Title: "Title", Width: 1}}, rows)
+	out, err := doc.Bytes()
+	require.NoError(t, err)
+
+	texts := pageTexts(t, out)
+	assert.Greater(t, len(texts), 2)
+	for i, text := range texts {
+		assert.Contains(t, text, fmt.Sprintf("Page %d of %d", i+1, len(texts)))
+		assert.Equal(t, 1, strings.Count(text, "Title"), "header repeated on every page")
+	}
+	assert.Contains(t, texts[len(texts)-1], "Task 199")
+}
+
+// TestWrap tests that text is wrapped between words and overlong words are shortened
+func TestWrap(t *testing.T) {
+	doc := New("Wrap")
+	lines := doc.wrap(strings.Repeat("word ", 100), textSize, 200)
+	assert.Greater(t, len(lines), 1)
+	for _, line := range lines {
+		assert.LessOrEqual(t, doc.width(line, textSize, false), 200.0)
+	}
+	assert.Equal(t, []string{"W..."}, doc.wrap(strings.Repeat("W", 10), textSize, doc.width("W...", textSize, false)))
+	assert.Equal(t, "Wo...", doc.truncate("Wonderful", textSize, false, doc.width("Wo...", textSize, false)))
+	assert.Greater(t, doc.width("कार्य", textSize, false), 0.0)
+}
@@ -0,0 +1,133 @@
+// permissions.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package permissions decides who may read and change a task. A task always belongs to its owner; its
+// visibility decides who else may read it, so that sensitive tasks in a shared project stay hidden from
+// everyone but the people working on them. Only the owner changes a task.
+package permissions
+
+import (
+	"context"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Visibility levels of a task, from the narrowest to the widest
+const (
+	VisibilityPrivate = "private" // The owner and the assignee
+	VisibilityTeam    = "team"    // Also the guests of the task's project; the default
+	VisibilityOrg     = "org"     // Also the members of the owner's workspace
+)
+
+// Subject is who a request is made by.
+type Subject struct {
+	UserID         primitive.ObjectID // The signed-in user, or for a guest the owner of the project
+	GuestProjectID primitive.ObjectID // The project a guest was invited to; zero for users
+}
+
+// Guest reports whether the subject is a guest of a project rather than a user.
+func (s Subject) Guest() bool {
+	return !s.GuestProjectID.IsZero()
+}
+
+// Valid reports whether visibility is a known level. The empty visibility is the default.
+//
+// Parameters:
+// - visibility: The visibility to check.
+//
+// Returns:
+// - bool: Whether the visibility can be stored.
+func Valid(visibility string) bool {
+	switch visibility {
+	case "", VisibilityPrivate, VisibilityTeam, VisibilityOrg:
+		return true
+	}
+	return false
+}
+
+// Of returns the visibility of a task, VisibilityTeam for tasks stored without one.
+//
+// Parameters:
+// - task: The task.
+//
+// Returns:
+// - string: The visibility level of the task.
+func Of(task models.Task) string {
+	if task.Visibility == "" {
+		return VisibilityTeam
+	}
+	return task.Visibility
+}
+
+// CanRead reports whether a subject may read a task: the owner and the assignee always, the guests of the
+// task's project unless it is private, and the members of the owner's workspace if it is visible to the
+// whole organization.
+//
+// Parameters:
+// - ctx: Context for the user lookups.
+// - subject: Who asks.
+// - task: The task.
+//
+// Returns:
+// - bool: Whether the subject may read the task.
+// - error: An error if the users could not be read.
+func CanRead(ctx context.Context, subject Subject, task models.Task) (bool, error) {
+	if subject.Guest() {
+		return task.UserID == subject.UserID && task.ProjectID == subject.GuestProjectID && Of(task) != VisibilityPrivate, nil
+	}
+	if task.UserID == subject.UserID {
+		return true, nil
+	}
+
+	user, err := repository.Users.FindByID(ctx, subject.UserID)
+	if err == repository.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if task.AllottedTo != "" && task.AllottedTo == user.Username {
+		return true, nil
+	}
+	if Of(task) != VisibilityOrg || user.WorkspaceID.IsZero() {
+		return false, nil
+	}
+
+	owner, err := repository.Users.FindByID(ctx, task.UserID)
+	if err == repository.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return owner.WorkspaceID == user.WorkspaceID, nil
+}
+
+// CanWrite reports whether a subject may change or delete a task, which only its owner may.
+//
+// Parameters:
+// - subject: Who asks.
+// - task: The task.
+//
+// Returns:
+// - bool: Whether the subject may change the task.
+func CanWrite(subject Subject, task models.Task) bool {
+	return !subject.Guest() && task.UserID == subject.UserID
+}
+
+// RestrictQuery limits a task query to the tasks a subject may list. Users list their own tasks; guests
+// list the tasks of their project that are not private.
+//
+// Parameters:
+// - subject: Who asks.
+// - query: The query to restrict.
+func RestrictQuery(subject Subject, query *repository.TaskQuery) {
+	if !subject.Guest() {
+		return
+	}
+	query.ProjectID = subject.GuestProjectID
+	query.ExcludeVisibility = VisibilityPrivate
+}
@@ -0,0 +1,83 @@
+// permissions_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package permissions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestCanRead tests who may read a task at each visibility level
+func TestCanRead(t *testing.T) {
+	repository.UseMemory()
+	ctx := context.Background()
+
+	workspace := primitive.NewObjectID()
+	owner := models.User{Username: "owner", WorkspaceID: workspace}
+	assignee := models.User{Username: "assignee"}
+	colleague := models.User{Username: "colleague", WorkspaceID: workspace}
+	stranger := models.User{Username: "stranger", WorkspaceID: primitive.NewObjectID()}
+	for _, user := range []*models.User{&owner, &assignee, &colleague, &stranger} {
+		require.NoError(t, repository.Users.Create(ctx, user))
+	}
+
+	project := primitive.NewObjectID()
+	task := models.Task{UserID: owner.ID, ProjectID: project, AllottedTo: "assignee"}
+	guest := Subject{UserID: owner.ID, GuestProjectID: project}
+
+	canRead := func(subject Subject, visibility string) bool {
+		task.Visibility = visibility
+		ok, err := CanRead(ctx, subject, task)
+		require.NoError(t, err)
+		return ok
+	}
+
+	// Assert that the owner and the assignee read the task whatever its visibility
+	for _, visibility := range []string{VisibilityPrivate, VisibilityTeam, VisibilityOrg} {
+		assert.True(t, canRead(Subject{UserID: owner.ID}, visibility), visibility)
+		assert.True(t, canRead(Subject{UserID: assignee.ID}, visibility), visibility)
+	}
+
+	// Assert that guests of the project read all but private tasks, and only in their project
+	assert.False(t, canRead(guest, VisibilityPrivate))
+	assert.True(t, canRead(guest, ""))
+	assert.True(t, canRead(guest, VisibilityTeam))
+	assert.False(t, canRead(Subject{UserID: owner.ID, GuestProjectID: primitive.NewObjectID()}, VisibilityTeam))
+
+	// Assert that members of the owner's workspace read only tasks visible to the organization
+	assert.False(t, canRead(Subject{UserID: colleague.ID}, VisibilityTeam))
+	assert.True(t, canRead(Subject{UserID: colleague.ID}, VisibilityOrg))
+	assert.False(t, canRead(Subject{UserID: stranger.ID}, VisibilityOrg))
+
+	// Assert that only the owner may change the task
+	assert.True(t, CanWrite(Subject{UserID: owner.ID}, task))
+	assert.False(t, CanWrite(Subject{UserID: assignee.ID}, task))
+	assert.False(t, CanWrite(guest, task))
+}
+
+// TestRestrictQuery tests that guests only list the non-private tasks of their project
+func TestRestrictQuery(t *testing.T) {
+	project := primitive.NewObjectID()
+
+	query := repository.TaskQuery{}
+	RestrictQuery(Subject{UserID: primitive.NewObjectID()}, &query)
+	// Assert that the queries of users are left as they are
+	assert.Equal(t, repository.TaskQuery{}, query)
+
+	RestrictQuery(Subject{UserID: primitive.NewObjectID(), GuestProjectID: project}, &query)
+	// Assert that guest queries are limited to their project, without private tasks
+	assert.Equal(t, project, query.ProjectID)
+	assert.Equal(t, VisibilityPrivate, query.ExcludeVisibility)
+
+	// Assert that unknown visibilities are rejected
+	assert.True(t, Valid(""))
+	assert.False(t, Valid("public"))
+}
@@ -0,0 +1,175 @@
+// board.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package planning
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/bkojha74/task-management/lexorank"
+	"github.com/bkojha74/task-management/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Errors returned by PlaceCard
+var (
+	ErrUnknownNeighbour = errors.New("neighbour card is not in the column")
+	ErrNotAdjacent      = errors.New("after and before cards are not adjacent")
+)
+
+// Column is one status column of a kanban board.
+type Column struct {
+	Status string        `json:"status"`
+	Tasks  []models.Task `json:"tasks"`
+}
+
+// BuildBoard groups tasks into status columns. The columns follow the workflow order of
+// models.TaskStatuses, always present, followed by any other statuses in alphabetical order. Cards are
+// ordered by rank; cards that were never moved follow, oldest first.
+//
+// Parameters:
+// - tasks: The tasks on the board.
+//
+// Returns:
+// - []Column: The columns in display order.
+func BuildBoard(tasks []models.Task) []Column {
+	byStatus := map[string][]models.Task{}
+	for _, task := range tasks {
+		byStatus[task.Status] = append(byStatus[task.Status], task)
+	}
+
+	statuses := append([]string{}, models.TaskStatuses...)
+	others := []string{}
+	for status := range byStatus {
+		if !containsStatus(models.TaskStatuses, status) {
+			others = append(others, status)
+		}
+	}
+	sort.Strings(others)
+
+	columns := []Column{}
+	for _, status := range append(statuses, others...) {
+		cards := byStatus[status]
+		if cards == nil {
+			cards = []models.Task{}
+		}
+		SortCards(cards)
+		columns = append(columns, Column{Status: status, Tasks: cards})
+	}
+	return columns
+}
+
+// SortCards orders cards by rank, then unranked cards by ID.
+func SortCards(cards []models.Task) {
+	sort.Slice(cards, func(i, j int) bool {
+		a, b := cards[i], cards[j]
+		if (a.Rank == "") != (b.Rank == "") {
+			return b.Rank == ""
+		}
+		if a.Rank != b.Rank {
+			return a.Rank < b.Rank
+		}
+		return bytes.Compare(a.ID[:], b.ID[:]) < 0
+	})
+}
+
+// PlaceCard ranks a card for its new position in a column, between the card afterID (the card above) and
+// the card beforeID (the card below). Either may be zero; with neither the card goes to the bottom.
+// When the column has unranked cards or no rank fits between the neighbours, the whole column is re-ranked.
+//
+// Parameters:
+// - column: The cards of the target column in board order, without the moved card.
+// - cardID: The moved card.
+// - afterID: The card to place the moved card after, or zero.
+// - beforeID: The card to place the moved card before, or zero.
+//
+// Returns:
+// - map[primitive.ObjectID]string: The new ranks of the moved card and of any re-ranked cards.
+// - error: ErrUnknownNeighbour or ErrNotAdjacent for neighbours that don't describe a position.
+func PlaceCard(column []models.Task, cardID, afterID, beforeID primitive.ObjectID) (map[primitive.ObjectID]string, error) {
+	pos := len(column)
+	if !afterID.IsZero() {
+		i := indexOfCard(column, afterID)
+		if i < 0 {
+			return nil, ErrUnknownNeighbour
+		}
+		pos = i + 1
+	}
+	if !beforeID.IsZero() {
+		i := indexOfCard(column, beforeID)
+		if i < 0 {
+			return nil, ErrUnknownNeighbour
+		}
+		if !afterID.IsZero() && i != pos {
+			return nil, ErrNotAdjacent
+		}
+		pos = i
+	}
+
+	ranked := true
+	for _, card := range column {
+		ranked = ranked && card.Rank != ""
+	}
+	if ranked {
+		lower, upper := "", ""
+		if pos > 0 {
+			lower = column[pos-1].Rank
+		}
+		if pos < len(column) {
+			upper = column[pos].Rank
+		}
+		if rank, err := lexorank.Between(lower, upper); err == nil {
+			return map[primitive.ObjectID]string{cardID: rank}, nil
+		}
+	}
+
+	// Re-rank the column with the card in its new position
+	ids := make([]primitive.ObjectID, 0, len(column)+1)
+	for _, card := range column[:pos] {
+		ids = append(ids, card.ID)
+	}
+	ids = append(ids, cardID)
+	for _, card := range column[pos:] {
+		ids = append(ids, card.ID)
+	}
+
+	ranks := lexorank.Spread(len(ids))
+	changed := map[primitive.ObjectID]string{cardID: ranks[pos]}
+	for i, id := range ids {
+		if id != cardID && column[cardIndex(i, pos)].Rank != ranks[i] {
+			changed[id] = ranks[i]
+		}
+	}
+	return changed, nil
+}
+
+// cardIndex maps a position in the re-ranked column back to the column without the moved card.
+func cardIndex(i, pos int) int {
+	if i > pos {
+		return i - 1
+	}
+	return i
+}
+
+// indexOfCard returns the position of a card in a column, or -1.
+func indexOfCard(column []models.Task, id primitive.ObjectID) int {
+	for i, card := range column {
+		if card.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// containsStatus reports whether statuses contains status.
+func containsStatus(statuses []string, status string) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+// board_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package planning
+
+import (
+	"testing"
+
+	"github.com/bkojha74/task-management/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// card returns a task with a new ID in the given column and position.
+func card(status, rank string) models.Task {
+	return models.Task{ID: primitive.NewObjectID(), Status: status, Rank: rank}
+}
+
+// TestBuildBoard tests the column order and the card order within columns
+func TestBuildBoard(t *testing.T) {
+	unranked := card(models.TaskStatusPending, "")
+	second := card(models.TaskStatusPending, "r")
+	first := card(models.TaskStatusPending, "i")
+	blocked := card("Blocked", "")
+
+	columns := BuildBoard([]models.Task{unranked, second, blocked, first})
+
+	// Assert that workflow columns come first, even when empty, then other statuses
+	require.Len(t, columns, 4)
+	assert.Equal(t, []string{models.TaskStatusPending, models.TaskStatusInProgress, models.TaskStatusDone, "Blocked"},
+		[]string{columns[0].Status, columns[1].Status, columns[2].Status, columns[3].Status})
+	assert.Empty(t, columns[1].Tasks)
+
+	// Assert that ranked cards come first in rank order
+	assert.Equal(t, []primitive.ObjectID{first.ID, second.ID, unranked.ID},
+		[]primitive.ObjectID{columns[0].Tasks[0].ID, columns[0].Tasks[1].ID, columns[0].Tasks[2].ID})
+}
+
+// TestPlaceCard tests that a moved card is ranked between its neighbours
+func TestPlaceCard(t *testing.T) {
+	a, b := card(models.TaskStatusPending, "i"), card(models.TaskStatusPending, "r")
+	column := []models.Task{a, b}
+	moved := primitive.NewObjectID()
+
+	// Assert that only the moved card is ranked when it fits
+	ranks, err := PlaceCard(column, moved, a.ID, b.ID)
+	require.NoError(t, err)
+	require.Len(t, ranks, 1)
+	assert.Greater(t, ranks[moved], a.Rank)
+	assert.Less(t, ranks[moved], b.Rank)
+
+	ranks, err = PlaceCard(column, moved, primitive.NilObjectID, a.ID)
+	require.NoError(t, err)
+	assert.Less(t, ranks[moved], a.Rank)
+
+	ranks, err = PlaceCard(column, moved, primitive.NilObjectID, primitive.NilObjectID)
+	require.NoError(t, err)
+	assert.Greater(t, ranks[moved], b.Rank)
+
+	// Assert that a column with unranked cards is re-ranked in order
+	c := card(models.TaskStatusPending, "")
+	ranks, err = PlaceCard([]models.Task{a, b, c}, moved, b.ID, c.ID)
+	require.NoError(t, err)
+	assert.Less(t, ranks[moved], ranks[c.ID])
+	if rank, ok := ranks[b.ID]; ok {
+		assert.Less(t, rank, ranks[moved])
+	} else {
+		assert.Less(t, b.Rank, ranks[moved])
+	}
+
+	// Assert that unknown and non-adjacent neighbours are rejected
+	_, err = PlaceCard(column, moved, primitive.NewObjectID(), primitive.NilObjectID)
+	assert.ErrorIs(t, err, ErrUnknownNeighbour)
+	_, err = PlaceCard(column, moved, b.ID, a.ID)
+	assert.ErrorIs(t, err, ErrNotAdjacent)
+}
@@ -0,0 +1,149 @@
+// burndown.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package planning
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxBurndownDays is the longest range a burndown is computed for.
+const MaxBurndownDays = 366
+
+// ErrInvalidRange is returned for burndown ranges that end before they start or are too long.
+var ErrInvalidRange = errors.New("invalid date range")
+
+// BurndownDay is the state of a project at the end of one day.
+type BurndownDay struct {
+	Date             string  `json:"date"`              // Day in YYYY-MM-DD form (UTC)
+	RemainingMinutes int     `json:"remaining_minutes"` // Estimates of the tasks still open
+	RemainingTasks   int     `json:"remaining_tasks"`   // Number of tasks still open
+	CompletedMinutes int     `json:"completed_minutes"` // Estimates of the tasks completed so far
+	IdealMinutes     float64 `json:"ideal_minutes"`     // Remaining work on a straight line to zero at the end of the range
+}
+
+// Burndown is the daily remaining-work series of a set of tasks.
+type Burndown struct {
+	From                 string        `json:"from"`
+	To                   string        `json:"to"`
+	TotalEstimateMinutes int           `json:"total_estimate_minutes"` // Estimates of all tasks in scope at the end
+	ActualMinutes        int           `json:"actual_minutes"`         // Actual effort of the completed tasks
+	UnestimatedTasks     int           `json:"unestimated_tasks"`      // Tasks in scope without an estimate
+	Days                 []BurndownDay `json:"days"`
+}
+
+// ComputeBurndown computes the remaining work at the end of every day from..to (UTC, inclusive).
+// A task is in scope from the day it was created, taken from its ObjectID, and remaining until the day it
+// was completed. Tasks marked Done without a completion time are treated as completed before the range.
+//
+// Parameters:
+// - tasks: The tasks to track, e.g. those of a project.
+// - from: The first day.
+// - to: The last day.
+//
+// Returns:
+// - Burndown: The series, one entry per day.
+// - error: ErrInvalidRange if to is before from or the range is longer than MaxBurndownDays.
+func ComputeBurndown(tasks []models.Task, from, to time.Time) (Burndown, error) {
+	from, to = Day(from), Day(to)
+	days := int(to.Sub(from).Hours()/24) + 1
+	if days < 1 || days > MaxBurndownDays {
+		return Burndown{}, ErrInvalidRange
+	}
+
+	burndown := Burndown{From: from.Format(time.DateOnly), To: to.Format(time.DateOnly), Days: make([]BurndownDay, 0, days)}
+	for i := 0; i < days; i++ {
+		end := from.AddDate(0, 0, i+1)
+		day := BurndownDay{Date: from.AddDate(0, 0, i).Format(time.DateOnly)}
+		for _, task := range tasks {
+			if !task.ID.Timestamp().Before(end) {
+				continue
+			}
+			if completedBy(task, end) {
+				day.CompletedMinutes += task.EstimateMinutes
+			} else {
+				day.RemainingMinutes += task.EstimateMinutes
+				day.RemainingTasks++
+			}
+		}
+		burndown.Days = append(burndown.Days, day)
+	}
+
+	// The ideal line starts at the work remaining on the first day and reaches zero on the last
+	start := float64(burndown.Days[0].RemainingMinutes)
+	for i := range burndown.Days {
+		ideal := start
+		if days > 1 {
+			ideal = start * float64(days-1-i) / float64(days-1)
+		}
+		burndown.Days[i].IdealMinutes = math.Round(ideal*10) / 10
+	}
+
+	rangeEnd := to.AddDate(0, 0, 1)
+	for _, task := range tasks {
+		if !task.ID.Timestamp().Before(rangeEnd) {
+			continue
+		}
+		burndown.TotalEstimateMinutes += task.EstimateMinutes
+		if task.EstimateMinutes == 0 {
+			burndown.UnestimatedTasks++
+		}
+		if completedBy(task, rangeEnd) {
+			burndown.ActualMinutes += task.ActualMinutes
+		}
+	}
+	return burndown, nil
+}
+
+// completedBy reports whether a task was completed before the given time.
+func completedBy(task models.Task, t time.Time) bool {
+	if task.Status != models.TaskStatusDone {
+		return false
+	}
+	return task.CompletedAt == 0 || task.CompletedAt.Time().Before(t)
+}
+
+// Day truncates a time to the start of its day in UTC.
+func Day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// TrackEffort stamps the server-managed effort fields of a task whose status changes from previous to
+// task.Status: StartedAt when work starts, and CompletedAt and ActualMinutes when it is completed.
+// Tasks completed without being started first count from their start_time. Reopening a task clears its
+// completion and unarchives it. Values sent by clients, including ArchivedAt, are replaced.
+//
+// Parameters:
+// - task: The task being written.
+// - previous: The stored task, or nil when it is created.
+// - now: The time of the change.
+func TrackEffort(task *models.Task, previous *models.Task, now time.Time) {
+	task.StartedAt, task.CompletedAt, task.ActualMinutes, task.ArchivedAt = 0, 0, 0, 0
+	if previous != nil {
+		task.StartedAt, task.CompletedAt, task.ActualMinutes = previous.StartedAt, previous.CompletedAt, previous.ActualMinutes
+		if task.Status == models.TaskStatusDone {
+			task.ArchivedAt = previous.ArchivedAt
+		}
+	}
+
+	if task.Status != models.TaskStatusPending && task.StartedAt == 0 {
+		task.StartedAt = primitive.NewDateTimeFromTime(now)
+		if task.Status == models.TaskStatusDone && task.StartDate != 0 && task.StartDate.Time().Before(now) {
+			task.StartedAt = primitive.DateTime(task.StartDate)
+		}
+	}
+	switch {
+	case task.Status == models.TaskStatusDone && task.CompletedAt == 0:
+		task.CompletedAt = primitive.NewDateTimeFromTime(now)
+		task.ActualMinutes = int(now.Sub(task.StartedAt.Time()).Minutes())
+	case task.Status != models.TaskStatusDone:
+		task.CompletedAt, task.ActualMinutes = 0, 0
+	}
+}
@@ -0,0 +1,92 @@
+// burndown_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package planning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// taskCreated returns a task whose ObjectID was generated at the given time.
+func taskCreated(at time.Time, estimate int) models.Task {
+	return models.Task{ID: primitive.NewObjectIDFromTimestamp(at), EstimateMinutes: estimate, Status: models.TaskStatusPending}
+}
+
+// TestComputeBurndown tests that remaining work follows task creation and completion per day
+func TestComputeBurndown(t *testing.T) {
+	day1 := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	day2, day3 := day1.AddDate(0, 0, 1), day1.AddDate(0, 0, 2)
+
+	done := taskCreated(day1, 120)
+	done.Status = models.TaskStatusDone
+	done.CompletedAt = primitive.NewDateTimeFromTime(day2)
+	done.ActualMinutes = 150
+	tasks := []models.Task{done, taskCreated(day1, 60), taskCreated(day2, 30), taskCreated(day3, 0)}
+
+	burndown, err := ComputeBurndown(tasks, day1, day3)
+	require.NoError(t, err)
+
+	// Assert one entry per day with the work remaining at its end
+	require.Len(t, burndown.Days, 3)
+	assert.Equal(t, "2024-03-04", burndown.Days[0].Date)
+	assert.Equal(t, 180, burndown.Days[0].RemainingMinutes)
+	assert.Equal(t, 60+30, burndown.Days[1].RemainingMinutes)
+	assert.Equal(t, 120, burndown.Days[1].CompletedMinutes)
+	assert.Equal(t, 3, burndown.Days[2].RemainingTasks)
+
+	// Assert the ideal line runs from the first day's work to zero
+	assert.Equal(t, 180.0, burndown.Days[0].IdealMinutes)
+	assert.Equal(t, 90.0, burndown.Days[1].IdealMinutes)
+	assert.Equal(t, 0.0, burndown.Days[2].IdealMinutes)
+
+	// Assert the totals
+	assert.Equal(t, 210, burndown.TotalEstimateMinutes)
+	assert.Equal(t, 150, burndown.ActualMinutes)
+	assert.Equal(t, 1, burndown.UnestimatedTasks)
+
+	// Assert that reversed and overlong ranges are rejected
+	_, err = ComputeBurndown(tasks, day3, day1)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+	_, err = ComputeBurndown(tasks, day1, day1.AddDate(2, 0, 0))
+	assert.ErrorIs(t, err, ErrInvalidRange)
+}
+
+// TestTrackEffort tests the start, completion and reopening of a task
+func TestTrackEffort(t *testing.T) {
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	task := models.Task{Status: models.TaskStatusPending, ActualMinutes: 5}
+	TrackEffort(&task, nil, start)
+
+	// Assert that pending tasks are not started and client values are dropped
+	assert.Zero(t, task.StartedAt)
+	assert.Zero(t, task.ActualMinutes)
+
+	previous := task
+	task.Status = models.TaskStatusInProgress
+	TrackEffort(&task, &previous, start)
+	assert.Equal(t, primitive.NewDateTimeFromTime(start), task.StartedAt)
+
+	previous = task
+	task.Status = models.TaskStatusDone
+	TrackEffort(&task, &previous, start.Add(90*time.Minute))
+
+	// Assert that completion computes the actual effort
+	assert.Equal(t, primitive.NewDateTimeFromTime(start.Add(90*time.Minute)), task.CompletedAt)
+	assert.Equal(t, 90, task.ActualMinutes)
+
+	previous = task
+	task.Status = models.TaskStatusInProgress
+	TrackEffort(&task, &previous, start.Add(2*time.Hour))
+
+	// Assert that reopening clears the completion but keeps the start
+	assert.Zero(t, task.CompletedAt)
+	assert.Zero(t, task.ActualMinutes)
+	assert.Equal(t, primitive.NewDateTimeFromTime(start), task.StartedAt)
+}
@@ -0,0 +1,119 @@
+// critical.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package planning
+
+import (
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CriticalTask is a task with the latest times it can start and finish without delaying the schedule.
+type CriticalTask struct {
+	ID              primitive.ObjectID `json:"id"`
+	Title           string             `json:"title"`
+	DurationMinutes int                `json:"duration_minutes"`
+	EarliestStart   models.Timestamp   `json:"earliest_start"`
+	EarliestFinish  models.Timestamp   `json:"earliest_finish"`
+	LatestStart     models.Timestamp   `json:"latest_start"`
+	LatestFinish    models.Timestamp   `json:"latest_finish"`
+	SlackMinutes    int                `json:"slack_minutes"` // How long the task can slip without moving the finish
+	Critical        bool               `json:"critical"`      // No slack
+}
+
+// CriticalPath is the longest chain of dependent tasks of a schedule, which determines when it finishes.
+type CriticalPath struct {
+	Start           models.Timestamp     `json:"start"`
+	Finish          models.Timestamp     `json:"finish"`
+	DurationMinutes int                  `json:"duration_minutes"` // From Start to Finish
+	Path            []primitive.ObjectID `json:"path"`             // Critical tasks from the first to the last
+	Tasks           []CriticalTask       `json:"tasks"`            // Every task after its dependencies
+}
+
+// ComputeCriticalPath schedules tasks like Schedule and works back from the finish to find each task's
+// latest start and finish and its slack. The path follows tasks without slack from one finishing last back
+// through the dependencies that held up its start.
+//
+// Parameters:
+// - tasks: The tasks, e.g. those of a project.
+// - start: The earliest start of any task, see Schedule.
+//
+// Returns:
+// - CriticalPath: The critical path and the slack of every task.
+// - error: ErrDependencyCycle if the dependencies form a cycle.
+func ComputeCriticalPath(tasks []models.Task, start time.Time) (CriticalPath, error) {
+	gantt, err := Schedule(tasks, start)
+	if err != nil {
+		return CriticalPath{}, err
+	}
+	result := CriticalPath{
+		Start:  gantt.Start,
+		Finish: gantt.Finish,
+		Path:   []primitive.ObjectID{},
+		Tasks:  make([]CriticalTask, len(gantt.Tasks)),
+	}
+	if len(gantt.Tasks) == 0 {
+		return result, nil
+	}
+	finish := gantt.Finish.Time()
+	result.DurationMinutes = int(finish.Sub(gantt.Start.Time()).Minutes())
+
+	// Backward pass: a task must finish before the latest start of every task depending on it
+	position := make(map[primitive.ObjectID]int, len(gantt.Tasks))
+	for i, task := range gantt.Tasks {
+		position[task.ID] = i
+	}
+	latestFinish := make([]time.Time, len(gantt.Tasks))
+	for i := range latestFinish {
+		latestFinish[i] = finish
+	}
+	for i := len(gantt.Tasks) - 1; i >= 0; i-- {
+		task := gantt.Tasks[i]
+		span := task.EarliestFinish.Time().Sub(task.EarliestStart.Time())
+		latestStart := latestFinish[i].Add(-span)
+		for _, dependency := range task.DependsOn {
+			if j := position[dependency]; latestStart.Before(latestFinish[j]) {
+				latestFinish[j] = latestStart
+			}
+		}
+
+		slack := latestStart.Sub(task.EarliestStart.Time())
+		result.Tasks[i] = CriticalTask{
+			ID:              task.ID,
+			Title:           task.Title,
+			DurationMinutes: int(span.Minutes()),
+			EarliestStart:   task.EarliestStart,
+			EarliestFinish:  task.EarliestFinish,
+			LatestStart:     models.NewTimestamp(latestStart),
+			LatestFinish:    models.NewTimestamp(latestFinish[i]),
+			SlackMinutes:    int(slack.Minutes()),
+			Critical:        slack <= 0,
+		}
+	}
+
+	// Walk back from the first critical task finishing last through the critical dependencies ending
+	// exactly when it starts
+	current := -1
+	for i, task := range result.Tasks {
+		if task.Critical && task.EarliestFinish == gantt.Finish {
+			current = i
+			break
+		}
+	}
+	for current >= 0 {
+		result.Path = append([]primitive.ObjectID{result.Tasks[current].ID}, result.Path...)
+		next := -1
+		for _, dependency := range gantt.Tasks[current].DependsOn {
+			j := position[dependency]
+			if result.Tasks[j].Critical && result.Tasks[j].EarliestFinish == result.Tasks[current].EarliestStart {
+				next = j
+				break
+			}
+		}
+		current = next
+	}
+	return result, nil
+}
@@ -0,0 +1,55 @@
+// critical_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package planning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestComputeCriticalPath tests the slack of tasks and the chain of tasks without slack
+func TestComputeCriticalPath(t *testing.T) {
+	start := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	task := func(title string, minutes int, dependsOn ...models.Task) models.Task {
+		task := models.Task{ID: primitive.NewObjectID(), Title: title, EstimateMinutes: minutes}
+		for _, dependency := range dependsOn {
+			task.DependsOn = append(task.DependsOn, dependency.ID)
+		}
+		return task
+	}
+	// Design (2h) -> Backend (8h) -> Release (1h), and Design -> Frontend (5h) -> Release
+	design := task("Design", 120)
+	backend := task("Backend", 480, design)
+	frontend := task("Frontend", 300, design)
+	release := task("Release", 60, backend, frontend)
+	notes := task("Notes", 30)
+
+	path, err := ComputeCriticalPath([]models.Task{design, backend, frontend, release, notes}, start)
+	require.NoError(t, err)
+	assert.Equal(t, []primitive.ObjectID{design.ID, backend.ID, release.ID}, path.Path)
+	assert.Equal(t, 11*60, path.DurationMinutes)
+	assert.Equal(t, models.NewTimestamp(start.Add(11*time.Hour)), path.Finish)
+
+	slack := map[string]int{}
+	for _, task := range path.Tasks {
+		slack[task.Title] = task.SlackMinutes
+		assert.Equal(t, task.SlackMinutes == 0, task.Critical, task.Title)
+	}
+	assert.Equal(t, map[string]int{"Design": 0, "Backend": 0, "Frontend": 180, "Release": 0, "Notes": 630}, slack)
+
+	// Frontend can start at the latest three hours after Design finished
+	assert.Equal(t, models.NewTimestamp(start.Add(5*time.Hour)), path.Tasks[2].LatestStart)
+	assert.Equal(t, models.NewTimestamp(start.Add(10*time.Hour)), path.Tasks[2].LatestFinish)
+
+	// No tasks, no path
+	path, err = ComputeCriticalPath(nil, start)
+	require.NoError(t, err)
+	assert.Empty(t, path.Path)
+}
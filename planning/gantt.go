@@ -0,0 +1,191 @@
+// gantt.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package planning
+
+import (
+	"container/heap"
+	"errors"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrDependencyCycle is returned when tasks depend on each other in a cycle and cannot be scheduled.
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// GanttTask is a task placed on a schedule.
+type GanttTask struct {
+	ID              primitive.ObjectID   `json:"id"`
+	Title           string               `json:"title"`
+	Status          string               `json:"status"`
+	AllottedTo      string               `json:"allotted_to"`
+	DependsOn       []primitive.ObjectID `json:"depends_on"`       // Dependencies within the scheduled tasks
+	StartTime       models.Timestamp     `json:"start_time"`       // Planned start, if any
+	EndTime         models.Timestamp     `json:"end_time"`         // Planned end, if any
+	DurationMinutes int                  `json:"duration_minutes"` // Planned span, else the estimate, else 0 for a milestone
+	EarliestStart   models.Timestamp     `json:"earliest_start"`   // Not before the planned start nor the end of any dependency
+	EarliestFinish  models.Timestamp     `json:"earliest_finish"`  // EarliestStart plus the duration, or the completion of done tasks
+	Delayed         bool                 `json:"delayed"`          // EarliestFinish is after the planned end
+}
+
+// Gantt is the schedule of a set of tasks.
+type Gantt struct {
+	Start  models.Timestamp `json:"start"`  // Earliest start of all tasks
+	Finish models.Timestamp `json:"finish"` // Latest earliest finish of all tasks
+	Tasks  []GanttTask      `json:"tasks"`  // Every task after its dependencies
+}
+
+// Duration returns the time a task takes: the span from its start_time to its end_time when both are set,
+// else its estimate, else zero.
+//
+// Parameters:
+// - task: The task.
+//
+// Returns:
+// - time.Duration: The duration.
+func Duration(task models.Task) time.Duration {
+	if task.StartDate != 0 && task.EndDate > task.StartDate {
+		return task.EndDate.Time().Sub(task.StartDate.Time())
+	}
+	return time.Duration(task.EstimateMinutes) * time.Minute
+}
+
+// Schedule computes the earliest start and finish of tasks from their dependencies and durations. A task
+// starts at the later of its start_time and start, unless a dependency finishes later; done tasks finish
+// when they were completed. Dependencies on tasks not in the set are ignored.
+//
+// Parameters:
+// - tasks: The tasks to schedule, e.g. those of a project.
+// - start: The earliest start of any task, e.g. the start of the project.
+//
+// Returns:
+// - Gantt: The schedule, listing every task after its dependencies and otherwise in the given order.
+// - error: ErrDependencyCycle if the dependencies form a cycle.
+func Schedule(tasks []models.Task, start time.Time) (Gantt, error) {
+	order, err := topologicalOrder(tasks)
+	if err != nil {
+		return Gantt{}, err
+	}
+
+	index := make(map[primitive.ObjectID]int, len(tasks))
+	for i, task := range tasks {
+		index[task.ID] = i
+	}
+	finish := make(map[primitive.ObjectID]time.Time, len(tasks))
+
+	gantt := Gantt{Tasks: make([]GanttTask, 0, len(tasks))}
+	var first, last time.Time
+	for _, i := range order {
+		task := tasks[i]
+		duration := Duration(task)
+		entry := GanttTask{
+			ID:              task.ID,
+			Title:           task.Title,
+			Status:          task.Status,
+			AllottedTo:      task.AllottedTo,
+			DependsOn:       []primitive.ObjectID{},
+			StartTime:       task.StartDate,
+			EndTime:         task.EndDate,
+			DurationMinutes: int(duration.Minutes()),
+		}
+
+		earliest := start
+		if task.StartDate.Time().After(earliest) {
+			earliest = task.StartDate.Time()
+		}
+		for _, dependency := range task.DependsOn {
+			if _, ok := index[dependency]; !ok {
+				continue
+			}
+			entry.DependsOn = append(entry.DependsOn, dependency)
+			if finish[dependency].After(earliest) {
+				earliest = finish[dependency]
+			}
+		}
+		end := earliest.Add(duration)
+		if task.Status == models.TaskStatusDone && task.CompletedAt != 0 {
+			end = task.CompletedAt.Time()
+			if earliest.After(end) {
+				earliest = end.Add(-duration)
+			}
+		}
+		finish[task.ID] = end
+
+		entry.EarliestStart, entry.EarliestFinish = models.NewTimestamp(earliest), models.NewTimestamp(end)
+		entry.Delayed = task.Status != models.TaskStatusDone && task.EndDate != 0 && end.After(task.EndDate.Time())
+		gantt.Tasks = append(gantt.Tasks, entry)
+
+		if first.IsZero() || earliest.Before(first) {
+			first = earliest
+		}
+		if end.After(last) {
+			last = end
+		}
+	}
+	if len(order) > 0 {
+		gantt.Start, gantt.Finish = models.NewTimestamp(first), models.NewTimestamp(last)
+	}
+	return gantt, nil
+}
+
+// topologicalOrder returns the indexes of tasks ordered so that every task comes after the tasks it depends
+// on, keeping the given order otherwise.
+func topologicalOrder(tasks []models.Task) ([]int, error) {
+	index := make(map[primitive.ObjectID]int, len(tasks))
+	for i, task := range tasks {
+		index[task.ID] = i
+	}
+	waiting := make([]int, len(tasks))      // Dependencies not yet ordered, per task
+	dependents := make([][]int, len(tasks)) // Tasks depending on each task
+	for i, task := range tasks {
+		seen := map[primitive.ObjectID]bool{}
+		for _, dependency := range task.DependsOn {
+			j, ok := index[dependency]
+			if !ok || seen[dependency] {
+				continue
+			}
+			seen[dependency] = true
+			waiting[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	// Repeatedly take the first task in the given order whose dependencies are all ordered
+	ready := &indexHeap{}
+	for i := range tasks {
+		if waiting[i] == 0 {
+			heap.Push(ready, i)
+		}
+	}
+	order := make([]int, 0, len(tasks))
+	for ready.Len() > 0 {
+		next := heap.Pop(ready).(int)
+		order = append(order, next)
+		for _, dependent := range dependents[next] {
+			if waiting[dependent]--; waiting[dependent] == 0 {
+				heap.Push(ready, dependent)
+			}
+		}
+	}
+	if len(order) < len(tasks) {
+		return nil, ErrDependencyCycle
+	}
+	return order, nil
+}
+
+// indexHeap is a min-heap of task indexes.
+type indexHeap []int
+
+func (h indexHeap) Len() int            { return len(h) }
+func (h indexHeap) Less(i, j int) bool  { return h[i] < h[j] }
+func (h indexHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *indexHeap) Push(x interface{}) { *h = append(*h, x.(int)) }
+func (h *indexHeap) Pop() interface{} {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
@@ -0,0 +1,65 @@
+// gantt_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package planning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestSchedule tests that tasks start after their dependencies finish and are listed after them
+func TestSchedule(t *testing.T) {
+	start := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	design := models.Task{ID: primitive.NewObjectID(), Title: "Design", EstimateMinutes: 120}
+	build := models.Task{ID: primitive.NewObjectID(), Title: "Build", EstimateMinutes: 480}
+	docs := models.Task{ID: primitive.NewObjectID(), Title: "Docs", StartDate: models.NewTimestamp(start.Add(time.Hour)), EndDate: models.NewTimestamp(start.Add(3 * time.Hour))}
+	release := models.Task{ID: primitive.NewObjectID(), Title: "Release", EndDate: models.NewTimestamp(start.Add(8 * time.Hour))}
+	build.DependsOn = []primitive.ObjectID{design.ID}
+	release.DependsOn = []primitive.ObjectID{build.ID, docs.ID, primitive.NewObjectID()}
+
+	// Release is given first but scheduled last
+	gantt, err := Schedule([]models.Task{release, docs, build, design}, start)
+	require.NoError(t, err)
+	require.Len(t, gantt.Tasks, 4)
+	titles := []string{}
+	for _, task := range gantt.Tasks {
+		titles = append(titles, task.Title)
+	}
+	assert.Equal(t, []string{"Docs", "Design", "Build", "Release"}, titles)
+
+	// Docs keeps its planned span, Build waits for Design, Release for Build
+	assert.Equal(t, models.NewTimestamp(start.Add(time.Hour)), gantt.Tasks[0].EarliestStart)
+	assert.Equal(t, 120, gantt.Tasks[0].DurationMinutes)
+	assert.Equal(t, models.NewTimestamp(start.Add(2*time.Hour)), gantt.Tasks[2].EarliestStart)
+	assert.Equal(t, models.NewTimestamp(start.Add(10*time.Hour)), gantt.Tasks[2].EarliestFinish)
+	assert.Equal(t, models.NewTimestamp(start.Add(10*time.Hour)), gantt.Tasks[3].EarliestStart)
+
+	// Release cannot meet its end date; unknown dependencies are dropped
+	assert.True(t, gantt.Tasks[3].Delayed)
+	assert.False(t, gantt.Tasks[2].Delayed)
+	assert.Equal(t, []primitive.ObjectID{build.ID, docs.ID}, gantt.Tasks[3].DependsOn)
+	assert.Equal(t, models.NewTimestamp(start), gantt.Start)
+	assert.Equal(t, models.NewTimestamp(start.Add(10*time.Hour)), gantt.Finish)
+
+	// A completed dependency frees its dependents when it was completed
+	design.Status = models.TaskStatusDone
+	design.CompletedAt = primitive.NewDateTimeFromTime(start.Add(30 * time.Minute))
+	gantt, err = Schedule([]models.Task{design, build}, start)
+	require.NoError(t, err)
+	assert.Equal(t, models.NewTimestamp(start.Add(30*time.Minute)), gantt.Tasks[1].EarliestStart)
+}
+
+// TestScheduleCycle tests that cyclic dependencies are reported
+func TestScheduleCycle(t *testing.T) {
+	a, b := models.Task{ID: primitive.NewObjectID()}, models.Task{ID: primitive.NewObjectID()}
+	a.DependsOn, b.DependsOn = []primitive.ObjectID{b.ID}, []primitive.ObjectID{a.ID}
+	_, err := Schedule([]models.Task{a, b}, time.Now())
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+}
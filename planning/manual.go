@@ -0,0 +1,47 @@
+// manual.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package planning
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/bkojha74/task-management/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SortManual orders tasks as GET /tasks?sort=manual does: pinned tasks first, each group by position,
+// tasks never reordered last, and ties by ID.
+func SortManual(tasks []models.Task) {
+	sort.Slice(tasks, func(i, j int) bool {
+		a, b := tasks[i].ManualKey(), tasks[j].ManualKey()
+		if a != b {
+			return a < b
+		}
+		return bytes.Compare(tasks[i].ID[:], tasks[j].ID[:]) < 0
+	})
+}
+
+// PlaceTask positions a task in the manual order between the task afterID (above) and the task beforeID
+// (below), like PlaceCard does for board columns. Either may be zero; with neither the task goes to the
+// bottom.
+//
+// Parameters:
+// - group: The tasks of the owner in the moved task's pin group in manual order, without the moved task.
+// - taskID: The moved task.
+// - afterID: The task to place the moved task after, or zero.
+// - beforeID: The task to place the moved task before, or zero.
+//
+// Returns:
+// - map[primitive.ObjectID]string: The new positions of the moved task and of any re-ranked tasks.
+// - error: ErrUnknownNeighbour or ErrNotAdjacent for neighbours that don't describe a position.
+func PlaceTask(group []models.Task, taskID, afterID, beforeID primitive.ObjectID) (map[primitive.ObjectID]string, error) {
+	// Positions are ranks of one list, so the cards of PlaceCard carry them as their rank
+	cards := make([]models.Task, len(group))
+	for i, task := range group {
+		cards[i] = models.Task{ID: task.ID, Rank: task.Position}
+	}
+	return PlaceCard(cards, taskID, afterID, beforeID)
+}
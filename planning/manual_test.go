@@ -0,0 +1,42 @@
+// manual_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package planning
+
+import (
+	"testing"
+
+	"github.com/bkojha74/task-management/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestManualOrder tests that pinned tasks come first and moved tasks are positioned between their neighbours
+func TestManualOrder(t *testing.T) {
+	unranked := models.Task{ID: primitive.NewObjectID()}
+	low := models.Task{ID: primitive.NewObjectID(), Position: "i"}
+	high := models.Task{ID: primitive.NewObjectID(), Position: "r"}
+	pinned := models.Task{ID: primitive.NewObjectID(), Position: "z", Pinned: true}
+
+	tasks := []models.Task{unranked, high, pinned, low}
+	SortManual(tasks)
+	// Assert that pinned tasks lead, then positioned tasks, then the tasks never reordered
+	assert.Equal(t, []primitive.ObjectID{pinned.ID, low.ID, high.ID, unranked.ID},
+		[]primitive.ObjectID{tasks[0].ID, tasks[1].ID, tasks[2].ID, tasks[3].ID})
+
+	// Assert that a task placed between two positioned tasks only gets a position of its own
+	moved := primitive.NewObjectID()
+	positions, err := PlaceTask([]models.Task{low, high}, moved, low.ID, high.ID)
+	require.NoError(t, err)
+	require.Len(t, positions, 1)
+	assert.Greater(t, positions[moved], low.Position)
+	assert.Less(t, positions[moved], high.Position)
+
+	// Assert that tasks never reordered are positioned when a task is placed among them
+	positions, err = PlaceTask([]models.Task{low, unranked}, moved, unranked.ID, primitive.NilObjectID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, positions[unranked.ID])
+	assert.Greater(t, positions[moved], positions[unranked.ID])
+}
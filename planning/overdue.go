@@ -0,0 +1,33 @@
+// overdue.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package planning
+
+import (
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+)
+
+// IsOverdue reports whether a task's end_time has passed while it is not Done.
+//
+// Parameters:
+// - task: The task to check.
+// - now: The current time.
+//
+// Returns:
+// - bool: True if the task is overdue.
+func IsOverdue(task models.Task, now time.Time) bool {
+	return task.Status != models.TaskStatusDone && task.EndDate > 0 && task.EndDate.Time().Before(now)
+}
+
+// TrackOverdue keeps the overdue flag of a written task while it is still overdue. The flag is only ever set
+// by the overdue job, which also sends the notification, so writes can clear it but never set it.
+//
+// Parameters:
+// - task: The task being written.
+// - previous: The stored task, or nil when it is created.
+// - now: The time of the change.
+func TrackOverdue(task *models.Task, previous *models.Task, now time.Time) {
+	task.Overdue = previous != nil && previous.Overdue && IsOverdue(*task, now)
+}
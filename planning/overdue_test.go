@@ -0,0 +1,40 @@
+// overdue_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package planning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTrackOverdue tests that writes keep the overdue flag only while the task is still overdue
+func TestTrackOverdue(t *testing.T) {
+	now := time.Now()
+	previous := models.Task{Status: models.TaskStatusPending, EndDate: models.NewTimestamp(now.Add(-time.Hour)), Overdue: true}
+
+	// Assert that the flag survives unrelated changes
+	task := previous
+	task.Title = "Renamed"
+	TrackOverdue(&task, &previous, now)
+	assert.True(t, task.Overdue)
+
+	// Assert that completing the task or moving its due date clears the flag
+	task.Status = models.TaskStatusDone
+	TrackOverdue(&task, &previous, now)
+	assert.False(t, task.Overdue)
+
+	task = previous
+	task.EndDate = models.NewTimestamp(now.Add(time.Hour))
+	TrackOverdue(&task, &previous, now)
+	assert.False(t, task.Overdue)
+
+	// Assert that clients cannot set the flag
+	task = models.Task{Status: models.TaskStatusPending, EndDate: previous.EndDate, Overdue: true}
+	TrackOverdue(&task, nil, now)
+	assert.False(t, task.Overdue)
+}
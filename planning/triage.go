@@ -0,0 +1,202 @@
+// triage.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package planning
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Scales of the triage factors: a factor reaches its maximum of 1 at these values.
+const (
+	TriageDueHorizon = 14 * 24 * time.Hour // Tasks due further away score 0 on due date proximity
+	TriageMaxAge     = 30 * 24 * time.Hour // Tasks open for longer score 1 on age
+	TriageMaxUnblock = 5                   // Tasks other tasks wait on this many times score 1 on unblocking
+)
+
+// MaxTriageWeight is the largest weight of a triage factor.
+const MaxTriageWeight = 100
+
+// ErrInvalidWeights is returned for weights that are not factor=number pairs within range.
+var ErrInvalidWeights = errors.New("invalid triage weights")
+
+// TriageWeights are the weights of the factors a task's triage score is made of. Only their ratios
+// matter; a weight of 0 ignores the factor.
+type TriageWeights struct {
+	Due      float64 `json:"due"`      // Due date proximity, overdue tasks scoring highest
+	Priority float64 `json:"priority"` // High, medium or low priority
+	Age      float64 `json:"age"`      // Time since the task was created
+	Unblocks float64 `json:"unblocks"` // Open tasks that depend on the task and can start once it is done
+}
+
+// DefaultTriageWeights rank by due date first, then priority and unblocking, with age breaking ties.
+var DefaultTriageWeights = TriageWeights{Due: 4, Priority: 3, Age: 1, Unblocks: 2}
+
+// ParseTriageWeights reads weights given as comma-separated factor=weight pairs, e.g. "due=5,age=0".
+// Factors that are not given keep their weight in base.
+//
+// Parameters:
+// - value: The weights; empty returns base.
+// - base: The weights of the factors not given.
+//
+// Returns:
+// - TriageWeights: The weights.
+// - error: ErrInvalidWeights wrapped with the reason.
+func ParseTriageWeights(value string, base TriageWeights) (TriageWeights, error) {
+	weights := base
+	if strings.TrimSpace(value) == "" {
+		return weights, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		name, number, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return base, fmt.Errorf("%w: %q is not factor=weight", ErrInvalidWeights, pair)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+		if err != nil || math.IsNaN(weight) || weight < 0 || weight > MaxTriageWeight {
+			return base, fmt.Errorf("%w: the weight of %s must be a number between 0 and %d", ErrInvalidWeights, name, MaxTriageWeight)
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "due":
+			weights.Due = weight
+		case "priority":
+			weights.Priority = weight
+		case "age":
+			weights.Age = weight
+		case "unblocks":
+			weights.Unblocks = weight
+		default:
+			return base, fmt.Errorf("%w: unknown factor %q, use due, priority, age or unblocks", ErrInvalidWeights, name)
+		}
+	}
+	return weights, nil
+}
+
+// TriageFactors are the factors of a triage score, each between 0 and 1.
+type TriageFactors struct {
+	Due      float64 `json:"due"`
+	Priority float64 `json:"priority"`
+	Age      float64 `json:"age"`
+	Unblocks float64 `json:"unblocks"`
+}
+
+// TriageEntry is a ranked task with how its score came about.
+type TriageEntry struct {
+	Task      models.Task          `json:"task"`
+	Score     float64              `json:"score"`                // Weighted mean of the factors, 0 to 100
+	Factors   TriageFactors        `json:"factors"`              // Unweighted factors
+	Unblocks  int                  `json:"unblocks"`             // Open tasks depending on the task
+	BlockedBy []primitive.ObjectID `json:"blocked_by,omitempty"` // Open dependencies that must finish first
+	Snoozed   bool                 `json:"snoozed"`              // Snoozed until a later time
+}
+
+// Actionable reports whether the task can be worked on now: it is neither blocked nor snoozed.
+func (e TriageEntry) Actionable() bool {
+	return len(e.BlockedBy) == 0 && !e.Snoozed
+}
+
+// Triage ranks open tasks by what to work on next. Each task is scored on its due date proximity,
+// priority, age and the number of open tasks waiting on it, weighted by weights. Actionable tasks come
+// first, highest score first; tasks blocked by an open dependency or snoozed follow, also by score. Ties go
+// to the task due first, then to the oldest.
+//
+// Dependencies are looked up among tasks, so that tasks should hold all open tasks of the owner even when
+// only some are shown; dependencies not among them count as done.
+//
+// Parameters:
+// - tasks: The open tasks.
+// - weights: The weights of the factors.
+// - now: The current time.
+//
+// Returns:
+// - []TriageEntry: The tasks in ranked order.
+func Triage(tasks []models.Task, weights TriageWeights, now time.Time) []TriageEntry {
+	open := map[primitive.ObjectID]bool{}
+	waiting := map[primitive.ObjectID]int{}
+	for _, task := range tasks {
+		open[task.ID] = true
+	}
+	for _, task := range tasks {
+		for _, dependency := range task.DependsOn {
+			if open[dependency] {
+				waiting[dependency]++
+			}
+		}
+	}
+
+	entries := make([]TriageEntry, 0, len(tasks))
+	for _, task := range tasks {
+		entry := TriageEntry{
+			Task:     task,
+			Unblocks: waiting[task.ID],
+			Snoozed:  task.SnoozedUntil != 0 && task.SnoozedUntil.Time().After(now),
+		}
+		for _, dependency := range task.DependsOn {
+			if open[dependency] {
+				entry.BlockedBy = append(entry.BlockedBy, dependency)
+			}
+		}
+		entry.Factors = triageFactors(task, entry.Unblocks, now)
+		entry.Score = triageScore(entry.Factors, weights)
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Actionable() != b.Actionable() {
+			return a.Actionable()
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Task.EndDate.IsZero() != b.Task.EndDate.IsZero() {
+			return !a.Task.EndDate.IsZero()
+		}
+		if a.Task.EndDate != b.Task.EndDate {
+			return a.Task.EndDate < b.Task.EndDate
+		}
+		return a.Task.CreatedAt < b.Task.CreatedAt
+	})
+	return entries
+}
+
+// triageFactors computes the factors of a task that unblocks the given number of open tasks.
+func triageFactors(task models.Task, unblocks int, now time.Time) TriageFactors {
+	var factors TriageFactors
+	if !task.EndDate.IsZero() {
+		left := task.EndDate.Time().Sub(now)
+		factors.Due = math.Min(1, math.Max(0, 1-float64(left)/float64(TriageDueHorizon))) // Overdue tasks score 1
+	}
+	switch task.Priority {
+	case models.TaskPriorityHigh:
+		factors.Priority = 1
+	case models.TaskPriorityMedium:
+		factors.Priority = 0.5
+	}
+	if !task.CreatedAt.IsZero() {
+		age := now.Sub(task.CreatedAt.Time())
+		factors.Age = math.Min(1, math.Max(0, float64(age)/float64(TriageMaxAge)))
+	}
+	factors.Unblocks = math.Min(1, float64(unblocks)/TriageMaxUnblock)
+	return factors
+}
+
+// triageScore is the weighted mean of the factors on a scale of 0 to 100, rounded to one decimal.
+func triageScore(factors TriageFactors, weights TriageWeights) float64 {
+	total := weights.Due + weights.Priority + weights.Age + weights.Unblocks
+	if total == 0 {
+		return 0
+	}
+	sum := weights.Due*factors.Due + weights.Priority*factors.Priority + weights.Age*factors.Age + weights.Unblocks*factors.Unblocks
+	return math.Round(sum/total*1000) / 10
+}
@@ -0,0 +1,75 @@
+// triage_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package planning
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestParseTriageWeights tests that given factors override the base weights and invalid ones are rejected
+func TestParseTriageWeights(t *testing.T) {
+	weights, err := ParseTriageWeights("", DefaultTriageWeights)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTriageWeights, weights)
+
+	weights, err = ParseTriageWeights(" due=10, Age=0 ", DefaultTriageWeights)
+	require.NoError(t, err)
+	assert.Equal(t, TriageWeights{Due: 10, Priority: 3, Age: 0, Unblocks: 2}, weights)
+
+	for _, value := range []string{"due", "due=x", "due=-1", "due=101", "due=NaN", "effort=1"} {
+		_, err := ParseTriageWeights(value, DefaultTriageWeights)
+		assert.True(t, errors.Is(err, ErrInvalidWeights), value)
+	}
+}
+
+// TestTriage tests the factors of each task and the ranking of actionable, blocked and snoozed tasks
+func TestTriage(t *testing.T) {
+	now := time.Date(2024, 7, 10, 12, 0, 0, 0, time.UTC)
+	task := func(title, priority string, due time.Duration, age time.Duration) models.Task {
+		task := models.Task{ID: primitive.NewObjectID(), Title: title, Priority: priority, CreatedAt: models.NewTimestamp(now.Add(-age))}
+		if due != 0 {
+			task.EndDate = models.NewTimestamp(now.Add(due))
+		}
+		return task
+	}
+	overdue := task("overdue", models.TaskPriorityLow, -time.Hour, time.Hour)
+	urgent := task("urgent", models.TaskPriorityHigh, 7*24*time.Hour, 0)
+	someday := task("someday", "", 0, 60*24*time.Hour)
+	blocker := task("blocker", models.TaskPriorityMedium, 0, 0)
+	waiting := task("waiting", models.TaskPriorityHigh, -time.Hour, 0)
+	waiting.DependsOn = []primitive.ObjectID{blocker.ID, primitive.NewObjectID()} // The second one is done
+	snoozed := task("snoozed", models.TaskPriorityHigh, -time.Hour, 0)
+	snoozed.SnoozedUntil = primitive.NewDateTimeFromTime(now.Add(time.Hour))
+
+	entries := Triage([]models.Task{someday, waiting, snoozed, blocker, urgent, overdue}, DefaultTriageWeights, now)
+	require.Len(t, entries, 6)
+	titles := make([]string, len(entries))
+	for i, entry := range entries {
+		titles[i] = entry.Task.Title
+	}
+	assert.Equal(t, []string{"urgent", "overdue", "blocker", "someday", "waiting", "snoozed"}, titles)
+
+	// Assert the factors behind the scores
+	assert.Equal(t, TriageFactors{Due: 0.5, Priority: 1}, entries[0].Factors)
+	assert.Equal(t, 1.0, entries[1].Factors.Due)
+	assert.Equal(t, 1, entries[2].Unblocks)
+	assert.Equal(t, 0.2, entries[2].Factors.Unblocks)
+	assert.Equal(t, 1.0, entries[3].Factors.Age)
+	assert.Equal(t, 50.0, entries[0].Score)
+	assert.Equal(t, []primitive.ObjectID{blocker.ID}, entries[4].BlockedBy)
+	assert.True(t, entries[5].Snoozed)
+
+	// Assert that the weights change the ranking
+	entries = Triage([]models.Task{someday, urgent}, TriageWeights{Age: 1}, now)
+	assert.Equal(t, "someday", entries[0].Task.Title)
+	assert.Zero(t, Triage([]models.Task{urgent}, TriageWeights{}, now)[0].Score)
+}
@@ -0,0 +1,52 @@
+// workflow.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package planning
+
+import (
+	"errors"
+
+	"github.com/bkojha74/task-management/models"
+)
+
+// Errors of status transitions that break the workflow rules
+var (
+	ErrUnknownStatus    = errors.New("unknown status")
+	ErrSameStatus       = errors.New("task already has this status")
+	ErrTransitionDenied = errors.New("transition not allowed by the workflow")
+)
+
+// transitions lists the statuses a task may move to from each status. Done tasks are reopened into In
+// Progress rather than back to Pending, so that their effort keeps being tracked.
+var transitions = map[string][]string{
+	models.TaskStatusPending:    {models.TaskStatusInProgress, models.TaskStatusDone},
+	models.TaskStatusInProgress: {models.TaskStatusPending, models.TaskStatusDone},
+	models.TaskStatusDone:       {models.TaskStatusInProgress},
+}
+
+// CheckTransition applies the workflow rules to moving a task from one status to another. Tasks stored
+// without a status count as Pending.
+//
+// Parameters:
+// - from: The current status of the task.
+// - to: The target status.
+//
+// Returns:
+// - error: ErrUnknownStatus, ErrSameStatus or ErrTransitionDenied if the move breaks a rule, nil otherwise.
+func CheckTransition(from, to string) error {
+	if _, ok := transitions[to]; !ok {
+		return ErrUnknownStatus
+	}
+	if from == "" {
+		from = models.TaskStatusPending
+	}
+	if from == to {
+		return ErrSameStatus
+	}
+	for _, next := range transitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return ErrTransitionDenied
+}
@@ -0,0 +1,25 @@
+// workflow_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package planning
+
+import (
+	"testing"
+
+	"github.com/bkojha74/task-management/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCheckTransition tests the workflow rules of status changes
+func TestCheckTransition(t *testing.T) {
+	assert.NoError(t, CheckTransition(models.TaskStatusPending, models.TaskStatusInProgress))
+	assert.NoError(t, CheckTransition(models.TaskStatusInProgress, models.TaskStatusDone))
+	assert.NoError(t, CheckTransition(models.TaskStatusDone, models.TaskStatusInProgress))
+	assert.NoError(t, CheckTransition("", models.TaskStatusDone), "tasks without a status are pending")
+
+	// Assert that done tasks are reopened into In Progress only
+	assert.ErrorIs(t, CheckTransition(models.TaskStatusDone, models.TaskStatusPending), ErrTransitionDenied)
+	assert.ErrorIs(t, CheckTransition(models.TaskStatusDone, models.TaskStatusDone), ErrSameStatus)
+	assert.ErrorIs(t, CheckTransition(models.TaskStatusPending, "Archived"), ErrUnknownStatus)
+}
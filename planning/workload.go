@@ -0,0 +1,152 @@
+// workload.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package planning
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+)
+
+// DefaultCapacityMinutes is the weekly capacity of an assignee when none is given, a 40-hour week.
+const DefaultCapacityMinutes = 40 * 60
+
+// ErrInvalidWeek is returned for weeks that are neither an ISO week nor a date.
+var ErrInvalidWeek = errors.New("invalid week")
+
+// AssigneeLoad is the open work of one assignee for a week.
+type AssigneeLoad struct {
+	Assignee         string  `json:"assignee"`          // Username, empty for unassigned tasks
+	OpenTasks        int     `json:"open_tasks"`        // All open tasks, whenever they are due
+	DueTasks         int     `json:"due_tasks"`         // Open tasks due within the week
+	OverdueTasks     int     `json:"overdue_tasks"`     // Open tasks due before the week, carried into it
+	UnscheduledTasks int     `json:"unscheduled_tasks"` // Open tasks without a due date
+	UnestimatedTasks int     `json:"unestimated_tasks"` // Due and overdue tasks without an estimate
+	EstimateMinutes  int     `json:"estimate_minutes"`  // Estimates of the due and overdue tasks
+	LoadPercent      float64 `json:"load_percent"`      // EstimateMinutes as a share of the capacity
+	Overloaded       bool    `json:"overloaded"`        // More work than capacity
+}
+
+// Workload is the open work per assignee for one week.
+type Workload struct {
+	Week            string         `json:"week"` // ISO week, e.g. 2024-W28
+	From            string         `json:"from"` // Monday, YYYY-MM-DD (UTC)
+	To              string         `json:"to"`   // Sunday, YYYY-MM-DD (UTC)
+	CapacityMinutes int            `json:"capacity_minutes"`
+	Assignees       []AssigneeLoad `json:"assignees"`            // Most loaded first
+	Unassigned      *AssigneeLoad  `json:"unassigned,omitempty"` // Open tasks nobody is allotted
+}
+
+// ParseWeek returns the Monday (UTC) starting a week given as an ISO week ("2024-W28") or as any day of it
+// ("2024-07-10"). An empty value is the current week.
+//
+// Parameters:
+// - value: The week.
+// - now: The current time.
+//
+// Returns:
+// - time.Time: The start of the week.
+// - error: ErrInvalidWeek if value is not a week.
+func ParseWeek(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return weekStart(now), nil
+	}
+	if day, err := time.Parse(time.DateOnly, value); err == nil {
+		return weekStart(day), nil
+	}
+
+	var year, week int
+	if _, err := fmt.Sscanf(value, "%4d-W%2d", &year, &week); err != nil || len(value) != len("2006-W01") {
+		return time.Time{}, ErrInvalidWeek
+	}
+	// Week 1 is the week containing January 4th
+	start := weekStart(time.Date(year, 1, 4, 0, 0, 0, 0, time.UTC)).AddDate(0, 0, 7*(week-1))
+	if y, w := start.ISOWeek(); y != year || w != week {
+		return time.Time{}, ErrInvalidWeek
+	}
+	return start, nil
+}
+
+// weekStart returns the Monday (UTC) starting the week of t.
+func weekStart(t time.Time) time.Time {
+	day := Day(t)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// ComputeWorkload sums the open tasks and their estimates per assignee for the week starting at start. The
+// work of a week is the tasks due within it plus the overdue tasks carried into it; tasks due later count
+// only as open tasks. Done tasks are ignored.
+//
+// Parameters:
+// - tasks: The tasks to aggregate.
+// - start: The Monday starting the week, see ParseWeek.
+// - capacityMinutes: The work one assignee can do in a week.
+//
+// Returns:
+// - Workload: The load of each assignee, most loaded first.
+func ComputeWorkload(tasks []models.Task, start time.Time, capacityMinutes int) Workload {
+	end := start.AddDate(0, 0, 7)
+	year, week := start.ISOWeek()
+	workload := Workload{
+		Week:            fmt.Sprintf("%04d-W%02d", year, week),
+		From:            start.Format(time.DateOnly),
+		To:              end.AddDate(0, 0, -1).Format(time.DateOnly),
+		CapacityMinutes: capacityMinutes,
+		Assignees:       []AssigneeLoad{},
+	}
+
+	loads := map[string]*AssigneeLoad{}
+	for _, task := range tasks {
+		if task.Status == models.TaskStatusDone {
+			continue
+		}
+		load, ok := loads[task.AllottedTo]
+		if !ok {
+			load = &AssigneeLoad{Assignee: task.AllottedTo}
+			loads[task.AllottedTo] = load
+		}
+
+		load.OpenTasks++
+		due := task.EndDate.Time()
+		switch {
+		case task.EndDate == 0:
+			load.UnscheduledTasks++
+			continue
+		case due.Before(start):
+			load.OverdueTasks++
+		case due.Before(end):
+			load.DueTasks++
+		default:
+			continue
+		}
+		load.EstimateMinutes += task.EstimateMinutes
+		if task.EstimateMinutes == 0 {
+			load.UnestimatedTasks++
+		}
+	}
+
+	for assignee, load := range loads {
+		if capacityMinutes > 0 {
+			load.LoadPercent = math.Round(float64(load.EstimateMinutes)*1000/float64(capacityMinutes)) / 10
+		}
+		load.Overloaded = load.EstimateMinutes > capacityMinutes
+		if assignee == "" {
+			workload.Unassigned = load
+			continue
+		}
+		workload.Assignees = append(workload.Assignees, *load)
+	}
+	sort.Slice(workload.Assignees, func(i, j int) bool {
+		a, b := workload.Assignees[i], workload.Assignees[j]
+		if a.EstimateMinutes != b.EstimateMinutes {
+			return a.EstimateMinutes > b.EstimateMinutes
+		}
+		return a.Assignee < b.Assignee
+	})
+	return workload
+}
@@ -0,0 +1,80 @@
+// workload_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package planning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseWeek tests ISO weeks, days and the current week
+func TestParseWeek(t *testing.T) {
+	monday := time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC)
+	for _, value := range []string{"2024-W28", "2024-07-08", "2024-07-14"} {
+		start, err := ParseWeek(value, time.Now())
+		require.NoError(t, err, value)
+		assert.Equal(t, monday, start, value)
+	}
+
+	// Week 1 of 2021 starts in 2021, week 1 of 2025 in 2024
+	start, err := ParseWeek("2021-W01", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC), start)
+	start, err = ParseWeek("2025-W01", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), start)
+
+	start, err = ParseWeek("", time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, monday, start)
+
+	for _, value := range []string{"2024-W54", "2023-W53", "2024-W0", "2024W28", "next week"} {
+		_, err := ParseWeek(value, time.Now())
+		assert.ErrorIs(t, err, ErrInvalidWeek, value)
+	}
+}
+
+// TestComputeWorkload tests that due and overdue estimates are summed per assignee against the capacity
+func TestComputeWorkload(t *testing.T) {
+	start := time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC)
+	task := func(assignee string, due time.Time, estimate int, status string) models.Task {
+		task := models.Task{AllottedTo: assignee, EstimateMinutes: estimate, Status: status}
+		if !due.IsZero() {
+			task.EndDate = models.NewTimestamp(due)
+		}
+		return task
+	}
+	tasks := []models.Task{
+		task("alice", start.AddDate(0, 0, 2), 1800, models.TaskStatusInProgress),
+		task("alice", start.AddDate(0, 0, -3), 900, models.TaskStatusPending),
+		task("alice", start.AddDate(0, 0, 10), 600, models.TaskStatusPending),
+		task("alice", start.AddDate(0, 0, 1), 0, models.TaskStatusPending),
+		task("bob", start.AddDate(0, 0, 6), 300, models.TaskStatusPending),
+		task("bob", start.AddDate(0, 0, 4), 1000, models.TaskStatusDone),
+		task("bob", time.Time{}, 120, models.TaskStatusPending),
+		task("", start.AddDate(0, 0, 3), 60, models.TaskStatusPending),
+	}
+
+	workload := ComputeWorkload(tasks, start, 2400)
+	assert.Equal(t, "2024-W28", workload.Week)
+	assert.Equal(t, "2024-07-08", workload.From)
+	assert.Equal(t, "2024-07-14", workload.To)
+
+	// Assert that the most loaded assignee comes first and is flagged
+	require.Len(t, workload.Assignees, 2)
+	assert.Equal(t, AssigneeLoad{
+		Assignee: "alice", OpenTasks: 4, DueTasks: 2, OverdueTasks: 1, UnestimatedTasks: 1,
+		EstimateMinutes: 2700, LoadPercent: 112.5, Overloaded: true,
+	}, workload.Assignees[0])
+	assert.Equal(t, AssigneeLoad{
+		Assignee: "bob", OpenTasks: 2, DueTasks: 1, UnscheduledTasks: 1, EstimateMinutes: 300, LoadPercent: 12.5,
+	}, workload.Assignees[1])
+	require.NotNil(t, workload.Unassigned)
+	assert.Equal(t, 60, workload.Unassigned.EstimateMinutes)
+}
@@ -0,0 +1,196 @@
+// quota.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Resources limited by quotas
+const (
+	ResourceTasks    = "tasks"
+	ResourceWebhooks = "webhooks"
+)
+
+// Limits are the most tasks and webhooks allowed; 0 means unlimited.
+type Limits struct {
+	Tasks    int `json:"tasks"`
+	Webhooks int `json:"webhooks"`
+}
+
+var (
+	// User are the limits of every user.
+	User Limits
+
+	// Workspace are the limits shared by the members of a workspace.
+	Workspace Limits
+
+	// WorkspaceLimits returns the limits of a workspace, e.g. those of its billing plan; when nil all
+	// workspaces have the Workspace limits.
+	WorkspaceLimits func(ctx context.Context, workspaceID primitive.ObjectID) (Limits, error)
+)
+
+// Consumption is the use of a resource against its limit.
+type Consumption struct {
+	Used  int64 `json:"used"`
+	Limit int64 `json:"limit"` // 0 means unlimited
+}
+
+// Exceeded reports whether one more unit would go over the limit.
+func (c Consumption) Exceeded() bool {
+	return c.Limit > 0 && c.Used >= c.Limit
+}
+
+// Scope is the consumption of a user or workspace.
+type Scope struct {
+	Tasks    Consumption `json:"tasks"`
+	Webhooks Consumption `json:"webhooks"`
+}
+
+// Usage is the consumption of a user and, for members, of their workspace.
+type Usage struct {
+	User      Scope  `json:"user"`
+	Workspace *Scope `json:"workspace,omitempty"`
+}
+
+// ExceededError is returned by Check when a quota is used up.
+type ExceededError struct {
+	Resource string `json:"resource"`
+	Scope    string `json:"scope"` // user or workspace
+	Used     int64  `json:"used"`
+	Limit    int64  `json:"limit"`
+}
+
+// Error implements the error interface.
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("%s quota of the %s exceeded: %d of %d used", e.Resource, e.Scope, e.Used, e.Limit)
+}
+
+// ForUser computes the consumption of a user and, if the user is a member of one, of their workspace.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - user: The user.
+//
+// Returns:
+// - Usage: The consumption and limits.
+// - error: An error if the tasks, webhooks or workspace members could not be read.
+func ForUser(ctx context.Context, user models.User) (Usage, error) {
+	members := []primitive.ObjectID{user.ID}
+	var usage Usage
+	scope, err := measure(ctx, members, User)
+	if err != nil {
+		return Usage{}, err
+	}
+	usage.User = scope
+
+	if user.WorkspaceID.IsZero() {
+		return usage, nil
+	}
+	users, err := repository.Users.FindByWorkspace(ctx, user.WorkspaceID)
+	if err != nil {
+		return Usage{}, err
+	}
+	members = members[:0]
+	for _, member := range users {
+		members = append(members, member.ID)
+	}
+	limits := Workspace
+	if WorkspaceLimits != nil {
+		if limits, err = WorkspaceLimits(ctx, user.WorkspaceID); err != nil {
+			return Usage{}, err
+		}
+	}
+	scope, err = measure(ctx, members, limits)
+	if err != nil {
+		return Usage{}, err
+	}
+	usage.Workspace = &scope
+	return usage, nil
+}
+
+// Check returns an *ExceededError if a user, or their workspace, may not add another unit of a resource.
+// Without any limit on the resource, and no WorkspaceLimits, it returns nil without reading the database.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - userID: The user about to add the resource.
+// - resource: ResourceTasks or ResourceWebhooks.
+//
+// Returns:
+// - error: An *ExceededError if a quota is used up, another error if the consumption could not be read.
+func Check(ctx context.Context, userID primitive.ObjectID, resource string) error {
+	if limit(User, resource) == 0 && limit(Workspace, resource) == 0 && WorkspaceLimits == nil {
+		return nil
+	}
+	user, err := repository.Users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	usage, err := ForUser(ctx, *user)
+	if err != nil {
+		return err
+	}
+
+	if used := pick(usage.User, resource); used.Exceeded() {
+		return &ExceededError{Resource: resource, Scope: "user", Used: used.Used, Limit: used.Limit}
+	}
+	if usage.Workspace != nil {
+		if used := pick(*usage.Workspace, resource); used.Exceeded() {
+			return &ExceededError{Resource: resource, Scope: "workspace", Used: used.Used, Limit: used.Limit}
+		}
+	}
+	return nil
+}
+
+// measure counts the tasks owned and webhooks created by a set of users.
+func measure(ctx context.Context, userIDs []primitive.ObjectID, limits Limits) (Scope, error) {
+	scope := Scope{
+		Tasks:    Consumption{Limit: int64(limits.Tasks)},
+		Webhooks: Consumption{Limit: int64(limits.Webhooks)},
+	}
+	for _, id := range userIDs {
+		count, err := repository.Tasks.Count(ctx, repository.TaskQuery{UserID: id})
+		if err != nil {
+			return Scope{}, err
+		}
+		scope.Tasks.Used += count
+	}
+
+	webhooks, err := repository.Webhooks.Find(ctx)
+	if err != nil {
+		return Scope{}, err
+	}
+	for _, webhook := range webhooks {
+		for _, id := range userIDs {
+			if webhook.CreatedBy == id {
+				scope.Webhooks.Used++
+				break
+			}
+		}
+	}
+	return scope, nil
+}
+
+// limit returns the limit of a resource.
+func limit(limits Limits, resource string) int {
+	if resource == ResourceWebhooks {
+		return limits.Webhooks
+	}
+	return limits.Tasks
+}
+
+// pick returns the consumption of a resource.
+func pick(scope Scope, resource string) Consumption {
+	if resource == ResourceWebhooks {
+		return scope.Webhooks
+	}
+	return scope.Tasks
+}
@@ -0,0 +1,51 @@
+// quota_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package quota
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestCheck tests that webhooks count against the quotas of their creator and workspace
+func TestCheck(t *testing.T) {
+	repository.UseMemory()
+	ctx := context.Background()
+	defer func() { User, Workspace = Limits{}, Limits{} }()
+
+	workspace := primitive.NewObjectID()
+	admin := models.User{Username: "admin", WorkspaceID: workspace}
+	colleague := models.User{Username: "colleague", WorkspaceID: workspace}
+	outsider := models.User{Username: "outsider"}
+	for _, user := range []*models.User{&admin, &colleague, &outsider} {
+		require.NoError(t, repository.Users.Create(ctx, user))
+	}
+	for _, creator := range []primitive.ObjectID{admin.ID, colleague.ID, outsider.ID} {
+		require.NoError(t, repository.Webhooks.Create(ctx, &models.Webhook{URL: "https://example.com/hook", CreatedBy: creator}))
+	}
+
+	// Without limits nothing is exceeded
+	assert.NoError(t, Check(ctx, admin.ID, ResourceWebhooks))
+
+	User = Limits{Webhooks: 2}
+	assert.NoError(t, Check(ctx, admin.ID, ResourceWebhooks))
+	assert.NoError(t, Check(ctx, admin.ID, ResourceTasks))
+
+	Workspace = Limits{Webhooks: 2}
+	err := Check(ctx, admin.ID, ResourceWebhooks)
+	assert.Equal(t, &ExceededError{Resource: ResourceWebhooks, Scope: "workspace", Used: 2, Limit: 2}, err)
+	assert.NoError(t, Check(ctx, outsider.ID, ResourceWebhooks))
+
+	usage, err := ForUser(ctx, outsider)
+	require.NoError(t, err)
+	assert.Equal(t, Consumption{Used: 1, Limit: 2}, usage.User.Webhooks)
+	assert.Nil(t, usage.Workspace)
+}
@@ -0,0 +1,101 @@
+// readmodel.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package readmodel
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/pagination"
+	"github.com/bkojha74/task-management/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SortFields are the list-view fields the summary list can be sorted by, in addition to _id.
+var SortFields = []string{"title", "status", "due", "updated_at"}
+
+// Sync writes the list-view document of a task. The assignee display name is resolved here once,
+// so that reads don't need a lookup. Comment and attachment counters are left untouched.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - task: The task as just written.
+//
+// Returns:
+// - error: An error object if the view could not be written.
+func Sync(ctx context.Context, task models.Task) error {
+	return repository.TaskViews.Upsert(ctx, models.TaskListView{
+		ID:           task.ID,
+		UserID:       task.UserID,
+		Title:        task.Title,
+		Status:       task.Status,
+		Priority:     task.Priority,
+		Due:          primitive.DateTime(task.EndDate),
+		AssigneeName: assigneeName(ctx, task.AllottedTo),
+		UpdatedAt:    primitive.NewDateTimeFromTime(time.Now()),
+	})
+}
+
+// Remove deletes the list-view document of a deleted task.
+func Remove(ctx context.Context, taskId primitive.ObjectID) error {
+	return repository.TaskViews.Delete(ctx, taskId)
+}
+
+// AdjustCounts increments (or decrements, with negative deltas) the comment and attachment counters of a task.
+func AdjustCounts(ctx context.Context, taskId primitive.ObjectID, comments, attachments int) error {
+	return repository.TaskViews.AdjustCounts(ctx, taskId, comments, attachments)
+}
+
+// List returns one page of a user's list-view documents, using the same keyset pagination as task lists.
+// A positive limit returns up to limit+1 documents so callers can tell whether another page exists.
+func List(ctx context.Context, userId primitive.ObjectID, sort pagination.Sort, after *pagination.Cursor, limit int) ([]models.TaskListView, error) {
+	return repository.TaskViews.Find(ctx, userId, sort, after, limit)
+}
+
+// Rebuild recreates the list-view documents of all tasks, e.g. after the view was introduced or lost.
+// It returns the number of tasks synced.
+func Rebuild(ctx context.Context) (int, error) {
+	tasks, err := repository.Tasks.Find(ctx, repository.TaskQuery{})
+	if err != nil {
+		return 0, err
+	}
+
+	for count, task := range tasks {
+		if err := Sync(ctx, task); err != nil {
+			return count, err
+		}
+	}
+	return len(tasks), nil
+}
+
+// SyncOrLog syncs the view of a task and logs failures instead of returning them, for use after
+// a successful task write where the view must not fail the request. A later Rebuild repairs the view.
+func SyncOrLog(ctx context.Context, task models.Task) {
+	if err := Sync(ctx, task); err != nil {
+		log.Printf("Error syncing list view of task %s: %v", task.ID.Hex(), err)
+	}
+}
+
+// RemoveOrLog removes the view of a deleted task and logs failures instead of returning them.
+func RemoveOrLog(ctx context.Context, taskId primitive.ObjectID) {
+	if err := Remove(ctx, taskId); err != nil {
+		log.Printf("Error removing list view of task %s: %v", taskId.Hex(), err)
+	}
+}
+
+// assigneeName resolves the display name of the user a task is allotted to, falling back to the username.
+func assigneeName(ctx context.Context, username string) string {
+	if username == "" {
+		return ""
+	}
+
+	user, err := repository.Users.FindByUsername(ctx, username)
+	if err != nil || user.DisplayName == "" {
+		return username
+	}
+	return user.DisplayName
+}
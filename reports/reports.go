@@ -0,0 +1,302 @@
+// reports.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package reports builds task reports for sharing with stakeholders: statistics of a set of tasks and a
+// table of them, rendered as PDF or CSV, and emails the reports users scheduled.
+package reports
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/focus"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/pdf"
+	"github.com/bkojha74/task-management/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxListed is the most tasks listed in the table of a report; the others are only counted.
+const MaxListed = 1000
+
+// Fields are the task fields a report needs, e.g. for a projection. Descriptions are left out: they may be
+// long or encrypted, and a report is an overview.
+var Fields = []string{"title", "status", "priority", "allotted_to", "end_time", "estimate_minutes", "overdue"}
+
+// Statistics are the counts of a set of tasks.
+type Statistics struct {
+	Total           int
+	ByStatus        map[string]int
+	ByPriority      map[string]int
+	Overdue         int // Open tasks past their end date
+	EstimateMinutes int // Estimated effort of the open tasks
+	FocusedMinutes  int // Time focused on the tasks in focus sessions, see AddFocusedTime
+}
+
+// Report is a titled set of tasks with their statistics.
+type Report struct {
+	Title       string
+	Subtitle    string        // e.g. the filters applied
+	GeneratedAt time.Time     // In the timezone dates are shown in
+	Tasks       []models.Task // Ordered by due date, tasks without one last
+	Statistics  Statistics
+
+	FocusedMinutes map[primitive.ObjectID]int // Time focused on each task, see AddFocusedTime
+}
+
+// Query returns the query for the tasks of a user selected by a filter.
+//
+// Parameters:
+// - userID: The owner of the tasks.
+// - filter: The filter.
+//
+// Returns:
+// - repository.TaskQuery: The query, loading only the Fields of a report.
+func Query(userID primitive.ObjectID, filter models.ReportFilter) repository.TaskQuery {
+	return repository.TaskQuery{
+		UserID:     userID,
+		ProjectID:  filter.ProjectID,
+		Statuses:   filter.Statuses,
+		AllottedTo: filter.AllottedTo,
+		Overdue:    filter.Overdue,
+		Fields:     Fields,
+	}
+}
+
+// Describe describes a filter for the subtitle of a report, e.g. "Status: Pending; Assignee: bob".
+//
+// Parameters:
+// - filter: The filter.
+//
+// Returns:
+// - string: The description, empty for no filter.
+func Describe(filter models.ReportFilter) string {
+	parts := []string{}
+	if len(filter.Statuses) > 0 {
+		parts = append(parts, "Status: "+strings.Join(filter.Statuses, ", "))
+	}
+	if filter.AllottedTo != "" {
+		parts = append(parts, "Assignee: "+filter.AllottedTo)
+	}
+	if filter.Overdue != nil {
+		parts = append(parts, fmt.Sprintf("Overdue: %t", *filter.Overdue))
+	}
+	if !filter.ProjectID.IsZero() {
+		parts = append(parts, "Project: "+filter.ProjectID.Hex())
+	}
+	return strings.Join(parts, "; ")
+}
+
+// New builds a report of tasks.
+//
+// Parameters:
+// - title: The title of the report.
+// - subtitle: A line below the title, e.g. the filters applied; may be empty.
+// - tasks: The tasks.
+// - now: The current time, in the timezone dates are shown in.
+//
+// Returns:
+// - Report: The report.
+func New(title, subtitle string, tasks []models.Task, now time.Time) Report {
+	ordered := append([]models.Task(nil), tasks...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i].EndDate, ordered[j].EndDate
+		if a == 0 || b == 0 {
+			return a != 0 && b == 0
+		}
+		return a < b
+	})
+	return Report{
+		Title:       title,
+		Subtitle:    subtitle,
+		GeneratedAt: now,
+		Tasks:       ordered,
+		Statistics:  Summarize(tasks, now),
+	}
+}
+
+// Summarize counts tasks by status and priority, and the open tasks that are overdue.
+//
+// Parameters:
+// - tasks: The tasks.
+// - now: The current time.
+//
+// Returns:
+// - Statistics: The counts.
+func Summarize(tasks []models.Task, now time.Time) Statistics {
+	stats := Statistics{Total: len(tasks), ByStatus: map[string]int{}, ByPriority: map[string]int{}}
+	for _, task := range tasks {
+		stats.ByStatus[task.Status]++
+		if task.Priority != "" {
+			stats.ByPriority[task.Priority]++
+		}
+		if task.Status == models.TaskStatusDone {
+			continue
+		}
+		stats.EstimateMinutes += task.EstimateMinutes
+		if task.Overdue || (task.EndDate != 0 && task.EndDate.Time().Before(now)) {
+			stats.Overdue++
+		}
+	}
+	return stats
+}
+
+// AddFocusedTime adds the time focused on the tasks of a report to it: the ended focus sessions of any
+// user on them, e.g. of their assignees, see package focus.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - report: The report.
+//
+// Returns:
+// - error: An error if the focus sessions could not be read.
+func AddFocusedTime(ctx context.Context, report *Report) error {
+	report.FocusedMinutes, report.Statistics.FocusedMinutes = map[primitive.ObjectID]int{}, 0
+	if len(report.Tasks) == 0 {
+		return nil
+	}
+	ids := make([]primitive.ObjectID, len(report.Tasks))
+	for i, task := range report.Tasks {
+		ids[i] = task.ID
+	}
+	sessions, err := repository.FocusSessions.Find(ctx, repository.FocusSessionQuery{TaskIDs: ids})
+	if err != nil {
+		return err
+	}
+	report.FocusedMinutes = focus.ByTask(sessions)
+	for _, minutes := range report.FocusedMinutes {
+		report.Statistics.FocusedMinutes += minutes
+	}
+	return nil
+}
+
+// PDF renders a report as a PDF document.
+//
+// Parameters:
+// - report: The report.
+//
+// Returns:
+// - []byte: The PDF file.
+// - error: An error if the document could not be written.
+func PDF(report Report) ([]byte, error) {
+	doc := pdf.New(report.Title)
+	if report.Subtitle != "" {
+		doc.Text(report.Subtitle)
+	}
+	doc.Text("Generated " + report.GeneratedAt.Format("2006-01-02 15:04 MST"))
+
+	stats := report.Statistics
+	doc.Heading("Summary")
+	doc.Text(fmt.Sprintf("%d tasks, %d overdue, %s of estimated open work.", stats.Total, stats.Overdue, hours(stats.EstimateMinutes)))
+	if stats.FocusedMinutes > 0 {
+		doc.Text(fmt.Sprintf("%s focused in focus sessions.", hours(stats.FocusedMinutes)))
+	}
+	doc.Text("By status: " + counts(stats.ByStatus, models.TaskStatuses))
+	if len(stats.ByPriority) > 0 {
+		doc.Text("By priority: " + counts(stats.ByPriority, []string{models.TaskPriorityHigh, models.TaskPriorityMedium, models.TaskPriorityLow}))
+	}
+
+	doc.Heading("Tasks")
+	if len(report.Tasks) == 0 {
+		doc.Text("No tasks match.")
+		return doc.Bytes()
+	}
+	listed := report.Tasks
+	if len(listed) > MaxListed {
+		listed = listed[:MaxListed]
+	}
+	location := report.GeneratedAt.Location()
+	rows := make([][]string, len(listed))
+	for i, task := range listed {
+		due := ""
+		if task.EndDate != 0 {
+			due = task.EndDate.Time().In(location).Format("2006-01-02 15:04")
+		}
+		rows[i] = []string{task.Title, task.Status, task.Priority, task.AllottedTo, due}
+	}
+	doc.Table([]pdf.Column{
+		{Title: "Title", Width: 0.40},
+		{Title: "Status", Width: 0.14},
+		{Title: "Priority", Width: 0.11},
+		{Title: "Assignee", Width: 0.15},
+		{Title: "Due", Width: 0.20},
+	}, rows)
+	if more := len(report.Tasks) - len(listed); more > 0 {
+		doc.Text(fmt.Sprintf("... and %d more tasks.", more))
+	}
+	return doc.Bytes()
+}
+
+// CSV renders the tasks of a report as CSV with a header row, all of them, with due dates in RFC3339 and
+// the minutes focused on each task.
+//
+// Parameters:
+// - report: The report.
+//
+// Returns:
+// - []byte: The CSV file.
+func CSV(report Report) []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"id", "title", "status", "priority", "allotted_to", "due", "estimate_minutes", "overdue", "focused_minutes"})
+	location := report.GeneratedAt.Location()
+	for _, task := range report.Tasks {
+		due := ""
+		if task.EndDate != 0 {
+			due = task.EndDate.Time().In(location).Format(time.RFC3339)
+		}
+		w.Write([]string{
+			task.ID.Hex(), csvSafe(task.Title), task.Status, task.Priority, csvSafe(task.AllottedTo), due,
+			fmt.Sprint(task.EstimateMinutes), fmt.Sprint(task.Overdue), fmt.Sprint(report.FocusedMinutes[task.ID]),
+		})
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+// csvSafe prefixes values spreadsheets would run as formulas with a quote.
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// counts formats counts as "a: 1, b: 2", in the given order first and then alphabetically.
+func counts(values map[string]int, order []string) string {
+	keys := []string{}
+	known := map[string]bool{}
+	for _, key := range order {
+		known[key] = true
+		if values[key] > 0 {
+			keys = append(keys, key)
+		}
+	}
+	others := []string{}
+	for key := range values {
+		if !known[key] {
+			others = append(others, key)
+		}
+	}
+	sort.Strings(others)
+
+	parts := []string{}
+	for _, key := range append(keys, others...) {
+		parts = append(parts, fmt.Sprintf("%s: %d", key, values[key]))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// hours formats minutes as hours, e.g. "2.5 h".
+func hours(minutes int) string {
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(minutes)/60), ".0") + " h"
+}
@@ -0,0 +1,72 @@
+// reports_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+
+	reader "github.com/ledongthuc/pdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNew tests the statistics of a report and that its tasks are ordered by due date
+func TestNew(t *testing.T) {
+	now := time.Date(2024, 7, 10, 12, 0, 0, 0, time.UTC)
+	tasks := []models.Task{
+		{Title: "Undated", Status: models.TaskStatusPending, Priority: models.TaskPriorityLow, EstimateMinutes: 30},
+		{Title: "Late", Status: models.TaskStatusInProgress, Priority: models.TaskPriorityHigh, EstimateMinutes: 60,
+			EndDate: models.NewTimestamp(now.Add(-time.Hour))},
+		{Title: "Finished late", Status: models.TaskStatusDone, EstimateMinutes: 90, EndDate: models.NewTimestamp(now.Add(-48 * time.Hour))},
+		{Title: "Soon", Status: models.TaskStatusPending, Priority: models.TaskPriorityHigh, EndDate: models.NewTimestamp(now.Add(time.Hour))},
+	}
+
+	report := New("Tasks", "", tasks, now)
+	assert.Equal(t, Statistics{
+		Total:           4,
+		ByStatus:        map[string]int{models.TaskStatusPending: 2, models.TaskStatusInProgress: 1, models.TaskStatusDone: 1},
+		ByPriority:      map[string]int{models.TaskPriorityHigh: 2, models.TaskPriorityLow: 1},
+		Overdue:         1,
+		EstimateMinutes: 90,
+	}, report.Statistics)
+
+	titles := []string{}
+	for _, task := range report.Tasks {
+		titles = append(titles, task.Title)
+	}
+	assert.Equal(t, []string{"Finished late", "Late", "Soon", "Undated"}, titles)
+	assert.Equal(t, "Pending: 2, In Progress: 1, Done: 1", counts(report.Statistics.ByStatus, models.TaskStatuses))
+	assert.Equal(t, "1.5 h", hours(90))
+}
+
+// TestPDF tests that a report renders its summary and lists at most MaxListed tasks
+func TestPDF(t *testing.T) {
+	tasks := make([]models.Task, MaxListed+5)
+	for i := range tasks {
+		tasks[i] = models.Task{Title: fmt.Sprintf("Task %d", i), Status: models.TaskStatusPending}
+	}
+	out, err := PDF(New("Project report", "Status: Pending", tasks, time.Now()))
+	require.NoError(t, err)
+
+	require.True(t, bytes.HasPrefix(out, []byte("%PDF-")))
+	r, err := reader.NewReader(bytes.NewReader(out), int64(len(out)))
+	require.NoError(t, err)
+	var text strings.Builder
+	for page := 1; page <= r.NumPage(); page++ {
+		content, err := r.Page(page).GetPlainText(nil)
+		require.NoError(t, err)
+		text.WriteString(content)
+	}
+	assert.Contains(t, text.String(), "Project report")
+	assert.Contains(t, text.String(), "1005 tasks, 0 overdue, 0 h of estimated open work.")
+	assert.Contains(t, text.String(), "Task 999")
+	assert.NotContains(t, text.String(), "Task 1000")
+	assert.Contains(t, text.String(), "... and 5 more tasks.")
+}
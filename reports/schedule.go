@@ -0,0 +1,252 @@
+// schedule.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/branding"
+	"github.com/bkojha74/task-management/cron"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/notifications"
+	"github.com/bkojha74/task-management/repository"
+)
+
+// Limits of report schedules
+const (
+	MaxRecipients = 10
+	MaxSchedules  = 20 // Per user
+	MinInterval   = time.Hour
+)
+
+// duePerRun is the most schedules SendDue runs at once; the others wait for the next run.
+const duePerRun = 100
+
+// Mailer sends HTML emails with attachments, e.g. a notifications.SMTP.
+type Mailer interface {
+	SendMailWithAttachments(ctx context.Context, sender, to, subject, html string, attachments []notifications.Attachment) error
+}
+
+// ValidateSchedule checks a report schedule: its name, format, cron expression, which must not fire more
+// often than MinInterval, and recipients.
+//
+// Parameters:
+// - schedule: The schedule to validate.
+//
+// Returns:
+// - error: A descriptive error if any field is invalid.
+func ValidateSchedule(schedule models.ReportSchedule) error {
+	if name := strings.TrimSpace(schedule.Name); name == "" || len(name) > 100 {
+		return errors.New("name must be 1 to 100 characters")
+	}
+	if schedule.Format != models.ReportFormatPDF && schedule.Format != models.ReportFormatCSV {
+		return errors.New("format must be pdf or csv")
+	}
+	parsed, err := cron.Parse(schedule.Schedule)
+	if err != nil {
+		return fmt.Errorf("invalid schedule: %v", err)
+	}
+	if interval := parsed.MinInterval(time.Now()); interval == 0 {
+		return errors.New("schedule never fires or fires only once")
+	} else if interval < MinInterval {
+		return errors.New("schedule must not fire more than once an hour")
+	}
+	if len(schedule.Recipients) > MaxRecipients {
+		return fmt.Errorf("at most %d recipients are allowed", MaxRecipients)
+	}
+	for _, recipient := range schedule.Recipients {
+		if address, err := mail.ParseAddress(recipient); err != nil || address.Address != recipient {
+			return fmt.Errorf("invalid recipient %q, use a plain email address", recipient)
+		}
+	}
+	return nil
+}
+
+// NextRun returns the first time after a time at which a schedule fires, in a timezone.
+//
+// Parameters:
+// - schedule: The schedule, with a valid cron expression.
+// - after: The time to search from.
+// - location: The timezone of the owner.
+//
+// Returns:
+// - models.Timestamp: The next run, or 0 if the schedule never fires again.
+func NextRun(schedule models.ReportSchedule, after time.Time, location *time.Location) models.Timestamp {
+	parsed, err := cron.Parse(schedule.Schedule)
+	if err != nil {
+		return 0
+	}
+	next := parsed.Next(after.In(location))
+	if next.IsZero() {
+		return 0
+	}
+	return models.NewTimestamp(next)
+}
+
+// Build builds the report of a schedule and renders it in the schedule's format.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - schedule: The schedule.
+// - now: The current time, in the timezone dates are shown in.
+//
+// Returns:
+// - Report: The report.
+// - notifications.Attachment: The rendered report.
+// - error: An error if the tasks or their focus sessions could not be read.
+func Build(ctx context.Context, schedule models.ReportSchedule, now time.Time) (Report, notifications.Attachment, error) {
+	tasks, err := repository.ReportTasks.Find(ctx, Query(schedule.UserID, schedule.Filter))
+	if err != nil {
+		return Report{}, notifications.Attachment{}, err
+	}
+	report := New(schedule.Name, Describe(schedule.Filter), tasks, now)
+	if err := AddFocusedTime(ctx, &report); err != nil {
+		return Report{}, notifications.Attachment{}, err
+	}
+
+	filename := fmt.Sprintf("%s-%s", fileName(schedule.Name), now.Format("2006-01-02"))
+	if schedule.Format == models.ReportFormatCSV {
+		return report, notifications.Attachment{Filename: filename + ".csv", ContentType: "text/csv", Data: CSV(report)}, nil
+	}
+	data, err := PDF(report)
+	if err != nil {
+		return Report{}, notifications.Attachment{}, err
+	}
+	return report, notifications.Attachment{Filename: filename + ".pdf", ContentType: "application/pdf", Data: data}, nil
+}
+
+// fileName turns a report name into a file name of letters, digits and dashes.
+func fileName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteByte('-')
+		}
+	}
+	if name := strings.Trim(b.String(), "-"); name != "" {
+		return name
+	}
+	return "report"
+}
+
+// body is the HTML of a scheduled report email inside the branded layout.
+var body = template.Must(template.New("report").Parse(`<p>Your scheduled report <strong>{{.Title}}</strong> is attached.</p>
+{{with .Subtitle}}<p style="color:#6b7280">{{.}}</p>{{end}}
+<p>{{.Statistics.Total}} tasks, {{.Statistics.Overdue}} overdue.</p>`))
+
+// Send emails the report of a schedule to its recipients, or its owner if it has none.
+//
+// Parameters:
+// - ctx: Context for the database operations and deliveries.
+// - mailer: The mail server.
+// - schedule: The schedule.
+// - owner: The owner of the schedule.
+// - now: The current time.
+//
+// Returns:
+// - error: An error if the report could not be built or sent to any recipient.
+func Send(ctx context.Context, mailer Mailer, schedule models.ReportSchedule, owner models.User, now time.Time) error {
+	recipients := schedule.Recipients
+	if len(recipients) == 0 {
+		if owner.Email == "" {
+			return errors.New("no recipients and no email address of the owner")
+		}
+		recipients = []string{owner.Email}
+	}
+
+	report, attachment, err := Build(ctx, schedule, now.In(location(owner)))
+	if err != nil {
+		return err
+	}
+	var html strings.Builder
+	if err := body.Execute(&html, report); err != nil {
+		return err
+	}
+	b := branding.ForUser(ctx, owner.ID)
+	page, err := branding.Render(b, schedule.Name, template.HTML(html.String()))
+	if err != nil {
+		return err
+	}
+
+	failed := []string{}
+	for _, recipient := range recipients {
+		if err := mailer.SendMailWithAttachments(ctx, b.SenderName, recipient, "Report: "+schedule.Name, page, []notifications.Attachment{attachment}); err != nil {
+			log.Printf("Could not send report schedule %s to %s: %v", schedule.ID.Hex(), recipient, err)
+			failed = append(failed, recipient)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("could not send to %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// SendDue sends the reports whose schedules are due and moves each schedule to its next run. A failed run
+// is recorded in the schedule's last_error and not retried before its next run, so that recipients who got
+// the report are not mailed again. Schedules of deactivated or deleted users are skipped.
+//
+// Parameters:
+// - ctx: Context for the database operations and deliveries.
+// - mailer: The mail server.
+// - now: The current time.
+//
+// Returns:
+// - int: The number of reports sent.
+// - error: An error if the schedules could not be read or updated.
+func SendDue(ctx context.Context, mailer Mailer, now time.Time) (int, error) {
+	schedules, err := repository.ReportSchedules.Due(ctx, now, duePerRun)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, schedule := range schedules {
+		owner, err := repository.Users.FindByID(ctx, schedule.UserID)
+		if err != nil && err != repository.ErrNotFound {
+			return sent, err
+		}
+
+		schedule.LastError = ""
+		switch {
+		case owner == nil || !owner.DeactivatedAt.IsZero() || !owner.DeleteAt.IsZero():
+			schedule.LastError = "owner is deactivated or deleted"
+		default:
+			if err := Send(ctx, mailer, schedule, *owner, now); err != nil {
+				schedule.LastError = err.Error()
+			} else {
+				sent++
+			}
+		}
+
+		loc := time.UTC
+		if owner != nil {
+			loc = location(*owner)
+		}
+		schedule.LastRunAt = models.NewTimestamp(now)
+		schedule.NextRunAt = NextRun(schedule, now, loc)
+		if err := repository.ReportSchedules.Update(ctx, &schedule); err != nil && err != repository.ErrNotFound {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// location returns the timezone of a user, UTC when none or an unknown one is set.
+func location(user models.User) *time.Location {
+	location, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
@@ -0,0 +1,116 @@
+// schedule_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package reports
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/notifications"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mailbox records the emails sent to it, refusing those to the addresses in fail.
+type mailbox struct {
+	to          []string
+	attachments []notifications.Attachment
+	fail        map[string]bool
+}
+
+func (m *mailbox) SendMailWithAttachments(ctx context.Context, sender, to, subject, html string, attachments []notifications.Attachment) error {
+	if m.fail[to] {
+		return errors.New("mailbox full")
+	}
+	m.to = append(m.to, to)
+	m.attachments = append(m.attachments, attachments...)
+	return nil
+}
+
+// TestValidateSchedule tests that invalid formats, schedules and recipients are rejected
+func TestValidateSchedule(t *testing.T) {
+	valid := models.ReportSchedule{Name: "Weekly", Format: models.ReportFormatPDF, Schedule: "0 9 * * mon"}
+	assert.NoError(t, ValidateSchedule(valid))
+
+	for name, change := range map[string]func(*models.ReportSchedule){
+		"no name":   func(s *models.ReportSchedule) { s.Name = " " },
+		"format":    func(s *models.ReportSchedule) { s.Format = "xlsx" },
+		"cron":      func(s *models.ReportSchedule) { s.Schedule = "every monday" },
+		"too often": func(s *models.ReportSchedule) { s.Schedule = "*/5 * * * *" },
+		"never":     func(s *models.ReportSchedule) { s.Schedule = "0 0 30 2 *" },
+		"recipient": func(s *models.ReportSchedule) { s.Recipients = []string{"Bob <bob@example.com>"} },
+		"many recipients": func(s *models.ReportSchedule) {
+			s.Recipients = strings.Split(strings.Repeat("a@example.com,", MaxRecipients+1), ",")[:MaxRecipients+1]
+		},
+	} {
+		schedule := valid
+		change(&schedule)
+		assert.Error(t, ValidateSchedule(schedule), name)
+	}
+}
+
+// TestSendDue tests that due reports are sent with their attachment and moved to their next run
+func TestSendDue(t *testing.T) {
+	repository.UseMemory()
+	ctx := context.Background()
+	now := time.Date(2024, 7, 8, 9, 0, 30, 0, time.UTC) // A Monday
+
+	owner := models.User{Username: "owner", Email: "owner@example.com"}
+	require.NoError(t, repository.Users.Create(ctx, &owner))
+	for _, task := range []models.Task{
+		{Title: "=SUM(A1)", Status: models.TaskStatusPending, AllottedTo: "owner"},
+		{Title: "Done already", Status: models.TaskStatusDone, AllottedTo: "owner"},
+	} {
+		task.UserID = owner.ID
+		require.NoError(t, repository.Tasks.Create(ctx, &task))
+	}
+
+	weekly := models.ReportSchedule{
+		UserID: owner.ID, Name: "Open work", Format: models.ReportFormatCSV, Schedule: "0 9 * * mon",
+		Filter: models.ReportFilter{Statuses: []string{models.TaskStatusPending}}, NextRunAt: models.NewTimestamp(now.Add(-30 * time.Second)),
+	}
+	failing := models.ReportSchedule{
+		UserID: owner.ID, Name: "Stakeholders", Format: models.ReportFormatPDF, Schedule: "@daily",
+		Recipients: []string{"boss@example.com", "full@example.com"}, NextRunAt: models.NewTimestamp(now.Add(-time.Hour)),
+	}
+	later := models.ReportSchedule{UserID: owner.ID, Name: "Later", Format: models.ReportFormatPDF, Schedule: "@daily", NextRunAt: models.NewTimestamp(now.Add(time.Hour))}
+	for _, schedule := range []*models.ReportSchedule{&weekly, &failing, &later} {
+		require.NoError(t, repository.ReportSchedules.Create(ctx, schedule))
+	}
+
+	mail := &mailbox{fail: map[string]bool{"full@example.com": true}}
+	sent, err := SendDue(ctx, mail, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, []string{"boss@example.com", "owner@example.com"}, mail.to)
+
+	require.Len(t, mail.attachments, 2)
+	assert.Equal(t, "stakeholders-2024-07-08.pdf", mail.attachments[0].Filename)
+	csv := mail.attachments[1]
+	assert.Equal(t, "open-work-2024-07-08.csv", csv.Filename)
+	assert.Contains(t, string(csv.Data), "'=SUM(A1)")
+	assert.NotContains(t, string(csv.Data), "Done already")
+
+	stored, err := repository.ReportSchedules.FindByID(ctx, owner.ID, weekly.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.LastError)
+	assert.Equal(t, now.Unix(), stored.LastRunAt.Time().Unix())
+	assert.True(t, stored.NextRunAt.Time().Equal(time.Date(2024, 7, 15, 9, 0, 0, 0, time.UTC)))
+
+	// A failed run is recorded and waits for the next run
+	stored, err = repository.ReportSchedules.FindByID(ctx, owner.ID, failing.ID)
+	require.NoError(t, err)
+	assert.Contains(t, stored.LastError, "full@example.com")
+	assert.True(t, stored.NextRunAt.Time().Equal(time.Date(2024, 7, 9, 0, 0, 0, 0, time.UTC)))
+
+	sent, err = SendDue(ctx, mail, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+}
@@ -0,0 +1,213 @@
+// encrypted.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package repository
+
+import (
+	"context"
+
+	"github.com/bkojha74/task-management/fieldcrypt"
+	"github.com/bkojha74/task-management/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UseFieldEncryption wraps Tasks, and ReportTasks, so that the descriptions of tasks in private projects, and their HTML, are
+// stored encrypted. It must be called after the repositories are set up; a nil keyring leaves them unchanged.
+//
+// Parameters:
+// - keyring: The keys to encrypt with, or nil when field encryption is off.
+func UseFieldEncryption(keyring *fieldcrypt.Keyring) {
+	if keyring == nil {
+		return
+	}
+	reportsShared := ReportTasks == Tasks
+	Tasks = &EncryptedTasks{TaskRepository: Tasks, Keyring: keyring}
+	if reportsShared {
+		ReportTasks = Tasks
+	} else if ReportTasks != nil {
+		ReportTasks = &EncryptedTasks{TaskRepository: ReportTasks, Keyring: keyring}
+	}
+}
+
+// EncryptedTasks is a TaskRepository that encrypts the description of tasks in private projects, and its
+// HTML, before they reach the wrapped repository and decrypts descriptions read from it, so that handlers
+// only ever see plaintext. Descriptions stored before encryption was enabled are read as they are.
+type EncryptedTasks struct {
+	TaskRepository
+	Keyring *fieldcrypt.Keyring
+}
+
+// Create stores a task, encrypting its description if its project is private.
+func (r *EncryptedTasks) Create(ctx context.Context, task *models.Task) error {
+	return r.write(ctx, task, func(stored *models.Task) error {
+		return r.TaskRepository.Create(ctx, stored)
+	})
+}
+
+// Update replaces a task, encrypting its description if its project is private.
+func (r *EncryptedTasks) Update(ctx context.Context, task *models.Task, expectedVersion int) error {
+	return r.write(ctx, task, func(stored *models.Task) error {
+		return r.TaskRepository.Update(ctx, stored, expectedVersion)
+	})
+}
+
+// Find returns the tasks matching the query with their descriptions decrypted.
+func (r *EncryptedTasks) Find(ctx context.Context, query TaskQuery) ([]models.Task, error) {
+	tasks, err := r.TaskRepository.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		if err := r.decrypt(&tasks[i]); err != nil {
+			return nil, err
+		}
+	}
+	return tasks, nil
+}
+
+// FindEntries returns the tasks matching the query, with their assignees and comment counts, with their
+// descriptions decrypted.
+func (r *EncryptedTasks) FindEntries(ctx context.Context, query TaskQuery) ([]models.TaskListEntry, error) {
+	entries, err := r.TaskRepository.FindEntries(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if err := r.decrypt(&entries[i].Task); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// FindByID returns a task with its description decrypted.
+func (r *EncryptedTasks) FindByID(ctx context.Context, userID, taskID primitive.ObjectID) (*models.Task, error) {
+	task, err := r.TaskRepository.FindByID(ctx, userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.decrypt(task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// FindTrashed returns the trashed tasks of an owner with their descriptions decrypted.
+func (r *EncryptedTasks) FindTrashed(ctx context.Context, userID primitive.ObjectID) ([]models.TrashedTask, error) {
+	trashed, err := r.TaskRepository.FindTrashed(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range trashed {
+		if err := r.decrypt(&trashed[i].Task); err != nil {
+			return nil, err
+		}
+	}
+	return trashed, nil
+}
+
+// Restore moves a trashed task back to the tasks and returns it with its description decrypted.
+func (r *EncryptedTasks) Restore(ctx context.Context, userID, taskID primitive.ObjectID) (*models.Task, error) {
+	task, err := r.TaskRepository.Restore(ctx, userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.decrypt(task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// Rotate re-encrypts the stored descriptions that are not as they should be: encrypted with a key other
+// than the primary one, not encrypted although the project is private, or encrypted although it is not.
+// Tasks changed concurrently are skipped; running Rotate again picks them up.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+//
+// Returns:
+// - int: The number of tasks rewritten.
+// - error: An error if the tasks could not be read, decrypted or written.
+func (r *EncryptedTasks) Rotate(ctx context.Context) (int, error) {
+	tasks, err := r.TaskRepository.Find(ctx, TaskQuery{})
+	if err != nil {
+		return 0, err
+	}
+
+	rewritten := 0
+	for _, task := range tasks {
+		private, err := r.private(ctx, &task)
+		if err != nil {
+			return rewritten, err
+		}
+		stored := task.Description
+		stale := private && stored != "" && !r.Keyring.IsPrimary(stored)
+		if !stale && (private || !fieldcrypt.IsEncrypted(stored)) {
+			continue
+		}
+
+		if err := r.decrypt(&task); err != nil {
+			return rewritten, err
+		}
+		err = r.Update(ctx, &task, task.Version)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return rewritten, err
+		}
+		rewritten++
+	}
+	return rewritten, nil
+}
+
+// write stores a copy of the task with the description encrypted as needed, and copies back what the
+// wrapped repository set, such as the ID and timestamps, keeping the plaintext description.
+func (r *EncryptedTasks) write(ctx context.Context, task *models.Task, store func(stored *models.Task) error) error {
+	private, err := r.private(ctx, task)
+	if err != nil {
+		return err
+	}
+
+	stored := *task
+	if private {
+		if stored.Description, err = r.Keyring.Encrypt(task.Description); err != nil {
+			return err
+		}
+		if stored.DescriptionHTML, err = r.Keyring.Encrypt(task.DescriptionHTML); err != nil {
+			return err
+		}
+	}
+	if err := store(&stored); err != nil {
+		return err
+	}
+	stored.Description, stored.DescriptionHTML = task.Description, task.DescriptionHTML
+	*task = stored
+	return nil
+}
+
+// decrypt decrypts the description of a task and its HTML.
+func (r *EncryptedTasks) decrypt(task *models.Task) error {
+	var err error
+	if task.Description, err = r.Keyring.Decrypt(task.Description); err != nil {
+		return err
+	}
+	task.DescriptionHTML, err = r.Keyring.Decrypt(task.DescriptionHTML)
+	return err
+}
+
+// private reports whether a task belongs to a private project of its owner.
+func (r *EncryptedTasks) private(ctx context.Context, task *models.Task) (bool, error) {
+	if task.ProjectID.IsZero() {
+		return false, nil
+	}
+	project, err := Projects.FindByID(ctx, task.UserID, task.ProjectID)
+	if err == ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return project.Private, nil
+}
@@ -0,0 +1,2141 @@
+// memory.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package repository
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/bkojha74/task-management/audit"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/pagination"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UseMemory installs fresh in-memory repositories, e.g. for unit tests that run without MongoDB.
+func UseMemory() {
+	Users = NewMemoryUsers()
+	Tasks = NewMemoryTasks()
+	TaskViews = NewMemoryTaskViews()
+	Projects = NewMemoryProjects()
+	Sessions = NewMemorySessions()
+	SignIns = NewMemorySignIns()
+	Invitations = NewMemoryInvitations()
+	Comments = NewMemoryComments()
+	InviteCodes = NewMemoryInviteCodes()
+	Maintenance = NewMemoryMaintenance()
+	Leases = NewMemoryLeases()
+	Nonces = NewMemoryNonces()
+	AuditLog = NewMemoryAuditLog()
+	Outbox = NewMemoryOutbox()
+	Webhooks = NewMemoryWebhooks()
+	WebhookDeliveries = NewMemoryWebhookDeliveries()
+	Tombstones = NewMemoryTombstones()
+	ReportSchedules = NewMemoryReportSchedules()
+	Subscriptions = NewMemorySubscriptions()
+	FocusSessions = NewMemoryFocusSessions()
+	Workspaces = NewMemoryWorkspaces()
+	Transactions = MemoryTransactions{}
+	ReportTasks = Tasks
+}
+
+// MemoryUsers is an in-memory implementation of UserRepository.
+type MemoryUsers struct {
+	mu    sync.RWMutex
+	users map[primitive.ObjectID]models.User
+}
+
+// NewMemoryUsers creates an empty in-memory user repository.
+func NewMemoryUsers() *MemoryUsers {
+	return &MemoryUsers{users: map[primitive.ObjectID]models.User{}}
+}
+
+// Create inserts a user and sets its ID.
+func (r *MemoryUsers) Create(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.users {
+		if existing.Username == user.Username || (user.Subject != "" && existing.Subject == user.Subject) {
+			return ErrDuplicate
+		}
+	}
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	user.CreatedAt = models.NewTimestamp(time.Now())
+	user.UpdatedAt = user.CreatedAt
+	r.users[user.ID] = *user
+	return nil
+}
+
+// FindByID returns the user with the given ID.
+func (r *MemoryUsers) FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+// FindByUsername returns the user with the given username.
+func (r *MemoryUsers) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// FindBySubject returns the user linked to a directory subject.
+func (r *MemoryUsers) FindBySubject(ctx context.Context, subject string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if subject != "" && user.Subject == subject {
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// FindByTelegramChat returns the user who linked a Telegram chat.
+func (r *MemoryUsers) FindByTelegramChat(ctx context.Context, chatID int64) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.TelegramChat != 0 && user.TelegramChat == chatID {
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Update replaces a stored user.
+func (r *MemoryUsers) Update(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID]; !ok {
+		return ErrNotFound
+	}
+	for _, existing := range r.users {
+		if (existing.Username == user.Username || (user.Subject != "" && existing.Subject == user.Subject)) && existing.ID != user.ID {
+			return ErrDuplicate
+		}
+	}
+	user.UpdatedAt = models.NewTimestamp(time.Now())
+	r.users[user.ID] = *user
+	return nil
+}
+
+// FindByWorkspace returns the members of a workspace.
+func (r *MemoryUsers) FindByWorkspace(ctx context.Context, workspaceID primitive.ObjectID) ([]models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := []models.User{}
+	for _, user := range r.users {
+		if user.WorkspaceID == workspaceID {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+// FindDeletionDue returns the users due for deletion.
+func (r *MemoryUsers) FindDeletionDue(ctx context.Context, before time.Time) ([]models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := []models.User{}
+	for _, user := range r.users {
+		if !user.DeleteAt.IsZero() && user.DeleteAt.Time().Before(before) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+// Delete deletes a user.
+func (r *MemoryUsers) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
+
+// List returns a page of the users in the order of their IDs.
+func (r *MemoryUsers) List(ctx context.Context, offset, limit int) ([]models.User, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]models.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID.Hex() < users[j].ID.Hex() })
+	total := int64(len(users))
+	if offset >= len(users) {
+		return []models.User{}, total, nil
+	}
+	users = users[offset:]
+	if limit < len(users) {
+		users = users[:limit]
+	}
+	return users, total, nil
+}
+
+// MemoryTasks is an in-memory implementation of TaskRepository.
+type MemoryTasks struct {
+	mu      sync.RWMutex
+	tasks   map[primitive.ObjectID]models.Task
+	trashed map[primitive.ObjectID]models.TrashedTask
+}
+
+// NewMemoryTasks creates an empty in-memory task repository.
+func NewMemoryTasks() *MemoryTasks {
+	return &MemoryTasks{tasks: map[primitive.ObjectID]models.Task{}, trashed: map[primitive.ObjectID]models.TrashedTask{}}
+}
+
+// Create inserts a task.
+func (r *MemoryTasks) Create(ctx context.Context, task *models.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if task.ID.IsZero() {
+		task.ID = primitive.NewObjectID()
+	}
+	if _, ok := r.tasks[task.ID]; ok {
+		return ErrDuplicate
+	}
+	task.CreatedAt = models.NewTimestamp(time.Now())
+	task.UpdatedAt = task.CreatedAt
+	task.ManualRank = task.ManualKey()
+	r.tasks[task.ID] = *task
+	return nil
+}
+
+// Find returns the tasks matching the query.
+func (r *MemoryTasks) Find(ctx context.Context, query TaskQuery) ([]models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sortBy := query.Sort
+	if sortBy.Field == "" {
+		sortBy.Field = "_id"
+	}
+	tasks := page(r.matching(query), sortBy, query.After, query.Limit)
+	if len(query.Fields) > 0 {
+		keep := append([]string{"_id", sortBy.Field}, query.Fields...)
+		for i := range tasks {
+			projected, err := project(tasks[i], keep)
+			if err != nil {
+				return nil, err
+			}
+			tasks[i] = projected
+		}
+	}
+	return tasks, nil
+}
+
+// FindEntries returns the tasks matching the query with their assignee summaries and comment counts,
+// looked up in the Users and Comments repositories once per assignee and once for all comments.
+func (r *MemoryTasks) FindEntries(ctx context.Context, query TaskQuery) ([]models.TaskListEntry, error) {
+	assignee, comments := entryJoins(query.Fields)
+	if assignee && len(query.Fields) > 0 {
+		// The assignee is joined on allotted_to
+		query.Fields = append([]string{"allotted_to"}, query.Fields...)
+	}
+	tasks, err := r.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.TaskListEntry, len(tasks))
+	ids := make([]primitive.ObjectID, len(tasks))
+	for i, task := range tasks {
+		entries[i].Task = task
+		ids[i] = task.ID
+	}
+	if assignee {
+		summaries := map[string]*models.UserSummary{}
+		for i, task := range tasks {
+			if task.AllottedTo == "" {
+				continue
+			}
+			summary, ok := summaries[task.AllottedTo]
+			if !ok {
+				user, err := Users.FindByUsername(ctx, task.AllottedTo)
+				if err != nil && err != ErrNotFound {
+					return nil, err
+				}
+				if user != nil {
+					summary = &models.UserSummary{ID: user.ID, Username: user.Username, DisplayName: user.DisplayName}
+				}
+				summaries[task.AllottedTo] = summary
+			}
+			entries[i].Assignee = summary
+		}
+	}
+	if comments {
+		counts, err := Comments.CountByTasks(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for i := range entries {
+			entries[i].CommentCount = counts[entries[i].ID]
+		}
+	}
+	return entries, nil
+}
+
+// project returns a copy of task with only the given BSON fields set, like a MongoDB projection.
+func project(task models.Task, fields []string) (models.Task, error) {
+	data, err := bson.Marshal(task)
+	if err != nil {
+		return task, err
+	}
+	var full bson.D
+	if err := bson.Unmarshal(data, &full); err != nil {
+		return task, err
+	}
+
+	kept := bson.D{}
+	for _, element := range full {
+		if contains(fields, element.Key) {
+			kept = append(kept, element)
+		}
+	}
+	data, err = bson.Marshal(kept)
+	if err != nil {
+		return task, err
+	}
+	var projected models.Task
+	err = bson.Unmarshal(data, &projected)
+	return projected, err
+}
+
+// FindByID returns a task of the given owner.
+func (r *MemoryTasks) FindByID(ctx context.Context, userID, taskID primitive.ObjectID) (*models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.tasks[taskID]
+	if !ok || task.UserID != userID {
+		return nil, ErrNotFound
+	}
+	return &task, nil
+}
+
+// Update replaces a task if its stored version is expectedVersion.
+func (r *MemoryTasks) Update(ctx context.Context, task *models.Task, expectedVersion int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.tasks[task.ID]
+	if !ok || stored.UserID != task.UserID || stored.Version != expectedVersion {
+		return ErrNotFound
+	}
+	task.CreatedAt = stored.CreatedAt
+	task.UpdatedAt = models.NewTimestamp(time.Now())
+	task.ManualRank = task.ManualKey()
+	r.tasks[task.ID] = *task
+	return nil
+}
+
+// Delete deletes a task of the given owner.
+func (r *MemoryTasks) Delete(ctx context.Context, userID, taskID primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.tasks[taskID]
+	if !ok || task.UserID != userID {
+		return ErrNotFound
+	}
+	delete(r.tasks, taskID)
+	return nil
+}
+
+// Count returns the number of tasks matching the query.
+func (r *MemoryTasks) Count(ctx context.Context, query TaskQuery) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return int64(len(r.matching(query))), nil
+}
+
+// DeleteMany deletes the tasks matching the query.
+func (r *MemoryTasks) DeleteMany(ctx context.Context, query TaskQuery) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tasks := r.matching(query)
+	for _, task := range tasks {
+		delete(r.tasks, task.ID)
+	}
+	return int64(len(tasks)), nil
+}
+
+// Trash moves a task to the trash.
+func (r *MemoryTasks) Trash(ctx context.Context, userID, taskID primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.tasks[taskID]
+	if !ok || task.UserID != userID {
+		return ErrNotFound
+	}
+	delete(r.tasks, taskID)
+	r.trashed[taskID] = models.TrashedTask{Task: task, DeletedAt: models.NewTimestamp(time.Now())}
+	return nil
+}
+
+// FindTrashed returns the trashed tasks of an owner, the most recently deleted first.
+func (r *MemoryTasks) FindTrashed(ctx context.Context, userID primitive.ObjectID) ([]models.TrashedTask, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	trashed := []models.TrashedTask{}
+	for _, task := range r.trashed {
+		if task.UserID == userID {
+			trashed = append(trashed, task)
+		}
+	}
+	sort.Slice(trashed, func(i, j int) bool {
+		if trashed[i].DeletedAt != trashed[j].DeletedAt {
+			return trashed[i].DeletedAt > trashed[j].DeletedAt
+		}
+		return bytes.Compare(trashed[i].ID[:], trashed[j].ID[:]) > 0
+	})
+	return trashed, nil
+}
+
+// Restore moves a trashed task back to the tasks.
+func (r *MemoryTasks) Restore(ctx context.Context, userID, taskID primitive.ObjectID) (*models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trashed, ok := r.trashed[taskID]
+	if !ok || trashed.UserID != userID {
+		return nil, ErrNotFound
+	}
+	if _, ok := r.tasks[taskID]; ok {
+		return nil, ErrDuplicate
+	}
+	task := trashed.Task
+	task.UpdatedAt = models.NewTimestamp(time.Now())
+	delete(r.trashed, taskID)
+	r.tasks[taskID] = task
+	return &task, nil
+}
+
+// CountTrashed returns the number of tasks trashed before a time.
+func (r *MemoryTasks) CountTrashed(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, task := range r.trashed {
+		if task.DeletedAt.Time().Before(before) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// PurgeTrash deletes the tasks trashed before a time.
+func (r *MemoryTasks) PurgeTrash(ctx context.Context, userID primitive.ObjectID, before time.Time) ([]primitive.ObjectID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := []primitive.ObjectID{}
+	for id, task := range r.trashed {
+		if (userID.IsZero() || task.UserID == userID) && task.DeletedAt.Time().Before(before) {
+			delete(r.trashed, id)
+			purged = append(purged, id)
+		}
+	}
+	return purged, nil
+}
+
+// matching returns the tasks matching the filter fields of the query, in no particular order.
+func (r *MemoryTasks) matching(query TaskQuery) []models.Task {
+	tasks := []models.Task{}
+	for _, task := range r.tasks {
+		if (!query.UserID.IsZero() && task.UserID != query.UserID) ||
+			(!query.ProjectID.IsZero() && task.ProjectID != query.ProjectID) ||
+			(query.AllottedTo != "" && task.AllottedTo != query.AllottedTo) ||
+			(len(query.Statuses) > 0 && !contains(query.Statuses, task.Status)) ||
+			(len(query.Statuses) == 0 && query.ExcludeStatus != "" && task.Status == query.ExcludeStatus) ||
+			(query.ExcludeVisibility != "" && task.Visibility == query.ExcludeVisibility) ||
+			(query.Text != "" && !containsWords(task.Title+" "+task.Description+" "+task.AllottedTo, query.Text)) ||
+			(!query.ExcludeID.IsZero() && task.ID == query.ExcludeID) ||
+			(len(query.IDs) > 0 && !containsID(query.IDs, task.ID)) ||
+			(!query.DueBefore.IsZero() && !task.EndDate.Time().Before(query.DueBefore)) ||
+			(query.HasDueDate && task.EndDate <= 0) ||
+			!inRange(task.CreatedAt, query.CreatedAfter, query.CreatedBefore) ||
+			!inRange(task.UpdatedAt, query.UpdatedAfter, query.UpdatedBefore) ||
+			(!query.CompletedAfter.IsZero() && !task.CompletedAt.Time().After(query.CompletedAfter)) ||
+			(!query.CompletedBefore.IsZero() && (task.CompletedAt == 0 || !task.CompletedAt.Time().Before(query.CompletedBefore))) ||
+			(query.Archived != nil && (task.ArchivedAt != 0) != *query.Archived) ||
+			(query.Overdue != nil && task.Overdue != *query.Overdue) ||
+			((query.HasGitHubIssue || query.GitHubRepo != "") && task.GitHubIssue == nil) ||
+			(query.GitHubRepo != "" && task.GitHubIssue.Repo != query.GitHubRepo) ||
+			(query.GitHubIssue != 0 && task.GitHubIssue.Number != query.GitHubIssue) ||
+			(query.CalDAVName != "" && (task.CalDAV == nil || task.CalDAV.Name != query.CalDAVName)) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// containsWords reports whether text contains every word of search, ignoring case, like a MongoDB text
+// search without stemming.
+func containsWords(text, search string) bool {
+	have := map[string]bool{}
+	for _, word := range words(text) {
+		have[word] = true
+	}
+	for _, word := range words(search) {
+		if !have[word] {
+			return false
+		}
+	}
+	return true
+}
+
+// words returns the lower-case words of a text.
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// containsID reports whether ids contains id.
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, item := range ids {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
+
+// inRange reports whether stamp is at or after from and before to; zero bounds don't restrict it.
+func inRange(stamp models.Timestamp, from, to time.Time) bool {
+	return (from.IsZero() || !stamp.Time().Before(from)) && (to.IsZero() || stamp.Time().Before(to))
+}
+
+// MemoryTaskViews is an in-memory implementation of TaskViewRepository.
+type MemoryTaskViews struct {
+	mu    sync.RWMutex
+	views map[primitive.ObjectID]models.TaskListView
+}
+
+// NewMemoryTaskViews creates an empty in-memory task view repository.
+func NewMemoryTaskViews() *MemoryTaskViews {
+	return &MemoryTaskViews{views: map[primitive.ObjectID]models.TaskListView{}}
+}
+
+// Upsert writes a view, keeping the stored counts.
+func (r *MemoryTaskViews) Upsert(ctx context.Context, view models.TaskListView) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.views[view.ID]; ok {
+		view.CommentCount = stored.CommentCount
+		view.AttachmentCount = stored.AttachmentCount
+	}
+	r.views[view.ID] = view
+	return nil
+}
+
+// Delete removes the view of a task.
+func (r *MemoryTaskViews) Delete(ctx context.Context, taskID primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.views, taskID)
+	return nil
+}
+
+// AdjustCounts adds the deltas to the counts of a view.
+func (r *MemoryTaskViews) AdjustCounts(ctx context.Context, taskID primitive.ObjectID, comments, attachments int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if view, ok := r.views[taskID]; ok {
+		view.CommentCount += comments
+		view.AttachmentCount += attachments
+		r.views[taskID] = view
+	}
+	return nil
+}
+
+// Find returns one page of an owner's views.
+func (r *MemoryTaskViews) Find(ctx context.Context, userID primitive.ObjectID, sortBy pagination.Sort, after *pagination.Cursor, limit int) ([]models.TaskListView, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	views := []models.TaskListView{}
+	for _, view := range r.views {
+		if view.UserID == userID {
+			views = append(views, view)
+		}
+	}
+	return page(views, sortBy, after, limit), nil
+}
+
+// MemoryProjects is an in-memory implementation of ProjectRepository.
+type MemoryProjects struct {
+	mu       sync.RWMutex
+	projects map[primitive.ObjectID]models.Project
+}
+
+// NewMemoryProjects creates an empty in-memory project repository.
+func NewMemoryProjects() *MemoryProjects {
+	return &MemoryProjects{projects: map[primitive.ObjectID]models.Project{}}
+}
+
+// Create inserts a project and sets its ID.
+func (r *MemoryProjects) Create(ctx context.Context, project *models.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if project.ID.IsZero() {
+		project.ID = primitive.NewObjectID()
+	}
+	if _, ok := r.projects[project.ID]; ok {
+		return ErrDuplicate
+	}
+	r.projects[project.ID] = *project
+	return nil
+}
+
+// FindByID returns a project of the given owner.
+func (r *MemoryProjects) FindByID(ctx context.Context, ownerID, projectID primitive.ObjectID) (*models.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	project, ok := r.projects[projectID]
+	if !ok || project.OwnerID != ownerID {
+		return nil, ErrNotFound
+	}
+	return &project, nil
+}
+
+// Find returns the projects of an owner.
+func (r *MemoryProjects) Find(ctx context.Context, ownerID primitive.ObjectID) ([]models.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	projects := []models.Project{}
+	for _, project := range r.projects {
+		if project.OwnerID == ownerID {
+			projects = append(projects, project)
+		}
+	}
+	return page(projects, pagination.Sort{Field: "_id"}, nil, 0), nil
+}
+
+// DeleteMany deletes the projects of an owner.
+func (r *MemoryProjects) DeleteMany(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := int64(0)
+	for id, project := range r.projects {
+		if project.OwnerID == ownerID {
+			delete(r.projects, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// SetAssignment sets or removes the assignment rule of a project of the given owner.
+func (r *MemoryProjects) SetAssignment(ctx context.Context, ownerID, projectID primitive.ObjectID, rule *models.AssignmentRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project, ok := r.projects[projectID]
+	if !ok || project.OwnerID != ownerID {
+		return ErrNotFound
+	}
+	if rule != nil {
+		copied := *rule
+		copied.Members = append([]string(nil), rule.Members...)
+		rule = &copied
+	}
+	project.Assignment = rule
+	r.projects[projectID] = project
+	return nil
+}
+
+// NextAssignment takes a round-robin turn of a project's assignment rule.
+func (r *MemoryProjects) NextAssignment(ctx context.Context, projectID primitive.ObjectID) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project, ok := r.projects[projectID]
+	if !ok || project.Assignment == nil {
+		return 0, ErrNotFound
+	}
+	rule := *project.Assignment
+	turns := rule.Next
+	rule.Next++
+	project.Assignment = &rule
+	r.projects[projectID] = project
+	return turns, nil
+}
+
+// ReturnAssignment gives back the last round-robin turn of a project's assignment rule.
+func (r *MemoryProjects) ReturnAssignment(ctx context.Context, projectID primitive.ObjectID, turn int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project, ok := r.projects[projectID]
+	if !ok || project.Assignment == nil || project.Assignment.Next != turn+1 {
+		return nil
+	}
+	rule := *project.Assignment
+	rule.Next = turn
+	project.Assignment = &rule
+	r.projects[projectID] = project
+	return nil
+}
+
+// MemorySessions is an in-memory implementation of SessionRepository.
+type MemorySessions struct {
+	mu       sync.RWMutex
+	sessions map[primitive.ObjectID]models.Session
+}
+
+// NewMemorySessions creates an empty in-memory session repository.
+func NewMemorySessions() *MemorySessions {
+	return &MemorySessions{sessions: map[primitive.ObjectID]models.Session{}}
+}
+
+// Create inserts a session and sets its ID.
+func (r *MemorySessions) Create(ctx context.Context, session *models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	if _, ok := r.sessions[session.ID]; ok {
+		return ErrDuplicate
+	}
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+// FindByID returns a session.
+func (r *MemorySessions) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &session, nil
+}
+
+// Find returns the active sessions of a user.
+func (r *MemorySessions) Find(ctx context.Context, userID primitive.ObjectID, now time.Time) ([]models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sessions := []models.Session{}
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt.IsZero() && session.ExpiresAt.Time().After(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	return page(sessions, pagination.Sort{Field: "_id", Descending: true}, nil, 0), nil
+}
+
+// Revoke marks a session as revoked.
+func (r *MemorySessions) Revoke(ctx context.Context, userID, id primitive.ObjectID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok || session.UserID != userID || !session.RevokedAt.IsZero() {
+		return ErrNotFound
+	}
+	session.RevokedAt = models.NewTimestamp(at)
+	r.sessions[id] = session
+	return nil
+}
+
+// Refresh rotates the refresh token of a session.
+func (r *MemorySessions) Refresh(ctx context.Context, id primitive.ObjectID, tokenHash, newHash string, expiresAt, now time.Time) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok || session.RefreshTokenHash == "" || session.RefreshTokenHash != tokenHash || !session.RevokedAt.IsZero() || !session.ExpiresAt.Time().After(now) {
+		return nil, ErrNotFound
+	}
+	session.RefreshTokenHash, session.PreviousRefreshHash = newHash, tokenHash
+	session.RefreshedAt, session.ExpiresAt = models.NewTimestamp(now), models.NewTimestamp(expiresAt)
+	r.sessions[id] = session
+	return &session, nil
+}
+
+// DeleteMany deletes the sessions of a user.
+func (r *MemorySessions) DeleteMany(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := int64(0)
+	for id, session := range r.sessions {
+		if session.UserID == userID {
+			delete(r.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// MemorySignIns is an in-memory implementation of SignInRepository.
+type MemorySignIns struct {
+	mu      sync.RWMutex
+	signIns []models.SignIn
+}
+
+// NewMemorySignIns creates an empty in-memory sign-in repository.
+func NewMemorySignIns() *MemorySignIns {
+	return &MemorySignIns{}
+}
+
+// Create inserts a sign-in and sets its ID.
+func (r *MemorySignIns) Create(ctx context.Context, signIn *models.SignIn) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if signIn.ID.IsZero() {
+		signIn.ID = primitive.NewObjectID()
+	}
+	r.signIns = append(r.signIns, *signIn)
+	return nil
+}
+
+// Find returns the latest sign-ins of a user.
+func (r *MemorySignIns) Find(ctx context.Context, userID primitive.ObjectID, limit int) ([]models.SignIn, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	signIns := []models.SignIn{}
+	for i := len(r.signIns) - 1; i >= 0 && len(signIns) < limit; i-- {
+		if r.signIns[i].UserID == userID {
+			signIns = append(signIns, r.signIns[i])
+		}
+	}
+	return signIns, nil
+}
+
+// Exists reports whether a sign-in matches the query.
+func (r *MemorySignIns) Exists(ctx context.Context, query SignInQuery) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, signIn := range r.signIns {
+		if signIn.UserID == query.UserID && (query.Device == "" || signIn.Device == query.Device) && (query.Network == "" || signIn.Network == query.Network) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteMany deletes the sign-ins of a user.
+func (r *MemorySignIns) DeleteMany(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.signIns[:0]
+	for _, signIn := range r.signIns {
+		if signIn.UserID != userID {
+			kept = append(kept, signIn)
+		}
+	}
+	deleted := int64(len(r.signIns) - len(kept))
+	r.signIns = kept
+	return deleted, nil
+}
+
+// MemoryInvitations is an in-memory implementation of InvitationRepository.
+type MemoryInvitations struct {
+	mu          sync.RWMutex
+	invitations map[primitive.ObjectID]models.Invitation
+}
+
+// NewMemoryInvitations creates an empty in-memory invitation repository.
+func NewMemoryInvitations() *MemoryInvitations {
+	return &MemoryInvitations{invitations: map[primitive.ObjectID]models.Invitation{}}
+}
+
+// Create inserts an invitation and sets its ID.
+func (r *MemoryInvitations) Create(ctx context.Context, invitation *models.Invitation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if invitation.ID.IsZero() {
+		invitation.ID = primitive.NewObjectID()
+	}
+	for _, existing := range r.invitations {
+		if existing.ID == invitation.ID || existing.TokenHash == invitation.TokenHash {
+			return ErrDuplicate
+		}
+	}
+	r.invitations[invitation.ID] = *invitation
+	return nil
+}
+
+// FindByID returns an invitation.
+func (r *MemoryInvitations) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Invitation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	invitation, ok := r.invitations[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &invitation, nil
+}
+
+// FindByTokenHash returns the invitation with the given token hash.
+func (r *MemoryInvitations) FindByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, invitation := range r.invitations {
+		if invitation.TokenHash == tokenHash {
+			return &invitation, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Find returns the invitations to a project.
+func (r *MemoryInvitations) Find(ctx context.Context, projectID primitive.ObjectID) ([]models.Invitation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	invitations := []models.Invitation{}
+	for _, invitation := range r.invitations {
+		if invitation.ProjectID == projectID {
+			invitations = append(invitations, invitation)
+		}
+	}
+	return page(invitations, pagination.Sort{Field: "_id"}, nil, 0), nil
+}
+
+// Accept marks a pending invitation as accepted.
+func (r *MemoryInvitations) Accept(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invitation, ok := r.invitations[id]
+	if !ok || !invitation.AcceptedAt.IsZero() || !invitation.RevokedAt.IsZero() || !invitation.ExpiresAt.Time().After(at) {
+		return ErrNotFound
+	}
+	invitation.AcceptedAt = models.NewTimestamp(at)
+	r.invitations[id] = invitation
+	return nil
+}
+
+// Revoke marks an invitation as revoked.
+func (r *MemoryInvitations) Revoke(ctx context.Context, projectID, id primitive.ObjectID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invitation, ok := r.invitations[id]
+	if !ok || invitation.ProjectID != projectID || !invitation.RevokedAt.IsZero() {
+		return ErrNotFound
+	}
+	invitation.RevokedAt = models.NewTimestamp(at)
+	r.invitations[id] = invitation
+	return nil
+}
+
+// DeleteMany deletes the invitations to the projects of an owner.
+func (r *MemoryInvitations) DeleteMany(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := int64(0)
+	for id, invitation := range r.invitations {
+		if invitation.OwnerID == ownerID {
+			delete(r.invitations, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// MemoryInviteCodes is an in-memory implementation of InviteCodeRepository.
+type MemoryInviteCodes struct {
+	mu    sync.Mutex
+	codes map[primitive.ObjectID]models.InviteCode
+}
+
+// NewMemoryInviteCodes creates an empty in-memory invite code repository.
+func NewMemoryInviteCodes() *MemoryInviteCodes {
+	return &MemoryInviteCodes{codes: map[primitive.ObjectID]models.InviteCode{}}
+}
+
+// Create inserts an invite code and sets its ID.
+func (r *MemoryInviteCodes) Create(ctx context.Context, code *models.InviteCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if code.ID.IsZero() {
+		code.ID = primitive.NewObjectID()
+	}
+	for _, stored := range r.codes {
+		if stored.ID == code.ID || stored.CodeHash == code.CodeHash {
+			return ErrDuplicate
+		}
+	}
+	r.codes[code.ID] = *code
+	return nil
+}
+
+// Find returns all invite codes.
+func (r *MemoryInviteCodes) Find(ctx context.Context) ([]models.InviteCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	codes := make([]models.InviteCode, 0, len(r.codes))
+	for _, code := range r.codes {
+		codes = append(codes, code)
+	}
+	return page(codes, pagination.Sort{Field: "_id"}, nil, 0), nil
+}
+
+// Redeem counts a use of a valid invite code.
+func (r *MemoryInviteCodes) Redeem(ctx context.Context, codeHash string, at time.Time) (*models.InviteCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, code := range r.codes {
+		if code.CodeHash != codeHash {
+			continue
+		}
+		if code.Uses >= code.MaxUses || !code.RevokedAt.IsZero() || !code.ExpiresAt.Time().After(at) {
+			return nil, ErrNotFound
+		}
+		code.Uses++
+		r.codes[id] = code
+		return &code, nil
+	}
+	return nil, ErrNotFound
+}
+
+// Release takes back a use of an invite code.
+func (r *MemoryInviteCodes) Release(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, ok := r.codes[id]
+	if !ok || code.Uses == 0 {
+		return ErrNotFound
+	}
+	code.Uses--
+	r.codes[id] = code
+	return nil
+}
+
+// Revoke marks an invite code as revoked.
+func (r *MemoryInviteCodes) Revoke(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, ok := r.codes[id]
+	if !ok || !code.RevokedAt.IsZero() {
+		return ErrNotFound
+	}
+	code.RevokedAt = models.NewTimestamp(at)
+	r.codes[id] = code
+	return nil
+}
+
+// MemoryComments is an in-memory implementation of CommentRepository.
+type MemoryComments struct {
+	mu       sync.RWMutex
+	comments map[primitive.ObjectID]models.Comment
+}
+
+// NewMemoryComments creates an empty in-memory comment repository.
+func NewMemoryComments() *MemoryComments {
+	return &MemoryComments{comments: map[primitive.ObjectID]models.Comment{}}
+}
+
+// Create inserts a comment and sets its ID.
+func (r *MemoryComments) Create(ctx context.Context, comment *models.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if comment.ID.IsZero() {
+		comment.ID = primitive.NewObjectID()
+	}
+	if _, ok := r.comments[comment.ID]; ok {
+		return ErrDuplicate
+	}
+	r.comments[comment.ID] = *comment
+	return nil
+}
+
+// Find returns the comments on a task.
+func (r *MemoryComments) Find(ctx context.Context, taskID primitive.ObjectID) ([]models.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comments := []models.Comment{}
+	for _, comment := range r.comments {
+		if comment.TaskID == taskID {
+			comments = append(comments, comment)
+		}
+	}
+	return page(comments, pagination.Sort{Field: "_id"}, nil, 0), nil
+}
+
+// CountByTasks returns the number of comments on each of the tasks.
+func (r *MemoryComments) CountByTasks(ctx context.Context, taskIDs []primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := make(map[primitive.ObjectID]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		wanted[id] = true
+	}
+	counts := map[primitive.ObjectID]int{}
+	for _, comment := range r.comments {
+		if wanted[comment.TaskID] {
+			counts[comment.TaskID]++
+		}
+	}
+	return counts, nil
+}
+
+// SetGitHubComment records the GitHub issue comment a comment was mirrored to.
+func (r *MemoryComments) SetGitHubComment(ctx context.Context, id primitive.ObjectID, githubCommentID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comment, ok := r.comments[id]
+	if !ok {
+		return ErrNotFound
+	}
+	comment.GitHubCommentID = githubCommentID
+	r.comments[id] = comment
+	return nil
+}
+
+// DeleteByTasks deletes the comments on the tasks.
+func (r *MemoryComments) DeleteByTasks(ctx context.Context, taskIDs []primitive.ObjectID) (int64, error) {
+	return r.delete(func(comment models.Comment) bool { return containsID(taskIDs, comment.TaskID) })
+}
+
+// DeleteMany deletes the comments on the tasks of an owner.
+func (r *MemoryComments) DeleteMany(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	return r.delete(func(comment models.Comment) bool { return comment.OwnerID == ownerID })
+}
+
+// delete deletes the comments matching a condition.
+func (r *MemoryComments) delete(matches func(comment models.Comment) bool) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := int64(0)
+	for id, comment := range r.comments {
+		if matches(comment) {
+			delete(r.comments, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// MemoryMaintenance is an in-memory implementation of MaintenanceRepository.
+type MemoryMaintenance struct {
+	mu          sync.RWMutex
+	maintenance models.Maintenance
+}
+
+// NewMemoryMaintenance creates an in-memory maintenance store with maintenance off.
+func NewMemoryMaintenance() *MemoryMaintenance {
+	return &MemoryMaintenance{maintenance: models.Maintenance{Mode: models.MaintenanceOff}}
+}
+
+// Get returns the maintenance mode.
+func (r *MemoryMaintenance) Get(ctx context.Context) (models.Maintenance, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.maintenance, nil
+}
+
+// Set replaces the maintenance mode.
+func (r *MemoryMaintenance) Set(ctx context.Context, maintenance models.Maintenance) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.maintenance = maintenance
+	return nil
+}
+
+// MemoryLeases is an in-memory implementation of LeaseRepository, for a single instance.
+type MemoryLeases struct {
+	mu     sync.Mutex
+	leases map[string]memoryLease
+}
+
+// memoryLease is the holder of a lease and when it expires.
+type memoryLease struct {
+	holder    string
+	expiresAt time.Time
+}
+
+// NewMemoryLeases creates an empty in-memory lease store.
+func NewMemoryLeases() *MemoryLeases {
+	return &MemoryLeases{leases: map[string]memoryLease{}}
+}
+
+// Acquire takes or extends a lease unless another holder has it.
+func (r *MemoryLeases) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if lease, ok := r.leases[name]; ok && lease.holder != holder && lease.expiresAt.After(now) {
+		return false, nil
+	}
+	r.leases[name] = memoryLease{holder: holder, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+// Release gives up a lease of holder.
+func (r *MemoryLeases) Release(ctx context.Context, name, holder string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if lease, ok := r.leases[name]; ok && lease.holder == holder {
+		delete(r.leases, name)
+	}
+	return nil
+}
+
+// MemoryNonces is an in-memory implementation of NonceRepository, for a single instance.
+type MemoryNonces struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time // Expiry by value
+}
+
+// NewMemoryNonces creates an empty in-memory nonce store.
+func NewMemoryNonces() *MemoryNonces {
+	return &MemoryNonces{nonces: map[string]time.Time{}}
+}
+
+// Use records a value as used unless it already is. Expired values are dropped on the way.
+func (r *MemoryNonces) Use(ctx context.Context, value string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for nonce, expiry := range r.nonces {
+		if !expiry.After(now) {
+			delete(r.nonces, nonce)
+		}
+	}
+	if _, ok := r.nonces[value]; ok {
+		return ErrDuplicate
+	}
+	r.nonces[value] = expiresAt
+	return nil
+}
+
+// MemoryAuditLog is an in-memory implementation of AuditLogRepository.
+type MemoryAuditLog struct {
+	mu      sync.RWMutex
+	entries []audit.Entry
+}
+
+// NewMemoryAuditLog creates an empty in-memory audit log.
+func NewMemoryAuditLog() *MemoryAuditLog {
+	return &MemoryAuditLog{}
+}
+
+// Create inserts an entry and sets its ID.
+func (r *MemoryAuditLog) Create(ctx context.Context, entry *audit.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.ID = primitive.NewObjectID()
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+// Update replaces a stored entry.
+func (r *MemoryAuditLog) Update(ctx context.Context, entry *audit.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.entries {
+		if r.entries[i].ID == entry.ID {
+			r.entries[i] = *entry
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// FindByUser returns the entries of a user, newest first.
+func (r *MemoryAuditLog) FindByUser(ctx context.Context, userID string) ([]audit.Entry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := []audit.Entry{}
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		if r.entries[i].UserID == userID {
+			entries = append(entries, r.entries[i])
+		}
+	}
+	return entries, nil
+}
+
+// MemoryOutbox is an in-memory implementation of OutboxRepository.
+type MemoryOutbox struct {
+	mu       sync.Mutex
+	messages map[primitive.ObjectID]models.OutboxMessage
+}
+
+// NewMemoryOutbox creates an empty in-memory outbox.
+func NewMemoryOutbox() *MemoryOutbox {
+	return &MemoryOutbox{messages: map[primitive.ObjectID]models.OutboxMessage{}}
+}
+
+// Add stores messages.
+func (r *MemoryOutbox) Add(ctx context.Context, messages ...models.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, message := range messages {
+		r.messages[message.ID] = message
+	}
+	return nil
+}
+
+// Due returns the messages due at now, oldest first.
+func (r *MemoryOutbox) Due(ctx context.Context, now time.Time, limit int) ([]models.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	due := []models.OutboxMessage{}
+	for _, message := range r.messages {
+		if !message.NextAttemptAt.Time().After(now) {
+			due = append(due, message)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID.Hex() < due[j].ID.Hex() })
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+// Retry records a failed attempt.
+func (r *MemoryOutbox) Retry(ctx context.Context, id primitive.ObjectID, attempts int, next time.Time, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message, ok := r.messages[id]
+	if !ok {
+		return ErrNotFound
+	}
+	message.Attempts = attempts
+	message.NextAttemptAt = primitive.NewDateTimeFromTime(next)
+	message.LastError = lastError
+	r.messages[id] = message
+	return nil
+}
+
+// Delete removes a message.
+func (r *MemoryOutbox) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.messages, id)
+	return nil
+}
+
+// MemoryWebhooks is an in-memory implementation of WebhookRepository.
+type MemoryWebhooks struct {
+	mu       sync.RWMutex
+	webhooks map[primitive.ObjectID]models.Webhook
+}
+
+// NewMemoryWebhooks creates an empty in-memory webhook repository.
+func NewMemoryWebhooks() *MemoryWebhooks {
+	return &MemoryWebhooks{webhooks: map[primitive.ObjectID]models.Webhook{}}
+}
+
+// Create inserts a webhook and sets its ID.
+func (r *MemoryWebhooks) Create(ctx context.Context, webhook *models.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if webhook.ID.IsZero() {
+		webhook.ID = primitive.NewObjectID()
+	}
+	if _, ok := r.webhooks[webhook.ID]; ok {
+		return ErrDuplicate
+	}
+	r.webhooks[webhook.ID] = *webhook
+	return nil
+}
+
+// Find returns all webhooks.
+func (r *MemoryWebhooks) Find(ctx context.Context) ([]models.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhooks := make([]models.Webhook, 0, len(r.webhooks))
+	for _, webhook := range r.webhooks {
+		webhooks = append(webhooks, webhook)
+	}
+	return page(webhooks, pagination.Sort{Field: "_id"}, nil, 0), nil
+}
+
+// FindByID returns a webhook by ID.
+func (r *MemoryWebhooks) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &webhook, nil
+}
+
+// Delete deletes a webhook.
+func (r *MemoryWebhooks) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.webhooks, id)
+	return nil
+}
+
+// MemoryWebhookDeliveries is an in-memory implementation of WebhookDeliveryRepository.
+type MemoryWebhookDeliveries struct {
+	mu         sync.RWMutex
+	deliveries map[primitive.ObjectID]models.WebhookDelivery
+}
+
+// NewMemoryWebhookDeliveries creates an empty in-memory webhook delivery repository.
+func NewMemoryWebhookDeliveries() *MemoryWebhookDeliveries {
+	return &MemoryWebhookDeliveries{deliveries: map[primitive.ObjectID]models.WebhookDelivery{}}
+}
+
+// Create inserts a delivery and sets its ID.
+func (r *MemoryWebhookDeliveries) Create(ctx context.Context, delivery *models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if delivery.ID.IsZero() {
+		delivery.ID = primitive.NewObjectID()
+	}
+	if _, ok := r.deliveries[delivery.ID]; ok {
+		return ErrDuplicate
+	}
+	r.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+// Find returns the latest deliveries to a webhook.
+func (r *MemoryWebhookDeliveries) Find(ctx context.Context, webhookID primitive.ObjectID, limit int) ([]models.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deliveries := []models.WebhookDelivery{}
+	for _, delivery := range r.deliveries {
+		if delivery.WebhookID == webhookID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return bytes.Compare(deliveries[i].ID[:], deliveries[j].ID[:]) > 0
+	})
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// FindByID returns a delivery to a webhook by ID.
+func (r *MemoryWebhookDeliveries) FindByID(ctx context.Context, webhookID, id primitive.ObjectID) (*models.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	delivery, ok := r.deliveries[id]
+	if !ok || delivery.WebhookID != webhookID {
+		return nil, ErrNotFound
+	}
+	return &delivery, nil
+}
+
+// AddAttempt records an attempt of a delivery.
+func (r *MemoryWebhookDeliveries) AddAttempt(ctx context.Context, id primitive.ObjectID, attempt models.WebhookAttempt, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return ErrNotFound
+	}
+	// Copy the attempts, so that deliveries returned earlier don't change
+	delivery.Attempts = append(append([]models.WebhookAttempt{}, delivery.Attempts...), attempt)
+	delivery.Status = status
+	r.deliveries[id] = delivery
+	return nil
+}
+
+// DeleteMany deletes the deliveries to a webhook.
+func (r *MemoryWebhookDeliveries) DeleteMany(ctx context.Context, webhookID primitive.ObjectID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, delivery := range r.deliveries {
+		if delivery.WebhookID == webhookID {
+			delete(r.deliveries, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// MemoryReportSchedules is an in-memory implementation of ReportScheduleRepository.
+type MemoryReportSchedules struct {
+	mu        sync.RWMutex
+	schedules map[primitive.ObjectID]models.ReportSchedule
+}
+
+// NewMemoryReportSchedules creates an empty in-memory report schedule repository.
+func NewMemoryReportSchedules() *MemoryReportSchedules {
+	return &MemoryReportSchedules{schedules: map[primitive.ObjectID]models.ReportSchedule{}}
+}
+
+// Create inserts a schedule and sets its ID and timestamps.
+func (r *MemoryReportSchedules) Create(ctx context.Context, schedule *models.ReportSchedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if schedule.ID.IsZero() {
+		schedule.ID = primitive.NewObjectID()
+	}
+	if _, ok := r.schedules[schedule.ID]; ok {
+		return ErrDuplicate
+	}
+	schedule.CreatedAt = models.NewTimestamp(time.Now())
+	schedule.UpdatedAt = schedule.CreatedAt
+	r.schedules[schedule.ID] = *schedule
+	return nil
+}
+
+// Find returns the schedules of a user.
+func (r *MemoryReportSchedules) Find(ctx context.Context, userID primitive.ObjectID) ([]models.ReportSchedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedules := []models.ReportSchedule{}
+	for _, schedule := range r.schedules {
+		if schedule.UserID == userID {
+			schedules = append(schedules, schedule)
+		}
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		return bytes.Compare(schedules[i].ID[:], schedules[j].ID[:]) < 0
+	})
+	return schedules, nil
+}
+
+// FindByID returns a schedule of a user by ID.
+func (r *MemoryReportSchedules) FindByID(ctx context.Context, userID, id primitive.ObjectID) (*models.ReportSchedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedule, ok := r.schedules[id]
+	if !ok || schedule.UserID != userID {
+		return nil, ErrNotFound
+	}
+	return &schedule, nil
+}
+
+// Due returns the schedules whose next run is due.
+func (r *MemoryReportSchedules) Due(ctx context.Context, now time.Time, limit int) ([]models.ReportSchedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	due := []models.ReportSchedule{}
+	for _, schedule := range r.schedules {
+		if schedule.NextRunAt != 0 && !schedule.NextRunAt.Time().After(now) {
+			due = append(due, schedule)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextRunAt < due[j].NextRunAt })
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+// Update replaces a schedule.
+func (r *MemoryReportSchedules) Update(ctx context.Context, schedule *models.ReportSchedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.schedules[schedule.ID]
+	if !ok || existing.UserID != schedule.UserID {
+		return ErrNotFound
+	}
+	schedule.CreatedAt = existing.CreatedAt
+	schedule.UpdatedAt = models.NewTimestamp(time.Now())
+	r.schedules[schedule.ID] = *schedule
+	return nil
+}
+
+// Delete deletes a schedule of a user.
+func (r *MemoryReportSchedules) Delete(ctx context.Context, userID, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if schedule, ok := r.schedules[id]; !ok || schedule.UserID != userID {
+		return ErrNotFound
+	}
+	delete(r.schedules, id)
+	return nil
+}
+
+// DeleteMany deletes the schedules of a user.
+func (r *MemoryReportSchedules) DeleteMany(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, schedule := range r.schedules {
+		if schedule.UserID == userID {
+			delete(r.schedules, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// MemoryTombstones is an in-memory implementation of TombstoneRepository.
+type MemoryTombstones struct {
+	mu         sync.RWMutex
+	tombstones map[primitive.ObjectID]models.Tombstone
+}
+
+// NewMemoryTombstones creates an empty in-memory tombstone repository.
+func NewMemoryTombstones() *MemoryTombstones {
+	return &MemoryTombstones{tombstones: map[primitive.ObjectID]models.Tombstone{}}
+}
+
+// Create inserts a tombstone and sets its ID and deletion time.
+func (r *MemoryTombstones) Create(ctx context.Context, tombstone *models.Tombstone) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if tombstone.ID.IsZero() {
+		tombstone.ID = primitive.NewObjectID()
+	}
+	if _, ok := r.tombstones[tombstone.ID]; ok {
+		return ErrDuplicate
+	}
+	tombstone.DeletedAt = models.NewTimestamp(time.Now())
+	r.tombstones[tombstone.ID] = *tombstone
+	return nil
+}
+
+// Find returns the tombstones of a user's tasks after the cursor.
+func (r *MemoryTombstones) Find(ctx context.Context, userID primitive.ObjectID, after *pagination.Cursor, limit int) ([]models.Tombstone, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tombstones := []models.Tombstone{}
+	for _, tombstone := range r.tombstones {
+		if tombstone.UserID == userID {
+			tombstones = append(tombstones, tombstone)
+		}
+	}
+	return page(tombstones, TombstoneSort, after, limit), nil
+}
+
+// DeleteBefore deletes the tombstones of tasks deleted before a time.
+func (r *MemoryTombstones) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, tombstone := range r.tombstones {
+		if tombstone.DeletedAt.Time().Before(before) {
+			delete(r.tombstones, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// DeleteByTask deletes the tombstones of a task.
+func (r *MemoryTombstones) DeleteByTask(ctx context.Context, taskID primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, tombstone := range r.tombstones {
+		if tombstone.TaskID == taskID {
+			delete(r.tombstones, id)
+		}
+	}
+	return nil
+}
+
+// MemoryTransactions is a Transactor for the in-memory repositories. They cannot roll back, so fn runs
+// without a transaction.
+type MemoryTransactions struct{}
+
+// InTransaction runs fn.
+func (MemoryTransactions) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// page orders documents like pagination.FindOptions, keeps those after the cursor like pagination.Filter,
+// and applies the limit plus one extra document. Sort keys are read from the BSON form of the documents,
+// so any model can be paginated the same way as in MongoDB.
+func page[T any](docs []T, sortBy pagination.Sort, after *pagination.Cursor, limit int) []T {
+	type keyed struct {
+		doc   T
+		value interface{}
+		id    primitive.ObjectID
+	}
+
+	items := make([]keyed, 0, len(docs))
+	for _, doc := range docs {
+		item := keyed{doc: doc}
+		if raw, err := bson.Marshal(doc); err == nil {
+			_ = bson.Raw(raw).Lookup("_id").Unmarshal(&item.id)
+			if sortBy.Field != "_id" {
+				if value, err := bson.Raw(raw).LookupErr(sortBy.Field); err == nil {
+					_ = value.Unmarshal(&item.value)
+				}
+			}
+		}
+		items = append(items, item)
+	}
+
+	// compare orders two items ascending by sort key, then _id
+	compare := func(value interface{}, id primitive.ObjectID, other keyed) int {
+		if c := compareValues(value, other.value); c != 0 {
+			return c
+		}
+		return bytes.Compare(id[:], other.id[:])
+	}
+	sort.Slice(items, func(i, j int) bool {
+		c := compare(items[i].value, items[i].id, items[j])
+		if sortBy.Descending {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	result := []T{}
+	for _, item := range items {
+		if after != nil {
+			var afterValue interface{}
+			if sortBy.Field != "_id" {
+				afterValue = after.Value
+			}
+			c := compare(item.value, item.id, keyed{value: afterValue, id: after.ID})
+			if (!sortBy.Descending && c <= 0) || (sortBy.Descending && c >= 0) {
+				continue
+			}
+		}
+		result = append(result, item.doc)
+		if limit > 0 && len(result) > limit {
+			break
+		}
+	}
+	return result
+}
+
+// compareValues compares two decoded BSON values in MongoDB's cross-type order:
+// null < numbers < strings < ObjectIDs < booleans < dates.
+func compareValues(a, b interface{}) int {
+	rankA, rankB := typeRank(a), typeRank(b)
+	if rankA != rankB {
+		return rankA - rankB
+	}
+
+	switch a := a.(type) {
+	case string:
+		return compareOrdered(a, b.(string))
+	case primitive.ObjectID:
+		id := b.(primitive.ObjectID)
+		return bytes.Compare(a[:], id[:])
+	case bool:
+		if a == b.(bool) {
+			return 0
+		}
+		if !a {
+			return -1
+		}
+		return 1
+	case primitive.DateTime:
+		return compareOrdered(a, b.(primitive.DateTime))
+	}
+	if rankA == 2 {
+		return compareOrdered(toFloat(a), toFloat(b))
+	}
+	return 0
+}
+
+// typeRank returns the position of a value's type in MongoDB's comparison order.
+func typeRank(value interface{}) int {
+	switch value.(type) {
+	case nil, primitive.Null, primitive.Undefined:
+		return 1
+	case int32, int64, float64, int:
+		return 2
+	case string:
+		return 3
+	case primitive.ObjectID:
+		return 7
+	case bool:
+		return 8
+	case primitive.DateTime:
+		return 9
+	}
+	return 10
+}
+
+// toFloat converts a decoded BSON number to float64.
+func toFloat(value interface{}) float64 {
+	switch n := value.(type) {
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case int:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+// compareOrdered compares two values of an ordered type.
+func compareOrdered[T string | float64 | primitive.DateTime](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// contains reports whether list contains value.
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// MemorySubscriptions is an in-memory implementation of SubscriptionRepository.
+type MemorySubscriptions struct {
+	mu            sync.RWMutex
+	subscriptions map[primitive.ObjectID]models.Subscription // By workspace
+}
+
+// NewMemorySubscriptions creates an empty in-memory subscription repository.
+func NewMemorySubscriptions() *MemorySubscriptions {
+	return &MemorySubscriptions{subscriptions: map[primitive.ObjectID]models.Subscription{}}
+}
+
+// FindByWorkspace returns the subscription of a workspace.
+func (r *MemorySubscriptions) FindByWorkspace(ctx context.Context, workspaceID primitive.ObjectID) (*models.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscription, ok := r.subscriptions[workspaceID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &subscription, nil
+}
+
+// FindByCustomer returns the subscription of a Stripe customer.
+func (r *MemorySubscriptions) FindByCustomer(ctx context.Context, customerID string) (*models.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, subscription := range r.subscriptions {
+		if customerID != "" && subscription.StripeCustomerID == customerID {
+			return &subscription, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Save inserts or replaces the subscription of a workspace.
+func (r *MemorySubscriptions) Save(ctx context.Context, subscription *models.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.subscriptions[subscription.WorkspaceID]; ok {
+		subscription.ID = existing.ID
+	} else if subscription.ID.IsZero() {
+		subscription.ID = primitive.NewObjectID()
+	}
+	subscription.UpdatedAt = models.NewTimestamp(time.Now())
+	r.subscriptions[subscription.WorkspaceID] = *subscription
+	return nil
+}
+
+// MemoryWorkspaces is an in-memory implementation of WorkspaceRepository.
+type MemoryWorkspaces struct {
+	mu         sync.RWMutex
+	workspaces map[primitive.ObjectID]models.Workspace
+}
+
+// NewMemoryWorkspaces creates an empty in-memory workspace repository.
+func NewMemoryWorkspaces() *MemoryWorkspaces {
+	return &MemoryWorkspaces{workspaces: map[primitive.ObjectID]models.Workspace{}}
+}
+
+// Create inserts a workspace and sets its ID and created_at.
+func (r *MemoryWorkspaces) Create(ctx context.Context, workspace *models.Workspace) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if workspace.ID.IsZero() {
+		workspace.ID = primitive.NewObjectID()
+	}
+	workspace.CreatedAt = primitive.NewDateTimeFromTime(time.Now())
+	r.workspaces[workspace.ID] = cloneWorkspace(*workspace)
+	return nil
+}
+
+// FindByID returns a workspace by ID.
+func (r *MemoryWorkspaces) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Workspace, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	workspace, ok := r.workspaces[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	workspace = cloneWorkspace(workspace)
+	return &workspace, nil
+}
+
+// UpdateBranding replaces the branding of a workspace.
+func (r *MemoryWorkspaces) UpdateBranding(ctx context.Context, id primitive.ObjectID, branding models.Branding) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	workspace, ok := r.workspaces[id]
+	if !ok {
+		return ErrNotFound
+	}
+	workspace.Branding = branding
+	r.workspaces[id] = workspace
+	return nil
+}
+
+// Invite adds a user to the invited users of a workspace.
+func (r *MemoryWorkspaces) Invite(ctx context.Context, id, userID primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	workspace, ok := r.workspaces[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if !containsID(workspace.Invited, userID) {
+		workspace.Invited = append(append([]primitive.ObjectID{}, workspace.Invited...), userID)
+		r.workspaces[id] = workspace
+	}
+	return nil
+}
+
+// Uninvite removes a user from the invited users of a workspace.
+func (r *MemoryWorkspaces) Uninvite(ctx context.Context, id, userID primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	workspace, ok := r.workspaces[id]
+	if !ok || !containsID(workspace.Invited, userID) {
+		return ErrNotFound
+	}
+	invited := []primitive.ObjectID{}
+	for _, invitee := range workspace.Invited {
+		if invitee != userID {
+			invited = append(invited, invitee)
+		}
+	}
+	workspace.Invited = invited
+	r.workspaces[id] = workspace
+	return nil
+}
+
+// cloneWorkspace copies a workspace so that callers cannot change the stored invited users.
+func cloneWorkspace(workspace models.Workspace) models.Workspace {
+	workspace.Invited = append([]primitive.ObjectID(nil), workspace.Invited...)
+	return workspace
+}
+
+// MemoryFocusSessions is an in-memory implementation of FocusSessionRepository.
+type MemoryFocusSessions struct {
+	mu       sync.RWMutex
+	sessions []models.FocusSession
+}
+
+// NewMemoryFocusSessions creates an empty in-memory focus session repository.
+func NewMemoryFocusSessions() *MemoryFocusSessions {
+	return &MemoryFocusSessions{}
+}
+
+// Create inserts a running session and sets its ID.
+func (r *MemoryFocusSessions) Create(ctx context.Context, session *models.FocusSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, stored := range r.sessions {
+		if stored.UserID == session.UserID && stored.EndedAt.IsZero() {
+			return ErrDuplicate
+		}
+	}
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	r.sessions = append(r.sessions, cloneFocusSession(*session))
+	return nil
+}
+
+// FindByID returns a session of a user by ID.
+func (r *MemoryFocusSessions) FindByID(ctx context.Context, userID, id primitive.ObjectID) (*models.FocusSession, error) {
+	return r.findOne(func(session models.FocusSession) bool { return session.ID == id && session.UserID == userID })
+}
+
+// FindRunning returns the running session of a user.
+func (r *MemoryFocusSessions) FindRunning(ctx context.Context, userID primitive.ObjectID) (*models.FocusSession, error) {
+	return r.findOne(func(session models.FocusSession) bool { return session.UserID == userID && session.EndedAt.IsZero() })
+}
+
+// Find returns the sessions matching the query.
+func (r *MemoryFocusSessions) Find(ctx context.Context, query FocusSessionQuery) ([]models.FocusSession, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sessions := []models.FocusSession{}
+	for _, session := range r.sessions {
+		started := session.StartedAt.Time()
+		switch {
+		case !query.UserID.IsZero() && session.UserID != query.UserID:
+		case query.TaskIDs != nil && !containsID(query.TaskIDs, session.TaskID):
+		case !query.StartedAfter.IsZero() && started.Before(query.StartedAfter):
+		case !query.StartedBefore.IsZero() && !started.Before(query.StartedBefore):
+		default:
+			sessions = append(sessions, cloneFocusSession(session))
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].StartedAt < sessions[j].StartedAt })
+	return sessions, nil
+}
+
+// UpdateRunning replaces a running session.
+func (r *MemoryFocusSessions) UpdateRunning(ctx context.Context, session *models.FocusSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, stored := range r.sessions {
+		if stored.ID == session.ID && stored.UserID == session.UserID && stored.EndedAt.IsZero() {
+			r.sessions[i] = cloneFocusSession(*session)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// DeleteMany deletes the sessions of a user.
+func (r *MemoryFocusSessions) DeleteMany(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.sessions[:0]
+	for _, session := range r.sessions {
+		if session.UserID != userID {
+			kept = append(kept, session)
+		}
+	}
+	deleted := int64(len(r.sessions) - len(kept))
+	r.sessions = kept
+	return deleted, nil
+}
+
+// findOne returns a copy of the first session matching match.
+func (r *MemoryFocusSessions) findOne(match func(session models.FocusSession) bool) (*models.FocusSession, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, session := range r.sessions {
+		if match(session) {
+			found := cloneFocusSession(session)
+			return &found, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// cloneFocusSession copies a session with its interruptions, so that stored sessions are not shared.
+func cloneFocusSession(session models.FocusSession) models.FocusSession {
+	session.Interruptions = append([]models.Interruption{}, session.Interruptions...)
+	return session
+}
@@ -0,0 +1,340 @@
+// memory_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package repository
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/audit"
+	"github.com/bkojha74/task-management/fieldcrypt"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/pagination"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestMemoryUsers tests the in-memory user repository
+func TestMemoryUsers(t *testing.T) {
+	ctx := context.Background()
+	users := NewMemoryUsers()
+
+	alice := models.User{Username: "alice", Password: "hash"}
+	require.NoError(t, users.Create(ctx, &alice))
+
+	// Assert that the ID is set and usernames are unique
+	assert.False(t, alice.ID.IsZero())
+	assert.ErrorIs(t, users.Create(ctx, &models.User{Username: "alice"}), ErrDuplicate)
+
+	found, err := users.FindByUsername(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, found.ID)
+
+	// Assert that updates are stored and missing users are reported
+	found.DisplayName = "Alice"
+	require.NoError(t, users.Update(ctx, found))
+	found, err = users.FindByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", found.DisplayName)
+	_, err = users.FindByUsername(ctx, "bob")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, users.Update(ctx, &models.User{ID: primitive.NewObjectID()}), ErrNotFound)
+
+	// Assert that users are listed page by page in the order of their IDs, with the total
+	bob := models.User{Username: "bob"}
+	require.NoError(t, users.Create(ctx, &bob))
+	page, total, err := users.List(ctx, 1, 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, page, 1)
+	assert.Equal(t, bob.ID, page[0].ID)
+	page, _, err = users.List(ctx, 2, 5)
+	require.NoError(t, err)
+	assert.Empty(t, page)
+
+	// Assert that a directory subject is linked to one user only
+	bob.Subject = "ldap|uid=bob"
+	require.NoError(t, users.Update(ctx, &bob))
+	found, err = users.FindBySubject(ctx, "ldap|uid=bob")
+	require.NoError(t, err)
+	assert.Equal(t, bob.ID, found.ID)
+	_, err = users.FindBySubject(ctx, "")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, users.Create(ctx, &models.User{Username: "carol", Subject: "ldap|uid=bob"}), ErrDuplicate)
+	found, err = users.FindByID(ctx, alice.ID)
+	require.NoError(t, err)
+	found.Subject = "ldap|uid=bob"
+	assert.ErrorIs(t, users.Update(ctx, found), ErrDuplicate)
+}
+
+// TestMemoryNonces tests that a value is used once until it expires
+func TestMemoryNonces(t *testing.T) {
+	ctx := context.Background()
+	nonces := NewMemoryNonces()
+
+	require.NoError(t, nonces.Use(ctx, "a", time.Now().Add(time.Minute)))
+	// Assert that a used value is rejected and other values are not
+	assert.ErrorIs(t, nonces.Use(ctx, "a", time.Now().Add(time.Minute)), ErrDuplicate)
+	require.NoError(t, nonces.Use(ctx, "b", time.Now().Add(-time.Second)))
+
+	// Assert that an expired value can be used again
+	assert.NoError(t, nonces.Use(ctx, "b", time.Now().Add(time.Minute)))
+}
+
+// TestMemoryAuditLog tests that entries are stored, updated and listed per user, newest first
+func TestMemoryAuditLog(t *testing.T) {
+	ctx := context.Background()
+	auditLog := NewMemoryAuditLog()
+
+	first := audit.Entry{UserID: "alice", Method: "PATCH", Path: "/tasks"}
+	require.NoError(t, auditLog.Create(ctx, &first))
+	require.NoError(t, auditLog.Create(ctx, &audit.Entry{UserID: "bob", Method: "PATCH", Path: "/tasks"}))
+	second := audit.Entry{UserID: "alice", Method: "DELETE", Path: "/tasks"}
+	require.NoError(t, auditLog.Create(ctx, &second))
+
+	// Assert that the ID is set and an entry can be completed later
+	assert.False(t, first.ID.IsZero())
+	first.Status = 200
+	require.NoError(t, auditLog.Update(ctx, &first))
+	assert.ErrorIs(t, auditLog.Update(ctx, &audit.Entry{ID: primitive.NewObjectID()}), ErrNotFound)
+
+	entries, err := auditLog.FindByUser(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, second.ID, entries[0].ID)
+	assert.Equal(t, 200, entries[1].Status)
+}
+
+// TestMemoryTasksPagination tests that the in-memory task repository paginates like MongoDB
+func TestMemoryTasksPagination(t *testing.T) {
+	ctx := context.Background()
+	tasks := NewMemoryTasks()
+	owner := primitive.NewObjectID()
+	base := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Five tasks, two of them due at the same time to exercise the _id tiebreaker
+	for i, day := range []int{3, 1, 2, 2, 5} {
+		task := models.Task{UserID: owner, Title: string(rune('a' + i)), EndDate: models.NewTimestamp(base.AddDate(0, 0, day))}
+		require.NoError(t, tasks.Create(ctx, &task))
+	}
+	require.NoError(t, tasks.Create(ctx, &models.Task{UserID: primitive.NewObjectID(), Title: "other owner"}))
+
+	sort := pagination.Sort{Field: "end_time", Descending: true}
+	seen := []string{}
+	var after *pagination.Cursor
+	for {
+		page, err := tasks.Find(ctx, TaskQuery{UserID: owner, Sort: sort, After: after, Limit: 2})
+		require.NoError(t, err)
+		if len(page) <= 2 {
+			for _, task := range page {
+				seen = append(seen, task.Title)
+			}
+			break
+		}
+		for _, task := range page[:2] {
+			seen = append(seen, task.Title)
+		}
+		after, err = pagination.CursorFor(page[1], sort)
+		require.NoError(t, err)
+	}
+
+	// Assert that every task of the owner is returned once, newest due date first
+	assert.Equal(t, []string{"e", "a", "d", "c", "b"}, seen)
+}
+
+// TestMemoryTasksPaginationEmptyValues tests that pages continue across tasks whose sort value is empty,
+// with the cursor encoded between pages like the handlers do
+func TestMemoryTasksPaginationEmptyValues(t *testing.T) {
+	ctx := context.Background()
+	tasks := NewMemoryTasks()
+	owner := primitive.NewObjectID()
+
+	// Four tasks without a due date and one with
+	for i := 0; i < 5; i++ {
+		task := models.Task{UserID: owner, Title: string(rune('a' + i))}
+		if i == 2 {
+			task.EndDate = models.NewTimestamp(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+		}
+		require.NoError(t, tasks.Create(ctx, &task))
+	}
+
+	sort := pagination.Sort{Field: "end_time"}
+	seen := []string{}
+	var after *pagination.Cursor
+	for {
+		page, err := tasks.Find(ctx, TaskQuery{UserID: owner, Sort: sort, After: after, Limit: 2})
+		require.NoError(t, err)
+		if len(page) <= 2 {
+			for _, task := range page {
+				seen = append(seen, task.Title)
+			}
+			break
+		}
+		for _, task := range page[:2] {
+			seen = append(seen, task.Title)
+		}
+		next, err := pagination.CursorFor(page[1], sort)
+		require.NoError(t, err)
+		after, err = pagination.Decode(next.Encode(), sort)
+		require.NoError(t, err)
+	}
+
+	// Assert that every task is returned once, the undated ones first
+	assert.Equal(t, []string{"a", "b", "d", "e", "c"}, seen)
+}
+
+// TestMemoryTasksVersioning tests the optimistic concurrency check and query filters
+func TestMemoryTasksVersioning(t *testing.T) {
+	ctx := context.Background()
+	tasks := NewMemoryTasks()
+	owner := primitive.NewObjectID()
+
+	task := models.Task{UserID: owner, Title: "v1", Status: models.TaskStatusPending, AllottedTo: "bob", Version: 1}
+	require.NoError(t, tasks.Create(ctx, &task))
+
+	// Assert that only the expected version can be replaced
+	task.Title, task.Version = "v2", 2
+	assert.ErrorIs(t, tasks.Update(ctx, &task, 5), ErrNotFound)
+	require.NoError(t, tasks.Update(ctx, &task, 1))
+	stored, err := tasks.FindByID(ctx, owner, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", stored.Title)
+
+	// Assert that tasks are scoped to their owner
+	_, err = tasks.FindByID(ctx, primitive.NewObjectID(), task.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Assert that counts honour the status filters
+	count, err := tasks.Count(ctx, TaskQuery{AllottedTo: "bob", ExcludeStatus: models.TaskStatusDone})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	count, err = tasks.Count(ctx, TaskQuery{AllottedTo: "bob", ExcludeID: task.ID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	require.NoError(t, tasks.Delete(ctx, owner, task.ID))
+	assert.ErrorIs(t, tasks.Delete(ctx, owner, task.ID), ErrNotFound)
+}
+
+// TestMemoryTasksTimestamps tests that writes stamp created_at and updated_at and that both can be filtered on
+func TestMemoryTasksTimestamps(t *testing.T) {
+	ctx := context.Background()
+	tasks := NewMemoryTasks()
+	owner := primitive.NewObjectID()
+
+	before := time.Now()
+	task := models.Task{UserID: owner, Title: "a", Version: 1, CreatedAt: models.NewTimestamp(before.AddDate(-1, 0, 0))}
+	require.NoError(t, tasks.Create(ctx, &task))
+
+	// Assert that creation stamps both times, ignoring what the caller sent
+	assert.WithinDuration(t, before, task.CreatedAt.Time(), time.Second)
+	assert.Equal(t, task.CreatedAt, task.UpdatedAt)
+	created := task.CreatedAt
+
+	// Assert that updates move updated_at and keep created_at
+	time.Sleep(2 * time.Millisecond)
+	task.CreatedAt = 0
+	task.Version = 2
+	require.NoError(t, tasks.Update(ctx, &task, 1))
+	assert.Equal(t, created, task.CreatedAt)
+	assert.True(t, task.UpdatedAt > created)
+
+	// Assert that the ranges include their lower and exclude their upper bound
+	count := func(query TaskQuery) int64 {
+		query.UserID = owner
+		n, err := tasks.Count(ctx, query)
+		require.NoError(t, err)
+		return n
+	}
+	assert.EqualValues(t, 1, count(TaskQuery{CreatedAfter: created.Time()}))
+	assert.EqualValues(t, 0, count(TaskQuery{CreatedBefore: created.Time()}))
+	assert.EqualValues(t, 0, count(TaskQuery{UpdatedAfter: task.UpdatedAt.Time().Add(time.Millisecond)}))
+	assert.EqualValues(t, 1, count(TaskQuery{UpdatedAfter: created.Time(), UpdatedBefore: time.Now().Add(time.Second)}))
+}
+
+// TestMemoryTasksProjection tests loading only some fields of tasks
+func TestMemoryTasksProjection(t *testing.T) {
+	ctx := context.Background()
+	tasks := NewMemoryTasks()
+	owner := primitive.NewObjectID()
+
+	task := models.Task{UserID: owner, Title: "a", Description: "long text", Status: models.TaskStatusPending, Version: 1}
+	require.NoError(t, tasks.Create(ctx, &task))
+
+	found, err := tasks.Find(ctx, TaskQuery{UserID: owner, Fields: []string{"title"}, Sort: pagination.Sort{Field: "status"}})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+
+	// Assert that the selected fields, the ID and the sort field are loaded, and nothing else
+	assert.Equal(t, task.ID, found[0].ID)
+	assert.Equal(t, "a", found[0].Title)
+	assert.Equal(t, models.TaskStatusPending, found[0].Status)
+	assert.Empty(t, found[0].Description)
+	assert.Zero(t, found[0].Version)
+
+	// Assert that the stored task is untouched
+	stored, err := tasks.FindByID(ctx, owner, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "long text", stored.Description)
+}
+
+// TestEncryptedTasks tests that descriptions of tasks in private projects are stored encrypted and re-encrypted on rotation
+func TestEncryptedTasks(t *testing.T) {
+	ctx := context.Background()
+	UseMemory()
+	inner := Tasks
+	owner := primitive.NewObjectID()
+	private := models.Project{OwnerID: owner, Name: "HR", Private: true}
+	public := models.Project{OwnerID: owner, Name: "Website"}
+	require.NoError(t, Projects.Create(ctx, &private))
+	require.NoError(t, Projects.Create(ctx, &public))
+
+	oldKey := fieldcrypt.Key{ID: "k1", Secret: bytes.Repeat([]byte{1}, 32)}
+	keyring, err := fieldcrypt.NewKeyring(oldKey)
+	require.NoError(t, err)
+	UseFieldEncryption(keyring)
+
+	secret := models.Task{UserID: owner, ProjectID: private.ID, Title: "Review", Description: "salary bands", DescriptionHTML: "<p>salary bands</p>"}
+	open := models.Task{UserID: owner, ProjectID: public.ID, Title: "Launch", Description: "new landing page"}
+	require.NoError(t, Tasks.Create(ctx, &secret))
+	require.NoError(t, Tasks.Create(ctx, &open))
+
+	// Assert that callers see plaintext while only the private description is stored encrypted
+	assert.Equal(t, "salary bands", secret.Description)
+	found, err := Tasks.FindByID(ctx, owner, secret.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "salary bands", found.Description)
+	assert.Equal(t, "<p>salary bands</p>", found.DescriptionHTML)
+	stored, err := inner.FindByID(ctx, owner, secret.ID)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(stored.Description, "enc:v1:k1:"))
+	assert.True(t, strings.HasPrefix(stored.DescriptionHTML, "enc:v1:k1:"))
+	stored, err = inner.FindByID(ctx, owner, open.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "new landing page", stored.Description)
+
+	// Assert that after a key rotation Rotate rewrites only the description under the old key
+	rotatedKeyring, err := fieldcrypt.NewKeyring(fieldcrypt.Key{ID: "k2", Secret: bytes.Repeat([]byte{2}, 32)}, oldKey)
+	require.NoError(t, err)
+	rotated := &EncryptedTasks{TaskRepository: inner, Keyring: rotatedKeyring}
+	count, err := rotated.Rotate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	stored, err = inner.FindByID(ctx, owner, secret.ID)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(stored.Description, "enc:v1:k2:"))
+	assert.True(t, strings.HasPrefix(stored.DescriptionHTML, "enc:v1:k2:"))
+	listed, err := rotated.Find(ctx, TaskQuery{UserID: owner})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"salary bands", "new landing page"}, []string{listed[0].Description, listed[1].Description})
+
+	count, err = rotated.Rotate(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
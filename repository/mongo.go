@@ -0,0 +1,1811 @@
+// mongo.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/audit"
+	"github.com/bkojha74/task-management/database"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/pagination"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InitMongo sets up the repositories on the collections opened by database.Init.
+func InitMongo() {
+	Users = &MongoUsers{Collection: database.UsersCollection}
+	Tasks = &MongoTasks{Collection: database.TasksCollection, Trashed: database.TrashCollection}
+	TaskViews = &MongoTaskViews{Collection: database.TaskListViewCollection}
+	Projects = &MongoProjects{Collection: database.ProjectsCollection}
+	Sessions = &MongoSessions{Collection: database.SessionsCollection}
+	SignIns = &MongoSignIns{Collection: database.SignInsCollection}
+	Invitations = &MongoInvitations{Collection: database.InvitationsCollection}
+	Comments = &MongoComments{Collection: database.CommentsCollection}
+	InviteCodes = &MongoInviteCodes{Collection: database.InviteCodesCollection}
+	Maintenance = &MongoMaintenance{Collection: database.SettingsCollection}
+	Leases = &MongoLeases{Collection: database.LeasesCollection}
+	Nonces = &MongoNonces{Collection: database.NoncesCollection}
+	AuditLog = &MongoAuditLog{Collection: database.AuditLogCollection}
+	Outbox = &MongoOutbox{Collection: database.OutboxCollection}
+	Webhooks = &MongoWebhooks{Collection: database.WebhooksCollection}
+	WebhookDeliveries = &MongoWebhookDeliveries{Collection: database.WebhookDeliveriesCollection}
+	Tombstones = &MongoTombstones{Collection: database.TombstonesCollection}
+	ReportSchedules = &MongoReportSchedules{Collection: database.ReportSchedulesCollection}
+	Subscriptions = &MongoSubscriptions{Collection: database.SubscriptionsCollection}
+	FocusSessions = &MongoFocusSessions{Collection: database.FocusSessionsCollection}
+	Workspaces = &MongoWorkspaces{Collection: database.WorkspacesCollection}
+	Transactions = &MongoTransactions{Client: database.MongoClient}
+	ReportTasks = Tasks
+}
+
+// UseReportingMongo serves the reporting reads from the collection opened by database.ConnectReporting.
+// Call it after InitMongo and before UseFieldEncryption.
+func UseReportingMongo() {
+	ReportTasks = &MongoTasks{Collection: database.ReportingTasksCollection, Trashed: database.TrashCollection}
+}
+
+// MongoUsers is the MongoDB implementation of UserRepository.
+type MongoUsers struct {
+	Collection *mongo.Collection
+}
+
+// Create inserts a user and sets its ID and timestamps.
+func (r *MongoUsers) Create(ctx context.Context, user *models.User) error {
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	user.CreatedAt = models.NewTimestamp(time.Now())
+	user.UpdatedAt = user.CreatedAt
+	_, err := r.Collection.InsertOne(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// FindByID returns the user with the given ID.
+func (r *MongoUsers) FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+// FindByUsername returns the user with the given username.
+func (r *MongoUsers) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.findOne(ctx, bson.M{"username": username})
+}
+
+// FindBySubject returns the user linked to a directory subject.
+func (r *MongoUsers) FindBySubject(ctx context.Context, subject string) (*models.User, error) {
+	if subject == "" {
+		return nil, ErrNotFound
+	}
+	return r.findOne(ctx, bson.M{"subject": subject})
+}
+
+// FindByTelegramChat returns the user who linked a Telegram chat.
+func (r *MongoUsers) FindByTelegramChat(ctx context.Context, chatID int64) (*models.User, error) {
+	return r.findOne(ctx, bson.M{"telegram_chat_id": chatID})
+}
+
+// Update replaces a stored user.
+func (r *MongoUsers) Update(ctx context.Context, user *models.User) error {
+	user.UpdatedAt = models.NewTimestamp(time.Now())
+	result, err := r.Collection.ReplaceOne(ctx, bson.M{"_id": user.ID}, user)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrDuplicate
+		}
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// FindByWorkspace returns the members of a workspace.
+func (r *MongoUsers) FindByWorkspace(ctx context.Context, workspaceID primitive.ObjectID) ([]models.User, error) {
+	cursor, err := r.Collection.Find(ctx, bson.M{"workspace_id": workspaceID})
+	if err != nil {
+		return nil, err
+	}
+
+	users := []models.User{}
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// FindDeletionDue returns the users due for deletion.
+func (r *MongoUsers) FindDeletionDue(ctx context.Context, before time.Time) ([]models.User, error) {
+	filter := bson.M{"delete_at": bson.M{"$gt": primitive.DateTime(0), "$lt": primitive.NewDateTimeFromTime(before)}}
+	cursor, err := r.Collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	users := []models.User{}
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// Delete deletes a user.
+func (r *MongoUsers) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.Collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// List returns a page of the users in the order of their IDs.
+func (r *MongoUsers) List(ctx context.Context, offset, limit int) ([]models.User, int64, error) {
+	total, err := r.Collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+	page := options.Find().SetSort(bson.M{"_id": 1}).SetSkip(int64(offset)).SetLimit(int64(limit))
+	cursor, err := r.Collection.Find(ctx, bson.M{}, page)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users := []models.User{}
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (r *MongoUsers) findOne(ctx context.Context, filter bson.M) (*models.User, error) {
+	var user models.User
+	if err := r.Collection.FindOne(ctx, filter).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// MongoTasks is the MongoDB implementation of TaskRepository.
+type MongoTasks struct {
+	Collection *mongo.Collection
+	Trashed    *mongo.Collection // The trash of deleted tasks
+}
+
+// Create inserts a task and sets its timestamps.
+func (r *MongoTasks) Create(ctx context.Context, task *models.Task) error {
+	if task.ID.IsZero() {
+		task.ID = primitive.NewObjectID()
+	}
+	task.CreatedAt = models.NewTimestamp(time.Now())
+	task.UpdatedAt = task.CreatedAt
+	task.ManualRank = task.ManualKey()
+	_, err := r.Collection.InsertOne(ctx, task)
+	return err
+}
+
+// Find returns the tasks matching the query.
+func (r *MongoTasks) Find(ctx context.Context, query TaskQuery) ([]models.Task, error) {
+	sort := query.Sort
+	if sort.Field == "" {
+		sort.Field = "_id"
+	}
+
+	opts := pagination.FindOptions(sort, query.Limit)
+	if len(query.Fields) > 0 {
+		projection := bson.M{sort.Field: 1}
+		for _, field := range query.Fields {
+			projection[field] = 1
+		}
+		opts.SetProjection(projection)
+	}
+
+	cursor, err := r.Collection.Find(ctx, taskFilter(query, sort), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []models.Task{}
+	if err = cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// FindEntries returns the tasks matching the query with an aggregation that joins the assignee's user
+// summary and counts the comments of each task, using the username and task_id indexes. Joins that the
+// selected fields leave out are skipped.
+func (r *MongoTasks) FindEntries(ctx context.Context, query TaskQuery) ([]models.TaskListEntry, error) {
+	sort := query.Sort
+	if sort.Field == "" {
+		sort.Field = "_id"
+	}
+	assignee, comments := entryJoins(query.Fields)
+
+	opts := pagination.FindOptions(sort, query.Limit)
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: taskFilter(query, sort)}},
+		{{Key: "$sort", Value: opts.Sort}},
+	}
+	if opts.Limit != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: *opts.Limit}})
+	}
+	if len(query.Fields) > 0 {
+		projection := bson.M{sort.Field: 1}
+		for _, field := range query.Fields {
+			projection[field] = 1
+		}
+		if assignee {
+			projection["allotted_to"] = 1 // The assignee is joined on it
+		}
+		pipeline = append(pipeline, bson.D{{Key: "$project", Value: projection}})
+	}
+
+	// The joined arrays are turned into a summary with only the public fields of the user, and a number
+	joined := bson.M{}
+	if assignee {
+		pipeline = append(pipeline, bson.D{{Key: "$lookup", Value: bson.M{
+			"from": "users", "localField": "allotted_to", "foreignField": "username", "as": "assignee",
+		}}})
+		joined["assignee"] = bson.M{"$arrayElemAt": bson.A{bson.M{"$map": bson.M{
+			"input": "$assignee",
+			"in":    bson.M{"_id": "$$this._id", "username": "$$this.username", "display_name": "$$this.display_name"},
+		}}, 0}}
+	}
+	if comments {
+		pipeline = append(pipeline, bson.D{{Key: "$lookup", Value: bson.M{
+			"from": "comments",
+			"let":  bson.M{"task": "$_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$task_id", "$$task"}}}},
+				bson.M{"$count": "count"},
+			},
+			"as": "comment_count",
+		}}})
+		joined["comment_count"] = bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$comment_count.count", 0}}, 0}}
+	}
+	if len(joined) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$addFields", Value: joined}})
+	}
+
+	cursor, err := r.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	capacity := 0
+	if opts.Limit != nil {
+		capacity = int(*opts.Limit)
+	}
+	entries := make([]models.TaskListEntry, 0, capacity)
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// FindByID returns a task of the given owner.
+func (r *MongoTasks) FindByID(ctx context.Context, userID, taskID primitive.ObjectID) (*models.Task, error) {
+	var task models.Task
+	if err := r.Collection.FindOne(ctx, bson.M{"_id": taskID, "userId": userID}).Decode(&task); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &task, nil
+}
+
+// Update replaces a task if its stored version is expectedVersion, and reads back the stored created_at.
+func (r *MongoTasks) Update(ctx context.Context, task *models.Task, expectedVersion int) error {
+	filter := bson.M{"_id": task.ID, "userId": task.UserID, "version": expectedVersion}
+	if expectedVersion == 0 {
+		// Tasks created before versioning have no version field
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	}
+
+	task.UpdatedAt = models.NewTimestamp(time.Now())
+	task.ManualRank = task.ManualKey()
+	set, err := documentWithout(task, "created_at")
+	if err != nil {
+		return err
+	}
+
+	var stored models.Task
+	err = r.Collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&stored)
+	if err == mongo.ErrNoDocuments {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	task.CreatedAt = stored.CreatedAt
+	return nil
+}
+
+// Delete deletes a task of the given owner.
+func (r *MongoTasks) Delete(ctx context.Context, userID, taskID primitive.ObjectID) error {
+	result, err := r.Collection.DeleteOne(ctx, bson.M{"_id": taskID, "userId": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Count returns the number of tasks matching the query.
+func (r *MongoTasks) Count(ctx context.Context, query TaskQuery) (int64, error) {
+	query.After = nil
+	return r.Collection.CountDocuments(ctx, taskFilter(query, pagination.Sort{Field: "_id"}))
+}
+
+// DeleteMany deletes the tasks matching the query.
+func (r *MongoTasks) DeleteMany(ctx context.Context, query TaskQuery) (int64, error) {
+	query.After = nil
+	result, err := r.Collection.DeleteMany(ctx, taskFilter(query, pagination.Sort{Field: "_id"}))
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// Trash moves a task to the trash.
+func (r *MongoTasks) Trash(ctx context.Context, userID, taskID primitive.ObjectID) error {
+	var task models.Task
+	err := r.Collection.FindOneAndDelete(ctx, bson.M{"_id": taskID, "userId": userID}).Decode(&task)
+	if err == mongo.ErrNoDocuments {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	_, err = r.Trashed.InsertOne(ctx, models.TrashedTask{Task: task, DeletedAt: models.NewTimestamp(time.Now())})
+	return err
+}
+
+// FindTrashed returns the trashed tasks of an owner, the most recently deleted first.
+func (r *MongoTasks) FindTrashed(ctx context.Context, userID primitive.ObjectID) ([]models.TrashedTask, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := r.Trashed.Find(ctx, bson.M{"userId": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	trashed := []models.TrashedTask{}
+	if err = cursor.All(ctx, &trashed); err != nil {
+		return nil, err
+	}
+	return trashed, nil
+}
+
+// Restore moves a trashed task back to the tasks.
+func (r *MongoTasks) Restore(ctx context.Context, userID, taskID primitive.ObjectID) (*models.Task, error) {
+	var trashed models.TrashedTask
+	err := r.Trashed.FindOneAndDelete(ctx, bson.M{"_id": taskID, "userId": userID}).Decode(&trashed)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	task := trashed.Task
+	task.UpdatedAt = models.NewTimestamp(time.Now())
+	if _, err := r.Collection.InsertOne(ctx, task); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrDuplicate
+		}
+		return nil, err
+	}
+	return &task, nil
+}
+
+// CountTrashed returns the number of tasks trashed before a time.
+func (r *MongoTasks) CountTrashed(ctx context.Context, before time.Time) (int64, error) {
+	return r.Trashed.CountDocuments(ctx, bson.M{"deleted_at": bson.M{"$lt": primitive.NewDateTimeFromTime(before)}})
+}
+
+// PurgeTrash deletes the tasks trashed before a time. The IDs are read first, so that only the tasks
+// whose IDs are returned are deleted.
+func (r *MongoTasks) PurgeTrash(ctx context.Context, userID primitive.ObjectID, before time.Time) ([]primitive.ObjectID, error) {
+	filter := bson.M{"deleted_at": bson.M{"$lt": primitive.NewDateTimeFromTime(before)}}
+	if !userID.IsZero() {
+		filter["userId"] = userID
+	}
+	cursor, err := r.Trashed.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var found []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+
+	purged := make([]primitive.ObjectID, 0, len(found))
+	for _, task := range found {
+		purged = append(purged, task.ID)
+	}
+	if len(purged) == 0 {
+		return purged, nil
+	}
+	if _, err := r.Trashed.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": purged}}); err != nil {
+		return nil, err
+	}
+	return purged, nil
+}
+
+// documentWithout marshals doc into a BSON document without the given fields.
+func documentWithout(doc interface{}, fields ...string) (bson.D, error) {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var full bson.D
+	if err := bson.Unmarshal(data, &full); err != nil {
+		return nil, err
+	}
+
+	kept := bson.D{}
+	for _, element := range full {
+		if !contains(fields, element.Key) {
+			kept = append(kept, element)
+		}
+	}
+	return kept, nil
+}
+
+// taskFilter translates a task query into a MongoDB filter.
+func taskFilter(query TaskQuery, sort pagination.Sort) bson.M {
+	filter := pagination.Filter(sort, query.After)
+	if !query.UserID.IsZero() {
+		filter["userId"] = query.UserID
+	}
+	if !query.ProjectID.IsZero() {
+		filter["project_id"] = query.ProjectID
+	}
+	if query.AllottedTo != "" {
+		filter["allotted_to"] = query.AllottedTo
+	}
+	if len(query.Statuses) > 0 {
+		filter["status"] = bson.M{"$in": query.Statuses}
+	} else if query.ExcludeStatus != "" {
+		filter["status"] = bson.M{"$ne": query.ExcludeStatus}
+	}
+	if query.ExcludeVisibility != "" {
+		filter["visibility"] = bson.M{"$ne": query.ExcludeVisibility}
+	}
+	if query.Text != "" {
+		filter["$text"] = bson.M{"$search": textSearch(query.Text)}
+	}
+	if !query.DueBefore.IsZero() || query.HasDueDate {
+		endFilter := bson.M{}
+		if !query.DueBefore.IsZero() {
+			endFilter["$lt"] = primitive.NewDateTimeFromTime(query.DueBefore)
+		}
+		if query.HasDueDate {
+			endFilter["$gt"] = primitive.DateTime(0)
+		}
+		filter["end_time"] = endFilter
+	}
+	if timeFilter := timeRange(query.CreatedAfter, query.CreatedBefore); timeFilter != nil {
+		filter["created_at"] = timeFilter
+	}
+	if timeFilter := timeRange(query.UpdatedAfter, query.UpdatedBefore); timeFilter != nil {
+		filter["updated_at"] = timeFilter
+	}
+	if !query.CompletedAfter.IsZero() || !query.CompletedBefore.IsZero() {
+		completedFilter := bson.M{"$gt": primitive.DateTime(0)}
+		if !query.CompletedAfter.IsZero() {
+			completedFilter["$gt"] = primitive.NewDateTimeFromTime(query.CompletedAfter)
+		}
+		if !query.CompletedBefore.IsZero() {
+			completedFilter["$lt"] = primitive.NewDateTimeFromTime(query.CompletedBefore)
+		}
+		filter["completed_at"] = completedFilter
+	}
+	if query.Archived != nil {
+		if *query.Archived {
+			filter["archived_at"] = bson.M{"$gt": primitive.DateTime(0)}
+		} else {
+			// Tasks that were never archived have no archived_at field
+			filter["archived_at"] = bson.M{"$not": bson.M{"$gt": primitive.DateTime(0)}}
+		}
+	}
+	if query.Overdue != nil {
+		if *query.Overdue {
+			filter["overdue"] = true
+		} else {
+			// Tasks that were never flagged have no overdue field
+			filter["overdue"] = bson.M{"$ne": true}
+		}
+	}
+	if query.HasGitHubIssue {
+		filter["github_issue"] = bson.M{"$exists": true}
+	}
+	if query.GitHubRepo != "" {
+		filter["github_issue.repo"] = query.GitHubRepo
+	}
+	if query.GitHubIssue != 0 {
+		filter["github_issue.number"] = query.GitHubIssue
+	}
+	if query.CalDAVName != "" {
+		filter["caldav.name"] = query.CalDAVName
+	}
+	if !query.ExcludeID.IsZero() || len(query.IDs) > 0 {
+		// The cursor may already restrict _id
+		idFilter, _ := filter["_id"].(bson.M)
+		if idFilter == nil {
+			idFilter = bson.M{}
+		}
+		if !query.ExcludeID.IsZero() {
+			idFilter["$ne"] = query.ExcludeID
+		}
+		if len(query.IDs) > 0 {
+			idFilter["$in"] = query.IDs
+		}
+		filter["_id"] = idFilter
+	}
+	return filter
+}
+
+// textSearch turns text into a $text search matching all of its words: MongoDB matches any of the words
+// of a search, but every one of its quoted phrases.
+func textSearch(text string) string {
+	phrases := []string{}
+	for _, word := range words(text) {
+		phrases = append(phrases, `"`+word+`"`)
+	}
+	return strings.Join(phrases, " ")
+}
+
+// timeRange returns the filter selecting times at or after from and before to, or nil when both are zero.
+func timeRange(from, to time.Time) bson.M {
+	if from.IsZero() && to.IsZero() {
+		return nil
+	}
+	filter := bson.M{}
+	if !from.IsZero() {
+		filter["$gte"] = primitive.NewDateTimeFromTime(from)
+	}
+	if !to.IsZero() {
+		filter["$lt"] = primitive.NewDateTimeFromTime(to)
+	}
+	return filter
+}
+
+// MongoTaskViews is the MongoDB implementation of TaskViewRepository.
+type MongoTaskViews struct {
+	Collection *mongo.Collection
+}
+
+// Upsert writes a view, keeping the stored counts.
+func (r *MongoTaskViews) Upsert(ctx context.Context, view models.TaskListView) error {
+	_, err := r.Collection.UpdateOne(ctx,
+		bson.M{"_id": view.ID},
+		bson.M{
+			"$set": bson.M{
+				"userId":        view.UserID,
+				"title":         view.Title,
+				"status":        view.Status,
+				"priority":      view.Priority,
+				"due":           view.Due,
+				"assignee_name": view.AssigneeName,
+				"updated_at":    view.UpdatedAt,
+			},
+			"$setOnInsert": bson.M{"comment_count": view.CommentCount, "attachment_count": view.AttachmentCount},
+		},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+// Delete removes the view of a task.
+func (r *MongoTaskViews) Delete(ctx context.Context, taskID primitive.ObjectID) error {
+	_, err := r.Collection.DeleteOne(ctx, bson.M{"_id": taskID})
+	return err
+}
+
+// AdjustCounts adds the deltas to the counts of a view.
+func (r *MongoTaskViews) AdjustCounts(ctx context.Context, taskID primitive.ObjectID, comments, attachments int) error {
+	_, err := r.Collection.UpdateOne(ctx, bson.M{"_id": taskID},
+		bson.M{"$inc": bson.M{"comment_count": comments, "attachment_count": attachments}})
+	return err
+}
+
+// Find returns one page of an owner's views.
+func (r *MongoTaskViews) Find(ctx context.Context, userID primitive.ObjectID, sort pagination.Sort, after *pagination.Cursor, limit int) ([]models.TaskListView, error) {
+	filter := pagination.Filter(sort, after)
+	filter["userId"] = userID
+
+	cursor, err := r.Collection.Find(ctx, filter, pagination.FindOptions(sort, limit))
+	if err != nil {
+		return nil, err
+	}
+
+	views := []models.TaskListView{}
+	if err = cursor.All(ctx, &views); err != nil {
+		return nil, err
+	}
+	return views, nil
+}
+
+// MongoProjects is the MongoDB implementation of ProjectRepository.
+type MongoProjects struct {
+	Collection *mongo.Collection
+}
+
+// Create inserts a project and sets its ID.
+func (r *MongoProjects) Create(ctx context.Context, project *models.Project) error {
+	if project.ID.IsZero() {
+		project.ID = primitive.NewObjectID()
+	}
+	_, err := r.Collection.InsertOne(ctx, project)
+	return err
+}
+
+// FindByID returns a project of the given owner.
+func (r *MongoProjects) FindByID(ctx context.Context, ownerID, projectID primitive.ObjectID) (*models.Project, error) {
+	var project models.Project
+	if err := r.Collection.FindOne(ctx, bson.M{"_id": projectID, "owner_id": ownerID}).Decode(&project); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &project, nil
+}
+
+// Find returns the projects of an owner.
+func (r *MongoProjects) Find(ctx context.Context, ownerID primitive.ObjectID) ([]models.Project, error) {
+	cursor, err := r.Collection.Find(ctx, bson.M{"owner_id": ownerID}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	projects := []models.Project{}
+	if err = cursor.All(ctx, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// DeleteMany deletes the projects of an owner.
+func (r *MongoProjects) DeleteMany(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	result, err := r.Collection.DeleteMany(ctx, bson.M{"owner_id": ownerID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// SetAssignment sets or removes the assignment rule of a project of the given owner.
+func (r *MongoProjects) SetAssignment(ctx context.Context, ownerID, projectID primitive.ObjectID, rule *models.AssignmentRule) error {
+	update := bson.M{"$unset": bson.M{"assignment": ""}}
+	if rule != nil {
+		update = bson.M{"$set": bson.M{"assignment": rule}}
+	}
+	result, err := r.Collection.UpdateOne(ctx, bson.M{"_id": projectID, "owner_id": ownerID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// NextAssignment takes a round-robin turn of a project's assignment rule in one atomic update, so that
+// concurrent task creations get different turns.
+func (r *MongoProjects) NextAssignment(ctx context.Context, projectID primitive.ObjectID) (int, error) {
+	var project models.Project
+	err := r.Collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": projectID, "assignment": bson.M{"$exists": true}},
+		bson.M{"$inc": bson.M{"assignment.next": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.Before).SetProjection(bson.M{"assignment": 1}),
+	).Decode(&project)
+	if err == mongo.ErrNoDocuments || (err == nil && project.Assignment == nil) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return project.Assignment.Next, nil
+}
+
+// ReturnAssignment gives back the last round-robin turn of a project's assignment rule, unless a later
+// turn was taken.
+func (r *MongoProjects) ReturnAssignment(ctx context.Context, projectID primitive.ObjectID, turn int) error {
+	_, err := r.Collection.UpdateOne(ctx,
+		bson.M{"_id": projectID, "assignment.next": turn + 1},
+		bson.M{"$set": bson.M{"assignment.next": turn}},
+	)
+	return err
+}
+
+// MongoSessions is the MongoDB implementation of SessionRepository.
+type MongoSessions struct {
+	Collection *mongo.Collection
+}
+
+// Create inserts a session and sets its ID.
+func (r *MongoSessions) Create(ctx context.Context, session *models.Session) error {
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	_, err := r.Collection.InsertOne(ctx, session)
+	return err
+}
+
+// FindByID returns a session.
+func (r *MongoSessions) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error) {
+	var session models.Session
+	if err := r.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&session); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Find returns the active sessions of a user.
+func (r *MongoSessions) Find(ctx context.Context, userID primitive.ObjectID, now time.Time) ([]models.Session, error) {
+	filter := bson.M{
+		"userId":     userID,
+		"expires_at": bson.M{"$gt": primitive.NewDateTimeFromTime(now)},
+		"revoked_at": bson.M{"$exists": false},
+	}
+	cursor, err := r.Collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []models.Session{}
+	if err = cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Revoke marks a session as revoked.
+func (r *MongoSessions) Revoke(ctx context.Context, userID, id primitive.ObjectID, at time.Time) error {
+	filter := bson.M{"_id": id, "userId": userID, "revoked_at": bson.M{"$exists": false}}
+	result, err := r.Collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revoked_at": primitive.NewDateTimeFromTime(at)}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Refresh rotates the refresh token of a session.
+func (r *MongoSessions) Refresh(ctx context.Context, id primitive.ObjectID, tokenHash, newHash string, expiresAt, now time.Time) (*models.Session, error) {
+	if tokenHash == "" {
+		return nil, ErrNotFound
+	}
+	filter := bson.M{
+		"_id":                id,
+		"refresh_token_hash": tokenHash,
+		"revoked_at":         bson.M{"$exists": false},
+		"expires_at":         bson.M{"$gt": primitive.NewDateTimeFromTime(now)},
+	}
+	update := bson.M{"$set": bson.M{
+		"refresh_token_hash":    newHash,
+		"previous_refresh_hash": tokenHash,
+		"refreshed_at":          primitive.NewDateTimeFromTime(now),
+		"expires_at":            primitive.NewDateTimeFromTime(expiresAt),
+	}}
+	var session models.Session
+	err := r.Collection.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// DeleteMany deletes the sessions of a user.
+func (r *MongoSessions) DeleteMany(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := r.Collection.DeleteMany(ctx, bson.M{"userId": userID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// MongoSignIns is the MongoDB implementation of SignInRepository.
+type MongoSignIns struct {
+	Collection *mongo.Collection
+}
+
+// Create inserts a sign-in and sets its ID.
+func (r *MongoSignIns) Create(ctx context.Context, signIn *models.SignIn) error {
+	if signIn.ID.IsZero() {
+		signIn.ID = primitive.NewObjectID()
+	}
+	_, err := r.Collection.InsertOne(ctx, signIn)
+	return err
+}
+
+// Find returns the latest sign-ins of a user.
+func (r *MongoSignIns) Find(ctx context.Context, userID primitive.ObjectID, limit int) ([]models.SignIn, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := r.Collection.Find(ctx, bson.M{"userId": userID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	signIns := []models.SignIn{}
+	if err = cursor.All(ctx, &signIns); err != nil {
+		return nil, err
+	}
+	return signIns, nil
+}
+
+// Exists reports whether a sign-in matches the query.
+func (r *MongoSignIns) Exists(ctx context.Context, query SignInQuery) (bool, error) {
+	filter := bson.M{"userId": query.UserID}
+	if query.Device != "" {
+		filter["device"] = query.Device
+	}
+	if query.Network != "" {
+		filter["network"] = query.Network
+	}
+	count, err := r.Collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+// DeleteMany deletes the sign-ins of a user.
+func (r *MongoSignIns) DeleteMany(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := r.Collection.DeleteMany(ctx, bson.M{"userId": userID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// MongoInvitations is the MongoDB implementation of InvitationRepository.
+type MongoInvitations struct {
+	Collection *mongo.Collection
+}
+
+// Create inserts an invitation and sets its ID.
+func (r *MongoInvitations) Create(ctx context.Context, invitation *models.Invitation) error {
+	if invitation.ID.IsZero() {
+		invitation.ID = primitive.NewObjectID()
+	}
+	_, err := r.Collection.InsertOne(ctx, invitation)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// FindByID returns an invitation.
+func (r *MongoInvitations) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Invitation, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+// FindByTokenHash returns the invitation with the given token hash.
+func (r *MongoInvitations) FindByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	return r.findOne(ctx, bson.M{"token_hash": tokenHash})
+}
+
+// Find returns the invitations to a project.
+func (r *MongoInvitations) Find(ctx context.Context, projectID primitive.ObjectID) ([]models.Invitation, error) {
+	cursor, err := r.Collection.Find(ctx, bson.M{"project_id": projectID}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := []models.Invitation{}
+	if err = cursor.All(ctx, &invitations); err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// Accept marks a pending invitation as accepted.
+func (r *MongoInvitations) Accept(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	filter := bson.M{
+		"_id":         id,
+		"expires_at":  bson.M{"$gt": primitive.NewDateTimeFromTime(at)},
+		"accepted_at": bson.M{"$exists": false},
+		"revoked_at":  bson.M{"$exists": false},
+	}
+	result, err := r.Collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"accepted_at": primitive.NewDateTimeFromTime(at)}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Revoke marks an invitation as revoked.
+func (r *MongoInvitations) Revoke(ctx context.Context, projectID, id primitive.ObjectID, at time.Time) error {
+	filter := bson.M{"_id": id, "project_id": projectID, "revoked_at": bson.M{"$exists": false}}
+	result, err := r.Collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revoked_at": primitive.NewDateTimeFromTime(at)}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteMany deletes the invitations to the projects of an owner.
+func (r *MongoInvitations) DeleteMany(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	result, err := r.Collection.DeleteMany(ctx, bson.M{"owner_id": ownerID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// findOne returns the invitation matching filter.
+func (r *MongoInvitations) findOne(ctx context.Context, filter bson.M) (*models.Invitation, error) {
+	var invitation models.Invitation
+	if err := r.Collection.FindOne(ctx, filter).Decode(&invitation); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// MongoInviteCodes is the MongoDB implementation of InviteCodeRepository.
+type MongoInviteCodes struct {
+	Collection *mongo.Collection
+}
+
+// Create inserts an invite code and sets its ID.
+func (r *MongoInviteCodes) Create(ctx context.Context, code *models.InviteCode) error {
+	if code.ID.IsZero() {
+		code.ID = primitive.NewObjectID()
+	}
+	_, err := r.Collection.InsertOne(ctx, code)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// Find returns all invite codes.
+func (r *MongoInviteCodes) Find(ctx context.Context) ([]models.InviteCode, error) {
+	cursor, err := r.Collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	codes := []models.InviteCode{}
+	if err = cursor.All(ctx, &codes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Redeem counts a use of a valid invite code in one update, so that concurrent sign-ups cannot use a code
+// more often than allowed.
+func (r *MongoInviteCodes) Redeem(ctx context.Context, codeHash string, at time.Time) (*models.InviteCode, error) {
+	filter := bson.M{
+		"code_hash":  codeHash,
+		"expires_at": bson.M{"$gt": primitive.NewDateTimeFromTime(at)},
+		"revoked_at": bson.M{"$exists": false},
+		"$expr":      bson.M{"$lt": bson.A{"$uses", "$max_uses"}},
+	}
+	var code models.InviteCode
+	err := r.Collection.FindOneAndUpdate(ctx, filter, bson.M{"$inc": bson.M{"uses": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&code)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// Release takes back a use of an invite code.
+func (r *MongoInviteCodes) Release(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.Collection.UpdateOne(ctx, bson.M{"_id": id, "uses": bson.M{"$gt": 0}}, bson.M{"$inc": bson.M{"uses": -1}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Revoke marks an invite code as revoked.
+func (r *MongoInviteCodes) Revoke(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	filter := bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}}
+	result, err := r.Collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revoked_at": primitive.NewDateTimeFromTime(at)}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MongoComments is the MongoDB implementation of CommentRepository.
+type MongoComments struct {
+	Collection *mongo.Collection
+}
+
+// Create inserts a comment and sets its ID.
+func (r *MongoComments) Create(ctx context.Context, comment *models.Comment) error {
+	if comment.ID.IsZero() {
+		comment.ID = primitive.NewObjectID()
+	}
+	_, err := r.Collection.InsertOne(ctx, comment)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// Find returns the comments on a task.
+func (r *MongoComments) Find(ctx context.Context, taskID primitive.ObjectID) ([]models.Comment, error) {
+	cursor, err := r.Collection.Find(ctx, bson.M{"task_id": taskID}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	comments := []models.Comment{}
+	if err = cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// CountByTasks returns the number of comments on each of the tasks, grouped on the task_id index.
+func (r *MongoComments) CountByTasks(ctx context.Context, taskIDs []primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	cursor, err := r.Collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"task_id": bson.M{"$in": taskIDs}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$task_id", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []struct {
+		TaskID primitive.ObjectID `bson:"_id"`
+		Count  int                `bson:"count"`
+	}
+	if err = cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+	counts := make(map[primitive.ObjectID]int, len(groups))
+	for _, group := range groups {
+		counts[group.TaskID] = group.Count
+	}
+	return counts, nil
+}
+
+// SetGitHubComment records the GitHub issue comment a comment was mirrored to.
+func (r *MongoComments) SetGitHubComment(ctx context.Context, id primitive.ObjectID, githubCommentID int64) error {
+	result, err := r.Collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"github_comment_id": githubCommentID}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteByTasks deletes the comments on the tasks.
+func (r *MongoComments) DeleteByTasks(ctx context.Context, taskIDs []primitive.ObjectID) (int64, error) {
+	if len(taskIDs) == 0 {
+		return 0, nil
+	}
+	result, err := r.Collection.DeleteMany(ctx, bson.M{"task_id": bson.M{"$in": taskIDs}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// DeleteMany deletes the comments on the tasks of an owner.
+func (r *MongoComments) DeleteMany(ctx context.Context, ownerID primitive.ObjectID) (int64, error) {
+	result, err := r.Collection.DeleteMany(ctx, bson.M{"owner_id": ownerID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// maintenanceID is the _id of the maintenance document in the settings collection.
+const maintenanceID = "maintenance"
+
+// MongoMaintenance is the MongoDB implementation of MaintenanceRepository, keeping the mode in a single
+// document of the settings collection.
+type MongoMaintenance struct {
+	Collection *mongo.Collection
+}
+
+// Get returns the maintenance mode.
+func (r *MongoMaintenance) Get(ctx context.Context) (models.Maintenance, error) {
+	maintenance := models.Maintenance{Mode: models.MaintenanceOff}
+	err := r.Collection.FindOne(ctx, bson.M{"_id": maintenanceID}).Decode(&maintenance)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return maintenance, err
+	}
+	return maintenance, nil
+}
+
+// Set replaces the maintenance mode.
+func (r *MongoMaintenance) Set(ctx context.Context, maintenance models.Maintenance) error {
+	_, err := r.Collection.ReplaceOne(ctx, bson.M{"_id": maintenanceID}, maintenance, options.Replace().SetUpsert(true))
+	return err
+}
+
+// MongoLeases is the MongoDB implementation of LeaseRepository, keeping one document per lease. Expiry is
+// computed with the clock of the MongoDB server, so the instances' clocks need not agree.
+type MongoLeases struct {
+	Collection *mongo.Collection
+}
+
+// Acquire takes or extends a lease unless another holder has it.
+func (r *MongoLeases) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	// Matches the lease if holder has it or it expired; otherwise the upsert collides with the
+	// existing document
+	filter := bson.M{"_id": name, "$or": bson.A{
+		bson.M{"holder": holder},
+		bson.M{"$expr": bson.M{"$lte": bson.A{"$expires_at", "$$NOW"}}},
+	}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"holder":     holder,
+		"expires_at": bson.M{"$add": bson.A{"$$NOW", ttl.Milliseconds()}},
+	}}}}
+	_, err := r.Collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Release gives up a lease of holder.
+func (r *MongoLeases) Release(ctx context.Context, name, holder string) error {
+	_, err := r.Collection.DeleteOne(ctx, bson.M{"_id": name, "holder": holder})
+	return err
+}
+
+// MongoNonces is the MongoDB implementation of NonceRepository, keeping one document per value, removed by
+// a TTL index once it expires.
+type MongoNonces struct {
+	Collection *mongo.Collection
+}
+
+// Use records a value as used unless it already is. The insert is atomic, so of concurrent uses of the
+// same value only one succeeds.
+func (r *MongoNonces) Use(ctx context.Context, value string, expiresAt time.Time) error {
+	_, err := r.Collection.InsertOne(ctx, bson.M{"_id": value, "expires_at": primitive.NewDateTimeFromTime(expiresAt)})
+	if !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	// The TTL monitor removes expired documents only once a minute; reuse a value that has expired
+	result, err := r.Collection.UpdateOne(ctx,
+		bson.M{"_id": value, "expires_at": bson.M{"$lte": primitive.NewDateTimeFromTime(time.Now())}},
+		bson.M{"$set": bson.M{"expires_at": primitive.NewDateTimeFromTime(expiresAt)}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrDuplicate
+	}
+	return nil
+}
+
+// MongoAuditLog is the MongoDB implementation of AuditLogRepository, on the collection of the mongo audit
+// sink.
+type MongoAuditLog struct {
+	Collection *mongo.Collection
+}
+
+// Create inserts an entry and sets its ID.
+func (r *MongoAuditLog) Create(ctx context.Context, entry *audit.Entry) error {
+	entry.ID = primitive.NewObjectID()
+	_, err := r.Collection.InsertOne(ctx, entry)
+	return err
+}
+
+// Update replaces a stored entry.
+func (r *MongoAuditLog) Update(ctx context.Context, entry *audit.Entry) error {
+	result, err := r.Collection.ReplaceOne(ctx, bson.M{"_id": entry.ID}, entry)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// FindByUser returns the entries of a user, newest first.
+func (r *MongoAuditLog) FindByUser(ctx context.Context, userID string) ([]audit.Entry, error) {
+	cursor, err := r.Collection.Find(ctx, bson.M{"user_id": userID}, options.Find().SetSort(bson.D{{Key: "time", Value: -1}, {Key: "_id", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []audit.Entry{}
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// MongoOutbox is the MongoDB implementation of OutboxRepository.
+type MongoOutbox struct {
+	Collection *mongo.Collection
+}
+
+// Add stores messages.
+func (r *MongoOutbox) Add(ctx context.Context, messages ...models.OutboxMessage) error {
+	docs := make([]interface{}, len(messages))
+	for i, message := range messages {
+		docs[i] = message
+	}
+	_, err := r.Collection.InsertMany(ctx, docs)
+	return err
+}
+
+// Due returns the messages due at now, oldest first.
+func (r *MongoOutbox) Due(ctx context.Context, now time.Time, limit int) ([]models.OutboxMessage, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := r.Collection.Find(ctx, bson.M{"next_attempt_at": bson.M{"$lte": primitive.NewDateTimeFromTime(now)}}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []models.OutboxMessage{}
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// Retry records a failed attempt.
+func (r *MongoOutbox) Retry(ctx context.Context, id primitive.ObjectID, attempts int, next time.Time, lastError string) error {
+	result, err := r.Collection.UpdateByID(ctx, id, bson.M{"$set": bson.M{
+		"attempts":        attempts,
+		"next_attempt_at": primitive.NewDateTimeFromTime(next),
+		"last_error":      lastError,
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes a message.
+func (r *MongoOutbox) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.Collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// MongoWebhooks is the MongoDB implementation of WebhookRepository.
+type MongoWebhooks struct {
+	Collection *mongo.Collection
+}
+
+// Create inserts a webhook and sets its ID.
+func (r *MongoWebhooks) Create(ctx context.Context, webhook *models.Webhook) error {
+	if webhook.ID.IsZero() {
+		webhook.ID = primitive.NewObjectID()
+	}
+	_, err := r.Collection.InsertOne(ctx, webhook)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// Find returns all webhooks.
+func (r *MongoWebhooks) Find(ctx context.Context) ([]models.Webhook, error) {
+	cursor, err := r.Collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := []models.Webhook{}
+	if err = cursor.All(ctx, &webhooks); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// FindByID returns a webhook by ID.
+func (r *MongoWebhooks) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := r.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&webhook)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// Delete deletes a webhook.
+func (r *MongoWebhooks) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.Collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MongoWebhookDeliveries is the MongoDB implementation of WebhookDeliveryRepository.
+type MongoWebhookDeliveries struct {
+	Collection *mongo.Collection
+}
+
+// Create inserts a delivery and sets its ID.
+func (r *MongoWebhookDeliveries) Create(ctx context.Context, delivery *models.WebhookDelivery) error {
+	if delivery.ID.IsZero() {
+		delivery.ID = primitive.NewObjectID()
+	}
+	_, err := r.Collection.InsertOne(ctx, delivery)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// Find returns the latest deliveries to a webhook.
+func (r *MongoWebhookDeliveries) Find(ctx context.Context, webhookID primitive.ObjectID, limit int) ([]models.WebhookDelivery, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := r.Collection.Find(ctx, bson.M{"webhook_id": webhookID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	if err = cursor.All(ctx, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// FindByID returns a delivery to a webhook by ID.
+func (r *MongoWebhookDeliveries) FindByID(ctx context.Context, webhookID, id primitive.ObjectID) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.Collection.FindOne(ctx, bson.M{"_id": id, "webhook_id": webhookID}).Decode(&delivery)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// AddAttempt records an attempt of a delivery.
+func (r *MongoWebhookDeliveries) AddAttempt(ctx context.Context, id primitive.ObjectID, attempt models.WebhookAttempt, status string) error {
+	result, err := r.Collection.UpdateByID(ctx, id, bson.M{
+		"$push": bson.M{"attempts": attempt},
+		"$set":  bson.M{"status": status},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteMany deletes the deliveries to a webhook.
+func (r *MongoWebhookDeliveries) DeleteMany(ctx context.Context, webhookID primitive.ObjectID) (int64, error) {
+	result, err := r.Collection.DeleteMany(ctx, bson.M{"webhook_id": webhookID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// MongoTombstones is the MongoDB implementation of TombstoneRepository.
+type MongoTombstones struct {
+	Collection *mongo.Collection
+}
+
+// Create inserts a tombstone and sets its ID and deletion time.
+func (r *MongoTombstones) Create(ctx context.Context, tombstone *models.Tombstone) error {
+	if tombstone.ID.IsZero() {
+		tombstone.ID = primitive.NewObjectID()
+	}
+	tombstone.DeletedAt = models.NewTimestamp(time.Now())
+	_, err := r.Collection.InsertOne(ctx, tombstone)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// Find returns the tombstones of a user's tasks after the cursor.
+func (r *MongoTombstones) Find(ctx context.Context, userID primitive.ObjectID, after *pagination.Cursor, limit int) ([]models.Tombstone, error) {
+	filter := pagination.Filter(TombstoneSort, after)
+	filter["userId"] = userID
+
+	cursor, err := r.Collection.Find(ctx, filter, pagination.FindOptions(TombstoneSort, limit))
+	if err != nil {
+		return nil, err
+	}
+
+	tombstones := []models.Tombstone{}
+	if err = cursor.All(ctx, &tombstones); err != nil {
+		return nil, err
+	}
+	return tombstones, nil
+}
+
+// DeleteBefore deletes the tombstones of tasks deleted before a time.
+func (r *MongoTombstones) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.Collection.DeleteMany(ctx, bson.M{"deleted_at": bson.M{"$lt": primitive.NewDateTimeFromTime(before)}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// DeleteByTask deletes the tombstones of a task.
+func (r *MongoTombstones) DeleteByTask(ctx context.Context, taskID primitive.ObjectID) error {
+	_, err := r.Collection.DeleteMany(ctx, bson.M{"task_id": taskID})
+	return err
+}
+
+// MongoReportSchedules is the MongoDB implementation of ReportScheduleRepository.
+type MongoReportSchedules struct {
+	Collection *mongo.Collection
+}
+
+// Create inserts a schedule and sets its ID and timestamps.
+func (r *MongoReportSchedules) Create(ctx context.Context, schedule *models.ReportSchedule) error {
+	if schedule.ID.IsZero() {
+		schedule.ID = primitive.NewObjectID()
+	}
+	schedule.CreatedAt = models.NewTimestamp(time.Now())
+	schedule.UpdatedAt = schedule.CreatedAt
+	_, err := r.Collection.InsertOne(ctx, schedule)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// Find returns the schedules of a user.
+func (r *MongoReportSchedules) Find(ctx context.Context, userID primitive.ObjectID) ([]models.ReportSchedule, error) {
+	return r.find(ctx, bson.M{"userId": userID}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+}
+
+// FindByID returns a schedule of a user by ID.
+func (r *MongoReportSchedules) FindByID(ctx context.Context, userID, id primitive.ObjectID) (*models.ReportSchedule, error) {
+	var schedule models.ReportSchedule
+	err := r.Collection.FindOne(ctx, bson.M{"_id": id, "userId": userID}).Decode(&schedule)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// Due returns the schedules whose next run is due.
+func (r *MongoReportSchedules) Due(ctx context.Context, now time.Time, limit int) ([]models.ReportSchedule, error) {
+	filter := bson.M{"next_run_at": bson.M{"$gt": primitive.DateTime(0), "$lte": primitive.NewDateTimeFromTime(now)}}
+	opts := options.Find().SetSort(bson.D{{Key: "next_run_at", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	return r.find(ctx, filter, opts)
+}
+
+// Update replaces a schedule.
+func (r *MongoReportSchedules) Update(ctx context.Context, schedule *models.ReportSchedule) error {
+	schedule.UpdatedAt = models.NewTimestamp(time.Now())
+	result, err := r.Collection.ReplaceOne(ctx, bson.M{"_id": schedule.ID, "userId": schedule.UserID}, schedule)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete deletes a schedule of a user.
+func (r *MongoReportSchedules) Delete(ctx context.Context, userID, id primitive.ObjectID) error {
+	result, err := r.Collection.DeleteOne(ctx, bson.M{"_id": id, "userId": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteMany deletes the schedules of a user.
+func (r *MongoReportSchedules) DeleteMany(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := r.Collection.DeleteMany(ctx, bson.M{"userId": userID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// find returns the schedules matching filter.
+func (r *MongoReportSchedules) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]models.ReportSchedule, error) {
+	cursor, err := r.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := []models.ReportSchedule{}
+	if err = cursor.All(ctx, &schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// MongoSubscriptions is the MongoDB implementation of SubscriptionRepository.
+type MongoSubscriptions struct {
+	Collection *mongo.Collection
+}
+
+// FindByWorkspace returns the subscription of a workspace.
+func (r *MongoSubscriptions) FindByWorkspace(ctx context.Context, workspaceID primitive.ObjectID) (*models.Subscription, error) {
+	return r.findOne(ctx, bson.M{"workspace_id": workspaceID})
+}
+
+// FindByCustomer returns the subscription of a Stripe customer.
+func (r *MongoSubscriptions) FindByCustomer(ctx context.Context, customerID string) (*models.Subscription, error) {
+	if customerID == "" {
+		return nil, ErrNotFound
+	}
+	return r.findOne(ctx, bson.M{"stripe_customer_id": customerID})
+}
+
+// Save inserts or replaces the subscription of a workspace, keeping the ID of a stored one.
+func (r *MongoSubscriptions) Save(ctx context.Context, subscription *models.Subscription) error {
+	if existing, err := r.FindByWorkspace(ctx, subscription.WorkspaceID); err == nil {
+		subscription.ID = existing.ID
+	} else if err != ErrNotFound {
+		return err
+	} else if subscription.ID.IsZero() {
+		subscription.ID = primitive.NewObjectID()
+	}
+	subscription.UpdatedAt = models.NewTimestamp(time.Now())
+	_, err := r.Collection.ReplaceOne(ctx, bson.M{"workspace_id": subscription.WorkspaceID}, subscription, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *MongoSubscriptions) findOne(ctx context.Context, filter bson.M) (*models.Subscription, error) {
+	var subscription models.Subscription
+	err := r.Collection.FindOne(ctx, filter).Decode(&subscription)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// MongoTransactions is the MongoDB implementation of Transactor. Transactions need a replica set or a
+// sharded cluster.
+type MongoTransactions struct {
+	Client *mongo.Client
+}
+
+// InTransaction runs fn in a transaction, retrying it on transient errors as the driver recommends.
+func (t *MongoTransactions) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := t.Client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessionCtx)
+	})
+	return err
+}
+
+// MongoWorkspaces is the MongoDB implementation of WorkspaceRepository.
+type MongoWorkspaces struct {
+	Collection *mongo.Collection
+}
+
+// Create inserts a workspace and sets its ID and created_at.
+func (r *MongoWorkspaces) Create(ctx context.Context, workspace *models.Workspace) error {
+	if workspace.ID.IsZero() {
+		workspace.ID = primitive.NewObjectID()
+	}
+	workspace.CreatedAt = primitive.NewDateTimeFromTime(time.Now())
+	_, err := r.Collection.InsertOne(ctx, workspace)
+	return err
+}
+
+// FindByID returns a workspace by ID.
+func (r *MongoWorkspaces) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Workspace, error) {
+	var workspace models.Workspace
+	err := r.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&workspace)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+// UpdateBranding replaces the branding of a workspace.
+func (r *MongoWorkspaces) UpdateBranding(ctx context.Context, id primitive.ObjectID, branding models.Branding) error {
+	return r.update(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"branding": branding}})
+}
+
+// Invite adds a user to the invited users of a workspace.
+func (r *MongoWorkspaces) Invite(ctx context.Context, id, userID primitive.ObjectID) error {
+	return r.update(ctx, bson.M{"_id": id}, bson.M{"$addToSet": bson.M{"invited": userID}})
+}
+
+// Uninvite removes a user from the invited users of a workspace.
+func (r *MongoWorkspaces) Uninvite(ctx context.Context, id, userID primitive.ObjectID) error {
+	return r.update(ctx, bson.M{"_id": id, "invited": userID}, bson.M{"$pull": bson.M{"invited": userID}})
+}
+
+// update applies an update to the workspace matching the filter and returns ErrNotFound if none matches.
+func (r *MongoWorkspaces) update(ctx context.Context, filter, update bson.M) error {
+	result, err := r.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MongoFocusSessions is the MongoDB implementation of FocusSessionRepository. A unique partial index on
+// the running sessions (migration 28) keeps users to one running session.
+type MongoFocusSessions struct {
+	Collection *mongo.Collection
+}
+
+// Create inserts a running session and sets its ID.
+func (r *MongoFocusSessions) Create(ctx context.Context, session *models.FocusSession) error {
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	_, err := r.Collection.InsertOne(ctx, session)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// FindByID returns a session of a user by ID.
+func (r *MongoFocusSessions) FindByID(ctx context.Context, userID, id primitive.ObjectID) (*models.FocusSession, error) {
+	return r.findOne(ctx, bson.M{"_id": id, "userId": userID})
+}
+
+// FindRunning returns the running session of a user.
+func (r *MongoFocusSessions) FindRunning(ctx context.Context, userID primitive.ObjectID) (*models.FocusSession, error) {
+	return r.findOne(ctx, bson.M{"userId": userID, "ended_at": primitive.DateTime(0)})
+}
+
+// Find returns the sessions matching the query.
+func (r *MongoFocusSessions) Find(ctx context.Context, query FocusSessionQuery) ([]models.FocusSession, error) {
+	filter := bson.M{}
+	if !query.UserID.IsZero() {
+		filter["userId"] = query.UserID
+	}
+	if query.TaskIDs != nil {
+		filter["task_id"] = bson.M{"$in": query.TaskIDs}
+	}
+	started := bson.M{}
+	if !query.StartedAfter.IsZero() {
+		started["$gte"] = primitive.NewDateTimeFromTime(query.StartedAfter)
+	}
+	if !query.StartedBefore.IsZero() {
+		started["$lt"] = primitive.NewDateTimeFromTime(query.StartedBefore)
+	}
+	if len(started) > 0 {
+		filter["started_at"] = started
+	}
+
+	cursor, err := r.Collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "started_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	sessions := []models.FocusSession{}
+	if err = cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// UpdateRunning replaces a running session.
+func (r *MongoFocusSessions) UpdateRunning(ctx context.Context, session *models.FocusSession) error {
+	filter := bson.M{"_id": session.ID, "userId": session.UserID, "ended_at": primitive.DateTime(0)}
+	result, err := r.Collection.ReplaceOne(ctx, filter, session)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteMany deletes the sessions of a user.
+func (r *MongoFocusSessions) DeleteMany(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := r.Collection.DeleteMany(ctx, bson.M{"userId": userID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// findOne returns the session matching filter.
+func (r *MongoFocusSessions) findOne(ctx context.Context, filter bson.M) (*models.FocusSession, error) {
+	var session models.FocusSession
+	err := r.Collection.FindOne(ctx, filter).Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
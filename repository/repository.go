@@ -0,0 +1,465 @@
+// repository.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bkojha74/task-management/audit"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/pagination"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Errors returned by all repository implementations
+var (
+	ErrNotFound  = errors.New("not found")
+	ErrDuplicate = errors.New("already exists")
+)
+
+// UserRepository stores user accounts.
+type UserRepository interface {
+	// Create inserts a user and sets its ID, created_at and updated_at. It returns ErrDuplicate if the username
+	// or subject is taken.
+	Create(ctx context.Context, user *models.User) error
+	// FindByID returns the user with the given ID, or ErrNotFound.
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error)
+	// FindByUsername returns the user with the given username, or ErrNotFound.
+	FindByUsername(ctx context.Context, username string) (*models.User, error)
+	// FindBySubject returns the user linked to the given directory subject, or ErrNotFound.
+	FindBySubject(ctx context.Context, subject string) (*models.User, error)
+	// FindByTelegramChat returns the user who linked the given Telegram chat, or ErrNotFound.
+	FindByTelegramChat(ctx context.Context, chatID int64) (*models.User, error)
+	// Update replaces a stored user and sets its updated_at. It returns ErrNotFound if the user does not exist
+	// and ErrDuplicate if the username or subject is taken.
+	Update(ctx context.Context, user *models.User) error
+	// FindByWorkspace returns the members of a workspace.
+	FindByWorkspace(ctx context.Context, workspaceID primitive.ObjectID) ([]models.User, error)
+	// FindDeletionDue returns the users whose delete_at is set and before the given time.
+	FindDeletionDue(ctx context.Context, before time.Time) ([]models.User, error)
+	// Delete deletes a user. It returns ErrNotFound if the user does not exist.
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// List returns up to limit users in the order of their IDs, after skipping offset of them, and the number
+	// of users in total.
+	List(ctx context.Context, offset, limit int) ([]models.User, int64, error)
+}
+
+// TaskQuery selects tasks. Zero fields don't restrict the result.
+type TaskQuery struct {
+	UserID            primitive.ObjectID   // Owner of the tasks
+	ProjectID         primitive.ObjectID   // Project the tasks belong to
+	AllottedTo        string               // Username the tasks are allotted to
+	Statuses          []string             // Any of these statuses
+	ExcludeStatus     string               // Any status but this one
+	ExcludeVisibility string               // Any visibility but this one, e.g. to hide private tasks from guests
+	Text              string               // All words of this text in the title, description or assignee
+	ExcludeID         primitive.ObjectID   // Skip this task, e.g. the one being updated
+	IDs               []primitive.ObjectID // Any of these tasks
+	DueBefore         time.Time            // End date before this time
+	HasDueDate        bool                 // Only tasks with an end date
+	Overdue           *bool                // Only tasks whose overdue flag has this value
+	CreatedAfter      time.Time            // Created at or after this time
+	CreatedBefore     time.Time            // Created before this time
+	UpdatedAfter      time.Time            // Last written at or after this time
+	UpdatedBefore     time.Time            // Last written before this time
+	CompletedAfter    time.Time            // Completed after this time, e.g. to poll for completions
+	CompletedBefore   time.Time            // Completed before this time
+	Archived          *bool                // Only tasks whose archived state is this value
+	HasGitHubIssue    bool                 // Only tasks linked to a GitHub issue
+	GitHubRepo        string               // Only tasks linked to an issue of this repository, "owner/name" in lower case
+	GitHubIssue       int                  // With GitHubRepo, only tasks linked to this issue number
+	CalDAVName        string               // Only the task a CalDAV client created under this resource name
+	Fields            []string             // Only load these fields, plus _id and the sort field; all fields when empty
+
+	Sort  pagination.Sort    // Result order, _id ascending by default
+	After *pagination.Cursor // Only tasks after this cursor in Sort order
+	Limit int                // Page size; a positive limit returns up to Limit+1 tasks to detect another page
+}
+
+// TaskRepository stores tasks.
+type TaskRepository interface {
+	// Create inserts a task and sets its created_at and updated_at.
+	Create(ctx context.Context, task *models.Task) error
+	// Find returns the tasks matching the query in query.Sort order.
+	Find(ctx context.Context, query TaskQuery) ([]models.Task, error)
+	// FindEntries returns the tasks matching the query like Find, each with the summary of its assignee
+	// and its comment count, joined in one query.
+	FindEntries(ctx context.Context, query TaskQuery) ([]models.TaskListEntry, error)
+	// FindByID returns a task of the given owner, or ErrNotFound.
+	FindByID(ctx context.Context, userID, taskID primitive.ObjectID) (*models.Task, error)
+	// Update replaces a task of task.UserID if its stored version is expectedVersion (0 also matches tasks
+	// without a version). It sets updated_at and keeps the stored created_at, whatever task carries. It
+	// returns ErrNotFound if no such task exists, e.g. because of a newer version.
+	Update(ctx context.Context, task *models.Task, expectedVersion int) error
+	// Delete deletes a task of the given owner. It returns ErrNotFound if the task does not exist.
+	Delete(ctx context.Context, userID, taskID primitive.ObjectID) error
+	// Count returns the number of tasks matching the query; sort and pagination fields are ignored.
+	Count(ctx context.Context, query TaskQuery) (int64, error)
+	// DeleteMany deletes the tasks matching the query and returns how many were deleted.
+	DeleteMany(ctx context.Context, query TaskQuery) (int64, error)
+	// Trash moves a task of the given owner to the trash, as stored, and sets its deletion time. It returns
+	// ErrNotFound if the task does not exist.
+	Trash(ctx context.Context, userID, taskID primitive.ObjectID) error
+	// FindTrashed returns the trashed tasks of an owner, the most recently deleted first.
+	FindTrashed(ctx context.Context, userID primitive.ObjectID) ([]models.TrashedTask, error)
+	// Restore moves a trashed task of the given owner back to the tasks and sets its updated_at. It returns
+	// ErrNotFound if the trash has no such task and ErrDuplicate if the task exists again.
+	Restore(ctx context.Context, userID, taskID primitive.ObjectID) (*models.Task, error)
+	// CountTrashed returns the number of tasks in the trash deleted before a time.
+	CountTrashed(ctx context.Context, before time.Time) (int64, error)
+	// PurgeTrash deletes the trashed tasks deleted before a time, of one owner or of all owners when userID
+	// is zero, and returns the IDs of the deleted tasks.
+	PurgeTrash(ctx context.Context, userID primitive.ObjectID, before time.Time) ([]primitive.ObjectID, error)
+}
+
+// TaskViewRepository stores the denormalized task list-view documents.
+type TaskViewRepository interface {
+	// Upsert writes a view, keeping the stored comment and attachment counts of an existing view.
+	Upsert(ctx context.Context, view models.TaskListView) error
+	// Delete removes the view of a task; removing a missing view is not an error.
+	Delete(ctx context.Context, taskID primitive.ObjectID) error
+	// AdjustCounts adds the deltas to the comment and attachment counts of a view.
+	AdjustCounts(ctx context.Context, taskID primitive.ObjectID, comments, attachments int) error
+	// Find returns one page of an owner's views; a positive limit returns up to limit+1 views.
+	Find(ctx context.Context, userID primitive.ObjectID, sort pagination.Sort, after *pagination.Cursor, limit int) ([]models.TaskListView, error)
+}
+
+// ProjectRepository stores projects.
+type ProjectRepository interface {
+	// Create inserts a project and sets its ID.
+	Create(ctx context.Context, project *models.Project) error
+	// FindByID returns a project of the given owner, or ErrNotFound.
+	FindByID(ctx context.Context, ownerID, projectID primitive.ObjectID) (*models.Project, error)
+	// Find returns the projects of an owner, oldest first.
+	Find(ctx context.Context, ownerID primitive.ObjectID) ([]models.Project, error)
+	// DeleteMany deletes the projects of an owner and returns how many were deleted.
+	DeleteMany(ctx context.Context, ownerID primitive.ObjectID) (int64, error)
+	// SetAssignment sets the assignment rule of a project of the given owner, or removes it when rule is
+	// nil. It returns ErrNotFound if the project does not exist.
+	SetAssignment(ctx context.Context, ownerID, projectID primitive.ObjectID, rule *models.AssignmentRule) error
+	// NextAssignment takes a round-robin turn of a project's assignment rule and returns the number of turns
+	// taken before it. It returns ErrNotFound if the project has no assignment rule.
+	NextAssignment(ctx context.Context, projectID primitive.ObjectID) (int, error)
+	// ReturnAssignment gives back the turn NextAssignment returned, if it is still the last one taken. It
+	// does nothing if a later turn was taken or the rule was removed meanwhile.
+	ReturnAssignment(ctx context.Context, projectID primitive.ObjectID, turn int) error
+}
+
+// SessionRepository stores the sign-in sessions of users.
+type SessionRepository interface {
+	// Create inserts a session and sets its ID.
+	Create(ctx context.Context, session *models.Session) error
+	// FindByID returns a session, or ErrNotFound.
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error)
+	// Find returns the sessions of a user that are neither revoked nor expired at now, newest first.
+	Find(ctx context.Context, userID primitive.ObjectID, now time.Time) ([]models.Session, error)
+	// Revoke marks a session of the given user as revoked at the given time. It returns ErrNotFound if the
+	// user has no such session or it is already revoked.
+	Revoke(ctx context.Context, userID, id primitive.ObjectID, at time.Time) error
+	// Refresh rotates the refresh token of a session from the token with tokenHash to the one with newHash,
+	// sets its expiry to expiresAt and returns the session. It returns ErrNotFound unless the session holds
+	// that token and is neither revoked nor expired at now, so that each refresh token is used once.
+	Refresh(ctx context.Context, id primitive.ObjectID, tokenHash, newHash string, expiresAt, now time.Time) (*models.Session, error)
+	// DeleteMany deletes the sessions of a user and returns how many were deleted.
+	DeleteMany(ctx context.Context, userID primitive.ObjectID) (int64, error)
+}
+
+// SignInQuery selects sign-ins of a user; an empty Device or Network matches any.
+type SignInQuery struct {
+	UserID  primitive.ObjectID
+	Device  string
+	Network string
+}
+
+// SignInRepository stores the sign-in history of users.
+type SignInRepository interface {
+	// Create inserts a sign-in and sets its ID.
+	Create(ctx context.Context, signIn *models.SignIn) error
+	// Find returns the latest sign-ins of a user, newest first, at most limit of them.
+	Find(ctx context.Context, userID primitive.ObjectID, limit int) ([]models.SignIn, error)
+	// Exists reports whether a sign-in matches the query.
+	Exists(ctx context.Context, query SignInQuery) (bool, error)
+	// DeleteMany deletes the sign-ins of a user and returns how many were deleted.
+	DeleteMany(ctx context.Context, userID primitive.ObjectID) (int64, error)
+}
+
+// InvitationRepository stores the invitations of guests to projects.
+type InvitationRepository interface {
+	// Create inserts an invitation and sets its ID.
+	Create(ctx context.Context, invitation *models.Invitation) error
+	// FindByID returns an invitation, or ErrNotFound.
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Invitation, error)
+	// FindByTokenHash returns the invitation with the given token hash, or ErrNotFound.
+	FindByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error)
+	// Find returns the invitations to a project, oldest first.
+	Find(ctx context.Context, projectID primitive.ObjectID) ([]models.Invitation, error)
+	// Accept marks an invitation as accepted at the given time. It returns ErrNotFound if the invitation
+	// is already accepted, revoked or expired at that time.
+	Accept(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	// Revoke marks an invitation to the given project as revoked at the given time. It returns ErrNotFound
+	// if the project has no such invitation or it is already revoked.
+	Revoke(ctx context.Context, projectID, id primitive.ObjectID, at time.Time) error
+	// DeleteMany deletes the invitations to the projects of an owner and returns how many were deleted.
+	DeleteMany(ctx context.Context, ownerID primitive.ObjectID) (int64, error)
+}
+
+// InviteCodeRepository stores the invite codes of invitation-only registration.
+type InviteCodeRepository interface {
+	// Create inserts an invite code and sets its ID.
+	Create(ctx context.Context, code *models.InviteCode) error
+	// Find returns all invite codes, oldest first.
+	Find(ctx context.Context) ([]models.InviteCode, error)
+	// Redeem counts a use of the invite code with the given hash and returns the code. It returns
+	// ErrNotFound if there is no such code or it is used up, expired or revoked at the given time.
+	Redeem(ctx context.Context, codeHash string, at time.Time) (*models.InviteCode, error)
+	// Release takes back a use counted by Redeem, e.g. when the sign-up failed after all.
+	Release(ctx context.Context, id primitive.ObjectID) error
+	// Revoke marks an invite code as revoked at the given time. It returns ErrNotFound if there is no such
+	// code or it is already revoked.
+	Revoke(ctx context.Context, id primitive.ObjectID, at time.Time) error
+}
+
+// CommentRepository stores the comments on tasks.
+type CommentRepository interface {
+	// Create inserts a comment and sets its ID.
+	Create(ctx context.Context, comment *models.Comment) error
+	// Find returns the comments on a task, oldest first.
+	Find(ctx context.Context, taskID primitive.ObjectID) ([]models.Comment, error)
+	// CountByTasks returns the number of comments on each of the tasks; tasks without comments are left out.
+	CountByTasks(ctx context.Context, taskIDs []primitive.ObjectID) (map[primitive.ObjectID]int, error)
+	// SetGitHubComment records the GitHub issue comment a comment was mirrored to.
+	SetGitHubComment(ctx context.Context, id primitive.ObjectID, githubCommentID int64) error
+	// DeleteByTasks deletes the comments on the tasks and returns how many were deleted.
+	DeleteByTasks(ctx context.Context, taskIDs []primitive.ObjectID) (int64, error)
+	// DeleteMany deletes the comments on the tasks of an owner and returns how many were deleted.
+	DeleteMany(ctx context.Context, ownerID primitive.ObjectID) (int64, error)
+}
+
+// MaintenanceRepository stores the maintenance mode shared by all instances.
+type MaintenanceRepository interface {
+	// Get returns the maintenance mode; it is off if it was never set.
+	Get(ctx context.Context) (models.Maintenance, error)
+	// Set replaces the maintenance mode.
+	Set(ctx context.Context, maintenance models.Maintenance) error
+}
+
+// LeaseRepository grants time-limited exclusive leases, e.g. so that only one of several instances runs
+// a background job.
+type LeaseRepository interface {
+	// Acquire takes the named lease for holder until ttl from now, or extends it if holder already has it.
+	// It returns false if another holder has an unexpired lease.
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	// Release gives up the named lease if holder has it, so another holder can take it right away.
+	Release(ctx context.Context, name, holder string) error
+}
+
+// NonceRepository remembers single-use values until they expire, e.g. the IDs of SAML requests and
+// assertions, so that each is accepted once.
+type NonceRepository interface {
+	// Use records a value as used until expiresAt. It returns ErrDuplicate if the value was used before and
+	// has not expired.
+	Use(ctx context.Context, value string, expiresAt time.Time) error
+}
+
+// OutboxRepository stores the events and notifications waiting to be delivered by the outbox relay.
+type OutboxRepository interface {
+	// Add stores messages.
+	Add(ctx context.Context, messages ...models.OutboxMessage) error
+	// Due returns up to limit messages whose next attempt is due at now, oldest first.
+	Due(ctx context.Context, now time.Time, limit int) ([]models.OutboxMessage, error)
+	// Retry records a failed attempt and when to make the next one.
+	Retry(ctx context.Context, id primitive.ObjectID, attempts int, next time.Time, lastError string) error
+	// Delete removes a delivered or dead-lettered message.
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+// WebhookRepository stores the webhooks registered by admins.
+type WebhookRepository interface {
+	// Create inserts a webhook and sets its ID.
+	Create(ctx context.Context, webhook *models.Webhook) error
+	// Find returns all webhooks, oldest first.
+	Find(ctx context.Context) ([]models.Webhook, error)
+	// FindByID returns the webhook with the given ID, or ErrNotFound.
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Webhook, error)
+	// Delete deletes a webhook. It returns ErrNotFound if there is no such webhook.
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+// WebhookDeliveryRepository stores the deliveries of events to webhooks with their attempts.
+type WebhookDeliveryRepository interface {
+	// Create inserts a delivery and sets its ID.
+	Create(ctx context.Context, delivery *models.WebhookDelivery) error
+	// Find returns up to limit deliveries to a webhook, newest first.
+	Find(ctx context.Context, webhookID primitive.ObjectID, limit int) ([]models.WebhookDelivery, error)
+	// FindByID returns a delivery to the given webhook, or ErrNotFound.
+	FindByID(ctx context.Context, webhookID, id primitive.ObjectID) (*models.WebhookDelivery, error)
+	// AddAttempt records an attempt of a delivery and sets its status. It returns ErrNotFound if there is
+	// no such delivery.
+	AddAttempt(ctx context.Context, id primitive.ObjectID, attempt models.WebhookAttempt, status string) error
+	// DeleteMany deletes the deliveries to a webhook and returns how many were deleted.
+	DeleteMany(ctx context.Context, webhookID primitive.ObjectID) (int64, error)
+}
+
+// TombstoneSort is the order TombstoneRepository.Find returns tombstones in, for the cursors of its pages.
+var TombstoneSort = pagination.Sort{Field: "deleted_at"}
+
+// TombstoneRepository stores the tombstones of deleted tasks that offline clients sync.
+type TombstoneRepository interface {
+	// Create inserts a tombstone and sets its ID and deletion time.
+	Create(ctx context.Context, tombstone *models.Tombstone) error
+	// Find returns the tombstones of a user's tasks ordered by deleted_at, then _id. Only those after the
+	// cursor are returned, and a positive limit returns up to limit+1 to detect another page.
+	Find(ctx context.Context, userID primitive.ObjectID, after *pagination.Cursor, limit int) ([]models.Tombstone, error)
+	// DeleteBefore deletes the tombstones of tasks deleted before a time and returns how many were deleted.
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+	// DeleteByTask deletes the tombstones of a task, e.g. once it is restored; deleting none is not an error.
+	DeleteByTask(ctx context.Context, taskID primitive.ObjectID) error
+}
+
+// ReportScheduleRepository stores the reports users have scheduled to be emailed.
+type ReportScheduleRepository interface {
+	// Create inserts a schedule and sets its ID and timestamps.
+	Create(ctx context.Context, schedule *models.ReportSchedule) error
+	// Find returns the schedules of a user, oldest first.
+	Find(ctx context.Context, userID primitive.ObjectID) ([]models.ReportSchedule, error)
+	// FindByID returns a schedule of a user, or ErrNotFound.
+	FindByID(ctx context.Context, userID, id primitive.ObjectID) (*models.ReportSchedule, error)
+	// Due returns up to limit schedules whose next run is at or before now, the longest due first.
+	Due(ctx context.Context, now time.Time, limit int) ([]models.ReportSchedule, error)
+	// Update replaces a schedule of schedule.UserID and sets its updated_at. It returns ErrNotFound if there
+	// is no such schedule.
+	Update(ctx context.Context, schedule *models.ReportSchedule) error
+	// Delete deletes a schedule of a user. It returns ErrNotFound if there is no such schedule.
+	Delete(ctx context.Context, userID, id primitive.ObjectID) error
+	// DeleteMany deletes the schedules of a user and returns how many were deleted.
+	DeleteMany(ctx context.Context, userID primitive.ObjectID) (int64, error)
+}
+
+// FocusSessionQuery selects focus sessions. Zero fields don't restrict the result.
+type FocusSessionQuery struct {
+	UserID        primitive.ObjectID   // User who focused
+	TaskIDs       []primitive.ObjectID // Sessions on any of these tasks
+	StartedAfter  time.Time            // Started at or after this time
+	StartedBefore time.Time            // Started before this time
+}
+
+// FocusSessionRepository stores the focus sessions of users.
+type FocusSessionRepository interface {
+	// Create inserts a running session and sets its ID. It returns ErrDuplicate if the user already has a
+	// running session.
+	Create(ctx context.Context, session *models.FocusSession) error
+	// FindByID returns a session of a user, or ErrNotFound.
+	FindByID(ctx context.Context, userID, id primitive.ObjectID) (*models.FocusSession, error)
+	// FindRunning returns the running session of a user, or ErrNotFound.
+	FindRunning(ctx context.Context, userID primitive.ObjectID) (*models.FocusSession, error)
+	// Find returns the sessions matching the query, in the order they started.
+	Find(ctx context.Context, query FocusSessionQuery) ([]models.FocusSession, error)
+	// UpdateRunning replaces a running session of session.UserID, e.g. to end it. It returns ErrNotFound if
+	// there is no such session or it has ended.
+	UpdateRunning(ctx context.Context, session *models.FocusSession) error
+	// DeleteMany deletes the sessions of a user and returns how many were deleted.
+	DeleteMany(ctx context.Context, userID primitive.ObjectID) (int64, error)
+}
+
+// WorkspaceRepository stores the workspaces. Their members are the users whose workspace_id is the
+// workspace's ID.
+type WorkspaceRepository interface {
+	// Create inserts a workspace and sets its ID and created_at.
+	Create(ctx context.Context, workspace *models.Workspace) error
+	// FindByID returns a workspace, or ErrNotFound.
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Workspace, error)
+	// UpdateBranding replaces the branding of a workspace. It returns ErrNotFound if the workspace does not
+	// exist.
+	UpdateBranding(ctx context.Context, id primitive.ObjectID, branding models.Branding) error
+	// Invite adds a user to the invited users of a workspace; inviting a user twice is not an error. It
+	// returns ErrNotFound if the workspace does not exist.
+	Invite(ctx context.Context, id, userID primitive.ObjectID) error
+	// Uninvite removes a user from the invited users of a workspace, e.g. when the user joins. It returns
+	// ErrNotFound if the workspace does not exist or the user is not invited, so that an invitation is
+	// used only once.
+	Uninvite(ctx context.Context, id, userID primitive.ObjectID) error
+}
+
+// SubscriptionRepository stores the billing state of workspaces.
+type SubscriptionRepository interface {
+	// FindByWorkspace returns the subscription of a workspace, or ErrNotFound.
+	FindByWorkspace(ctx context.Context, workspaceID primitive.ObjectID) (*models.Subscription, error)
+	// FindByCustomer returns the subscription of a Stripe customer, or ErrNotFound.
+	FindByCustomer(ctx context.Context, customerID string) (*models.Subscription, error)
+	// Save inserts or replaces the subscription of subscription.WorkspaceID and sets its ID and updated_at.
+	Save(ctx context.Context, subscription *models.Subscription) error
+}
+
+// AuditLogRepository stores the audit entries a handler must record whatever the audit sink, such as those
+// of bulk updates, in the audit_log collection.
+type AuditLogRepository interface {
+	// Create inserts an entry and sets its ID.
+	Create(ctx context.Context, entry *audit.Entry) error
+	// Update replaces a stored entry, e.g. to add the outcome of the request. It returns ErrNotFound if the
+	// entry does not exist.
+	Update(ctx context.Context, entry *audit.Entry) error
+	// FindByUser returns the entries of a user, newest first.
+	FindByUser(ctx context.Context, userID string) ([]audit.Entry, error)
+}
+
+// Transactor runs functions in a database transaction.
+type Transactor interface {
+	// InTransaction runs fn with a context whose repository operations are committed together if fn
+	// returns nil and rolled back otherwise. fn may be called again when the transaction is retried.
+	InTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// The repositories used by handlers and commands, set up by InitMongo (or replaced in tests)
+var (
+	Users     UserRepository
+	Tasks     TaskRepository
+	TaskViews TaskViewRepository
+	Projects  ProjectRepository
+	Sessions  SessionRepository
+	SignIns   SignInRepository
+
+	Invitations InvitationRepository
+	Comments    CommentRepository
+	InviteCodes InviteCodeRepository
+	Maintenance MaintenanceRepository
+	Leases      LeaseRepository
+	Nonces      NonceRepository
+	AuditLog    AuditLogRepository
+	Outbox      OutboxRepository
+	Webhooks    WebhookRepository
+
+	WebhookDeliveries WebhookDeliveryRepository
+	Tombstones        TombstoneRepository
+	ReportSchedules   ReportScheduleRepository
+	Subscriptions     SubscriptionRepository
+	FocusSessions     FocusSessionRepository
+	Workspaces        WorkspaceRepository
+
+	Transactions Transactor
+
+	// ReportTasks serves the reads of the reporting and analytics endpoints. It is Tasks unless reports
+	// use a client of their own (UseReportingMongo), whose secondaries may lag behind: never write
+	// through it, nor read from it what a request is about to change.
+	ReportTasks TaskRepository
+)
+
+// entryJoins reports which joins of FindEntries the selected fields need: the assignee and the comment
+// count. Without selected fields both are joined.
+func entryJoins(fields []string) (assignee, comments bool) {
+	if len(fields) == 0 {
+		return true, true
+	}
+	for _, field := range fields {
+		switch field {
+		case "assignee":
+			assignee = true
+		case "comment_count":
+			comments = true
+		}
+	}
+	return assignee, comments
+}
@@ -0,0 +1,120 @@
+// response.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package response
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// EnvelopeHeader is the request header selecting the response format per request ("true" or "false").
+const EnvelopeHeader = "X-Response-Envelope"
+
+// EnvelopeQuery is the query parameter selecting the response format per request ("true" or "false").
+const EnvelopeQuery = "envelope"
+
+// EnvelopeMediaType is the Accept media type selecting the envelope format.
+const EnvelopeMediaType = "application/vnd.taskmanager.envelope+json"
+
+// DefaultEnvelope selects the envelope format for requests that don't ask for a format explicitly.
+// It is false by default so that existing clients keep receiving bare resources and arrays.
+var DefaultEnvelope bool
+
+// Request context keys under which handlers collect response metadata and links
+const (
+	metaKey  = "responseMeta"
+	linksKey = "responseLinks"
+)
+
+// Envelope is the opt-in response format carrying metadata and errors alongside the result.
+// Errors are rendered by the central error handler of the apierror package.
+type Envelope struct {
+	Data   interface{}            `json:"data"`
+	Meta   map[string]interface{} `json:"meta,omitempty"`
+	Links  Links                  `json:"_links,omitempty"`
+	Errors []interface{}          `json:"errors,omitempty"`
+}
+
+// Link is a hypermedia link to a related resource or action.
+type Link struct {
+	Href   string `json:"href"`
+	Method string `json:"method,omitempty"` // HTTP method of an action; GET when empty
+}
+
+// Links are hypermedia links keyed by relation, e.g. "self", "next" or "update".
+type Links map[string]Link
+
+// Enabled reports whether the response to this request should be wrapped in an envelope.
+// The X-Response-Envelope header takes precedence, then the envelope query parameter, then the Accept
+// header, then DefaultEnvelope.
+func Enabled(c *fiber.Ctx) bool {
+	for _, value := range []string{c.Get(EnvelopeHeader), c.Query(EnvelopeQuery)} {
+		if value == "" {
+			continue
+		}
+		if enabled, err := strconv.ParseBool(value); err == nil {
+			return enabled
+		}
+	}
+	if strings.Contains(c.Get(fiber.HeaderAccept), EnvelopeMediaType) {
+		return true
+	}
+	return DefaultEnvelope
+}
+
+// AddMeta records a metadata entry for the response. It only appears in envelope responses.
+func AddMeta(c *fiber.Ctx, key string, value interface{}) {
+	meta, _ := c.Locals(metaKey).(map[string]interface{})
+	if meta == nil {
+		meta = map[string]interface{}{}
+		c.Locals(metaKey, meta)
+	}
+	meta[key] = value
+}
+
+// Meta returns the metadata collected for the response so far, or nil.
+func Meta(c *fiber.Ctx) map[string]interface{} {
+	meta, _ := c.Locals(metaKey).(map[string]interface{})
+	return meta
+}
+
+// AddLink records a link of the response as a whole, e.g. to the next page. It only appears in envelope
+// responses.
+func AddLink(c *fiber.Ctx, relation string, link Link) {
+	links, _ := c.Locals(linksKey).(Links)
+	if links == nil {
+		links = Links{}
+		c.Locals(linksKey, links)
+	}
+	links[relation] = link
+}
+
+// AddWarning records a non-fatal warning for the response. Warnings are listed under "warnings" in the
+// envelope metadata, and sent as Warning headers so that clients using bare responses see them too.
+func AddWarning(c *fiber.Ctx, code, message string) {
+	warnings, _ := Meta(c)["warnings"].([]fiber.Map)
+	AddMeta(c, "warnings", append(warnings, fiber.Map{"code": code, "message": message}))
+	c.Append(fiber.HeaderWarning, `299 - "`+strings.ReplaceAll(message, `"`, `'`)+`"`)
+}
+
+// JSON sends data with the given status, wrapped in an envelope together with the collected metadata
+// when the envelope format is enabled for the request.
+//
+// Parameters:
+// - c: Fiber context, which provides methods to interact with the request and response.
+// - status: The HTTP status code.
+// - data: The resource or list to send.
+//
+// Returns:
+// - error: An error object if the response could not be written.
+func JSON(c *fiber.Ctx, status int, data interface{}) error {
+	if !Enabled(c) {
+		return c.Status(status).JSON(data)
+	}
+
+	links, _ := c.Locals(linksKey).(Links)
+	return c.Status(status).JSON(Envelope{Data: data, Meta: Meta(c), Links: links})
+}
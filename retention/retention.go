@@ -0,0 +1,170 @@
+// retention.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package retention
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bkojha74/task-management/audit"
+	"github.com/bkojha74/task-management/cache"
+	"github.com/bkojha74/task-management/helper"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/outbox"
+	"github.com/bkojha74/task-management/pagination"
+	"github.com/bkojha74/task-management/readmodel"
+	"github.com/bkojha74/task-management/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// archiveBatchSize is the number of tasks archived per query.
+const archiveBatchSize = 500
+
+// Policy is how long completed tasks, trashed tasks and audit entries are kept; 0 keeps them forever.
+type Policy struct {
+	ArchiveDoneAfter time.Duration // Completed tasks are archived this long after their completion
+	PurgeTrashAfter  time.Duration // Trashed tasks are deleted for good this long after their deletion
+	AuditLogMaxAge   time.Duration // Audit entries older than this are deleted, if the sink supports it
+	DryRun           bool          // Only report what would be archived and deleted
+}
+
+// Report is what a run of Enforce archived and deleted, or would have in a dry run.
+type Report struct {
+	DryRun       bool  `json:"dry_run"`
+	Archived     int64 `json:"archived_tasks"`
+	PurgedTasks  int64 `json:"purged_tasks"`
+	AuditEntries int64 `json:"purged_audit_entries"`
+}
+
+// LoadPolicy reads the policy from RETENTION_ARCHIVE_DONE_DAYS (default 0), RETENTION_TRASH_DAYS (default
+// 30), RETENTION_AUDIT_DAYS (default 0) and RETENTION_DRY_RUN.
+//
+// Returns:
+// - Policy: The configured policy.
+func LoadPolicy() Policy {
+	day := 24 * time.Hour
+	return Policy{
+		ArchiveDoneAfter: time.Duration(helper.GetEnvInt("RETENTION_ARCHIVE_DONE_DAYS", 0)) * day,
+		PurgeTrashAfter:  time.Duration(helper.GetEnvInt("RETENTION_TRASH_DAYS", 30)) * day,
+		AuditLogMaxAge:   time.Duration(helper.GetEnvInt("RETENTION_AUDIT_DAYS", 0)) * day,
+		DryRun:           helper.GetEnv("RETENTION_DRY_RUN") == "true",
+	}
+}
+
+// Validate checks that no retention period is negative.
+func (p Policy) Validate() error {
+	if p.ArchiveDoneAfter < 0 || p.PurgeTrashAfter < 0 || p.AuditLogMaxAge < 0 {
+		return errors.New("retention periods must not be negative")
+	}
+	return nil
+}
+
+// Enforce archives the tasks completed longer ago than the policy allows, purges the trash and deletes old
+// audit entries from sinks that can prune. In a dry run nothing is changed and the report counts what would
+// be. Tasks changed concurrently are skipped and picked up by the next run.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - policy: The retention policy.
+// - sink: The audit sink, or nil when auditing is off.
+// - now: The time the retention periods are counted back from.
+//
+// Returns:
+// - Report: What was, or would be, archived and deleted.
+// - error: An error if the tasks, the trash or the audit log could not be read or written.
+func Enforce(ctx context.Context, policy Policy, sink audit.Sink, now time.Time) (Report, error) {
+	report := Report{DryRun: policy.DryRun}
+	var err error
+
+	if policy.ArchiveDoneAfter > 0 {
+		if report.Archived, err = archive(ctx, now.Add(-policy.ArchiveDoneAfter), now, report.DryRun); err != nil {
+			return report, err
+		}
+	}
+
+	if policy.PurgeTrashAfter > 0 {
+		before := now.Add(-policy.PurgeTrashAfter)
+		if report.DryRun {
+			report.PurgedTasks, err = repository.Tasks.CountTrashed(ctx, before)
+		} else {
+			report.PurgedTasks, err = purgeTrash(ctx, before)
+		}
+		if err != nil {
+			return report, err
+		}
+	}
+
+	if pruner, ok := sink.(audit.Pruner); ok && policy.AuditLogMaxAge > 0 {
+		if report.AuditEntries, err = pruner.Prune(ctx, now.Add(-policy.AuditLogMaxAge), report.DryRun); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// purgeTrash deletes the tasks trashed before a time together with the comments they kept in the trash,
+// in one transaction when the outbox is enabled, and returns how many tasks were deleted.
+func purgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	var purged []primitive.ObjectID
+	err := outbox.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if purged, err = repository.Tasks.PurgeTrash(ctx, primitive.NilObjectID, before); err != nil {
+			return err
+		}
+		_, err = repository.Comments.DeleteByTasks(ctx, purged)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(purged)), nil
+}
+
+// archive sets archived_at on the unarchived tasks completed before a time, or counts them in a dry run.
+func archive(ctx context.Context, completedBefore, now time.Time, dryRun bool) (int64, error) {
+	notArchived := false
+	query := repository.TaskQuery{
+		Statuses:        []string{models.TaskStatusDone},
+		CompletedBefore: completedBefore,
+		Archived:        &notArchived,
+		Sort:            pagination.Sort{Field: "_id"},
+		Limit:           archiveBatchSize,
+	}
+	if dryRun {
+		return repository.Tasks.Count(ctx, query)
+	}
+
+	var archived int64
+	for {
+		tasks, err := repository.Tasks.Find(ctx, query)
+		if err != nil {
+			return archived, err
+		}
+		if len(tasks) > archiveBatchSize {
+			tasks = tasks[:archiveBatchSize]
+		}
+
+		for _, task := range tasks {
+			task.ArchivedAt = primitive.NewDateTimeFromTime(now)
+			task.Version++
+			err := repository.Tasks.Update(ctx, &task, task.Version-1)
+			if err == repository.ErrNotFound {
+				continue
+			}
+			if err != nil {
+				return archived, err
+			}
+			archived++
+			cache.InvalidateTask(task.UserID.Hex(), task.ID.Hex())
+			readmodel.SyncOrLog(ctx, task)
+		}
+
+		if len(tasks) < archiveBatchSize {
+			return archived, nil
+		}
+		query.After = &pagination.Cursor{Sort: query.Sort.String(), ID: tasks[len(tasks)-1].ID}
+	}
+}
@@ -0,0 +1,100 @@
+// retention_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/audit"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// pruner records the cutoffs it was asked to prune before.
+type pruner struct {
+	before []time.Time
+	dryRun []bool
+}
+
+func (p *pruner) Write(ctx context.Context, entry audit.Entry) error { return nil }
+func (p *pruner) Close() error                                       { return nil }
+
+func (p *pruner) Prune(ctx context.Context, before time.Time, dryRun bool) (int64, error) {
+	p.before, p.dryRun = append(p.before, before), append(p.dryRun, dryRun)
+	return 7, nil
+}
+
+// TestEnforce tests that a dry run only reports and that a run archives long completed tasks, purges the
+// old trash with its comments and prunes the audit log
+func TestEnforce(t *testing.T) {
+	repository.UseMemory()
+	ctx := context.Background()
+	now := time.Now()
+	owner := primitive.NewObjectID()
+
+	for _, task := range []models.Task{
+		{Title: "Done long ago", Status: models.TaskStatusDone, CompletedAt: primitive.NewDateTimeFromTime(now.Add(-40 * 24 * time.Hour))},
+		{Title: "Done lately", Status: models.TaskStatusDone, CompletedAt: primitive.NewDateTimeFromTime(now.Add(-24 * time.Hour))},
+		{Title: "Pending", Status: models.TaskStatusPending},
+		{Title: "Trashed", Status: models.TaskStatusPending},
+	} {
+		task.UserID = owner
+		require.NoError(t, repository.Tasks.Create(ctx, &task))
+		require.NoError(t, repository.Comments.Create(ctx, &models.Comment{TaskID: task.ID, OwnerID: owner, Body: "On " + task.Title}))
+		if task.Title == "Trashed" {
+			require.NoError(t, repository.Tasks.Trash(ctx, owner, task.ID))
+		}
+	}
+	trashed, err := repository.Tasks.FindTrashed(ctx, owner)
+	require.NoError(t, err)
+	require.Len(t, trashed, 1)
+	trashedID := trashed[0].ID
+
+	policy := Policy{ArchiveDoneAfter: 30 * 24 * time.Hour, PurgeTrashAfter: time.Hour, AuditLogMaxAge: 90 * 24 * time.Hour, DryRun: true}
+	sink := &pruner{}
+
+	// Assert that a dry run counts without changing anything; the trashed task is not old enough yet
+	report, err := Enforce(ctx, policy, sink, now)
+	require.NoError(t, err)
+	assert.Equal(t, Report{DryRun: true, Archived: 1, AuditEntries: 7}, report)
+	archived := true
+	count, err := repository.Tasks.Count(ctx, repository.TaskQuery{Archived: &archived})
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	policy.DryRun = false
+	report, err = Enforce(ctx, policy, sink, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, Report{Archived: 1, PurgedTasks: 1, AuditEntries: 7}, report)
+	tasks, err := repository.Tasks.Find(ctx, repository.TaskQuery{Archived: &archived})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Done long ago", tasks[0].Title)
+	trashed, err = repository.Tasks.FindTrashed(ctx, owner)
+	require.NoError(t, err)
+	assert.Empty(t, trashed)
+	comments, err := repository.Comments.Find(ctx, trashedID)
+	require.NoError(t, err)
+	assert.Empty(t, comments)
+	kept, err := repository.Comments.CountByTasks(ctx, []primitive.ObjectID{tasks[0].ID})
+	require.NoError(t, err)
+	assert.Equal(t, 1, kept[tasks[0].ID])
+	assert.Equal(t, []bool{true, false}, sink.dryRun)
+	assert.True(t, sink.before[1].Equal(now.Add(2*time.Hour-policy.AuditLogMaxAge)))
+
+	// Assert that archived tasks are not archived again and that zero periods keep everything
+	report, err = Enforce(ctx, policy, sink, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, report.Archived)
+	report, err = Enforce(ctx, Policy{}, sink, now)
+	require.NoError(t, err)
+	assert.Equal(t, Report{}, report)
+	assert.Len(t, sink.before, 3)
+}
@@ -0,0 +1,444 @@
+// saml.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package saml is the service provider side of SAML 2.0 single sign-on, initiated by the service: it
+// describes the service in its metadata, sends users to the identity provider with an authentication
+// request over the HTTP-Redirect binding, and checks the response posted back to the assertion consumer
+// service. Responses are only accepted signed by the identity provider's certificate with RSA and SHA-256
+// or SHA-512, for the request they answer, and within their validity period. XML signatures are verified
+// with goxmldsig.
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/helper"
+
+	"github.com/beevik/etree"
+)
+
+// Formats and bindings
+const (
+	nameIDUnspecified = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+	nameIDTransient   = "urn:oasis:names:tc:SAML:2.0:nameid-format:transient"
+	bindingPOST       = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	statusSuccess     = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	confirmBearer     = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+)
+
+// Errors of ParseResponse besides the signature errors
+var (
+	ErrMalformed       = errors.New("saml: malformed response")
+	ErrFailed          = errors.New("saml: identity provider did not authenticate the user")
+	ErrWrongRecipient  = errors.New("saml: response is meant for another service")
+	ErrWrongRequest    = errors.New("saml: response does not answer the request")
+	ErrExpired         = errors.New("saml: assertion is expired or not yet valid")
+	ErrUnknownIssuer   = errors.New("saml: response is from an unknown identity provider")
+	ErrMissingUsername = errors.New("saml: assertion has no username")
+	ErrMissingSubject  = errors.New("saml: assertion has no persistent NameID")
+)
+
+// ServiceProvider is this service as a SAML service provider of one identity provider.
+type ServiceProvider struct {
+	EntityID          string              // Entity ID of the service, by convention the URL of its metadata
+	ACSURL            string              // URL of the assertion consumer service, where responses are posted
+	IdPEntityID       string              // Entity ID of the identity provider, the issuer of its responses
+	IdPSSOURL         string              // Single sign-on URL of the identity provider (HTTP-Redirect binding)
+	IdPCertificates   []*x509.Certificate // Certificates the identity provider signs with; several during rotation
+	UsernameAttribute string              // Attribute holding the username; the NameID when empty
+	EmailAttribute    string              // Attribute holding the email address, e.g. email
+	RedirectURL       string              // Where users go after sign-in, with the token in the fragment; JSON when empty
+	ClockSkew         time.Duration       // Tolerated difference between the clocks of the providers
+}
+
+// Assertion is what a valid response says about the user.
+type Assertion struct {
+	ID           string              // ID of the assertion, unique per identity provider
+	ExpiresAt    time.Time           // Earliest NotOnOrAfter of the assertion; zero if it has none
+	NameID       string              // Subject of the assertion
+	NameIDFormat string              // Format of the NameID; "" if the identity provider did not say
+	Attributes   map[string][]string // Attribute values by name, and by friendly name if there is one
+}
+
+// LoadServiceProvider creates the service provider when SAML_IDP_SSO_URL is set, from SAML_SP_ENTITY_ID,
+// SAML_SP_ACS_URL, SAML_IDP_ENTITY_ID, SAML_IDP_SSO_URL, SAML_IDP_CERT_FILE (PEM, one or more
+// certificates), SAML_USERNAME_ATTRIBUTE, SAML_EMAIL_ATTRIBUTE (default "email"), SAML_REDIRECT_URL and
+// SAML_CLOCK_SKEW in seconds (default 60).
+//
+// Returns:
+// - *ServiceProvider: The configured service provider, or nil when SAML is not configured.
+// - error: An error if the settings are incomplete or the certificates cannot be read.
+func LoadServiceProvider() (*ServiceProvider, error) {
+	ssoURL := helper.GetEnv("SAML_IDP_SSO_URL")
+	if ssoURL == "" {
+		return nil, nil
+	}
+	sp := &ServiceProvider{
+		EntityID:          helper.GetEnv("SAML_SP_ENTITY_ID"),
+		ACSURL:            helper.GetEnv("SAML_SP_ACS_URL"),
+		IdPEntityID:       helper.GetEnv("SAML_IDP_ENTITY_ID"),
+		IdPSSOURL:         ssoURL,
+		UsernameAttribute: helper.GetEnv("SAML_USERNAME_ATTRIBUTE"),
+		EmailAttribute:    helper.GetEnv("SAML_EMAIL_ATTRIBUTE"),
+		RedirectURL:       helper.GetEnv("SAML_REDIRECT_URL"),
+		ClockSkew:         time.Duration(helper.GetEnvInt("SAML_CLOCK_SKEW", 60)) * time.Second,
+	}
+	if sp.EmailAttribute == "" {
+		sp.EmailAttribute = "email"
+	}
+	if certFile := helper.GetEnv("SAML_IDP_CERT_FILE"); certFile != "" {
+		data, err := os.ReadFile(certFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read SAML_IDP_CERT_FILE: %w", err)
+		}
+		if sp.IdPCertificates, err = ParseCertificates(data); err != nil {
+			return nil, fmt.Errorf("invalid SAML_IDP_CERT_FILE: %w", err)
+		}
+	}
+	if err := sp.Validate(); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+// ParseCertificates parses the PEM certificates in data.
+//
+// Parameters:
+// - data: One or more PEM blocks of type CERTIFICATE.
+//
+// Returns:
+// - []*x509.Certificate: The certificates.
+// - error: An error if data holds no certificate or one cannot be parsed.
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	certificates := []*x509.Certificate{}
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, errors.New("no PEM certificate found")
+	}
+	return certificates, nil
+}
+
+// Validate checks that the entity IDs, URLs and certificates are all set.
+func (sp *ServiceProvider) Validate() error {
+	switch {
+	case sp.EntityID == "" || sp.IdPEntityID == "":
+		return errors.New("SAML_SP_ENTITY_ID and SAML_IDP_ENTITY_ID must be set for SAML")
+	case !absoluteURL(sp.ACSURL):
+		return errors.New("SAML_SP_ACS_URL must be the absolute URL of /saml/acs")
+	case !absoluteURL(sp.IdPSSOURL):
+		return errors.New("SAML_IDP_SSO_URL must be an absolute URL")
+	case len(sp.IdPCertificates) == 0:
+		return errors.New("SAML_IDP_CERT_FILE must be set for SAML, responses are only accepted signed")
+	case sp.RedirectURL != "" && !absoluteURL(sp.RedirectURL):
+		return errors.New("SAML_REDIRECT_URL must be an absolute URL")
+	}
+	return nil
+}
+
+// absoluteURL reports whether raw is an absolute http or https URL.
+func absoluteURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	return err == nil && (parsed.Scheme == "https" || parsed.Scheme == "http") && parsed.Host != ""
+}
+
+// Metadata returns the metadata of the service provider, to be registered with the identity provider.
+//
+// Returns:
+// - []byte: An EntityDescriptor with the assertion consumer service on the HTTP-POST binding.
+func (sp *ServiceProvider) Metadata() []byte {
+	return []byte(`<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<md:EntityDescriptor xmlns:md="` + nsMetadata + `" entityID="` + html.EscapeString(sp.EntityID) + `">` +
+		`<md:SPSSODescriptor AuthnRequestsSigned="false" WantAssertionsSigned="true" protocolSupportEnumeration="` + nsProtocol + `">` +
+		`<md:NameIDFormat>` + nameIDUnspecified + `</md:NameIDFormat>` +
+		`<md:AssertionConsumerService Binding="` + bindingPOST + `" Location="` + html.EscapeString(sp.ACSURL) + `" index="0" isDefault="true"/>` +
+		`</md:SPSSODescriptor></md:EntityDescriptor>` + "\n")
+}
+
+// NewRequestID returns a random ID for an authentication request.
+//
+// Returns:
+// - string: The ID, 40 hex digits after "id" since IDs must not start with a digit.
+// - error: An error if no random bytes could be read.
+func NewRequestID() (string, error) {
+	random := make([]byte, 20)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return "id" + hex.EncodeToString(random), nil
+}
+
+// AuthnRequestURL creates an authentication request and returns the URL sending the user with it to the
+// identity provider.
+//
+// Parameters:
+// - id: The ID of the request, from NewRequestID; the response must be in response to it.
+// - relayState: State the identity provider posts back with the response.
+// - now: The time the request is issued.
+//
+// Returns:
+// - string: The URL to redirect the user to.
+// - error: An error if the single sign-on URL of the identity provider is invalid.
+func (sp *ServiceProvider) AuthnRequestURL(id, relayState string, now time.Time) (string, error) {
+	request := `<samlp:AuthnRequest xmlns:samlp="` + nsProtocol + `" xmlns:saml="` + nsAssertion + `"` +
+		` ID="` + id + `" Version="2.0" IssueInstant="` + now.UTC().Format(time.RFC3339) + `"` +
+		` Destination="` + html.EscapeString(sp.IdPSSOURL) + `"` +
+		` AssertionConsumerServiceURL="` + html.EscapeString(sp.ACSURL) + `" ProtocolBinding="` + bindingPOST + `">` +
+		`<saml:Issuer>` + html.EscapeString(sp.EntityID) + `</saml:Issuer>` +
+		`<samlp:NameIDPolicy Format="` + nameIDUnspecified + `" AllowCreate="true"/>` +
+		`</samlp:AuthnRequest>`
+
+	var deflated bytes.Buffer
+	writer, _ := flate.NewWriter(&deflated, flate.BestCompression)
+	_, _ = writer.Write([]byte(request))
+	_ = writer.Close()
+
+	destination, err := url.Parse(sp.IdPSSOURL)
+	if err != nil {
+		return "", err
+	}
+	query := destination.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(deflated.Bytes()))
+	if relayState != "" {
+		query.Set("RelayState", relayState)
+	}
+	destination.RawQuery = query.Encode()
+	return destination.String(), nil
+}
+
+// ParseResponse checks a response posted to the assertion consumer service and returns its assertion.
+// Either the response or its assertion must be signed by the identity provider; only the signed element
+// is read, so that content wrapped around it cannot be passed off as signed.
+//
+// Parameters:
+// - encoded: The SAMLResponse form value, base64-encoded XML.
+// - requestID: The ID of the authentication request the response must answer.
+// - now: The time to check the validity periods against.
+//
+// Returns:
+// - Assertion: The subject and attributes of the user.
+// - error: The reason the response is rejected.
+func (sp *ServiceProvider) ParseResponse(encoded, requestID string, now time.Time) (Assertion, error) {
+	data, err := base64.StdEncoding.DecodeString(compact(encoded))
+	if err != nil {
+		return Assertion{}, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	response, err := parseDocument(data)
+	if err != nil {
+		return Assertion{}, err
+	}
+	if !is(response, nsProtocol, "Response") {
+		return Assertion{}, fmt.Errorf("%w: not a response", ErrMalformed)
+	}
+	if len(childElements(response, nsAssertion, "Assertion")) != 1 {
+		return Assertion{}, fmt.Errorf("%w: expected exactly one unencrypted assertion", ErrMalformed)
+	}
+
+	// From here on, only the signed response or the signed assertion is read
+	var assertion *etree.Element
+	if child(response, nsSignature, "Signature") != nil {
+		if response, err = verifySignature(response, sp.IdPCertificates, now); err != nil {
+			return Assertion{}, err
+		}
+		if assertion = child(response, nsAssertion, "Assertion"); assertion == nil {
+			return Assertion{}, fmt.Errorf("%w: expected exactly one unencrypted assertion", ErrMalformed)
+		}
+	} else if assertion, err = verifySignature(child(response, nsAssertion, "Assertion"), sp.IdPCertificates, now); err != nil {
+		return Assertion{}, err
+	}
+
+	if destination := attr(response, "Destination"); destination != "" && destination != sp.ACSURL {
+		return Assertion{}, ErrWrongRecipient
+	}
+	if attr(response, "InResponseTo") != requestID {
+		return Assertion{}, ErrWrongRequest
+	}
+	if issuer := child(response, nsAssertion, "Issuer"); issuer != nil && text(issuer) != sp.IdPEntityID {
+		return Assertion{}, ErrUnknownIssuer
+	}
+	status := child(response, nsProtocol, "Status")
+	if status == nil || child(status, nsProtocol, "StatusCode") == nil || attr(child(status, nsProtocol, "StatusCode"), "Value") != statusSuccess {
+		return Assertion{}, ErrFailed
+	}
+	return sp.checkAssertion(assertion, requestID, now)
+}
+
+// checkAssertion checks the issuer, subject confirmation and conditions of a signed assertion.
+func (sp *ServiceProvider) checkAssertion(assertion *etree.Element, requestID string, now time.Time) (Assertion, error) {
+	issuer := child(assertion, nsAssertion, "Issuer")
+	if issuer == nil || text(issuer) != sp.IdPEntityID {
+		return Assertion{}, ErrUnknownIssuer
+	}
+	result := Assertion{ID: attr(assertion, "ID"), Attributes: map[string][]string{}}
+	if result.ID == "" {
+		return Assertion{}, fmt.Errorf("%w: assertion has no ID", ErrMalformed)
+	}
+
+	subject := child(assertion, nsAssertion, "Subject")
+	if subject == nil {
+		return Assertion{}, fmt.Errorf("%w: assertion has no subject", ErrMalformed)
+	}
+	confirmed := false
+	for _, confirmation := range childElements(subject, nsAssertion, "SubjectConfirmation") {
+		data := child(confirmation, nsAssertion, "SubjectConfirmationData")
+		if attr(confirmation, "Method") != confirmBearer || data == nil {
+			continue
+		}
+		if attr(data, "Recipient") != sp.ACSURL {
+			return Assertion{}, ErrWrongRecipient
+		}
+		if attr(data, "InResponseTo") != requestID {
+			return Assertion{}, ErrWrongRequest
+		}
+		if err := sp.checkPeriod("", attr(data, "NotOnOrAfter"), now); err != nil {
+			return Assertion{}, err
+		}
+		result.ExpiresAt = earliest(result.ExpiresAt, attr(data, "NotOnOrAfter"))
+		confirmed = true
+	}
+	if !confirmed {
+		return Assertion{}, fmt.Errorf("%w: assertion has no bearer confirmation", ErrMalformed)
+	}
+
+	conditions := child(assertion, nsAssertion, "Conditions")
+	if conditions == nil {
+		return Assertion{}, fmt.Errorf("%w: assertion has no conditions", ErrMalformed)
+	}
+	if err := sp.checkPeriod(attr(conditions, "NotBefore"), attr(conditions, "NotOnOrAfter"), now); err != nil {
+		return Assertion{}, err
+	}
+	result.ExpiresAt = earliest(result.ExpiresAt, attr(conditions, "NotOnOrAfter"))
+	for _, restriction := range childElements(conditions, nsAssertion, "AudienceRestriction") {
+		audience := false
+		for _, allowed := range childElements(restriction, nsAssertion, "Audience") {
+			audience = audience || text(allowed) == sp.EntityID
+		}
+		if !audience {
+			return Assertion{}, ErrWrongRecipient
+		}
+	}
+
+	if nameID := child(subject, nsAssertion, "NameID"); nameID != nil {
+		result.NameID = text(nameID)
+		result.NameIDFormat = attr(nameID, "Format")
+	}
+	for _, statement := range childElements(assertion, nsAssertion, "AttributeStatement") {
+		for _, attribute := range childElements(statement, nsAssertion, "Attribute") {
+			values := []string{}
+			for _, value := range childElements(attribute, nsAssertion, "AttributeValue") {
+				values = append(values, text(value))
+			}
+			for _, name := range []string{attr(attribute, "Name"), attr(attribute, "FriendlyName")} {
+				if name != "" {
+					result.Attributes[name] = append(result.Attributes[name], values...)
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// checkPeriod checks that now is within a validity period, give or take ClockSkew. Bounds that are
+// empty are open.
+func (sp *ServiceProvider) checkPeriod(notBefore, notOnOrAfter string, now time.Time) error {
+	if notBefore != "" {
+		start, err := time.Parse(time.RFC3339Nano, notBefore)
+		if err != nil {
+			return fmt.Errorf("%w: invalid NotBefore", ErrMalformed)
+		}
+		if now.Add(sp.ClockSkew).Before(start) {
+			return ErrExpired
+		}
+	}
+	if notOnOrAfter != "" {
+		end, err := time.Parse(time.RFC3339Nano, notOnOrAfter)
+		if err != nil {
+			return fmt.Errorf("%w: invalid NotOnOrAfter", ErrMalformed)
+		}
+		if !now.Add(-sp.ClockSkew).Before(end) {
+			return ErrExpired
+		}
+	}
+	return nil
+}
+
+// earliest returns the earlier of current and the instant value, which checkPeriod accepted; a zero current
+// or an empty value is no bound.
+func earliest(current time.Time, value string) time.Time {
+	instant, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil || (!current.IsZero() && current.Before(instant)) {
+		return current
+	}
+	return instant
+}
+
+// Identity returns the username and email address of the user, from the configured attributes.
+//
+// Parameters:
+// - assertion: The assertion returned by ParseResponse.
+//
+// Returns:
+// - string: The username, from UsernameAttribute or the NameID.
+// - string: The email address, or "".
+// - error: ErrMissingUsername if the assertion does not name the user.
+func (sp *ServiceProvider) Identity(assertion Assertion) (string, string, error) {
+	username := assertion.NameID
+	if sp.UsernameAttribute != "" {
+		username = first(assertion.Attributes[sp.UsernameAttribute])
+	}
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return "", "", ErrMissingUsername
+	}
+	return username, strings.TrimSpace(first(assertion.Attributes[sp.EmailAttribute])), nil
+}
+
+// Subject returns the stable identity of the user, the issuer and NameID, which accounts are linked to.
+// Usernames can be renamed or reused at the identity provider; the NameID is meant not to be.
+//
+// Parameters:
+// - assertion: The assertion returned by ParseResponse.
+//
+// Returns:
+// - string: The subject, "saml|<issuer>|<NameID>".
+// - error: ErrMissingSubject if the assertion has no NameID or a transient one, which changes every sign-in.
+func (sp *ServiceProvider) Subject(assertion Assertion) (string, error) {
+	if assertion.NameID == "" || assertion.NameIDFormat == nameIDTransient {
+		return "", ErrMissingSubject
+	}
+	return "saml|" + sp.IdPEntityID + "|" + assertion.NameID, nil
+}
+
+// compact removes the whitespace base64 values are often wrapped with.
+func compact(value string) string {
+	return strings.Join(strings.Fields(value), "")
+}
+
+// first returns the first value, or "".
+func first(values []string) string {
+	if len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
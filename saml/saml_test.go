@@ -0,0 +1,295 @@
+// saml_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signatureMarker is where sign puts the signature into a template.
+const signatureMarker = "{{signature}}"
+
+// identityProvider is a key and self-signed certificate of a test identity provider.
+type identityProvider struct {
+	key         *rsa.PrivateKey
+	certificate *x509.Certificate
+}
+
+// newIdentityProvider generates a key and a certificate for it.
+func newIdentityProvider(t *testing.T) identityProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return identityProvider{key: key, certificate: certificate}
+}
+
+// GetKeyPair returns the key and certificate of the identity provider, as goxmldsig's key store.
+func (idp identityProvider) GetKeyPair() (*rsa.PrivateKey, []byte, error) {
+	return idp.key, idp.certificate.Raw, nil
+}
+
+// sign signs the element with the given ID in template with an enveloped signature made by goxmldsig, put
+// where the template has signatureMarker, and returns the signed document.
+func (idp identityProvider) sign(t *testing.T, template, id string) string {
+	document := etree.NewDocument()
+	require.NoError(t, document.ReadFromString(strings.Replace(template, signatureMarker, "", 1)))
+	signed := document.FindElement("//[@ID='" + id + "']")
+	require.NotNil(t, signed)
+	scope, err := etreeutils.NSBuildParentContext(signed)
+	require.NoError(t, err)
+	detached, err := etreeutils.NSDetatch(scope, signed)
+	require.NoError(t, err)
+
+	signing := dsig.NewDefaultSigningContext(idp)
+	signing.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+	signature, err := signing.ConstructSignature(detached, true)
+	require.NoError(t, err)
+	out := etree.NewDocument()
+	out.SetRoot(signature)
+	value, err := out.WriteToString()
+	require.NoError(t, err)
+	return strings.Replace(template, signatureMarker, value, 1)
+}
+
+// signatureOf returns the signature element of a signed document.
+func signatureOf(t *testing.T, signed string) string {
+	start, end := strings.Index(signed, "<ds:Signature"), strings.Index(signed, "</ds:Signature>")
+	require.True(t, start >= 0 && end > start)
+	return signed[start : end+len("</ds:Signature>")]
+}
+
+// testServiceProvider returns a service provider trusting the identity provider.
+func testServiceProvider(idp identityProvider) *ServiceProvider {
+	return &ServiceProvider{
+		EntityID:        "https://tasks.example.com/saml/metadata",
+		ACSURL:          "https://tasks.example.com/saml/acs",
+		IdPEntityID:     "https://idp.example.com",
+		IdPSSOURL:       "https://idp.example.com/sso?tenant=acme",
+		IdPCertificates: []*x509.Certificate{idp.certificate},
+		EmailAttribute:  "email",
+		ClockSkew:       time.Minute,
+	}
+}
+
+// assertionXML returns an assertion for alice answering the request, valid for five minutes from now,
+// with signatureMarker after its issuer.
+func assertionXML(requestID, audience string, now time.Time) string {
+	notOnOrAfter := now.Add(5 * time.Minute).UTC().Format(time.RFC3339)
+	return `<saml:Assertion xmlns:saml="` + nsAssertion + `" ID="assertion-1" Version="2.0" IssueInstant="` + now.UTC().Format(time.RFC3339) + `">` +
+		`<saml:Issuer>https://idp.example.com</saml:Issuer>` + signatureMarker +
+		`<saml:Subject><saml:NameID>alice@example.com</saml:NameID>` +
+		`<saml:SubjectConfirmation Method="` + confirmBearer + `"><saml:SubjectConfirmationData InResponseTo="` + requestID + `"` +
+		` NotOnOrAfter="` + notOnOrAfter + `" Recipient="https://tasks.example.com/saml/acs"/></saml:SubjectConfirmation></saml:Subject>` +
+		`<saml:Conditions NotBefore="` + now.Add(-time.Minute).UTC().Format(time.RFC3339) + `" NotOnOrAfter="` + notOnOrAfter + `">` +
+		`<saml:AudienceRestriction><saml:Audience>` + audience + `</saml:Audience></saml:AudienceRestriction></saml:Conditions>` +
+		`<saml:AttributeStatement>` +
+		`<saml:Attribute Name="urn:oid:0.9.2342.19200300.100.1.1" FriendlyName="uid"><saml:AttributeValue>alice</saml:AttributeValue></saml:Attribute>` +
+		`<saml:Attribute Name="email"><saml:AttributeValue>alice@example.com</saml:AttributeValue></saml:Attribute>` +
+		`</saml:AttributeStatement></saml:Assertion>`
+}
+
+// responseXML wraps an assertion into a response to the request with the given status, with signatureMarker
+// after its issuer.
+func responseXML(requestID, assertion, status string) string {
+	return `<samlp:Response xmlns:samlp="` + nsProtocol + `" xmlns:saml="` + nsAssertion + `" ID="response-1" Version="2.0"` +
+		` InResponseTo="` + requestID + `" Destination="https://tasks.example.com/saml/acs">` +
+		`<saml:Issuer>https://idp.example.com</saml:Issuer>` + signatureMarker +
+		`<samlp:Status><samlp:StatusCode Value="` + status + `"/></samlp:Status>` + assertion + `</samlp:Response>`
+}
+
+// encode base64-encodes a response as the HTTP-POST binding does.
+func encode(response string) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Replace(response, signatureMarker, "", 1)))
+}
+
+// TestParseResponse tests that signed responses to the request are accepted and everything else rejected
+func TestParseResponse(t *testing.T) {
+	idp := newIdentityProvider(t)
+	sp := testServiceProvider(idp)
+	now := time.Now()
+	signedAssertion := idp.sign(t, assertionXML("id-1", sp.EntityID, now), "assertion-1")
+
+	assertion, err := sp.ParseResponse(encode(responseXML("id-1", signedAssertion, statusSuccess)), "id-1", now)
+	require.NoError(t, err)
+	// Assert that the subject and the attributes, by name and friendly name, are returned
+	assert.Equal(t, "alice@example.com", assertion.NameID)
+	assert.Equal(t, []string{"alice"}, assertion.Attributes["uid"])
+	assert.Equal(t, []string{"alice"}, assertion.Attributes["urn:oid:0.9.2342.19200300.100.1.1"])
+	assert.Equal(t, []string{"alice@example.com"}, assertion.Attributes["email"])
+
+	// Assert that a signed response with an unsigned assertion is accepted as well
+	signedResponse := idp.sign(t, responseXML("id-1", strings.Replace(assertionXML("id-1", sp.EntityID, now), signatureMarker, "", 1), statusSuccess), "response-1")
+	_, err = sp.ParseResponse(encode(signedResponse), "id-1", now)
+	assert.NoError(t, err)
+
+	// Assert that a response to another request, and expired or not yet valid assertions, are rejected
+	_, err = sp.ParseResponse(encode(responseXML("id-1", signedAssertion, statusSuccess)), "id-2", now)
+	assert.ErrorIs(t, err, ErrWrongRequest)
+	_, err = sp.ParseResponse(encode(responseXML("id-1", signedAssertion, statusSuccess)), "id-1", now.Add(10*time.Minute))
+	assert.ErrorIs(t, err, ErrExpired)
+	_, err = sp.ParseResponse(encode(responseXML("id-1", signedAssertion, statusSuccess)), "id-1", now.Add(-10*time.Minute))
+	assert.ErrorIs(t, err, ErrExpired)
+
+	// Assert that assertions for another audience and failed authentications are rejected
+	otherAudience := idp.sign(t, assertionXML("id-1", "https://other.example.com", now), "assertion-1")
+	_, err = sp.ParseResponse(encode(responseXML("id-1", otherAudience, statusSuccess)), "id-1", now)
+	assert.ErrorIs(t, err, ErrWrongRecipient)
+	_, err = sp.ParseResponse(encode(responseXML("id-1", signedAssertion, "urn:oasis:names:tc:SAML:2.0:status:Responder")), "id-1", now)
+	assert.ErrorIs(t, err, ErrFailed)
+}
+
+// TestParseResponseSignature tests that unsigned, tampered, foreign and wrapped assertions are rejected
+func TestParseResponseSignature(t *testing.T) {
+	idp := newIdentityProvider(t)
+	sp := testServiceProvider(idp)
+	now := time.Now()
+	unsigned := strings.Replace(assertionXML("id-1", sp.EntityID, now), signatureMarker, "", 1)
+	signed := idp.sign(t, assertionXML("id-1", sp.EntityID, now), "assertion-1")
+
+	// Assert that unsigned assertions are rejected
+	_, err := sp.ParseResponse(encode(responseXML("id-1", unsigned, statusSuccess)), "id-1", now)
+	assert.ErrorIs(t, err, ErrUnsigned)
+
+	// Assert that changing a signed assertion breaks its signature
+	tampered := strings.Replace(signed, "<saml:NameID>alice@example.com", "<saml:NameID>admin", 1)
+	_, err = sp.ParseResponse(encode(responseXML("id-1", tampered, statusSuccess)), "id-1", now)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// Assert that assertions signed by another key are rejected
+	foreign := newIdentityProvider(t).sign(t, assertionXML("id-1", sp.EntityID, now), "assertion-1")
+	_, err = sp.ParseResponse(encode(responseXML("id-1", foreign, statusSuccess)), "id-1", now)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// Assert that an unsigned assertion next to a signed one, or wrapping it, is not passed off as signed
+	evil := strings.Replace(unsigned, "alice", "admin", -1)
+	_, err = sp.ParseResponse(encode(responseXML("id-1", evil+signed, statusSuccess)), "id-1", now)
+	assert.ErrorIs(t, err, ErrMalformed)
+	wrapped := strings.Replace(evil, "</saml:Assertion>", "<saml:Advice>"+signed+"</saml:Advice></saml:Assertion>", 1)
+	_, err = sp.ParseResponse(encode(responseXML("id-1", wrapped, statusSuccess)), "id-1", now)
+	assert.ErrorIs(t, err, ErrUnsigned)
+
+	// Assert that a signature moved into another assertion, its reference pointing at the signed one, is
+	// rejected, as is an assertion added next to a signed response
+	moved := strings.Replace(evil, `ID="assertion-1"`, `ID="assertion-2"`, 1)
+	moved = strings.Replace(moved, "</saml:Issuer>", "</saml:Issuer>"+signatureOf(t, signed), 1)
+	moved = strings.Replace(moved, "</saml:Assertion>", "<saml:Advice>"+signed+"</saml:Advice></saml:Assertion>", 1)
+	_, err = sp.ParseResponse(encode(responseXML("id-1", moved, statusSuccess)), "id-1", now)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	signedResponse := idp.sign(t, responseXML("id-1", unsigned, statusSuccess), "response-1")
+	_, err = sp.ParseResponse(encode(strings.Replace(signedResponse, "</samlp:Response>", evil+"</samlp:Response>", 1)), "id-1", now)
+	assert.ErrorIs(t, err, ErrMalformed)
+	_, err = sp.ParseResponse(encode(strings.Replace(signedResponse, "<saml:Assertion ", evil+"<saml:Assertion ", 1)), "id-1", now)
+	assert.ErrorIs(t, err, ErrMalformed)
+
+	// Assert that a comment inside the NameID, which canonicalization drops, does not cut the name short
+	long := strings.Replace(assertionXML("id-1", sp.EntityID, now), "alice@example.com</saml:NameID>", "alice@example.com.evil.example</saml:NameID>", 1)
+	commented := strings.Replace(idp.sign(t, long, "assertion-1"), "alice@example.com.evil", "alice@example.com<!---->.evil", 1)
+	assertion, err := sp.ParseResponse(encode(responseXML("id-1", commented, statusSuccess)), "id-1", now)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com.evil.example", assertion.NameID)
+
+	// Assert that SHA-1 signatures are rejected, and any of several certificates is trusted during rotation
+	sha1 := strings.Replace(signed, dsig.RSASHA256SignatureMethod, dsig.RSASHA1SignatureMethod, 1)
+	_, err = sp.ParseResponse(encode(responseXML("id-1", sha1, statusSuccess)), "id-1", now)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	sp.IdPCertificates = []*x509.Certificate{newIdentityProvider(t).certificate, idp.certificate}
+	_, err = sp.ParseResponse(encode(responseXML("id-1", signed, statusSuccess)), "id-1", now)
+	assert.NoError(t, err)
+
+	// Assert that documents with a DTD are rejected
+	_, err = sp.ParseResponse(base64.StdEncoding.EncodeToString([]byte(`<!DOCTYPE r [<!ENTITY x "y">]><r/>`)), "id-1", now)
+	assert.Error(t, err)
+}
+
+// TestAuthnRequestURL tests that the request is deflated into the query of the single sign-on URL
+func TestAuthnRequestURL(t *testing.T) {
+	sp := testServiceProvider(newIdentityProvider(t))
+	id, err := NewRequestID()
+	require.NoError(t, err)
+
+	location, err := sp.AuthnRequestURL(id, "state", time.Now())
+	require.NoError(t, err)
+	parsed, err := url.Parse(location)
+	require.NoError(t, err)
+	// Assert that the query of the IdP URL is kept and the relay state added
+	assert.Equal(t, "acme", parsed.Query().Get("tenant"))
+	assert.Equal(t, "state", parsed.Query().Get("RelayState"))
+
+	deflated, err := base64.StdEncoding.DecodeString(parsed.Query().Get("SAMLRequest"))
+	require.NoError(t, err)
+	request, err := io.ReadAll(flate.NewReader(bytes.NewReader(deflated)))
+	require.NoError(t, err)
+	parsedRequest, err := parseDocument(request)
+	require.NoError(t, err)
+	// Assert that the request has the ID and names the service and its assertion consumer service
+	assert.True(t, is(parsedRequest, nsProtocol, "AuthnRequest"))
+	assert.Equal(t, id, attr(parsedRequest, "ID"))
+	assert.Equal(t, sp.ACSURL, attr(parsedRequest, "AssertionConsumerServiceURL"))
+	assert.Equal(t, sp.EntityID, text(child(parsedRequest, nsAssertion, "Issuer")))
+}
+
+// TestIdentity tests the mapping of assertions to usernames and email addresses
+func TestIdentity(t *testing.T) {
+	sp := testServiceProvider(newIdentityProvider(t))
+	assertion := Assertion{NameID: "alice@example.com", Attributes: map[string][]string{"uid": {"alice"}, "email": {"alice@example.com"}}}
+
+	// Assert that the NameID is the username unless an attribute is configured
+	username, email, err := sp.Identity(assertion)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", username)
+	assert.Equal(t, "alice@example.com", email)
+
+	sp.UsernameAttribute = "uid"
+	username, _, err = sp.Identity(assertion)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", username)
+
+	// Assert that assertions without the username attribute are rejected
+	sp.UsernameAttribute = "employeeNumber"
+	_, _, err = sp.Identity(assertion)
+	assert.ErrorIs(t, err, ErrMissingUsername)
+}
+
+// TestSubject tests that accounts are linked to the issuer and a NameID that is not transient
+func TestSubject(t *testing.T) {
+	sp := testServiceProvider(newIdentityProvider(t))
+
+	// Assert that the subject names the identity provider and the NameID
+	subject, err := sp.Subject(Assertion{NameID: "alice@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "saml|"+sp.IdPEntityID+"|alice@example.com", subject)
+
+	// Assert that missing and transient NameIDs are rejected
+	_, err = sp.Subject(Assertion{})
+	assert.ErrorIs(t, err, ErrMissingSubject)
+	_, err = sp.Subject(Assertion{NameID: "_4f2a", NameIDFormat: "urn:oasis:names:tc:SAML:2.0:nameid-format:transient"})
+	assert.ErrorIs(t, err, ErrMissingSubject)
+}
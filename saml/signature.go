@@ -0,0 +1,69 @@
+// signature.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package saml
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+)
+
+// Errors of signature verification
+var (
+	ErrUnsigned         = errors.New("saml: response is not signed")
+	ErrInvalidSignature = errors.New("saml: invalid signature")
+)
+
+// acceptedAlgorithms are the signature and digest methods that are accepted. SHA-1 is not.
+var acceptedAlgorithms = map[string]bool{
+	dsig.RSASHA256SignatureMethod:             true,
+	dsig.RSASHA512SignatureMethod:             true,
+	"http://www.w3.org/2001/04/xmlenc#sha256": true,
+	"http://www.w3.org/2001/04/xmlenc#sha512": true,
+}
+
+// verifySignature checks the enveloped signature of e with goxmldsig: that it covers e as a whole, by e's
+// ID, and was made with the key of one of the certificates, valid at now. The key info in the signature
+// is only used to pick one of the configured certificates, which alone are trusted.
+//
+// It returns the element as it was signed, re-parsed from its verified canonical form; callers must read
+// only from it, never from e, so that content wrapped around or inserted into e cannot pass as signed.
+func verifySignature(e *etree.Element, certificates []*x509.Certificate, now time.Time) (*etree.Element, error) {
+	signature := child(e, nsSignature, "Signature")
+	if signature == nil {
+		return nil, ErrUnsigned
+	}
+	for _, path := range []string{"./SignedInfo/SignatureMethod", "./SignedInfo/Reference/DigestMethod"} {
+		for _, method := range signature.FindElements(path) {
+			if !acceptedAlgorithms[method.SelectAttrValue("Algorithm", "")] {
+				return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidSignature, method.SelectAttrValue("Algorithm", ""))
+			}
+		}
+	}
+
+	// The namespaces declared on the ancestors of e are part of what was signed
+	scope, err := etreeutils.NSBuildParentContext(e)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	detached, err := etreeutils.NSDetatch(scope, e)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	// Certificates are tried one by one, so that signatures without key info verify during rotation
+	for _, certificate := range certificates {
+		validation := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{Roots: []*x509.Certificate{certificate}})
+		validation.Clock = dsig.NewFakeClockAt(now)
+		if signed, err := validation.Validate(detached); err == nil {
+			return signed, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: not signed by the identity provider", ErrInvalidSignature)
+}
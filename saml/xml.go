@@ -0,0 +1,84 @@
+// xml.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package saml
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+// Namespaces of SAML and XML signatures
+const (
+	nsProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	nsAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+	nsMetadata  = "urn:oasis:names:tc:SAML:2.0:metadata"
+	nsSignature = "http://www.w3.org/2000/09/xmldsig#"
+)
+
+// parseDocument parses a document into its root element. Documents with a DTD are rejected.
+func parseDocument(data []byte) (*etree.Element, error) {
+	document := etree.NewDocument()
+	if err := document.ReadFromBytes(data); err != nil {
+		return nil, fmt.Errorf("saml: malformed XML: %w", err)
+	}
+	for _, token := range document.Child {
+		if _, ok := token.(*etree.Directive); ok {
+			return nil, errors.New("saml: DTDs are not allowed")
+		}
+	}
+	if document.Root() == nil {
+		return nil, errors.New("saml: incomplete XML")
+	}
+	return document.Root(), nil
+}
+
+// is reports whether the element has the given namespace and local name.
+func is(e *etree.Element, space, local string) bool {
+	return e.NamespaceURI() == space && e.Tag == local
+}
+
+// attr returns the value of an attribute without namespace, or "".
+func attr(e *etree.Element, local string) string {
+	for _, a := range e.Attr {
+		if a.Space == "" && a.Key == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// childElements returns the child elements with the given namespace and local name.
+func childElements(e *etree.Element, space, local string) []*etree.Element {
+	found := []*etree.Element{}
+	for _, child := range e.ChildElements() {
+		if is(child, space, local) {
+			found = append(found, child)
+		}
+	}
+	return found
+}
+
+// child returns the only child element with the given namespace and local name, or nil if there is none
+// or more than one.
+func child(e *etree.Element, space, local string) *etree.Element {
+	if found := childElements(e, space, local); len(found) == 1 {
+		return found[0]
+	}
+	return nil
+}
+
+// text returns all character data of the element, trimmed. Unlike etree's Text, which stops at the first
+// comment, it reads the text on both sides of comments, the way the canonical form it was signed in does.
+func text(e *etree.Element) string {
+	var text strings.Builder
+	for _, token := range e.Child {
+		if data, ok := token.(*etree.CharData); ok {
+			text.WriteString(data.Data)
+		}
+	}
+	return strings.TrimSpace(text.String())
+}
@@ -0,0 +1,305 @@
+// elastic.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/fieldcrypt"
+	"github.com/bkojha74/task-management/helper"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Elastic is a Backend on Elasticsearch or OpenSearch, for datasets where the MongoDB text index is not
+// enough. Tasks are mirrored into an index by Index and Delete, which the change stream calls for every
+// write; projects are few per user and are still searched in the repository.
+type Elastic struct {
+	URL      string       // Base URL of the cluster, e.g. http://localhost:9200
+	Index    string       // Name of the task index
+	Username string       // Basic auth user, optional
+	Password string       // Basic auth password
+	Client   *http.Client // HTTP client to use; http.DefaultClient when nil
+}
+
+// elasticTask is the indexed form of a task. Encrypted descriptions are left out, so that the index never
+// holds what the database only stores encrypted.
+type elasticTask struct {
+	UserID      string    `json:"user_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	AllottedTo  string    `json:"allotted_to"`
+	Status      string    `json:"status"`
+	ProjectID   string    `json:"project_id,omitempty"`
+	Visibility  string    `json:"visibility,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// elasticMappings are the mappings of the task index: full-text fields with a keyword variant of the
+// assignee for filtering, and keywords for the IDs.
+var elasticMappings = map[string]interface{}{
+	"mappings": map[string]interface{}{
+		"properties": map[string]interface{}{
+			"user_id":     map[string]string{"type": "keyword"},
+			"title":       map[string]string{"type": "text"},
+			"description": map[string]string{"type": "text"},
+			"allotted_to": map[string]interface{}{"type": "text", "fields": map[string]interface{}{"keyword": map[string]string{"type": "keyword"}}},
+			"status":      map[string]string{"type": "keyword"},
+			"project_id":  map[string]string{"type": "keyword"},
+			"visibility":  map[string]string{"type": "keyword"},
+			"updated_at":  map[string]string{"type": "date"},
+		},
+	},
+}
+
+// LoadElastic creates the Elasticsearch backend when SEARCH_BACKEND is "elasticsearch" (or "opensearch"),
+// from ELASTICSEARCH_URL, ELASTICSEARCH_INDEX (default "tasks"), ELASTICSEARCH_USERNAME and
+// ELASTICSEARCH_PASSWORD. Searches stay on MongoDB when SEARCH_BACKEND is empty or "mongo".
+//
+// Returns:
+// - *Elastic: The configured backend, or nil when none is configured.
+// - error: An error if the backend is unknown or ELASTICSEARCH_URL is missing.
+func LoadElastic() (*Elastic, error) {
+	switch kind := helper.GetEnv("SEARCH_BACKEND"); kind {
+	case "", "mongo":
+		return nil, nil
+	case "elasticsearch", "opensearch":
+		url := strings.TrimRight(helper.GetEnv("ELASTICSEARCH_URL"), "/")
+		if url == "" {
+			return nil, fmt.Errorf("ELASTICSEARCH_URL must be set for the %s search backend", kind)
+		}
+		index := helper.GetEnv("ELASTICSEARCH_INDEX")
+		if index == "" {
+			index = "tasks"
+		}
+		return &Elastic{
+			URL:      url,
+			Index:    index,
+			Username: helper.GetEnv("ELASTICSEARCH_USERNAME"),
+			Password: helper.GetEnv("ELASTICSEARCH_PASSWORD"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown SEARCH_BACKEND %q, use mongo, elasticsearch or opensearch", kind)
+	}
+}
+
+// EnsureIndex creates the task index with its mappings unless it exists.
+//
+// Parameters:
+// - ctx: Context for the request.
+//
+// Returns:
+// - error: An error if the index could not be created.
+func (e *Elastic) EnsureIndex(ctx context.Context) error {
+	err := e.do(ctx, http.MethodPut, "/"+e.Index, elasticMappings, nil)
+	var elasticErr *ElasticError
+	if errors.As(err, &elasticErr) && elasticErr.Status == http.StatusBadRequest && strings.Contains(elasticErr.Body, "resource_already_exists_exception") {
+		return nil
+	}
+	return err
+}
+
+// IndexTask stores a task in the index, replacing the previous version.
+//
+// Parameters:
+// - ctx: Context for the request.
+// - task: The task as stored in the database.
+//
+// Returns:
+// - error: An error if the task could not be indexed.
+func (e *Elastic) IndexTask(ctx context.Context, task models.Task) error {
+	document := elasticTask{
+		UserID:      task.UserID.Hex(),
+		Title:       task.Title,
+		Description: task.Description,
+		AllottedTo:  task.AllottedTo,
+		Status:      task.Status,
+		Visibility:  task.Visibility,
+		UpdatedAt:   task.UpdatedAt.Time(),
+	}
+	if fieldcrypt.IsEncrypted(document.Description) {
+		document.Description = ""
+	}
+	if !task.ProjectID.IsZero() {
+		document.ProjectID = task.ProjectID.Hex()
+	}
+	return e.do(ctx, http.MethodPut, "/"+e.Index+"/_doc/"+task.ID.Hex(), document, nil)
+}
+
+// DeleteTask removes a task from the index. Tasks that are not indexed are ignored.
+//
+// Parameters:
+// - ctx: Context for the request.
+// - taskID: ID of the deleted task.
+//
+// Returns:
+// - error: An error if the task could not be removed.
+func (e *Elastic) DeleteTask(ctx context.Context, taskID primitive.ObjectID) error {
+	err := e.do(ctx, http.MethodDelete, "/"+e.Index+"/_doc/"+taskID.Hex(), nil, nil)
+	var elasticErr *ElasticError
+	if errors.As(err, &elasticErr) && elasticErr.Status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// Search runs a query: tasks in the index, projects in the repository.
+func (e *Elastic) Search(ctx context.Context, query Query) (Results, error) {
+	results := Results{}
+	if includes(query.Types, TypeTask) {
+		tasks, err := e.searchTasks(ctx, query)
+		if err != nil {
+			return Results{}, err
+		}
+		results.Tasks = tasks
+	}
+	if includes(query.Types, TypeProject) {
+		hits, err := searchProjects(ctx, query, Words(query.Text))
+		if err != nil {
+			return Results{}, err
+		}
+		results.Projects = page(hits, query)
+	}
+	return results, nil
+}
+
+// searchTasks finds the tasks the user owns or is the assignee of, the tasks they may read, by relevance
+// and then most recently updated first, highlighted by the cluster.
+func (e *Elastic) searchTasks(ctx context.Context, query Query) (*Group, error) {
+	user, err := repository.Users.FindByID(ctx, query.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	request := map[string]interface{}{
+		"from":             query.Offset,
+		"size":             query.Limit,
+		"track_total_hits": true,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": map[string]interface{}{
+					"multi_match": map[string]interface{}{
+						"query":    query.Text,
+						"fields":   []string{"title^2", "description", "allotted_to"},
+						"operator": "and",
+					},
+				},
+				"filter": map[string]interface{}{
+					"bool": map[string]interface{}{
+						"should": []interface{}{
+							map[string]interface{}{"term": map[string]string{"user_id": query.UserID.Hex()}},
+							map[string]interface{}{"term": map[string]string{"allotted_to.keyword": user.Username}},
+						},
+						"minimum_should_match": 1,
+					},
+				},
+			},
+		},
+		"sort": []interface{}{"_score", map[string]string{"updated_at": "desc"}},
+		"highlight": map[string]interface{}{
+			"pre_tags":  []string{"<mark>"},
+			"post_tags": []string{"</mark>"},
+			"encoder":   "html",
+			"fields": map[string]interface{}{
+				"title":       map[string]int{"number_of_fragments": 0},
+				"description": map[string]int{"fragment_size": fragmentLength, "number_of_fragments": 1},
+				"allotted_to": map[string]int{"number_of_fragments": 0},
+			},
+		},
+	}
+
+	var response struct {
+		Hits struct {
+			Total struct {
+				Value int `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				ID        string              `json:"_id"`
+				Source    elasticTask         `json:"_source"`
+				Highlight map[string][]string `json:"highlight"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := e.do(ctx, http.MethodPost, "/"+e.Index+"/_search", request, &response); err != nil {
+		return nil, err
+	}
+
+	group := &Group{Total: response.Hits.Total.Value, Hits: []Hit{}}
+	for _, found := range response.Hits.Hits {
+		hit := Hit{Title: found.Source.Title, Status: found.Source.Status, Highlights: map[string]string{}}
+		hit.ID, _ = primitive.ObjectIDFromHex(found.ID)
+		hit.ProjectID, _ = primitive.ObjectIDFromHex(found.Source.ProjectID)
+		for field, fragments := range found.Highlight {
+			if len(fragments) > 0 {
+				hit.Highlights[field] = fragments[0]
+			}
+		}
+		group.Hits = append(group.Hits, hit)
+	}
+	return group, nil
+}
+
+// ElasticError is a response of the cluster other than 2xx.
+type ElasticError struct {
+	Status int    // HTTP status code
+	Body   string // Response body, describing the error
+}
+
+// Error returns the status and the error description of the cluster.
+func (e *ElasticError) Error() string {
+	return fmt.Sprintf("elasticsearch answered %d: %s", e.Status, e.Body)
+}
+
+// do sends a request with body as JSON and decodes the response into out, if given. Responses other than
+// 2xx are returned as *ElasticError.
+func (e *Elastic) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, e.URL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if e.Username != "" {
+		req.SetBasicAuth(e.Username, e.Password)
+	}
+
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		description, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &ElasticError{Status: resp.StatusCode, Body: string(description)}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
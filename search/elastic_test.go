@@ -0,0 +1,79 @@
+// elastic_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bkojha74/task-management/fieldcrypt"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestElastic tests that tasks are mirrored into the index and searches are answered from it
+func TestElastic(t *testing.T) {
+	repository.UseMemory()
+	ctx := context.Background()
+	user := models.User{Username: "alice"}
+	require.NoError(t, repository.Users.Create(ctx, &user))
+
+	taskID := primitive.NewObjectID()
+	var indexed map[string]interface{}
+	var searched map[string]interface{}
+	cluster := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "PUT /tasks":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": {"type": "resource_already_exists_exception"}}`))
+		case "PUT /tasks/_doc/" + taskID.Hex():
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&indexed))
+		case "DELETE /tasks/_doc/" + taskID.Hex():
+			w.WriteHeader(http.StatusNotFound)
+		case "POST /tasks/_search":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&searched))
+			_, _ = w.Write([]byte(`{"hits": {"total": {"value": 7}, "hits": [{"_id": "` + taskID.Hex() + `",
+				"_source": {"title": "Budget review", "status": "Pending"}, "highlight": {"title": ["<mark>Budget</mark> review"]}}]}}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer cluster.Close()
+	elastic := &Elastic{URL: cluster.URL, Index: "tasks"}
+
+	// Assert that an existing index is not an error, and neither is deleting a task that isn't indexed
+	require.NoError(t, elastic.EnsureIndex(ctx))
+	require.NoError(t, elastic.DeleteTask(ctx, taskID))
+
+	keyring, err := fieldcrypt.NewKeyring(fieldcrypt.Key{ID: "k1", Secret: make([]byte, 32)})
+	require.NoError(t, err)
+	secret, err := keyring.Encrypt("Salaries")
+	require.NoError(t, err)
+	require.NoError(t, elastic.IndexTask(ctx, models.Task{ID: taskID, UserID: user.ID, Title: "Budget review", Description: secret}))
+	// Assert that tasks are indexed by owner without their encrypted descriptions
+	assert.Equal(t, user.ID.Hex(), indexed["user_id"])
+	assert.Equal(t, "Budget review", indexed["title"])
+	assert.NotContains(t, indexed, "description")
+
+	results, err := elastic.Search(ctx, Query{Text: "budget", UserID: user.ID, Types: []string{TypeTask}, Limit: 1, Offset: 3})
+	require.NoError(t, err)
+	// Assert that the page is requested from the cluster and its hits are returned with their highlights
+	assert.Equal(t, float64(3), searched["from"])
+	assert.Equal(t, float64(1), searched["size"])
+	assert.Nil(t, results.Projects)
+	require.Len(t, results.Tasks.Hits, 1)
+	assert.Equal(t, 7, results.Tasks.Total)
+	assert.Equal(t, taskID, results.Tasks.Hits[0].ID)
+	assert.Equal(t, "<mark>Budget</mark> review", results.Tasks.Hits[0].Highlights["title"])
+
+	// Assert that other errors of the cluster are returned
+	assert.Error(t, elastic.IndexTask(ctx, models.Task{ID: primitive.NewObjectID()}))
+}
@@ -0,0 +1,75 @@
+// highlight.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+// fragmentLength is the longest highlighted fragment, in characters, not counting the markup.
+const fragmentLength = 160
+
+// Highlight returns a fragment of text with the given words wrapped in <mark> tags. The rest of the text
+// is HTML-escaped, so the fragment can be rendered as it is. Texts longer than a fragment are cut around
+// the first match and the cuts marked with an ellipsis.
+//
+// Parameters:
+// - text: The text to highlight, e.g. a task description.
+// - words: The lower-case words to highlight, as returned by Words.
+//
+// Returns:
+// - string: The highlighted fragment.
+// - bool: Whether the text contains any of the words.
+func Highlight(text string, words []string) (string, bool) {
+	wanted := map[string]bool{}
+	for _, word := range words {
+		wanted[word] = true
+	}
+
+	// Find the words of the text that were searched for, as rune offsets
+	runes := []rune(text)
+	var matches [][2]int
+	start := -1
+	for i := 0; i <= len(runes); i++ {
+		inWord := i < len(runes) && !isSeparator(runes[i])
+		if inWord && start < 0 {
+			start = i
+		}
+		if !inWord && start >= 0 {
+			if wanted[strings.ToLower(string(runes[start:i]))] {
+				matches = append(matches, [2]int{start, i})
+			}
+			start = -1
+		}
+	}
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	from, to := 0, len(runes)
+	if len(runes) > fragmentLength {
+		from = max(0, min(matches[0][0]-fragmentLength/4, len(runes)-fragmentLength))
+		to = from + fragmentLength
+	}
+
+	var fragment strings.Builder
+	if from > 0 {
+		fragment.WriteString("…")
+	}
+	at := from
+	for _, match := range matches {
+		if match[0] < from || match[1] > to {
+			continue
+		}
+		fragment.WriteString(html.EscapeString(string(runes[at:match[0]])))
+		fragment.WriteString("<mark>" + html.EscapeString(string(runes[match[0]:match[1]])) + "</mark>")
+		at = match[1]
+	}
+	fragment.WriteString(html.EscapeString(string(runes[at:to])))
+	if to < len(runes) {
+		fragment.WriteString("…")
+	}
+	return fragment.String(), true
+}
@@ -0,0 +1,237 @@
+// search.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package search finds the tasks and projects a user can access by the words they contain, for the global
+// search. Results are grouped by type and carry highlighted fragments of the fields that matched.
+package search
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/permissions"
+	"github.com/bkojha74/task-management/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Result types
+const (
+	TypeTask    = "task"
+	TypeProject = "project"
+)
+
+// DefaultLimit is the number of results returned per type when a query doesn't set one.
+const DefaultLimit = 20
+
+// Query is a search made by a user.
+type Query struct {
+	Text   string             // The words to find, all of which must match
+	UserID primitive.ObjectID // Who searches
+	Types  []string           // The result types to search, all of them when empty
+	Limit  int                // Results per type
+	Offset int                // Results per type to skip, for the next pages
+}
+
+// Hit is a task or project found by a search.
+type Hit struct {
+	ID         primitive.ObjectID `json:"id"`
+	Title      string             `json:"title"`                // Title of the task, or name of the project
+	Status     string             `json:"status,omitempty"`     // Status of a task
+	ProjectID  primitive.ObjectID `json:"project_id,omitempty"` // Project of a task
+	Highlights map[string]string  `json:"highlights"`           // Fragments of the matching fields, matches in <mark>
+}
+
+// Group holds a page of the hits of one type.
+type Group struct {
+	Total int   `json:"total"` // Hits of this type on all pages
+	Hits  []Hit `json:"hits"`
+}
+
+// Results are the hits of a search grouped by type. Types that were not searched are left out.
+type Results struct {
+	Tasks    *Group `json:"tasks,omitempty"`
+	Projects *Group `json:"projects,omitempty"`
+}
+
+// Backend runs searches. The default backend queries the repositories, with a MongoDB text index on tasks;
+// a search engine can be plugged in by replacing Default.
+type Backend interface {
+	Search(ctx context.Context, query Query) (Results, error)
+}
+
+// Default is the backend used by the search endpoint.
+var Default Backend = Repository{}
+
+// Repository is a Backend searching the repositories: the tasks owned by or allotted to the user that
+// they may read, and the projects they own.
+type Repository struct{}
+
+// Search runs a query.
+func (Repository) Search(ctx context.Context, query Query) (Results, error) {
+	results := Results{}
+	words := Words(query.Text)
+	if includes(query.Types, TypeTask) {
+		hits, err := searchTasks(ctx, query, words)
+		if err != nil {
+			return Results{}, err
+		}
+		results.Tasks = page(hits, query)
+	}
+	if includes(query.Types, TypeProject) {
+		hits, err := searchProjects(ctx, query, words)
+		if err != nil {
+			return Results{}, err
+		}
+		results.Projects = page(hits, query)
+	}
+	return results, nil
+}
+
+// searchTasks returns the hits among the tasks owned by or allotted to the user, most recently updated
+// first.
+func searchTasks(ctx context.Context, query Query, words []string) ([]Hit, error) {
+	user, err := repository.Users.FindByID(ctx, query.UserID)
+	if err != nil {
+		return nil, err
+	}
+	owned, err := repository.Tasks.Find(ctx, repository.TaskQuery{UserID: query.UserID, Text: query.Text})
+	if err != nil {
+		return nil, err
+	}
+	allotted, err := repository.Tasks.Find(ctx, repository.TaskQuery{AllottedTo: user.Username, Text: query.Text})
+	if err != nil {
+		return nil, err
+	}
+
+	subject := permissions.Subject{UserID: query.UserID}
+	seen := map[primitive.ObjectID]bool{}
+	tasks := []models.Task{}
+	for _, task := range append(owned, allotted...) {
+		if seen[task.ID] {
+			continue
+		}
+		seen[task.ID] = true
+		ok, err := permissions.CanRead(ctx, subject, task)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].UpdatedAt != tasks[j].UpdatedAt {
+			return tasks[i].UpdatedAt > tasks[j].UpdatedAt
+		}
+		return tasks[i].ID.Hex() > tasks[j].ID.Hex()
+	})
+
+	hits := make([]Hit, 0, len(tasks))
+	for _, task := range tasks {
+		hits = append(hits, Hit{
+			ID:         task.ID,
+			Title:      task.Title,
+			Status:     task.Status,
+			ProjectID:  task.ProjectID,
+			Highlights: highlights(words, map[string]string{"title": task.Title, "description": task.Description, "allotted_to": task.AllottedTo}),
+		})
+	}
+	return hits, nil
+}
+
+// searchProjects returns the hits among the projects of the user, oldest first.
+func searchProjects(ctx context.Context, query Query, words []string) ([]Hit, error) {
+	projects, err := repository.Projects.Find(ctx, query.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := []Hit{}
+	for _, project := range projects {
+		if !containsAll(project.Name, words) {
+			continue
+		}
+		hits = append(hits, Hit{
+			ID:         project.ID,
+			Title:      project.Name,
+			Highlights: highlights(words, map[string]string{"name": project.Name}),
+		})
+	}
+	return hits, nil
+}
+
+// page returns the hits on the page selected by the query.
+func page(hits []Hit, query Query) *Group {
+	group := &Group{Total: len(hits), Hits: []Hit{}}
+	if query.Offset < len(hits) {
+		hits = hits[query.Offset:]
+		group.Hits = hits[:min(query.Limit, len(hits))]
+	}
+	return group
+}
+
+// highlights returns the highlighted fragments of the fields containing any of the words.
+func highlights(words []string, fields map[string]string) map[string]string {
+	result := map[string]string{}
+	for field, text := range fields {
+		if fragment, ok := Highlight(text, words); ok {
+			result[field] = fragment
+		}
+	}
+	return result
+}
+
+// containsAll reports whether text contains every one of the words.
+func containsAll(text string, words []string) bool {
+	have := map[string]bool{}
+	for _, word := range Words(text) {
+		have[word] = true
+	}
+	for _, word := range words {
+		if !have[word] {
+			return false
+		}
+	}
+	return true
+}
+
+// includes reports whether types selects the given type; no types select all of them.
+func includes(types []string, kind string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, item := range types {
+		if item == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Words returns the distinct lower-case words of a text, in order.
+//
+// Parameters:
+// - text: The text, e.g. a search query.
+//
+// Returns:
+// - []string: The words of the text.
+func Words(text string) []string {
+	seen := map[string]bool{}
+	words := []string{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), isSeparator) {
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// isSeparator reports whether r separates words.
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
@@ -0,0 +1,78 @@
+// search_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package search
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHighlight tests that matching words are marked and the rest of the text is escaped
+func TestHighlight(t *testing.T) {
+	fragment, ok := Highlight("Fix the <b>Login</b> page, login again", Words("login"))
+	// Assert that every match is marked, ignoring case, and markup in the text is escaped
+	assert.True(t, ok)
+	assert.Equal(t, "Fix the &lt;b&gt;<mark>Login</mark>&lt;/b&gt; page, <mark>login</mark> again", fragment)
+
+	// Assert that words are only matched as a whole
+	_, ok = Highlight("Logins are slow", Words("login"))
+	assert.False(t, ok)
+
+	// Assert that long texts are cut around the first match
+	fragment, ok = Highlight(strings.Repeat("filler ", 50)+"deadline "+strings.Repeat("filler ", 50), Words("deadline"))
+	assert.True(t, ok)
+	assert.True(t, strings.HasPrefix(fragment, "…"))
+	assert.True(t, strings.HasSuffix(fragment, "…"))
+	assert.Contains(t, fragment, "<mark>deadline</mark>")
+}
+
+// TestRepositorySearch tests that searches find the readable tasks and the projects of a user, by type
+// and page
+func TestRepositorySearch(t *testing.T) {
+	repository.UseMemory()
+	ctx := context.Background()
+
+	alice := models.User{Username: "alice"}
+	bob := models.User{Username: "bob"}
+	require.NoError(t, repository.Users.Create(ctx, &alice))
+	require.NoError(t, repository.Users.Create(ctx, &bob))
+
+	for _, task := range []models.Task{
+		{UserID: alice.ID, Title: "Budget review", AllottedTo: "alice"},
+		{UserID: alice.ID, Title: "Plan", Description: "Review the budget with finance", AllottedTo: "alice"},
+		{UserID: alice.ID, Title: "Budget draft", AllottedTo: "alice"},
+		{UserID: bob.ID, Title: "Review budget of marketing", AllottedTo: "alice", Visibility: "private"},
+		{UserID: bob.ID, Title: "Review budget of sales", AllottedTo: "bob"},
+	} {
+		require.NoError(t, repository.Tasks.Create(ctx, &task))
+	}
+	require.NoError(t, repository.Projects.Create(ctx, &models.Project{OwnerID: alice.ID, Name: "Budget review 2025"}))
+	require.NoError(t, repository.Projects.Create(ctx, &models.Project{OwnerID: bob.ID, Name: "Budget review 2026"}))
+
+	results, err := Repository{}.Search(ctx, Query{Text: "review BUDGET", UserID: alice.ID, Limit: 10})
+	require.NoError(t, err)
+	// Assert that all words must match, in the user's own tasks and the tasks allotted to them
+	require.NotNil(t, results.Tasks)
+	assert.Equal(t, 3, results.Tasks.Total)
+	require.NotNil(t, results.Projects)
+	assert.Equal(t, 1, results.Projects.Total)
+	assert.Equal(t, "<mark>Budget</mark> <mark>review</mark> 2025", results.Projects.Hits[0].Highlights["name"])
+	for _, hit := range results.Tasks.Hits {
+		assert.NotContains(t, hit.Title, "sales")
+	}
+
+	results, err = Repository{}.Search(ctx, Query{Text: "budget", UserID: alice.ID, Types: []string{TypeTask}, Limit: 2, Offset: 2})
+	require.NoError(t, err)
+	// Assert that only the requested types are searched and pages are cut per type
+	assert.Nil(t, results.Projects)
+	assert.Equal(t, 4, results.Tasks.Total)
+	assert.Len(t, results.Tasks.Hits, 2)
+}
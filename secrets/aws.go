@@ -0,0 +1,76 @@
+// aws.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// AWSSecretsClient is the part of the Secrets Manager client of the AWS SDK that AWSSecretsManager uses.
+type AWSSecretsClient interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// AWSSecretsManager reads a secret from AWS Secrets Manager through the AWS SDK, which signs the requests.
+// The secret string must be a JSON object of string values, e.g. {"JWT_SECRET": "...", "MONGO_URI": "..."}.
+type AWSSecretsManager struct {
+	SecretID string // name or ARN of the secret
+	Client   AWSSecretsClient
+}
+
+// NewAWSSecretsManager creates a reader of a secret with the credentials of the SDK's default chain: the
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN variables, the shared configuration
+// files, or the role of the instance or task.
+//
+// Parameters:
+// - ctx: Context for loading the configuration.
+// - region: The AWS region of the secret.
+// - secretID: The name or ARN of the secret.
+// - endpoint: Overrides https://secretsmanager.<region>.amazonaws.com, e.g. for a VPC endpoint; "" keeps it.
+// - timeout: The time limit of each request.
+//
+// Returns:
+// - *AWSSecretsManager: The reader.
+// - error: An error if the AWS configuration could not be loaded.
+func NewAWSSecretsManager(ctx context.Context, region, secretID, endpoint string, timeout time.Duration) (*AWSSecretsManager, error) {
+	// The SDK's own client, unlike *http.Client, can take the certificates of AWS_CA_BUNDLE
+	client := awshttp.NewBuildableClient().WithTimeout(timeout)
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region), config.WithHTTPClient(client))
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS configuration: %w", err)
+	}
+	return &AWSSecretsManager{
+		SecretID: secretID,
+		Client: secretsmanager.NewFromConfig(cfg, func(options *secretsmanager.Options) {
+			if endpoint != "" {
+				options.BaseEndpoint = aws.String(endpoint)
+			}
+		}),
+	}, nil
+}
+
+// Fetch returns the key-value pairs of the secret's current version.
+func (m *AWSSecretsManager) Fetch(ctx context.Context) (map[string]string, error) {
+	output, err := m.Client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(m.SecretID)})
+	if err != nil {
+		return nil, fmt.Errorf("reading secret %s from secrets manager: %w", m.SecretID, err)
+	}
+	if output.SecretString == nil {
+		return nil, fmt.Errorf("secret %s has no secret string", m.SecretID)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(*output.SecretString), &data); err != nil {
+		return nil, fmt.Errorf("secret %s is not a JSON object: %w", m.SecretID, err)
+	}
+	return stringValues(data)
+}
@@ -0,0 +1,89 @@
+// secrets.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/bkojha74/task-management/helper"
+)
+
+// Provider fetches settings such as JWT_SECRET and MONGO_URI from a secrets backend.
+type Provider interface {
+	// Fetch returns the secret's values by environment variable name.
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// httpTimeout bounds every request to a secrets backend.
+const httpTimeout = 10 * time.Second
+
+// LoadProvider creates the provider selected by SECRETS_BACKEND: "vault" (VAULT_ADDR, VAULT_TOKEN,
+// VAULT_SECRET_PATH) or "aws" (AWS_REGION, AWS_SECRET_ID and credentials from the SDK's default chain).
+// Settings come from the environment only when it is empty.
+//
+// Returns:
+// - Provider: The configured provider, or nil when none is configured.
+// - error: An error if the backend is unknown or a required variable is missing.
+func LoadProvider() (Provider, error) {
+	client := &http.Client{Timeout: httpTimeout}
+
+	switch kind := helper.GetEnv("SECRETS_BACKEND"); kind {
+	case "":
+		return nil, nil
+	case "vault":
+		vault := &Vault{
+			Addr:   helper.GetEnv("VAULT_ADDR"),
+			Token:  helper.GetEnv("VAULT_TOKEN"),
+			Path:   helper.GetEnv("VAULT_SECRET_PATH"),
+			Client: client,
+		}
+		if vault.Addr == "" || vault.Token == "" || vault.Path == "" {
+			return nil, fmt.Errorf("VAULT_ADDR, VAULT_TOKEN and VAULT_SECRET_PATH must be set for the vault backend")
+		}
+		return vault, nil
+	case "aws":
+		region, secretID := helper.GetEnv("AWS_REGION"), helper.GetEnv("AWS_SECRET_ID")
+		if region == "" || secretID == "" {
+			return nil, fmt.Errorf("AWS_REGION and AWS_SECRET_ID must be set for the aws backend")
+		}
+		return NewAWSSecretsManager(context.Background(), region, secretID, helper.GetEnv("AWS_SECRETS_ENDPOINT"), httpTimeout)
+	default:
+		return nil, fmt.Errorf("unknown SECRETS_BACKEND %q, use vault or aws", kind)
+	}
+}
+
+// Apply fetches the secret and sets its values as environment variables, overriding the .env file, so
+// that the configuration is read from them as usual.
+//
+// Parameters:
+// - ctx: Context for the request to the backend.
+// - provider: The backend to fetch from.
+//
+// Returns:
+// - []string: The names of the variables whose value changed, sorted.
+// - error: An error if the secret could not be fetched or a variable could not be set.
+func Apply(ctx context.Context, provider Provider) ([]string, error) {
+	values, err := provider.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []string{}
+	for name, value := range values {
+		if os.Getenv(name) == value {
+			continue
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return changed, fmt.Errorf("setting %s: %w", name, err)
+		}
+		changed = append(changed, name)
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
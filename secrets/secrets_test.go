@@ -0,0 +1,160 @@
+// secrets_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVault tests reading KV version 1 and 2 secrets from Vault
+func TestVault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/app":
+			w.Write([]byte(`{"data":{"data":{"JWT_SECRET":"s3cret"},"metadata":{"version":3}}}`))
+		case "/v1/kv/app":
+			w.Write([]byte(`{"data":{"MONGO_URI":"mongodb://db"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	vault := func(token, path string) *Vault {
+		return &Vault{Addr: server.URL, Token: token, Path: path, Client: server.Client()}
+	}
+
+	// Assert that both KV versions are read
+	values, err := vault("root", "secret/data/app").Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"JWT_SECRET": "s3cret"}, values)
+	values, err = vault("root", "/kv/app").Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"MONGO_URI": "mongodb://db"}, values)
+
+	// Assert that a rejected token and a missing secret are errors
+	_, err = vault("wrong", "secret/data/app").Fetch(context.Background())
+	assert.Error(t, err)
+	_, err = vault("root", "secret/data/missing").Fetch(context.Background())
+	assert.Error(t, err)
+}
+
+// TestAWSSecretsManager tests reading a secret from AWS Secrets Manager through the SDK with credentials from
+// the environment
+func TestAWSSecretsManager(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+
+		ok := r.Method == http.MethodPost &&
+			r.Header.Get("X-Amz-Target") == "secretsmanager.GetSecretValue" &&
+			r.Header.Get("X-Amz-Security-Token") == "session" &&
+			strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") &&
+			strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/secretsmanager/aws4_request, ") &&
+			body["SecretId"] == "task-management"
+		if !ok {
+			w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"ResourceNotFoundException","Message":"Secrets Manager can't find the specified secret."}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.Write([]byte(`{"Name":"task-management","SecretString":"{\"JWT_SECRET\":\"s3cret\",\"MONGO_URI\":\"mongodb://db\"}"}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "session")
+	t.Setenv("AWS_CONFIG_FILE", os.DevNull)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", os.DevNull)
+
+	manager, err := NewAWSSecretsManager(context.Background(), "eu-west-1", "task-management", server.URL, time.Second)
+	require.NoError(t, err)
+
+	// Assert that the secret string is decoded into its values
+	values, err := manager.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"JWT_SECRET": "s3cret", "MONGO_URI": "mongodb://db"}, values)
+
+	// Assert that a failed request is an error
+	manager.SecretID = "other"
+	_, err = manager.Fetch(context.Background())
+	assert.Error(t, err)
+}
+
+// TestApply tests that fetched values are set as environment variables and changes are reported
+func TestApply(t *testing.T) {
+	t.Setenv("SECRETS_TEST_A", "old")
+	t.Setenv("SECRETS_TEST_B", "same")
+	provider := staticProvider{"SECRETS_TEST_A": "new", "SECRETS_TEST_B": "same"}
+
+	// Assert that only the changed variable is reported
+	changed, err := Apply(context.Background(), provider)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SECRETS_TEST_A"}, changed)
+	assert.Equal(t, "new", os.Getenv("SECRETS_TEST_A"))
+
+	// Assert that applying again changes nothing
+	changed, err = Apply(context.Background(), provider)
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+}
+
+// TestLoadProvider tests the backend selection and its required settings
+func TestLoadProvider(t *testing.T) {
+	// Assert that no backend is configured by default
+	t.Setenv("SECRETS_BACKEND", "")
+	provider, err := LoadProvider()
+	require.NoError(t, err)
+	assert.Nil(t, provider)
+
+	// Assert that a backend without its settings and an unknown backend are rejected
+	t.Setenv("VAULT_ADDR", "")
+	t.Setenv("AWS_SECRET_ID", "")
+	for _, backend := range []string{"vault", "aws", "consul"} {
+		t.Setenv("SECRETS_BACKEND", backend)
+		_, err := LoadProvider()
+		assert.Error(t, err, backend)
+	}
+
+	t.Setenv("SECRETS_BACKEND", "vault")
+	t.Setenv("VAULT_ADDR", "https://vault:8200")
+	t.Setenv("VAULT_TOKEN", "root")
+	t.Setenv("VAULT_SECRET_PATH", "secret/data/app")
+	provider, err = LoadProvider()
+	require.NoError(t, err)
+	assert.IsType(t, &Vault{}, provider)
+
+	// Assert that the aws backend needs no keys, the SDK's credential chain provides them
+	t.Setenv("SECRETS_BACKEND", "aws")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_SECRET_ID", "task-management")
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	provider, err = LoadProvider()
+	require.NoError(t, err)
+	assert.IsType(t, &AWSSecretsManager{}, provider)
+}
+
+// staticProvider returns fixed values.
+type staticProvider map[string]string
+
+func (p staticProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	return p, nil
+}
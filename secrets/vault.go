@@ -0,0 +1,68 @@
+// vault.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Vault reads a secret from HashiCorp Vault's KV secrets engine, version 1 or 2, with a token.
+type Vault struct {
+	Addr   string // e.g. https://vault.example.com:8200
+	Token  string
+	Path   string // API path of the secret, e.g. secret/data/task-management for KV version 2
+	Client *http.Client
+}
+
+// Fetch returns the key-value pairs of the secret.
+func (v *Vault) Fetch(ctx context.Context) (map[string]string, error) {
+	url := strings.TrimSuffix(v.Addr, "/") + "/v1/" + strings.TrimPrefix(v.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned %s for %s", resp.Status, v.Path)
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding vault response: %w", err)
+	}
+
+	// KV version 2 nests the values in data.data next to data.metadata
+	data := body.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	return stringValues(data)
+}
+
+// stringValues converts the values of a decoded JSON object, which must all be strings.
+func stringValues(data map[string]interface{}) (map[string]string, error) {
+	values := make(map[string]string, len(data))
+	for key, value := range data {
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("secret value %s is not a string", key)
+		}
+		values[key] = text
+	}
+	return values, nil
+}
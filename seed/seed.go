@@ -0,0 +1,205 @@
+// seed.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/environment"
+	"github.com/bkojha74/task-management/markdown"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/readmodel"
+	"github.com/bkojha74/task-management/repository"
+	"github.com/bkojha74/task-management/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UsernamePrefix marks seeded accounts, so that re-running the seed reuses them.
+const UsernamePrefix = "seed-"
+
+// ErrProduction is returned when seeding targets a production environment.
+var ErrProduction = errors.New("refusing to seed a production database")
+
+// ErrStaging is returned when seeding targets a staging environment without explicit permission.
+var ErrStaging = errors.New("refusing to seed a staging database without --allow-remote")
+
+// ErrRemote is returned when seeding targets a non-local database without explicit permission.
+var ErrRemote = errors.New("refusing to seed a non-local database without --allow-remote")
+
+// Options controls how much data is generated.
+type Options struct {
+	Users    int    // Number of seeded users
+	Tasks    int    // Number of tasks created
+	Password string // Password of all seeded users
+	Seed     int64  // Random seed; the same seed generates the same fixtures
+}
+
+// Result summarizes a seed run.
+type Result struct {
+	UsersCreated int
+	UsersReused  int
+	TasksCreated int
+}
+
+var (
+	firstNames = []string{"Aarav", "Priya", "Liam", "Sofia", "Noah", "Mei", "Lucas", "Amara", "Ethan", "Zara", "Mateo", "Hana"}
+	lastNames  = []string{"Sharma", "Smith", "Garcia", "Chen", "Okafor", "Müller", "Rossi", "Tanaka", "Kowalski", "Silva"}
+	verbs      = []string{"Review", "Draft", "Fix", "Update", "Prepare", "Test", "Deploy", "Plan", "Document", "Refactor"}
+	objects    = []string{"quarterly report", "login page", "invoice export", "release notes", "onboarding checklist",
+		"API rate limits", "customer survey", "database backup", "marketing email", "sprint board"}
+	statuses   = []string{models.TaskStatusPending, models.TaskStatusPending, models.TaskStatusInProgress, models.TaskStatusDone, models.TaskStatusDone}
+	priorities = []string{models.TaskPriorityLow, models.TaskPriorityMedium, models.TaskPriorityMedium, models.TaskPriorityHigh}
+)
+
+// CheckTarget refuses production environments (APP_ENV "production" or "prod") and unknown ones. Unless
+// allowRemote is set, it also refuses staging environments and databases on hosts other than localhost.
+//
+// Parameters:
+// - env: The value of APP_ENV.
+// - mongoURI: The MongoDB connection string.
+// - allowRemote: Whether non-local hosts and staging are allowed.
+//
+// Returns:
+// - error: ErrProduction, ErrStaging or ErrRemote if the target must not be seeded.
+func CheckTarget(env, mongoURI string, allowRemote bool) error {
+	parsed, err := environment.Parse(env)
+	if err != nil {
+		return err
+	}
+	switch parsed {
+	case environment.Production:
+		return ErrProduction
+	case environment.Staging:
+		if !allowRemote {
+			return ErrStaging
+		}
+	}
+	if allowRemote {
+		return nil
+	}
+
+	// Connection strings list several hosts, which url.Parse rejects
+	if !strings.HasPrefix(mongoURI, "mongodb://") {
+		return ErrRemote
+	}
+	hosts := strings.TrimPrefix(mongoURI, "mongodb://")
+	if i := strings.IndexAny(hosts, "/?"); i >= 0 {
+		hosts = hosts[:i]
+	}
+	if i := strings.LastIndex(hosts, "@"); i >= 0 {
+		hosts = hosts[i+1:]
+	}
+	for _, host := range strings.Split(hosts, ",") {
+		hostname := host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			hostname = h
+		}
+		hostname = strings.Trim(hostname, "[]")
+		if hostname != "localhost" && hostname != "127.0.0.1" && hostname != "::1" && hostname != "mongo" && hostname != "mongodb" {
+			return ErrRemote
+		}
+	}
+	return nil
+}
+
+// Users generates the seeded user accounts, without IDs, all with the given password hash.
+func Users(r *rand.Rand, count int, passwordHash string) []models.User {
+	users := make([]models.User, 0, count)
+	for i := 1; i <= count; i++ {
+		first := firstNames[r.Intn(len(firstNames))]
+		last := lastNames[r.Intn(len(lastNames))]
+		users = append(users, models.User{
+			Username:    fmt.Sprintf("%s%s%02d", UsernamePrefix, strings.ToLower(first), i),
+			DisplayName: first + " " + last,
+			Password:    passwordHash,
+		})
+	}
+	return users
+}
+
+// Tasks generates tasks owned by and allotted to the given users, with statuses, priorities and
+// dates spread over the last three months and the next month.
+func Tasks(r *rand.Rand, users []models.User, count int, now time.Time) []models.Task {
+	tasks := make([]models.Task, 0, count)
+	for i := 0; i < count; i++ {
+		owner := users[r.Intn(len(users))]
+		assignee := users[r.Intn(len(users))]
+		start := now.AddDate(0, 0, -r.Intn(90)).Add(-time.Duration(r.Intn(24*60)) * time.Minute)
+		due := start.AddDate(0, 0, 1+r.Intn(45))
+
+		task := models.Task{
+			ID:          primitive.NewObjectID(),
+			UserID:      owner.ID,
+			Title:       verbs[r.Intn(len(verbs))] + " " + objects[r.Intn(len(objects))],
+			Description: fmt.Sprintf("Seeded task %d for demos and load testing", i+1),
+			AllottedTo:  assignee.Username,
+			Status:      statuses[r.Intn(len(statuses))],
+			Priority:    priorities[r.Intn(len(priorities))],
+			StartDate:   models.NewTimestamp(start),
+			EndDate:     models.NewTimestamp(due),
+			Version:     1,
+		}
+		task.DescriptionHTML = markdown.Render(task.Description)
+		if task.Status == models.TaskStatusDone {
+			task.DoneBy = assignee.Username
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// Run creates the seeded users (reusing accounts from earlier runs) and tasks through the repositories,
+// and writes the task list views.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - opts: How much data to generate.
+//
+// Returns:
+// - Result: The number of users and tasks written.
+// - error: An error object if writing failed.
+func Run(ctx context.Context, opts Options) (Result, error) {
+	var result Result
+	if opts.Users <= 0 {
+		return result, errors.New("at least one user is required")
+	}
+	r := rand.New(rand.NewSource(opts.Seed))
+
+	// Hashing is deliberately slow, so all seeded users share one hash
+	hash := utils.HashPassword(opts.Password)
+
+	users := Users(r, opts.Users, hash)
+	for i := range users {
+		existing, err := repository.Users.FindByUsername(ctx, users[i].Username)
+		if err == nil {
+			users[i] = *existing
+			result.UsersReused++
+			continue
+		}
+		if err != repository.ErrNotFound {
+			return result, err
+		}
+
+		if err := repository.Users.Create(ctx, &users[i]); err != nil {
+			return result, err
+		}
+		result.UsersCreated++
+	}
+
+	for _, task := range Tasks(r, users, opts.Tasks, time.Now()) {
+		if err := repository.Tasks.Create(ctx, &task); err != nil {
+			return result, err
+		}
+		readmodel.SyncOrLog(ctx, task)
+		result.TasksCreated++
+	}
+	return result, nil
+}
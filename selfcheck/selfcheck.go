@@ -0,0 +1,242 @@
+// selfcheck.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package selfcheck runs the startup self-check: database connectivity, indexes, migrations, JWT secret
+// strength and clock skew, reported as one structured log line or, with --check, as a CI/CD gate.
+package selfcheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/database"
+	"github.com/bkojha74/task-management/migrations"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+// Outcomes of a check, from best to worst
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn" // Works, but should be looked at
+	StatusFail Status = "fail" // Must be fixed; fails --check
+)
+
+// MinJWTSecretLength is the length in bytes below which a JWT secret fails the check; HS256 keys should
+// have at least 256 bits.
+const MinJWTSecretLength = 32
+
+// Check is one named check.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (Status, string)
+}
+
+// Result is the outcome of a check.
+type Result struct {
+	Name       string `json:"name"`
+	Status     Status `json:"status"`
+	Message    string `json:"message"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Report is the outcome of all checks; its Status is the worst of theirs.
+type Report struct {
+	Time   time.Time `json:"time"`
+	Status Status    `json:"status"`
+	Checks []Result  `json:"checks"`
+}
+
+// Startup returns the checks run when the server starts. The database checks use the connection of
+// the database package.
+//
+// Parameters:
+// - jwtSecret: The secret tokens are signed with.
+// - maxClockSkew: The clock difference to MongoDB above which the check fails, the JWT clock skew leeway.
+//
+// Returns:
+// - []Check: The checks, in the order they run.
+func Startup(jwtSecret string, maxClockSkew time.Duration) []Check {
+	return []Check{
+		Database(),
+		Migrations(),
+		Indexes(),
+		JWTSecret(jwtSecret),
+		ClockSkew(maxClockSkew),
+	}
+}
+
+// Run runs the checks one after the other.
+//
+// Parameters:
+// - ctx: Context for the checks.
+// - checks: The checks to run.
+//
+// Returns:
+// - Report: The outcome of the checks.
+func Run(ctx context.Context, checks []Check) Report {
+	report := Report{Time: time.Now().UTC(), Status: StatusOK, Checks: []Result{}}
+	for _, check := range checks {
+		start := time.Now()
+		status, message := check.Run(ctx)
+		report.Checks = append(report.Checks, Result{
+			Name:       check.Name,
+			Status:     status,
+			Message:    message,
+			DurationMs: time.Since(start).Milliseconds(),
+		})
+		if severity(status) > severity(report.Status) {
+			report.Status = status
+		}
+	}
+	return report
+}
+
+// Failed reports whether a check failed.
+func (report Report) Failed() bool {
+	return report.Status == StatusFail
+}
+
+// Log writes the report as one JSON line.
+func (report Report) Log() {
+	line, err := json.Marshal(report)
+	if err != nil {
+		log.Println("Error encoding self-check report:", err)
+		return
+	}
+	log.Printf("Startup self-check: %s", line)
+}
+
+// Database checks that MongoDB is connected and answers.
+func Database() Check {
+	return Check{Name: "database", Run: func(ctx context.Context) (Status, string) {
+		if database.MongoClient == nil {
+			return StatusFail, "not connected to MongoDB"
+		}
+		if err := database.MongoClient.Ping(ctx, nil); err != nil {
+			return StatusFail, "MongoDB does not answer: " + err.Error()
+		}
+		return StatusOK, "connected"
+	}}
+}
+
+// Migrations checks that all migrations are applied.
+func Migrations() Check {
+	return Check{Name: "migrations", Run: func(ctx context.Context) (Status, string) {
+		if database.MongoClient == nil {
+			return StatusFail, "not connected to MongoDB"
+		}
+		db := database.MongoClient.Database(database.Name)
+		pending, err := migrations.Pending(ctx, db)
+		if err != nil {
+			return StatusFail, "reading migrations: " + err.Error()
+		}
+		if len(pending) > 0 {
+			versions := make([]string, len(pending))
+			for i, migration := range pending {
+				versions[i] = fmt.Sprint(migration.Version)
+			}
+			return StatusFail, "pending migrations " + strings.Join(versions, ", ") + ", run taskctl migrate up"
+		}
+
+		// A newer binary may have migrated the database already, e.g. during a rollback
+		applied, err := migrations.Status(ctx, db)
+		if err != nil {
+			return StatusFail, "reading migrations: " + err.Error()
+		}
+		if len(applied) > len(migrations.All) {
+			return StatusWarn, fmt.Sprintf("database has %d migrations, this binary knows %d", len(applied), len(migrations.All))
+		}
+		return StatusOK, fmt.Sprintf("all %d migrations applied", len(migrations.All))
+	}}
+}
+
+// Indexes checks that the indexes created by the applied migrations exist.
+func Indexes() Check {
+	return Check{Name: "indexes", Run: func(ctx context.Context) (Status, string) {
+		if database.MongoClient == nil {
+			return StatusFail, "not connected to MongoDB"
+		}
+		missing, err := migrations.MissingIndexes(ctx, database.MongoClient.Database(database.Name))
+		if err != nil {
+			return StatusFail, "listing indexes: " + err.Error()
+		}
+		if len(missing) > 0 {
+			names := make([]string, len(missing))
+			for i, index := range missing {
+				names[i] = index.Collection + "." + index.Name
+			}
+			return StatusFail, "missing indexes " + strings.Join(names, ", ")
+		}
+		return StatusOK, "all indexes present"
+	}}
+}
+
+// JWTSecret checks that the secret tokens are signed with is long enough to resist brute force.
+func JWTSecret(secret string) Check {
+	return Check{Name: "jwt_secret", Run: func(ctx context.Context) (Status, string) {
+		if len(secret) < MinJWTSecretLength {
+			return StatusFail, fmt.Sprintf("JWT secret has %d bytes, use at least %d, e.g. from openssl rand -base64 32", len(secret), MinJWTSecretLength)
+		}
+		if strings.Count(secret, secret[:1]) == len(secret) {
+			return StatusFail, "JWT secret repeats a single character"
+		}
+		return StatusOK, fmt.Sprintf("%d bytes", len(secret))
+	}}
+}
+
+// ClockSkew checks that the local clock agrees with MongoDB's within max. Token expiry and due dates
+// depend on it.
+func ClockSkew(max time.Duration) Check {
+	return Check{Name: "clock_skew", Run: func(ctx context.Context) (Status, string) {
+		if database.MongoClient == nil {
+			return StatusFail, "not connected to MongoDB"
+		}
+
+		var hello struct {
+			LocalTime time.Time `bson:"localTime"`
+		}
+		start := time.Now()
+		err := database.MongoClient.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+		if err != nil {
+			return StatusFail, "reading the MongoDB server time: " + err.Error()
+		}
+		return skewStatus(start, time.Now(), hello.LocalTime, max)
+	}}
+}
+
+// skewStatus compares the server time with the local time halfway through the round trip.
+func skewStatus(sent, received, server time.Time, max time.Duration) (Status, string) {
+	local := sent.Add(received.Sub(sent) / 2)
+	skew := local.Sub(server)
+	if skew < 0 {
+		skew = -skew
+	}
+	// MongoDB reports milliseconds, so smaller differences are noise
+	skew = skew.Round(time.Millisecond)
+
+	message := fmt.Sprintf("clock differs from MongoDB by %s", skew)
+	if skew > max {
+		return StatusFail, message + fmt.Sprintf(", more than the allowed %s", max)
+	}
+	return StatusOK, message
+}
+
+// severity orders the statuses.
+func severity(status Status) int {
+	switch status {
+	case StatusFail:
+		return 2
+	case StatusWarn:
+		return 1
+	default:
+		return 0
+	}
+}
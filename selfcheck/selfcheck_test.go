@@ -0,0 +1,69 @@
+// selfcheck_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package selfcheck
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRun tests that the report lists every check and takes the worst status
+func TestRun(t *testing.T) {
+	fixed := func(name string, status Status) Check {
+		return Check{Name: name, Run: func(ctx context.Context) (Status, string) { return status, name }}
+	}
+
+	// Assert that warnings do not fail the report, but a failed check does
+	report := Run(context.Background(), []Check{fixed("a", StatusOK), fixed("b", StatusWarn)})
+	assert.Equal(t, StatusWarn, report.Status)
+	assert.False(t, report.Failed())
+
+	report = Run(context.Background(), []Check{fixed("a", StatusOK), fixed("b", StatusFail), fixed("c", StatusWarn)})
+	assert.Equal(t, StatusFail, report.Status)
+	assert.True(t, report.Failed())
+	require.Len(t, report.Checks, 3)
+	assert.Equal(t, "b", report.Checks[1].Name)
+
+	// Assert that the database checks fail without a connection
+	report = Run(context.Background(), Startup(strings.Repeat("k7", 16), 30*time.Second))
+	assert.True(t, report.Failed())
+	assert.Equal(t, StatusFail, report.Checks[0].Status)
+	assert.Equal(t, StatusOK, report.Checks[3].Status)
+}
+
+// TestJWTSecret tests the secret strength check
+func TestJWTSecret(t *testing.T) {
+	check := func(secret string) Status {
+		status, _ := JWTSecret(secret).Run(context.Background())
+		return status
+	}
+
+	// Assert that short and single-character secrets fail
+	assert.Equal(t, StatusFail, check(""))
+	assert.Equal(t, StatusFail, check("secret"))
+	assert.Equal(t, StatusFail, check(strings.Repeat("a", 40)))
+	assert.Equal(t, StatusOK, check("q8JtY2rN0bXv5LcPz3WfHs7KdMg1Ae9U"))
+}
+
+// TestSkewStatus tests the clock comparison, halfway through the round trip
+func TestSkewStatus(t *testing.T) {
+	sent := time.Date(2024, 7, 5, 12, 0, 0, 0, time.UTC)
+	received := sent.Add(200 * time.Millisecond)
+
+	// Assert that a server time within the round trip is no skew
+	status, message := skewStatus(sent, received, sent.Add(100*time.Millisecond), 30*time.Second)
+	assert.Equal(t, StatusOK, status)
+	assert.Contains(t, message, "by 0s")
+
+	// Assert that a clock behind or ahead by more than the maximum fails
+	status, _ = skewStatus(sent, received, sent.Add(-time.Minute), 30*time.Second)
+	assert.Equal(t, StatusFail, status)
+	status, _ = skewStatus(sent, received, sent.Add(time.Minute), 30*time.Second)
+	assert.Equal(t, StatusFail, status)
+}
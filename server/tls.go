@@ -0,0 +1,149 @@
+// tls.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TLSConfig selects how the server terminates TLS itself, for deployments without a reverse proxy.
+// Either a certificate/key pair or a list of autocert (Let's Encrypt) domains can be given.
+type TLSConfig struct {
+	CertFile         string   // PEM certificate file
+	KeyFile          string   // PEM private key file
+	AutocertDomains  []string // Domains to obtain Let's Encrypt certificates for
+	AutocertCacheDir string   // Directory the obtained certificates are cached in
+	AutocertEmail    string   // Contact address for the ACME account
+	RedirectPort     string   // Plain HTTP port redirecting to HTTPS (and answering ACME challenges); empty disables it
+}
+
+// LoadTLSConfig reads the TLS settings from the TLS_CERT_FILE, TLS_KEY_FILE, TLS_AUTOCERT_DOMAINS,
+// TLS_AUTOCERT_CACHE, TLS_AUTOCERT_EMAIL and HTTP_REDIRECT_PORT environment variables.
+//
+// Returns:
+// - TLSConfig: The configured TLS settings.
+func LoadTLSConfig() TLSConfig {
+	config := TLSConfig{
+		CertFile:         helper.GetEnv("TLS_CERT_FILE"),
+		KeyFile:          helper.GetEnv("TLS_KEY_FILE"),
+		AutocertCacheDir: helper.GetEnv("TLS_AUTOCERT_CACHE"),
+		AutocertEmail:    helper.GetEnv("TLS_AUTOCERT_EMAIL"),
+		RedirectPort:     helper.GetEnv("HTTP_REDIRECT_PORT"),
+	}
+	for _, domain := range strings.Split(helper.GetEnv("TLS_AUTOCERT_DOMAINS"), ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			config.AutocertDomains = append(config.AutocertDomains, domain)
+		}
+	}
+	if config.AutocertCacheDir == "" {
+		config.AutocertCacheDir = "certs"
+	}
+	return config
+}
+
+// Enabled reports whether the server terminates TLS.
+func (config TLSConfig) Enabled() bool {
+	return config.CertFile != "" || len(config.AutocertDomains) > 0
+}
+
+// Validate checks that the TLS settings are complete and not contradictory.
+func (config TLSConfig) Validate() error {
+	if (config.CertFile == "") != (config.KeyFile == "") {
+		return errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if config.CertFile != "" && len(config.AutocertDomains) > 0 {
+		return errors.New("TLS_CERT_FILE and TLS_AUTOCERT_DOMAINS cannot be combined")
+	}
+	if config.RedirectPort != "" && !config.Enabled() {
+		return errors.New("HTTP_REDIRECT_PORT requires TLS to be enabled")
+	}
+	return nil
+}
+
+// Listen starts the app on the given port, over TLS when it is enabled. With a redirect port, a plain
+// HTTP server is started alongside that redirects to HTTPS and answers ACME HTTP-01 challenges.
+//
+// Parameters:
+// - app: The Fiber app to serve.
+// - port: The port to listen on.
+// - config: The TLS settings.
+//
+// Returns:
+// - error: An error object if the server could not be started or stopped unexpectedly.
+func Listen(app *fiber.App, port string, config TLSConfig) error {
+	if !config.Enabled() {
+		return app.Listen(":" + port)
+	}
+
+	var manager *autocert.Manager
+	if len(config.AutocertDomains) > 0 {
+		manager = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(config.AutocertDomains...),
+			Cache:      autocert.DirCache(config.AutocertCacheDir),
+			Email:      config.AutocertEmail,
+		}
+	}
+
+	if config.RedirectPort != "" {
+		go serveRedirect(config.RedirectPort, port, manager)
+	}
+
+	if manager == nil {
+		return app.ListenTLS(":"+port, config.CertFile, config.KeyFile)
+	}
+
+	listener, err := tls.Listen("tcp", ":"+port, &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: manager.GetCertificate,
+		NextProtos:     []string{"http/1.1", acme.ALPNProto},
+	})
+	if err != nil {
+		return err
+	}
+	return app.Listener(listener)
+}
+
+// serveRedirect runs the plain HTTP server redirecting every request to the HTTPS port.
+func serveRedirect(redirectPort, httpsPort string, manager *autocert.Manager) {
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, HTTPSURL(r.Host, httpsPort, r.URL.RequestURI()), http.StatusPermanentRedirect)
+	})
+	if manager != nil {
+		handler = manager.HTTPHandler(handler)
+	}
+
+	redirectServer := &http.Server{
+		Addr:              ":" + redirectPort,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if err := redirectServer.ListenAndServe(); err != nil {
+		log.Println("HTTP redirect server stopped:", err)
+	}
+}
+
+// HTTPSURL builds the HTTPS URL of a request received over plain HTTP. The port of the host is replaced
+// with the HTTPS port, which is omitted when it is the default 443.
+func HTTPSURL(host, httpsPort, requestURI string) string {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	if httpsPort != "" && httpsPort != "443" {
+		host = net.JoinHostPort(strings.Trim(host, "[]"), httpsPort)
+	}
+	return "https://" + host + requestURI
+}
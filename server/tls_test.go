@@ -0,0 +1,33 @@
+// tls_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHTTPSURL tests building the redirect target of plain HTTP requests
+func TestHTTPSURL(t *testing.T) {
+	// Assert that the default HTTPS port is omitted
+	assert.Equal(t, "https://example.com/tasks?limit=5", HTTPSURL("example.com", "443", "/tasks?limit=5"))
+	assert.Equal(t, "https://example.com/", HTTPSURL("example.com:80", "443", "/"))
+
+	// Assert that other HTTPS ports replace the HTTP port
+	assert.Equal(t, "https://example.com:8443/", HTTPSURL("example.com:8080", "8443", "/"))
+	assert.Equal(t, "https://[::1]:8443/", HTTPSURL("[::1]:8080", "8443", "/"))
+}
+
+// TestTLSConfigValidate tests the TLS setting combinations
+func TestTLSConfigValidate(t *testing.T) {
+	assert.NoError(t, TLSConfig{}.Validate())
+	assert.NoError(t, TLSConfig{CertFile: "cert.pem", KeyFile: "key.pem", RedirectPort: "80"}.Validate())
+	assert.NoError(t, TLSConfig{AutocertDomains: []string{"example.com"}}.Validate())
+
+	// Assert that incomplete or contradictory settings are rejected
+	assert.Error(t, TLSConfig{CertFile: "cert.pem"}.Validate())
+	assert.Error(t, TLSConfig{CertFile: "cert.pem", KeyFile: "key.pem", AutocertDomains: []string{"example.com"}}.Validate())
+	assert.Error(t, TLSConfig{RedirectPort: "80"}.Validate())
+}
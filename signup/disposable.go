@@ -0,0 +1,82 @@
+// disposable.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package signup
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// DisposableDomains are well-known disposable and temporary mail providers. Subdomains are blocked too.
+// Deployments add their own with SIGNUP_BLOCKED_EMAIL_DOMAINS.
+var DisposableDomains = map[string]bool{
+	"10minutemail.com":       true,
+	"20minutemail.com":       true,
+	"33mail.com":             true,
+	"burnermail.io":          true,
+	"discard.email":          true,
+	"dispostable.com":        true,
+	"emailondeck.com":        true,
+	"fakeinbox.com":          true,
+	"getairmail.com":         true,
+	"getnada.com":            true,
+	"guerrillamail.com":      true,
+	"guerrillamail.net":      true,
+	"guerrillamail.org":      true,
+	"guerrillamailblock.com": true,
+	"harakirimail.com":       true,
+	"inboxkitten.com":        true,
+	"maildrop.cc":            true,
+	"mailinator.com":         true,
+	"mailnesia.com":          true,
+	"mailpoof.com":           true,
+	"mintemail.com":          true,
+	"mohmal.com":             true,
+	"mytemp.email":           true,
+	"sharklasers.com":        true,
+	"spamgourmet.com":        true,
+	"temp-mail.org":          true,
+	"tempail.com":            true,
+	"tempmail.dev":           true,
+	"tempmailo.com":          true,
+	"tempr.email":            true,
+	"throwawaymail.com":      true,
+	"trashmail.com":          true,
+	"yopmail.com":            true,
+}
+
+// Disposable reports whether a domain, or a domain it is a subdomain of, is a disposable mail provider.
+//
+// Parameters:
+// - domain: The lowercase domain of an email address.
+//
+// Returns:
+// - bool: Whether addresses of the domain are disposable.
+func Disposable(domain string) bool {
+	for domain != "" {
+		if DisposableDomains[domain] {
+			return true
+		}
+		dot := strings.IndexByte(domain, '.')
+		if dot < 0 {
+			return false
+		}
+		domain = domain[dot+1:]
+	}
+	return false
+}
+
+// emailDomain returns the lowercase domain of a bare email address such as jane@example.com.
+func emailDomain(email string) (string, bool) {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || address.Name != "" {
+		return "", false
+	}
+	at := strings.LastIndexByte(email, '@')
+	domain := strings.ToLower(email[at+1:])
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", false
+	}
+	return domain, true
+}
@@ -0,0 +1,52 @@
+// invites.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package signup
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+)
+
+// inviteEncoding encodes invite codes in upper-case letters and digits without padding.
+var inviteEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewInviteCode generates a random invite code of 80 bits, grouped for reading as XXXX-XXXX-XXXX-XXXX.
+//
+// Returns:
+// - string: The invite code, to be handed out once.
+// - error: An error if no random bytes could be read.
+func NewInviteCode() (string, error) {
+	random := make([]byte, 10)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	encoded := inviteEncoding.EncodeToString(random)
+	groups := make([]string, 0, len(encoded)/4)
+	for i := 0; i < len(encoded); i += 4 {
+		groups = append(groups, encoded[i:i+4])
+	}
+	return strings.Join(groups, "-"), nil
+}
+
+// HashInviteCode returns the hash an invite code is stored and looked up by. Codes are compared without
+// case, dashes and spaces, so that a code typed in by hand still matches.
+//
+// Parameters:
+// - code: The invite code as given by the user.
+//
+// Returns:
+// - string: The SHA-256 of the normalized code, in hex.
+func HashInviteCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,230 @@
+// signup.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package signup guards public sign-up against junk accounts: it verifies a CAPTCHA token with hCaptcha
+// or reCAPTCHA and rejects email addresses of disposable mail providers. Private deployments can close
+// sign-up to everyone without an invite code issued by an admin.
+package signup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/helper"
+)
+
+// CAPTCHA providers
+const (
+	ProviderHCaptcha  = "hcaptcha"
+	ProviderReCaptcha = "recaptcha"
+)
+
+// Sign-up modes
+const (
+	ModeOpen   = "open"   // Anyone can sign up
+	ModeInvite = "invite" // Sign-ups need an invite code
+)
+
+// verifyURLs are the siteverify endpoints of the providers.
+var verifyURLs = map[string]string{
+	ProviderHCaptcha:  "https://api.hcaptcha.com/siteverify",
+	ProviderReCaptcha: "https://www.google.com/recaptcha/api/siteverify",
+}
+
+// Errors returned by Policy.Check
+var (
+	ErrCaptchaRequired    = errors.New("captcha token is required")
+	ErrCaptchaFailed      = errors.New("captcha verification failed")
+	ErrEmailRequired      = errors.New("email is required")
+	ErrInvalidEmail       = errors.New("email is not a valid address")
+	ErrDisposableEmail    = errors.New("email domain is not allowed")
+	ErrCaptchaUnavailable = errors.New("captcha provider could not be reached")
+	ErrInviteRequired     = errors.New("invite_code is required")
+)
+
+// Default is the policy applied by the sign-up handler; the zero policy accepts every sign-up.
+var Default = Policy{}
+
+// Policy is what a sign-up must pass.
+type Policy struct {
+	Mode            string       // ModeOpen or ModeInvite; empty is ModeOpen
+	CaptchaProvider string       // ProviderHCaptcha or ProviderReCaptcha; empty turns the CAPTCHA off
+	CaptchaSecret   string       // Secret key of the site at the provider
+	CaptchaMinScore float64      // Lowest reCAPTCHA v3 score accepted; 0 accepts any score
+	VerifyURL       string       // Overrides the provider's siteverify endpoint, e.g. for a proxy
+	RequireEmail    bool         // Sign-ups must give an email address
+	BlockedDomains  []string     // Email domains rejected in addition to DisposableDomains
+	Client          *http.Client // HTTP client for the provider; a client with a 10s timeout when nil
+}
+
+// Request is what a sign-up sends that the policy checks.
+type Request struct {
+	Email        string // Email address, optional unless RequireEmail
+	InviteCode   string // Invite code, required in ModeInvite; redeemed by the caller
+	CaptchaToken string // Response token of the CAPTCHA widget
+	RemoteIP     string // IP of the client, passed on to the provider
+}
+
+// LoadPolicy reads the policy from SIGNUP_MODE (open or invite, default open), CAPTCHA_PROVIDER (hcaptcha or recaptcha, empty for none),
+// CAPTCHA_SECRET, CAPTCHA_MIN_SCORE, CAPTCHA_VERIFY_URL, SIGNUP_REQUIRE_EMAIL and
+// SIGNUP_BLOCKED_EMAIL_DOMAINS (comma-separated).
+//
+// Returns:
+// - Policy: The configured policy.
+// - error: An error if CAPTCHA_MIN_SCORE is not a number.
+func LoadPolicy() (Policy, error) {
+	policy := Policy{
+		Mode:            strings.ToLower(helper.GetEnv("SIGNUP_MODE")),
+		CaptchaProvider: strings.ToLower(helper.GetEnv("CAPTCHA_PROVIDER")),
+		CaptchaSecret:   helper.GetEnv("CAPTCHA_SECRET"),
+		VerifyURL:       helper.GetEnv("CAPTCHA_VERIFY_URL"),
+		RequireEmail:    helper.GetEnv("SIGNUP_REQUIRE_EMAIL") == "true",
+		BlockedDomains:  []string{},
+	}
+	if policy.Mode == "" {
+		policy.Mode = ModeOpen
+	}
+	if score := helper.GetEnv("CAPTCHA_MIN_SCORE"); score != "" {
+		value, err := strconv.ParseFloat(score, 64)
+		if err != nil {
+			return Policy{}, fmt.Errorf("invalid CAPTCHA_MIN_SCORE %q: %w", score, err)
+		}
+		policy.CaptchaMinScore = value
+	}
+	for _, domain := range strings.Split(helper.GetEnv("SIGNUP_BLOCKED_EMAIL_DOMAINS"), ",") {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			policy.BlockedDomains = append(policy.BlockedDomains, domain)
+		}
+	}
+	return policy, nil
+}
+
+// Validate rejects unknown modes and providers, a provider without a secret and scores outside 0 to 1.
+func (p Policy) Validate() error {
+	if p.Mode != "" && p.Mode != ModeOpen && p.Mode != ModeInvite {
+		return fmt.Errorf("unknown SIGNUP_MODE %q, use open or invite", p.Mode)
+	}
+	if p.CaptchaProvider != "" {
+		if _, ok := verifyURLs[p.CaptchaProvider]; !ok {
+			return fmt.Errorf("unknown CAPTCHA_PROVIDER %q, use hcaptcha or recaptcha", p.CaptchaProvider)
+		}
+		if p.CaptchaSecret == "" {
+			return errors.New("CAPTCHA_SECRET must be set with CAPTCHA_PROVIDER")
+		}
+	}
+	if p.CaptchaMinScore < 0 || p.CaptchaMinScore > 1 {
+		return errors.New("CAPTCHA_MIN_SCORE must be between 0 and 1")
+	}
+	return nil
+}
+
+// InviteOnly reports whether sign-ups need an invite code.
+func (p Policy) InviteOnly() bool {
+	return p.Mode == ModeInvite
+}
+
+// Check checks a sign-up: that it has an invite code if one is needed and the email address first, which
+// cost nothing, then the CAPTCHA token with the provider. The invite code itself is redeemed by the caller.
+//
+// Parameters:
+// - ctx: Context for the request to the provider.
+// - request: The email address, CAPTCHA token and client IP of the sign-up.
+//
+// Returns:
+// - error: ErrInviteRequired, ErrEmailRequired, ErrInvalidEmail, ErrDisposableEmail, ErrCaptchaRequired or ErrCaptchaFailed
+// if the sign-up is rejected, or an error wrapping ErrCaptchaUnavailable if the provider could not tell.
+func (p Policy) Check(ctx context.Context, request Request) error {
+	if p.InviteOnly() && strings.TrimSpace(request.InviteCode) == "" {
+		return ErrInviteRequired
+	}
+	if err := p.checkEmail(request.Email); err != nil {
+		return err
+	}
+	if p.CaptchaProvider == "" {
+		return nil
+	}
+	if strings.TrimSpace(request.CaptchaToken) == "" {
+		return ErrCaptchaRequired
+	}
+	return p.verify(ctx, request)
+}
+
+// checkEmail checks the format and the domain of an email address.
+func (p Policy) checkEmail(email string) error {
+	if email == "" {
+		if p.RequireEmail {
+			return ErrEmailRequired
+		}
+		return nil
+	}
+	domain, ok := emailDomain(email)
+	if !ok {
+		return ErrInvalidEmail
+	}
+	if Disposable(domain) {
+		return ErrDisposableEmail
+	}
+	for _, blocked := range p.BlockedDomains {
+		if domain == blocked || strings.HasSuffix(domain, "."+blocked) {
+			return ErrDisposableEmail
+		}
+	}
+	return nil
+}
+
+// siteVerifyResponse is the answer of the hCaptcha and reCAPTCHA siteverify endpoints.
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	Score      *float64 `json:"score"` // reCAPTCHA v3 only
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// verify asks the provider whether the token was issued for a solved challenge.
+func (p Policy) verify(ctx context.Context, request Request) error {
+	endpoint := p.VerifyURL
+	if endpoint == "" {
+		endpoint = verifyURLs[p.CaptchaProvider]
+	}
+	form := url.Values{"secret": {p.CaptchaSecret}, "response": {request.CaptchaToken}}
+	if request.RemoteIP != "" {
+		form.Set("remoteip", request.RemoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCaptchaUnavailable, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCaptchaUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: status %d", ErrCaptchaUnavailable, resp.StatusCode)
+	}
+
+	var result siteVerifyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("%w: %v", ErrCaptchaUnavailable, err)
+	}
+	if !result.Success {
+		return ErrCaptchaFailed
+	}
+	if p.CaptchaMinScore > 0 && result.Score != nil && *result.Score < p.CaptchaMinScore {
+		return ErrCaptchaFailed
+	}
+	return nil
+}
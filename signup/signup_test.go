@@ -0,0 +1,88 @@
+// signup_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package signup
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCheckEmail tests that malformed and disposable email addresses are rejected
+func TestCheckEmail(t *testing.T) {
+	policy := Policy{BlockedDomains: []string{"junk.example"}}
+	ctx := context.Background()
+
+	assert.NoError(t, policy.Check(ctx, Request{}))                                                  // Assert that email is optional by default
+	assert.NoError(t, policy.Check(ctx, Request{Email: "jane@example.com"}))                         // Assert that ordinary addresses pass
+	assert.ErrorIs(t, policy.Check(ctx, Request{Email: "Jane <jane@example.com>"}), ErrInvalidEmail) // Assert that only bare addresses are accepted
+	assert.ErrorIs(t, policy.Check(ctx, Request{Email: "jane@localhost"}), ErrInvalidEmail)          // Assert that the domain needs a dot
+	assert.ErrorIs(t, policy.Check(ctx, Request{Email: "bot@Mailinator.com"}), ErrDisposableEmail)   // Assert that disposable providers are blocked
+	assert.ErrorIs(t, policy.Check(ctx, Request{Email: "bot@eu.yopmail.com"}), ErrDisposableEmail)   // Assert that their subdomains are blocked too
+	assert.ErrorIs(t, policy.Check(ctx, Request{Email: "bot@mx.junk.example"}), ErrDisposableEmail)  // Assert that configured domains are blocked
+	assert.ErrorIs(t, Policy{RequireEmail: true}.Check(ctx, Request{}), ErrEmailRequired)            // Assert that email can be required
+}
+
+// TestCheckCaptcha tests that CAPTCHA tokens are verified with the provider
+func TestCheckCaptcha(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "site-secret", r.PostForm.Get("secret"))
+		switch r.PostForm.Get("response") {
+		case "solved":
+			assert.Equal(t, "203.0.113.7", r.PostForm.Get("remoteip"))
+			_, _ = w.Write([]byte(`{"success": true, "score": 0.9}`))
+		case "bot":
+			_, _ = w.Write([]byte(`{"success": true, "score": 0.1}`))
+		case "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			_, _ = w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-response"]}`))
+		}
+	}))
+	defer provider.Close()
+
+	policy := Policy{CaptchaProvider: ProviderReCaptcha, CaptchaSecret: "site-secret", CaptchaMinScore: 0.5, VerifyURL: provider.URL}
+	require.NoError(t, policy.Validate())
+	ctx := context.Background()
+
+	// Assert that solved challenges pass and missing, invalid or low-scoring tokens don't
+	assert.NoError(t, policy.Check(ctx, Request{CaptchaToken: "solved", RemoteIP: "203.0.113.7"}))
+	assert.ErrorIs(t, policy.Check(ctx, Request{}), ErrCaptchaRequired)
+	assert.ErrorIs(t, policy.Check(ctx, Request{CaptchaToken: "forged"}), ErrCaptchaFailed)
+	assert.ErrorIs(t, policy.Check(ctx, Request{CaptchaToken: "bot"}), ErrCaptchaFailed)
+	// Assert that a failing provider is reported as unavailable
+	assert.ErrorIs(t, policy.Check(ctx, Request{CaptchaToken: "broken"}), ErrCaptchaUnavailable)
+
+	// Assert that unknown providers and providers without a secret are rejected
+	assert.Error(t, Policy{CaptchaProvider: "turnstile", CaptchaSecret: "s"}.Validate())
+	assert.Error(t, Policy{CaptchaProvider: ProviderHCaptcha}.Validate())
+}
+
+// TestInviteCodes tests that invite codes are required in invite mode and match however they are typed
+func TestInviteCodes(t *testing.T) {
+	policy := Policy{Mode: ModeInvite}
+	require.NoError(t, policy.Validate())
+	ctx := context.Background()
+
+	// Assert that invite mode needs a code and open mode doesn't
+	assert.ErrorIs(t, policy.Check(ctx, Request{}), ErrInviteRequired)
+	assert.NoError(t, policy.Check(ctx, Request{InviteCode: "ABCD"}))
+	assert.NoError(t, Policy{Mode: ModeOpen}.Check(ctx, Request{}))
+	assert.Error(t, Policy{Mode: "closed"}.Validate())
+
+	code, err := NewInviteCode()
+	require.NoError(t, err)
+	// Assert that codes are grouped and compared without case, dashes and spaces
+	assert.Regexp(t, `^[A-Z2-7]{4}(-[A-Z2-7]{4}){3}$`, code)
+	assert.Equal(t, HashInviteCode(code), HashInviteCode(" "+strings.ToLower(strings.ReplaceAll(code, "-", " "))))
+	other, err := NewInviteCode()
+	require.NoError(t, err)
+	assert.NotEqual(t, HashInviteCode(code), HashInviteCode(other))
+}
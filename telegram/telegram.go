@@ -0,0 +1,234 @@
+// telegram.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package telegram talks to the Telegram Bot API: it sends messages, reads the updates Telegram posts to
+// the bot's webhook, and issues the codes users link their chat to their account with.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/helper"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultAPIURL is the Telegram Bot API server.
+const DefaultAPIURL = "https://api.telegram.org"
+
+// SecretHeader is the header Telegram sends the webhook secret in.
+const SecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// ErrInvalidLinkCode is returned by ParseLinkCode for codes that are malformed, forged or expired.
+var ErrInvalidLinkCode = errors.New("invalid or expired link code")
+
+// secretPattern is the alphabet Telegram allows in webhook secrets.
+var secretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{32,256}$`)
+
+// Bot is a Telegram bot created with @BotFather.
+type Bot struct {
+	Token         string       // Bot token from @BotFather
+	Username      string       // Username of the bot without "@", for t.me links
+	WebhookSecret string       // Sent by Telegram with every update; also signs the link codes
+	APIURL        string       // Bot API server; DefaultAPIURL when empty
+	Client        *http.Client // HTTP client to use; http.DefaultClient when nil
+}
+
+// LoadBot reads the TELEGRAM_BOT_TOKEN, TELEGRAM_BOT_USERNAME, TELEGRAM_WEBHOOK_SECRET and
+// TELEGRAM_API_URL environment variables.
+//
+// Returns:
+// - *Bot: The bot, or nil if TELEGRAM_BOT_TOKEN is not set.
+// - error: An error if the bot is configured incompletely.
+func LoadBot() (*Bot, error) {
+	token := helper.GetEnv("TELEGRAM_BOT_TOKEN")
+	if token == "" {
+		return nil, nil
+	}
+	bot := &Bot{
+		Token:         token,
+		Username:      strings.TrimPrefix(helper.GetEnv("TELEGRAM_BOT_USERNAME"), "@"),
+		WebhookSecret: helper.GetEnv("TELEGRAM_WEBHOOK_SECRET"),
+		APIURL:        helper.GetEnv("TELEGRAM_API_URL"),
+	}
+	if err := bot.Validate(); err != nil {
+		return nil, err
+	}
+	return bot, nil
+}
+
+// Validate checks that the bot has a token, a username and a webhook secret Telegram accepts.
+//
+// Returns:
+// - error: A descriptive error if a setting is missing or invalid.
+func (b *Bot) Validate() error {
+	if b.Token == "" {
+		return errors.New("TELEGRAM_BOT_TOKEN is required")
+	}
+	if b.Username == "" {
+		return errors.New("TELEGRAM_BOT_USERNAME is required")
+	}
+	if !secretPattern.MatchString(b.WebhookSecret) {
+		return errors.New("TELEGRAM_WEBHOOK_SECRET must be 32 to 256 letters, digits, _ or -")
+	}
+	return nil
+}
+
+// LinkURL returns the t.me link that opens a chat with the bot and sends it "/start <code>".
+func (b *Bot) LinkURL(code string) string {
+	return "https://t.me/" + b.Username + "?start=" + code
+}
+
+// SendMessage sends a plain-text message to a chat.
+//
+// Parameters:
+// - ctx: Context bounding the request.
+// - chatID: The chat to send to.
+// - text: The message text.
+//
+// Returns:
+// - error: An error if the request failed or Telegram refused the message.
+func (b *Bot) SendMessage(ctx context.Context, chatID int64, text string) error {
+	body, err := json.Marshal(map[string]interface{}{"chat_id": chatID, "text": text})
+	if err != nil {
+		return err
+	}
+	apiURL := b.APIURL
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(apiURL, "/")+"/bot"+b.Token+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := b.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// The URL contains the bot token, so keep it out of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram sendMessage: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || !result.OK {
+		return fmt.Errorf("telegram sendMessage answered %s: %s", resp.Status, result.Description)
+	}
+	return nil
+}
+
+// Update is an update Telegram posts to the webhook. Only messages are handled.
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message"`
+}
+
+// Message is a message sent to the bot.
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	Chat      Chat   `json:"chat"`
+	Text      string `json:"text"`
+}
+
+// Chat is the chat a message was sent in.
+type Chat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"` // "private" for a one-to-one chat with the bot
+}
+
+// ParseCommand splits a message like "/new Write report" into the command and its arguments. Commands
+// addressed to another bot, like "/new@other_bot", are not commands for this one.
+//
+// Parameters:
+// - text: The message text.
+//
+// Returns:
+// - string: The command without the slash, in lower case, e.g. "new".
+// - string: The arguments, trimmed.
+// - bool: Whether the message is a command for this bot.
+func (b *Bot) ParseCommand(text string) (string, string, bool) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return "", "", false
+	}
+	command, args, _ := strings.Cut(text[1:], " ")
+	command, addressee, addressed := strings.Cut(command, "@")
+	if addressed && !strings.EqualFold(addressee, b.Username) {
+		return "", "", false
+	}
+	return strings.ToLower(command), strings.TrimSpace(args), command != ""
+}
+
+// LinkCode issues a code that links the chat it is sent from to a user until expiry. The code is signed
+// with the webhook secret, so it needs no storage, and fits Telegram's 64 character limit for /start.
+//
+// Parameters:
+// - userID: The user to link.
+// - expiry: When the code expires.
+//
+// Returns:
+// - string: The code.
+func (b *Bot) LinkCode(userID primitive.ObjectID, expiry time.Time) string {
+	payload := make([]byte, 16)
+	copy(payload, userID[:])
+	binary.BigEndian.PutUint32(payload[12:], uint32(expiry.Unix()))
+	return base64.RawURLEncoding.EncodeToString(append(payload, b.linkMAC(payload)...))
+}
+
+// ParseLinkCode returns the user a code issued by LinkCode links to.
+//
+// Parameters:
+// - code: The code.
+// - now: The current time.
+//
+// Returns:
+// - primitive.ObjectID: The user to link.
+// - error: ErrInvalidLinkCode if the code is malformed, forged or expired.
+func (b *Bot) ParseLinkCode(code string, now time.Time) (primitive.ObjectID, error) {
+	data, err := base64.RawURLEncoding.DecodeString(code)
+	if err != nil || len(data) != 28 {
+		return primitive.NilObjectID, ErrInvalidLinkCode
+	}
+	payload, mac := data[:16], data[16:]
+	if !hmac.Equal(mac, b.linkMAC(payload)) {
+		return primitive.NilObjectID, ErrInvalidLinkCode
+	}
+	if now.Unix() > int64(binary.BigEndian.Uint32(payload[12:])) {
+		return primitive.NilObjectID, ErrInvalidLinkCode
+	}
+	var userID primitive.ObjectID
+	copy(userID[:], payload[:12])
+	return userID, nil
+}
+
+// linkMAC returns the truncated HMAC-SHA256 of a link code payload.
+func (b *Bot) linkMAC(payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(b.WebhookSecret))
+	mac.Write([]byte("telegram-link:"))
+	mac.Write(payload)
+	return mac.Sum(nil)[:12]
+}
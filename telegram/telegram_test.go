@@ -0,0 +1,62 @@
+// telegram_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestLinkCode tests that link codes identify the user until they expire and cannot be forged
+func TestLinkCode(t *testing.T) {
+	bot := &Bot{Token: "token", Username: "tasks_bot", WebhookSecret: strings.Repeat("a", 32)}
+	userID := primitive.NewObjectID()
+	now := time.Unix(1720180800, 0)
+	code := bot.LinkCode(userID, now.Add(10*time.Minute))
+
+	// Assert that the code fits the 64 characters Telegram allows for /start
+	assert.LessOrEqual(t, len(code), 64)
+	parsed, err := bot.ParseLinkCode(code, now)
+	require.NoError(t, err)
+	assert.Equal(t, userID, parsed)
+
+	// Assert that expired codes and codes of another secret are rejected
+	_, err = bot.ParseLinkCode(code, now.Add(11*time.Minute))
+	assert.ErrorIs(t, err, ErrInvalidLinkCode)
+	other := &Bot{WebhookSecret: strings.Repeat("b", 32)}
+	_, err = other.ParseLinkCode(code, now)
+	assert.ErrorIs(t, err, ErrInvalidLinkCode)
+	_, err = bot.ParseLinkCode("not-a-code", now)
+	assert.ErrorIs(t, err, ErrInvalidLinkCode)
+}
+
+// TestParseCommand tests that commands are split from their arguments and commands for other bots are ignored
+func TestParseCommand(t *testing.T) {
+	bot := &Bot{Username: "tasks_bot"}
+
+	tests := []struct {
+		text    string
+		command string
+		args    string
+		ok      bool
+	}{
+		{"/new Write report due friday", "new", "Write report due friday", true},
+		{"  /Tasks  ", "tasks", "", true},
+		{"/new@Tasks_Bot Call Bob", "new", "Call Bob", true},
+		{"/new@other_bot Call Bob", "", "", false},
+		{"Write report", "", "", false},
+		{"/", "", "", false},
+	}
+	for _, test := range tests {
+		command, args, ok := bot.ParseCommand(test.text)
+		assert.Equal(t, test.ok, ok, test.text)
+		assert.Equal(t, test.command, command, test.text)
+		assert.Equal(t, test.args, args, test.text)
+	}
+}
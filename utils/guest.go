@@ -0,0 +1,172 @@
+// guest.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TokenTypeGuest is the "typ" claim of the tokens issued to guests accepting a project invitation. Only
+// the read-only routes open to guests accept them.
+const TokenTypeGuest = "guest"
+
+// Reasons a guest token is rejected besides those of TokenPolicy.Validate
+var (
+	ErrGuestTokenType    = errors.New("token is not a guest token")
+	ErrGuestTokenProject = errors.New("token has no project or invitation")
+)
+
+// GuestClaims are the claims of a guest token, granting read access to one project of its owner.
+type GuestClaims struct {
+	jwt.RegisteredClaims
+	UserID       string `json:"userId"` // The owner of the project
+	ProjectID    string `json:"pid"`
+	InvitationID string `json:"sid"` // Revoking the invitation invalidates the token
+	Email        string `json:"email"`
+	Type         string `json:"typ"`
+}
+
+// Valid checks the claims against Tokens at the current time.
+func (c GuestClaims) Valid() error {
+	return Tokens.ValidateGuest(c, time.Now())
+}
+
+// NewGuestClaims returns the claims of a guest token issued now.
+//
+// Parameters:
+// - userId: The hex ID of the owner of the project.
+// - projectId: The hex ID of the project the guest may read.
+// - invitationId: The hex ID of the accepted invitation.
+// - email: The email address the invitation was sent to.
+// - now: The time the token is issued and becomes valid.
+// - expiry: The time the token expires.
+//
+// Returns:
+// - GuestClaims: The claims, to be signed with SigningSecret.SignToken.
+func (p TokenPolicy) NewGuestClaims(userId, projectId, invitationId, email string, now, expiry time.Time) GuestClaims {
+	claims := GuestClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    p.Issuer,
+			Subject:   email,
+			ExpiresAt: jwt.NewNumericDate(expiry),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		UserID:       userId,
+		ProjectID:    projectId,
+		InvitationID: invitationId,
+		Email:        email,
+		Type:         TokenTypeGuest,
+	}
+	if p.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{p.Audience}
+	}
+	return claims
+}
+
+// ValidateGuest checks a guest token like Validate checks an access token, and that it names a project
+// and the invitation it was issued for.
+//
+// Parameters:
+// - claims: The claims of the token.
+// - now: The time to check the validity period against.
+//
+// Returns:
+// - error: The reason the token is rejected, or nil.
+func (p TokenPolicy) ValidateGuest(claims GuestClaims, now time.Time) error {
+	switch {
+	case claims.ExpiresAt == nil:
+		return ErrTokenNoExpiry
+	case !now.Before(claims.ExpiresAt.Add(p.ClockSkew)):
+		return ErrTokenExpired
+	case claims.NotBefore != nil && now.Add(p.ClockSkew).Before(claims.NotBefore.Time):
+		return ErrTokenNotYetValid
+	case p.Issuer != "" && claims.Issuer != p.Issuer:
+		return ErrTokenIssuer
+	case p.Audience != "" && !claims.VerifyAudience(p.Audience, true):
+		return ErrTokenAudience
+	case claims.Type != TokenTypeGuest:
+		return ErrGuestTokenType
+	case claims.UserID == "":
+		return ErrTokenUser
+	case claims.ProjectID == "" || claims.InvitationID == "":
+		return ErrGuestTokenProject
+	}
+	return nil
+}
+
+// IsGuestToken reports whether a token claims to be a guest token, without verifying it.
+//
+// Parameters:
+// - tokenString: The token from the Authorization header.
+//
+// Returns:
+// - bool: Whether the "typ" claim is TokenTypeGuest.
+func IsGuestToken(tokenString string) bool {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(strings.TrimPrefix(tokenString, "Bearer "), claims); err != nil {
+		return false
+	}
+	return claims["typ"] == TokenTypeGuest
+}
+
+// GuestMiddleware authenticates the read-only routes open to guests. Access tokens are handled like
+// JWTMiddleware does. Guest tokens must belong to an accepted invitation that has not been revoked; the
+// request is then scoped to the project owner ("userId") and the "guestProjectId" and "guestEmail" locals
+// are set, which the handlers use to show only the guest's project.
+//
+// Parameters:
+// - secret: The secret the tokens are signed with.
+//
+// Returns:
+// - fiber.Handler: The Fiber middleware handler.
+func GuestMiddleware(secret *SigningSecret) fiber.Handler {
+	users := JWTMiddleware(secret)
+	return func(c *fiber.Ctx) error {
+		tokenString := c.Get("Authorization")
+		if !IsGuestToken(tokenString) {
+			return users(c)
+		}
+
+		claims := &GuestClaims{}
+		if _, err := jwt.ParseWithClaims(tokenString, claims, secret.Keyfunc); err != nil {
+			log.Printf("Error parsing guest JWT: %v", err)
+			return apierror.Unauthorized(apierror.CodeInvalidToken, "invalid JWT")
+		}
+		projectId, err := primitive.ObjectIDFromHex(claims.ProjectID)
+		if err != nil || !activeInvitation(claims.InvitationID, projectId) {
+			return apierror.Unauthorized(apierror.CodeInvalidToken, "invitation revoked")
+		}
+
+		c.Locals("userId", claims.UserID)
+		c.Locals("guestProjectId", projectId)
+		c.Locals("guestEmail", claims.Email)
+		return c.Next()
+	}
+}
+
+// activeInvitation reports whether the invitation a guest token was issued for exists, is for the token's
+// project, was accepted and has not been revoked.
+func activeInvitation(invitationId string, projectId primitive.ObjectID) bool {
+	id, err := primitive.ObjectIDFromHex(invitationId)
+	if err != nil {
+		return false
+	}
+	invitation, err := repository.Invitations.FindByID(context.Background(), id)
+	if err != nil {
+		return false
+	}
+	return invitation.ProjectID == projectId && !invitation.AcceptedAt.IsZero() && invitation.RevokedAt.IsZero()
+}
@@ -0,0 +1,51 @@
+// scopes.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package utils
+
+import "net/http"
+
+// Scopes of API tokens. Tokens issued at sign-in have no scopes and may do everything their user may.
+const (
+	ScopeTasksRead  = "tasks:read"  // Read requests (GET and HEAD)
+	ScopeTasksWrite = "tasks:write" // All requests outside the admin endpoints; includes tasks:read
+	ScopeAdmin      = "admin"       // The admin endpoints, for admins only; includes tasks:write
+)
+
+// KnownScope reports whether scope is one of the scopes above.
+func KnownScope(scope string) bool {
+	return scope == ScopeTasksRead || scope == ScopeTasksWrite || scope == ScopeAdmin
+}
+
+// HasScope reports whether the scopes of a token grant the required scope. Tokens without scopes grant
+// every scope.
+//
+// Parameters:
+// - granted: The scopes of the token.
+// - required: The scope the request needs.
+//
+// Returns:
+// - bool: Whether the request is allowed.
+func HasScope(granted []string, required string) bool {
+	if len(granted) == 0 {
+		return true
+	}
+	for _, scope := range granted {
+		switch {
+		case scope == required, scope == ScopeAdmin:
+			return true
+		case scope == ScopeTasksWrite && required == ScopeTasksRead:
+			return true
+		}
+	}
+	return false
+}
+
+// methodScope returns the scope a request with the given method needs outside the admin endpoints. The
+// WebDAV PROPFIND and REPORT methods of the CalDAV endpoints only read.
+func methodScope(method string) string {
+	if method == http.MethodGet || method == http.MethodHead || method == "PROPFIND" || method == "REPORT" {
+		return ScopeTasksRead
+	}
+	return ScopeTasksWrite
+}
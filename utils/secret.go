@@ -0,0 +1,97 @@
+// secret.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// ErrSigningMethod is returned for tokens not signed with HMAC.
+var ErrSigningMethod = errors.New("unexpected signing method")
+
+// SigningSecret is the HMAC secret tokens are signed and verified with. It can be replaced while the
+// server runs, e.g. when it is rotated in a secrets backend: new tokens are signed with the new secret,
+// and tokens signed with the previous one stay valid until they expire. Tokens name their secret in the
+// "kid" header, a hash prefix of the secret.
+type SigningSecret struct {
+	mu       sync.RWMutex
+	current  string
+	previous string
+}
+
+// NewSigningSecret creates a signing secret.
+//
+// Parameters:
+// - secret: The secret to sign and verify tokens with.
+//
+// Returns:
+// - *SigningSecret: The signing secret.
+func NewSigningSecret(secret string) *SigningSecret {
+	return &SigningSecret{current: secret}
+}
+
+// Set replaces the secret new tokens are signed with, keeping the current one for verifying the tokens
+// already issued. Setting the current secret again changes nothing.
+//
+// Parameters:
+// - secret: The new secret.
+//
+// Returns:
+// - bool: Whether the secret changed.
+func (s *SigningSecret) Set(secret string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if secret == s.current {
+		return false
+	}
+	s.previous, s.current = s.current, secret
+	return true
+}
+
+// SignToken signs claims with the current secret (HS256), naming it in the "kid" header.
+//
+// Parameters:
+// - claims: The claims of the token.
+//
+// Returns:
+// - string: The signed token.
+// - error: An error if the token could not be signed.
+func (s *SigningSecret) SignToken(claims jwt.Claims) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = keyID(s.current)
+	return token.SignedString([]byte(s.current))
+}
+
+// Keyfunc returns the key to verify a token with, for jwt.Parse: the previous secret if the token's
+// "kid" names it, otherwise the current one, which also covers tokens issued without a key ID.
+// Tokens not signed with HMAC are rejected.
+func (s *SigningSecret) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, ErrSigningMethod
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	kid, _ := token.Header["kid"].(string)
+	if s.previous != "" && kid != "" && kid == keyID(s.previous) {
+		return []byte(s.previous), nil
+	}
+	return []byte(s.current), nil
+}
+
+// keyID returns the key ID of a secret, the first 8 bytes of its SHA-256 hash in hex.
+func keyID(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,45 @@
+// secret_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSigningSecretRotation tests that tokens signed before a rotation stay valid after it
+func TestSigningSecretRotation(t *testing.T) {
+	secret := NewSigningSecret("first")
+	parse := func(token string) error {
+		_, err := jwt.Parse(token, secret.Keyfunc)
+		return err
+	}
+
+	old, err := secret.SignToken(jwt.MapClaims{"sub": "66a1"})
+	require.NoError(t, err)
+	require.NoError(t, parse(old))
+
+	// Assert that setting the same secret is not a rotation
+	assert.False(t, secret.Set("first"))
+
+	// Assert that after a rotation both the old and the new tokens verify
+	require.True(t, secret.Set("second"))
+	fresh, err := secret.SignToken(jwt.MapClaims{"sub": "66a1"})
+	require.NoError(t, err)
+	assert.NoError(t, parse(old))
+	assert.NoError(t, parse(fresh))
+
+	// Assert that tokens from two rotations ago no longer verify
+	require.True(t, secret.Set("third"))
+	assert.Error(t, parse(old))
+	assert.NoError(t, parse(fresh))
+
+	// Assert that tokens signed with another algorithm are rejected
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+	assert.ErrorIs(t, parse(unsigned), ErrSigningMethod)
+}
@@ -0,0 +1,104 @@
+// share.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// TokenTypeShare is the "typ" claim of the tokens of public task links. The API does not accept them
+// as access tokens, nor access tokens as share tokens.
+const TokenTypeShare = "share"
+
+// Reasons a share token is rejected besides those of TokenPolicy.Validate
+var (
+	ErrShareTokenType = errors.New("token is not a share token")
+	ErrShareTokenTask = errors.New("token has no task")
+)
+
+// ShareClaims are the claims of a share token, granting read access to one task until it expires.
+type ShareClaims struct {
+	jwt.RegisteredClaims
+	UserID string `json:"userId"` // The owner of the task
+	TaskID string `json:"tid"`
+	Type   string `json:"typ"`
+}
+
+// Valid checks the claims against Tokens at the current time.
+func (c ShareClaims) Valid() error {
+	return Tokens.ValidateShare(c, time.Now())
+}
+
+// NewShareClaims returns the claims of a share token issued now.
+//
+// Parameters:
+// - userId: The hex ID of the owner of the task.
+// - taskId: The hex ID of the shared task.
+// - now: The time the token is issued and becomes valid.
+// - expiry: The time the link expires.
+//
+// Returns:
+// - ShareClaims: The claims, to be signed with SigningSecret.SignToken.
+func (p TokenPolicy) NewShareClaims(userId, taskId string, now, expiry time.Time) ShareClaims {
+	return ShareClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    p.Issuer,
+			ExpiresAt: jwt.NewNumericDate(expiry),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		UserID: userId,
+		TaskID: taskId,
+		Type:   TokenTypeShare,
+	}
+}
+
+// ValidateShare checks that a share token expires and is within its validity period, give or take
+// ClockSkew, and that it names a task of a user and was issued by this API.
+//
+// Parameters:
+// - claims: The claims of the token.
+// - now: The time to check the validity period against.
+//
+// Returns:
+// - error: The reason the token is rejected, or nil.
+func (p TokenPolicy) ValidateShare(claims ShareClaims, now time.Time) error {
+	switch {
+	case claims.ExpiresAt == nil:
+		return ErrTokenNoExpiry
+	case !now.Before(claims.ExpiresAt.Add(p.ClockSkew)):
+		return ErrTokenExpired
+	case claims.NotBefore != nil && now.Add(p.ClockSkew).Before(claims.NotBefore.Time):
+		return ErrTokenNotYetValid
+	case p.Issuer != "" && claims.Issuer != p.Issuer:
+		return ErrTokenIssuer
+	case claims.Type != TokenTypeShare:
+		return ErrShareTokenType
+	case claims.UserID == "":
+		return ErrTokenUser
+	case claims.TaskID == "":
+		return ErrShareTokenTask
+	}
+	return nil
+}
+
+// ParseShareToken verifies the signature and claims of a share token.
+//
+// Parameters:
+// - secret: The secret the token was signed with.
+// - token: The token from the link.
+//
+// Returns:
+// - *ShareClaims: The claims of a valid token.
+// - error: The reason the token is rejected.
+func ParseShareToken(secret *SigningSecret, token string) (*ShareClaims, error) {
+	claims := &ShareClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, secret.Keyfunc); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,105 @@
+// sso.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// TokenTypeSAMLRequest is the "typ" claim of the tokens that carry a SAML authentication request through the
+// identity provider as its relay state. The API does not accept them as access tokens.
+const TokenTypeSAMLRequest = "saml"
+
+// Reasons a SAML request token is rejected besides those of TokenPolicy.Validate
+var (
+	ErrSAMLRequestTokenType = errors.New("token is not a SAML request token")
+	ErrSAMLRequestTokenID   = errors.New("token has no request ID")
+	ErrSAMLRequestNonce     = errors.New("token is not bound to a browser")
+)
+
+// SAMLRequestClaims are the claims of a SAML request token, naming the request a response must answer and
+// the browser that sent it.
+type SAMLRequestClaims struct {
+	jwt.RegisteredClaims
+	RequestID string `json:"rid"`
+	NonceHash string `json:"nonce"` // SHA-256 of the nonce in the cookie of the browser that started sign-in, hex
+	Type      string `json:"typ"`
+}
+
+// Valid checks the claims against Tokens at the current time.
+func (c SAMLRequestClaims) Valid() error {
+	return Tokens.ValidateSAMLRequest(c, time.Now())
+}
+
+// NewSAMLRequestClaims returns the claims of a SAML request token issued now.
+//
+// Parameters:
+// - requestId: The ID of the authentication request.
+// - nonceHash: The hash of the nonce the browser starting sign-in was given in a cookie.
+// - now: The time the request is sent and the token becomes valid.
+// - expiry: The time by which the user must have signed in at the identity provider.
+//
+// Returns:
+// - SAMLRequestClaims: The claims, to be signed with SigningSecret.SignToken.
+func (p TokenPolicy) NewSAMLRequestClaims(requestId, nonceHash string, now, expiry time.Time) SAMLRequestClaims {
+	return SAMLRequestClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    p.Issuer,
+			ExpiresAt: jwt.NewNumericDate(expiry),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+		RequestID: requestId,
+		NonceHash: nonceHash,
+		Type:      TokenTypeSAMLRequest,
+	}
+}
+
+// ValidateSAMLRequest checks that a SAML request token expires and is within its validity period, give or
+// take ClockSkew, and that it names a request and a browser and was issued by this API.
+//
+// Parameters:
+// - claims: The claims of the token.
+// - now: The time to check the validity period against.
+//
+// Returns:
+// - error: The reason the token is rejected, or nil.
+func (p TokenPolicy) ValidateSAMLRequest(claims SAMLRequestClaims, now time.Time) error {
+	switch {
+	case claims.ExpiresAt == nil:
+		return ErrTokenNoExpiry
+	case !now.Before(claims.ExpiresAt.Add(p.ClockSkew)):
+		return ErrTokenExpired
+	case claims.NotBefore != nil && now.Add(p.ClockSkew).Before(claims.NotBefore.Time):
+		return ErrTokenNotYetValid
+	case p.Issuer != "" && claims.Issuer != p.Issuer:
+		return ErrTokenIssuer
+	case claims.Type != TokenTypeSAMLRequest:
+		return ErrSAMLRequestTokenType
+	case claims.RequestID == "":
+		return ErrSAMLRequestTokenID
+	case claims.NonceHash == "":
+		return ErrSAMLRequestNonce
+	}
+	return nil
+}
+
+// ParseSAMLRequestToken verifies the signature and claims of a SAML request token.
+//
+// Parameters:
+// - secret: The secret the token was signed with.
+// - token: The relay state posted back by the identity provider.
+//
+// Returns:
+// - *SAMLRequestClaims: The claims of a valid token.
+// - error: The reason the token is rejected.
+func ParseSAMLRequestToken(secret *SigningSecret, token string) (*SAMLRequestClaims, error) {
+	claims := &SAMLRequestClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, secret.Keyfunc); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,158 @@
+// tokens.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package utils
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// TokenTypeAccess is the "typ" claim of the tokens issued at sign-in, the only type accepted by the API.
+const TokenTypeAccess = "access"
+
+// Reasons a token is rejected by TokenPolicy.Validate
+var (
+	ErrTokenNoExpiry    = errors.New("token has no expiry")
+	ErrTokenExpired     = errors.New("token is expired")
+	ErrTokenNotYetValid = errors.New("token is not valid yet")
+	ErrTokenIssuer      = errors.New("token has the wrong issuer")
+	ErrTokenAudience    = errors.New("token is not meant for this audience")
+	ErrTokenType        = errors.New("token is not an access token")
+	ErrTokenUser        = errors.New("token has no user")
+	ErrTokenScope       = errors.New("token has an unknown scope")
+)
+
+// TokenPolicy is what the issued tokens claim and what accepted tokens must claim.
+type TokenPolicy struct {
+	Issuer    string        // "iss" of the tokens; empty issues tokens without and accepts tokens of any issuer
+	Audience  string        // "aud" the tokens must include; empty issues tokens without and accepts any audience
+	ClockSkew time.Duration // Leeway for exp, nbf and iat, for clocks that differ between servers
+	Refresh   time.Duration // Lifetime of the refresh tokens issued at sign-in; 0 issues none
+
+	RememberMe time.Duration // Lifetime of the refresh tokens of sign-ins with remember_me; 0 uses Refresh
+	Sliding    bool          // Every refresh extends the session by its refresh token lifetime, up to MaxSession
+	MaxSession time.Duration // Longest a sliding session lasts after its sign-in; 0 is no limit
+}
+
+// Tokens is the policy of the issued and accepted tokens, set from the configuration by app.New.
+var Tokens = TokenPolicy{Issuer: "task-management", Audience: "task-management-api", ClockSkew: 30 * time.Second}
+
+// AccessClaims are the claims of an access token. Their Valid method applies Tokens, so every parser of
+// the token, including the JWT middlewares, enforces the policy.
+type AccessClaims struct {
+	jwt.RegisteredClaims
+	UserID    string `json:"userId"`
+	SessionID string `json:"sid,omitempty"`
+	Scope     string `json:"scope,omitempty"` // Space-separated scopes of an API token; empty for sign-in tokens
+	Type      string `json:"typ"`
+}
+
+// Scopes returns the scopes of the token, none for tokens issued at sign-in.
+func (c AccessClaims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// Valid checks the claims against Tokens at the current time.
+func (c AccessClaims) Valid() error {
+	return Tokens.Validate(c, time.Now())
+}
+
+// NewClaims returns the claims of an access token issued now.
+//
+// Parameters:
+// - userId: The hex ID of the user the token is for.
+// - sessionId: The hex ID of the session the token belongs to.
+// - now: The time the token is issued and becomes valid.
+// - expiry: The time the token expires.
+//
+// Returns:
+// - AccessClaims: The claims, to be signed with jwt.NewWithClaims.
+func (p TokenPolicy) NewClaims(userId, sessionId string, now, expiry time.Time) AccessClaims {
+	claims := AccessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    p.Issuer,
+			ExpiresAt: jwt.NewNumericDate(expiry),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		UserID:    userId,
+		SessionID: sessionId,
+		Type:      TokenTypeAccess,
+	}
+	if p.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{p.Audience}
+	}
+	return claims
+}
+
+// Validate checks that a token expires and is within its validity period, give or take ClockSkew, and
+// that it is an access token of a user issued by and for this API.
+//
+// Parameters:
+// - claims: The claims of the token.
+// - now: The time to check the validity period against.
+//
+// Returns:
+// - error: The reason the token is rejected, or nil.
+func (p TokenPolicy) Validate(claims AccessClaims, now time.Time) error {
+	switch {
+	case claims.ExpiresAt == nil:
+		return ErrTokenNoExpiry
+	case !now.Before(claims.ExpiresAt.Add(p.ClockSkew)):
+		return ErrTokenExpired
+	case claims.NotBefore != nil && now.Add(p.ClockSkew).Before(claims.NotBefore.Time):
+		return ErrTokenNotYetValid
+	case claims.IssuedAt != nil && now.Add(p.ClockSkew).Before(claims.IssuedAt.Time):
+		return ErrTokenNotYetValid
+	case p.Issuer != "" && claims.Issuer != p.Issuer:
+		return ErrTokenIssuer
+	case p.Audience != "" && !claims.VerifyAudience(p.Audience, true):
+		return ErrTokenAudience
+	case claims.Type != TokenTypeAccess:
+		return ErrTokenType
+	case claims.UserID == "":
+		return ErrTokenUser
+	}
+	for _, scope := range claims.Scopes() {
+		if !KnownScope(scope) {
+			return ErrTokenScope
+		}
+	}
+	return nil
+}
+
+// RefreshLifetime returns the lifetime of the refresh token of a sign-in, 0 if it gets none. Sign-ins with
+// remember_me get the longer of Refresh and RememberMe.
+func (p TokenPolicy) RefreshLifetime(rememberMe bool) time.Duration {
+	if rememberMe && p.RememberMe > p.Refresh {
+		return p.RememberMe
+	}
+	return p.Refresh
+}
+
+// SessionExpiry returns when a session with a refresh token expires, at its sign-in or after it is refreshed
+// at now. Sessions expire a refresh token lifetime after their sign-in; sliding sessions a lifetime after
+// their last refresh instead, but no later than MaxSession after their sign-in.
+//
+// Parameters:
+// - issuedAt: The time of the sign-in starting the session.
+// - rememberMe: Whether the sign-in asked to be remembered.
+// - now: The time of the sign-in or refresh.
+//
+// Returns:
+// - time.Time: The expiry of the session.
+func (p TokenPolicy) SessionExpiry(issuedAt time.Time, rememberMe bool, now time.Time) time.Time {
+	lifetime := p.RefreshLifetime(rememberMe)
+	if !p.Sliding {
+		return issuedAt.Add(lifetime)
+	}
+	expiry := now.Add(lifetime)
+	if limit := issuedAt.Add(p.MaxSession); p.MaxSession > 0 && expiry.After(limit) {
+		return limit
+	}
+	return expiry
+}
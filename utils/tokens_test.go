@@ -0,0 +1,107 @@
+// tokens_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTokenPolicyValidate tests the claims checks of access tokens
+func TestTokenPolicyValidate(t *testing.T) {
+	policy := TokenPolicy{Issuer: "task-management", Audience: "task-management-api", ClockSkew: 30 * time.Second}
+	now := time.Date(2024, 7, 5, 12, 0, 0, 0, time.UTC)
+	valid := policy.NewClaims("66a1", "66a2", now, now.Add(time.Hour))
+
+	// Assert that issued claims pass, also within the clock skew around their validity period
+	assert.NoError(t, policy.Validate(valid, now))
+	assert.NoError(t, policy.Validate(valid, now.Add(-20*time.Second)))
+	assert.NoError(t, policy.Validate(valid, now.Add(time.Hour+20*time.Second)))
+	assert.ErrorIs(t, policy.Validate(valid, now.Add(-time.Minute)), ErrTokenNotYetValid)
+	assert.ErrorIs(t, policy.Validate(valid, now.Add(time.Hour+time.Minute)), ErrTokenExpired)
+
+	// Assert that each broken claim is rejected
+	broken := func(change func(claims *AccessClaims)) AccessClaims {
+		claims := policy.NewClaims("66a1", "66a2", now, now.Add(time.Hour))
+		change(&claims)
+		return claims
+	}
+	assert.ErrorIs(t, policy.Validate(broken(func(c *AccessClaims) { c.ExpiresAt = nil }), now), ErrTokenNoExpiry)
+	assert.ErrorIs(t, policy.Validate(broken(func(c *AccessClaims) { c.Issuer = "someone-else" }), now), ErrTokenIssuer)
+	assert.ErrorIs(t, policy.Validate(broken(func(c *AccessClaims) { c.Audience = jwt.ClaimStrings{"billing"} }), now), ErrTokenAudience)
+	assert.ErrorIs(t, policy.Validate(broken(func(c *AccessClaims) { c.Type = "refresh" }), now), ErrTokenType)
+	assert.ErrorIs(t, policy.Validate(broken(func(c *AccessClaims) { c.UserID = "" }), now), ErrTokenUser)
+	assert.ErrorIs(t, policy.Validate(broken(func(c *AccessClaims) { c.Scope = "tasks:read tasks:delete" }), now), ErrTokenScope)
+	assert.NoError(t, policy.Validate(broken(func(c *AccessClaims) { c.Scope = "tasks:read" }), now))
+
+	// Assert that an empty issuer and audience are not checked
+	open := TokenPolicy{}
+	claims := broken(func(c *AccessClaims) { c.Issuer, c.Audience = "anyone", nil })
+	require.NoError(t, open.Validate(claims, now))
+}
+
+// TestTokenPolicySessions tests the lifetime of refresh tokens and sliding sessions
+func TestTokenPolicySessions(t *testing.T) {
+	policy := TokenPolicy{Refresh: 24 * time.Hour, RememberMe: 30 * 24 * time.Hour}
+	issued := time.Date(2024, 7, 5, 12, 0, 0, 0, time.UTC)
+
+	// Assert that remember_me gets the longer lifetime, and that fixed sessions expire a lifetime after sign-in
+	assert.Equal(t, 24*time.Hour, policy.RefreshLifetime(false))
+	assert.Equal(t, 30*24*time.Hour, policy.RefreshLifetime(true))
+	assert.Equal(t, 24*time.Hour, TokenPolicy{Refresh: 24 * time.Hour, RememberMe: time.Hour}.RefreshLifetime(true))
+	assert.Equal(t, issued.Add(24*time.Hour), policy.SessionExpiry(issued, false, issued.Add(20*time.Hour)))
+	assert.Equal(t, issued.Add(30*24*time.Hour), policy.SessionExpiry(issued, true, issued.Add(20*time.Hour)))
+
+	// Assert that sliding sessions last a lifetime from the last refresh, up to the maximum after sign-in
+	policy.Sliding, policy.MaxSession = true, 7*24*time.Hour
+	assert.Equal(t, issued.Add(44*time.Hour), policy.SessionExpiry(issued, false, issued.Add(20*time.Hour)))
+	assert.Equal(t, issued.Add(7*24*time.Hour), policy.SessionExpiry(issued, false, issued.Add(6*24*time.Hour+time.Hour)))
+	assert.Equal(t, issued.Add(7*24*time.Hour), policy.SessionExpiry(issued, true, issued))
+}
+
+// TestHasScope tests which scopes grant which
+func TestHasScope(t *testing.T) {
+	// Assert that sign-in tokens grant everything and scopes grant themselves
+	assert.True(t, HasScope(nil, ScopeAdmin))
+	assert.True(t, HasScope([]string{ScopeTasksRead}, ScopeTasksRead))
+
+	// Assert that write includes read, admin includes both, and read grants nothing more
+	assert.True(t, HasScope([]string{ScopeTasksWrite}, ScopeTasksRead))
+	assert.True(t, HasScope([]string{ScopeAdmin}, ScopeTasksWrite))
+	assert.False(t, HasScope([]string{ScopeTasksRead}, ScopeTasksWrite))
+	assert.False(t, HasScope([]string{ScopeTasksWrite}, ScopeAdmin))
+}
+
+// TestShareTokens tests that share tokens and access tokens are not accepted in place of each other
+func TestShareTokens(t *testing.T) {
+	secret := NewSigningSecret("secret")
+	now := time.Now()
+
+	share, err := secret.SignToken(Tokens.NewShareClaims("66a1", "66b1", now, now.Add(time.Hour)))
+	require.NoError(t, err)
+	access, err := secret.SignToken(Tokens.NewClaims("66a1", "", now, now.Add(time.Hour)))
+	require.NoError(t, err)
+	expired, err := secret.SignToken(Tokens.NewShareClaims("66a1", "66b1", now.Add(-2*time.Hour), now.Add(-time.Hour)))
+	require.NoError(t, err)
+
+	// Assert that a share token names its task
+	claims, err := ParseShareToken(secret, share)
+	require.NoError(t, err)
+	assert.Equal(t, "66a1", claims.UserID)
+	assert.Equal(t, "66b1", claims.TaskID)
+
+	// Assert that access tokens and expired links are rejected
+	_, err = ParseShareToken(secret, access)
+	assert.ErrorIs(t, err, ErrShareTokenType)
+	_, err = ParseShareToken(secret, expired)
+	assert.ErrorIs(t, err, ErrTokenExpired)
+
+	// Assert that a share token is not an access token
+	_, err = jwt.ParseWithClaims(share, &AccessClaims{}, secret.Keyfunc)
+	assert.Error(t, err)
+}
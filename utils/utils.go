@@ -1,10 +1,15 @@
 package utils
 
 import (
+	"context"
 	"log"
 
+	"github.com/bkojha74/task-management/apierror"
+	"github.com/bkojha74/task-management/repository"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -18,34 +23,62 @@ func CheckPasswordHash(password, hash string) bool {
 	return err == nil
 }
 
-func JWTMiddleware(secret string) fiber.Handler {
+func JWTMiddleware(secret *SigningSecret) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Get the token from the Authorization header
 		tokenString := c.Get("Authorization")
 		if tokenString == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "missing or malformed JWT"})
+			return apierror.Unauthorized(apierror.CodeInvalidToken, "missing or malformed JWT")
 		}
 
-		// Parse the token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Make sure that the token method conform to "SigningMethodHMAC"
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fiber.NewError(fiber.StatusUnauthorized, "unexpected signing method")
+		// Guests may only use the read-only routes of GuestMiddleware
+		if IsGuestToken(tokenString) {
+			if _, err := jwt.ParseWithClaims(tokenString, &GuestClaims{}, secret.Keyfunc); err == nil {
+				return apierror.Forbidden(apierror.CodeForbidden, "Guests have read-only access to their project")
 			}
-			return []byte(secret), nil
-		})
+		}
+
+		// Parse the token; the claims are validated against the token policy
+		token, err := jwt.ParseWithClaims(tokenString, &AccessClaims{}, secret.Keyfunc)
 
 		if err != nil {
 			log.Printf("Error parsing JWT: %v", err)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid JWT"})
+			return apierror.Unauthorized(apierror.CodeInvalidToken, "invalid JWT")
 		}
 
 		// Extract the claims and set them in the context
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			c.Locals("userId", claims["userId"])
+		if claims, ok := token.Claims.(*AccessClaims); ok && token.Valid {
+			if claims.SessionID != "" {
+				if !activeSession(claims.SessionID, claims.UserID) {
+					return apierror.Unauthorized(apierror.CodeInvalidToken, "session revoked")
+				}
+				c.Locals("sessionId", claims.SessionID)
+			}
+			// API tokens are limited to their scopes; the admin endpoints check for ScopeAdmin themselves
+			if scopes := claims.Scopes(); len(scopes) > 0 {
+				if !HasScope(scopes, methodScope(c.Method())) {
+					return apierror.Forbidden(apierror.CodeForbidden, "token lacks the required scope")
+				}
+				c.Locals("scopes", scopes)
+			}
+			c.Locals("userId", claims.UserID)
 			return c.Next()
 		} else {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid JWT"})
+			return apierror.Unauthorized(apierror.CodeInvalidToken, "invalid JWT")
 		}
 	}
 }
+
+// activeSession reports whether the session a token was issued for exists, belongs to the token's user
+// and has not been revoked.
+func activeSession(sid string, userId string) bool {
+	id, err := primitive.ObjectIDFromHex(sid)
+	if err != nil {
+		return false
+	}
+	session, err := repository.Sessions.FindByID(context.Background(), id)
+	if err != nil {
+		return false
+	}
+	return session.UserID.Hex() == userId && session.RevokedAt.IsZero()
+}
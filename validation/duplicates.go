@@ -0,0 +1,118 @@
+// duplicates.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package validation
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+)
+
+var (
+	// DuplicateThreshold is the title similarity, between 0 and 1, from which an open task of the same
+	// assignee is a near-duplicate.
+	DuplicateThreshold = 0.75
+
+	// MaxDuplicates is the number of near-duplicates returned, most similar first.
+	MaxDuplicates = 5
+)
+
+// Duplicate is an open task that looks like the same work item as a new task.
+type Duplicate struct {
+	Task       models.Task
+	Similarity float64 // Title similarity, see TitleSimilarity
+}
+
+// Duplicates finds the open tasks allotted to the same user as task whose titles are near-duplicates of
+// its title, most similar first. Tasks of all owners are returned; callers hide those the user may not read.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - task: The task about to be created.
+//
+// Returns:
+// - []Duplicate: Up to MaxDuplicates near-duplicates, empty if there are none.
+// - error: An error if the tasks could not be read.
+func Duplicates(ctx context.Context, task models.Task) ([]Duplicate, error) {
+	duplicates := []Duplicate{}
+	if task.AllottedTo == "" || strings.TrimSpace(task.Title) == "" {
+		return duplicates, nil
+	}
+
+	open, err := repository.Tasks.Find(ctx, repository.TaskQuery{
+		AllottedTo:    task.AllottedTo,
+		ExcludeStatus: models.TaskStatusDone,
+		ExcludeID:     task.ID,
+		Fields:        []string{"userId", "title", "status", "allotted_to", "visibility", "project_id"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range open {
+		if similarity := TitleSimilarity(task.Title, candidate.Title); similarity >= DuplicateThreshold {
+			duplicates = append(duplicates, Duplicate{Task: candidate, Similarity: similarity})
+		}
+	}
+
+	sort.SliceStable(duplicates, func(i, j int) bool { return duplicates[i].Similarity > duplicates[j].Similarity })
+	if len(duplicates) > MaxDuplicates {
+		duplicates = duplicates[:MaxDuplicates]
+	}
+	return duplicates, nil
+}
+
+// TitleSimilarity compares two titles, ignoring case, punctuation and spacing: the Dice coefficient of
+// their character bigrams, so that reworded and mistyped titles still score high.
+//
+// Parameters:
+// - a: The first title.
+// - b: The second title.
+//
+// Returns:
+// - float64: 1 for the same title, 0 for titles with nothing in common.
+func TitleSimilarity(a, b string) float64 {
+	a, b = normalizeTitle(a), normalizeTitle(b)
+	if a == b {
+		return 1
+	}
+	left, right := bigrams(a), bigrams(b)
+	if len(left) == 0 || len(right) == 0 {
+		return 0
+	}
+
+	counts := map[string]int{}
+	for _, pair := range left {
+		counts[pair]++
+	}
+	shared := 0
+	for _, pair := range right {
+		if counts[pair] > 0 {
+			counts[pair]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(left)+len(right))
+}
+
+// normalizeTitle lowercases a title and reduces everything but letters and digits to single spaces.
+func normalizeTitle(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// bigrams returns the pairs of adjacent characters of a normalized title.
+func bigrams(title string) []string {
+	runes := []rune(title)
+	pairs := []string{}
+	for i := 0; i+1 < len(runes); i++ {
+		pairs = append(pairs, string(runes[i:i+2]))
+	}
+	return pairs
+}
@@ -0,0 +1,52 @@
+// duplicates_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package validation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTitleSimilarity tests that reworded and mistyped titles are similar and different titles are not
+func TestTitleSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, TitleSimilarity("Fix login page", "  fix LOGIN-page! ")) // Assert that case, punctuation and spacing are ignored
+	assert.Greater(t, TitleSimilarity("Fix login page", "Fix logn page"), 0.75)   // Assert that typos keep titles similar
+	assert.Less(t, TitleSimilarity("Fix login page", "Write release notes"), 0.3) // Assert that different titles are not
+	assert.Equal(t, 0.0, TitleSimilarity("a", "b"))                               // Assert that single characters only match themselves
+}
+
+// TestDuplicates tests that only similar open tasks of the same assignee are duplicates
+func TestDuplicates(t *testing.T) {
+	repository.UseMemory()
+	ctx := context.Background()
+
+	for _, task := range []models.Task{
+		{Title: "Fix the login page", AllottedTo: "alice", Status: models.TaskStatusPending},
+		{Title: "Fix login page", AllottedTo: "alice", Status: models.TaskStatusInProgress},
+		{Title: "Fix login page", AllottedTo: "alice", Status: models.TaskStatusDone},
+		{Title: "Fix login page", AllottedTo: "bob", Status: models.TaskStatusPending},
+		{Title: "Update pricing", AllottedTo: "alice", Status: models.TaskStatusPending},
+	} {
+		require.NoError(t, repository.Tasks.Create(ctx, &task))
+	}
+
+	duplicates, err := Duplicates(ctx, models.Task{Title: "fix login page", AllottedTo: "alice"})
+	require.NoError(t, err)
+	// Assert that the open tasks of the assignee are found, most similar first
+	require.Len(t, duplicates, 2)
+	assert.Equal(t, "Fix login page", duplicates[0].Task.Title)
+	assert.Equal(t, 1.0, duplicates[0].Similarity)
+	assert.Equal(t, "Fix the login page", duplicates[1].Task.Title)
+
+	// Assert that tasks without an assignee are not checked
+	duplicates, err = Duplicates(ctx, models.Task{Title: "Fix login page"})
+	require.NoError(t, err)
+	assert.Empty(t, duplicates)
+}
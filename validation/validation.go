@@ -0,0 +1,81 @@
+// validation.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package validation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+)
+
+// Warning is a non-fatal validation finding. The request still succeeds, but the client is told about it.
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+var (
+	// Holidays are the dates (YYYY-MM-DD) on which a due date triggers a warning.
+	Holidays = map[string]bool{}
+
+	// MaxOpenTasksPerAssignee is the number of open tasks above which an assignee is considered overloaded.
+	// Zero disables the check.
+	MaxOpenTasksPerAssignee = 20
+)
+
+// SetHolidays replaces the holiday calendar with a comma separated list of YYYY-MM-DD dates.
+func SetHolidays(list string) {
+	holidays := map[string]bool{}
+	for _, day := range strings.Split(list, ",") {
+		if day = strings.TrimSpace(day); day != "" {
+			holidays[day] = true
+		}
+	}
+	Holidays = holidays
+}
+
+// TaskWarnings checks a task about to be created or updated and returns the non-fatal warnings.
+//
+// Parameters:
+// - ctx: Context for the database operations.
+// - task: The task to check.
+//
+// Returns:
+// - []Warning: The warnings found, empty if the task looks fine.
+func TaskWarnings(ctx context.Context, task models.Task) []Warning {
+	warnings := []Warning{}
+
+	if task.EndDate != 0 {
+		due := task.EndDate.Time().UTC()
+		if due.Before(time.Now()) {
+			warnings = append(warnings, Warning{"due_in_past", "due date is in the past"})
+		}
+		if task.StartDate != 0 && due.Before(task.StartDate.Time()) {
+			warnings = append(warnings, Warning{"due_before_start", "due date is before the start date"})
+		}
+		if Holidays[due.Format("2006-01-02")] {
+			warnings = append(warnings, Warning{"due_on_holiday", "due date is on a holiday"})
+		} else if due.Weekday() == time.Saturday || due.Weekday() == time.Sunday {
+			warnings = append(warnings, Warning{"due_on_weekend", "due date is on a weekend"})
+		}
+	}
+
+	if task.AllottedTo != "" && MaxOpenTasksPerAssignee > 0 {
+		open, err := repository.Tasks.Count(ctx, repository.TaskQuery{
+			AllottedTo:    task.AllottedTo,
+			ExcludeStatus: models.TaskStatusDone,
+			ExcludeID:     task.ID,
+		})
+		if err == nil && open >= int64(MaxOpenTasksPerAssignee) {
+			warnings = append(warnings, Warning{"assignee_overloaded",
+				fmt.Sprintf("assignee %s already has %d open tasks", task.AllottedTo, open)})
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,46 @@
+// validation_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package validation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// warningCodes returns the codes of the warnings for a task due at the given time
+func warningCodes(due time.Time) []string {
+	codes := []string{}
+	for _, warning := range TaskWarnings(context.Background(), models.Task{EndDate: models.NewTimestamp(due)}) {
+		codes = append(codes, warning.Code)
+	}
+	return codes
+}
+
+// TestTaskWarningsDueDate tests the due date warnings
+func TestTaskWarningsDueDate(t *testing.T) {
+	nextYear := time.Now().Year() + 1
+
+	SetHolidays(time.Date(nextYear, 12, 25, 0, 0, 0, 0, time.UTC).Format("2006-01-02"))
+	defer SetHolidays("")
+
+	// Find a future weekday that is not a holiday
+	weekday := time.Date(nextYear, 3, 2, 12, 0, 0, 0, time.UTC)
+	for weekday.Weekday() == time.Saturday || weekday.Weekday() == time.Sunday {
+		weekday = weekday.AddDate(0, 0, 1)
+	}
+	saturday := weekday
+	for saturday.Weekday() != time.Saturday {
+		saturday = saturday.AddDate(0, 0, 1)
+	}
+
+	assert.Empty(t, warningCodes(weekday))                                                                       // Assert that a future weekday is fine
+	assert.Equal(t, []string{"due_on_weekend"}, warningCodes(saturday))                                          // Assert that weekends are flagged
+	assert.Equal(t, []string{"due_on_holiday"}, warningCodes(time.Date(nextYear, 12, 25, 9, 0, 0, 0, time.UTC))) // Assert that holidays are flagged
+	assert.Contains(t, warningCodes(time.Now().Add(-time.Hour)), "due_in_past")                                  // Assert that past due dates are flagged
+}
@@ -0,0 +1,315 @@
+// deliverer.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bkojha74/task-management/deadletter"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/helper"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxBackoff caps the delay between two delivery attempts.
+const maxBackoff = 5 * time.Minute
+
+// Config sizes the worker pool and the retry policy of a Deliverer.
+type Config struct {
+	Workers     int           // Number of concurrent deliveries
+	QueueSize   int           // Events waiting for a worker before new ones are dropped
+	MaxAttempts int           // Delivery attempts before a delivery fails
+	Backoff     time.Duration // Delay before the first retry, doubled for every further retry
+	Timeout     time.Duration // Time limit of a single attempt
+}
+
+// LoadConfig reads the WEBHOOK_WORKERS, WEBHOOK_QUEUE_SIZE, WEBHOOK_MAX_ATTEMPTS, WEBHOOK_BACKOFF_MS and
+// WEBHOOK_TIMEOUT (seconds) environment variables.
+//
+// Returns:
+// - Config: The configured settings.
+func LoadConfig() Config {
+	return Config{
+		Workers:     helper.GetEnvInt("WEBHOOK_WORKERS", 4),
+		QueueSize:   helper.GetEnvInt("WEBHOOK_QUEUE_SIZE", 1000),
+		MaxAttempts: helper.GetEnvInt("WEBHOOK_MAX_ATTEMPTS", 5),
+		Backoff:     time.Duration(helper.GetEnvInt("WEBHOOK_BACKOFF_MS", 1000)) * time.Millisecond,
+		Timeout:     time.Duration(helper.GetEnvInt("WEBHOOK_TIMEOUT", 10)) * time.Second,
+	}
+}
+
+// Deliverer delivers domain events to the webhooks subscribed to them on a bounded pool of workers, so
+// slow receivers never block requests. Every delivery and attempt is recorded; failed attempts are retried
+// with exponential backoff, and deliveries that still fail are dead-lettered, to be replayed or discarded
+// by an admin.
+type Deliverer struct {
+	Client *http.Client // HTTP client to send with; Client when nil
+
+	// DeadLetter records failed deliveries; it defaults to deadletter.Record.
+	DeadLetter func(ctx context.Context, kind string, payload map[string]interface{}, cause error) error
+
+	config Config
+	queue  chan events.Event
+	abort  chan struct{}
+	wg     sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewDeliverer starts a deliverer.
+//
+// Parameters:
+// - config: The worker pool and retry settings; non-positive values fall back to one worker, an
+// unbuffered queue and a single attempt.
+//
+// Returns:
+// - *Deliverer: The running deliverer. Subscribe its Handle method to a bus and Close it on shutdown.
+func NewDeliverer(config Config) *Deliverer {
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.QueueSize < 0 {
+		config.QueueSize = 0
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 1
+	}
+
+	d := &Deliverer{
+		DeadLetter: deadletter.Record,
+		config:     config,
+		queue:      make(chan events.Event, config.QueueSize),
+		abort:      make(chan struct{}),
+	}
+	d.wg.Add(config.Workers)
+	for i := 0; i < config.Workers; i++ {
+		go d.work()
+	}
+	return d
+}
+
+// Handle queues an event for the webhooks without waiting for them. Events that find the queue full are
+// dropped and logged.
+func (d *Deliverer) Handle(ctx context.Context, event events.Event) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return
+	}
+
+	select {
+	case d.queue <- event:
+	default:
+		log.Printf("Webhook queue is full, dropped %s event %s", event.Type, event.ID)
+	}
+}
+
+// Close stops accepting events and waits for the queued ones to be delivered. When ctx ends first,
+// pending retries are abandoned; their deliveries stay pending and can be redelivered.
+//
+// Parameters:
+// - ctx: Bounds how long to wait for the queue to drain.
+//
+// Returns:
+// - error: ctx.Err() if the queue did not drain in time.
+func (d *Deliverer) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	close(d.queue)
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		close(d.abort)
+		<-done
+		return ctx.Err()
+	}
+}
+
+// work delivers queued events until the queue is closed.
+func (d *Deliverer) work() {
+	defer d.wg.Done()
+	for event := range d.queue {
+		d.dispatch(event)
+	}
+}
+
+// dispatch records a delivery of the event to every webhook subscribed to it and delivers them.
+func (d *Deliverer) dispatch(event events.Event) {
+	ctx := context.Background()
+	webhooks, err := repository.Webhooks.Find(ctx)
+	if err != nil {
+		log.Printf("Could not load the webhooks for %s event %s: %v", event.Type, event.ID, err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Could not encode %s event %s: %v", event.Type, event.ID, err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		if !Subscribed(webhook, event.Type) {
+			continue
+		}
+		delivery := models.WebhookDelivery{
+			WebhookID: webhook.ID,
+			EventID:   event.ID,
+			Event:     event.Type,
+			Payload:   string(payload),
+			Status:    models.WebhookDeliveryPending,
+			Attempts:  []models.WebhookAttempt{},
+			CreatedAt: models.NewTimestamp(time.Now()),
+		}
+		if err := repository.WebhookDeliveries.Create(ctx, &delivery); err != nil {
+			log.Printf("Could not record the delivery of %s event %s to webhook %s: %v", event.Type, event.ID, webhook.ID.Hex(), err)
+			continue
+		}
+		d.deliver(webhook, &delivery)
+	}
+}
+
+// deliver attempts a delivery, retrying with exponential backoff, until it succeeds or all attempts failed.
+func (d *Deliverer) deliver(webhook models.Webhook, delivery *models.WebhookDelivery) {
+	backoff := d.config.Backoff
+	for attempt := 1; attempt <= d.config.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(backoff):
+			case <-d.abort:
+				return
+			}
+			if backoff *= 2; backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+
+		result := d.attempt(webhook, *delivery)
+		status := models.WebhookDeliveryPending
+		if succeeded(result) {
+			status = models.WebhookDeliverySucceeded
+		} else if attempt == d.config.MaxAttempts {
+			status = models.WebhookDeliveryFailed
+		}
+		if err := record(context.Background(), delivery, result, status); err != nil {
+			log.Printf("Could not record an attempt of webhook delivery %s: %v", delivery.ID.Hex(), err)
+		}
+		if status == models.WebhookDeliverySucceeded {
+			return
+		}
+		log.Printf("Delivery attempt %d of %s event to webhook %s failed: %s", attempt, delivery.Event, webhook.ID.Hex(), result.Error)
+		if status == models.WebhookDeliveryFailed {
+			d.deadLetter(*delivery, errors.New(result.Error))
+		}
+	}
+}
+
+// deadLetter records a failed delivery, logging when even that fails.
+func (d *Deliverer) deadLetter(delivery models.WebhookDelivery, cause error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.DeadLetter(ctx, deadletter.KindWebhook, ToPayload(delivery), cause); err != nil {
+		log.Printf("Could not dead-letter webhook delivery %s: %v", delivery.ID.Hex(), err)
+	}
+}
+
+// ToPayload converts a delivery to a dead letter payload, which ReplayDelivery redelivers. The body is the
+// event as it was posted.
+func ToPayload(delivery models.WebhookDelivery) map[string]interface{} {
+	return map[string]interface{}{
+		"webhook_id":  delivery.WebhookID.Hex(),
+		"delivery_id": delivery.ID.Hex(),
+		"event":       delivery.Event,
+		"body":        delivery.Payload,
+	}
+}
+
+// ReplayDelivery redelivers a dead-lettered delivery, with the body of the payload if an admin edited it.
+// It is registered as the replayer of webhook dead letters.
+//
+// Parameters:
+// - ctx: Context bounding the request and the database operations.
+// - payload: The dead letter payload made by ToPayload.
+//
+// Returns:
+// - error: An error if the delivery is unknown, could not be recorded or failed again.
+func ReplayDelivery(ctx context.Context, payload map[string]interface{}) error {
+	webhookID, err := primitive.ObjectIDFromHex(fmt.Sprint(payload["webhook_id"]))
+	if err != nil {
+		return fmt.Errorf("invalid webhook_id: %w", err)
+	}
+	deliveryID, err := primitive.ObjectIDFromHex(fmt.Sprint(payload["delivery_id"]))
+	if err != nil {
+		return fmt.Errorf("invalid delivery_id: %w", err)
+	}
+	webhook, err := repository.Webhooks.FindByID(ctx, webhookID)
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", webhookID.Hex(), err)
+	}
+	delivery, err := repository.WebhookDeliveries.FindByID(ctx, webhookID, deliveryID)
+	if err != nil {
+		return fmt.Errorf("delivery %s: %w", deliveryID.Hex(), err)
+	}
+	if body, ok := payload["body"].(string); ok {
+		delivery.Payload = body
+	}
+
+	if err := Redeliver(ctx, *webhook, delivery); err != nil {
+		return err
+	}
+	if delivery.Status != models.WebhookDeliverySucceeded {
+		return errors.New(delivery.Attempts[len(delivery.Attempts)-1].Error)
+	}
+	return nil
+}
+
+// attempt makes a single attempt within the configured timeout.
+func (d *Deliverer) attempt(webhook models.Webhook, delivery models.WebhookDelivery) models.WebhookAttempt {
+	ctx := context.Background()
+	if d.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.config.Timeout)
+		defer cancel()
+	}
+	return Post(ctx, d.Client, webhook, delivery, false)
+}
+
+// Subscribed reports whether a webhook receives events of the given type.
+func Subscribed(webhook models.Webhook, eventType string) bool {
+	if len(webhook.Events) == 0 {
+		return true
+	}
+	for _, subscribed := range webhook.Events {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
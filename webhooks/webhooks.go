@@ -0,0 +1,187 @@
+// webhooks.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+// Package webhooks posts domain events to the webhooks registered by admins. Every request is signed with
+// the webhook's secret and carries a timestamp and a delivery ID, so receivers can reject forged and
+// replayed requests.
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+)
+
+// Headers of webhook requests
+const (
+	HeaderWebhook   = "X-Webhook-ID"        // ID of the webhook
+	HeaderDelivery  = "X-Webhook-Delivery"  // ID of the delivery, the same for every attempt
+	HeaderEvent     = "X-Webhook-Event"     // Event type, e.g. task.created
+	HeaderTimestamp = "X-Webhook-Timestamp" // Unix time of the attempt, in seconds
+	HeaderSignature = "X-Webhook-Signature" // "sha256=" and the hex HMAC-SHA256 of "<timestamp>.<body>"
+)
+
+var (
+	// ErrInvalidSignature is returned by Verify when the signature is missing or does not match.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrStaleTimestamp is returned by Verify when the timestamp is missing or outside the tolerance.
+	ErrStaleTimestamp = errors.New("webhook timestamp is outside the tolerance")
+)
+
+// Client sends the webhook requests. Redirects are not followed, so a receiver cannot bounce the signed
+// request elsewhere; they count as failed attempts.
+var Client = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// NewSecret generates the secret a new webhook signs its requests with.
+//
+// Returns:
+// - string: The secret, "whsec_" and 32 random bytes in base64url.
+// - error: An error if the random source fails.
+func NewSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// Sign computes the signature header of a request.
+//
+// Parameters:
+// - secret: The secret of the webhook.
+// - timestamp: The value of the timestamp header.
+// - body: The request body.
+//
+// Returns:
+// - string: "sha256=" and the hex HMAC-SHA256 of "<timestamp>.<body>".
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and the timestamp of a webhook request, as receivers written in Go can do.
+// To also reject replays within the tolerance, receivers remember the delivery IDs they processed.
+//
+// Parameters:
+// - secret: The secret of the webhook.
+// - header: The request headers.
+// - body: The request body.
+// - now: The current time.
+// - tolerance: How far the timestamp may be from now, e.g. 5 minutes.
+//
+// Returns:
+// - error: ErrStaleTimestamp or ErrInvalidSignature if the request must be rejected.
+func Verify(secret string, header http.Header, body []byte, now time.Time, tolerance time.Duration) error {
+	timestamp := header.Get(HeaderTimestamp)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrStaleTimestamp
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return ErrStaleTimestamp
+	}
+	if !hmac.Equal([]byte(header.Get(HeaderSignature)), []byte(Sign(secret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Post makes one attempt to deliver an event to a webhook, signed at the time of the attempt.
+//
+// Parameters:
+// - ctx: Context bounding the request.
+// - client: The HTTP client to send with; Client when nil.
+// - webhook: The webhook to deliver to.
+// - delivery: The delivery, whose payload is the request body.
+// - manual: Whether an admin asked for the attempt.
+//
+// Returns:
+// - models.WebhookAttempt: The outcome; any response other than 2xx is a failure.
+func Post(ctx context.Context, client *http.Client, webhook models.Webhook, delivery models.WebhookDelivery, manual bool) models.WebhookAttempt {
+	start := time.Now()
+	attempt := models.WebhookAttempt{At: models.NewTimestamp(start), Manual: manual}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	timestamp := strconv.FormatInt(start.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhook, webhook.ID.Hex())
+	req.Header.Set(HeaderDelivery, delivery.ID.Hex())
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, []byte(delivery.Payload)))
+
+	if client == nil {
+		client = Client
+	}
+	resp, err := client.Do(req)
+	attempt.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // Let the connection be reused
+
+	attempt.StatusCode = resp.StatusCode
+	if !succeeded(attempt) {
+		attempt.Error = fmt.Sprintf("%s answered %s", req.URL.Host, resp.Status)
+	}
+	return attempt
+}
+
+// Redeliver makes one more attempt of a delivery, e.g. after the receiver was fixed, and records it.
+//
+// Parameters:
+// - ctx: Context bounding the request and the database operation.
+// - webhook: The webhook to deliver to.
+// - delivery: The delivery to repeat; its attempts and status are updated.
+//
+// Returns:
+// - error: An error if the attempt could not be recorded; a failed attempt is not an error.
+func Redeliver(ctx context.Context, webhook models.Webhook, delivery *models.WebhookDelivery) error {
+	attempt := Post(ctx, nil, webhook, *delivery, true)
+	status := models.WebhookDeliveryFailed
+	if succeeded(attempt) {
+		status = models.WebhookDeliverySucceeded
+	}
+	return record(ctx, delivery, attempt, status)
+}
+
+// succeeded reports whether an attempt got a 2xx response.
+func succeeded(attempt models.WebhookAttempt) bool {
+	return attempt.StatusCode >= 200 && attempt.StatusCode <= 299
+}
+
+// record stores an attempt of a delivery and applies it to delivery.
+func record(ctx context.Context, delivery *models.WebhookDelivery, attempt models.WebhookAttempt, status string) error {
+	if err := repository.WebhookDeliveries.AddAttempt(ctx, delivery.ID, attempt, status); err != nil {
+		return err
+	}
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	delivery.Status = status
+	return nil
+}
@@ -0,0 +1,168 @@
+// webhooks_test.go
+// Author: Bipin Kumar Ojha (Freelancer)
+
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bkojha74/task-management/deadletter"
+	"github.com/bkojha74/task-management/events"
+	"github.com/bkojha74/task-management/models"
+	"github.com/bkojha74/task-management/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVerify tests that signatures cover the timestamp and the body and that stale timestamps are rejected
+func TestVerify(t *testing.T) {
+	now := time.Unix(1720180800, 0)
+	body := []byte(`{"type":"task.created"}`)
+	header := http.Header{}
+	header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	header.Set(HeaderSignature, Sign("whsec_test", header.Get(HeaderTimestamp), body))
+
+	assert.NoError(t, Verify("whsec_test", header, body, now.Add(time.Minute), 5*time.Minute))
+
+	// Assert that other secrets and bodies don't match
+	assert.ErrorIs(t, Verify("whsec_other", header, body, now, 5*time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("whsec_test", header, []byte(`{"type":"task.deleted"}`), now, 5*time.Minute), ErrInvalidSignature)
+
+	// Assert that a replayed request is rejected once the tolerance is over, and a changed timestamp breaks the signature
+	assert.ErrorIs(t, Verify("whsec_test", header, body, now.Add(10*time.Minute), 5*time.Minute), ErrStaleTimestamp)
+	header.Set(HeaderTimestamp, strconv.FormatInt(now.Add(10*time.Minute).Unix(), 10))
+	assert.ErrorIs(t, Verify("whsec_test", header, body, now.Add(10*time.Minute), 5*time.Minute), ErrInvalidSignature)
+}
+
+// TestDeliverer tests that events are delivered signed to the subscribed webhooks, retried and recorded
+func TestDeliverer(t *testing.T) {
+	repository.UseMemory()
+
+	var mu sync.Mutex
+	var requests []*http.Request
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+		// The first attempt fails
+		if len(requests) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	subscribed := models.Webhook{URL: server.URL, Secret: "whsec_test", Events: []string{events.TaskCreated}}
+	require.NoError(t, repository.Webhooks.Create(ctx, &subscribed))
+	other := models.Webhook{URL: server.URL, Secret: "whsec_other", Events: []string{events.UserDeleted}}
+	require.NoError(t, repository.Webhooks.Create(ctx, &other))
+
+	deliverer := NewDeliverer(Config{Workers: 1, QueueSize: 10, MaxAttempts: 3, Backoff: time.Millisecond, Timeout: time.Second})
+	deliverer.Handle(ctx, events.New(events.TaskCreated, "66a1", map[string]string{"title": "Write report"}))
+	require.NoError(t, deliverer.Close(ctx))
+
+	// Assert that only the subscribed webhook got the event, on the second attempt
+	require.Len(t, requests, 2)
+	for i, request := range requests {
+		assert.Equal(t, subscribed.ID.Hex(), request.Header.Get(HeaderWebhook))
+		assert.Equal(t, events.TaskCreated, request.Header.Get(HeaderEvent))
+		assert.NoError(t, Verify("whsec_test", request.Header, bodies[i], time.Now(), time.Minute))
+	}
+	// Assert that retries keep the delivery ID
+	assert.Equal(t, requests[0].Header.Get(HeaderDelivery), requests[1].Header.Get(HeaderDelivery))
+
+	deliveries, err := repository.WebhookDeliveries.Find(ctx, subscribed.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	delivery := deliveries[0]
+	assert.Equal(t, requests[0].Header.Get(HeaderDelivery), delivery.ID.Hex())
+	assert.Equal(t, models.WebhookDeliverySucceeded, delivery.Status)
+	require.Len(t, delivery.Attempts, 2)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.Attempts[0].StatusCode)
+	assert.NotEmpty(t, delivery.Attempts[0].Error)
+	assert.Equal(t, http.StatusNoContent, delivery.Attempts[1].StatusCode)
+
+	deliveries, err = repository.WebhookDeliveries.Find(ctx, other.ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+
+	// Assert that a redelivery sends the same body and is recorded as manual
+	require.NoError(t, Redeliver(ctx, subscribed, &delivery))
+	require.Len(t, requests, 3)
+	assert.Equal(t, bodies[0], bodies[2])
+	stored, err := repository.WebhookDeliveries.FindByID(ctx, subscribed.ID, delivery.ID)
+	require.NoError(t, err)
+	require.Len(t, stored.Attempts, 3)
+	assert.True(t, stored.Attempts[2].Manual)
+}
+
+// TestDeadLetteredDelivery tests that a delivery failing all attempts is dead-lettered and can be replayed
+// with an edited body
+func TestDeadLetteredDelivery(t *testing.T) {
+	repository.UseMemory()
+
+	var mu sync.Mutex
+	var bodies []string
+	healthy := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		bodies = append(bodies, string(body))
+		if !healthy {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	webhook := models.Webhook{URL: server.URL, Secret: "whsec_test"}
+	require.NoError(t, repository.Webhooks.Create(ctx, &webhook))
+
+	var deadLetters []map[string]interface{}
+	deliverer := NewDeliverer(Config{Workers: 1, QueueSize: 10, MaxAttempts: 2, Backoff: time.Millisecond, Timeout: time.Second})
+	deliverer.DeadLetter = func(ctx context.Context, kind string, payload map[string]interface{}, cause error) error {
+		assert.Equal(t, deadletter.KindWebhook, kind)
+		assert.Contains(t, cause.Error(), "502")
+		deadLetters = append(deadLetters, payload)
+		return nil
+	}
+	deliverer.Handle(ctx, events.New(events.TaskCreated, "66a1", map[string]string{"title": "Write report"}))
+	require.NoError(t, deliverer.Close(ctx))
+
+	// Assert that only the final failure is dead-lettered, with the body as posted
+	require.Len(t, deadLetters, 1)
+	payload := deadLetters[0]
+	assert.Equal(t, webhook.ID.Hex(), payload["webhook_id"])
+	assert.Equal(t, bodies[0], payload["body"])
+
+	// Assert that a replay fails while the receiver is down and sends the edited body once it is up
+	assert.Error(t, ReplayDelivery(ctx, payload))
+	mu.Lock()
+	healthy = true
+	mu.Unlock()
+	payload["body"] = `{"fixed":true}`
+	require.NoError(t, ReplayDelivery(ctx, payload))
+	assert.Equal(t, `{"fixed":true}`, bodies[len(bodies)-1])
+
+	deliveries, err := repository.WebhookDeliveries.Find(ctx, webhook.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, models.WebhookDeliverySucceeded, deliveries[0].Status)
+	assert.Len(t, deliveries[0].Attempts, 4)
+}